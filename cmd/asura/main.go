@@ -77,13 +77,27 @@ func main() {
 	logger := setupLogger(cfg.Logging)
 	logger.Info("starting asura", "version", version, "listen", cfg.Server.Listen)
 
-	store, err := storage.NewSQLiteStore(cfg.Database.Path, cfg.Database.MaxReadConns)
+	store, err := openStore(cfg.Database, cfg.Database.MaxReadConns)
 	if err != nil {
-		logger.Error("failed to open database", "error", err)
+		logger.Error("failed to open database", "driver", cfg.Database.Driver, "error", err)
 		os.Exit(1)
 	}
 	defer store.Close()
-	logger.Info("database opened", "path", cfg.Database.Path)
+	// The DSN usually carries a password, so only the SQLite path is logged.
+	if cfg.Database.Driver == "postgres" {
+		logger.Info("database opened", "driver", cfg.Database.Driver)
+	} else {
+		logger.Info("database opened", "driver", cfg.Database.Driver, "path", cfg.Database.Path)
+	}
+	store.SetMonitorLimits(storage.MonitorLimits{
+		MaxMonitors:         cfg.Monitor.MaxMonitors,
+		MaxMonitorsPerGroup: cfg.Monitor.MaxMonitorsPerGroup,
+	})
+	store.SetWriteRetry(storage.WriteRetry{
+		Attempts:   cfg.Database.WriteRetries,
+		Backoff:    cfg.Database.WriteRetryBackoff,
+		BufferSize: cfg.Database.WriteBufferSize,
+	})
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -93,7 +107,20 @@ func main() {
 	registry := checker.DefaultRegistry(cfg.Monitor.CommandAllowlist, cfg.Monitor.AllowPrivateTargets)
 	incMgr := incident.NewManager(store, logger)
 	pipeline := monitor.NewPipeline(store, registry, incMgr, cfg.Monitor.Workers, cfg.Monitor.AdaptiveIntervals, logger)
+	pipeline.SetAckSilencesReminders(cfg.Monitor.AckSilencesReminders)
+	pipeline.SetBaselineMinSamples(cfg.Monitor.BaselineMinSamples)
+	pipeline.SetMaxStoredBodyBytes(cfg.Monitor.MaxStoredBodyBytes)
+	pipeline.SetScheduleJitter(cfg.Monitor.ScheduleJitter)
+	pipeline.SetMaxQueue(cfg.Monitor.MaxQueue)
+	pipeline.SetMaxInFlight(cfg.Monitor.MaxInFlight)
+	pipeline.SetHostLimits(cfg.Monitor.PerHostMaxConcurrent, cfg.Monitor.PerHostMinInterval, cfg.Monitor.PerHostMaxWait)
 	dispatcher := notifier.NewDispatcher(store, logger, cfg.Monitor.AllowPrivateTargets)
+	dispatcher.SetDefaultChannel(cfg.Monitor.DefaultNotificationChannel)
+	dispatcher.SetOwnerChannels(cfg.Monitor.OwnerChannels)
+	dispatcher.SetPublicURL(cfg.ResolvedExternalURL())
+	dispatcher.SetMinNotifyInterval(cfg.Notifier.MinNotifyInterval)
+	dispatcher.SetActionLinks(cfg.Notifier.ActionKey, cfg.Notifier.ActionSecret, cfg.Notifier.ActionLinkTTL)
+	warnMissingDefaultChannel(ctx, store, cfg.Monitor.DefaultNotificationChannel, logger)
 
 	go forwardNotifications(ctx, pipeline, dispatcher)
 	go pipeline.Run(ctx)
@@ -101,12 +128,22 @@ func main() {
 	heartbeatWatcher := monitor.NewHeartbeatWatcher(store, incMgr, pipeline, cfg.Monitor.HeartbeatCheckInterval, logger)
 	go heartbeatWatcher.Run(ctx)
 
-	retentionWorker := storage.NewRetentionWorker(store, cfg.Database.RetentionDays, cfg.Database.RequestLogRetentionDays, cfg.Database.RetentionPeriod, logger)
+	baselineWorker := monitor.NewBaselineWorker(store, cfg.Monitor.BaselineInterval, cfg.Monitor.BaselineDays, logger)
+	go baselineWorker.Run(ctx)
+
+	if cfg.Monitor.ProxyCheckInterval > 0 {
+		proxyHealth := monitor.NewProxyHealthWorker(store, pipeline, cfg.Monitor.ProxyCheckInterval, cfg.Monitor.ProxyCanaryURL,
+			cfg.Monitor.ProxyDisableAfter, cfg.Monitor.ProxyReenable, logger)
+		go proxyHealth.Run(ctx)
+	}
+
+	retentionWorker := storage.NewRetentionWorker(store, cfg.Database.RetentionDays, cfg.Database.RequestLogRetentionDays,
+		cfg.Database.TransitionRetentionDays, cfg.Database.RetentionPeriod, logger)
 	go retentionWorker.Run(ctx)
 
 	srv := server.NewServer(cfg, store, pipeline, dispatcher, logger, version)
 	go srv.RequestLogWriter().Run(ctx)
-	go runRollupWorker(ctx, store, logger)
+	go runRollupWorker(ctx, store, cfg.Database.RetentionDays, logger)
 	httpServer := startHTTPServer(cfg, srv, logger, cancel)
 
 	quit := make(chan os.Signal, 1)
@@ -126,6 +163,7 @@ func main() {
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
 		logger.Error("server shutdown error", "error", err)
 	}
+	dispatcher.FlushDigests(shutdownCtx)
 
 	logger.Info("shutdown complete")
 }
@@ -138,11 +176,16 @@ func forwardNotifications(ctx context.Context, pipeline *monitor.Pipeline, dispa
 		case event := <-pipeline.NotifyChan():
 			payload := &notifier.Payload{
 				EventType: event.EventType,
+				Severity:  event.Severity,
 				Incident:  event.Incident,
 				Monitor:   event.Monitor,
 				Change:    event.Change,
+				Check:     event.Check,
+				Proxy:     event.Proxy,
 			}
-			if event.MonitorID > 0 {
+			if len(event.ChannelIDs) > 0 {
+				dispatcher.NotifyChannels(event.ChannelIDs, payload)
+			} else if event.MonitorID > 0 {
 				dispatcher.NotifyForMonitor(event.MonitorID, payload)
 			} else {
 				dispatcher.NotifyWithPayload(payload)
@@ -184,6 +227,21 @@ func startHTTPServer(cfg *config.Config, handler http.Handler, logger *slog.Logg
 	return httpServer
 }
 
+// appStore is a storage.Store with the setup hooks main configures.
+type appStore interface {
+	storage.Store
+	SetMonitorLimits(storage.MonitorLimits)
+	SetWriteRetry(storage.WriteRetry)
+}
+
+// openStore opens the backend selected by database.driver.
+func openStore(db config.DatabaseConfig, maxConns int) (appStore, error) {
+	if db.Driver == "postgres" {
+		return storage.NewPostgresStore(db.DSN, maxConns)
+	}
+	return storage.NewSQLiteStore(db.Path, maxConns)
+}
+
 func setupLogger(cfg config.LoggingConfig) *slog.Logger {
 	level := slog.LevelInfo
 	switch strings.ToLower(cfg.Level) {
@@ -207,21 +265,54 @@ func setupLogger(cfg config.LoggingConfig) *slog.Logger {
 	return slog.New(handler)
 }
 
-func runRollupWorker(ctx context.Context, store storage.Store, logger *slog.Logger) {
+func runRollupWorker(ctx context.Context, store storage.Store, retentionDays int, logger *slog.Logger) {
 	ticker := time.NewTicker(1 * time.Hour)
 	defer ticker.Stop()
 
+	// Fill the status page history window on startup, then keep yesterday
+	// current for results that arrive after midnight. Days that retention
+	// has already purged, fully or in part, keep the rows rolled up while
+	// their results still existed.
+	today := time.Now().UTC()
+	from := today.AddDate(0, 0, -90)
+	if kept := today.AddDate(0, 0, -retentionDays+1); kept.After(from) {
+		from = kept
+	}
+	if err := store.RollupDailyUptime(ctx, from, today); err != nil {
+		logger.Error("daily uptime rollup failed", "error", err)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+			now := time.Now().UTC()
+			yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
 			if err := store.RollupRequestLogs(ctx, yesterday); err != nil {
 				logger.Error("request log rollup failed", "date", yesterday, "error", err)
 			}
+			if err := store.RollupDailyUptime(ctx, now.AddDate(0, 0, -1), now); err != nil {
+				logger.Error("daily uptime rollup failed", "date", yesterday, "error", err)
+			}
+		}
+	}
+}
+
+func warnMissingDefaultChannel(ctx context.Context, store storage.Store, name string, logger *slog.Logger) {
+	if name == "" {
+		return
+	}
+	channels, err := store.ListNotificationChannels(ctx)
+	if err != nil {
+		return
+	}
+	for _, ch := range channels {
+		if ch.Name == name {
+			return
 		}
 	}
+	logger.Warn("default notification channel not found, monitors without channels notify every channel", "channel", name)
 }
 
 func purgeStaleSessionsOnStartup(ctx context.Context, store storage.Store, cfg *config.Config, logger *slog.Logger) {
@@ -30,7 +30,7 @@ func handleTOTPCommands(configPath, setupName, verifyName, removeName string, ar
 		return err
 	}
 
-	store, err := storage.NewSQLiteStore(cfg.Database.Path, 1)
+	store, err := openStore(cfg.Database, 1)
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
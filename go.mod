@@ -3,7 +3,10 @@ module github.com/y0f/asura
 go 1.24.0
 
 require (
+	github.com/a-h/templ v0.3.977
 	github.com/coder/websocket v1.8.12
+	github.com/hashicorp/cronexpr v1.1.3
+	github.com/lib/pq v1.12.3
 	golang.org/x/net v0.50.0
 	golang.org/x/time v0.9.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -11,7 +14,6 @@ require (
 )
 
 require (
-	github.com/a-h/templ v0.3.977 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
@@ -2,6 +2,9 @@ package api
 
 import (
 	"net/http"
+	"strconv"
+
+	"github.com/y0f/asura/internal/httputil"
 )
 
 func (h *Handler) DBVacuum(w http.ResponseWriter, r *http.Request) {
@@ -22,3 +25,30 @@ func (h *Handler) DBSize(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, map[string]int64{"size_bytes": size})
 }
+
+// DebugScheduler exposes the scheduler heap and worker pool state. It is
+// restricted to super-admin keys. ?limit controls how many upcoming checks
+// are listed (default 10, max 100).
+func (h *Handler) DebugScheduler(w http.ResponseWriter, r *http.Request) {
+	k := httputil.GetAPIKey(r.Context())
+	if k == nil || !k.SuperAdmin {
+		writeError(w, http.StatusForbidden, "admin access required")
+		return
+	}
+	if h.pipeline == nil {
+		writeError(w, http.StatusServiceUnavailable, "scheduler is not running")
+		return
+	}
+
+	limit := 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		limit = min(n, 100)
+	}
+
+	writeJSON(w, http.StatusOK, h.pipeline.SchedulerStats(limit))
+}
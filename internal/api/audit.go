@@ -8,6 +8,8 @@ import (
 	"github.com/y0f/asura/internal/storage"
 )
 
+// ListAuditLog lists audit entries, newest first, filtered by action,
+// entity, api_key and an RFC3339 from/to range.
 func (h *Handler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 	p := httputil.ParsePagination(r)
@@ -17,14 +19,17 @@ func (h *Handler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
 		Entity:     q.Get("entity"),
 		APIKeyName: q.Get("api_key"),
 	}
+	var err error
 	if from := q.Get("from"); from != "" {
-		if t, err := time.Parse(time.RFC3339, from); err == nil {
-			f.From = t
+		if f.From, err = time.Parse(time.RFC3339, from); err != nil {
+			writeError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+			return
 		}
 	}
 	if to := q.Get("to"); to != "" {
-		if t, err := time.Parse(time.RFC3339, to); err == nil {
-			f.To = t
+		if f.To, err = time.Parse(time.RFC3339, to); err != nil {
+			writeError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+			return
 		}
 	}
 
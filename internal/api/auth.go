@@ -2,12 +2,27 @@ package api
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/y0f/asura/internal/httputil"
+	"github.com/y0f/asura/internal/storage"
 )
 
+// Auth requires an API key with perm. Keys scoped to groups or tags are
+// refused; routes that honor a scope use AuthScoped.
 func (h *Handler) Auth(perm string) func(http.Handler) http.Handler {
+	return h.auth(perm, false)
+}
+
+// AuthScoped is Auth for routes that filter what they return by the key's
+// monitor scope. The scope of a scoped key is added to the request context.
+func (h *Handler) AuthScoped(perm string) func(http.Handler) http.Handler {
+	return h.auth(perm, true)
+}
+
+func (h *Handler) auth(perm string, allowScoped bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			key := r.Header.Get("X-API-Key")
@@ -28,7 +43,64 @@ func (h *Handler) Auth(perm string) func(http.Handler) http.Handler {
 			}
 
 			ctx := context.WithValue(r.Context(), httputil.CtxKeyAPIKey, apiKey)
+			if apiKey.Scoped() {
+				if !allowScoped {
+					writeError(w, http.StatusForbidden, "not available to API keys scoped to groups or tags")
+					return
+				}
+				ctx = context.WithValue(ctx, httputil.CtxKeyScope, &storage.MonitorScope{
+					GroupIDs: apiKey.GroupIDs,
+					TagIDs:   apiKey.TagIDs,
+				})
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// MonitorScope answers 404 for a monitor outside the caller's scope, as if
+// it did not exist. It wraps routes with a monitor {id} behind AuthScoped.
+func (h *Handler) MonitorScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := httputil.GetMonitorScope(r.Context())
+		if scope == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, err := httputil.ParseID(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		ok, err := h.monitorInScope(r.Context(), scope, id)
+		if err != nil {
+			h.logger.Error("monitor scope", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to get monitor")
+			return
+		}
+		if !ok {
+			writeError(w, http.StatusNotFound, "monitor not found")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) monitorInScope(ctx context.Context, scope *storage.MonitorScope, id int64) (bool, error) {
+	m, err := h.store.GetMonitor(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	tags, err := h.store.GetMonitorTags(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	tagIDs := make([]int64, len(tags))
+	for i, t := range tags {
+		tagIDs[i] = t.TagID
+	}
+	return scope.Allows(m.GroupID, tagIDs), nil
+}
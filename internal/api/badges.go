@@ -5,9 +5,14 @@ import (
 	"html"
 	"io"
 	"net/http"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/y0f/asura/internal/httputil"
+	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/storage"
 )
 
 const (
@@ -20,49 +25,46 @@ const (
 func (h *Handler) BadgeStatus(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
-		writeBadgeSVG(w, "status", "error", colorGrey)
+		writeBadge(w, r, "status", "error", colorGrey)
 		return
 	}
 
 	ctx := r.Context()
 	m, err := h.store.GetMonitor(ctx, id)
 	if err != nil {
-		writeBadgeSVG(w, "status", "not found", colorGrey)
+		writeBadge(w, r, "status", "not found", colorGrey)
 		return
 	}
 
 	visible, err := h.store.IsMonitorOnStatusPage(ctx, m.ID)
 	if err != nil || !visible {
-		writeBadgeSVG(w, "status", "not found", colorGrey)
+		writeBadge(w, r, "status", "not found", colorGrey)
 		return
 	}
 
-	label := r.URL.Query().Get("label")
-	if label == "" {
-		label = m.Name
-	}
+	label := badgeLabel(r, m.Name)
 	status := m.Status
 	color := statusColor(status)
-	writeBadgeSVG(w, label, status, color)
+	writeBadge(w, r, label, status, badgeColor(r, color))
 }
 
 func (h *Handler) BadgeUptime(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
-		writeBadgeSVG(w, "uptime", "error", colorGrey)
+		writeBadge(w, r, "uptime", "error", colorGrey)
 		return
 	}
 
 	ctx := r.Context()
 	m, err := h.store.GetMonitor(ctx, id)
 	if err != nil {
-		writeBadgeSVG(w, "uptime", "not found", colorGrey)
+		writeBadge(w, r, "uptime", "not found", colorGrey)
 		return
 	}
 
 	visible, err := h.store.IsMonitorOnStatusPage(ctx, m.ID)
 	if err != nil || !visible {
-		writeBadgeSVG(w, "uptime", "not found", colorGrey)
+		writeBadge(w, r, "uptime", "not found", colorGrey)
 		return
 	}
 
@@ -70,14 +72,11 @@ func (h *Handler) BadgeUptime(w http.ResponseWriter, r *http.Request) {
 	from := now.Add(-30 * 24 * time.Hour)
 	pct, err := h.store.GetUptimePercent(ctx, id, from, now)
 	if err != nil {
-		writeBadgeSVG(w, "uptime", "error", colorGrey)
+		writeBadge(w, r, "uptime", "error", colorGrey)
 		return
 	}
 
-	label := r.URL.Query().Get("label")
-	if label == "" {
-		label = "uptime"
-	}
+	label := badgeLabel(r, "uptime")
 	value := fmt.Sprintf("%.2f%%", pct)
 	color := colorGreen
 	if pct < 99 {
@@ -86,26 +85,26 @@ func (h *Handler) BadgeUptime(w http.ResponseWriter, r *http.Request) {
 	if pct < 95 {
 		color = colorRed
 	}
-	writeBadgeSVG(w, label, value, color)
+	writeBadge(w, r, label, value, badgeColor(r, color))
 }
 
 func (h *Handler) BadgeResponseTime(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
-		writeBadgeSVG(w, "response", "error", colorGrey)
+		writeBadge(w, r, "response", "error", colorGrey)
 		return
 	}
 
 	ctx := r.Context()
 	m, err := h.store.GetMonitor(ctx, id)
 	if err != nil {
-		writeBadgeSVG(w, "response", "not found", colorGrey)
+		writeBadge(w, r, "response", "not found", colorGrey)
 		return
 	}
 
 	visible, err := h.store.IsMonitorOnStatusPage(ctx, m.ID)
 	if err != nil || !visible {
-		writeBadgeSVG(w, "response", "not found", colorGrey)
+		writeBadge(w, r, "response", "not found", colorGrey)
 		return
 	}
 
@@ -113,14 +112,11 @@ func (h *Handler) BadgeResponseTime(w http.ResponseWriter, r *http.Request) {
 	from := now.Add(-24 * time.Hour)
 	p50, _, _, err := h.store.GetResponseTimePercentiles(ctx, id, from, now)
 	if err != nil {
-		writeBadgeSVG(w, "response", "error", colorGrey)
+		writeBadge(w, r, "response", "error", colorGrey)
 		return
 	}
 
-	label := r.URL.Query().Get("label")
-	if label == "" {
-		label = "response time"
-	}
+	label := badgeLabel(r, "response time")
 	value := fmt.Sprintf("%.0fms", p50)
 	color := colorGreen
 	if p50 > 500 {
@@ -129,39 +125,36 @@ func (h *Handler) BadgeResponseTime(w http.ResponseWriter, r *http.Request) {
 	if p50 > 2000 {
 		color = colorRed
 	}
-	writeBadgeSVG(w, label, value, color)
+	writeBadge(w, r, label, value, badgeColor(r, color))
 }
 
 func (h *Handler) BadgeCert(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
-		writeBadgeSVG(w, "cert", "error", colorGrey)
+		writeBadge(w, r, "cert", "error", colorGrey)
 		return
 	}
 
 	ctx := r.Context()
 	m, err := h.store.GetMonitor(ctx, id)
 	if err != nil {
-		writeBadgeSVG(w, "cert", "not found", colorGrey)
+		writeBadge(w, r, "cert", "not found", colorGrey)
 		return
 	}
 
 	visible, err := h.store.IsMonitorOnStatusPage(ctx, m.ID)
 	if err != nil || !visible {
-		writeBadgeSVG(w, "cert", "not found", colorGrey)
+		writeBadge(w, r, "cert", "not found", colorGrey)
 		return
 	}
 
 	cr, err := h.store.GetLatestCheckResult(ctx, id)
 	if err != nil || cr == nil || cr.CertExpiry == nil {
-		writeBadgeSVG(w, "cert", "n/a", colorGrey)
+		writeBadge(w, r, "cert", "n/a", colorGrey)
 		return
 	}
 
-	label := r.URL.Query().Get("label")
-	if label == "" {
-		label = "cert expiry"
-	}
+	label := badgeLabel(r, "cert expiry")
 	days := int(time.Until(*cr.CertExpiry).Hours() / 24)
 	value := fmt.Sprintf("%dd", days)
 	color := colorGreen
@@ -171,7 +164,61 @@ func (h *Handler) BadgeCert(w http.ResponseWriter, r *http.Request) {
 	if days < 7 {
 		color = colorRed
 	}
-	writeBadgeSVG(w, label, value, color)
+	writeBadge(w, r, label, value, badgeColor(r, color))
+}
+
+// BadgeIncident shows "operational" or the number of unresolved incidents,
+// coloured by the severity of the most recent one.
+func (h *Handler) BadgeIncident(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeBadge(w, r, "incidents", "error", colorGrey)
+		return
+	}
+
+	ctx := r.Context()
+	m, err := h.store.GetMonitor(ctx, id)
+	if err != nil {
+		writeBadge(w, r, "incidents", "not found", colorGrey)
+		return
+	}
+
+	visible, err := h.store.IsMonitorOnStatusPage(ctx, m.ID)
+	if err != nil || !visible {
+		writeBadge(w, r, "incidents", "not found", colorGrey)
+		return
+	}
+
+	label := badgeLabel(r, "incidents")
+
+	// The badge is red while any open incident is critical and yellow only
+	// when every one of them is a warning.
+	var count int64
+	color := colorYellow
+	for _, status := range []string{incident.StatusOpen, incident.StatusAcknowledged} {
+		res, err := h.store.ListIncidents(ctx, id, status, "", storage.Pagination{Page: 1, PerPage: 100})
+		if err != nil {
+			writeBadge(w, r, "incidents", "error", colorGrey)
+			return
+		}
+		count += res.Total
+		incs, _ := res.Data.([]*storage.Incident)
+		for _, inc := range incs {
+			if inc.Severity != incident.SeverityWarning {
+				color = colorRed
+			}
+		}
+	}
+	if count == 0 {
+		writeBadge(w, r, label, "operational", badgeColor(r, colorGreen))
+		return
+	}
+
+	value := "1 open incident"
+	if count > 1 {
+		value = fmt.Sprintf("%d open incidents", count)
+	}
+	writeBadge(w, r, label, value, badgeColor(r, color))
 }
 
 func statusColor(status string) string {
@@ -187,6 +234,77 @@ func statusColor(status string) string {
 	}
 }
 
+// maxBadgeLabel caps the length of a label passed in the query string.
+const maxBadgeLabel = 64
+
+// badgeLabel returns the label query parameter with control characters
+// removed and its length capped, or def when it is empty.
+func badgeLabel(r *http.Request, def string) string {
+	label := strings.Map(func(c rune) rune {
+		if unicode.IsControl(c) {
+			return -1
+		}
+		return c
+	}, r.URL.Query().Get("label"))
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return def
+	}
+	if runes := []rune(label); len(runes) > maxBadgeLabel {
+		label = string(runes[:maxBadgeLabel])
+	}
+	return label
+}
+
+// badgeNamedColors maps the shields.io color names to their hex values.
+var badgeNamedColors = map[string]string{
+	"brightgreen": colorGreen,
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      colorYellow,
+	"orange":      "#fe7d37",
+	"red":         colorRed,
+	"blue":        "#007ec6",
+	"lightgrey":   colorGrey,
+	"lightgray":   colorGrey,
+	"grey":        "#555",
+	"gray":        "#555",
+}
+
+var badgeHexColor = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// badgeColor returns the color query parameter when it is a shields.io color
+// name or a 3 or 6 digit hex value, and def otherwise.
+func badgeColor(r *http.Request, def string) string {
+	c := strings.TrimSpace(r.URL.Query().Get("color"))
+	if named, ok := badgeNamedColors[strings.ToLower(c)]; ok {
+		return named
+	}
+	if m := badgeHexColor.FindStringSubmatch(c); m != nil {
+		return "#" + strings.ToLower(m[1])
+	}
+	return def
+}
+
+// badgeJSON is the shields.io endpoint badge schema.
+type badgeJSON struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// writeBadge renders an SVG badge, or the shields.io endpoint JSON when the
+// request asks for format=json.
+func writeBadge(w http.ResponseWriter, r *http.Request, label, value, color string) {
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Cache-Control", "max-age=300")
+		writeJSON(w, http.StatusOK, badgeJSON{SchemaVersion: 1, Label: label, Message: value, Color: color})
+		return
+	}
+	writeBadgeSVG(w, label, value, color)
+}
+
 func writeBadgeSVG(w http.ResponseWriter, label, value, color string) {
 	label = html.EscapeString(label)
 	value = html.EscapeString(value)
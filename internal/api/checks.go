@@ -0,0 +1,334 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/y0f/asura/internal/httputil"
+	"github.com/y0f/asura/internal/monitor"
+	"github.com/y0f/asura/internal/storage"
+)
+
+type checkFieldDiff struct {
+	Field    string `json:"field"`
+	Original any    `json:"original"`
+	Rerun    any    `json:"rerun"`
+}
+
+type checkRerunResponse struct {
+	Original      *storage.CheckResult `json:"original"`
+	Rerun         *storage.CheckResult `json:"rerun"`
+	ConfigSource  string               `json:"config_source"` // snapshot or current
+	ConfigChanged bool                 `json:"config_changed"`
+	Differences   []checkFieldDiff     `json:"differences"`
+}
+
+func (h *Handler) GetCheck(w http.ResponseWriter, r *http.Request) {
+	monitorID, cr, ok := h.loadCheck(w, r)
+	if !ok {
+		return
+	}
+
+	resp := map[string]any{"check": cr}
+	if cr.ConfigHash != "" {
+		if cfg, err := h.store.GetCheckConfig(r.Context(), monitorID, cr.ConfigHash); err == nil {
+			resp["config"] = cfg
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// RerunCheck executes a check again using the configuration snapshot stored
+// with a historical result (or the current configuration with ?config=current)
+// and reports how the new outcome differs from the stored one.
+func (h *Handler) RerunCheck(w http.ResponseWriter, r *http.Request) {
+	if h.pipeline == nil {
+		writeError(w, http.StatusServiceUnavailable, "check runner is not available")
+		return
+	}
+
+	monitorID, cr, ok := h.loadCheck(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	mon, err := h.store.GetMonitor(ctx, monitorID)
+	if err != nil {
+		h.logger.Error("get monitor for rerun", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+
+	resp := checkRerunResponse{Original: cr, ConfigSource: "current"}
+	if r.URL.Query().Get("config") != "current" && cr.ConfigHash != "" {
+		cfg, err := h.store.GetCheckConfig(ctx, monitorID, cr.ConfigHash)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			h.logger.Error("get check config", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to get check config")
+			return
+		}
+		if cfg != nil {
+			mon.Type = cfg.Type
+			mon.Target = cfg.Target
+			mon.Timeout = cfg.Timeout
+			mon.ConnectTimeout = cfg.ConnectTimeout
+			mon.Settings = cfg.Settings
+			mon.Assertions = cfg.Assertions
+			mon.UpsideDown = cfg.UpsideDown
+			resp.ConfigSource = "snapshot"
+		}
+	}
+
+	resp.Rerun = h.pipeline.RunCheck(ctx, mon)
+	resp.ConfigChanged = cr.ConfigHash != "" && cr.ConfigHash != resp.Rerun.ConfigHash
+	resp.Differences = diffCheckResults(cr, resp.Rerun)
+
+	h.audit(r, "rerun", "check", cr.ID, fmt.Sprintf("monitor=%d config=%s", monitorID, resp.ConfigSource))
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type checkNowRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+type checkNowResult struct {
+	MonitorID int64                `json:"monitor_id"`
+	Check     *storage.CheckResult `json:"check,omitempty"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// CheckMonitorNow checks a monitor immediately, outside its schedule, and
+// returns the stored result.
+func (h *Handler) CheckMonitorNow(w http.ResponseWriter, r *http.Request) {
+	if h.pipeline == nil {
+		writeError(w, http.StatusServiceUnavailable, "check runner is not available")
+		return
+	}
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := h.checkNowContext(r)
+	defer cancel()
+	res := h.pipeline.TriggerNow(ctx, id)[0]
+	if res.Err != nil {
+		status, msg := h.checkNowError(res)
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", strconv.Itoa(int(monitor.TriggerCooldown.Seconds())))
+		}
+		writeError(w, status, msg)
+		return
+	}
+
+	h.audit(r, "check_now", "monitor", id, "")
+	writeJSON(w, http.StatusOK, res.Check)
+}
+
+// CheckMonitorsNow checks several monitors immediately and reports the
+// stored result or the reason it was skipped for each.
+func (h *Handler) CheckMonitorsNow(w http.ResponseWriter, r *http.Request) {
+	if h.pipeline == nil {
+		writeError(w, http.StatusServiceUnavailable, "check runner is not available")
+		return
+	}
+	var req checkNowRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeError(w, http.StatusBadRequest, "ids is required")
+		return
+	}
+	if len(req.IDs) > monitor.MaxTriggerBatch {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("max %d monitors per request", monitor.MaxTriggerBatch))
+		return
+	}
+
+	ctx, cancel := h.checkNowContext(r)
+	defer cancel()
+	results := make([]checkNowResult, 0, len(req.IDs))
+	checked := 0
+	for _, res := range h.pipeline.TriggerNow(ctx, req.IDs...) {
+		out := checkNowResult{MonitorID: res.MonitorID, Check: res.Check}
+		if res.Err != nil {
+			_, out.Error = h.checkNowError(res)
+		} else {
+			checked++
+		}
+		results = append(results, out)
+	}
+
+	h.audit(r, "bulk_check_now", "monitor", 0, fmt.Sprintf("ids=%v", req.IDs))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"checked": checked,
+		"results": results,
+	})
+}
+
+// checkNowContext bounds how long a check-now request waits for results so
+// the response is written before the server's write timeout. Checks still
+// running at that point finish in the background.
+func (h *Handler) checkNowContext(r *http.Request) (context.Context, context.CancelFunc) {
+	wait := h.cfg.Server.WriteTimeout - 2*time.Second
+	if wait <= 0 {
+		wait = 28 * time.Second
+	}
+	return context.WithTimeout(r.Context(), wait)
+}
+
+func (h *Handler) checkNowError(res monitor.TriggerResult) (int, string) {
+	switch {
+	case errors.Is(res.Err, sql.ErrNoRows):
+		return http.StatusNotFound, "monitor not found"
+	case errors.Is(res.Err, monitor.ErrTriggerHeartbeat):
+		return http.StatusBadRequest, res.Err.Error()
+	case errors.Is(res.Err, monitor.ErrTriggerPaused):
+		return http.StatusConflict, res.Err.Error()
+	case errors.Is(res.Err, monitor.ErrTriggerTooSoon):
+		return http.StatusTooManyRequests, res.Err.Error()
+	case errors.Is(res.Err, monitor.ErrTriggerDropped):
+		return http.StatusServiceUnavailable, res.Err.Error()
+	case errors.Is(res.Err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "check is still running; its result will appear in the check history"
+	default:
+		h.logger.Error("check now", "monitor_id", res.MonitorID, "error", res.Err)
+		return http.StatusInternalServerError, "check failed"
+	}
+}
+
+// csvBatchSize is how many check results ExportChecksCSV reads per query.
+const csvBatchSize = 1000
+
+// ExportChecksCSV streams a monitor's check results as CSV, oldest first.
+// from and to (RFC3339) bound the range; by default it covers all history.
+func (h *Handler) ExportChecksCSV(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	from := time.Unix(0, 0).UTC()
+	to := time.Now().UTC()
+	if f := r.URL.Query().Get("from"); f != "" {
+		if from, err = time.Parse(time.RFC3339, f); err != nil {
+			writeError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if t := r.URL.Query().Get("to"); t != "" {
+		if to, err = time.Parse(time.RFC3339, t); err != nil {
+			writeError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+	}
+
+	if _, err := h.store.GetMonitor(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "monitor not found")
+			return
+		}
+		h.logger.Error("get monitor for csv export", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="asura-monitor-%d-checks.csv"`, id))
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "status", "response_time", "status_code", "message", "created_at"})
+
+	rc := http.NewResponseController(w)
+	var afterID int64
+	for {
+		// Each batch gets a fresh write deadline so that exporting a long
+		// history is not cut off by server.write_timeout.
+		if h.cfg.Server.WriteTimeout > 0 {
+			rc.SetWriteDeadline(time.Now().Add(h.cfg.Server.WriteTimeout))
+		}
+		batch, err := h.store.ListCheckResultsRange(r.Context(), id, from, to, afterID, csvBatchSize)
+		if err != nil {
+			// The status is already sent, so abort the connection: the
+			// client then sees a failed download instead of a short file.
+			h.logger.Error("list checks for csv export", "monitor_id", id, "error", err)
+			panic(http.ErrAbortHandler)
+		}
+		for _, cr := range batch {
+			cw.Write([]string{
+				strconv.FormatInt(cr.ID, 10),
+				cr.Status,
+				strconv.FormatInt(cr.ResponseTime, 10),
+				strconv.Itoa(cr.StatusCode),
+				cr.Message,
+				cr.CreatedAt.UTC().Format(time.RFC3339),
+			})
+		}
+		cw.Flush()
+		rc.Flush()
+		if len(batch) < csvBatchSize {
+			break
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+	cw.Flush()
+}
+
+func (h *Handler) loadCheck(w http.ResponseWriter, r *http.Request) (int64, *storage.CheckResult, bool) {
+	monitorID, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return 0, nil, false
+	}
+	checkID, err := strconv.ParseInt(r.PathValue("checkID"), 10, 64)
+	if err != nil || checkID <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid check id")
+		return 0, nil, false
+	}
+
+	cr, err := h.store.GetCheckResult(r.Context(), checkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "check not found")
+			return 0, nil, false
+		}
+		h.logger.Error("get check result", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get check")
+		return 0, nil, false
+	}
+	if cr.MonitorID != monitorID {
+		writeError(w, http.StatusNotFound, "check not found")
+		return 0, nil, false
+	}
+	return monitorID, cr, true
+}
+
+func diffCheckResults(a, b *storage.CheckResult) []checkFieldDiff {
+	diffs := []checkFieldDiff{}
+	if a.Status != b.Status {
+		diffs = append(diffs, checkFieldDiff{Field: "status", Original: a.Status, Rerun: b.Status})
+	}
+	if a.StatusCode != b.StatusCode {
+		diffs = append(diffs, checkFieldDiff{Field: "status_code", Original: a.StatusCode, Rerun: b.StatusCode})
+	}
+	if a.Message != b.Message {
+		diffs = append(diffs, checkFieldDiff{Field: "message", Original: a.Message, Rerun: b.Message})
+	}
+	if a.BodyHash != b.BodyHash {
+		diffs = append(diffs, checkFieldDiff{Field: "body_hash", Original: a.BodyHash, Rerun: b.BodyHash})
+	}
+	if a.DNSRecords != b.DNSRecords {
+		diffs = append(diffs, checkFieldDiff{Field: "dns_records", Original: a.DNSRecords, Rerun: b.DNSRecords})
+	}
+	return diffs
+}
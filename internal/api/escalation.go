@@ -0,0 +1,166 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/y0f/asura/internal/httputil"
+	"github.com/y0f/asura/internal/storage"
+	"github.com/y0f/asura/internal/validate"
+)
+
+func (h *Handler) ListEscalationPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.store.ListEscalationPolicies(r.Context())
+	if err != nil {
+		h.logger.Error("list escalation policies", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list escalation policies")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": policies})
+}
+
+func (h *Handler) GetEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ep, err := h.store.GetEscalationPolicy(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "escalation policy not found")
+			return
+		}
+		h.logger.Error("get escalation policy", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get escalation policy")
+		return
+	}
+	writeJSON(w, http.StatusOK, ep)
+}
+
+func (h *Handler) CreateEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	var ep storage.EscalationPolicy
+	if err := readJSON(r, &ep); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.validateEscalationPolicy(r, &ep); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.store.CreateEscalationPolicy(r.Context(), &ep); err != nil {
+		h.logger.Error("create escalation policy", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create escalation policy")
+		return
+	}
+
+	h.audit(r, "create", "escalation_policy", ep.ID, "")
+	writeJSON(w, http.StatusCreated, ep)
+}
+
+func (h *Handler) UpdateEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	existing, err := h.store.GetEscalationPolicy(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "escalation policy not found")
+			return
+		}
+		h.logger.Error("get escalation policy for update", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get escalation policy")
+		return
+	}
+
+	var ep storage.EscalationPolicy
+	if err := readJSON(r, &ep); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ep.ID = id
+	ep.CreatedAt = existing.CreatedAt
+
+	if err := h.validateEscalationPolicy(r, &ep); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.store.UpdateEscalationPolicy(r.Context(), &ep); err != nil {
+		h.logger.Error("update escalation policy", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update escalation policy")
+		return
+	}
+
+	updated, _ := h.store.GetEscalationPolicy(r.Context(), id)
+	if updated == nil {
+		updated = &ep
+	}
+
+	h.audit(r, "update", "escalation_policy", ep.ID, "")
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *Handler) DeleteEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	_, err = h.store.GetEscalationPolicy(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "escalation policy not found")
+			return
+		}
+		h.logger.Error("get escalation policy for delete", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get escalation policy")
+		return
+	}
+
+	if err := h.store.DeleteEscalationPolicy(r.Context(), id); err != nil {
+		h.logger.Error("delete escalation policy", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete escalation policy")
+		return
+	}
+
+	h.audit(r, "delete", "escalation_policy", id, "")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// validateEscalationPolicy checks the policy's shape and that every step
+// names existing notification channels.
+func (h *Handler) validateEscalationPolicy(r *http.Request, ep *storage.EscalationPolicy) error {
+	if err := validate.ValidateEscalationPolicy(ep); err != nil {
+		return err
+	}
+	for i, step := range ep.Steps {
+		for _, cid := range step.ChannelIDs {
+			if _, err := h.store.GetNotificationChannel(r.Context(), cid); err != nil {
+				return fmt.Errorf("steps[%d]: notification channel %d not found", i, cid)
+			}
+		}
+	}
+	return nil
+}
+
+// escalationPolicyExists writes a 400 and returns false when id names a
+// policy that does not exist. A nil id is always valid.
+func (h *Handler) escalationPolicyExists(w http.ResponseWriter, r *http.Request, id *int64) bool {
+	if id == nil {
+		return true
+	}
+	if _, err := h.store.GetEscalationPolicy(r.Context(), *id); err != nil {
+		writeError(w, http.StatusBadRequest, "escalation policy not found")
+		return false
+	}
+	return true
+}
@@ -1,48 +1,77 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"path"
+	"strings"
 	"time"
 
+	"github.com/y0f/asura/internal/httputil"
 	"github.com/y0f/asura/internal/storage"
 	"github.com/y0f/asura/internal/validate"
+	"gopkg.in/yaml.v3"
 )
 
 // ExportData is the top-level export format.
 type ExportData struct {
-	Version              int                            `json:"version"`
-	ExportedAt           time.Time                      `json:"exported_at"`
-	Monitors             []ExportMonitor                `json:"monitors"`
-	NotificationChannels []*storage.NotificationChannel `json:"notification_channels"`
-	MonitorGroups        []*storage.MonitorGroup        `json:"monitor_groups"`
-	MaintenanceWindows   []*storage.MaintenanceWindow   `json:"maintenance_windows"`
-	Proxies              []ExportProxy                  `json:"proxies"`
-	StatusPages          []ExportStatusPage             `json:"status_pages"`
+	Version              int                          `json:"version"`
+	ExportedAt           time.Time                    `json:"exported_at"`
+	Monitors             []ExportMonitor              `json:"monitors"`
+	NotificationChannels []ExportChannel              `json:"notification_channels"`
+	OncallSchedules      []*storage.OncallSchedule    `json:"oncall_schedules,omitempty"`
+	MonitorGroups        []*storage.MonitorGroup      `json:"monitor_groups"`
+	MaintenanceWindows   []*storage.MaintenanceWindow `json:"maintenance_windows"`
+	Proxies              []ExportProxy                `json:"proxies"`
+	Probes               []ExportProbe                `json:"probes,omitempty"`
+	StatusPages          []ExportStatusPage           `json:"status_pages"`
 }
 
 type ExportMonitor struct {
-	Name                     string          `json:"name"`
-	Description              string          `json:"description,omitempty"`
-	Type                     string          `json:"type"`
-	Target                   string          `json:"target"`
-	Interval                 int             `json:"interval"`
-	Timeout                  int             `json:"timeout"`
-	Enabled                  bool            `json:"enabled"`
+	Name                     string             `json:"name"`
+	Description              string             `json:"description,omitempty"`
+	Owner                    string             `json:"owner,omitempty"`
+	Type                     string             `json:"type"`
+	Target                   string             `json:"target"`
+	Interval                 int                `json:"interval"`
+	Timeout                  int                `json:"timeout"`
+	ConnectTimeout           int                `json:"connect_timeout,omitempty"`
+	Enabled                  bool               `json:"enabled"`
 	Tags                     []ExportMonitorTag `json:"tags,omitempty"`
-	Settings                 json.RawMessage `json:"settings,omitempty"`
-	Assertions               json.RawMessage `json:"assertions,omitempty"`
-	TrackChanges             bool            `json:"track_changes,omitempty"`
-	FailureThreshold         int             `json:"failure_threshold"`
-	SuccessThreshold         int             `json:"success_threshold"`
-	UpsideDown               bool            `json:"upside_down,omitempty"`
-	ResendInterval           int             `json:"resend_interval,omitempty"`
-	GroupName                string          `json:"group_name,omitempty"`
-	ProxyName                string          `json:"proxy_name,omitempty"`
-	NotificationChannelNames []string        `json:"notification_channel_names,omitempty"`
+	Settings                 json.RawMessage    `json:"settings,omitempty"`
+	Assertions               json.RawMessage    `json:"assertions,omitempty"`
+	TrackChanges             bool               `json:"track_changes,omitempty"`
+	FailureThreshold         int                `json:"failure_threshold"`
+	SuccessThreshold         int                `json:"success_threshold"`
+	UpsideDown               bool               `json:"upside_down,omitempty"`
+	ResendInterval           int                `json:"resend_interval,omitempty"`
+	AckSilencesReminders     *bool              `json:"ack_silences_reminders,omitempty"`
+	LatencyBaselineSigma     float64            `json:"latency_baseline_sigma,omitempty"`
+	SkipDefaultChannel       bool               `json:"skip_default_channel,omitempty"`
+	CaptureFailureContext    bool               `json:"capture_failure_context,omitempty"`
+	StreamChecksEvery        int                `json:"stream_checks_every,omitempty"`
+	Retries                  int                `json:"retries,omitempty"`
+	RetryInterval            int                `json:"retry_interval,omitempty"`
+	RedactPatterns           []string           `json:"redact_patterns,omitempty"`
+	DegradedThreshold        int                `json:"degraded_threshold,omitempty"`
+	FlapWindowSeconds        int                `json:"flap_window_seconds,omitempty"`
+	MaxResponseTimeMs        int                `json:"max_response_time_ms,omitempty"`
+	WarmupSeconds            int                `json:"warmup_seconds,omitempty"`
+	GroupName                string             `json:"group_name,omitempty"`
+	ParentName               string             `json:"parent_name,omitempty"`
+	ProxyName                string             `json:"proxy_name,omitempty"`
+	NotificationChannelNames []string           `json:"notification_channel_names,omitempty"`
+	ProbeNames               []string           `json:"probe_names,omitempty"`
+	// NotificationFilters holds the per-channel filters keyed by channel name.
+	NotificationFilters map[string]*storage.NotificationFilter `json:"notification_filters,omitempty"`
 }
 
 type ExportMonitorTag struct {
@@ -51,6 +80,21 @@ type ExportMonitorTag struct {
 	Value   string `json:"value,omitempty"`
 }
 
+// ExportChannel is a notification channel with its on-call schedule and
+// tags referenced by name.
+type ExportChannel struct {
+	Name                 string          `json:"name"`
+	Type                 string          `json:"type"`
+	Enabled              bool            `json:"enabled"`
+	Settings             json.RawMessage `json:"settings"`
+	Events               []string        `json:"events"`
+	Schedule             json.RawMessage `json:"schedule,omitempty"`
+	TagNames             []string        `json:"tag_names,omitempty"`
+	OncallScheduleName   string          `json:"oncall_schedule_name,omitempty"`
+	DigestWindowSeconds  int             `json:"digest_window_seconds,omitempty"`
+	DigestFlushOnResolve bool            `json:"digest_flush_on_resolve,omitempty"`
+}
+
 type ExportProxy struct {
 	Name     string `json:"name"`
 	Protocol string `json:"protocol"`
@@ -61,6 +105,15 @@ type ExportProxy struct {
 	Enabled  bool   `json:"enabled"`
 }
 
+type ExportProbe struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	SourceAddr string `json:"source_addr,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Token      string `json:"token,omitempty"`
+	Enabled    bool   `json:"enabled"`
+}
+
 type ExportStatusPage struct {
 	Slug          string                `json:"slug"`
 	Title         string                `json:"title"`
@@ -70,7 +123,9 @@ type ExportStatusPage struct {
 	Enabled       bool                  `json:"enabled"`
 	APIEnabled    bool                  `json:"api_enabled"`
 	SortOrder     int                   `json:"sort_order"`
+	APITokenHash  string                `json:"api_token_hash,omitempty"`
 	Monitors      []ExportStatusPageMon `json:"monitors,omitempty"`
+	Components    []ExportComponent     `json:"components,omitempty"`
 }
 
 type ExportStatusPageMon struct {
@@ -79,15 +134,29 @@ type ExportStatusPageMon struct {
 	GroupName   string `json:"group_name,omitempty"`
 }
 
+type ExportComponent struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	SortOrder   int                  `json:"sort_order"`
+	Monitors    []ExportComponentMon `json:"monitors,omitempty"`
+}
+
+type ExportComponentMon struct {
+	MonitorName string `json:"monitor_name"`
+	Weight      int    `json:"weight"`
+}
+
 type ImportStats struct {
-	Groups      int `json:"groups_created"`
-	Proxies     int `json:"proxies_created"`
-	Channels    int `json:"channels_created"`
-	Monitors    int `json:"monitors_created"`
-	Maintenance int `json:"maintenance_created"`
-	StatusPages int `json:"status_pages_created"`
-	Skipped     int `json:"skipped"`
-	Errors      int `json:"errors"`
+	Groups          int `json:"groups_created"`
+	Proxies         int `json:"proxies_created"`
+	Probes          int `json:"probes_created"`
+	OncallSchedules int `json:"oncall_schedules_created"`
+	Channels        int `json:"channels_created"`
+	Monitors        int `json:"monitors_created"`
+	Maintenance     int `json:"maintenance_created"`
+	StatusPages     int `json:"status_pages_created"`
+	Skipped         int `json:"skipped"`
+	Errors          int `json:"errors"`
 }
 
 // BuildExportData assembles a full configuration export from the store.
@@ -119,16 +188,45 @@ func BuildExportData(ctx context.Context, store storage.Store, redact bool) (*Ex
 		channelMap[ch.ID] = ch.Name
 	}
 
+	probes, err := store.ListProbes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list probes: %w", err)
+	}
+	probeMap := make(map[int64]string, len(probes))
+	for _, p := range probes {
+		probeMap[p.ID] = p.Name
+	}
+
+	schedules, err := store.ListOncallSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list on-call schedules: %w", err)
+	}
+	scheduleMap := make(map[int64]string, len(schedules))
+	for _, sc := range schedules {
+		scheduleMap[sc.ID] = sc.Name
+	}
+
+	tags, err := store.ListTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	tagMap := make(map[int64]string, len(tags))
+	for _, t := range tags {
+		tagMap[t.ID] = t.Name
+	}
+
 	result, err := store.ListMonitors(ctx, storage.MonitorListFilter{}, storage.Pagination{Page: 1, PerPage: 10000})
 	if err != nil {
 		return nil, fmt.Errorf("list monitors: %w", err)
 	}
 	monitors := result.Data.([]*storage.Monitor)
 
-	exportMonitors := buildExportMonitors(ctx, store, monitors, groupMap, proxyMap, channelMap)
-	exportPages := buildExportStatusPages(ctx, store, monitors)
+	exportMonitors := buildExportMonitors(ctx, store, monitors, groupMap, proxyMap, channelMap, probeMap)
+	exportPages := buildExportStatusPages(ctx, store, monitors, redact)
 	exportProxies := buildExportProxies(proxies, redact)
-	exportChannels := buildExportChannels(channels, redact)
+	exportProbes := buildExportProbes(probes, redact)
+	exportChannels := buildExportChannels(channels, scheduleMap, tagMap, redact)
+	exportSchedules := buildExportOncallSchedules(schedules, redact)
 	exportGroups := buildExportGroups(groups)
 
 	mw, _ := store.ListMaintenanceWindows(ctx)
@@ -139,42 +237,127 @@ func BuildExportData(ctx context.Context, store storage.Store, redact bool) (*Ex
 		ExportedAt:           time.Now().UTC(),
 		Monitors:             exportMonitors,
 		NotificationChannels: exportChannels,
+		OncallSchedules:      exportSchedules,
 		MonitorGroups:        exportGroups,
 		MaintenanceWindows:   exportMW,
 		Proxies:              exportProxies,
+		Probes:               exportProbes,
 		StatusPages:          exportPages,
 	}, nil
 }
 
+// BuildMonitorExport exports a single monitor in the full export format, so
+// the result can be passed to the import endpoint unchanged. Group, proxy,
+// probe and notification channel references are exported by name.
+func BuildMonitorExport(ctx context.Context, store storage.Store, id int64) (*ExportData, error) {
+	m, err := store.GetMonitor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	groupMap := make(map[int64]string)
+	if m.GroupID != nil {
+		if g, err := store.GetMonitorGroup(ctx, *m.GroupID); err == nil {
+			groupMap[g.ID] = g.Name
+		}
+	}
+	proxyMap := make(map[int64]string)
+	if m.ProxyID != nil {
+		if p, err := store.GetProxy(ctx, *m.ProxyID); err == nil {
+			proxyMap[p.ID] = p.Name
+		}
+	}
+	channels, err := store.ListNotificationChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list notification channels: %w", err)
+	}
+	channelMap := make(map[int64]string, len(channels))
+	for _, ch := range channels {
+		channelMap[ch.ID] = ch.Name
+	}
+	probes, err := store.ListProbes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list probes: %w", err)
+	}
+	probeMap := make(map[int64]string, len(probes))
+	for _, p := range probes {
+		probeMap[p.ID] = p.Name
+	}
+
+	return &ExportData{
+		Version:              1,
+		ExportedAt:           time.Now().UTC(),
+		Monitors:             buildExportMonitors(ctx, store, []*storage.Monitor{m}, groupMap, proxyMap, channelMap, probeMap),
+		NotificationChannels: []ExportChannel{},
+		MonitorGroups:        []*storage.MonitorGroup{},
+		MaintenanceWindows:   []*storage.MaintenanceWindow{},
+		Proxies:              []ExportProxy{},
+		StatusPages:          []ExportStatusPage{},
+	}, nil
+}
+
 func buildExportMonitors(ctx context.Context, store storage.Store, monitors []*storage.Monitor,
-	groupMap, proxyMap, channelMap map[int64]string) []ExportMonitor {
+	groupMap, proxyMap, channelMap, probeMap map[int64]string) []ExportMonitor {
 	monIDs := make([]int64, len(monitors))
 	for i, m := range monitors {
 		monIDs[i] = m.ID
 	}
 	tagMap, _ := store.GetMonitorTagsBatch(ctx, monIDs)
 
+	// Parents are exported by name; one outside monitors is looked up.
+	monitorNames := make(map[int64]string, len(monitors))
+	for _, m := range monitors {
+		monitorNames[m.ID] = m.Name
+	}
+	parentName := func(id int64) string {
+		if name, ok := monitorNames[id]; ok {
+			return name
+		}
+		if p, err := store.GetMonitor(ctx, id); err == nil {
+			monitorNames[id] = p.Name
+			return p.Name
+		}
+		return ""
+	}
+
 	var out []ExportMonitor
 	for _, m := range monitors {
 		em := ExportMonitor{
-			Name:             m.Name,
-			Description:      m.Description,
-			Type:             m.Type,
-			Target:           m.Target,
-			Interval:         m.Interval,
-			Timeout:          m.Timeout,
-			Enabled:          m.Enabled,
-			Settings:         m.Settings,
-			Assertions:       m.Assertions,
-			TrackChanges:     m.TrackChanges,
-			FailureThreshold: m.FailureThreshold,
-			SuccessThreshold: m.SuccessThreshold,
-			UpsideDown:       m.UpsideDown,
-			ResendInterval:   m.ResendInterval,
+			Name:                  m.Name,
+			Description:           m.Description,
+			Owner:                 m.Owner,
+			Type:                  m.Type,
+			Target:                m.Target,
+			Interval:              m.Interval,
+			Timeout:               m.Timeout,
+			ConnectTimeout:        m.ConnectTimeout,
+			Enabled:               m.Enabled,
+			Settings:              m.Settings,
+			Assertions:            m.Assertions,
+			TrackChanges:          m.TrackChanges,
+			FailureThreshold:      m.FailureThreshold,
+			SuccessThreshold:      m.SuccessThreshold,
+			UpsideDown:            m.UpsideDown,
+			ResendInterval:        m.ResendInterval,
+			AckSilencesReminders:  m.AckSilencesReminders,
+			LatencyBaselineSigma:  m.LatencyBaselineSigma,
+			SkipDefaultChannel:    m.SkipDefaultChannel,
+			CaptureFailureContext: m.CaptureFailureContext,
+			StreamChecksEvery:     m.StreamChecksEvery,
+			Retries:               m.Retries,
+			RetryInterval:         m.RetryInterval,
+			RedactPatterns:        m.RedactPatterns,
+			DegradedThreshold:     m.DegradedThreshold,
+			FlapWindowSeconds:     m.FlapWindowSeconds,
+			MaxResponseTimeMs:     m.MaxResponseTimeMs,
+			WarmupSeconds:         m.WarmupSeconds,
 		}
 		if m.GroupID != nil {
 			em.GroupName = groupMap[*m.GroupID]
 		}
+		if m.ParentID != nil {
+			em.ParentName = parentName(*m.ParentID)
+		}
 		if m.ProxyID != nil {
 			em.ProxyName = proxyMap[*m.ProxyID]
 		}
@@ -184,6 +367,21 @@ func buildExportMonitors(ctx context.Context, store storage.Store, monitors []*s
 				em.NotificationChannelNames = append(em.NotificationChannelNames, name)
 			}
 		}
+		probeIDs, _ := store.GetMonitorProbeIDs(ctx, m.ID)
+		for _, pid := range probeIDs {
+			if name, ok := probeMap[pid]; ok {
+				em.ProbeNames = append(em.ProbeNames, name)
+			}
+		}
+		filters, _ := store.GetMonitorNotificationFilters(ctx, m.ID)
+		for chID, f := range filters {
+			if name, ok := channelMap[chID]; ok {
+				if em.NotificationFilters == nil {
+					em.NotificationFilters = make(map[string]*storage.NotificationFilter)
+				}
+				em.NotificationFilters[name] = f
+			}
+		}
 		for _, mt := range tagMap[m.ID] {
 			em.Tags = append(em.Tags, ExportMonitorTag{
 				TagName: mt.Name,
@@ -196,7 +394,7 @@ func buildExportMonitors(ctx context.Context, store storage.Store, monitors []*s
 	return out
 }
 
-func buildExportStatusPages(ctx context.Context, store storage.Store, monitors []*storage.Monitor) []ExportStatusPage {
+func buildExportStatusPages(ctx context.Context, store storage.Store, monitors []*storage.Monitor, redact bool) []ExportStatusPage {
 	monIDToName := make(map[int64]string, len(monitors))
 	for _, m := range monitors {
 		monIDToName[m.ID] = m.Name
@@ -215,6 +413,9 @@ func buildExportStatusPages(ctx context.Context, store storage.Store, monitors [
 			APIEnabled:    sp.APIEnabled,
 			SortOrder:     sp.SortOrder,
 		}
+		if !redact {
+			ep.APITokenHash = sp.APITokenHash
+		}
 		spMons, _ := store.ListStatusPageMonitors(ctx, sp.ID)
 		for _, spm := range spMons {
 			ep.Monitors = append(ep.Monitors, ExportStatusPageMon{
@@ -223,6 +424,17 @@ func buildExportStatusPages(ctx context.Context, store storage.Store, monitors [
 				GroupName:   spm.GroupName,
 			})
 		}
+		comps, _ := store.ListStatusPageComponents(ctx, sp.ID)
+		for _, c := range comps {
+			ec := ExportComponent{Name: c.Name, Description: c.Description, SortOrder: c.SortOrder}
+			for _, cm := range c.Monitors {
+				ec.Monitors = append(ec.Monitors, ExportComponentMon{
+					MonitorName: monIDToName[cm.MonitorID],
+					Weight:      cm.Weight,
+				})
+			}
+			ep.Components = append(ep.Components, ec)
+		}
 		out = append(out, ep)
 	}
 	return out
@@ -247,19 +459,77 @@ func buildExportProxies(proxies []*storage.Proxy, redact bool) []ExportProxy {
 	return out
 }
 
-func buildExportChannels(channels []*storage.NotificationChannel, redact bool) []*storage.NotificationChannel {
-	out := make([]*storage.NotificationChannel, len(channels))
+func buildExportProbes(probes []*storage.Probe, redact bool) []ExportProbe {
+	var out []ExportProbe
+	for _, p := range probes {
+		ep := ExportProbe{
+			Name:       p.Name,
+			Type:       p.Type,
+			SourceAddr: p.SourceAddr,
+			URL:        p.URL,
+			Enabled:    p.Enabled,
+		}
+		if !redact {
+			ep.Token = p.Token
+		}
+		out = append(out, ep)
+	}
+	return out
+}
+
+func buildExportChannels(channels []*storage.NotificationChannel, scheduleMap, tagMap map[int64]string, redact bool) []ExportChannel {
+	out := make([]ExportChannel, len(channels))
 	for i, ch := range channels {
 		settings := ch.Settings
 		if redact {
 			settings = json.RawMessage(`{}`)
 		}
-		out[i] = &storage.NotificationChannel{
-			Name:     ch.Name,
-			Type:     ch.Type,
-			Enabled:  ch.Enabled,
-			Settings: settings,
-			Events:   ch.Events,
+		out[i] = ExportChannel{
+			Name:                 ch.Name,
+			Type:                 ch.Type,
+			Enabled:              ch.Enabled,
+			Settings:             settings,
+			Events:               ch.Events,
+			Schedule:             ch.Schedule,
+			DigestWindowSeconds:  ch.DigestWindowSeconds,
+			DigestFlushOnResolve: ch.DigestFlushOnResolve,
+		}
+		if ch.OncallScheduleID != nil {
+			out[i].OncallScheduleName = scheduleMap[*ch.OncallScheduleID]
+		}
+		for _, id := range ch.TagIDs {
+			if name, ok := tagMap[id]; ok {
+				out[i].TagNames = append(out[i].TagNames, name)
+			}
+		}
+	}
+	return out
+}
+
+// buildExportOncallSchedules copies schedules without their IDs. Redacting
+// drops member PagerDuty routing keys, which are integration secrets.
+func buildExportOncallSchedules(schedules []*storage.OncallSchedule, redact bool) []*storage.OncallSchedule {
+	out := make([]*storage.OncallSchedule, len(schedules))
+	for i, sc := range schedules {
+		members := make([]storage.OncallMember, len(sc.Members))
+		copy(members, sc.Members)
+		overrides := make([]storage.OncallOverride, len(sc.Overrides))
+		copy(overrides, sc.Overrides)
+		if redact {
+			for j := range members {
+				members[j].RoutingKey = ""
+			}
+			for j := range overrides {
+				overrides[j].Member.RoutingKey = ""
+			}
+		}
+		out[i] = &storage.OncallSchedule{
+			Name:          sc.Name,
+			Timezone:      sc.Timezone,
+			RotationStart: sc.RotationStart,
+			ShiftHours:    sc.ShiftHours,
+			Members:       members,
+			Overrides:     overrides,
 		}
 	}
 	return out
@@ -280,11 +550,14 @@ func buildExportMaintenance(mw []*storage.MaintenanceWindow) []*storage.Maintena
 	out := make([]*storage.MaintenanceWindow, len(mw))
 	for i, m := range mw {
 		out[i] = &storage.MaintenanceWindow{
-			Name:       m.Name,
-			MonitorIDs: m.MonitorIDs,
-			StartTime:  m.StartTime,
-			EndTime:    m.EndTime,
-			Recurring:  m.Recurring,
+			Name:            m.Name,
+			MonitorIDs:      m.MonitorIDs,
+			StartTime:       m.StartTime,
+			EndTime:         m.EndTime,
+			Recurring:       m.Recurring,
+			Timezone:        m.Timezone,
+			CronExpr:        m.CronExpr,
+			DurationMinutes: m.DurationMinutes,
 		}
 	}
 	return out
@@ -297,7 +570,9 @@ type importCtx struct {
 	mode            string
 	groupNameToID   map[string]int64
 	proxyNameToID   map[string]int64
+	probeNameToID   map[string]int64
 	channelNameToID map[string]int64
+	oncallNameToID  map[string]int64
 	monitorNameToID map[string]int64
 }
 
@@ -310,12 +585,16 @@ func RunImport(ctx context.Context, store storage.Store, logger *slog.Logger, da
 		mode:            mode,
 		groupNameToID:   make(map[string]int64),
 		proxyNameToID:   make(map[string]int64),
+		probeNameToID:   make(map[string]int64),
 		channelNameToID: make(map[string]int64),
+		oncallNameToID:  make(map[string]int64),
 		monitorNameToID: make(map[string]int64),
 	}
 
 	importGroups(ctx, ic, data.MonitorGroups, stats)
 	importProxies(ctx, ic, data.Proxies, stats)
+	importProbes(ctx, ic, data.Probes, stats)
+	importOncallSchedules(ctx, ic, data.OncallSchedules, stats)
 	importChannels(ctx, ic, data.NotificationChannels, stats)
 	importMonitors(ctx, ic, data.Monitors, stats)
 	importMaintenance(ctx, ic, data.MaintenanceWindows, stats)
@@ -381,7 +660,71 @@ func importProxies(ctx context.Context, ic *importCtx, proxies []ExportProxy, st
 	}
 }
 
-func importChannels(ctx context.Context, ic *importCtx, channels []*storage.NotificationChannel, stats *ImportStats) {
+func importProbes(ctx context.Context, ic *importCtx, probes []ExportProbe, stats *ImportStats) {
+	existing, err := ic.store.ListProbes(ctx)
+	if err != nil {
+		ic.logger.Error("import: list probes", "error", err)
+		stats.Errors += len(probes)
+		return
+	}
+	for _, p := range existing {
+		ic.probeNameToID[p.Name] = p.ID
+	}
+	for _, p := range probes {
+		if _, exists := ic.probeNameToID[p.Name]; exists && ic.mode == "merge" {
+			stats.Skipped++
+			continue
+		}
+		np := &storage.Probe{
+			Name: p.Name, Type: p.Type, SourceAddr: p.SourceAddr,
+			URL: p.URL, Token: p.Token, Enabled: p.Enabled,
+		}
+		if err := validate.ValidateProbe(np); err != nil {
+			stats.Errors++
+			continue
+		}
+		if err := ic.store.CreateProbe(ctx, np); err != nil {
+			stats.Errors++
+			continue
+		}
+		ic.probeNameToID[np.Name] = np.ID
+		stats.Probes++
+	}
+}
+
+func importOncallSchedules(ctx context.Context, ic *importCtx, schedules []*storage.OncallSchedule, stats *ImportStats) {
+	existing, err := ic.store.ListOncallSchedules(ctx)
+	if err != nil {
+		ic.logger.Error("import: list on-call schedules", "error", err)
+		stats.Errors += len(schedules)
+		return
+	}
+	for _, sc := range existing {
+		ic.oncallNameToID[sc.Name] = sc.ID
+	}
+	for _, sc := range schedules {
+		if _, exists := ic.oncallNameToID[sc.Name]; exists && ic.mode == "merge" {
+			stats.Skipped++
+			continue
+		}
+		nsc := &storage.OncallSchedule{
+			Name: sc.Name, Timezone: sc.Timezone, RotationStart: sc.RotationStart,
+			ShiftHours: sc.ShiftHours, Members: sc.Members, Overrides: sc.Overrides,
+		}
+		if err := validate.ValidateOncallSchedule(nsc); err != nil {
+			stats.Errors++
+			continue
+		}
+		if err := ic.store.CreateOncallSchedule(ctx, nsc); err != nil {
+			stats.Errors++
+			continue
+		}
+		ic.oncallNameToID[nsc.Name] = nsc.ID
+		stats.OncallSchedules++
+	}
+}
+
+func importChannels(ctx context.Context, ic *importCtx, channels []ExportChannel, stats *ImportStats) {
 	existing, err := ic.store.ListNotificationChannels(ctx)
 	if err != nil {
 		ic.logger.Error("import: list channels", "error", err)
@@ -398,17 +741,58 @@ func importChannels(ctx context.Context, ic *importCtx, channels []*storage.Noti
 		}
 		nch := &storage.NotificationChannel{
 			Name: ch.Name, Type: ch.Type, Enabled: ch.Enabled,
-			Settings: ch.Settings, Events: ch.Events,
+			Settings: ch.Settings, Events: ch.Events, Schedule: ch.Schedule,
+			DigestWindowSeconds: ch.DigestWindowSeconds, DigestFlushOnResolve: ch.DigestFlushOnResolve,
+		}
+		if ch.OncallScheduleName != "" {
+			if id, ok := ic.oncallNameToID[ch.OncallScheduleName]; ok {
+				nch.OncallScheduleID = &id
+			}
 		}
 		if err := ic.store.CreateNotificationChannel(ctx, nch); err != nil {
 			stats.Errors++
 			continue
 		}
+		if tagIDs := importChannelTags(ctx, ic, ch.TagNames); len(tagIDs) > 0 {
+			if err := ic.store.SetChannelTags(ctx, nch.ID, tagIDs); err != nil {
+				ic.logger.Error("import: set channel tags", "channel_id", nch.ID, "error", err)
+			}
+		}
 		ic.channelNameToID[nch.Name] = nch.ID
 		stats.Channels++
 	}
 }
 
+// importChannelTags resolves a channel's tag names, creating tags that don't
+// exist yet like importMonitorTags does.
+func importChannelTags(ctx context.Context, ic *importCtx, names []string) []int64 {
+	if len(names) == 0 {
+		return nil
+	}
+	allTags, err := ic.store.ListTags(ctx)
+	if err != nil {
+		ic.logger.Error("import: list tags", "error", err)
+		return nil
+	}
+	tagNameToID := make(map[string]int64, len(allTags))
+	for _, t := range allTags {
+		tagNameToID[t.Name] = t.ID
+	}
+	var ids []int64
+	for _, name := range names {
+		tid, ok := tagNameToID[name]
+		if !ok {
+			newTag := &storage.Tag{Name: name, Color: "#808080"}
+			if err := ic.store.CreateTag(ctx, newTag); err != nil {
+				continue
+			}
+			tid = newTag.ID
+		}
+		ids = append(ids, tid)
+	}
+	return ids
+}
+
 func importMonitors(ctx context.Context, ic *importCtx, monitors []ExportMonitor, stats *ImportStats) {
 	existing, err := ic.store.ListMonitors(ctx, storage.MonitorListFilter{}, storage.Pagination{Page: 1, PerPage: 10000})
 	if err != nil {
@@ -421,6 +805,9 @@ func importMonitors(ctx context.Context, ic *importCtx, monitors []ExportMonitor
 			ic.monitorNameToID[m.Name] = m.ID
 		}
 	}
+	// Parents are linked once every monitor exists, since a child may be
+	// listed before its parent.
+	parentNames := make(map[int64]string)
 	for _, em := range monitors {
 		if _, exists := ic.monitorNameToID[em.Name]; exists && ic.mode == "merge" {
 			stats.Skipped++
@@ -428,20 +815,57 @@ func importMonitors(ctx context.Context, ic *importCtx, monitors []ExportMonitor
 		}
 		if importSingleMonitor(ctx, ic, &em) {
 			stats.Monitors++
+			if em.ParentName != "" {
+				parentNames[ic.monitorNameToID[em.Name]] = em.ParentName
+			}
 		} else {
 			stats.Errors++
 		}
 	}
+	for id, name := range parentNames {
+		importMonitorParent(ctx, ic, id, name)
+	}
+}
+
+func importMonitorParent(ctx context.Context, ic *importCtx, id int64, parentName string) {
+	parentID, ok := ic.monitorNameToID[parentName]
+	if !ok {
+		ic.logger.Warn("import: parent monitor not found", "monitor_id", id, "parent", parentName)
+		return
+	}
+	parents, err := ic.store.ListMonitorParents(ctx)
+	if err != nil {
+		ic.logger.Error("import: list monitor parents", "error", err)
+		return
+	}
+	if err := validate.ValidateMonitorParent(id, &parentID, parents); err != nil {
+		ic.logger.Warn("import: skipping parent monitor", "monitor_id", id, "parent", parentName, "error", err)
+		return
+	}
+	m, err := ic.store.GetMonitor(ctx, id)
+	if err != nil {
+		ic.logger.Error("import: get monitor", "monitor_id", id, "error", err)
+		return
+	}
+	m.ParentID = &parentID
+	if err := ic.store.UpdateMonitor(ctx, m); err != nil {
+		ic.logger.Error("import: set parent monitor", "monitor_id", id, "error", err)
+	}
 }
 
 func importSingleMonitor(ctx context.Context, ic *importCtx, em *ExportMonitor) bool {
 	m := &storage.Monitor{
-		Name: em.Name, Description: em.Description, Type: em.Type, Target: em.Target,
-		Interval: em.Interval, Timeout: em.Timeout, Enabled: em.Enabled,
+		Name: em.Name, Description: em.Description, Owner: em.Owner, Type: em.Type, Target: em.Target,
+		Interval: em.Interval, Timeout: em.Timeout, ConnectTimeout: em.ConnectTimeout, Enabled: em.Enabled,
 		Settings: em.Settings, Assertions: em.Assertions,
 		TrackChanges: em.TrackChanges, FailureThreshold: em.FailureThreshold,
 		SuccessThreshold: em.SuccessThreshold, UpsideDown: em.UpsideDown,
-		ResendInterval: em.ResendInterval,
+		ResendInterval: em.ResendInterval, AckSilencesReminders: em.AckSilencesReminders,
+		LatencyBaselineSigma: em.LatencyBaselineSigma, SkipDefaultChannel: em.SkipDefaultChannel,
+		CaptureFailureContext: em.CaptureFailureContext, StreamChecksEvery: em.StreamChecksEvery,
+		Retries: em.Retries, RetryInterval: em.RetryInterval, RedactPatterns: em.RedactPatterns,
+		DegradedThreshold: em.DegradedThreshold, FlapWindowSeconds: em.FlapWindowSeconds,
+		MaxResponseTimeMs: em.MaxResponseTimeMs, WarmupSeconds: em.WarmupSeconds,
 	}
 	if em.GroupName != "" {
 		if gid, ok := ic.groupNameToID[em.GroupName]; ok {
@@ -470,6 +894,30 @@ func importSingleMonitor(ctx context.Context, ic *importCtx, em *ExportMonitor)
 	if len(chIDs) > 0 {
 		if err := ic.store.SetMonitorNotificationChannels(ctx, m.ID, chIDs); err != nil {
 			ic.logger.Error("import: set monitor channels", "monitor", m.Name, "error", err)
+		} else if len(em.NotificationFilters) > 0 {
+			filters := make(map[int64]*storage.NotificationFilter)
+			for chName, f := range em.NotificationFilters {
+				if chID, ok := ic.channelNameToID[chName]; ok {
+					filters[chID] = f
+				}
+			}
+			if err := validate.ValidateNotificationFilters(filters, chIDs); err != nil {
+				ic.logger.Warn("import: skipping monitor notification filters", "monitor", m.Name, "error", err)
+			} else if err := ic.store.SetMonitorNotificationFilters(ctx, m.ID, filters); err != nil {
+				ic.logger.Error("import: set monitor notification filters", "monitor", m.Name, "error", err)
+			}
+		}
+	}
+
+	var probeIDs []int64
+	for _, name := range em.ProbeNames {
+		if pid, ok := ic.probeNameToID[name]; ok {
+			probeIDs = append(probeIDs, pid)
+		}
+	}
+	if len(probeIDs) > 0 {
+		if err := ic.store.SetMonitorProbes(ctx, m.ID, probeIDs); err != nil {
+			ic.logger.Error("import: set monitor probes", "monitor", m.Name, "error", err)
 		}
 	}
 
@@ -518,6 +966,7 @@ func importMaintenance(ctx context.Context, ic *importCtx, windows []*storage.Ma
 		nmw := &storage.MaintenanceWindow{
 			Name: mw.Name, MonitorIDs: mw.MonitorIDs,
 			StartTime: mw.StartTime, EndTime: mw.EndTime, Recurring: mw.Recurring,
+			Timezone: mw.Timezone, CronExpr: mw.CronExpr, DurationMinutes: mw.DurationMinutes,
 		}
 		if err := ic.store.CreateMaintenanceWindow(ctx, nmw); err != nil {
 			stats.Errors++
@@ -533,6 +982,7 @@ func importStatusPages(ctx context.Context, ic *importCtx, pages []ExportStatusP
 			Slug: esp.Slug, Title: esp.Title, Description: esp.Description,
 			CustomCSS: esp.CustomCSS, ShowIncidents: esp.ShowIncidents,
 			Enabled: esp.Enabled, APIEnabled: esp.APIEnabled, SortOrder: esp.SortOrder,
+			APITokenHash: esp.APITokenHash,
 		}
 		if err := validate.ValidateStatusPage(nsp); err != nil {
 			stats.Errors++
@@ -556,12 +1006,130 @@ func importStatusPages(ctx context.Context, ic *importCtx, pages []ExportStatusP
 				ic.logger.Error("import: set status page monitors", "page", nsp.Slug, "error", err)
 			}
 		}
+		var comps []storage.StatusPageComponent
+		for _, ec := range esp.Components {
+			c := storage.StatusPageComponent{Name: ec.Name, Description: ec.Description, SortOrder: ec.SortOrder}
+			for _, ecm := range ec.Monitors {
+				if mid, ok := ic.monitorNameToID[ecm.MonitorName]; ok {
+					c.Monitors = append(c.Monitors, storage.StatusPageComponentMonitor{MonitorID: mid, Weight: ecm.Weight})
+				}
+			}
+			comps = append(comps, c)
+		}
+		if len(comps) > 0 && validate.ValidateStatusPageComponents(comps) == nil {
+			if err := ic.store.SetStatusPageComponents(ctx, nsp.ID, comps); err != nil {
+				ic.logger.Error("import: set status page components", "page", nsp.Slug, "error", err)
+			}
+		}
 		stats.StatusPages++
 	}
 }
 
+// MarshalExportYAML renders an export as YAML. It goes through the JSON
+// encoding so keys match the JSON format and raw settings and assertions
+// come out as nested YAML rather than strings.
+func MarshalExportYAML(data *ExportData) ([]byte, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is valid YAML; parsing it keeps the field order, and clearing
+	// the flow and quoting styles lets the encoder pick block style.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	clearYAMLStyle(&doc)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	enc.Close()
+	return buf.Bytes(), nil
+}
+
+func clearYAMLStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		clearYAMLStyle(c)
+	}
+}
+
+// ExportYAMLToJSON converts a YAML export to JSON so it can be decoded into
+// ExportData like a JSON upload, including the raw settings and assertions.
+func ExportYAMLToJSON(body []byte) ([]byte, error) {
+	var v any
+	if err := yaml.Unmarshal(body, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// IsYAMLExport reports whether an uploaded export is YAML, going by its
+// content type or file name and otherwise by whether it starts like JSON.
+func IsYAMLExport(contentType, filename string, body []byte) bool {
+	if isYAMLContentType(contentType) {
+		return true
+	}
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".yaml", ".yml":
+		return true
+	case ".json":
+		return false
+	}
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] != '{'
+}
+
+func isYAMLContentType(contentType string) bool {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch mt {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
+
+// WriteExport writes an export as an attachment named name plus the format's
+// extension, in YAML when format is "yaml" and JSON otherwise.
+func WriteExport(w http.ResponseWriter, data *ExportData, name, format string) error {
+	if format == "yaml" {
+		b, err := MarshalExportYAML(data)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/x-yaml")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.yaml"`, name))
+		w.Write(b)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, name))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+func exportFormat(r *http.Request) (string, error) {
+	switch f := r.URL.Query().Get("format"); f {
+	case "", "json":
+		return "json", nil
+	case "yaml":
+		return f, nil
+	default:
+		return "", errors.New("format must be 'json' or 'yaml'")
+	}
+}
+
 func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
 	redact := r.URL.Query().Get("redact_secrets") == "true"
+	format, err := exportFormat(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	data, err := BuildExportData(r.Context(), h.store, redact)
 	if err != nil {
@@ -569,15 +1137,48 @@ func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Content-Disposition", `attachment; filename="asura-export.json"`)
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	enc.Encode(data)
+	if err := WriteExport(w, data, "asura-export", format); err != nil {
+		h.logger.Error("export", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to encode export data")
+		return
+	}
 
 	h.audit(r, "export", "config", 0, "")
 }
 
+// ExportMonitor returns one monitor's configuration in the import format.
+func (h *Handler) ExportMonitor(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	format, err := exportFormat(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, err := BuildMonitorExport(r.Context(), h.store, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "monitor not found")
+			return
+		}
+		h.logger.Error("export monitor", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to build export data")
+		return
+	}
+
+	if err := WriteExport(w, data, fmt.Sprintf("asura-monitor-%d", id), format); err != nil {
+		h.logger.Error("export monitor", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to encode export data")
+		return
+	}
+
+	h.audit(r, "export", "monitor", id, "")
+}
+
 func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
 	mode := r.URL.Query().Get("mode")
 	if mode == "" {
@@ -589,7 +1190,11 @@ func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var data ExportData
-	if err := readJSON(r, &data); err != nil {
+	read := readJSON
+	if isYAMLContentType(r.Header.Get("Content-Type")) || r.URL.Query().Get("format") == "yaml" {
+		read = readYAML
+	}
+	if err := read(r, &data); err != nil {
 		writeError(w, 400, err.Error())
 		return
 	}
@@ -610,3 +1215,26 @@ func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
 	h.audit(r, "import", "config", 0, fmt.Sprintf("mode=%s", mode))
 	writeJSON(w, 200, stats)
 }
+
+// readYAML decodes a YAML request body into v with the same strictness as
+// readJSON.
+func readYAML(r *http.Request, v any) error {
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return fmt.Errorf("request body is empty")
+	}
+	b, err := ExportYAMLToJSON(body)
+	if err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	return nil
+}
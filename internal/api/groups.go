@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/y0f/asura/internal/httputil"
 	"github.com/y0f/asura/internal/storage"
@@ -17,6 +19,11 @@ func (h *Handler) ListGroups(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to list groups")
 		return
 	}
+	if scope := httputil.GetMonitorScope(r.Context()); scope != nil {
+		groups = slices.DeleteFunc(groups, func(g *storage.MonitorGroup) bool {
+			return !slices.Contains(scope.GroupIDs, g.ID)
+		})
+	}
 	writeJSON(w, http.StatusOK, map[string]any{"data": groups})
 }
 
@@ -109,3 +116,150 @@ func (h *Handler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
 	h.audit(r, "delete", "monitor_group", id, "")
 	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
+
+// GroupStatus reports the status rollup of a group's monitors.
+func (h *Handler) GroupStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	gs, err := h.store.GetGroupStatus(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "group not found")
+			return
+		}
+		h.logger.Error("get group status", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get group status")
+		return
+	}
+	writeJSON(w, http.StatusOK, gs)
+}
+
+// GroupUptime reports a group's combined uptime between from and to
+// (RFC3339), by default over the previous calendar month. mode is all (the
+// default) or any.
+func (h *Handler) GroupUptime(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := r.URL.Query()
+	mode := q.Get("mode")
+	switch mode {
+	case "":
+		mode = storage.GroupUptimeAll
+	case storage.GroupUptimeAll, storage.GroupUptimeAny:
+	default:
+		writeError(w, http.StatusBadRequest, "mode must be all or any")
+		return
+	}
+	from, to := httputil.PreviousMonth(time.Now())
+	if f := q.Get("from"); f != "" {
+		if from, err = time.Parse(time.RFC3339, f); err != nil {
+			writeError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if t := q.Get("to"); t != "" {
+		if to, err = time.Parse(time.RFC3339, t); err != nil {
+			writeError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if !to.After(from) {
+		writeError(w, http.StatusBadRequest, "to must be after from")
+		return
+	}
+
+	gu, err := h.store.GetGroupUptime(r.Context(), id, from, to, mode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "group not found")
+			return
+		}
+		h.logger.Error("get group uptime", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get group uptime")
+		return
+	}
+	writeJSON(w, http.StatusOK, gu)
+}
+
+// GroupSLA reports the combined SLA of a group's monitors between from and
+// to (RFC3339), by default over the previous calendar month.
+func (h *Handler) GroupSLA(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	from, to := httputil.PreviousMonth(time.Now())
+	if f := r.URL.Query().Get("from"); f != "" {
+		if from, err = time.Parse(time.RFC3339, f); err != nil {
+			writeError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if t := r.URL.Query().Get("to"); t != "" {
+		if to, err = time.Parse(time.RFC3339, t); err != nil {
+			writeError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if !to.After(from) {
+		writeError(w, http.StatusBadRequest, "to must be after from")
+		return
+	}
+
+	rep, err := h.store.GetGroupSLAReport(r.Context(), id, from, to)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "group not found")
+			return
+		}
+		h.logger.Error("get group sla report", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get sla report")
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+type groupUsage struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Monitors int64  `json:"monitors"`
+}
+
+// MonitorLimits reports the configured monitor limits alongside current usage.
+func (h *Handler) MonitorLimits(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	total, byGroup, err := h.store.CountMonitors(ctx)
+	if err != nil {
+		h.logger.Error("count monitors", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to count monitors")
+		return
+	}
+	groups, err := h.store.ListMonitorGroups(ctx)
+	if err != nil {
+		h.logger.Error("list groups", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list groups")
+		return
+	}
+
+	usage := make([]groupUsage, 0, len(groups))
+	for _, g := range groups {
+		usage = append(usage, groupUsage{ID: g.ID, Name: g.Name, Monitors: byGroup[g.ID]})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"max_monitors":           h.cfg.Monitor.MaxMonitors,
+		"max_monitors_per_group": h.cfg.Monitor.MaxMonitorsPerGroup,
+		"monitors":               total,
+		"groups":                 usage,
+	})
+}
@@ -61,6 +61,19 @@ func readJSON(r *http.Request, v any) error {
 	return nil
 }
 
+// readOptionalJSON is readJSON for endpoints whose body may be omitted: an
+// empty body leaves v untouched instead of failing. Chunked requests report
+// no ContentLength, so emptiness is decided by the decoder reaching EOF.
+func readOptionalJSON(r *http.Request, v any) error {
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
+
 func (h *Handler) audit(r *http.Request, action, entity string, entityID int64, detail string) {
 	entry := &storage.AuditEntry{
 		Action:     action,
@@ -1,13 +1,139 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"time"
 )
 
+const (
+	// healthPingTimeout bounds the database ping so a hung database fails
+	// the probe instead of stalling it.
+	healthPingTimeout = 2 * time.Second
+	// schedulerStaleAfter is how long the scheduler, which runs every second,
+	// may go without a pass before it is reported as stalled.
+	schedulerStaleAfter = 30 * time.Second
+)
+
+// Health component and overall statuses.
+const (
+	healthOK          = "ok"
+	healthDegraded    = "degraded"
+	healthUnavailable = "unavailable"
+)
+
+type healthResponse struct {
+	Status string       `json:"status"`
+	Uptime string       `json:"uptime"`
+	Checks healthChecks `json:"checks"`
+}
+
+type healthChecks struct {
+	Database      databaseHealth      `json:"database"`
+	Scheduler     *schedulerHealth    `json:"scheduler,omitempty"`
+	Notifications *notificationHealth `json:"notifications,omitempty"`
+}
+
+type databaseHealth struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+type schedulerHealth struct {
+	Status           string     `json:"status"`
+	LastRun          *time.Time `json:"last_run"`
+	LastDispatch     *time.Time `json:"last_dispatch"`
+	QueuedJobs       int        `json:"queued_jobs"`
+	JobQueueCapacity int        `json:"job_queue_capacity"`
+}
+
+type notificationHealth struct {
+	Status   string `json:"status"`
+	Queued   int    `json:"queued"`
+	Capacity int    `json:"capacity"`
+	Dropped  int64  `json:"dropped"`
+}
+
+// Health is the readiness probe. It is public, so it reports only the
+// overall status and the uptime, answering 503 when the database is
+// unreachable. Stalled components make the status degraded; HealthDetails
+// says which.
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]any{
-		"status": "ok",
-		"uptime": time.Since(h.startTime).String(),
-	})
+	resp, code := h.checkHealth(r)
+	writeJSON(w, code, map[string]string{"status": resp.Status, "uptime": resp.Uptime})
+}
+
+// HealthDetails reports each component of Health with its queue counters.
+// It needs metrics.read.
+func (h *Handler) HealthDetails(w http.ResponseWriter, r *http.Request) {
+	resp, code := h.checkHealth(r)
+	writeJSON(w, code, resp)
+}
+
+// checkHealth pings the database and inspects the scheduler and notification
+// queue, returning the report and the status code to answer with.
+func (h *Handler) checkHealth(r *http.Request) (healthResponse, int) {
+	resp := healthResponse{
+		Status: healthOK,
+		Uptime: time.Since(h.startTime).String(),
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthPingTimeout)
+	start := time.Now()
+	err := h.store.Ping(ctx)
+	cancel()
+	resp.Checks.Database = databaseHealth{Status: healthOK, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		h.logger.Warn("health: database ping failed", "error", err)
+		resp.Checks.Database.Status = healthUnavailable
+		resp.Status = healthUnavailable
+	}
+
+	if h.pipeline != nil {
+		ph := h.pipeline.Health()
+		now := time.Now()
+		sched := &schedulerHealth{
+			Status:           healthOK,
+			LastRun:          timePtr(ph.LastRun),
+			LastDispatch:     timePtr(ph.LastDispatch),
+			QueuedJobs:       ph.QueuedJobs,
+			JobQueueCapacity: ph.JobQueueCapacity,
+		}
+		if now.Sub(h.startTime) > schedulerStaleAfter && now.Sub(ph.LastRun) > schedulerStaleAfter {
+			sched.Status = healthDegraded
+		}
+		notif := &notificationHealth{
+			Status:   healthOK,
+			Queued:   ph.QueuedNotifications,
+			Capacity: ph.NotificationQueueCapacity,
+			Dropped:  ph.DroppedNotifications,
+		}
+		if notif.Capacity > 0 && notif.Queued >= notif.Capacity {
+			notif.Status = healthDegraded
+		}
+		resp.Checks.Scheduler = sched
+		resp.Checks.Notifications = notif
+		if resp.Status == healthOK && (sched.Status != healthOK || notif.Status != healthOK) {
+			resp.Status = healthDegraded
+		}
+	}
+
+	code := http.StatusOK
+	if resp.Status == healthUnavailable {
+		code = http.StatusServiceUnavailable
+	}
+	return resp, code
+}
+
+// Livez is the liveness probe. It only shows the process is serving
+// requests and checks no dependencies.
+func (h *Handler) Livez(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": healthOK})
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
 }
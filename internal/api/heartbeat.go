@@ -4,25 +4,16 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+
+	"github.com/y0f/asura/internal/storage"
 )
 
 func (h *Handler) HeartbeatPing(w http.ResponseWriter, r *http.Request) {
-	token := r.PathValue("token")
-	if token == "" {
-		writeError(w, http.StatusBadRequest, "token is required")
-		return
-	}
-
-	hb, err := h.store.GetHeartbeatByToken(r.Context(), token)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			writeError(w, http.StatusNotFound, "heartbeat not found")
-			return
-		}
-		h.logger.Error("get heartbeat by token", "error", err)
-		writeError(w, http.StatusInternalServerError, "failed to get heartbeat")
+	hb, ok := h.heartbeatByToken(w, r)
+	if !ok {
 		return
 	}
+	token := hb.Token
 
 	if err := h.store.UpdateHeartbeatPing(r.Context(), token); err != nil {
 		h.logger.Error("update heartbeat ping", "error", err)
@@ -30,12 +21,69 @@ func (h *Handler) HeartbeatPing(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if hb.Status == "down" && h.pipeline != nil {
+	// A resumed heartbeat is pending, but its monitor may still be down
+	// from before the pause.
+	if (hb.Status == "down" || hb.Status == "pending") && h.pipeline != nil {
 		mon, err := h.store.GetMonitor(r.Context(), hb.MonitorID)
-		if err == nil && mon != nil {
+		if err == nil && mon != nil && (hb.Status == "down" || mon.Status == "down") {
 			h.pipeline.ProcessHeartbeatRecovery(r.Context(), mon)
 		}
 	}
 
 	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
+
+// HeartbeatPause stops expiry checking for a heartbeat, for example while
+// the job it watches is deliberately stopped. Pings are still recorded but
+// do not resume it.
+func (h *Handler) HeartbeatPause(w http.ResponseWriter, r *http.Request) {
+	hb, ok := h.heartbeatByToken(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.PauseHeartbeat(r.Context(), hb.Token); err != nil {
+		h.logger.Error("pause heartbeat", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to pause heartbeat")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "paused"})
+}
+
+// HeartbeatResume restarts expiry checking for a paused heartbeat. The next
+// ping is due one interval plus grace from now.
+func (h *Handler) HeartbeatResume(w http.ResponseWriter, r *http.Request) {
+	hb, ok := h.heartbeatByToken(w, r)
+	if !ok {
+		return
+	}
+	if hb.Status != "paused" {
+		writeJSON(w, http.StatusOK, map[string]string{"status": hb.Status})
+		return
+	}
+	if err := h.store.ResumeHeartbeat(r.Context(), hb.Token); err != nil {
+		h.logger.Error("resume heartbeat", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to resume heartbeat")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "pending"})
+}
+
+func (h *Handler) heartbeatByToken(w http.ResponseWriter, r *http.Request) (*storage.Heartbeat, bool) {
+	token := r.PathValue("token")
+	if token == "" {
+		writeError(w, http.StatusBadRequest, "token is required")
+		return nil, false
+	}
+
+	hb, err := h.store.GetHeartbeatByToken(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "heartbeat not found")
+			return nil, false
+		}
+		h.logger.Error("get heartbeat by token", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get heartbeat")
+		return nil, false
+	}
+	return hb, true
+}
@@ -1,10 +1,14 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"html"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/y0f/asura/internal/httputil"
@@ -62,6 +66,9 @@ func (h *Handler) GetIncident(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// AckIncident acknowledges an open incident. The optional body
+// {"ack_timeout_minutes": N} makes the acknowledgement lapse after N minutes
+// if the incident is still unresolved.
 func (h *Handler) AckIncident(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
@@ -69,6 +76,18 @@ func (h *Handler) AckIncident(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var req struct {
+		AckTimeoutMinutes int `json:"ack_timeout_minutes"`
+	}
+	if err := readOptionalJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validate.ValidateAckTimeout(req.AckTimeoutMinutes); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	inc, err := h.store.GetIncident(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -85,22 +104,33 @@ func (h *Handler) AckIncident(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.acknowledge(r, inc, req.AckTimeoutMinutes); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to acknowledge incident")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, inc)
+}
+
+// acknowledge marks inc acknowledged by the calling API key, records it on
+// the timeline and in the audit log, and notifies channels.
+func (h *Handler) acknowledge(r *http.Request, inc *storage.Incident, ackTimeoutMinutes int) error {
 	now := time.Now().UTC()
 	inc.Status = incident.StatusAcknowledged
 	inc.AcknowledgedAt = &now
 	inc.AcknowledgedBy = httputil.GetAPIKeyName(r.Context())
+	inc.AckDeadline = incident.AckDeadline(now, ackTimeoutMinutes)
 
 	if err := h.store.UpdateIncident(r.Context(), inc); err != nil {
 		h.logger.Error("ack incident", "error", err)
-		writeError(w, http.StatusInternalServerError, "failed to acknowledge incident")
-		return
+		return err
 	}
 
-	if err := h.store.InsertIncidentEvent(r.Context(), newIncidentEvent(inc.ID, incident.EventAcknowledged, "Acknowledged by "+inc.AcknowledgedBy)); err != nil {
+	if err := h.store.InsertIncidentEvent(r.Context(), newIncidentEvent(inc.ID, incident.EventAcknowledged, incident.AckMessage(inc.AcknowledgedBy, ackTimeoutMinutes))); err != nil {
 		h.logger.Error("insert ack event", "error", err)
 	}
 
-	h.audit(r, "acknowledge", "incident", id, "")
+	h.audit(r, "acknowledge", "incident", inc.ID, "")
 
 	if h.notifier != nil {
 		h.notifier.NotifyWithPayload(&notifier.Payload{
@@ -108,8 +138,7 @@ func (h *Handler) AckIncident(w http.ResponseWriter, r *http.Request) {
 			Incident:  inc,
 		})
 	}
-
-	writeJSON(w, http.StatusOK, inc)
+	return nil
 }
 
 func (h *Handler) ResolveIncident(w http.ResponseWriter, r *http.Request) {
@@ -135,22 +164,33 @@ func (h *Handler) ResolveIncident(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.resolve(r, inc); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to resolve incident")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, inc)
+}
+
+// resolve marks inc resolved by the calling API key, records it on the
+// timeline and in the audit log, and notifies channels.
+func (h *Handler) resolve(r *http.Request, inc *storage.Incident) error {
 	now := time.Now().UTC()
 	inc.Status = incident.StatusResolved
 	inc.ResolvedAt = &now
 	inc.ResolvedBy = httputil.GetAPIKeyName(r.Context())
+	inc.AckDeadline = nil
 
 	if err := h.store.UpdateIncident(r.Context(), inc); err != nil {
 		h.logger.Error("resolve incident", "error", err)
-		writeError(w, http.StatusInternalServerError, "failed to resolve incident")
-		return
+		return err
 	}
 
 	if err := h.store.InsertIncidentEvent(r.Context(), newIncidentEvent(inc.ID, incident.EventResolved, "Manually resolved by "+inc.ResolvedBy)); err != nil {
 		h.logger.Error("insert resolve event", "error", err)
 	}
 
-	h.audit(r, "resolve", "incident", id, "")
+	h.audit(r, "resolve", "incident", inc.ID, "")
 
 	if h.notifier != nil {
 		h.notifier.NotifyWithPayload(&notifier.Payload{
@@ -158,8 +198,7 @@ func (h *Handler) ResolveIncident(w http.ResponseWriter, r *http.Request) {
 			Incident:  inc,
 		})
 	}
-
-	writeJSON(w, http.StatusOK, inc)
+	return nil
 }
 
 func (h *Handler) DeleteIncident(w http.ResponseWriter, r *http.Request) {
@@ -189,6 +228,91 @@ func (h *Handler) DeleteIncident(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// SetIncidentPostmortem replaces an incident's written root-cause summary.
+// An empty postmortem clears it.
+func (h *Handler) SetIncidentPostmortem(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req struct {
+		Postmortem string `json:"postmortem"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validate.ValidatePostmortem(req.Postmortem); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.store.SetIncidentPostmortem(r.Context(), id, req.Postmortem); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "incident not found")
+			return
+		}
+		h.logger.Error("set incident postmortem", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save postmortem")
+		return
+	}
+
+	h.audit(r, "postmortem", "incident", id, "")
+
+	inc, err := h.store.GetIncident(r.Context(), id)
+	if err != nil {
+		h.logger.Error("get incident after postmortem", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get incident")
+		return
+	}
+	writeJSON(w, http.StatusOK, inc)
+}
+
+// AddIncidentNote adds a free-text note to an incident's timeline, credited
+// to the calling API key.
+func (h *Handler) AddIncidentNote(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req struct {
+		Message string `json:"message"`
+	}
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validate.ValidateIncidentNote(req.Message); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := h.store.GetIncident(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "incident not found")
+			return
+		}
+		h.logger.Error("get incident for note", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get incident")
+		return
+	}
+
+	e := newIncidentEvent(id, incident.EventNote, strings.TrimSpace(req.Message))
+	e.Author = httputil.GetAPIKeyName(r.Context())
+	if err := h.store.InsertIncidentEvent(r.Context(), e); err != nil {
+		h.logger.Error("insert incident note", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to add note")
+		return
+	}
+
+	h.audit(r, "note", "incident", id, "")
+	writeJSON(w, http.StatusCreated, e)
+}
+
 func newIncidentEvent(incidentID int64, eventType, message string) *storage.IncidentEvent {
 	return &storage.IncidentEvent{
 		IncidentID: incidentID,
@@ -196,3 +320,122 @@ func newIncidentEvent(incidentID int64, eventType, message string) *storage.Inci
 		Message:    message,
 	}
 }
+
+// AckIncidentLink answers the signed acknowledge link in a notification.
+// GET only shows a confirmation form, so link scanners and prefetching mail
+// clients can't act on the incident; the form's POST acknowledges it, acting
+// as the API key named by notifier.action_key.
+func (h *Handler) AckIncidentLink(w http.ResponseWriter, r *http.Request) {
+	h.incidentLink(w, r, notifier.ActionAck)
+}
+
+// ResolveIncidentLink answers the signed resolve link in a notification
+// like AckIncidentLink.
+func (h *Handler) ResolveIncidentLink(w http.ResponseWriter, r *http.Request) {
+	h.incidentLink(w, r, notifier.ActionResolve)
+}
+
+func (h *Handler) incidentLink(w http.ResponseWriter, r *http.Request, action string) {
+	keyName := h.cfg.Notifier.ActionKey
+	if keyName == "" {
+		writeLinkResult(w, r, http.StatusNotFound, "action links are disabled", nil)
+		return
+	}
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeLinkResult(w, r, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	token := r.FormValue("token")
+	if err := notifier.VerifyActionLink(h.cfg.Notifier.ActionSecret, keyName, id, action, token, time.Now()); err != nil {
+		msg := "invalid link"
+		if errors.Is(err, notifier.ErrActionLinkExpired) {
+			msg = "link expired"
+		}
+		writeLinkResult(w, r, http.StatusForbidden, msg, nil)
+		return
+	}
+	// The key is looked up again so removing it or its incidents.write
+	// permission revokes links already sent.
+	key := h.cfg.LookupAPIKeyByName(keyName)
+	if key == nil || !key.HasPermission("incidents.write") {
+		writeLinkResult(w, r, http.StatusForbidden, "invalid link", nil)
+		return
+	}
+	r = r.WithContext(context.WithValue(r.Context(), httputil.CtxKeyAPIKey, key))
+
+	inc, err := h.store.GetIncident(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeLinkResult(w, r, http.StatusNotFound, "incident not found", nil)
+			return
+		}
+		h.logger.Error("get incident for link", "error", err)
+		writeLinkResult(w, r, http.StatusInternalServerError, "failed to get incident", nil)
+		return
+	}
+
+	switch {
+	case inc.Status == incident.StatusResolved:
+		writeLinkResult(w, r, http.StatusConflict, "incident is already resolved", inc)
+	case action == notifier.ActionAck && inc.Status != incident.StatusOpen:
+		writeLinkResult(w, r, http.StatusConflict, "incident is already acknowledged", inc)
+	case r.Method != http.MethodPost:
+		writeLinkConfirm(w, r, action, token, inc)
+	case action == notifier.ActionAck:
+		if err := h.acknowledge(r, inc, 0); err != nil {
+			writeLinkResult(w, r, http.StatusInternalServerError, "failed to acknowledge incident", nil)
+			return
+		}
+		writeLinkResult(w, r, http.StatusOK, fmt.Sprintf("Incident #%d acknowledged", inc.ID), inc)
+	default:
+		if err := h.resolve(r, inc); err != nil {
+			writeLinkResult(w, r, http.StatusInternalServerError, "failed to resolve incident", nil)
+			return
+		}
+		writeLinkResult(w, r, http.StatusOK, fmt.Sprintf("Incident #%d resolved", inc.ID), inc)
+	}
+}
+
+// writeLinkConfirm answers the GET of a valid incident link with the
+// incident for API clients and, for browsers, a page whose form posts the
+// token to the link's confirm path.
+func writeLinkConfirm(w http.ResponseWriter, r *http.Request, action, token string, inc *storage.Incident) {
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		writeJSON(w, http.StatusOK, inc)
+		return
+	}
+	title := "Asura"
+	if inc.MonitorName != "" {
+		title = inc.MonitorName
+	}
+	verb := "Acknowledge"
+	if action == notifier.ActionResolve {
+		verb = "Resolve"
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title></head><body><h1>%s</h1><p>%s incident #%d?</p>"+
+		"<form method=\"post\" action=\"%s/confirm\"><input type=\"hidden\" name=\"token\" value=\"%s\"><button type=\"submit\">%s</button></form></body></html>\n",
+		html.EscapeString(title), html.EscapeString(title), verb, inc.ID, action, html.EscapeString(token), verb)
+}
+
+// writeLinkResult answers an incident link with JSON for API clients and a
+// short confirmation page for browsers.
+func writeLinkResult(w http.ResponseWriter, r *http.Request, status int, msg string, inc *storage.Incident) {
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		if status >= 400 {
+			writeError(w, status, msg)
+			return
+		}
+		writeJSON(w, status, inc)
+		return
+	}
+	title := "Asura"
+	if inc != nil && inc.MonitorName != "" {
+		title = inc.MonitorName
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title></head><body><h1>%s</h1><p>%s</p></body></html>\n",
+		html.EscapeString(title), html.EscapeString(title), html.EscapeString(msg))
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/y0f/asura/internal/httputil"
 	"github.com/y0f/asura/internal/storage"
@@ -115,3 +116,44 @@ func (h *Handler) DeleteMaintenance(w http.ResponseWriter, r *http.Request) {
 	h.audit(r, "delete", "maintenance_window", id, "")
 	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
+
+// NextMaintenance returns the window's next occurrence after now, or after
+// the optional RFC 3339 "after" query parameter. An occurrence already in
+// progress is returned with active set; next is null when the window never
+// opens again.
+func (h *Handler) NextMaintenance(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	after := time.Now().UTC()
+	if v := r.URL.Query().Get("after"); v != "" {
+		if after, err = time.Parse(time.RFC3339, v); err != nil {
+			writeError(w, http.StatusBadRequest, "after must be an RFC3339 timestamp")
+			return
+		}
+	}
+
+	mw, err := h.store.GetMaintenanceWindow(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "maintenance window not found")
+			return
+		}
+		h.logger.Error("get maintenance for next", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get maintenance window")
+		return
+	}
+
+	resp := map[string]any{"maintenance_id": mw.ID, "next": nil}
+	if start, end, ok := storage.NextMaintenanceOccurrence(mw, after); ok {
+		resp["next"] = map[string]any{
+			"start":  start.UTC(),
+			"end":    end.UTC(),
+			"active": !start.After(after),
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -2,9 +2,12 @@ package api
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -40,71 +43,118 @@ func (h *Handler) Metrics(w http.ResponseWriter, r *http.Request) {
 
 	monList, ok := monitors.Data.([]*storage.Monitor)
 	if ok && len(monList) > 0 {
-		h.writeMonitorMetrics(&sb, ctx, monList)
+		writeMonitorTypeMetrics(&sb, monList)
+	}
+
+	if h.cfg.IsDetailedMetrics() {
+		if err := h.writeMonitorMetrics(&sb, ctx); err != nil {
+			h.logger.Error("metrics: monitor series", "error", err)
+			writeError(w, http.StatusInternalServerError, "metrics error")
+			return
+		}
 	}
 
 	h.writeIncidentMetrics(&sb, ctx)
 	h.writeRequestMetrics(&sb, ctx)
 
+	sb.WriteString("\n# HELP asura_db_writes_dropped_total Total check results and status updates lost while the database was locked.\n")
+	sb.WriteString("# TYPE asura_db_writes_dropped_total counter\n")
+	fmt.Fprintf(&sb, "asura_db_writes_dropped_total %d\n", h.store.DroppedWrites())
+
+	sb.WriteString("\n# HELP asura_db_writes_pending Writes buffered in memory waiting for the database lock.\n")
+	sb.WriteString("# TYPE asura_db_writes_pending gauge\n")
+	fmt.Fprintf(&sb, "asura_db_writes_pending %d\n", h.store.PendingWrites())
+
 	if h.pipeline != nil {
-		sb.WriteString("\n# HELP asura_scheduler_jobs_dropped_total Total scheduler jobs dropped due to full channel.\n")
+		sb.WriteString("\n# HELP asura_scheduler_jobs_dropped_total Total scheduled checks dropped because the job queue was full.\n")
 		sb.WriteString("# TYPE asura_scheduler_jobs_dropped_total counter\n")
 		fmt.Fprintf(&sb, "asura_scheduler_jobs_dropped_total %d\n", h.pipeline.DroppedJobs())
 
+		sb.WriteString("\n# HELP asura_job_queue_depth Scheduled checks waiting for a free worker.\n")
+		sb.WriteString("# TYPE asura_job_queue_depth gauge\n")
+		fmt.Fprintf(&sb, "asura_job_queue_depth %d\n", h.pipeline.QueuedJobs())
+
+		sb.WriteString("\n# HELP asura_job_queue_capacity Scheduled checks that can wait for a free worker.\n")
+		sb.WriteString("# TYPE asura_job_queue_capacity gauge\n")
+		fmt.Fprintf(&sb, "asura_job_queue_capacity %d\n", h.pipeline.JobQueueCapacity())
+
+		sb.WriteString("\n# HELP asura_checks_in_flight Checks currently running.\n")
+		sb.WriteString("# TYPE asura_checks_in_flight gauge\n")
+		fmt.Fprintf(&sb, "asura_checks_in_flight %d\n", h.pipeline.InFlight())
+
 		sb.WriteString("\n# HELP asura_notifications_dropped_total Total notification events dropped due to full channel.\n")
 		sb.WriteString("# TYPE asura_notifications_dropped_total counter\n")
 		fmt.Fprintf(&sb, "asura_notifications_dropped_total %d\n", h.pipeline.DroppedNotifications())
+
+		sb.WriteString("\n# HELP asura_host_checks_queued_total Total checks that waited for their target host's limit.\n")
+		sb.WriteString("# TYPE asura_host_checks_queued_total counter\n")
+		fmt.Fprintf(&sb, "asura_host_checks_queued_total %d\n", h.pipeline.QueuedHostChecks())
+
+		sb.WriteString("\n# HELP asura_host_checks_dropped_total Total checks skipped because their target host stayed busy.\n")
+		sb.WriteString("# TYPE asura_host_checks_dropped_total counter\n")
+		fmt.Fprintf(&sb, "asura_host_checks_dropped_total %d\n", h.pipeline.DroppedHostChecks())
 	}
 
 	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
 	io.WriteString(w, sb.String())
 }
 
-func (h *Handler) writeMonitorMetrics(sb *strings.Builder, ctx context.Context, monList []*storage.Monitor) {
+// maxPromLabelLen caps user-controlled label values so a long monitor name
+// or tag value can't bloat every series.
+const maxPromLabelLen = 128
+
+// writeMonitorMetrics writes per-monitor series for enabled monitors.
+func (h *Handler) writeMonitorMetrics(sb *strings.Builder, ctx context.Context) error {
+	monList, err := h.store.GetAllEnabledMonitors(ctx)
+	if err != nil || len(monList) == 0 {
+		return err
+	}
+
 	monIDs := make([]int64, len(monList))
+	labels := make([]string, len(monList))
 	for i, m := range monList {
 		monIDs[i] = m.ID
+		labels[i] = fmt.Sprintf("monitor_id=\"%d\",name=\"%s\",type=\"%s\"",
+			m.ID, promLabel(m.Name), m.Type)
 	}
 	tagMap, _ := h.store.GetMonitorTagsBatch(ctx, monIDs)
 
 	sb.WriteString("\n# HELP asura_monitor_up Whether the monitor is up (1) or down (0).\n")
 	sb.WriteString("# TYPE asura_monitor_up gauge\n")
-	for _, m := range monList {
+	for i, m := range monList {
 		val := 0
 		if m.Status == "up" {
 			val = 1
 		}
-		tagsLabel := formatTagsLabel(tagMap[m.ID])
-		fmt.Fprintf(sb, "asura_monitor_up{id=\"%d\",name=\"%s\",type=\"%s\"%s} %d\n",
-			m.ID, escapeProm(m.Name), m.Type, tagsLabel, val)
+		fmt.Fprintf(sb, "asura_monitor_up{%s%s} %d\n", labels[i], formatTagsLabel(tagMap[m.ID]), val)
 	}
 
 	rtMap, err := h.store.GetLatestResponseTimes(ctx)
 	if err == nil && len(rtMap) > 0 {
 		sb.WriteString("\n# HELP asura_monitor_response_time_ms Last response time in milliseconds.\n")
 		sb.WriteString("# TYPE asura_monitor_response_time_ms gauge\n")
-		for _, m := range monList {
+		for i, m := range monList {
 			if rt, ok := rtMap[m.ID]; ok {
-				fmt.Fprintf(sb, "asura_monitor_response_time_ms{id=\"%d\",name=\"%s\"} %d\n",
-					m.ID, escapeProm(m.Name), rt)
+				fmt.Fprintf(sb, "asura_monitor_response_time_ms{%s} %d\n", labels[i], rt)
 			}
 		}
 	}
 
 	sb.WriteString("\n# HELP asura_monitor_consecutive_failures Current consecutive failure count.\n")
 	sb.WriteString("# TYPE asura_monitor_consecutive_failures gauge\n")
-	for _, m := range monList {
-		fmt.Fprintf(sb, "asura_monitor_consecutive_failures{id=\"%d\",name=\"%s\"} %d\n",
-			m.ID, escapeProm(m.Name), m.ConsecFails)
+	for i, m := range monList {
+		fmt.Fprintf(sb, "asura_monitor_consecutive_failures{%s} %d\n", labels[i], m.ConsecFails)
 	}
 
 	sb.WriteString("\n# HELP asura_monitor_consecutive_successes Current consecutive success count.\n")
 	sb.WriteString("# TYPE asura_monitor_consecutive_successes gauge\n")
-	for _, m := range monList {
-		fmt.Fprintf(sb, "asura_monitor_consecutive_successes{id=\"%d\",name=\"%s\"} %d\n",
-			m.ID, escapeProm(m.Name), m.ConsecSuccesses)
+	for i, m := range monList {
+		fmt.Fprintf(sb, "asura_monitor_consecutive_successes{%s} %d\n", labels[i], m.ConsecSuccesses)
 	}
+	return nil
+}
 
+func writeMonitorTypeMetrics(sb *strings.Builder, monList []*storage.Monitor) {
 	typeCounts := make(map[string]int)
 	for _, m := range monList {
 		typeCounts[m.Type]++
@@ -160,11 +210,20 @@ func formatTagsLabel(tags []storage.MonitorTag) string {
 		if val == "" {
 			val = "true"
 		}
-		fmt.Fprintf(&sb, ",tag_%s=\"%s\"", escapeProm(label), escapeProm(val))
+		fmt.Fprintf(&sb, ",tag_%s=\"%s\"", escapeProm(label), promLabel(val))
 	}
 	return sb.String()
 }
 
+// promLabel escapes a label value and caps it at maxPromLabelLen bytes,
+// without splitting a UTF-8 sequence.
+func promLabel(s string) string {
+	if len(s) > maxPromLabelLen {
+		s = strings.ToValidUTF8(s[:maxPromLabelLen], "")
+	}
+	return escapeProm(s)
+}
+
 func escapeProm(s string) string {
 	s = strings.ReplaceAll(s, "\\", "\\\\")
 	s = strings.ReplaceAll(s, "\"", "\\\"")
@@ -216,14 +275,139 @@ func (h *Handler) MonitorMetrics(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
+	resp := map[string]any{
 		"monitor_id":    id,
 		"from":          from.Format(time.RFC3339),
 		"to":            to.Format(time.RFC3339),
 		"uptime_pct":    uptime,
 		"response_time": map[string]float64{"p50": p50, "p95": p95, "p99": p99},
 		"checks":        map[string]int64{"total": total, "up": up, "down": down, "degraded": degraded},
-	})
+	}
+
+	switch r.URL.Query().Get("group_by") {
+	case "":
+	case "probe":
+		byProbe, err := h.store.GetUptimeByProbe(r.Context(), id, from, to)
+		if err != nil {
+			h.logger.Error("get uptime by probe", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to get uptime by probe")
+			return
+		}
+		resp["probes"] = byProbe
+	default:
+		writeError(w, http.StatusBadRequest, "group_by must be probe")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// MonitorSLA reports a monitor's SLA between from and to (RFC3339), by
+// default over the previous calendar month.
+func (h *Handler) MonitorSLA(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	from, to := httputil.PreviousMonth(time.Now())
+	if f := r.URL.Query().Get("from"); f != "" {
+		if from, err = time.Parse(time.RFC3339, f); err != nil {
+			writeError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if t := r.URL.Query().Get("to"); t != "" {
+		if to, err = time.Parse(time.RFC3339, t); err != nil {
+			writeError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if !to.After(from) {
+		writeError(w, http.StatusBadRequest, "to must be after from")
+		return
+	}
+
+	rep, err := h.store.GetSLAReport(r.Context(), id, from, to)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "monitor not found")
+			return
+		}
+		h.logger.Error("get sla report", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get sla report")
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+// maxApdexThresholdMs bounds the Apdex target time to the longest check
+// timeout in milliseconds.
+const maxApdexThresholdMs = 300000
+
+// MonitorApdex returns a monitor's Apdex score, by default over the last
+// 24 hours with T = storage.DefaultApdexThresholdMs.
+func (h *Handler) MonitorApdex(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := r.URL.Query()
+	threshold := int64(storage.DefaultApdexThresholdMs)
+	if v := q.Get("threshold"); v != "" {
+		threshold, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || threshold < 1 || threshold > maxApdexThresholdMs {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("threshold must be between 1 and %d milliseconds", maxApdexThresholdMs))
+			return
+		}
+	}
+	failedAsFrustrated := false
+	if v := q.Get("count_failed"); v != "" {
+		if failedAsFrustrated, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, "count_failed must be true or false")
+			return
+		}
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-24 * time.Hour)
+	if f := q.Get("from"); f != "" {
+		if from, err = time.Parse(time.RFC3339, f); err != nil {
+			writeError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if t := q.Get("to"); t != "" {
+		if to, err = time.Parse(time.RFC3339, t); err != nil {
+			writeError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if !to.After(from) {
+		writeError(w, http.StatusBadRequest, "to must be after from")
+		return
+	}
+
+	if _, err := h.store.GetMonitor(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "monitor not found")
+			return
+		}
+		h.logger.Error("get monitor", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+
+	rep, err := h.store.GetApdex(r.Context(), id, from, to, threshold, failedAsFrustrated)
+	if err != nil {
+		h.logger.Error("get apdex", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get apdex")
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
 }
 
 func (h *Handler) MonitorChart(w http.ResponseWriter, r *http.Request) {
@@ -260,6 +444,80 @@ func (h *Handler) MonitorChart(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// maxSeriesPoints bounds the buckets a time series request may ask for.
+const maxSeriesPoints = 2000
+
+// MonitorSeries returns a monitor's check results between from and to,
+// downsampled to at most points buckets, by default 500 over the last 24
+// hours. Buckets without checks are included with n 0.
+func (h *Handler) MonitorSeries(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := r.URL.Query()
+	points := 500
+	if v := q.Get("points"); v != "" {
+		points, err = strconv.Atoi(v)
+		if err != nil || points < 1 || points > maxSeriesPoints {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("points must be between 1 and %d", maxSeriesPoints))
+			return
+		}
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-24 * time.Hour)
+	if f := q.Get("from"); f != "" {
+		if from, err = time.Parse(time.RFC3339, f); err != nil {
+			writeError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if t := q.Get("to"); t != "" {
+		if to, err = time.Parse(time.RFC3339, t); err != nil {
+			writeError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+	}
+	if !to.After(from) {
+		writeError(w, http.StatusBadRequest, "to must be after from")
+		return
+	}
+
+	if _, err := h.store.GetMonitor(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "monitor not found")
+			return
+		}
+		h.logger.Error("get monitor", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+
+	series, err := h.store.GetTimeSeries(r.Context(), id, from, to, points)
+	if err != nil {
+		h.logger.Error("get time series", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get time series")
+		return
+	}
+
+	var bucket int64
+	if len(series) > 1 {
+		bucket = series[1].Timestamp - series[0].Timestamp
+	} else {
+		bucket = int64(to.Sub(from).Seconds())
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"monitor_id":     id,
+		"from":           from.UTC().Format(time.RFC3339),
+		"to":             to.UTC().Format(time.RFC3339),
+		"bucket_seconds": bucket,
+		"points":         series,
+	})
+}
+
 func (h *Handler) Overview(w http.ResponseWriter, r *http.Request) {
 	up, down, degraded, paused, err := h.store.CountMonitorsByStatus(r.Context())
 	if err != nil {
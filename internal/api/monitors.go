@@ -8,7 +8,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/y0f/asura/internal/config"
 	"github.com/y0f/asura/internal/httputil"
@@ -18,7 +22,14 @@ import (
 
 func (h *Handler) ListMonitors(w http.ResponseWriter, r *http.Request) {
 	p := httputil.ParsePagination(r)
-	result, err := h.store.ListMonitors(r.Context(), storage.MonitorListFilter{}, p)
+	tags, tagMatch := httputil.ParseTagFilter(r)
+	f := storage.MonitorListFilter{
+		Owner:    httputil.ResolveOwner(r.Context(), r.URL.Query().Get("owner")),
+		Scope:    httputil.GetMonitorScope(r.Context()),
+		TagIDs:   tags,
+		TagMatch: tagMatch,
+	}
+	result, err := h.store.ListMonitors(r.Context(), f, p)
 	if err != nil {
 		h.logger.Error("list monitors", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list monitors")
@@ -48,6 +59,11 @@ func (h *Handler) GetMonitor(w http.ResponseWriter, r *http.Request) {
 	if channelIDs != nil {
 		m.NotificationChannelIDs = channelIDs
 	}
+	if filters, _ := h.store.GetMonitorNotificationFilters(r.Context(), m.ID); len(filters) > 0 {
+		m.NotificationFilters = filters
+	}
+	m.EscalationPolicyID, _ = h.store.GetMonitorEscalationPolicyID(r.Context(), m.ID)
+	m.ProbeIDs, _ = h.store.GetMonitorProbeIDs(r.Context(), m.ID)
 
 	m.MonitorTags, _ = h.store.GetMonitorTags(r.Context(), m.ID)
 
@@ -78,8 +94,20 @@ func (h *Handler) CreateMonitor(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := validate.ValidateNotificationFilters(m.NotificationFilters, m.NotificationChannelIDs); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.escalationPolicyExists(w, r, m.EscalationPolicyID) || !h.probesExist(w, r, m.ProbeIDs) || !h.monitorParentValid(w, r, &m) {
+		return
+	}
+	h.applyAutoTags(r, &m)
 
 	if err := h.store.CreateMonitor(r.Context(), &m); err != nil {
+		if errors.Is(err, storage.ErrMonitorLimit) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
 		h.logger.Error("create monitor", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to create monitor")
 		return
@@ -88,6 +116,22 @@ func (h *Handler) CreateMonitor(w http.ResponseWriter, r *http.Request) {
 	if len(m.NotificationChannelIDs) > 0 {
 		if err := h.store.SetMonitorNotificationChannels(r.Context(), m.ID, m.NotificationChannelIDs); err != nil {
 			h.logger.Error("set monitor notification channels", "error", err)
+		} else if len(m.NotificationFilters) > 0 {
+			if err := h.store.SetMonitorNotificationFilters(r.Context(), m.ID, m.NotificationFilters); err != nil {
+				h.logger.Error("set monitor notification filters", "error", err)
+			}
+		}
+	}
+
+	if m.EscalationPolicyID != nil {
+		if err := h.store.SetMonitorEscalationPolicy(r.Context(), m.ID, m.EscalationPolicyID); err != nil {
+			h.logger.Error("set monitor escalation policy", "error", err)
+		}
+	}
+
+	if len(m.ProbeIDs) > 0 {
+		if err := h.store.SetMonitorProbes(r.Context(), m.ID, m.ProbeIDs); err != nil {
+			h.logger.Error("set monitor probes", "error", err)
 		}
 	}
 
@@ -111,7 +155,7 @@ func (h *Handler) CreateMonitor(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	h.audit(r, "create", "monitor", m.ID, "")
+	h.audit(r, "create", "monitor", m.ID, autoTagDetail(m.AutoTags))
 
 	if h.pipeline != nil {
 		h.pipeline.ReloadMonitors()
@@ -127,6 +171,24 @@ func (h *Handler) CreateMonitor(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, m)
 }
 
+// applyAutoTags adds the tags of matching monitor.auto_tag_rules to m and
+// records which ones were added in m.AutoTags.
+func (h *Handler) applyAutoTags(r *http.Request, m *storage.Monitor) {
+	tags, applied, err := httputil.ApplyAutoTags(r.Context(), h.store, h.cfg.Monitor.AutoTagRules, m, m.MonitorTags)
+	if err != nil {
+		h.logger.Error("apply auto tags", "error", err)
+	}
+	m.MonitorTags = tags
+	m.AutoTags = applied
+}
+
+func autoTagDetail(applied []string) string {
+	if len(applied) == 0 {
+		return ""
+	}
+	return "auto-tagged: " + strings.Join(applied, ", ")
+}
+
 func (h *Handler) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
@@ -157,8 +219,20 @@ func (h *Handler) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := validate.ValidateNotificationFilters(m.NotificationFilters, m.NotificationChannelIDs); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.escalationPolicyExists(w, r, m.EscalationPolicyID) || !h.probesExist(w, r, m.ProbeIDs) || !h.monitorParentValid(w, r, &m) {
+		return
+	}
+	h.applyAutoTags(r, &m)
 
 	if err := h.store.UpdateMonitor(r.Context(), &m); err != nil {
+		if errors.Is(err, storage.ErrMonitorLimit) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
 		h.logger.Error("update monitor", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to update monitor")
 		return
@@ -166,6 +240,16 @@ func (h *Handler) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.store.SetMonitorNotificationChannels(r.Context(), m.ID, m.NotificationChannelIDs); err != nil {
 		h.logger.Error("set monitor notification channels", "error", err)
+	} else if err := h.store.SetMonitorNotificationFilters(r.Context(), m.ID, m.NotificationFilters); err != nil {
+		h.logger.Error("set monitor notification filters", "error", err)
+	}
+
+	if err := h.store.SetMonitorEscalationPolicy(r.Context(), m.ID, m.EscalationPolicyID); err != nil {
+		h.logger.Error("set monitor escalation policy", "error", err)
+	}
+
+	if err := h.store.SetMonitorProbes(r.Context(), m.ID, m.ProbeIDs); err != nil {
+		h.logger.Error("set monitor probes", "error", err)
 	}
 
 	if err := validate.ValidateMonitorTags(m.MonitorTags); err != nil {
@@ -176,7 +260,7 @@ func (h *Handler) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
 		h.logger.Error("set monitor tags", "error", err)
 	}
 
-	h.audit(r, "update", "monitor", m.ID, "")
+	h.audit(r, "update", "monitor", m.ID, autoTagDetail(m.AutoTags))
 
 	if h.pipeline != nil {
 		h.pipeline.ReloadMonitors()
@@ -184,12 +268,35 @@ func (h *Handler) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
 
 	updated, _ := h.store.GetMonitor(r.Context(), id)
 	if updated != nil {
+		updated.AutoTags = m.AutoTags
 		writeJSON(w, http.StatusOK, updated)
 	} else {
 		writeJSON(w, http.StatusOK, m)
 	}
 }
 
+// monitorParentValid checks that m's parent exists and doesn't depend on m.
+func (h *Handler) monitorParentValid(w http.ResponseWriter, r *http.Request, m *storage.Monitor) bool {
+	if m.ParentID == nil {
+		return true
+	}
+	if _, err := h.store.GetMonitor(r.Context(), *m.ParentID); err != nil {
+		writeError(w, http.StatusBadRequest, "parent monitor not found")
+		return false
+	}
+	parents, err := h.store.ListMonitorParents(r.Context())
+	if err != nil {
+		h.logger.Error("list monitor parents", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to check parent monitor")
+		return false
+	}
+	if err := validate.ValidateMonitorParent(m.ID, m.ParentID, parents); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
 func (h *Handler) DeleteMonitor(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
@@ -272,6 +379,14 @@ func (h *Handler) ListChecks(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to list checks")
 		return
 	}
+	// Headers can be large, so they are only returned on request.
+	if r.URL.Query().Get("include_headers") != "true" {
+		if checks, ok := result.Data.([]*storage.CheckResult); ok {
+			for _, c := range checks {
+				c.Headers = ""
+			}
+		}
+	}
 	writeJSON(w, http.StatusOK, result)
 }
 
@@ -291,6 +406,44 @@ func (h *Handler) ListChanges(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, result)
 }
 
+// maxTransitions caps the limit of the transitions endpoint.
+const maxTransitions = 500
+
+// ListTransitions returns the monitor's latest status transitions, newest
+// first: 20 by default, or ?limit of them.
+func (h *Handler) ListTransitions(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = min(n, maxTransitions)
+	}
+	if _, err := h.store.GetMonitor(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "monitor not found")
+			return
+		}
+		h.logger.Error("get monitor", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+	transitions, err := h.store.ListMonitorTransitions(r.Context(), id, limit)
+	if err != nil {
+		h.logger.Error("list transitions", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list transitions")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": transitions})
+}
+
 func (h *Handler) CloneMonitor(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
@@ -310,26 +463,14 @@ func (h *Handler) CloneMonitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	clone := &storage.Monitor{
-		Name:             src.Name + " (copy)",
-		Description:      src.Description,
-		Type:             src.Type,
-		Target:           src.Target,
-		Interval:         src.Interval,
-		Timeout:          src.Timeout,
-		Enabled:          false,
-		Settings:         src.Settings,
-		Assertions:       src.Assertions,
-		TrackChanges:     src.TrackChanges,
-		FailureThreshold: src.FailureThreshold,
-		SuccessThreshold: src.SuccessThreshold,
-		UpsideDown:       src.UpsideDown,
-		ResendInterval:   src.ResendInterval,
-		GroupID:          src.GroupID,
-		ProxyID:          src.ProxyID,
-	}
+	clone := cloneMonitorConfig(src)
+	clone.Name = src.Name + " (copy)"
 
 	if err := h.store.CreateMonitor(ctx, clone); err != nil {
+		if errors.Is(err, storage.ErrMonitorLimit) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
 		h.logger.Error("clone monitor", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to clone monitor")
 		return
@@ -338,6 +479,17 @@ func (h *Handler) CloneMonitor(w http.ResponseWriter, r *http.Request) {
 	channelIDs, _ := h.store.GetMonitorNotificationChannelIDs(ctx, id)
 	if len(channelIDs) > 0 {
 		h.store.SetMonitorNotificationChannels(ctx, clone.ID, channelIDs)
+		if filters, _ := h.store.GetMonitorNotificationFilters(ctx, id); len(filters) > 0 {
+			h.store.SetMonitorNotificationFilters(ctx, clone.ID, filters)
+		}
+	}
+
+	if policyID, _ := h.store.GetMonitorEscalationPolicyID(ctx, id); policyID != nil {
+		h.store.SetMonitorEscalationPolicy(ctx, clone.ID, policyID)
+	}
+
+	if probeIDs, _ := h.store.GetMonitorProbeIDs(ctx, id); len(probeIDs) > 0 {
+		h.store.SetMonitorProbes(ctx, clone.ID, probeIDs)
 	}
 
 	srcTags, _ := h.store.GetMonitorTags(ctx, id)
@@ -358,6 +510,227 @@ func (h *Handler) CloneMonitor(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, clone)
 }
 
+// cloneMonitorConfig copies src's configuration into a new, paused monitor.
+// Links to channels, policies, probes and tags are not copied.
+func cloneMonitorConfig(src *storage.Monitor) *storage.Monitor {
+	return &storage.Monitor{
+		Description:           src.Description,
+		Owner:                 src.Owner,
+		Type:                  src.Type,
+		Target:                src.Target,
+		Interval:              src.Interval,
+		Timeout:               src.Timeout,
+		Enabled:               false,
+		Settings:              src.Settings,
+		Assertions:            src.Assertions,
+		TrackChanges:          src.TrackChanges,
+		FailureThreshold:      src.FailureThreshold,
+		SuccessThreshold:      src.SuccessThreshold,
+		UpsideDown:            src.UpsideDown,
+		ResendInterval:        src.ResendInterval,
+		GroupID:               src.GroupID,
+		ProxyID:               src.ProxyID,
+		AckSilencesReminders:  src.AckSilencesReminders,
+		LatencyBaselineSigma:  src.LatencyBaselineSigma,
+		SkipDefaultChannel:    src.SkipDefaultChannel,
+		CaptureFailureContext: src.CaptureFailureContext,
+		StreamChecksEvery:     src.StreamChecksEvery,
+		Retries:               src.Retries,
+		RetryInterval:         src.RetryInterval,
+		RedactPatterns:        src.RedactPatterns,
+		DegradedThreshold:     src.DegradedThreshold,
+		FlapWindowSeconds:     src.FlapWindowSeconds,
+		ParentID:              src.ParentID,
+		ConnectTimeout:        src.ConnectTimeout,
+		MaxResponseTimeMs:     src.MaxResponseTimeMs,
+		WarmupSeconds:         src.WarmupSeconds,
+	}
+}
+
+// maxTemplateItems caps how many monitors one template request creates.
+const maxTemplateItems = 500
+
+type templateRequest struct {
+	BaseID int64          `json:"base_id"`
+	Items  []templateItem `json:"items"`
+}
+
+// templateItem overrides the base monitor for one created monitor. An empty
+// target keeps the base target; tag_values set or add tags by name.
+type templateItem struct {
+	Name      string            `json:"name"`
+	Target    string            `json:"target,omitempty"`
+	TagValues map[string]string `json:"tag_values,omitempty"`
+}
+
+type templateError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// CreateMonitorsFromTemplate creates one monitor per item from a base
+// monitor, copying its settings, assertions, notification channels and
+// filters, escalation policy, probes and tags. Items that fail validation are
+// reported in errors; the rest are created in one transaction.
+func (h *Handler) CreateMonitorsFromTemplate(w http.ResponseWriter, r *http.Request) {
+	var req templateRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.BaseID <= 0 {
+		writeError(w, http.StatusBadRequest, "base_id is required")
+		return
+	}
+	if len(req.Items) == 0 {
+		writeError(w, http.StatusBadRequest, "items is required")
+		return
+	}
+	if len(req.Items) > maxTemplateItems {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("max %d items per request", maxTemplateItems))
+		return
+	}
+
+	ctx := r.Context()
+	base, err := h.store.GetMonitor(ctx, req.BaseID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "base monitor not found")
+			return
+		}
+		h.logger.Error("get template base monitor", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+
+	channelIDs, err := h.store.GetMonitorNotificationChannelIDs(ctx, base.ID)
+	if err != nil {
+		h.logger.Error("get template notification channels", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+	filters, err := h.store.GetMonitorNotificationFilters(ctx, base.ID)
+	if err != nil {
+		h.logger.Error("get template notification filters", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+	policyID, err := h.store.GetMonitorEscalationPolicyID(ctx, base.ID)
+	if err != nil {
+		h.logger.Error("get template escalation policy", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+	probeIDs, err := h.store.GetMonitorProbeIDs(ctx, base.ID)
+	if err != nil {
+		h.logger.Error("get template probes", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+	baseTags, err := h.store.GetMonitorTags(ctx, base.ID)
+	if err != nil {
+		h.logger.Error("get template tags", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get monitor")
+		return
+	}
+	tags, err := h.store.ListTags(ctx)
+	if err != nil {
+		h.logger.Error("list tags", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list tags")
+		return
+	}
+	tagIDs := make(map[string]int64, len(tags))
+	for _, t := range tags {
+		tagIDs[t.Name] = t.ID
+	}
+
+	var monitors []*storage.Monitor
+	errs := []templateError{}
+	for i, item := range req.Items {
+		m := cloneMonitorConfig(base)
+		m.Name = item.Name
+		if item.Target != "" {
+			m.Target = item.Target
+		}
+		m.Enabled = base.Enabled
+		m.NotificationChannelIDs = channelIDs
+		m.NotificationFilters = filters
+		m.EscalationPolicyID = policyID
+		m.ProbeIDs = probeIDs
+
+		monitorTags, err := applyTagValues(baseTags, item.TagValues, tagIDs)
+		if err == nil {
+			m.MonitorTags = monitorTags
+			err = validate.ValidateMonitor(m)
+		}
+		if err == nil {
+			h.applyAutoTags(r, m)
+			err = validate.ValidateMonitorTags(m.MonitorTags)
+		}
+		if err != nil {
+			errs = append(errs, templateError{Index: i, Error: err.Error()})
+			continue
+		}
+		monitors = append(monitors, m)
+	}
+
+	if len(monitors) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":  "no valid items",
+			"errors": errs,
+		})
+		return
+	}
+
+	if err := h.store.CreateMonitors(ctx, monitors); err != nil {
+		if errors.Is(err, storage.ErrMonitorLimit) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		h.logger.Error("create monitors from template", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create monitors")
+		return
+	}
+
+	created := make([]int64, 0, len(monitors))
+	for _, m := range monitors {
+		if m.Type == "heartbeat" {
+			h.createHeartbeat(ctx, m)
+		}
+		h.audit(r, "create", "monitor", m.ID, fmt.Sprintf("template=%d", base.ID))
+		created = append(created, m.ID)
+	}
+
+	if h.pipeline != nil {
+		h.pipeline.ReloadMonitors()
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"created": created,
+		"errors":  errs,
+	})
+}
+
+// applyTagValues returns base with the values in values set by tag name,
+// adding tags the base monitor lacks.
+func applyTagValues(base []storage.MonitorTag, values map[string]string, tagIDs map[string]int64) ([]storage.MonitorTag, error) {
+	out := slices.Clone(base)
+	names := slices.Sorted(maps.Keys(values))
+	for _, name := range names {
+		id, ok := tagIDs[name]
+		if !ok {
+			return nil, fmt.Errorf("tag %q not found", name)
+		}
+		i := slices.IndexFunc(out, func(t storage.MonitorTag) bool { return t.TagID == id })
+		if i < 0 {
+			out = append(out, storage.MonitorTag{TagID: id, Value: values[name]})
+			continue
+		}
+		out[i].Value = values[name]
+	}
+	return out, nil
+}
+
 type bulkRequest struct {
 	Action  string  `json:"action"`
 	IDs     []int64 `json:"ids"`
@@ -397,6 +770,10 @@ func (h *Handler) BulkMonitors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if errors.Is(err, storage.ErrMonitorLimit) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Error("bulk monitors", "action", req.Action, "error", err)
 		writeError(w, http.StatusInternalServerError, "bulk operation failed")
@@ -34,6 +34,9 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !h.oncallScheduleExists(w, r, ch.OncallScheduleID) {
+		return
+	}
 
 	if err := h.store.CreateNotificationChannel(r.Context(), &ch); err != nil {
 		h.logger.Error("create notification", "error", err)
@@ -41,6 +44,12 @@ func (h *Handler) CreateNotification(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(ch.TagIDs) > 0 {
+		if err := h.store.SetChannelTags(r.Context(), ch.ID, ch.TagIDs); err != nil {
+			h.logger.Error("set channel tags", "error", err)
+		}
+	}
+
 	h.audit(r, "create", "notification_channel", ch.ID, "")
 	writeJSON(w, http.StatusCreated, ch)
 }
@@ -74,6 +83,9 @@ func (h *Handler) UpdateNotification(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !h.oncallScheduleExists(w, r, ch.OncallScheduleID) {
+		return
+	}
 
 	if err := h.store.UpdateNotificationChannel(r.Context(), &ch); err != nil {
 		h.logger.Error("update notification", "error", err)
@@ -81,6 +93,10 @@ func (h *Handler) UpdateNotification(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.store.SetChannelTags(r.Context(), ch.ID, ch.TagIDs); err != nil {
+		h.logger.Error("set channel tags", "error", err)
+	}
+
 	h.audit(r, "update", "notification_channel", ch.ID, "")
 	writeJSON(w, http.StatusOK, ch)
 }
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/y0f/asura/internal/httputil"
+	"github.com/y0f/asura/internal/storage"
+	"github.com/y0f/asura/internal/validate"
+)
+
+func (h *Handler) ListOncallSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.store.ListOncallSchedules(r.Context())
+	if err != nil {
+		h.logger.Error("list oncall schedules", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list on-call schedules")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": schedules})
+}
+
+func (h *Handler) GetOncallSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sched, err := h.store.GetOncallSchedule(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "on-call schedule not found")
+			return
+		}
+		h.logger.Error("get oncall schedule", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get on-call schedule")
+		return
+	}
+	writeJSON(w, http.StatusOK, sched)
+}
+
+func (h *Handler) CreateOncallSchedule(w http.ResponseWriter, r *http.Request) {
+	var sched storage.OncallSchedule
+	if err := readJSON(r, &sched); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := validate.ValidateOncallSchedule(&sched); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.store.CreateOncallSchedule(r.Context(), &sched); err != nil {
+		h.logger.Error("create oncall schedule", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create on-call schedule")
+		return
+	}
+
+	h.audit(r, "create", "oncall_schedule", sched.ID, "")
+	writeJSON(w, http.StatusCreated, sched)
+}
+
+func (h *Handler) UpdateOncallSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	existing, err := h.store.GetOncallSchedule(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "on-call schedule not found")
+			return
+		}
+		h.logger.Error("get oncall schedule for update", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get on-call schedule")
+		return
+	}
+
+	var sched storage.OncallSchedule
+	if err := readJSON(r, &sched); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sched.ID = id
+	sched.CreatedAt = existing.CreatedAt
+
+	if err := validate.ValidateOncallSchedule(&sched); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.store.UpdateOncallSchedule(r.Context(), &sched); err != nil {
+		h.logger.Error("update oncall schedule", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update on-call schedule")
+		return
+	}
+
+	updated, _ := h.store.GetOncallSchedule(r.Context(), id)
+	if updated == nil {
+		updated = &sched
+	}
+
+	h.invalidateOncall(id)
+	h.audit(r, "update", "oncall_schedule", sched.ID, "")
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *Handler) DeleteOncallSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	_, err = h.store.GetOncallSchedule(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "on-call schedule not found")
+			return
+		}
+		h.logger.Error("get oncall schedule for delete", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get on-call schedule")
+		return
+	}
+
+	if err := h.store.DeleteOncallSchedule(r.Context(), id); err != nil {
+		h.logger.Error("delete oncall schedule", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete on-call schedule")
+		return
+	}
+
+	h.invalidateOncall(id)
+	h.audit(r, "delete", "oncall_schedule", id, "")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// invalidateOncall makes notifications pick up a changed or deleted schedule
+// right away instead of after the dispatcher's cache expires.
+func (h *Handler) invalidateOncall(id int64) {
+	if h.notifier != nil {
+		h.notifier.InvalidateOncall(id)
+	}
+}
+
+// OncallResponder returns who is on call for a schedule, now or at the
+// RFC3339 time in ?at=. The responder is null when nobody is on call.
+func (h *Handler) OncallResponder(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	at := time.Now().UTC()
+	if v := r.URL.Query().Get("at"); v != "" {
+		if at, err = time.Parse(time.RFC3339, v); err != nil {
+			writeError(w, http.StatusBadRequest, "at must be an RFC3339 timestamp")
+			return
+		}
+	}
+
+	member, err := h.store.CurrentResponder(r.Context(), id, at)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "on-call schedule not found")
+			return
+		}
+		h.logger.Error("resolve oncall responder", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to resolve on-call responder")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"schedule_id": id,
+		"at":          at,
+		"responder":   member,
+	})
+}
+
+// oncallScheduleExists writes a 400 and returns false when id names a
+// schedule that does not exist. A nil id is always valid.
+func (h *Handler) oncallScheduleExists(w http.ResponseWriter, r *http.Request, id *int64) bool {
+	if id == nil {
+		return true
+	}
+	if _, err := h.store.GetOncallSchedule(r.Context(), *id); err != nil {
+		writeError(w, http.StatusBadRequest, "on-call schedule not found")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,458 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/monitor"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// openAPIOp describes one /api/v1 route in the OpenAPI document. Body and
+// Resp are sample values whose types are turned into schemas: a model, a
+// slice of one, fields for an ad-hoc object, paged or list for the two list
+// envelopes, or nil for none.
+type openAPIOp struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	Perm    string   // required API key permission; empty for public routes
+	Query   []string // keys of openAPIParams
+	Body    any
+	Resp    any
+	Status  int    // success status; 200 when zero
+	Content string // response media type when not JSON
+	// Bearer marks a public route that requires a status page API token
+	// when the page has one.
+	Bearer bool
+}
+
+// fields is an ad-hoc JSON object: property name to sample value.
+type fields map[string]any
+
+// paged is a storage.PaginatedResult whose data holds the given model.
+type paged struct{ of any }
+
+// list is the {"data": [...]} envelope of unpaginated lists.
+type list struct{ of any }
+
+var statusResp = fields{"status": ""}
+
+var pagination = []string{"page", "per_page"}
+
+// openAPIParams describes the query parameters used by openAPIOps.
+var openAPIParams = map[string]struct {
+	Type, Format, Description string
+}{
+	"page":            {"integer", "", "Page number, from 1"},
+	"per_page":        {"integer", "", "Results per page, 1 to 100 (default 20)"},
+	"from":            {"string", "date-time", "Start of the range (RFC3339)"},
+	"to":              {"string", "date-time", "End of the range (RFC3339)"},
+	"owner":           {"string", "", "Only monitors with this owner; \"me\" is the calling API key"},
+	"tag":             {"integer", "", "Only monitors with this tag; repeat for several"},
+	"tag_match":       {"string", "", "\"all\" (default) or \"any\" of the tag filters"},
+	"monitor_id":      {"integer", "", "Only entries for this monitor"},
+	"status":          {"string", "", "Filter by status"},
+	"label":           {"string", "", "Badge label"},
+	"color":           {"string", "", "Badge color: a shields.io color name or hex value"},
+	"config":          {"string", "", "\"current\" reruns with the monitor's current configuration instead of the snapshot"},
+	"range":           {"string", "", "24h (default), 7d or 30d"},
+	"group_by":        {"string", "", "\"probe\" adds per-probe results"},
+	"after":           {"string", "date-time", "Find the first occurrence after this time (RFC3339, default now)"},
+	"channel_id":      {"integer", "", "Only deliveries to this channel"},
+	"event_type":      {"string", "", "Filter by event type"},
+	"group":           {"string", "", "Filter by route group: web, api, badge, auth, status"},
+	"method":          {"string", "", "Filter by HTTP method"},
+	"status_code":     {"integer", "", "Filter by HTTP status code"},
+	"action":          {"string", "", "Filter by action"},
+	"entity":          {"string", "", "Filter by entity"},
+	"api_key":         {"string", "", "Filter by API key name"},
+	"limit":           {"integer", "", "Maximum number of entries"},
+	"redact_secrets":  {"boolean", "", "Replace credentials with placeholders"},
+	"mode":            {"string", "", "Import: merge (default) or replace; group uptime: all (default) or any"},
+	"format":          {"string", "", "Export: json (default) or yaml; badges: svg (default) or json"},
+	"threshold":       {"integer", "", "Apdex target time T in milliseconds (default 500)"},
+	"count_failed":    {"boolean", "", "Count down and degraded checks as frustrated instead of leaving them out"},
+	"include_headers": {"boolean", "", "Include the stored response headers (JSON encoded) of each check"},
+	"token":           {"string", "", "Signed token from the notification link"},
+}
+
+var openAPIOps = []openAPIOp{
+	{Method: "GET", Path: "/api/v1/health", Tag: "System", Summary: "Readiness check", Resp: fields{"status": ""}},
+	{Method: "GET", Path: "/api/v1/health/details", Tag: "System", Summary: "Readiness check with database, scheduler and notification status", Perm: "metrics.read", Resp: healthResponse{}},
+	{Method: "GET", Path: "/api/v1/openapi.json", Tag: "System", Summary: "This OpenAPI document", Resp: fields{}},
+	{Method: "GET", Path: "/api/v1/heartbeat/{token}", Tag: "Heartbeats", Summary: "Record a heartbeat ping", Resp: statusResp},
+	{Method: "POST", Path: "/api/v1/heartbeat/{token}", Tag: "Heartbeats", Summary: "Record a heartbeat ping", Resp: statusResp},
+	{Method: "POST", Path: "/api/v1/heartbeat/{token}/pause", Tag: "Heartbeats", Summary: "Stop expiry checking until resumed", Resp: statusResp},
+	{Method: "POST", Path: "/api/v1/heartbeat/{token}/resume", Tag: "Heartbeats", Summary: "Resume expiry checking; the next ping is due one interval plus grace from now", Resp: statusResp},
+	{Method: "GET", Path: "/api/v1/badge/{id}/status", Tag: "Badges", Summary: "Status badge", Query: []string{"label", "color", "format"}, Content: "image/svg+xml"},
+	{Method: "GET", Path: "/api/v1/badge/{id}/uptime", Tag: "Badges", Summary: "Uptime badge", Query: []string{"label", "color", "format"}, Content: "image/svg+xml"},
+	{Method: "GET", Path: "/api/v1/badge/{id}/response", Tag: "Badges", Summary: "Response time badge", Query: []string{"label", "color", "format"}, Content: "image/svg+xml"},
+	{Method: "GET", Path: "/api/v1/badge/{id}/cert", Tag: "Badges", Summary: "Certificate expiry badge", Query: []string{"label", "color", "format"}, Content: "image/svg+xml"},
+	{Method: "GET", Path: "/api/v1/badge/{id}/incident", Tag: "Badges", Summary: "Incident badge", Query: []string{"label", "color", "format"}, Content: "image/svg+xml"},
+
+	{Method: "GET", Path: "/api/v1/monitors", Tag: "Monitors", Summary: "List monitors", Perm: "monitors.read", Query: append([]string{"owner", "tag", "tag_match"}, pagination...), Resp: paged{storage.Monitor{}}},
+	{Method: "POST", Path: "/api/v1/monitors", Tag: "Monitors", Summary: "Create a monitor; heartbeat monitors return {monitor, heartbeat}", Perm: "monitors.write", Body: storage.Monitor{}, Resp: storage.Monitor{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/monitors/{id}", Tag: "Monitors", Summary: "Get a monitor; heartbeat monitors return {monitor, heartbeat}", Perm: "monitors.read", Resp: storage.Monitor{}},
+	{Method: "PUT", Path: "/api/v1/monitors/{id}", Tag: "Monitors", Summary: "Update a monitor", Perm: "monitors.write", Body: storage.Monitor{}, Resp: storage.Monitor{}},
+	{Method: "DELETE", Path: "/api/v1/monitors/{id}", Tag: "Monitors", Summary: "Delete a monitor", Perm: "monitors.write", Resp: statusResp},
+	{Method: "POST", Path: "/api/v1/monitors/{id}/pause", Tag: "Monitors", Summary: "Pause a monitor", Perm: "monitors.write", Resp: statusResp},
+	{Method: "POST", Path: "/api/v1/monitors/{id}/resume", Tag: "Monitors", Summary: "Resume a monitor", Perm: "monitors.write", Resp: statusResp},
+	{Method: "POST", Path: "/api/v1/monitors/{id}/clone", Tag: "Monitors", Summary: "Clone a monitor", Perm: "monitors.write", Resp: storage.Monitor{}, Status: http.StatusCreated},
+	{Method: "POST", Path: "/api/v1/monitors/template", Tag: "Monitors", Summary: "Create monitors from a base monitor", Perm: "monitors.write", Body: templateRequest{}, Resp: fields{"created": []int64{}, "errors": []templateError{}}, Status: http.StatusCreated},
+	{Method: "POST", Path: "/api/v1/monitors/bulk", Tag: "Monitors", Summary: "Pause, resume, delete or regroup monitors", Perm: "monitors.write", Body: bulkRequest{}, Resp: fields{"status": "", "affected": int64(0)}},
+	{Method: "POST", Path: "/api/v1/monitors/{id}/check", Tag: "Checks", Summary: "Check a monitor now", Perm: "monitors.write", Resp: storage.CheckResult{}},
+	{Method: "POST", Path: "/api/v1/monitors/check", Tag: "Checks", Summary: "Check several monitors now", Perm: "monitors.write", Body: checkNowRequest{}, Resp: fields{"checked": 0, "results": []checkNowResult{}}},
+	{Method: "GET", Path: "/api/v1/monitors/{id}/export", Tag: "Monitors", Summary: "Export one monitor", Perm: "monitors.read", Query: []string{"redact_secrets", "format"}, Resp: ExportData{}},
+	{Method: "GET", Path: "/api/v1/monitors/{id}/metrics", Tag: "Monitors", Summary: "Uptime, response time percentiles and check counts", Perm: "monitors.read", Query: []string{"from", "to", "group_by"},
+		Resp: fields{"monitor_id": int64(0), "from": "", "to": "", "uptime_pct": float64(0), "response_time": map[string]float64{}, "checks": map[string]int64{}, "probes": []storage.ProbeUptime{}}},
+	{Method: "GET", Path: "/api/v1/monitors/{id}/sla", Tag: "Monitors", Summary: "SLA report, by default for the previous calendar month", Perm: "monitors.read", Query: []string{"from", "to"}, Resp: storage.SLAReport{}},
+	{Method: "GET", Path: "/api/v1/monitors/{id}/apdex", Tag: "Monitors", Summary: "Apdex score, by default over the last 24 hours", Perm: "monitors.read", Query: []string{"threshold", "count_failed", "from", "to"}, Resp: storage.ApdexReport{}},
+	{Method: "GET", Path: "/api/v1/monitors/{id}/chart", Tag: "Monitors", Summary: "Response time series", Perm: "monitors.read", Query: []string{"range"}, Resp: fields{"points": []storage.TimeSeriesPoint{}}},
+	{Method: "GET", Path: "/api/v1/monitors/{id}/series", Tag: "Monitors", Summary: "Downsampled check time series for a custom range", Perm: "monitors.read", Query: []string{"from", "to", "points"},
+		Resp: fields{"monitor_id": int64(0), "from": "", "to": "", "bucket_seconds": int64(0), "points": []storage.TimeSeriesPoint{}}},
+	{Method: "GET", Path: "/api/v1/monitors/{id}/changes", Tag: "Monitors", Summary: "List content changes", Perm: "monitors.read", Query: pagination, Resp: paged{storage.ContentChange{}}},
+	{Method: "GET", Path: "/api/v1/monitors/{id}/transitions", Tag: "Monitors", Summary: "List recent status transitions", Perm: "monitors.read", Query: []string{"limit"}, Resp: list{storage.MonitorTransition{}}},
+
+	{Method: "GET", Path: "/api/v1/monitors/{id}/checks", Tag: "Checks", Summary: "List check results", Perm: "monitors.read", Query: append([]string{"include_headers"}, pagination...), Resp: paged{storage.CheckResult{}}},
+	{Method: "GET", Path: "/api/v1/monitors/{id}/checks.csv", Tag: "Checks", Summary: "Export check results as CSV", Perm: "monitors.read", Query: []string{"from", "to"}, Content: "text/csv"},
+	{Method: "GET", Path: "/api/v1/monitors/{id}/checks/{checkID}", Tag: "Checks", Summary: "Get a check result with its configuration snapshot", Perm: "monitors.read", Resp: fields{"check": storage.CheckResult{}, "config": storage.CheckConfig{}}},
+	{Method: "POST", Path: "/api/v1/monitors/{id}/checks/{checkID}/rerun", Tag: "Checks", Summary: "Run a check again", Perm: "monitors.write", Query: []string{"config"}, Resp: checkRerunResponse{}},
+
+	{Method: "GET", Path: "/api/v1/incidents", Tag: "Incidents", Summary: "List incidents", Perm: "incidents.read", Query: append([]string{"monitor_id", "status"}, pagination...), Resp: paged{storage.Incident{}}},
+	{Method: "GET", Path: "/api/v1/incidents/{id}", Tag: "Incidents", Summary: "Get an incident with its timeline", Perm: "incidents.read", Resp: fields{"incident": storage.Incident{}, "timeline": []storage.IncidentEvent{}}},
+	{Method: "DELETE", Path: "/api/v1/incidents/{id}", Tag: "Incidents", Summary: "Delete an incident", Perm: "incidents.write", Resp: statusResp},
+	{Method: "POST", Path: "/api/v1/incidents/{id}/ack", Tag: "Incidents", Summary: "Acknowledge an incident", Perm: "incidents.write", Body: fields{"ack_timeout_minutes": 0}, Resp: storage.Incident{}},
+	{Method: "POST", Path: "/api/v1/incidents/{id}/resolve", Tag: "Incidents", Summary: "Resolve an incident", Perm: "incidents.write", Resp: storage.Incident{}},
+	{Method: "GET", Path: "/api/v1/incidents/{id}/ack", Tag: "Incidents", Summary: "Confirmation page for a notification acknowledge link (HTML unless JSON is accepted)", Query: []string{"token"}, Resp: storage.Incident{}},
+	{Method: "GET", Path: "/api/v1/incidents/{id}/resolve", Tag: "Incidents", Summary: "Confirmation page for a notification resolve link (HTML unless JSON is accepted)", Query: []string{"token"}, Resp: storage.Incident{}},
+	{Method: "POST", Path: "/api/v1/incidents/{id}/ack/confirm", Tag: "Incidents", Summary: "Acknowledge an incident with a notification link token (HTML unless JSON is accepted)", Query: []string{"token"}, Resp: storage.Incident{}},
+	{Method: "POST", Path: "/api/v1/incidents/{id}/resolve/confirm", Tag: "Incidents", Summary: "Resolve an incident with a notification link token (HTML unless JSON is accepted)", Query: []string{"token"}, Resp: storage.Incident{}},
+	{Method: "PUT", Path: "/api/v1/incidents/{id}/postmortem", Tag: "Incidents", Summary: "Set an incident's postmortem", Perm: "incidents.write", Body: fields{"postmortem": ""}, Resp: storage.Incident{}},
+	{Method: "POST", Path: "/api/v1/incidents/{id}/events", Tag: "Incidents", Summary: "Add a note to an incident's timeline", Perm: "incidents.write", Body: fields{"message": ""}, Resp: storage.IncidentEvent{}, Status: http.StatusCreated},
+
+	{Method: "GET", Path: "/api/v1/notifications", Tag: "Notifications", Summary: "List notification channels", Perm: "notifications.read", Resp: list{storage.NotificationChannel{}}},
+	{Method: "POST", Path: "/api/v1/notifications", Tag: "Notifications", Summary: "Create a notification channel", Perm: "notifications.write", Body: storage.NotificationChannel{}, Resp: storage.NotificationChannel{}, Status: http.StatusCreated},
+	{Method: "PUT", Path: "/api/v1/notifications/{id}", Tag: "Notifications", Summary: "Update a notification channel", Perm: "notifications.write", Body: storage.NotificationChannel{}, Resp: storage.NotificationChannel{}},
+	{Method: "DELETE", Path: "/api/v1/notifications/{id}", Tag: "Notifications", Summary: "Delete a notification channel", Perm: "notifications.write", Resp: statusResp},
+	{Method: "POST", Path: "/api/v1/notifications/{id}/test", Tag: "Notifications", Summary: "Send a test notification", Perm: "notifications.write", Resp: statusResp},
+	{Method: "GET", Path: "/api/v1/notifications/history", Tag: "Notifications", Summary: "List notification deliveries", Perm: "notifications.read", Query: append([]string{"channel_id", "status", "event_type"}, pagination...), Resp: paged{storage.NotificationHistory{}}},
+
+	{Method: "GET", Path: "/api/v1/escalation-policies", Tag: "Notifications", Summary: "List escalation policies", Perm: "notifications.read", Resp: list{storage.EscalationPolicy{}}},
+	{Method: "POST", Path: "/api/v1/escalation-policies", Tag: "Notifications", Summary: "Create an escalation policy", Perm: "notifications.write", Body: storage.EscalationPolicy{}, Resp: storage.EscalationPolicy{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/escalation-policies/{id}", Tag: "Notifications", Summary: "Get an escalation policy", Perm: "notifications.read", Resp: storage.EscalationPolicy{}},
+	{Method: "PUT", Path: "/api/v1/escalation-policies/{id}", Tag: "Notifications", Summary: "Update an escalation policy", Perm: "notifications.write", Body: storage.EscalationPolicy{}, Resp: storage.EscalationPolicy{}},
+	{Method: "DELETE", Path: "/api/v1/escalation-policies/{id}", Tag: "Notifications", Summary: "Delete an escalation policy", Perm: "notifications.write", Resp: statusResp},
+
+	{Method: "GET", Path: "/api/v1/oncall-schedules", Tag: "Notifications", Summary: "List on-call schedules", Perm: "notifications.read", Resp: list{storage.OncallSchedule{}}},
+	{Method: "POST", Path: "/api/v1/oncall-schedules", Tag: "Notifications", Summary: "Create an on-call schedule", Perm: "notifications.write", Body: storage.OncallSchedule{}, Resp: storage.OncallSchedule{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/oncall-schedules/{id}", Tag: "Notifications", Summary: "Get an on-call schedule", Perm: "notifications.read", Resp: storage.OncallSchedule{}},
+	{Method: "PUT", Path: "/api/v1/oncall-schedules/{id}", Tag: "Notifications", Summary: "Update an on-call schedule", Perm: "notifications.write", Body: storage.OncallSchedule{}, Resp: storage.OncallSchedule{}},
+	{Method: "DELETE", Path: "/api/v1/oncall-schedules/{id}", Tag: "Notifications", Summary: "Delete an on-call schedule", Perm: "notifications.write", Resp: statusResp},
+	{Method: "GET", Path: "/api/v1/oncall-schedules/{id}/responder", Tag: "Notifications", Summary: "Who is on call", Perm: "notifications.read", Query: []string{"at"}, Resp: fields{"schedule_id": int64(0), "at": time.Time{}, "responder": storage.OncallMember{}}},
+
+	{Method: "GET", Path: "/api/v1/maintenance", Tag: "Maintenance", Summary: "List maintenance windows", Perm: "maintenance.read", Resp: list{storage.MaintenanceWindow{}}},
+	{Method: "POST", Path: "/api/v1/maintenance", Tag: "Maintenance", Summary: "Create a maintenance window", Perm: "maintenance.write", Body: storage.MaintenanceWindow{}, Resp: storage.MaintenanceWindow{}, Status: http.StatusCreated},
+	{Method: "PUT", Path: "/api/v1/maintenance/{id}", Tag: "Maintenance", Summary: "Update a maintenance window", Perm: "maintenance.write", Body: storage.MaintenanceWindow{}, Resp: storage.MaintenanceWindow{}},
+	{Method: "DELETE", Path: "/api/v1/maintenance/{id}", Tag: "Maintenance", Summary: "Delete a maintenance window", Perm: "maintenance.write", Resp: statusResp},
+	{Method: "GET", Path: "/api/v1/maintenance/{id}/next", Tag: "Maintenance", Summary: "Next occurrence of a maintenance window", Perm: "maintenance.read", Query: []string{"after"},
+		Resp: fields{"maintenance_id": int64(0), "next": fields{"start": time.Time{}, "end": time.Time{}, "active": false}}},
+
+	{Method: "GET", Path: "/api/v1/groups", Tag: "Groups", Summary: "List monitor groups", Perm: "monitors.read", Resp: list{storage.MonitorGroup{}}},
+	{Method: "POST", Path: "/api/v1/groups", Tag: "Groups", Summary: "Create a monitor group", Perm: "monitors.write", Body: storage.MonitorGroup{}, Resp: storage.MonitorGroup{}, Status: http.StatusCreated},
+	{Method: "PUT", Path: "/api/v1/groups/{id}", Tag: "Groups", Summary: "Update a monitor group", Perm: "monitors.write", Body: storage.MonitorGroup{}, Resp: storage.MonitorGroup{}},
+	{Method: "DELETE", Path: "/api/v1/groups/{id}", Tag: "Groups", Summary: "Delete a monitor group", Perm: "monitors.write", Resp: statusResp},
+	{Method: "GET", Path: "/api/v1/groups/{id}/status", Tag: "Groups", Summary: "Status rollup of a group's monitors", Perm: "monitors.read", Resp: storage.GroupStatus{}},
+	{Method: "GET", Path: "/api/v1/groups/{id}/uptime", Tag: "Groups", Summary: "Combined uptime of a group's monitors, by default for the previous calendar month", Perm: "monitors.read", Query: []string{"mode", "from", "to"}, Resp: storage.GroupUptime{}},
+	{Method: "GET", Path: "/api/v1/groups/{id}/sla", Tag: "Groups", Summary: "SLA report of a group's monitors, by default for the previous calendar month", Perm: "monitors.read", Query: []string{"from", "to"}, Resp: storage.GroupSLAReport{}},
+	{Method: "GET", Path: "/api/v1/limits", Tag: "Groups", Summary: "Monitor limits and usage", Perm: "monitors.read",
+		Resp: fields{"max_monitors": 0, "max_monitors_per_group": 0, "monitors": int64(0), "groups": []groupUsage{}}},
+
+	{Method: "GET", Path: "/api/v1/tags", Tag: "Tags", Summary: "List tags", Perm: "monitors.read", Resp: list{storage.Tag{}}},
+	{Method: "POST", Path: "/api/v1/tags", Tag: "Tags", Summary: "Create a tag", Perm: "monitors.write", Body: storage.Tag{}, Resp: storage.Tag{}, Status: http.StatusCreated},
+	{Method: "PUT", Path: "/api/v1/tags/{id}", Tag: "Tags", Summary: "Update a tag", Perm: "monitors.write", Body: storage.Tag{}, Resp: storage.Tag{}},
+	{Method: "DELETE", Path: "/api/v1/tags/{id}", Tag: "Tags", Summary: "Delete a tag", Perm: "monitors.write", Resp: statusResp},
+
+	{Method: "GET", Path: "/api/v1/proxies", Tag: "Proxies", Summary: "List proxies", Perm: "monitors.read", Resp: list{storage.Proxy{}}},
+	{Method: "POST", Path: "/api/v1/proxies", Tag: "Proxies", Summary: "Create a proxy", Perm: "monitors.write", Body: storage.Proxy{}, Resp: storage.Proxy{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/proxies/{id}", Tag: "Proxies", Summary: "Get a proxy", Perm: "monitors.read", Resp: storage.Proxy{}},
+	{Method: "PUT", Path: "/api/v1/proxies/{id}", Tag: "Proxies", Summary: "Update a proxy", Perm: "monitors.write", Body: storage.Proxy{}, Resp: storage.Proxy{}},
+	{Method: "DELETE", Path: "/api/v1/proxies/{id}", Tag: "Proxies", Summary: "Delete a proxy", Perm: "monitors.write", Resp: statusResp},
+
+	{Method: "GET", Path: "/api/v1/probes", Tag: "Probes", Summary: "List probes", Perm: "monitors.read", Resp: list{storage.Probe{}}},
+	{Method: "POST", Path: "/api/v1/probes", Tag: "Probes", Summary: "Create a probe", Perm: "monitors.write", Body: probeWithToken{}, Resp: probeWithToken{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/probes/{id}", Tag: "Probes", Summary: "Get a probe", Perm: "monitors.read", Resp: storage.Probe{}},
+	{Method: "PUT", Path: "/api/v1/probes/{id}", Tag: "Probes", Summary: "Update a probe", Perm: "monitors.write", Body: probeWithToken{}, Resp: storage.Probe{}},
+	{Method: "DELETE", Path: "/api/v1/probes/{id}", Tag: "Probes", Summary: "Delete a probe", Perm: "monitors.write", Resp: statusResp},
+	{Method: "POST", Path: "/api/v1/probe/check", Tag: "Probes", Summary: "Run a check for another instance", Perm: "monitors.write", Body: storage.Monitor{}, Resp: checker.Result{}},
+
+	{Method: "GET", Path: "/api/v1/status-pages", Tag: "Status pages", Summary: "List status pages", Perm: "monitors.read", Resp: list{storage.StatusPage{}}},
+	{Method: "POST", Path: "/api/v1/status-pages", Tag: "Status pages", Summary: "Create a status page", Perm: "monitors.write", Body: statusPageInput{}, Resp: storage.StatusPage{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/api/v1/status-pages/{id}", Tag: "Status pages", Summary: "Get a status page", Perm: "monitors.read",
+		Resp: fields{"status_page": storage.StatusPage{}, "monitors": []storage.StatusPageMonitor{}, "components": []storage.StatusPageComponent{}}},
+	{Method: "PUT", Path: "/api/v1/status-pages/{id}", Tag: "Status pages", Summary: "Update a status page", Perm: "monitors.write", Body: statusPageInput{}, Resp: storage.StatusPage{}},
+	{Method: "DELETE", Path: "/api/v1/status-pages/{id}", Tag: "Status pages", Summary: "Delete a status page", Perm: "monitors.write", Resp: statusResp},
+	{Method: "GET", Path: "/api/v1/status-pages/{id}/subscribers", Tag: "Status pages", Summary: "List email subscribers", Perm: "monitors.read", Resp: list{storage.StatusPageSubscriber{}}},
+	{Method: "POST", Path: "/api/v1/status-pages/{id}/api-token", Tag: "Status pages", Summary: "Regenerate the public API token", Perm: "monitors.write", Resp: fields{"api_token": ""}},
+	{Method: "DELETE", Path: "/api/v1/status-pages/{id}/api-token", Tag: "Status pages", Summary: "Remove the public API token", Perm: "monitors.write", Resp: statusResp},
+	{Method: "GET", Path: "/api/v1/status-pages/{id}/public", Tag: "Status pages", Summary: "Public status page data", Bearer: true,
+		Resp: fields{"page": map[string]string{}, "overall_status": "", "components": []fields{}, "monitors": []fields{}, "incidents": []fields{}}},
+
+	{Method: "GET", Path: "/api/v1/overview", Tag: "System", Summary: "Monitor counts by status", Perm: "monitors.read", Resp: fields{"monitors": map[string]int64{}}},
+	{Method: "GET", Path: "/api/v1/request-logs", Tag: "System", Summary: "List request logs", Perm: "metrics.read",
+		Query: append([]string{"group", "method", "status_code", "monitor_id", "from", "to"}, pagination...), Resp: paged{storage.RequestLog{}}},
+	{Method: "GET", Path: "/api/v1/request-logs/stats", Tag: "System", Summary: "Request log statistics", Perm: "metrics.read", Query: []string{"from", "to"}, Resp: storage.RequestLogStats{}},
+	{Method: "GET", Path: "/api/v1/audit", Tag: "System", Summary: "List the audit log", Perm: "metrics.read", Query: append([]string{"action", "entity", "api_key", "from", "to"}, pagination...), Resp: paged{storage.AuditEntry{}}},
+	{Method: "GET", Path: "/api/v1/db/size", Tag: "System", Summary: "Database size", Perm: "metrics.read", Resp: fields{"size_bytes": int64(0)}},
+	{Method: "POST", Path: "/api/v1/db/vacuum", Tag: "System", Summary: "Vacuum the database", Perm: "monitors.write", Resp: statusResp},
+	{Method: "GET", Path: "/api/v1/debug/scheduler", Tag: "System", Summary: "Scheduler state (super_admin keys only)", Perm: "metrics.read", Query: []string{"limit"}, Resp: monitor.SchedulerStats{}},
+
+	{Method: "GET", Path: "/api/v1/export", Tag: "Export", Summary: "Export the configuration", Perm: "monitors.read", Query: []string{"redact_secrets", "format"}, Resp: ExportData{}},
+	{Method: "POST", Path: "/api/v1/import", Tag: "Export", Summary: "Import a configuration export", Perm: "monitors.write", Query: []string{"mode", "format"}, Body: ExportData{}, Resp: ImportStats{}},
+}
+
+// statusPageInput documents the request body of status page create and
+// update.
+type statusPageInput struct {
+	storage.StatusPage
+	Monitors   []storage.StatusPageMonitor   `json:"monitors"`
+	Components []storage.StatusPageComponent `json:"components"`
+}
+
+// OpenAPI serves an OpenAPI 3.0 description of the /api/v1 routes.
+func (h *Handler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	server := h.cfg.Server.BasePath
+	if server == "" {
+		server = "/"
+	}
+	writeJSON(w, http.StatusOK, buildOpenAPI(server))
+}
+
+var pathParamRe = regexp.MustCompile(`\{([A-Za-z]+)\}`)
+
+func buildOpenAPI(server string) map[string]any {
+	sg := &schemaGen{components: map[string]any{}}
+	paths := map[string]map[string]any{}
+	for _, op := range openAPIOps {
+		var params []any
+		for _, m := range pathParamRe.FindAllStringSubmatch(op.Path, -1) {
+			schema := map[string]any{"type": "integer", "format": "int64"}
+			if m[1] == "token" {
+				schema = map[string]any{"type": "string"}
+			}
+			params = append(params, map[string]any{"name": m[1], "in": "path", "required": true, "schema": schema})
+		}
+		for _, q := range op.Query {
+			p := openAPIParams[q]
+			schema := map[string]any{"type": p.Type}
+			if p.Format != "" {
+				schema["format"] = p.Format
+			}
+			params = append(params, map[string]any{"name": q, "in": "query", "description": p.Description, "schema": schema})
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		resp := map[string]any{"description": http.StatusText(status)}
+		switch {
+		case op.Content != "":
+			resp["content"] = map[string]any{op.Content: map[string]any{"schema": map[string]any{"type": "string"}}}
+		case op.Resp != nil:
+			resp["content"] = map[string]any{"application/json": map[string]any{"schema": sg.sample(op.Resp)}}
+		}
+
+		operation := map[string]any{
+			"tags":        []string{op.Tag},
+			"summary":     op.Summary,
+			"operationId": operationID(op),
+			"responses": map[string]any{
+				strconv.Itoa(status): resp,
+				"default":            map[string]any{"$ref": "#/components/responses/Error"},
+			},
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+		if op.Body != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": sg.sample(op.Body)}},
+			}
+		}
+		if op.Perm != "" {
+			operation["security"] = []any{map[string][]string{"apiKey": {}}}
+			operation["description"] = "Requires the " + op.Perm + " permission."
+		} else if op.Bearer {
+			operation["security"] = []any{map[string][]string{}, map[string][]string{"statusPageToken": {}}}
+			operation["description"] = "Requires Authorization: Bearer with the page's API token when one is set."
+		} else {
+			operation["security"] = []any{}
+		}
+
+		if paths[op.Path] == nil {
+			paths[op.Path] = map[string]any{}
+		}
+		paths[op.Path][strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Asura API",
+			"version": "1",
+		},
+		"servers": []any{map[string]string{"url": server}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": sg.components,
+			"securitySchemes": map[string]any{
+				"apiKey":          map[string]string{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"statusPageToken": map[string]string{"type": "http", "scheme": "bearer"},
+			},
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "Error",
+					"content": map[string]any{"application/json": map[string]any{"schema": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"error": map[string]string{"type": "string"}},
+					}}},
+				},
+			},
+		},
+		"security": []any{map[string][]string{"apiKey": {}}},
+	}
+}
+
+// operationID derives a stable operationId such as getApiV1MonitorsId.
+func operationID(op openAPIOp) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, part := range strings.FieldsFunc(op.Path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '-' || r == '.'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// schemaGen turns Go values into OpenAPI schemas, following json struct tags.
+// Named struct types become components referenced by $ref.
+type schemaGen struct {
+	components map[string]any
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *schemaGen) sample(v any) map[string]any {
+	switch v := v.(type) {
+	case fields:
+		props := map[string]any{}
+		for name, sample := range v {
+			props[name] = g.sample(sample)
+		}
+		return map[string]any{"type": "object", "properties": props}
+	case []fields:
+		return map[string]any{"type": "array", "items": map[string]any{"type": "object"}}
+	case paged:
+		return map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"data":        map[string]any{"type": "array", "items": g.sample(v.of)},
+				"total":       map[string]any{"type": "integer", "format": "int64"},
+				"page":        map[string]any{"type": "integer"},
+				"per_page":    map[string]any{"type": "integer"},
+				"total_pages": map[string]any{"type": "integer"},
+			},
+		}
+	case list:
+		return map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"data": map[string]any{"type": "array", "items": g.sample(v.of)}},
+		}
+	}
+	return g.schema(reflect.TypeOf(v))
+}
+
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawJSONType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if _, isRef := s["$ref"]; isRef {
+			return map[string]any{"allOf": []any{s}, "nullable": true}
+		}
+		s["nullable"] = true
+		return s
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = map[string]any{} // placeholder for recursive types
+			g.components[name] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	g.addFields(t, props)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (g *schemaGen) addFields(t reflect.Type, props map[string]any) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			g.addFields(f.Type, props)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+	}
+}
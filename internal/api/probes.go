@@ -0,0 +1,189 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/y0f/asura/internal/httputil"
+	"github.com/y0f/asura/internal/storage"
+	"github.com/y0f/asura/internal/validate"
+)
+
+// probeWithToken is a probe with its agent API key, which is write-only: it
+// is accepted on create and update but only returned by create.
+type probeWithToken struct {
+	storage.Probe
+	Token string `json:"token,omitempty"`
+}
+
+func (h *Handler) ListProbes(w http.ResponseWriter, r *http.Request) {
+	probes, err := h.store.ListProbes(r.Context())
+	if err != nil {
+		h.logger.Error("list probes", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list probes")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": probes})
+}
+
+func (h *Handler) GetProbe(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	p, ok := h.loadProbe(w, r, id)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (h *Handler) CreateProbe(w http.ResponseWriter, r *http.Request) {
+	var input probeWithToken
+	if err := readJSON(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	p := input.Probe
+	p.Token = input.Token
+
+	if err := validate.ValidateProbe(&p); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.store.CreateProbe(r.Context(), &p); err != nil {
+		h.logger.Error("create probe", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create probe")
+		return
+	}
+
+	h.audit(r, "create", "probe", p.ID, "")
+	writeJSON(w, http.StatusCreated, probeWithToken{Probe: p, Token: p.Token})
+}
+
+// UpdateProbe replaces a probe. An empty token keeps the stored one, so
+// clients can update a probe without resending its secret.
+func (h *Handler) UpdateProbe(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	existing, ok := h.loadProbe(w, r, id)
+	if !ok {
+		return
+	}
+
+	var input probeWithToken
+	if err := readJSON(r, &input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	p := input.Probe
+	p.ID = id
+	p.Token = input.Token
+	if p.Token == "" && p.Type == existing.Type {
+		p.Token = existing.Token
+	}
+
+	if err := validate.ValidateProbe(&p); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.store.UpdateProbe(r.Context(), &p); err != nil {
+		h.logger.Error("update probe", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update probe")
+		return
+	}
+
+	updated, _ := h.store.GetProbe(r.Context(), id)
+	if updated == nil {
+		updated = &p
+	}
+
+	h.audit(r, "update", "probe", p.ID, "")
+	if h.pipeline != nil {
+		h.pipeline.ReloadMonitors()
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *Handler) DeleteProbe(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, ok := h.loadProbe(w, r, id); !ok {
+		return
+	}
+
+	if err := h.store.DeleteProbe(r.Context(), id); err != nil {
+		h.logger.Error("delete probe", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete probe")
+		return
+	}
+
+	h.audit(r, "delete", "probe", id, "")
+	if h.pipeline != nil {
+		h.pipeline.ReloadMonitors()
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// ProbeCheck runs a check on behalf of another Asura instance that uses this
+// one as an agent probe, and returns the raw result. Assertions are left to
+// the caller.
+func (h *Handler) ProbeCheck(w http.ResponseWriter, r *http.Request) {
+	if h.pipeline == nil {
+		writeError(w, http.StatusServiceUnavailable, "check runner is not available")
+		return
+	}
+
+	var m storage.Monitor
+	if err := readJSON(r, &m); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validate.ValidateMonitor(&m); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.pipeline.ProbeCheck(r.Context(), &m)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) loadProbe(w http.ResponseWriter, r *http.Request, id int64) (*storage.Probe, bool) {
+	p, err := h.store.GetProbe(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "probe not found")
+			return nil, false
+		}
+		h.logger.Error("get probe", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get probe")
+		return nil, false
+	}
+	return p, true
+}
+
+func (h *Handler) probesExist(w http.ResponseWriter, r *http.Request, ids []int64) bool {
+	for _, id := range ids {
+		if _, err := h.store.GetProbe(r.Context(), id); err != nil {
+			writeError(w, http.StatusBadRequest, "probe not found")
+			return false
+		}
+	}
+	return true
+}
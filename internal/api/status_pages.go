@@ -44,16 +44,24 @@ func (h *Handler) GetStatusPage(w http.ResponseWriter, r *http.Request) {
 		monitors = []storage.StatusPageMonitor{}
 	}
 
+	components, err := h.store.ListStatusPageComponents(r.Context(), id)
+	if err != nil {
+		h.logger.Error("get status page components", "error", err)
+		components = []storage.StatusPageComponent{}
+	}
+
 	writeJSON(w, http.StatusOK, map[string]any{
 		"status_page": sp,
 		"monitors":    monitors,
+		"components":  components,
 	})
 }
 
 func (h *Handler) CreateStatusPage(w http.ResponseWriter, r *http.Request) {
 	var input struct {
 		storage.StatusPage
-		Monitors []storage.StatusPageMonitor `json:"monitors"`
+		Monitors   []storage.StatusPageMonitor   `json:"monitors"`
+		Components []storage.StatusPageComponent `json:"components"`
 	}
 	if err := readJSON(r, &input); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
@@ -65,8 +73,16 @@ func (h *Handler) CreateStatusPage(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := validate.ValidateStatusPageComponents(input.Components); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	ctx := r.Context()
+	if err := httputil.CheckSubscriberChannel(ctx, h.store, sp); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	existing, err := h.store.GetStatusPageBySlug(ctx, sp.Slug)
 	if err == nil && existing != nil {
@@ -91,6 +107,14 @@ func (h *Handler) CreateStatusPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if len(input.Components) > 0 {
+		if err := h.store.SetStatusPageComponents(ctx, sp.ID, input.Components); err != nil {
+			h.logger.Error("set status page components", "error", err)
+			writeError(w, http.StatusInternalServerError, "status page created but failed to set components")
+			return
+		}
+	}
+
 	if h.OnStatusPageChange != nil {
 		h.OnStatusPageChange()
 	}
@@ -118,7 +142,8 @@ func (h *Handler) UpdateStatusPage(w http.ResponseWriter, r *http.Request) {
 
 	var input struct {
 		storage.StatusPage
-		Monitors *[]storage.StatusPageMonitor `json:"monitors"`
+		Monitors   *[]storage.StatusPageMonitor   `json:"monitors"`
+		Components *[]storage.StatusPageComponent `json:"components"`
 	}
 	if err := readJSON(r, &input); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
@@ -128,10 +153,22 @@ func (h *Handler) UpdateStatusPage(w http.ResponseWriter, r *http.Request) {
 	sp := &input.StatusPage
 	sp.ID = id
 	sp.PasswordHash = existing.PasswordHash
+	sp.APITokenHash = existing.APITokenHash
+	sp.APITokenEnabled = existing.APITokenEnabled
 	if err := validate.ValidateStatusPage(sp); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if input.Components != nil {
+		if err := validate.ValidateStatusPageComponents(*input.Components); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if err := httputil.CheckSubscriberChannel(ctx, h.store, sp); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	slugOwner, err := h.store.GetStatusPageBySlug(ctx, sp.Slug)
 	if err == nil && slugOwner != nil && slugOwner.ID != id {
@@ -156,6 +193,14 @@ func (h *Handler) UpdateStatusPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if input.Components != nil {
+		if err := h.store.SetStatusPageComponents(ctx, id, *input.Components); err != nil {
+			h.logger.Error("set status page components", "error", err)
+			writeError(w, http.StatusInternalServerError, "status page updated but failed to set components")
+			return
+		}
+	}
+
 	if h.OnStatusPageChange != nil {
 		h.OnStatusPageChange()
 	}
@@ -194,6 +239,90 @@ func (h *Handler) DeleteStatusPage(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
 
+// RegenerateStatusPageToken sets a new bearer token for the page's public
+// API, replacing any previous one. The token is only returned here; the page
+// keeps its hash.
+func (h *Handler) RegenerateStatusPageToken(w http.ResponseWriter, r *http.Request) {
+	sp, ok := h.statusPageForToken(w, r)
+	if !ok {
+		return
+	}
+	token, hash, err := httputil.GenerateStatusPageToken()
+	if err != nil {
+		h.logger.Error("generate status page token", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to generate token")
+		return
+	}
+	sp.APITokenHash = hash
+	if err := h.store.UpdateStatusPage(r.Context(), sp); err != nil {
+		h.logger.Error("update status page token", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to save token")
+		return
+	}
+	h.audit(r, "regenerate_token", "status_page", sp.ID, "")
+	writeJSON(w, http.StatusOK, map[string]string{"api_token": token})
+}
+
+// DeleteStatusPageToken makes the page's public API open to everyone again.
+func (h *Handler) DeleteStatusPageToken(w http.ResponseWriter, r *http.Request) {
+	sp, ok := h.statusPageForToken(w, r)
+	if !ok {
+		return
+	}
+	sp.APITokenHash = ""
+	if err := h.store.UpdateStatusPage(r.Context(), sp); err != nil {
+		h.logger.Error("delete status page token", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to remove token")
+		return
+	}
+	h.audit(r, "delete_token", "status_page", sp.ID, "")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+func (h *Handler) statusPageForToken(w http.ResponseWriter, r *http.Request) (*storage.StatusPage, bool) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	sp, err := h.store.GetStatusPage(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "status page not found")
+			return nil, false
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get status page")
+		return nil, false
+	}
+	return sp, true
+}
+
+func (h *Handler) ListStatusPageSubscribers(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := h.store.GetStatusPage(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "status page not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to get status page")
+		return
+	}
+
+	subs, err := h.store.ListStatusPageSubscribers(ctx, id)
+	if err != nil {
+		h.logger.Error("list status page subscribers", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list subscribers")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": subs})
+}
+
 func (h *Handler) PublicStatusPage(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
@@ -216,6 +345,11 @@ func (h *Handler) PublicStatusPage(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "status page is not enabled")
 		return
 	}
+	if !httputil.StatusPageTokenValid(r, sp.APITokenHash) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="status page"`)
+		writeError(w, http.StatusUnauthorized, "missing or invalid status page API token")
+		return
+	}
 
 	monitors, _, err := h.store.ListStatusPageMonitorsWithStatus(ctx, sp.ID)
 	if err != nil {
@@ -236,8 +370,39 @@ func (h *Handler) PublicStatusPage(w http.ResponseWriter, r *http.Request) {
 		DailyUptime []*storage.DailyUptime `json:"daily_uptime"`
 	}
 
+	type safeComponent struct {
+		ID          int64                  `json:"id"`
+		Name        string                 `json:"name"`
+		Description string                 `json:"description,omitempty"`
+		Status      string                 `json:"status"`
+		Uptime90d   float64                `json:"uptime_90d"`
+		DailyUptime []*storage.DailyUptime `json:"daily_uptime"`
+	}
+
+	comps, err := h.store.ListStatusPageComponents(ctx, sp.ID)
+	if err != nil {
+		h.logger.Error("public status page: list components", "error", err)
+		comps = nil
+	}
+	summaries, members := httputil.SummarizeComponents(ctx, h.store, comps, monitors, from, now)
+
+	components := make([]safeComponent, 0, len(summaries))
+	for _, cs := range summaries {
+		components = append(components, safeComponent{
+			ID:          cs.Component.ID,
+			Name:        cs.Component.Name,
+			Description: cs.Component.Description,
+			Status:      cs.Status,
+			Uptime90d:   cs.Uptime,
+			DailyUptime: cs.Daily,
+		})
+	}
+
 	result := make([]safeMonitor, 0, len(monitors))
 	for _, m := range monitors {
+		if members[m.ID] {
+			continue
+		}
 		daily, err := h.store.GetDailyUptime(ctx, m.ID, from, now)
 		if err != nil {
 			daily = []*storage.DailyUptime{}
@@ -258,14 +423,17 @@ func (h *Handler) PublicStatusPage(w http.ResponseWriter, r *http.Request) {
 
 	overall := httputil.OverallStatus(monitors)
 	incidents := httputil.PublicIncidentsForPage(ctx, h.store, sp, monitors, now)
+	httputil.RelabelComponentIncidents(incidents, summaries)
 
 	w.Header().Set("Cache-Control", "public, max-age=30")
 	writeJSON(w, http.StatusOK, map[string]any{
 		"page": map[string]string{
-			"title":       sp.Title,
-			"description": sp.Description,
+			"title":        sp.Title,
+			"description":  sp.Description,
+			"announcement": httputil.ActiveAnnouncement(sp, now),
 		},
 		"overall_status": overall,
+		"components":     components,
 		"monitors":       result,
 		"incidents":      incidents,
 	})
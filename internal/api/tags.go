@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"slices"
 
 	"github.com/y0f/asura/internal/httputil"
 	"github.com/y0f/asura/internal/storage"
@@ -17,6 +18,11 @@ func (h *Handler) ListTags(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to list tags")
 		return
 	}
+	if scope := httputil.GetMonitorScope(r.Context()); scope != nil {
+		tags = slices.DeleteFunc(tags, func(t *storage.Tag) bool {
+			return !slices.Contains(scope.TagIDs, t.ID)
+		})
+	}
 	writeJSON(w, http.StatusOK, map[string]any{"data": tags})
 }
 
@@ -2,14 +2,19 @@ package assertion
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/textproto"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 )
 
-func Evaluate(assertionsJSON json.RawMessage, statusCode int, body string,
+// Evaluate checks a result against a condition set. bodySize is the full
+// response body length, which may exceed len(body) when the stored body was
+// truncated.
+func Evaluate(assertionsJSON json.RawMessage, statusCode int, body string, bodySize int64,
 	headers map[string]string, responseTimeMs int64, certExpiry *int64, dnsRecords []string) AssertionResult {
 
 	var cs ConditionSet
@@ -23,7 +28,7 @@ func Evaluate(assertionsJSON json.RawMessage, statusCode int, body string,
 	degraded := false
 
 	for _, g := range cs.Groups {
-		gr := evalGroup(g, statusCode, body, headers, responseTimeMs, certExpiry, dnsRecords)
+		gr := evalGroup(g, statusCode, body, bodySize, headers, responseTimeMs, certExpiry, dnsRecords)
 		allDetails = append(allDetails, gr.Details...)
 		groupPasses = append(groupPasses, gr.Pass)
 		if !gr.Pass && gr.Message != "" {
@@ -44,7 +49,7 @@ func Evaluate(assertionsJSON json.RawMessage, statusCode int, body string,
 }
 
 
-func evalGroup(g ConditionGroup, statusCode int, body string,
+func evalGroup(g ConditionGroup, statusCode int, body string, bodySize int64,
 	headers map[string]string, responseTimeMs int64, certExpiry *int64, dnsRecords []string) AssertionResult {
 
 	if len(g.Conditions) == 0 {
@@ -57,7 +62,7 @@ func evalGroup(g ConditionGroup, statusCode int, body string,
 	degraded := false
 
 	for _, a := range g.Conditions {
-		detail := evaluateSingle(a, statusCode, body, headers, responseTimeMs, certExpiry, dnsRecords)
+		detail := evaluateSingle(a, statusCode, body, bodySize, headers, responseTimeMs, certExpiry, dnsRecords)
 		details = append(details, detail)
 		condPasses = append(condPasses, detail.Pass)
 		if !detail.Pass {
@@ -99,7 +104,7 @@ func combinePasses(passes []bool, operator string) bool {
 	return true
 }
 
-func evaluateSingle(a Assertion, statusCode int, body string,
+func evaluateSingle(a Assertion, statusCode int, body string, bodySize int64,
 	headers map[string]string, responseTimeMs int64, certExpiry *int64, dnsRecords []string) AssertionDetail {
 
 	switch a.Type {
@@ -115,6 +120,8 @@ func evaluateSingle(a Assertion, statusCode int, body string,
 		return evalHeader(a, headers)
 	case "response_time":
 		return evalResponseTime(a, responseTimeMs)
+	case "response_size":
+		return evalResponseSize(a, bodySize)
 	case "cert_expiry":
 		return evalCertExpiry(a, certExpiry)
 	case "dns_record":
@@ -178,6 +185,12 @@ func evalBodyRegex(a Assertion, body string) AssertionDetail {
 
 func evalJSONPath(a Assertion, body string) AssertionDetail {
 	val, err := walkJSONPath(body, a.Target)
+	if errors.Is(err, errInvalidJSON) {
+		return AssertionDetail{
+			Assertion: a, Pass: false,
+			Message: "json_path: response body is not valid JSON",
+		}
+	}
 	if err != nil {
 		if a.Operator == "exists" {
 			return AssertionDetail{
@@ -187,11 +200,11 @@ func evalJSONPath(a Assertion, body string) AssertionDetail {
 		}
 		return AssertionDetail{
 			Assertion: a, Pass: false,
-			Message: fmt.Sprintf("json_path: %v", err),
+			Message: fmt.Sprintf("json_path: %s matched nothing (%v)", a.Target, err),
 		}
 	}
 
-	actual := fmt.Sprintf("%v", val)
+	actual := jsonValueString(val)
 
 	if a.Operator == "exists" {
 		return AssertionDetail{Assertion: a, Pass: true, Actual: actual}
@@ -205,11 +218,39 @@ func evalJSONPath(a Assertion, body string) AssertionDetail {
 	return AssertionDetail{Assertion: a, Pass: pass, Actual: actual, Message: msg}
 }
 
+// JSONPathValue resolves path against the JSON document body and returns the
+// value rendered as json_path assertions compare it.
+func JSONPathValue(body, path string) (string, error) {
+	val, err := walkJSONPath(body, path)
+	if err != nil {
+		return "", err
+	}
+	return jsonValueString(val), nil
+}
+
+// jsonValueString renders a matched value for comparison: strings bare,
+// numbers without exponent, objects and arrays as compact JSON.
+func jsonValueString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return "null"
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
 func evalHeader(a Assertion, headers map[string]string) AssertionDetail {
-	headerName := a.Target
-	val, exists := headers[headerName]
+	headerName := strings.TrimSpace(a.Target)
+	val, exists := headers[textproto.CanonicalMIMEHeaderKey(headerName)]
 	if !exists {
-		// Try case-insensitive
+		// Header names are case-insensitive; fall back for non-canonical keys.
 		for k, v := range headers {
 			if strings.EqualFold(k, headerName) {
 				val = v
@@ -254,6 +295,17 @@ func evalResponseTime(a Assertion, responseTimeMs int64) AssertionDetail {
 	return AssertionDetail{Assertion: a, Pass: pass, Actual: actual, Message: msg}
 }
 
+func evalResponseSize(a Assertion, bodySize int64) AssertionDetail {
+	expected, _ := strconv.ParseInt(a.Value, 10, 64)
+	actual := strconv.FormatInt(bodySize, 10)
+	pass := compareInt64(bodySize, expected, a.Operator)
+	msg := ""
+	if !pass {
+		msg = fmt.Sprintf("response_size: expected %s %s bytes, got %d bytes", a.Operator, a.Value, bodySize)
+	}
+	return AssertionDetail{Assertion: a, Pass: pass, Actual: actual, Message: msg}
+}
+
 func evalCertExpiry(a Assertion, certExpiry *int64) AssertionDetail {
 	if certExpiry == nil {
 		return AssertionDetail{
@@ -307,14 +359,19 @@ func evalDNSRecord(a Assertion, dnsRecords []string) AssertionDetail {
 	}
 }
 
-// walkJSONPath walks a JSON document using dot notation with array indexing.
-// Examples: "status", "data.name", "items[0].id", "nested.list[2].value"
+// errInvalidJSON is returned by walkJSONPath when the body is not JSON.
+var errInvalidJSON = errors.New("invalid JSON body")
+
+// walkJSONPath resolves a path like "$.data.items[0].id" against a JSON
+// document using dot notation with array indexing. The leading "$" is
+// optional, so "data.items[0].id" is the same.
 func walkJSONPath(jsonStr string, path string) (any, error) {
 	var root any
 	if err := json.Unmarshal([]byte(jsonStr), &root); err != nil {
-		return nil, fmt.Errorf("invalid JSON body")
+		return nil, errInvalidJSON
 	}
 
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
 	parts := splitPath(path)
 	current := root
 
@@ -2,6 +2,7 @@ package assertion
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -17,12 +18,12 @@ func group(operator string, assertions ...Assertion) ConditionGroup {
 func TestStatusCodeAssertion(t *testing.T) {
 	raw := cs("and", group("and", Assertion{Type: "status_code", Operator: "eq", Value: "200"}))
 
-	result := Evaluate(raw, 200, "", nil, 100, nil, nil)
+	result := Evaluate(raw, 200, "", 0, nil, 100, nil, nil)
 	if !result.Pass {
 		t.Fatal("expected pass")
 	}
 
-	result = Evaluate(raw, 500, "", nil, 100, nil, nil)
+	result = Evaluate(raw, 500, "", 0, nil, 100, nil, nil)
 	if result.Pass {
 		t.Fatal("expected fail")
 	}
@@ -31,12 +32,12 @@ func TestStatusCodeAssertion(t *testing.T) {
 func TestBodyContainsAssertion(t *testing.T) {
 	raw := cs("and", group("and", Assertion{Type: "body_contains", Operator: "contains", Value: "hello"}))
 
-	result := Evaluate(raw, 200, "hello world", nil, 100, nil, nil)
+	result := Evaluate(raw, 200, "hello world", 0, nil, 100, nil, nil)
 	if !result.Pass {
 		t.Fatal("expected pass")
 	}
 
-	result = Evaluate(raw, 200, "goodbye", nil, 100, nil, nil)
+	result = Evaluate(raw, 200, "goodbye", 0, nil, 100, nil, nil)
 	if result.Pass {
 		t.Fatal("expected fail")
 	}
@@ -45,12 +46,12 @@ func TestBodyContainsAssertion(t *testing.T) {
 func TestBodyRegexAssertion(t *testing.T) {
 	raw := cs("and", group("and", Assertion{Type: "body_regex", Operator: "matches", Value: `\d{3}`}))
 
-	result := Evaluate(raw, 200, "code 200 ok", nil, 100, nil, nil)
+	result := Evaluate(raw, 200, "code 200 ok", 0, nil, 100, nil, nil)
 	if !result.Pass {
 		t.Fatal("expected pass")
 	}
 
-	result = Evaluate(raw, 200, "no numbers", nil, 100, nil, nil)
+	result = Evaluate(raw, 200, "no numbers", 0, nil, 100, nil, nil)
 	if result.Pass {
 		t.Fatal("expected fail")
 	}
@@ -71,11 +72,16 @@ func TestJSONPathAssertion(t *testing.T) {
 		{"items[1].id", "eq", "2", true},
 		{"missing", "exists", "", false},
 		{"status", "exists", "", true},
+		{"$.status", "eq", "ok", true},
+		{"$.data.count", "gt", "40", true},
+		{"$.items[1].id", "eq", "2", true},
+		{"$.data", "eq", `{"count":42}`, true},
+		{"$.missing", "eq", "ok", false},
 	}
 
 	for _, tt := range tests {
 		raw := cs("and", group("and", Assertion{Type: "json_path", Target: tt.target, Operator: tt.operator, Value: tt.value}))
-		result := Evaluate(raw, 200, body, nil, 100, nil, nil)
+		result := Evaluate(raw, 200, body, 0, nil, 100, nil, nil)
 		if result.Pass != tt.pass {
 			t.Fatalf("json_path %s %s %s: expected pass=%v, got %v (msg: %s)",
 				tt.target, tt.operator, tt.value, tt.pass, result.Pass, result.Message)
@@ -83,25 +89,89 @@ func TestJSONPathAssertion(t *testing.T) {
 	}
 }
 
+func TestJSONPathAssertionEdgeCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		target  string
+		op      string
+		value   string
+		pass    bool
+		wantMsg string
+	}{
+		{"invalid json", `<html>oops</html>`, "$.status", "eq", "ok", false, "not valid JSON"},
+		{"invalid json exists", `{"status":`, "$.status", "exists", "", false, "not valid JSON"},
+		{"no match", `{"data":{}}`, "$.data.healthy", "eq", "true", false, "matched nothing"},
+		{"index out of range", `{"items":[]}`, "$.items[0]", "eq", "1", false, "matched nothing"},
+		{"root array", `[{"id":7}]`, "$[0].id", "eq", "7", true, ""},
+		{"bool", `{"data":{"healthy":true}}`, "$.data.healthy", "eq", "true", true, ""},
+		{"null", `{"error":null}`, "$.error", "eq", "null", true, ""},
+		{"large number kept verbatim", `{"n":12345678}`, "$.n", "eq", "12345678", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := cs("and", group("and", Assertion{Type: "json_path", Target: tt.target, Operator: tt.op, Value: tt.value}))
+			result := Evaluate(raw, 200, tt.body, 0, nil, 100, nil, nil)
+			if result.Pass != tt.pass {
+				t.Fatalf("expected pass=%v, got %v (msg: %s)", tt.pass, result.Pass, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMsg) {
+				t.Fatalf("expected message containing %q, got %q", tt.wantMsg, result.Message)
+			}
+		})
+	}
+}
+
 func TestHeaderAssertion(t *testing.T) {
 	headers := map[string]string{"Content-Type": "application/json"}
 	raw := cs("and", group("and", Assertion{Type: "header", Target: "Content-Type", Operator: "contains", Value: "json"}))
 
-	result := Evaluate(raw, 200, "", headers, 100, nil, nil)
+	result := Evaluate(raw, 200, "", 0, headers, 100, nil, nil)
 	if !result.Pass {
 		t.Fatal("expected pass")
 	}
 }
 
+func TestHeaderAssertionOperators(t *testing.T) {
+	headers := map[string]string{
+		"Cache-Control": "no-cache, no-store",
+		"x-custom":      "Yes",
+	}
+	tests := []struct {
+		name string
+		a    Assertion
+		pass bool
+	}{
+		{"case-insensitive name", Assertion{Type: "header", Target: "cache-control", Operator: "eq", Value: "no-cache, no-store"}, true},
+		{"non-canonical key", Assertion{Type: "header", Target: "X-Custom", Operator: "eq", Value: "Yes"}, true},
+		{"value is case-sensitive", Assertion{Type: "header", Target: "X-Custom", Operator: "eq", Value: "yes"}, false},
+		{"joined values contain", Assertion{Type: "header", Target: "Cache-Control", Operator: "contains", Value: "no-store"}, true},
+		{"not contains", Assertion{Type: "header", Target: "Cache-Control", Operator: "not_contains", Value: "private"}, true},
+		{"neq", Assertion{Type: "header", Target: "X-Custom", Operator: "neq", Value: "No"}, true},
+		{"exists", Assertion{Type: "header", Target: " cache-control ", Operator: "exists"}, true},
+		{"exists missing", Assertion{Type: "header", Target: "Strict-Transport-Security", Operator: "exists"}, false},
+		{"compare missing", Assertion{Type: "header", Target: "Strict-Transport-Security", Operator: "contains", Value: "max-age"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate(cs("and", group("and", tt.a)), 200, "", 0, headers, 100, nil, nil)
+			if result.Pass != tt.pass {
+				t.Fatalf("expected pass=%v, got %v (msg: %s)", tt.pass, result.Pass, result.Message)
+			}
+		})
+	}
+}
+
 func TestResponseTimeAssertion(t *testing.T) {
 	raw := cs("and", group("and", Assertion{Type: "response_time", Operator: "lt", Value: "500"}))
 
-	result := Evaluate(raw, 200, "", nil, 200, nil, nil)
+	result := Evaluate(raw, 200, "", 0, nil, 200, nil, nil)
 	if !result.Pass {
 		t.Fatal("expected pass: 200 < 500")
 	}
 
-	result = Evaluate(raw, 200, "", nil, 600, nil, nil)
+	result = Evaluate(raw, 200, "", 0, nil, 600, nil, nil)
 	if result.Pass {
 		t.Fatal("expected fail: 600 < 500 should fail")
 	}
@@ -110,7 +180,7 @@ func TestResponseTimeAssertion(t *testing.T) {
 func TestDegradedAssertion(t *testing.T) {
 	raw := cs("and", group("and", Assertion{Type: "response_time", Operator: "lt", Value: "100", Degraded: true}))
 
-	result := Evaluate(raw, 200, "", nil, 200, nil, nil)
+	result := Evaluate(raw, 200, "", 0, nil, 200, nil, nil)
 	if result.Pass {
 		t.Fatal("expected fail")
 	}
@@ -123,7 +193,7 @@ func TestDNSRecordAssertion(t *testing.T) {
 	records := []string{"1.2.3.4", "5.6.7.8"}
 	raw := cs("and", group("and", Assertion{Type: "dns_record", Operator: "contains", Value: "1.2.3.4"}))
 
-	result := Evaluate(raw, 0, "", nil, 0, nil, records)
+	result := Evaluate(raw, 0, "", 0, nil, 0, nil, records)
 	if !result.Pass {
 		t.Fatal("expected pass")
 	}
@@ -147,12 +217,12 @@ func TestConditionSetAND(t *testing.T) {
 		group("and", Assertion{Type: "body_contains", Operator: "contains", Value: "ok"}),
 	)
 
-	result := Evaluate(raw, 200, "ok", nil, 100, nil, nil)
+	result := Evaluate(raw, 200, "ok", 0, nil, 100, nil, nil)
 	if !result.Pass {
 		t.Fatal("expected pass when both groups pass")
 	}
 
-	result = Evaluate(raw, 200, "nope", nil, 100, nil, nil)
+	result = Evaluate(raw, 200, "nope", 0, nil, 100, nil, nil)
 	if result.Pass {
 		t.Fatal("expected fail when one group fails (AND)")
 	}
@@ -164,17 +234,17 @@ func TestConditionSetOR(t *testing.T) {
 		group("and", Assertion{Type: "status_code", Operator: "eq", Value: "201"}),
 	)
 
-	result := Evaluate(raw, 200, "", nil, 100, nil, nil)
+	result := Evaluate(raw, 200, "", 0, nil, 100, nil, nil)
 	if !result.Pass {
 		t.Fatal("expected pass for 200 (OR)")
 	}
 
-	result = Evaluate(raw, 201, "", nil, 100, nil, nil)
+	result = Evaluate(raw, 201, "", 0, nil, 100, nil, nil)
 	if !result.Pass {
 		t.Fatal("expected pass for 201 (OR)")
 	}
 
-	result = Evaluate(raw, 500, "", nil, 100, nil, nil)
+	result = Evaluate(raw, 500, "", 0, nil, 100, nil, nil)
 	if result.Pass {
 		t.Fatal("expected fail for 500 (OR, neither group passes)")
 	}
@@ -186,17 +256,17 @@ func TestConditionGroupOR(t *testing.T) {
 		Assertion{Type: "status_code", Operator: "eq", Value: "201"},
 	))
 
-	result := Evaluate(raw, 200, "", nil, 100, nil, nil)
+	result := Evaluate(raw, 200, "", 0, nil, 100, nil, nil)
 	if !result.Pass {
 		t.Fatal("expected pass for 200 (inner OR)")
 	}
 
-	result = Evaluate(raw, 201, "", nil, 100, nil, nil)
+	result = Evaluate(raw, 201, "", 0, nil, 100, nil, nil)
 	if !result.Pass {
 		t.Fatal("expected pass for 201 (inner OR)")
 	}
 
-	result = Evaluate(raw, 500, "", nil, 100, nil, nil)
+	result = Evaluate(raw, 500, "", 0, nil, 100, nil, nil)
 	if result.Pass {
 		t.Fatal("expected fail for 500 (inner OR)")
 	}
@@ -205,7 +275,7 @@ func TestConditionGroupOR(t *testing.T) {
 func TestConditionSetDegradedPropagation(t *testing.T) {
 	raw := cs("and", group("and", Assertion{Type: "response_time", Operator: "lt", Value: "100", Degraded: true}))
 
-	result := Evaluate(raw, 200, "", nil, 500, nil, nil)
+	result := Evaluate(raw, 200, "", 0, nil, 500, nil, nil)
 	if result.Pass {
 		t.Fatal("expected fail")
 	}
@@ -213,3 +283,23 @@ func TestConditionSetDegradedPropagation(t *testing.T) {
 		t.Fatal("expected degraded=true")
 	}
 }
+
+func TestResponseSize(t *testing.T) {
+	raw := cs("and", group("and",
+		Assertion{Type: "response_size", Operator: "gt", Value: "1000"},
+		Assertion{Type: "response_size", Operator: "lt", Value: "5000"},
+	))
+
+	result := Evaluate(raw, 200, "truncated", 2048, nil, 100, nil, nil)
+	if !result.Pass {
+		t.Fatalf("expected pass on the full size, got: %s", result.Message)
+	}
+
+	result = Evaluate(raw, 200, "", 120, nil, 100, nil, nil)
+	if result.Pass {
+		t.Fatal("expected fail for a shrunken body")
+	}
+	if result.Message != "response_size: expected gt 1000 bytes, got 120 bytes" {
+		t.Fatalf("unexpected message: %s", result.Message)
+	}
+}
@@ -0,0 +1,149 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/safenet"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// AMQPChecker reads a RabbitMQ queue's depth from the management API.
+type AMQPChecker struct {
+	AllowPrivate bool
+}
+
+func (c *AMQPChecker) Type() string { return "amqp" }
+
+type amqpQueueInfo struct {
+	Messages  int64 `json:"messages"`
+	Consumers int   `json:"consumers"`
+}
+
+func (c *AMQPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Result, error) {
+	var settings storage.AMQPSettings
+	if len(monitor.Settings) > 0 {
+		if err := json.Unmarshal(monitor.Settings, &settings); err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: %v", err)}, nil
+		}
+	}
+
+	endpoint, err := amqpQueueURL(monitor.Target, settings)
+	if err != nil {
+		return &Result{Status: "down", Message: err.Error()}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return &Result{Status: "down", Message: fmt.Sprintf("invalid request: %v", err)}, nil
+	}
+	req.Header.Set("Accept", "application/json")
+	if settings.Username != "" {
+		req.SetBasicAuth(settings.Username, settings.Password)
+	}
+
+	timeout := time.Duration(monitor.Timeout) * time.Second
+	baseDial := sourceDial(&net.Dialer{
+		Timeout: timeout,
+		Control: safenet.MaybeDialControl(c.AllowPrivate),
+	}, monitor.SourceAddr)
+
+	transport := &http.Transport{
+		DialContext:       baseDial,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: settings.SkipTLSVerify},
+		DisableKeepAlives: true,
+	}
+	applyHTTPProxy(transport, monitor.ProxyURL, baseDial)
+	client := &http.Client{Transport: transport, Timeout: timeout}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		return &Result{
+			Status:       "down",
+			ResponseTime: elapsed,
+			Message:      fmt.Sprintf("management API request failed: %v", err),
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return &Result{
+			Status:       "down",
+			ResponseTime: elapsed,
+			StatusCode:   resp.StatusCode,
+			Message:      fmt.Sprintf("queue %q not found in vhost %q", settings.Queue, amqpVHost(settings)),
+		}, nil
+	case resp.StatusCode != http.StatusOK:
+		return &Result{
+			Status:       "down",
+			ResponseTime: elapsed,
+			StatusCode:   resp.StatusCode,
+			Message:      fmt.Sprintf("management API returned HTTP %d", resp.StatusCode),
+		}, nil
+	}
+
+	var info amqpQueueInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return &Result{
+			Status:       "down",
+			ResponseTime: elapsed,
+			StatusCode:   resp.StatusCode,
+			Message:      fmt.Sprintf("invalid management API response: %v", err),
+		}, nil
+	}
+
+	status, message := amqpDepthStatus(settings, info.Messages)
+	value, _ := json.Marshal(map[string]any{
+		"queue":     settings.Queue,
+		"messages":  info.Messages,
+		"consumers": info.Consumers,
+	})
+	return &Result{
+		Status:       status,
+		ResponseTime: elapsed,
+		StatusCode:   resp.StatusCode,
+		Message:      message,
+		Body:         string(value),
+	}, nil
+}
+
+// amqpQueueURL builds the management API queue endpoint from the monitor
+// target, e.g. http://rabbit:15672 -> http://rabbit:15672/api/queues/%2F/orders.
+func amqpQueueURL(target string, settings storage.AMQPSettings) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("target must be the management API URL, e.g. http://rabbitmq:15672")
+	}
+	base := strings.TrimRight(u.Scheme+"://"+u.Host+u.EscapedPath(), "/")
+	return base + "/api/queues/" + url.PathEscape(amqpVHost(settings)) + "/" + url.PathEscape(settings.Queue), nil
+}
+
+func amqpVHost(settings storage.AMQPSettings) string {
+	if settings.VHost == "" {
+		return "/"
+	}
+	return settings.VHost
+}
+
+func amqpDepthStatus(settings storage.AMQPSettings, depth int64) (string, string) {
+	switch {
+	case settings.CritDepth > 0 && depth >= settings.CritDepth:
+		return "down", fmt.Sprintf("queue %s depth %d exceeds critical threshold %d", settings.Queue, depth, settings.CritDepth)
+	case settings.WarnDepth > 0 && depth >= settings.WarnDepth:
+		return "degraded", fmt.Sprintf("queue %s depth %d exceeds warning threshold %d", settings.Queue, depth, settings.WarnDepth)
+	default:
+		return "up", fmt.Sprintf("queue %s depth %d", settings.Queue, depth)
+	}
+}
@@ -0,0 +1,84 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestAMQPChecker(t *testing.T) {
+	var gotPath, gotUser string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotUser, _, _ = r.BasicAuth()
+		if !strings.HasSuffix(r.URL.Path, "/orders") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"name":"orders","messages":1500,"consumers":2}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		settings   storage.AMQPSettings
+		wantStatus string
+		wantMsg    string
+	}{
+		{"below thresholds", storage.AMQPSettings{Queue: "orders", WarnDepth: 2000, CritDepth: 5000}, "up", "depth 1500"},
+		{"warning", storage.AMQPSettings{Queue: "orders", WarnDepth: 1000, CritDepth: 5000}, "degraded", "warning threshold 1000"},
+		{"critical", storage.AMQPSettings{Queue: "orders", WarnDepth: 100, CritDepth: 1500}, "down", "critical threshold 1500"},
+		{"missing queue", storage.AMQPSettings{Queue: "payments"}, "down", "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.settings.Username = "monitor"
+			settings, _ := json.Marshal(tt.settings)
+			c := &AMQPChecker{AllowPrivate: true}
+			result, err := c.Check(context.Background(), &storage.Monitor{
+				Type: "amqp", Target: server.URL, Timeout: 5, Settings: settings,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q (%s)", result.Status, tt.wantStatus, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want substring %q", result.Message, tt.wantMsg)
+			}
+			if gotUser != "monitor" {
+				t.Errorf("basic auth user = %q, want monitor", gotUser)
+			}
+		})
+	}
+
+	if gotPath != "/api/queues/%2F/payments" {
+		t.Errorf("path = %q, want default vhost escaped", gotPath)
+	}
+}
+
+func TestAMQPCheckerReportsDepthInBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"messages":42,"consumers":1}`))
+	}))
+	defer server.Close()
+
+	c := &AMQPChecker{AllowPrivate: true}
+	result, _ := c.Check(context.Background(), &storage.Monitor{
+		Type: "amqp", Target: server.URL, Timeout: 5,
+		Settings: json.RawMessage(`{"queue":"jobs","vhost":"prod"}`),
+	})
+	var body struct {
+		Messages int64 `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &body); err != nil || body.Messages != 42 {
+		t.Errorf("body = %q, want messages 42", result.Body)
+	}
+}
@@ -4,22 +4,39 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/y0f/asura/internal/storage"
 )
 
-// Result holds the outcome of a protocol check.
+// Result holds the outcome of a protocol check. The JSON form is what a
+// probe agent returns.
 type Result struct {
-	Status          string // "up", "down", "degraded"
-	ResponseTime    int64  // milliseconds
-	StatusCode      int
-	Message         string
-	Headers         map[string]string
-	Body            string
-	BodyHash        string
-	CertExpiry      *int64 // unix timestamp
-	CertFingerprint string // SHA-256 hex fingerprint of leaf cert
-	DNSRecords      []string
+	Status          string            `json:"status"`        // "up", "down", "degraded"
+	ResponseTime    int64             `json:"response_time"` // milliseconds
+	StatusCode      int               `json:"status_code,omitempty"`
+	Message         string            `json:"message,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	BodySize        int64             `json:"body_size,omitempty"`    // full body length before truncation or decompression
+	DecodedSize     int64             `json:"decoded_size,omitempty"` // full body length after decompression, when decompressed
+	BodyHash        string            `json:"body_hash,omitempty"`
+	CertExpiry      *int64            `json:"cert_expiry,omitempty"`      // unix timestamp
+	CertFingerprint string            `json:"cert_fingerprint,omitempty"` // SHA-256 hex fingerprint of leaf cert
+	DNSRecords      []string          `json:"dns_records,omitempty"`
+	TLS             *TLSDetails       `json:"tls,omitempty"`
+}
+
+// TLSDetails describes the negotiated TLS session and leaf certificate.
+type TLSDetails struct {
+	Version     string    `json:"version"`
+	CipherSuite string    `json:"cipher_suite"`
+	ServerName  string    `json:"server_name,omitempty"`
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	DNSNames    []string  `json:"dns_names,omitempty"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
 }
 
 // Checker performs a protocol-specific check against a target.
@@ -70,5 +87,23 @@ func DefaultRegistry(commandAllowlist []string, allowPrivateTargets bool) *Regis
 	r.Register(&DomainChecker{AllowPrivate: allowPrivateTargets})
 	r.Register(&GRPCChecker{AllowPrivate: allowPrivateTargets})
 	r.Register(&MQTTChecker{AllowPrivate: allowPrivateTargets})
+	r.Register(&AMQPChecker{AllowPrivate: allowPrivateTargets})
+	r.Register(&S3Checker{AllowPrivate: allowPrivateTargets})
+	r.Register(&SMTPChecker{AllowPrivate: allowPrivateTargets})
+	r.Register(&RedisChecker{AllowPrivate: allowPrivateTargets})
+	r.Register(&SSHChecker{AllowPrivate: allowPrivateTargets})
+	r.Register(&NTPChecker{AllowPrivate: allowPrivateTargets})
+	r.Register(&KafkaChecker{AllowPrivate: allowPrivateTargets})
+	r.Register(&TransactionChecker{AllowPrivate: allowPrivateTargets})
 	return r
 }
+
+// connectTimeout bounds dialing the monitor's target: its ConnectTimeout, or
+// Timeout when that is unset. The check context carries Timeout as the
+// deadline for the whole check.
+func connectTimeout(m *storage.Monitor) time.Duration {
+	if m.ConnectTimeout > 0 {
+		return time.Duration(m.ConnectTimeout) * time.Second
+	}
+	return time.Duration(m.Timeout) * time.Second
+}
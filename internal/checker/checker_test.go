@@ -1,13 +1,21 @@
 package checker
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"encoding/json"
 	"io"
+	"mime"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/y0f/asura/internal/storage"
 )
@@ -93,43 +101,103 @@ func TestHTTPCheckerDown(t *testing.T) {
 	}
 }
 
+func TestConnectTimeout(t *testing.T) {
+	if got := connectTimeout(&storage.Monitor{Timeout: 10}); got != 10*time.Second {
+		t.Errorf("unset: got %v, want the monitor timeout", got)
+	}
+	if got := connectTimeout(&storage.Monitor{Timeout: 10, ConnectTimeout: 2}); got != 2*time.Second {
+		t.Errorf("set: got %v, want 2s", got)
+	}
+}
+
+func TestHTTPCheckerSlowResponseWithinTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The connect timeout only bounds dialing, so a response slower than it
+	// but within the overall timeout is up.
+	c := &HTTPChecker{AllowPrivate: true}
+	result, err := c.Check(context.Background(), &storage.Monitor{Target: server.URL, Timeout: 3, ConnectTimeout: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "up" {
+		t.Fatalf("expected up, got %s: %s", result.Status, result.Message)
+	}
+}
+
 func TestHTTPCheckerExpectedStatus(t *testing.T) {
 	tests := []struct {
 		name           string
 		serverStatus   int
-		expectedStatus int
+		expectedStatus storage.StatusSpec
 		wantStatus     string
 		wantMessage    string
 	}{
 		{
 			name:           "matching expected status",
 			serverStatus:   200,
-			expectedStatus: 200,
+			expectedStatus: "200",
 			wantStatus:     "up",
 		},
 		{
 			name:           "mismatched expected status",
 			serverStatus:   502,
-			expectedStatus: 200,
+			expectedStatus: "200",
 			wantStatus:     "down",
 			wantMessage:    "expected status 200, got 502",
 		},
 		{
 			name:           "no expected status set",
 			serverStatus:   502,
-			expectedStatus: 0,
+			expectedStatus: "",
+			wantStatus:     "down",
+			wantMessage:    "expected a 2xx or 3xx status, got 502",
+		},
+		{
+			name:           "no expected status set accepts 2xx",
+			serverStatus:   204,
+			expectedStatus: "",
+			wantStatus:     "up",
+		},
+		{
+			name:           "status in range",
+			serverStatus:   204,
+			expectedStatus: "200-299,401",
+			wantStatus:     "up",
+		},
+		{
+			name:           "listed code outside the range",
+			serverStatus:   401,
+			expectedStatus: "200-299, 401",
+			wantStatus:     "up",
+		},
+		{
+			name:           "status class",
+			serverStatus:   503,
+			expectedStatus: "5xx",
 			wantStatus:     "up",
 		},
+		{
+			name:           "status outside the spec",
+			serverStatus:   403,
+			expectedStatus: "200-299,401",
+			wantStatus:     "down",
+			wantMessage:    "expected status 200-299,401, got 403",
+		},
 		{
 			name:           "expected non-200 status matches",
 			serverStatus:   201,
-			expectedStatus: 201,
+			expectedStatus: "201",
 			wantStatus:     "up",
 		},
 		{
 			name:           "expected non-200 status mismatches",
 			serverStatus:   200,
-			expectedStatus: 201,
+			expectedStatus: "201",
 			wantStatus:     "down",
 			wantMessage:    "expected status 201, got 200",
 		},
@@ -180,7 +248,7 @@ func TestRegistryGetUnregistered(t *testing.T) {
 
 func TestDefaultRegistryHasAllTypes(t *testing.T) {
 	r := DefaultRegistry(nil, false)
-	types := []string{"http", "tcp", "dns", "icmp", "tls", "websocket", "command", "docker"}
+	types := []string{"http", "tcp", "dns", "icmp", "tls", "websocket", "command", "docker", "amqp", "s3", "smtp", "redis", "ssh", "ntp", "kafka"}
 	for _, typ := range types {
 		if _, err := r.Get(typ); err != nil {
 			t.Fatalf("expected %s checker, got error: %v", typ, err)
@@ -234,7 +302,7 @@ func TestHTTPCheckerUpsideDownIgnored(t *testing.T) {
 	})
 
 	t.Run("expected status mismatch returns down", func(t *testing.T) {
-		settings, _ := json.Marshal(storage.HTTPSettings{ExpectedStatus: 404})
+		settings, _ := json.Marshal(storage.HTTPSettings{ExpectedStatus: "404"})
 		mon := &storage.Monitor{Target: server.URL, Timeout: 5, UpsideDown: true, Settings: settings}
 		result, err := c.Check(context.Background(), mon)
 		if err != nil {
@@ -331,6 +399,75 @@ func TestHTTPCheckerBodyEncoding(t *testing.T) {
 	}
 }
 
+func TestHTTPCheckerMultipartBody(t *testing.T) {
+	var gotCT, gotFile, gotFileCT string
+	var gotFields url.Values
+	var gotLength int64
+	var boundaries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCT = r.Header.Get("Content-Type")
+		gotLength = r.ContentLength
+		_, params, _ := mime.ParseMediaType(gotCT)
+		boundaries = append(boundaries, params["boundary"])
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		gotFields = url.Values(r.MultipartForm.Value)
+		if fh := r.MultipartForm.File["upload"]; len(fh) == 1 {
+			f, _ := fh[0].Open()
+			b, _ := io.ReadAll(f)
+			gotFile = fh[0].Filename + ":" + string(b)
+			gotFileCT = fh[0].Header.Get("Content-Type")
+		}
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	settings, _ := json.Marshal(storage.HTTPSettings{
+		Method:       "POST",
+		Body:         "name=asura\r\n\nquery=a=b\n",
+		BodyEncoding: "multipart",
+		Headers:      map[string]string{"Content-Type": "text/plain"},
+		MultipartFile: &storage.MultipartFile{
+			Field: "upload", Filename: "probe.txt", Content: "hello",
+		},
+	})
+	c := &HTTPChecker{AllowPrivate: true}
+	mon := &storage.Monitor{Target: server.URL, Timeout: 5, Settings: settings}
+	for range 2 {
+		result, err := c.Check(context.Background(), mon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != "up" {
+			t.Fatalf("status = %s: %s (Content-Type %q)", result.Status, result.Message, gotCT)
+		}
+	}
+
+	if !strings.HasPrefix(gotCT, "multipart/form-data; boundary=") {
+		t.Errorf("Content-Type = %q", gotCT)
+	}
+	if boundaries[0] == boundaries[1] {
+		t.Error("expected a new boundary per request")
+	}
+	if gotLength <= 0 {
+		t.Errorf("Content-Length = %d, want the body size", gotLength)
+	}
+	if gotFields.Get("name") != "asura" || gotFields.Get("query") != "a=b" {
+		t.Errorf("fields = %v", gotFields)
+	}
+	if gotFile != "probe.txt:hello" || gotFileCT != "application/octet-stream" {
+		t.Errorf("file part = %q (%s)", gotFile, gotFileCT)
+	}
+
+	settings, _ = json.Marshal(storage.HTTPSettings{Method: "POST", Body: "no separator", BodyEncoding: "multipart"})
+	result, _ := c.Check(context.Background(), &storage.Monitor{Target: server.URL, Timeout: 5, Settings: settings})
+	if result.Status != "down" || !strings.Contains(result.Message, "line 1") {
+		t.Errorf("expected malformed body to fail, got %s: %s", result.Status, result.Message)
+	}
+}
+
 func TestHTTPCheckerAuthMethods(t *testing.T) {
 	t.Run("basic auth", func(t *testing.T) {
 		var gotAuth string
@@ -466,3 +603,244 @@ func TestHTTPCheckerRequestBody(t *testing.T) {
 		t.Errorf("body = %q", receivedBody)
 	}
 }
+
+func TestHTTPCheckerRange(t *testing.T) {
+	content := strings.NewReader(strings.Repeat("0123456789", 1000))
+	var gotRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		if r.URL.Path == "/ignore" {
+			w.Write([]byte(strings.Repeat("x", 10000)))
+			return
+		}
+		http.ServeContent(w, r, "asset.bin", time.Time{}, content)
+	}))
+	defer server.Close()
+
+	settings, _ := json.Marshal(storage.HTTPSettings{RangeBytes: 16})
+	c := &HTTPChecker{AllowPrivate: true}
+
+	t.Run("partial content", func(t *testing.T) {
+		mon := &storage.Monitor{Target: server.URL, Timeout: 5, Settings: settings}
+		result, err := c.Check(context.Background(), mon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotRange != "bytes=0-15" {
+			t.Errorf("Range header = %q", gotRange)
+		}
+		if result.Status != "up" || result.StatusCode != http.StatusPartialContent {
+			t.Fatalf("expected up with 206, got %s/%d: %s", result.Status, result.StatusCode, result.Message)
+		}
+		if result.Body != "0123456789012345" {
+			t.Errorf("body = %q", result.Body)
+		}
+	})
+
+	t.Run("range ignored", func(t *testing.T) {
+		mon := &storage.Monitor{Target: server.URL + "/ignore", Timeout: 5, Settings: settings}
+		result, err := c.Check(context.Background(), mon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != "down" || !strings.Contains(result.Message, "206") {
+			t.Fatalf("expected down for 200 response, got %s: %s", result.Status, result.Message)
+		}
+		if len(result.Body) != 16 {
+			t.Errorf("expected only 16 bytes read, got %d", len(result.Body))
+		}
+	})
+}
+
+func TestHTTPCheckerMaxTTFB(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	settings, _ := json.Marshal(storage.HTTPSettings{MaxTTFBMs: 10})
+	c := &HTTPChecker{AllowPrivate: true}
+	mon := &storage.Monitor{Target: server.URL, Timeout: 5, Settings: settings}
+	result, err := c.Check(context.Background(), mon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "degraded" || !strings.Contains(result.Message, "time to first byte") {
+		t.Fatalf("expected degraded, got %s: %s", result.Status, result.Message)
+	}
+}
+
+func TestHTTPCheckerBodySize(t *testing.T) {
+	large := strings.Repeat("a", maxBodyRead+100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gzip":
+			if r.Header.Get("Accept-Encoding") != "gzip, deflate" {
+				t.Errorf("expected gzip and deflate to be negotiated, got %q", r.Header.Get("Accept-Encoding"))
+			}
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			zw.Write([]byte(strings.Repeat("hello ", 100)))
+			zw.Close()
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+			w.Write(buf.Bytes())
+		case "/large":
+			w.(http.Flusher).Flush() // chunked, no Content-Length
+			io.WriteString(w, large)
+		}
+	}))
+	defer server.Close()
+
+	c := &HTTPChecker{AllowPrivate: true}
+
+	t.Run("measured before decompression", func(t *testing.T) {
+		result, err := c.Check(context.Background(), &storage.Monitor{Target: server.URL + "/gzip", Timeout: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Body != strings.Repeat("hello ", 100) {
+			t.Fatalf("expected decompressed body, got %q", result.Body)
+		}
+		if result.BodySize == 0 || result.BodySize >= int64(len(result.Body)) {
+			t.Fatalf("expected compressed size below %d, got %d", len(result.Body), result.BodySize)
+		}
+		if result.DecodedSize != int64(len(result.Body)) {
+			t.Fatalf("expected decoded size %d, got %d", len(result.Body), result.DecodedSize)
+		}
+		if _, ok := result.Headers["Content-Encoding"]; ok {
+			t.Fatal("expected Content-Encoding to be removed after decompression")
+		}
+	})
+
+	t.Run("measured past truncation", func(t *testing.T) {
+		result, err := c.Check(context.Background(), &storage.Monitor{Target: server.URL + "/large", Timeout: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Body) != maxBodyRead {
+			t.Fatalf("expected stored body truncated to %d, got %d", maxBodyRead, len(result.Body))
+		}
+		if result.BodySize != int64(len(large)) {
+			t.Fatalf("expected body size %d, got %d", len(large), result.BodySize)
+		}
+	})
+}
+
+func TestHTTPCheckerDecompression(t *testing.T) {
+	const text = "compressed payload compressed payload"
+	var gz, zl, raw bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(text))
+	zw.Close()
+	lw := zlib.NewWriter(&zl)
+	lw.Write([]byte(text))
+	lw.Close()
+	fw, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	fw.Write([]byte(text))
+	fw.Close()
+	corrupt := bytes.Clone(gz.Bytes())
+	corrupt[len(corrupt)-5] ^= 0xff // CRC-32 trailer
+
+	bodies := map[string]struct {
+		encoding string
+		body     []byte
+	}{
+		"/gzip":    {"gzip", gz.Bytes()},
+		"/zlib":    {"deflate", zl.Bytes()},
+		"/deflate": {"deflate", raw.Bytes()},
+		"/corrupt": {"gzip", corrupt},
+		"/garbage": {"gzip", []byte("not gzip at all")},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := bodies[r.URL.Path]
+		w.Header().Set("Content-Encoding", b.encoding)
+		w.Write(b.body)
+	}))
+	defer server.Close()
+
+	c := &HTTPChecker{AllowPrivate: true}
+	check := func(path, settings string) *Result {
+		t.Helper()
+		mon := &storage.Monitor{Target: server.URL + path, Timeout: 5}
+		if settings != "" {
+			mon.Settings = json.RawMessage(settings)
+		}
+		result, err := c.Check(context.Background(), mon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	for _, path := range []string{"/gzip", "/zlib", "/deflate"} {
+		t.Run(path, func(t *testing.T) {
+			result := check(path, "")
+			if result.Status != "up" || result.Body != text {
+				t.Fatalf("expected decoded body, got %s %q: %s", result.Status, result.Body, result.Message)
+			}
+			if result.BodySize != int64(len(bodies[path].body)) || result.DecodedSize != int64(len(text)) {
+				t.Fatalf("sizes = %d/%d", result.BodySize, result.DecodedSize)
+			}
+		})
+	}
+
+	for _, path := range []string{"/corrupt", "/garbage"} {
+		t.Run(path, func(t *testing.T) {
+			result := check(path, "")
+			if result.Status != "down" || !strings.Contains(result.Message, "corrupt gzip response body") {
+				t.Fatalf("expected down for corrupt body, got %s: %s", result.Status, result.Message)
+			}
+		})
+	}
+
+	t.Run("no_decompress", func(t *testing.T) {
+		result := check("/gzip", `{"no_decompress":true}`)
+		if result.Status != "up" || result.Body != gz.String() {
+			t.Fatalf("expected raw body, got %s %q", result.Status, result.Body)
+		}
+		if result.DecodedSize != 0 || result.Headers["Content-Encoding"] != "gzip" {
+			t.Fatalf("expected encoded response untouched, got %d %v", result.DecodedSize, result.Headers)
+		}
+	})
+}
+
+func TestHTTPCheckerResponseTimeIncludesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(w, "late body")
+	}))
+	defer server.Close()
+
+	c := &HTTPChecker{AllowPrivate: true}
+	result, err := c.Check(context.Background(), &storage.Monitor{Target: server.URL, Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Body != "late body" || result.ResponseTime < 100 {
+		t.Fatalf("expected response time to cover the body read, got %dms for %q", result.ResponseTime, result.Body)
+	}
+}
+
+func TestHTTPCheckerJoinsRepeatedHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("X-Single", "one")
+	}))
+	defer server.Close()
+
+	c := &HTTPChecker{AllowPrivate: true}
+	result, err := c.Check(context.Background(), &storage.Monitor{Target: server.URL, Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Headers["Vary"]; got != "Accept-Encoding, Origin" {
+		t.Errorf("Vary = %q, want joined values", got)
+	}
+	if got := result.Headers["X-Single"]; got != "one" {
+		t.Errorf("X-Single = %q", got)
+	}
+}
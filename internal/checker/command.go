@@ -60,32 +60,116 @@ func (c *CommandChecker) Check(ctx context.Context, monitor *storage.Monitor) (*
 	start := time.Now()
 	cmd := exec.CommandContext(ctx, settings.Command, settings.Args...)
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	stdout := &cappedBuffer{limit: maxBodyRead}
+	stderr := &cappedBuffer{limit: maxBodyRead}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	err := cmd.Run()
 	elapsed := time.Since(start).Milliseconds()
+	out := strings.TrimSpace(stdout.String())
+	errOut := strings.TrimSpace(stderr.String())
 
 	if err != nil {
 		exitCode := -1
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			exitCode = exitErr.ExitCode()
 		}
+		body := errOut
+		if body == "" {
+			body = out
+		}
+		if settings.ParseJSON {
+			if doc, ok := jsonOutput(out); ok {
+				body = doc
+			}
+		}
+		msg := fmt.Sprintf("command failed: %v", err)
+		if line := lastLine(errOut, out); line != "" {
+			msg += ": " + line
+		}
 		return &Result{
 			Status:       "down",
 			ResponseTime: elapsed,
 			StatusCode:   exitCode,
-			Message:      fmt.Sprintf("command failed: %v", err),
-			Body:         strings.TrimSpace(stderr.String()),
+			Message:      msg,
+			Body:         body,
 		}, nil
 	}
 
+	if settings.ParseJSON {
+		doc, ok := jsonOutput(out)
+		if !ok {
+			return &Result{
+				Status:       "down",
+				ResponseTime: elapsed,
+				Message:      "command output is not valid JSON",
+				Body:         out,
+			}, nil
+		}
+		out = doc
+	}
+
 	return &Result{
 		Status:       "up",
 		ResponseTime: elapsed,
 		StatusCode:   0,
 		Message:      "command succeeded",
-		Body:         strings.TrimSpace(stdout.String()),
+		Body:         out,
 	}, nil
 }
+
+// maxCommandMessage caps the output line appended to a failed command's
+// message.
+const maxCommandMessage = 200
+
+// jsonOutput returns the JSON document in out: the whole output, or failing
+// that its last non-empty line, so scripts may log before printing the result.
+func jsonOutput(out string) (string, bool) {
+	if out != "" && json.Valid([]byte(out)) {
+		return out, true
+	}
+	if line := lastLine(out); line != "" && json.Valid([]byte(line)) {
+		return line, true
+	}
+	return "", false
+}
+
+// lastLine returns the last non-empty line of the first output that has one,
+// truncated to maxCommandMessage bytes.
+func lastLine(outputs ...string) string {
+	for _, o := range outputs {
+		lines := strings.Split(o, "\n")
+		for i := len(lines) - 1; i >= 0; i-- {
+			line := strings.TrimSpace(lines[i])
+			if line == "" {
+				continue
+			}
+			if len(line) > maxCommandMessage {
+				line = strings.ToValidUTF8(line[:maxCommandMessage], "") + "..."
+			}
+			return line
+		}
+	}
+	return ""
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the
+// rest, so a chatty command cannot exhaust memory or block on a full pipe.
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string { return b.buf.String() }
@@ -0,0 +1,82 @@
+//go:build linux
+
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func runShell(t *testing.T, script string, parseJSON bool) *Result {
+	t.Helper()
+	settings, _ := json.Marshal(storage.CommandSettings{
+		Command:   "/bin/sh",
+		Args:      []string{"-c", script},
+		ParseJSON: parseJSON,
+	})
+	c := &CommandChecker{Allowlist: []string{"/bin/sh"}}
+	result, err := c.Check(context.Background(), &storage.Monitor{Type: "command", Settings: settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestCommandCheckerAllowlist(t *testing.T) {
+	settings, _ := json.Marshal(storage.CommandSettings{Command: "/bin/echo", Args: []string{"hi"}})
+	c := &CommandChecker{Allowlist: []string{"/bin/sh"}}
+	result, _ := c.Check(context.Background(), &storage.Monitor{Type: "command", Settings: settings})
+	if result.Status != "down" || !strings.Contains(result.Message, "not in allowlist") {
+		t.Fatalf("expected allowlist rejection, got %s: %s", result.Status, result.Message)
+	}
+}
+
+func TestCommandCheckerExitCode(t *testing.T) {
+	result := runShell(t, "echo ok", false)
+	if result.Status != "up" || result.Body != "ok" {
+		t.Fatalf("expected up with body ok, got %s %q", result.Status, result.Body)
+	}
+
+	result = runShell(t, "echo starting; echo 'disk full' >&2; exit 3", false)
+	if result.Status != "down" || result.StatusCode != 3 {
+		t.Fatalf("expected down with exit code 3, got %s %d", result.Status, result.StatusCode)
+	}
+	if result.Message != "command failed: exit status 3: disk full" || result.Body != "disk full" {
+		t.Fatalf("unexpected failure output: %q, body %q", result.Message, result.Body)
+	}
+}
+
+func TestCommandCheckerParseJSON(t *testing.T) {
+	result := runShell(t, `echo '{"queue": {"depth": 12}}'`, true)
+	if result.Status != "up" || result.Body != `{"queue": {"depth": 12}}` {
+		t.Fatalf("expected JSON body, got %s %q", result.Status, result.Body)
+	}
+
+	// Log lines before the document are skipped.
+	result = runShell(t, `echo checking; echo '{"ok": true}'`, true)
+	if result.Status != "up" || result.Body != `{"ok": true}` {
+		t.Fatalf("expected last line as body, got %s %q", result.Status, result.Body)
+	}
+
+	result = runShell(t, "echo not json", true)
+	if result.Status != "down" || result.Message != "command output is not valid JSON" || result.Body != "not json" {
+		t.Fatalf("expected invalid JSON failure, got %s %q %q", result.Status, result.Message, result.Body)
+	}
+
+	// A failing command keeps its JSON report as the body.
+	result = runShell(t, `echo '{"error": "stale"}'; echo oops >&2; exit 1`, true)
+	if result.Status != "down" || result.Body != `{"error": "stale"}` {
+		t.Fatalf("expected down with JSON body, got %s %q", result.Status, result.Body)
+	}
+}
+
+func TestCommandCheckerOutputCap(t *testing.T) {
+	result := runShell(t, "head -c 3000000 /dev/zero | tr '\\0' a", false)
+	if result.Status != "up" || len(result.Body) != maxBodyRead {
+		t.Fatalf("expected body capped at %d bytes, got %s %d", maxBodyRead, result.Status, len(result.Body))
+	}
+}
@@ -3,8 +3,11 @@ package checker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/y0f/asura/internal/safenet"
@@ -25,52 +28,103 @@ func (c *DNSChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Resu
 		}
 	}
 
-	recordType := settings.RecordType
-	if recordType == "" {
-		recordType = "A"
-	}
+	recordTypes := parseRecordTypes(settings.RecordType)
 
-	baseDial := (&net.Dialer{
-		Timeout: time.Duration(monitor.Timeout) * time.Second,
+	timeout := time.Duration(monitor.Timeout) * time.Second
+	baseDial := sourceDial(&net.Dialer{
+		Timeout: timeout,
 		Control: safenet.MaybeDialControl(c.AllowPrivate),
-	}).DialContext
+	}, monitor.SourceAddr)
 
 	dialFn := baseDial
 	if socks := ProxyDialer(monitor.ProxyURL, baseDial); socks != nil {
 		dialFn = socks
 	}
 
+	// exchange carries the raw query of a DNSSEC check to the same server
+	// the lookups use. Like the lookups, the system resolver is queried
+	// directly: it is usually a loopback stub that the private address
+	// guard and a proxy would both refuse.
+	systemDial := sourceDial(&net.Dialer{Timeout: timeout}, monitor.SourceAddr)
+	exchange := func(ctx context.Context, query []byte) ([]byte, error) {
+		return connExchange(systemDial, "udp", systemNameserver())(ctx, query)
+	}
+
 	resolver := net.DefaultResolver
-	if settings.Server != "" {
+	lookup := func(ctx context.Context, recordType string) ([]string, bool, error) {
+		return lookupDNSRecords(ctx, resolver, recordType, monitor.Target)
+	}
+	if settings.DoHURL != "" {
+		transport := &http.Transport{
+			DialContext:       baseDial,
+			DisableKeepAlives: true,
+		}
+		applyHTTPProxy(transport, monitor.ProxyURL, baseDial)
+		client := &http.Client{Transport: transport, Timeout: timeout}
+		lookup = func(ctx context.Context, recordType string) ([]string, bool, error) {
+			return lookupDoH(ctx, client, settings.DoHURL, recordType, monitor.Target)
+		}
+		exchange = func(ctx context.Context, query []byte) ([]byte, error) {
+			return dohExchange(ctx, client, settings.DoHURL, query)
+		}
+	} else if settings.Server != "" {
+		server := dnsServerAddr(settings.Server)
+		network := "udp"
+		if monitor.ProxyURL != "" {
+			network = "tcp"
+		}
+		exchange = connExchange(dialFn, network, server)
 		resolver = &net.Resolver{
 			PreferGo: true,
 			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
 				if monitor.ProxyURL != "" {
 					network = "tcp"
 				}
-				return dialFn(ctx, network, settings.Server+":53")
+				return dialFn(ctx, network, server)
 			},
 		}
 	}
 
-	start := time.Now()
-	records, supported, err := lookupDNSRecords(ctx, resolver, recordType, monitor.Target)
-	elapsed := time.Since(start).Milliseconds()
-
-	if !supported {
-		return &Result{
-			Status:  "down",
-			Message: fmt.Sprintf("unsupported record type: %s", recordType),
-		}, nil
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	if err != nil {
-		return &Result{
-			Status:       "down",
-			ResponseTime: elapsed,
-			Message:      fmt.Sprintf("DNS lookup failed: %v", err),
-		}, nil
+	start := time.Now()
+	var records []string
+	for _, recordType := range recordTypes {
+		found, supported, err := lookup(ctx, recordType)
+		if !supported {
+			return &Result{
+				Status:  "down",
+				Message: fmt.Sprintf("unsupported record type: %s", recordType),
+			}, nil
+		}
+		if err != nil {
+			// With several types, a missing record of one type is not a failure.
+			var dnsErr *net.DNSError
+			if len(recordTypes) > 1 && errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+				continue
+			}
+			msg := fmt.Sprintf("DNS lookup failed: %v", err)
+			if len(recordTypes) > 1 {
+				msg = fmt.Sprintf("DNS lookup failed (%s): %v", recordType, err)
+			}
+			result := &Result{
+				Status:       "down",
+				ResponseTime: time.Since(start).Milliseconds(),
+				Message:      msg,
+			}
+			var statusErr *dohStatusError
+			if errors.As(err, &statusErr) {
+				result.StatusCode = statusErr.code
+			}
+			return result, nil
+		}
+		records = append(records, found...)
 	}
+	elapsed := time.Since(start).Milliseconds()
 
 	if len(records) == 0 {
 		return &Result{
@@ -80,12 +134,114 @@ func (c *DNSChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Resu
 		}, nil
 	}
 
-	return &Result{
+	result := &Result{
 		Status:       "up",
 		ResponseTime: elapsed,
 		DNSRecords:   records,
 		Message:      fmt.Sprintf("found %d record(s)", len(records)),
-	}, nil
+	}
+	if len(settings.ExpectedValues) > 0 {
+		result.Status, result.Message = matchDNSRecords(records, settings.ExpectedValues, settings.MatchMode)
+	}
+	if settings.ValidateDNSSEC {
+		status, msg := checkDNSSEC(ctx, exchange, recordTypes[0], monitor.Target)
+		switch {
+		case status == "down":
+			result.Status = "down"
+			result.Message = msg + "; " + result.Message
+		case status == "degraded" && result.Status == "up":
+			result.Status = "degraded"
+			result.Message += "; " + msg
+		default:
+			result.Message += "; " + msg
+		}
+	}
+	return result, nil
+}
+
+// parseRecordTypes splits a comma-separated record_type into upper-cased,
+// de-duplicated types, defaulting to A.
+func parseRecordTypes(s string) []string {
+	var types []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(s, ",") {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return []string{"A"}
+	}
+	return types
+}
+
+// dnsServerAddr accepts "8.8.8.8", "8.8.8.8:53", "2001:4860:4860::8888" and
+// "[2001:4860:4860::8888]:53", defaulting the port to 53.
+func dnsServerAddr(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(strings.Trim(server, "[]"), "53")
+}
+
+// normalizeDNSValue makes resolved and expected values comparable: IPs are
+// canonicalized, names lose their trailing dot and are lower-cased.
+func normalizeDNSValue(v string) string {
+	v = strings.TrimSpace(v)
+	if ip := net.ParseIP(v); ip != nil {
+		return ip.String()
+	}
+	return strings.ToLower(strings.TrimSuffix(v, "."))
+}
+
+// matchDNSRecords compares resolved records against the expected values.
+// "any" needs one expected value present, "all" needs every one, and "exact"
+// also forbids extra records. A check where no expected value resolved is
+// down; one that matched only partially is degraded.
+func matchDNSRecords(records, expected []string, mode string) (status, message string) {
+	got := make(map[string]bool, len(records))
+	for _, r := range records {
+		got[normalizeDNSValue(r)] = true
+	}
+	want := make(map[string]bool, len(expected))
+	var missing []string
+	for _, e := range expected {
+		n := normalizeDNSValue(e)
+		if want[n] {
+			continue
+		}
+		want[n] = true
+		if !got[n] {
+			missing = append(missing, e)
+		}
+	}
+	var extra []string
+	for _, r := range records {
+		if !want[normalizeDNSValue(r)] {
+			extra = append(extra, r)
+		}
+	}
+
+	if len(missing) == len(want) {
+		return "down", fmt.Sprintf("none of the expected values resolved (got %s)", strings.Join(records, ", "))
+	}
+	switch mode {
+	case "all":
+		if len(missing) > 0 {
+			return "degraded", fmt.Sprintf("missing expected values: %s", strings.Join(missing, ", "))
+		}
+	case "exact":
+		if len(missing) > 0 {
+			return "degraded", fmt.Sprintf("missing expected values: %s", strings.Join(missing, ", "))
+		}
+		if len(extra) > 0 {
+			return "degraded", fmt.Sprintf("unexpected records: %s", strings.Join(extra, ", "))
+		}
+	}
+	return "up", fmt.Sprintf("found %d record(s), expected values matched", len(records))
 }
 
 func lookupDNSRecords(ctx context.Context, resolver *net.Resolver, recordType, target string) (records []string, supported bool, err error) {
@@ -0,0 +1,307 @@
+package checker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// dnsServer answers A and AAAA queries from the given records over UDP. With
+// no records at all it never replies.
+func dnsServer(t *testing.T, a, aaaa []string) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if len(a) == 0 && len(aaaa) == 0 {
+				continue
+			}
+			var req dnsmessage.Message
+			if err := req.Unpack(buf[:n]); err != nil || len(req.Questions) == 0 {
+				continue
+			}
+			q := req.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: req.ID, Response: true, Authoritative: true},
+				Questions: req.Questions,
+			}
+			hdr := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 60}
+			switch q.Type {
+			case dnsmessage.TypeA:
+				for _, ip := range a {
+					var r dnsmessage.AResource
+					copy(r.A[:], net.ParseIP(ip).To4())
+					h := hdr
+					h.Type = dnsmessage.TypeA
+					resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: h, Body: &r})
+				}
+			case dnsmessage.TypeAAAA:
+				for _, ip := range aaaa {
+					var r dnsmessage.AAAAResource
+					copy(r.AAAA[:], net.ParseIP(ip).To16())
+					h := hdr
+					h.Type = dnsmessage.TypeAAAA
+					resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: h, Body: &r})
+				}
+			}
+			out, err := resp.Pack()
+			if err != nil {
+				continue
+			}
+			pc.WriteTo(out, addr)
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+func dnsMonitor(t *testing.T, timeout int, settings storage.DNSSettings) *storage.Monitor {
+	t.Helper()
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &storage.Monitor{Type: "dns", Target: "app.example.test", Timeout: timeout, Settings: raw}
+}
+
+func TestDNSCheckerExpectedValues(t *testing.T) {
+	server := dnsServer(t, []string{"10.0.0.1", "10.0.0.2"}, []string{"2001:db8::1"})
+	c := &DNSChecker{AllowPrivate: true}
+
+	tests := []struct {
+		name       string
+		settings   storage.DNSSettings
+		wantStatus string
+		wantMsg    string
+	}{
+		{"no expectations", storage.DNSSettings{RecordType: "A"}, "up", "found 2 record(s)"},
+		{"any match", storage.DNSSettings{RecordType: "A", ExpectedValues: []string{"10.0.0.2", "10.9.9.9"}}, "up", "expected values matched"},
+		{"none match", storage.DNSSettings{RecordType: "A", ExpectedValues: []string{"10.9.9.9"}}, "down", "none of the expected values"},
+		{"all missing one", storage.DNSSettings{RecordType: "A", ExpectedValues: []string{"10.0.0.1", "10.9.9.9"}, MatchMode: "all"}, "degraded", "10.9.9.9"},
+		{"exact with extra record", storage.DNSSettings{RecordType: "A", ExpectedValues: []string{"10.0.0.1"}, MatchMode: "exact"}, "degraded", "unexpected records: 10.0.0.2"},
+		{"exact", storage.DNSSettings{RecordType: "a, aaaa", ExpectedValues: []string{"10.0.0.1", "10.0.0.2", "2001:0db8::0001"}, MatchMode: "exact"}, "up", "found 3 record(s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.settings.Server = server
+			result, err := c.Check(context.Background(), dnsMonitor(t, 5, tt.settings))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus || !strings.Contains(result.Message, tt.wantMsg) {
+				t.Fatalf("got %s %q, want %s containing %q", result.Status, result.Message, tt.wantStatus, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestDNSCheckerMultipleTypes(t *testing.T) {
+	server := dnsServer(t, []string{"10.0.0.1"}, nil)
+	c := &DNSChecker{AllowPrivate: true}
+
+	result, err := c.Check(context.Background(), dnsMonitor(t, 5, storage.DNSSettings{RecordType: "A,AAAA", Server: server}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "up" || len(result.DNSRecords) != 1 || result.DNSRecords[0] != "10.0.0.1" {
+		t.Fatalf("missing AAAA should not fail the check: %s %q %v", result.Status, result.Message, result.DNSRecords)
+	}
+}
+
+func TestDNSCheckerHonorsTimeout(t *testing.T) {
+	server := dnsServer(t, nil, nil)
+	c := &DNSChecker{AllowPrivate: true}
+
+	start := time.Now()
+	result, err := c.Check(context.Background(), dnsMonitor(t, 1, storage.DNSSettings{RecordType: "A", Server: server}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "down" {
+		t.Fatalf("expected down, got %s %q", result.Status, result.Message)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("lookup took %v, expected it to stop near the 1s monitor timeout", elapsed)
+	}
+}
+
+// dohServer answers RFC 8484 GET queries for app.example.test with one A and
+// one MX record, NXDOMAIN for other names, and 503 under /down.
+func dohServer(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(r.URL.Query().Get("dns"))
+		var req dnsmessage.Message
+		if err != nil || req.Unpack(raw) != nil || len(req.Questions) != 1 || r.Header.Get("Accept") != "application/dns-message" {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		q := req.Questions[0]
+		resp := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: req.ID, Response: true},
+			Questions: req.Questions,
+		}
+		hdr := dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: dnsmessage.ClassINET, TTL: 60}
+		switch {
+		case q.Name.String() != "app.example.test.":
+			resp.RCode = dnsmessage.RCodeNameError
+		case q.Type == dnsmessage.TypeA:
+			resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}}})
+		case q.Type == dnsmessage.TypeMX:
+			resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: &dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mail.example.test.")}})
+		}
+		out, _ := resp.Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(out)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestDNSCheckerDoH(t *testing.T) {
+	endpoint := dohServer(t)
+	c := &DNSChecker{AllowPrivate: true}
+
+	tests := []struct {
+		name       string
+		target     string
+		settings   storage.DNSSettings
+		wantStatus string
+		wantMsg    string
+	}{
+		{"a record", "app.example.test", storage.DNSSettings{RecordType: "A", ExpectedValues: []string{"10.0.0.1"}}, "up", "expected values matched"},
+		{"mx record", "app.example.test", storage.DNSSettings{RecordType: "MX", ExpectedValues: []string{"10 mail.example.test"}}, "up", "expected values matched"},
+		{"missing type", "app.example.test", storage.DNSSettings{RecordType: "A,AAAA"}, "up", "found 1 record(s)"},
+		{"nxdomain", "missing.example.test", storage.DNSSettings{RecordType: "A"}, "down", "no such host"},
+		{"http error", "app.example.test", storage.DNSSettings{RecordType: "A", DoHURL: endpoint + "/down"}, "down", "HTTP 503"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.settings.DoHURL == "" {
+				tt.settings.DoHURL = endpoint + "/dns-query"
+			}
+			mon := dnsMonitor(t, 5, tt.settings)
+			mon.Target = tt.target
+			result, err := c.Check(context.Background(), mon)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus || !strings.Contains(result.Message, tt.wantMsg) {
+				t.Fatalf("got %s %q, want %s containing %q", result.Status, result.Message, tt.wantStatus, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestDNSServerAddr(t *testing.T) {
+	tests := map[string]string{
+		"8.8.8.8":                    "8.8.8.8:53",
+		"8.8.8.8:53":                 "8.8.8.8:53",
+		"1.1.1.1:5353":               "1.1.1.1:5353",
+		"dns.example.com":            "dns.example.com:53",
+		"2001:4860:4860::8888":       "[2001:4860:4860::8888]:53",
+		"[2001:4860:4860::8888]":     "[2001:4860:4860::8888]:53",
+		"[2001:4860:4860::8888]:853": "[2001:4860:4860::8888]:853",
+	}
+	for in, want := range tests {
+		if got := dnsServerAddr(in); got != want {
+			t.Errorf("dnsServerAddr(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// dnssecExchange answers a DNSSEC query in memory with an A record, the AD
+// bit set as given and, when signed, an RRSIG beside it.
+func dnssecExchange(t *testing.T, rcode dnsmessage.RCode, authenticated, signed bool) dnsExchange {
+	return func(ctx context.Context, query []byte) ([]byte, error) {
+		var req dnsmessage.Message
+		if err := req.Unpack(query); err != nil {
+			t.Fatal(err)
+		}
+		if len(req.Additionals) != 1 || !req.Additionals[0].Header.DNSSECAllowed() {
+			t.Fatalf("query does not set the DO bit: %+v", req.Additionals)
+		}
+		resp := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: req.ID, Response: true, RCode: rcode, AuthenticData: authenticated},
+			Questions: req.Questions,
+		}
+		if rcode == dnsmessage.RCodeSuccess {
+			hdr := dnsmessage.ResourceHeader{Name: req.Questions[0].Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60}
+			resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}}})
+			if signed {
+				hdr.Type = typeRRSIG
+				resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: &dnsmessage.UnknownResource{Type: typeRRSIG, Data: []byte{0, 1}}})
+			}
+		}
+		return resp.Pack()
+	}
+}
+
+func TestCheckDNSSEC(t *testing.T) {
+	tests := []struct {
+		name       string
+		exchange   dnsExchange
+		wantStatus string
+		wantMsg    string
+	}{
+		{"validated", dnssecExchange(t, dnsmessage.RCodeSuccess, true, true), "up", "DNSSEC validated"},
+		{"signed only", dnssecExchange(t, dnsmessage.RCodeSuccess, false, true), "degraded", "AD not set"},
+		{"unsigned", dnssecExchange(t, dnsmessage.RCodeSuccess, false, false), "down", "not DNSSEC signed"},
+		{"bogus", dnssecExchange(t, dnsmessage.RCodeServerFailure, false, false), "down", "validation failed (SERVFAIL)"},
+		{"refused", dnssecExchange(t, dnsmessage.RCodeRefused, false, false), "down", "Refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, msg := checkDNSSEC(context.Background(), tt.exchange, "A", "app.example.test")
+			if status != tt.wantStatus || !strings.Contains(msg, tt.wantMsg) {
+				t.Fatalf("got %s %q, want %s containing %q", status, msg, tt.wantStatus, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestDNSCheckerDNSSECUnsigned(t *testing.T) {
+	server := dnsServer(t, []string{"10.0.0.1"}, nil)
+	endpoint := dohServer(t)
+	c := &DNSChecker{AllowPrivate: true}
+
+	for name, settings := range map[string]storage.DNSSettings{
+		"server": {RecordType: "A", Server: server, ValidateDNSSEC: true},
+		"doh":    {RecordType: "A", DoHURL: endpoint + "/dns-query", ValidateDNSSEC: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := c.Check(context.Background(), dnsMonitor(t, 5, settings))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != "down" || !strings.HasPrefix(result.Message, "records are not DNSSEC signed") || len(result.DNSRecords) != 1 {
+				t.Fatalf("got %s %q %v, want down for unsigned records", result.Status, result.Message, result.DNSRecords)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package checker
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// typeRRSIG is the RFC 4034 signature record type, which dnsmessage does not
+// name.
+const typeRRSIG dnsmessage.Type = 46
+
+// dnssecUDPSize is the EDNS0 payload size advertised on validating queries,
+// small enough to avoid IP fragmentation. Larger answers come back truncated
+// and are retried over TCP.
+const dnssecUDPSize = 1232
+
+// dnsExchange sends a packed DNS query and returns the packed response.
+type dnsExchange func(ctx context.Context, query []byte) ([]byte, error)
+
+// checkDNSSEC asks the resolver for recordType of target with the DNSSEC OK
+// bit set and reports the signing status. An answer the resolver validated
+// (AD bit) is up. One that carries RRSIG records without the AD bit is
+// degraded, since nothing checked the signatures. A validation failure, which
+// validating resolvers report as SERVFAIL, and unsigned records are down.
+func checkDNSSEC(ctx context.Context, exchange dnsExchange, recordType, target string) (status, message string) {
+	qtype, ok := dohRecordTypes[recordType]
+	if !ok {
+		return "down", fmt.Sprintf("unsupported record type: %s", recordType)
+	}
+	query, id, err := dnssecQuery(qtype, target)
+	if err != nil {
+		return "down", fmt.Sprintf("DNSSEC query failed: %v", err)
+	}
+	resp, err := exchange(ctx, query)
+	if err != nil {
+		return "down", fmt.Sprintf("DNSSEC query failed: %v", err)
+	}
+	authenticated, signed, rcode, err := parseDNSSECResponse(resp, id)
+	if err != nil {
+		return "down", fmt.Sprintf("DNSSEC query failed: %v", err)
+	}
+	switch {
+	case rcode == dnsmessage.RCodeServerFailure:
+		return "down", "DNSSEC validation failed (SERVFAIL)"
+	case rcode != dnsmessage.RCodeSuccess && rcode != dnsmessage.RCodeNameError:
+		return "down", fmt.Sprintf("DNSSEC query failed: DNS server returned %s", strings.TrimPrefix(rcode.String(), "RCode"))
+	case authenticated:
+		return "up", "DNSSEC validated"
+	case signed:
+		return "degraded", "DNSSEC signed, but the resolver did not validate it (AD not set)"
+	}
+	return "down", "records are not DNSSEC signed"
+}
+
+// dnssecQuery packs a recursive query with an EDNS0 record whose DO bit asks
+// for signatures, and the AD bit set to ask for the validation result.
+func dnssecQuery(qtype dnsmessage.Type, target string) ([]byte, uint16, error) {
+	name, err := dnsmessage.NewName(dnsFQDN(target))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid name %q: %w", target, err)
+	}
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(dnssecUDPSize, dnsmessage.RCodeSuccess, true); err != nil {
+		return nil, 0, err
+	}
+	id := uint16(rand.N(1 << 16))
+	query := dnsmessage.Message{
+		Header:      dnsmessage.Header{ID: id, RecursionDesired: true, AuthenticData: true},
+		Questions:   []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+		Additionals: []dnsmessage.Resource{{Header: opt, Body: &dnsmessage.OPTResource{}}},
+	}
+	packed, err := query.Pack()
+	return packed, id, err
+}
+
+// parseDNSSECResponse reads the AD bit and looks for RRSIG records in the
+// answer and authority sections; the latter signs the denial for a name or
+// type without records.
+func parseDNSSECResponse(msg []byte, id uint16) (authenticated, signed bool, rcode dnsmessage.RCode, err error) {
+	var p dnsmessage.Parser
+	hdr, err := p.Start(msg)
+	if err != nil {
+		return false, false, 0, fmt.Errorf("invalid DNS response: %w", err)
+	}
+	if hdr.ID != id {
+		return false, false, 0, errors.New("DNS response ID does not match the query")
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return false, false, 0, fmt.Errorf("invalid DNS response: %w", err)
+	}
+	for {
+		rh, err := p.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return false, false, 0, fmt.Errorf("invalid DNS response: %w", err)
+		}
+		signed = signed || rh.Type == typeRRSIG
+		if err := p.SkipAnswer(); err != nil {
+			return false, false, 0, fmt.Errorf("invalid DNS response: %w", err)
+		}
+	}
+	for {
+		rh, err := p.AuthorityHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return false, false, 0, fmt.Errorf("invalid DNS response: %w", err)
+		}
+		signed = signed || rh.Type == typeRRSIG
+		if err := p.SkipAuthority(); err != nil {
+			return false, false, 0, fmt.Errorf("invalid DNS response: %w", err)
+		}
+	}
+	return hdr.AuthenticData, signed, hdr.RCode, nil
+}
+
+// connExchange exchanges queries with server over network, "udp" or "tcp".
+// A truncated UDP response is retried over TCP.
+func connExchange(dial func(ctx context.Context, network, addr string) (net.Conn, error), network, server string) dnsExchange {
+	return func(ctx context.Context, query []byte) ([]byte, error) {
+		resp, err := exchangeOnce(ctx, dial, network, server, query)
+		if err != nil || network == "tcp" {
+			return resp, err
+		}
+		// The TC flag is bit 1 of the third header byte.
+		if len(resp) > 2 && resp[2]&0x02 != 0 {
+			return exchangeOnce(ctx, dial, "tcp", server, query)
+		}
+		return resp, nil
+	}
+}
+
+func exchangeOnce(ctx context.Context, dial func(ctx context.Context, network, addr string) (net.Conn, error), network, server string, query []byte) ([]byte, error) {
+	conn, err := dial(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if network == "udp" {
+		if _, err := conn.Write(query); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 1<<16)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+
+	// DNS over TCP prefixes each message with its 16-bit length.
+	framed := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(framed, uint16(len(query)))
+	copy(framed[2:], query)
+	if _, err := conn.Write(framed); err != nil {
+		return nil, err
+	}
+	var size [2]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// systemNameserver returns the first nameserver in /etc/resolv.conf, the one
+// the system resolver queries first, falling back to a local resolver.
+func systemNameserver() string {
+	data, err := os.ReadFile("/etc/resolv.conf")
+	if err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && fields[0] == "nameserver" {
+				return dnsServerAddr(fields[1])
+			}
+		}
+	}
+	return "127.0.0.1:53"
+}
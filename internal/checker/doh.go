@@ -0,0 +1,187 @@
+package checker
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// maxDoHResponse caps a DNS-over-HTTPS answer, well above the 65535 bytes a
+// DNS message can hold.
+const maxDoHResponse = 1 << 17
+
+var dohRecordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"TXT":   dnsmessage.TypeTXT,
+	"NS":    dnsmessage.TypeNS,
+}
+
+// dohStatusError is a DoH endpoint answering with a non-200 status.
+type dohStatusError struct {
+	code int
+}
+
+func (e *dohStatusError) Error() string {
+	return fmt.Sprintf("DoH server returned HTTP %d", e.code)
+}
+
+// lookupDoH resolves one record type through an RFC 8484 GET request. Values
+// are formatted like the system resolver's, and a name without records of
+// the type is reported as a not-found *net.DNSError.
+func lookupDoH(ctx context.Context, client *http.Client, endpoint, recordType, target string) (records []string, supported bool, err error) {
+	qtype, ok := dohRecordTypes[recordType]
+	if !ok {
+		return nil, false, nil
+	}
+
+	name, err := dnsmessage.NewName(dnsFQDN(target))
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid name %q: %w", target, err)
+	}
+	// RFC 8484 asks for ID 0 so that GET responses are cacheable.
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, true, err
+	}
+
+	body, err := dohExchange(ctx, client, endpoint, packed)
+	if err != nil {
+		return nil, true, err
+	}
+
+	records, err = parseDoHAnswer(body, qtype, target)
+	return records, true, err
+}
+
+// dohExchange sends a packed DNS query to an RFC 8484 endpoint as a GET
+// request and returns the wire-format response.
+func dohExchange(ctx context.Context, client *http.Client, endpoint string, packed []byte) ([]byte, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DoH URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("dns", base64.RawURLEncoding.EncodeToString(packed))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/dns-message")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &dohStatusError{code: resp.StatusCode}
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "application/dns-message" {
+		return nil, fmt.Errorf("DoH server returned content type %q", resp.Header.Get("Content-Type"))
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxDoHResponse))
+}
+
+// parseDoHAnswer extracts the answers of the queried type from a wire-format
+// DNS response.
+func parseDoHAnswer(msg []byte, qtype dnsmessage.Type, target string) ([]string, error) {
+	var p dnsmessage.Parser
+	hdr, err := p.Start(msg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS response: %w", err)
+	}
+	switch hdr.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, &net.DNSError{Err: "no such host", Name: target, IsNotFound: true}
+	default:
+		return nil, fmt.Errorf("DNS server returned %s", strings.TrimPrefix(hdr.RCode.String(), "RCode"))
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, fmt.Errorf("invalid DNS response: %w", err)
+	}
+
+	var records []string
+	for {
+		rh, err := p.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid DNS response: %w", err)
+		}
+		if rh.Type != qtype || rh.Class != dnsmessage.ClassINET {
+			if err := p.SkipAnswer(); err != nil {
+				return nil, fmt.Errorf("invalid DNS response: %w", err)
+			}
+			continue
+		}
+		var value string
+		switch qtype {
+		case dnsmessage.TypeA:
+			r, err := p.AResource()
+			if err != nil {
+				return nil, err
+			}
+			value = net.IP(r.A[:]).String()
+		case dnsmessage.TypeAAAA:
+			r, err := p.AAAAResource()
+			if err != nil {
+				return nil, err
+			}
+			value = net.IP(r.AAAA[:]).String()
+		case dnsmessage.TypeCNAME:
+			r, err := p.CNAMEResource()
+			if err != nil {
+				return nil, err
+			}
+			value = r.CNAME.String()
+		case dnsmessage.TypeMX:
+			r, err := p.MXResource()
+			if err != nil {
+				return nil, err
+			}
+			value = fmt.Sprintf("%d %s", r.Pref, r.MX.String())
+		case dnsmessage.TypeTXT:
+			r, err := p.TXTResource()
+			if err != nil {
+				return nil, err
+			}
+			value = strings.Join(r.TXT, "")
+		case dnsmessage.TypeNS:
+			r, err := p.NSResource()
+			if err != nil {
+				return nil, err
+			}
+			value = r.NS.String()
+		}
+		records = append(records, value)
+	}
+	if len(records) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: target, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func dnsFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
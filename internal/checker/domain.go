@@ -44,7 +44,7 @@ func (c *DomainChecker) Check(ctx context.Context, monitor *storage.Monitor) (*R
 	}
 
 	timeout := time.Duration(monitor.Timeout) * time.Second
-	baseDial := (&net.Dialer{Timeout: timeout, Control: safenet.MaybeDialControl(c.AllowPrivate)}).DialContext
+	baseDial := sourceDial(&net.Dialer{Timeout: timeout, Control: safenet.MaybeDialControl(c.AllowPrivate)}, monitor.SourceAddr)
 
 	dialFn := baseDial
 	if socks := ProxyDialer(monitor.ProxyURL, baseDial); socks != nil {
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -41,10 +43,10 @@ func (c *GRPCChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 	}
 
 	timeout := time.Duration(monitor.Timeout) * time.Second
-	baseDial := (&net.Dialer{
+	baseDial := sourceDial(&net.Dialer{
 		Timeout: timeout,
 		Control: safenet.MaybeDialControl(c.AllowPrivate),
-	}).DialContext
+	}, monitor.SourceAddr)
 
 	dialFn := baseDial
 	if socks := ProxyDialer(monitor.ProxyURL, baseDial); socks != nil {
@@ -52,6 +54,14 @@ func (c *GRPCChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 	}
 
 	scheme, transport := grpcTransport(settings, target, dialFn)
+	client := &http.Client{Transport: transport, Timeout: timeout}
+	if settings.Mode == "stream" {
+		return checkGRPCStream(ctx, client, scheme, target, settings)
+	}
+	if settings.Method != "" {
+		return checkGRPCUnary(ctx, client, scheme, target, settings, timeout)
+	}
+
 	reqBody := encodeGRPCFrame(encodeHealthRequest(settings.ServiceName))
 
 	url := fmt.Sprintf("%s://%s/grpc.health.v1.Health/Check", scheme, target)
@@ -62,8 +72,6 @@ func (c *GRPCChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 	req.Header.Set("Content-Type", "application/grpc")
 	req.Header.Set("TE", "trailers")
 
-	client := &http.Client{Transport: transport, Timeout: timeout}
-
 	start := time.Now()
 	resp, err := client.Do(req)
 	elapsed := time.Since(start).Milliseconds()
@@ -81,6 +89,105 @@ func (c *GRPCChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 	return evaluateGRPCResponse(resp, body, elapsed)
 }
 
+const grpcHealthWatch = "/grpc.health.v1.Health/Watch"
+
+// checkGRPCStream opens a server-streaming call, waits for the first message
+// and closes the stream. The response time is the time to that message.
+func checkGRPCStream(ctx context.Context, client *http.Client, scheme, target string, settings storage.GRPCSettings) (*Result, error) {
+	method := settings.Method
+	if method == "" {
+		method = grpcHealthWatch
+	}
+	var msg []byte
+	if settings.RequestBase64 != "" {
+		var err error
+		if msg, err = base64.StdEncoding.DecodeString(settings.RequestBase64); err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid request_base64: %v", err)}, nil
+		}
+	} else if method == grpcHealthWatch {
+		msg = encodeHealthRequest(settings.ServiceName)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	url := fmt.Sprintf("%s://%s%s", scheme, target, method)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(encodeGRPCFrame(msg)))
+	if err != nil {
+		return &Result{Status: "down", Message: fmt.Sprintf("invalid request: %v", err)}, nil
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return &Result{
+			Status:       "down",
+			ResponseTime: time.Since(start).Milliseconds(),
+			Message:      fmt.Sprintf("gRPC request failed: %v", err),
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	payload, err := readGRPCMessage(resp.Body)
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		msg := fmt.Sprintf("gRPC stream: %v", err)
+		if errors.Is(err, io.EOF) {
+			// Trailers are only available once the body is drained.
+			io.Copy(io.Discard, resp.Body)
+			msg = "gRPC stream closed before the first message"
+			if st := grpcMeta(resp, "grpc-status"); st != "" && st != "0" {
+				msg = "gRPC error: " + grpcStatusText(resp)
+			}
+		}
+		return &Result{
+			Status:       "down",
+			ResponseTime: elapsed,
+			StatusCode:   resp.StatusCode,
+			Message:      msg,
+		}, nil
+	}
+
+	if method == grpcHealthWatch {
+		return grpcHealthResult(decodeHealthResponse(payload), resp.StatusCode, elapsed), nil
+	}
+	return &Result{
+		Status:       "up",
+		ResponseTime: elapsed,
+		StatusCode:   resp.StatusCode,
+		Message:      fmt.Sprintf("gRPC stream: first message received (%d bytes)", len(payload)),
+	}, nil
+}
+
+// maxGRPCStreamMessage caps the first stream message read by the checker.
+const maxGRPCStreamMessage = 1 << 20
+
+// readGRPCMessage reads one length-prefixed message from a gRPC stream. It
+// returns io.EOF if the stream ends before a message starts.
+func readGRPCMessage(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("truncated message header")
+		}
+		return nil, err
+	}
+	if header[0] != 0 {
+		return nil, fmt.Errorf("compressed messages are not supported")
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > maxGRPCStreamMessage {
+		return nil, fmt.Errorf("message too large: %d bytes", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("truncated message: %w", err)
+	}
+	return payload, nil
+}
+
 func grpcTransport(settings storage.GRPCSettings, target string, dialFn func(context.Context, string, string) (net.Conn, error)) (string, http.RoundTripper) {
 	if settings.UseTLS {
 		host, _, _ := net.SplitHostPort(target)
@@ -111,22 +218,24 @@ func grpcTransport(settings storage.GRPCSettings, target string, dialFn func(con
 	}
 }
 
-func evaluateGRPCResponse(resp *http.Response, body []byte, elapsed int64) (*Result, error) {
-	grpcStatus := resp.Trailer.Get("grpc-status")
-	if grpcStatus == "" {
-		grpcStatus = resp.Header.Get("grpc-status")
+// grpcMeta returns a gRPC status field from the trailers, falling back to
+// the headers for trailers-only responses.
+func grpcMeta(resp *http.Response, key string) string {
+	if v := resp.Trailer.Get(key); v != "" {
+		return v
 	}
+	return resp.Header.Get(key)
+}
+
+func evaluateGRPCResponse(resp *http.Response, body []byte, elapsed int64) (*Result, error) {
+	grpcStatus := grpcMeta(resp, "grpc-status")
 
 	if grpcStatus != "" && grpcStatus != "0" {
-		grpcMsg := resp.Trailer.Get("grpc-message")
-		if grpcMsg == "" {
-			grpcMsg = resp.Header.Get("grpc-message")
-		}
 		return &Result{
 			Status:       "down",
 			ResponseTime: elapsed,
 			StatusCode:   resp.StatusCode,
-			Message:      fmt.Sprintf("gRPC error: status=%s message=%s", grpcStatus, grpcMsg),
+			Message:      "gRPC error: " + grpcStatusText(resp),
 		}, nil
 	}
 
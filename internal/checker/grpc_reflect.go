@@ -0,0 +1,874 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// grpcCodeNames are the canonical gRPC status code names, indexed by code.
+var grpcCodeNames = [...]string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND",
+	"ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION",
+	"ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// grpcStatusText formats the grpc-status and grpc-message of a response,
+// e.g. "status=5 (NOT_FOUND) message=no such user".
+func grpcStatusText(resp *http.Response) string {
+	st := grpcMeta(resp, "grpc-status")
+	name := "UNKNOWN"
+	if code, err := strconv.Atoi(st); err == nil && code >= 0 && code < len(grpcCodeNames) {
+		name = grpcCodeNames[code]
+	}
+	msg := grpcMeta(resp, "grpc-message")
+	if m, err := url.PathUnescape(msg); err == nil {
+		msg = m
+	}
+	return fmt.Sprintf("status=%s (%s) message=%s", st, name, msg)
+}
+
+// checkGRPCUnary calls the unary method in settings.Method with the JSON
+// request encoded against the method's input type, which is looked up
+// through server reflection. Any response with status OK is up.
+func checkGRPCUnary(ctx context.Context, client *http.Client, scheme, target string, settings storage.GRPCSettings, timeout time.Duration) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	reg, method, err := grpcReflect(ctx, client, scheme, target, settings.Method)
+	if err != nil {
+		return &Result{Status: "down", Message: fmt.Sprintf("gRPC reflection: %v", err)}, nil
+	}
+	if method.clientStreaming || method.serverStreaming {
+		return &Result{Status: "down", Message: fmt.Sprintf("%s is a streaming method; use stream mode", settings.Method)}, nil
+	}
+	msg, err := reg.encodeJSON(method.input, settings.Request)
+	if err != nil {
+		return &Result{Status: "down", Message: fmt.Sprintf("invalid request for %s: %v", method.input, err)}, nil
+	}
+
+	start := time.Now()
+	resp, err := grpcPost(ctx, client, scheme, target, settings.Method, msg)
+	if err != nil {
+		return &Result{
+			Status:       "down",
+			ResponseTime: time.Since(start).Milliseconds(),
+			Message:      fmt.Sprintf("gRPC request failed: %v", err),
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	payload, readErr := readGRPCMessage(resp.Body)
+	// Trailers are only available once the body is drained.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxGRPCStreamMessage))
+	r := &Result{ResponseTime: time.Since(start).Milliseconds(), StatusCode: resp.StatusCode, Status: "down"}
+	switch st := grpcMeta(resp, "grpc-status"); {
+	case st != "0" && st != "":
+		r.Message = "gRPC error: " + grpcStatusText(resp)
+	case st == "":
+		r.Message = fmt.Sprintf("gRPC response without grpc-status (HTTP %d)", resp.StatusCode)
+	case readErr != nil:
+		r.Message = fmt.Sprintf("invalid gRPC response: %v", readErr)
+	default:
+		r.Status = "up"
+		r.Message = fmt.Sprintf("gRPC %s: status=0 (OK), %d-byte response", settings.Method, len(payload))
+	}
+	return r, nil
+}
+
+// grpcPost sends one framed message to a gRPC method and closes the request
+// stream. The context deadline is passed on as grpc-timeout.
+func grpcPost(ctx context.Context, client *http.Client, scheme, target, method string, msg []byte) (*http.Response, error) {
+	url := fmt.Sprintf("%s://%s%s", scheme, target, method)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(encodeGRPCFrame(msg)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set("grpc-timeout", fmt.Sprintf("%dm", max(time.Until(deadline).Milliseconds(), 1)))
+	}
+	return client.Do(req)
+}
+
+// maxReflectionRequests caps the reflection round trips spent resolving one
+// method and the types its request refers to.
+const maxReflectionRequests = 8
+
+// grpcReflectionServices are tried in order; older servers only implement
+// v1alpha.
+var grpcReflectionServices = []string{
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// grpcReflect resolves method (/package.Service/Method) through the server
+// reflection service. It fetches the file defining the service, then the
+// files defining any request types the server left out.
+func grpcReflect(ctx context.Context, client *http.Client, scheme, target, method string) (*protoRegistry, *protoMethod, error) {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	reg := newProtoRegistry()
+	symbol := service
+	for range maxReflectionRequests {
+		files, err := grpcReflectSymbol(ctx, client, scheme, target, symbol)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, f := range files {
+			if err := reg.addFile(f); err != nil {
+				return nil, nil, fmt.Errorf("invalid file descriptor: %w", err)
+			}
+		}
+		m := reg.methods[method]
+		if m == nil {
+			return nil, nil, fmt.Errorf("method %s not found", method)
+		}
+		missing := reg.missingType(m.input)
+		if missing == "" {
+			return reg, m, nil
+		}
+		if missing == symbol {
+			return nil, nil, fmt.Errorf("type %s not found", missing)
+		}
+		symbol = missing
+	}
+	return nil, nil, fmt.Errorf("could not resolve the request type of %s", method)
+}
+
+// grpcReflectSymbol asks the reflection service for the file descriptors
+// defining symbol and returns them serialized.
+func grpcReflectSymbol(ctx context.Context, client *http.Client, scheme, target, symbol string) ([][]byte, error) {
+	// ServerReflectionRequest.file_containing_symbol
+	req := appendProtoBytes(nil, 4, []byte(symbol))
+	for _, path := range grpcReflectionServices {
+		resp, err := grpcPost(ctx, client, scheme, target, path, req)
+		if err != nil {
+			return nil, err
+		}
+		payload, err := readGRPCMessage(resp.Body)
+		if err != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxGRPCStreamMessage))
+			resp.Body.Close()
+			switch st := grpcMeta(resp, "grpc-status"); st {
+			case "12":
+				continue
+			case "", "0":
+				return nil, err
+			default:
+				return nil, errors.New(grpcStatusText(resp))
+			}
+		}
+		resp.Body.Close()
+		return parseReflectionResponse(payload)
+	}
+	return nil, errors.New("server reflection is not enabled")
+}
+
+func parseReflectionResponse(data []byte) ([][]byte, error) {
+	var files [][]byte
+	var errCode uint64
+	var errMsg string
+	var hasErr bool
+	err := protoWalk(data, func(num int32, _ int, _ uint64, b []byte) error {
+		switch num {
+		case 4: // file_descriptor_response
+			return protoWalk(b, func(num int32, _ int, _ uint64, b []byte) error {
+				if num == 1 {
+					files = append(files, b)
+				}
+				return nil
+			})
+		case 7: // error_response
+			hasErr = true
+			return protoWalk(b, func(num int32, _ int, v uint64, b []byte) error {
+				switch num {
+				case 1:
+					errCode = v
+				case 2:
+					errMsg = string(b)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if hasErr {
+		name := "UNKNOWN"
+		if errCode < uint64(len(grpcCodeNames)) {
+			name = grpcCodeNames[errCode]
+		}
+		return nil, fmt.Errorf("%s (%s)", errMsg, name)
+	}
+	return files, nil
+}
+
+// FieldDescriptorProto.Type values.
+const (
+	protoTypeDouble   = 1
+	protoTypeFloat    = 2
+	protoTypeInt64    = 3
+	protoTypeUint64   = 4
+	protoTypeInt32    = 5
+	protoTypeFixed64  = 6
+	protoTypeFixed32  = 7
+	protoTypeBool     = 8
+	protoTypeString   = 9
+	protoTypeGroup    = 10
+	protoTypeMessage  = 11
+	protoTypeBytes    = 12
+	protoTypeUint32   = 13
+	protoTypeEnum     = 14
+	protoTypeSfixed32 = 15
+	protoTypeSfixed64 = 16
+	protoTypeSint32   = 17
+	protoTypeSint64   = 18
+)
+
+// protoRegistry holds the parts of file descriptors needed to encode a
+// request message from JSON. Type names are fully qualified without the
+// leading dot.
+type protoRegistry struct {
+	files    map[string]bool
+	messages map[string]*protoMsg
+	enums    map[string]map[string]int32
+	methods  map[string]*protoMethod // by /package.Service/Method
+}
+
+type protoMsg struct {
+	fields   []*protoField
+	mapEntry bool
+}
+
+type protoField struct {
+	name     string
+	jsonName string
+	number   int32
+	repeated bool
+	typ      int32
+	typeName string
+}
+
+type protoMethod struct {
+	input           string
+	clientStreaming bool
+	serverStreaming bool
+}
+
+func newProtoRegistry() *protoRegistry {
+	return &protoRegistry{
+		files:    map[string]bool{},
+		messages: map[string]*protoMsg{},
+		enums:    map[string]map[string]int32{},
+		methods:  map[string]*protoMethod{},
+	}
+}
+
+func qualifyProtoName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// addFile adds the messages, enums and services of a serialized
+// FileDescriptorProto.
+func (r *protoRegistry) addFile(data []byte) error {
+	var name, pkg string
+	var messages, enums, services [][]byte
+	err := protoWalk(data, func(num int32, _ int, _ uint64, b []byte) error {
+		switch num {
+		case 1:
+			name = string(b)
+		case 2:
+			pkg = string(b)
+		case 4:
+			messages = append(messages, b)
+		case 5:
+			enums = append(enums, b)
+		case 6:
+			services = append(services, b)
+		}
+		return nil
+	})
+	if err != nil || r.files[name] {
+		return err
+	}
+	r.files[name] = true
+	for _, b := range messages {
+		if err := r.addMessage(pkg, b); err != nil {
+			return err
+		}
+	}
+	for _, b := range enums {
+		if err := r.addEnum(pkg, b); err != nil {
+			return err
+		}
+	}
+	for _, b := range services {
+		if err := r.addService(pkg, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *protoRegistry) addMessage(prefix string, data []byte) error {
+	var name string
+	var fields, nested, enums [][]byte
+	msg := &protoMsg{}
+	err := protoWalk(data, func(num int32, _ int, _ uint64, b []byte) error {
+		switch num {
+		case 1:
+			name = string(b)
+		case 2:
+			fields = append(fields, b)
+		case 3:
+			nested = append(nested, b)
+		case 4:
+			enums = append(enums, b)
+		case 7: // MessageOptions.map_entry
+			return protoWalk(b, func(num int32, _ int, v uint64, _ []byte) error {
+				if num == 7 {
+					msg.mapEntry = v != 0
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	full := qualifyProtoName(prefix, name)
+	for _, b := range fields {
+		f, err := parseProtoField(b)
+		if err != nil {
+			return err
+		}
+		msg.fields = append(msg.fields, f)
+	}
+	r.messages[full] = msg
+	for _, b := range nested {
+		if err := r.addMessage(full, b); err != nil {
+			return err
+		}
+	}
+	for _, b := range enums {
+		if err := r.addEnum(full, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseProtoField(data []byte) (*protoField, error) {
+	f := &protoField{}
+	err := protoWalk(data, func(num int32, _ int, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			f.name = string(b)
+		case 3:
+			f.number = int32(v)
+		case 4:
+			f.repeated = v == 3 // LABEL_REPEATED
+		case 5:
+			f.typ = int32(v)
+		case 6:
+			f.typeName = strings.TrimPrefix(string(b), ".")
+		case 10:
+			f.jsonName = string(b)
+		}
+		return nil
+	})
+	if f.jsonName == "" {
+		f.jsonName = protoJSONName(f.name)
+	}
+	return f, err
+}
+
+// protoJSONName is protoc's default JSON name: snake_case to lowerCamelCase.
+func protoJSONName(name string) string {
+	var sb strings.Builder
+	upper := false
+	for _, c := range name {
+		switch {
+		case c == '_':
+			upper = true
+		case upper && c >= 'a' && c <= 'z':
+			sb.WriteRune(c - 'a' + 'A')
+			upper = false
+		default:
+			sb.WriteRune(c)
+			upper = false
+		}
+	}
+	return sb.String()
+}
+
+func (r *protoRegistry) addEnum(prefix string, data []byte) error {
+	var name string
+	values := map[string]int32{}
+	err := protoWalk(data, func(num int32, _ int, _ uint64, b []byte) error {
+		switch num {
+		case 1:
+			name = string(b)
+		case 2:
+			var vname string
+			var vnum int32
+			err := protoWalk(b, func(num int32, _ int, v uint64, b []byte) error {
+				switch num {
+				case 1:
+					vname = string(b)
+				case 2:
+					vnum = int32(v)
+				}
+				return nil
+			})
+			values[vname] = vnum
+			return err
+		}
+		return nil
+	})
+	r.enums[qualifyProtoName(prefix, name)] = values
+	return err
+}
+
+func (r *protoRegistry) addService(pkg string, data []byte) error {
+	var name string
+	var methods [][]byte
+	err := protoWalk(data, func(num int32, _ int, _ uint64, b []byte) error {
+		switch num {
+		case 1:
+			name = string(b)
+		case 2:
+			methods = append(methods, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	service := qualifyProtoName(pkg, name)
+	for _, b := range methods {
+		var mname string
+		m := &protoMethod{}
+		err := protoWalk(b, func(num int32, _ int, v uint64, b []byte) error {
+			switch num {
+			case 1:
+				mname = string(b)
+			case 2:
+				m.input = strings.TrimPrefix(string(b), ".")
+			case 5:
+				m.clientStreaming = v != 0
+			case 6:
+				m.serverStreaming = v != 0
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		r.methods["/"+service+"/"+mname] = m
+	}
+	return nil
+}
+
+// missingType returns the first message or enum type reachable from the
+// message root that is not in the registry, or "" if all are.
+func (r *protoRegistry) missingType(root string) string {
+	seen := map[string]bool{}
+	var walk func(name string) string
+	walk = func(name string) string {
+		if seen[name] {
+			return ""
+		}
+		seen[name] = true
+		msg := r.messages[name]
+		if msg == nil {
+			return name
+		}
+		for _, f := range msg.fields {
+			switch f.typ {
+			case protoTypeMessage:
+				if missing := walk(f.typeName); missing != "" {
+					return missing
+				}
+			case protoTypeEnum:
+				if _, ok := r.enums[f.typeName]; !ok {
+					return f.typeName
+				}
+			}
+		}
+		return ""
+	}
+	return walk(root)
+}
+
+// encodeJSON serializes a message of type name from its proto3 JSON form.
+// The special JSON forms of well-known types such as Timestamp are not
+// supported; those are written as plain messages.
+func (r *protoRegistry) encodeJSON(name string, raw json.RawMessage) ([]byte, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, nil
+	}
+	return r.encodeMessage(nil, name, v)
+}
+
+func (r *protoRegistry) encodeMessage(buf []byte, name string, v any) ([]byte, error) {
+	msg := r.messages[name]
+	if msg == nil {
+		return nil, fmt.Errorf("unknown message type %s", name)
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a JSON object for %s", name)
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		f := msg.field(k)
+		if f == nil {
+			return nil, fmt.Errorf("%s has no field %q", name, k)
+		}
+		val := obj[k]
+		if val == nil {
+			continue
+		}
+		var err error
+		switch entry := r.messages[f.typeName]; {
+		case f.repeated && entry != nil && entry.mapEntry:
+			buf, err = r.appendMap(buf, f, entry, val)
+		case f.repeated:
+			arr, ok := val.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: expected an array", k)
+			}
+			for _, el := range arr {
+				if buf, err = r.appendValue(buf, f, el); err != nil {
+					break
+				}
+			}
+		default:
+			buf, err = r.appendValue(buf, f, val)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+	}
+	return buf, nil
+}
+
+func (m *protoMsg) field(key string) *protoField {
+	for _, f := range m.fields {
+		if f.jsonName == key || f.name == key {
+			return f
+		}
+	}
+	return nil
+}
+
+func (m *protoMsg) fieldNumber(n int32) *protoField {
+	for _, f := range m.fields {
+		if f.number == n {
+			return f
+		}
+	}
+	return nil
+}
+
+// appendMap writes a JSON object as map entries: messages with the key in
+// field 1 and the value in field 2.
+func (r *protoRegistry) appendMap(buf []byte, f *protoField, entry *protoMsg, v any) ([]byte, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, errors.New("expected a JSON object")
+	}
+	kf, vf := entry.fieldNumber(1), entry.fieldNumber(2)
+	if kf == nil || vf == nil {
+		return nil, errors.New("invalid map entry type")
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		var key any = k
+		switch kf.typ {
+		case protoTypeString:
+		case protoTypeBool:
+			b, err := strconv.ParseBool(k)
+			if err != nil {
+				return nil, fmt.Errorf("invalid map key %q", k)
+			}
+			key = b
+		default:
+			key = json.Number(k)
+		}
+		e, err := r.appendValue(nil, kf, key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k, err)
+		}
+		if obj[k] != nil {
+			if e, err = r.appendValue(e, vf, obj[k]); err != nil {
+				return nil, fmt.Errorf("%q: %w", k, err)
+			}
+		}
+		buf = appendProtoBytes(buf, f.number, e)
+	}
+	return buf, nil
+}
+
+// appendValue writes one value of field f.
+func (r *protoRegistry) appendValue(buf []byte, f *protoField, v any) ([]byte, error) {
+	switch f.typ {
+	case protoTypeDouble, protoTypeFloat:
+		x, err := jsonProtoFloat(v)
+		if err != nil {
+			return nil, err
+		}
+		if f.typ == protoTypeFloat {
+			buf = appendProtoTag(buf, f.number, 5)
+			return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(x))), nil
+		}
+		buf = appendProtoTag(buf, f.number, 1)
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(x)), nil
+	case protoTypeInt32, protoTypeSint32, protoTypeSfixed32, protoTypeInt64, protoTypeSint64, protoTypeSfixed64:
+		bits := 64
+		if f.typ == protoTypeInt32 || f.typ == protoTypeSint32 || f.typ == protoTypeSfixed32 {
+			bits = 32
+		}
+		x, err := jsonProtoInt(v, bits)
+		if err != nil {
+			return nil, err
+		}
+		switch f.typ {
+		case protoTypeSint32, protoTypeSint64:
+			return appendProtoVarint(buf, f.number, uint64(x<<1)^uint64(x>>63)), nil
+		case protoTypeSfixed32:
+			buf = appendProtoTag(buf, f.number, 5)
+			return binary.LittleEndian.AppendUint32(buf, uint32(int32(x))), nil
+		case protoTypeSfixed64:
+			buf = appendProtoTag(buf, f.number, 1)
+			return binary.LittleEndian.AppendUint64(buf, uint64(x)), nil
+		}
+		return appendProtoVarint(buf, f.number, uint64(x)), nil
+	case protoTypeUint32, protoTypeFixed32, protoTypeUint64, protoTypeFixed64:
+		bits := 64
+		if f.typ == protoTypeUint32 || f.typ == protoTypeFixed32 {
+			bits = 32
+		}
+		x, err := jsonProtoUint(v, bits)
+		if err != nil {
+			return nil, err
+		}
+		switch f.typ {
+		case protoTypeFixed32:
+			buf = appendProtoTag(buf, f.number, 5)
+			return binary.LittleEndian.AppendUint32(buf, uint32(x)), nil
+		case protoTypeFixed64:
+			buf = appendProtoTag(buf, f.number, 1)
+			return binary.LittleEndian.AppendUint64(buf, x), nil
+		}
+		return appendProtoVarint(buf, f.number, x), nil
+	case protoTypeBool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, errors.New("expected a boolean")
+		}
+		var x uint64
+		if b {
+			x = 1
+		}
+		return appendProtoVarint(buf, f.number, x), nil
+	case protoTypeString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("expected a string")
+		}
+		return appendProtoBytes(buf, f.number, []byte(s)), nil
+	case protoTypeBytes:
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("expected a base64 string")
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			if b, err = base64.URLEncoding.DecodeString(s); err != nil {
+				return nil, errors.New("expected a base64 string")
+			}
+		}
+		return appendProtoBytes(buf, f.number, b), nil
+	case protoTypeEnum:
+		switch x := v.(type) {
+		case string:
+			n, ok := r.enums[f.typeName][x]
+			if !ok {
+				return nil, fmt.Errorf("%s has no value %q", f.typeName, x)
+			}
+			return appendProtoVarint(buf, f.number, uint64(n)), nil
+		case json.Number:
+			n, err := strconv.ParseInt(x.String(), 10, 32)
+			if err != nil {
+				return nil, errors.New("expected an enum name or number")
+			}
+			return appendProtoVarint(buf, f.number, uint64(n)), nil
+		}
+		return nil, errors.New("expected an enum name or number")
+	case protoTypeMessage:
+		b, err := r.encodeMessage(nil, f.typeName, v)
+		if err != nil {
+			return nil, err
+		}
+		return appendProtoBytes(buf, f.number, b), nil
+	}
+	return nil, fmt.Errorf("unsupported field type %d", f.typ)
+}
+
+// jsonProtoInt accepts an integer as a JSON number or string.
+func jsonProtoInt(v any, bits int) (int64, error) {
+	s, ok := jsonNumberText(v)
+	if !ok {
+		return 0, errors.New("expected an integer")
+	}
+	n, err := strconv.ParseInt(s, 10, bits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %d-bit integer %q", bits, s)
+	}
+	return n, nil
+}
+
+func jsonProtoUint(v any, bits int) (uint64, error) {
+	s, ok := jsonNumberText(v)
+	if !ok {
+		return 0, errors.New("expected an unsigned integer")
+	}
+	n, err := strconv.ParseUint(s, 10, bits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unsigned %d-bit integer %q", bits, s)
+	}
+	return n, nil
+}
+
+// jsonProtoFloat accepts a JSON number, a numeric string, or "NaN",
+// "Infinity" and "-Infinity".
+func jsonProtoFloat(v any) (float64, error) {
+	s, ok := jsonNumberText(v)
+	if !ok {
+		return 0, errors.New("expected a number")
+	}
+	switch s {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return x, nil
+}
+
+func jsonNumberText(v any) (string, bool) {
+	switch x := v.(type) {
+	case json.Number:
+		return x.String(), true
+	case string:
+		return x, true
+	}
+	return "", false
+}
+
+func appendProtoTag(buf []byte, num int32, wireType int) []byte {
+	return binary.AppendUvarint(buf, uint64(num)<<3|uint64(wireType))
+}
+
+func appendProtoVarint(buf []byte, num int32, v uint64) []byte {
+	return binary.AppendUvarint(appendProtoTag(buf, num, 0), v)
+}
+
+func appendProtoBytes(buf []byte, num int32, b []byte) []byte {
+	buf = binary.AppendUvarint(appendProtoTag(buf, num, 2), uint64(len(b)))
+	return append(buf, b...)
+}
+
+var errProtoTruncated = errors.New("truncated protobuf message")
+
+// protoWalk calls fn for each field of a serialized protobuf message. v holds
+// the value of varint and fixed-width fields and b the contents of
+// length-delimited ones.
+func protoWalk(data []byte, fn func(num int32, wireType int, v uint64, b []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errProtoTruncated
+		}
+		data = data[n:]
+		num, wireType := int32(key>>3), int(key&7)
+		var v uint64
+		var b []byte
+		switch wireType {
+		case 0:
+			if v, n = binary.Uvarint(data); n <= 0 {
+				return errProtoTruncated
+			}
+			data = data[n:]
+		case 1:
+			if len(data) < 8 {
+				return errProtoTruncated
+			}
+			v, data = binary.LittleEndian.Uint64(data), data[8:]
+		case 2:
+			l, n := binary.Uvarint(data)
+			if n <= 0 || l > uint64(len(data)-n) {
+				return errProtoTruncated
+			}
+			b, data = data[n:n+int(l)], data[n+int(l):]
+		case 5:
+			if len(data) < 4 {
+				return errProtoTruncated
+			}
+			v, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d", wireType)
+		}
+		if err := fn(num, wireType, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,7 +1,19 @@
 package checker
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 func TestEncodeGRPCFrame(t *testing.T) {
@@ -99,3 +111,264 @@ func TestGRPCFrameRoundTrip(t *testing.T) {
 		t.Errorf("decoded empty frame length = %d, want 0", len(decoded))
 	}
 }
+
+func TestReadGRPCMessage(t *testing.T) {
+	t.Run("message", func(t *testing.T) {
+		got, err := readGRPCMessage(bytes.NewReader(append(encodeGRPCFrame([]byte("hi")), encodeGRPCFrame([]byte("more"))...)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "hi" {
+			t.Errorf("got %q, want %q", got, "hi")
+		}
+	})
+	t.Run("empty stream", func(t *testing.T) {
+		if _, err := readGRPCMessage(bytes.NewReader(nil)); !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF, got %v", err)
+		}
+	})
+	t.Run("truncated", func(t *testing.T) {
+		if _, err := readGRPCMessage(bytes.NewReader([]byte{0, 0, 0, 0, 9, 'x'})); err == nil || errors.Is(err, io.EOF) {
+			t.Errorf("expected truncation error, got %v", err)
+		}
+	})
+	t.Run("compressed", func(t *testing.T) {
+		if _, err := readGRPCMessage(bytes.NewReader([]byte{1, 0, 0, 0, 0})); err == nil {
+			t.Error("expected error for compressed message")
+		}
+	})
+}
+
+func TestGRPCStreamCheck(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "grpc-status")
+		switch r.URL.Path {
+		case "/test.Feed/Subscribe":
+			w.Write(encodeGRPCFrame([]byte("event")))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done() // keep the stream open like a real feed
+		case grpcHealthWatch:
+			w.Write(encodeGRPCFrame([]byte{0x08, 0x02}))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.Header().Set("grpc-status", "12")
+		}
+	})
+	srv := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer srv.Close()
+	target := strings.TrimPrefix(srv.URL, "http://")
+
+	check := func(settings string) *Result {
+		t.Helper()
+		c := &GRPCChecker{AllowPrivate: true}
+		res, err := c.Check(context.Background(), &storage.Monitor{
+			Type: "grpc", Target: target, Timeout: 5, Settings: json.RawMessage(settings),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	if res := check(`{"mode":"stream","method":"/test.Feed/Subscribe"}`); res.Status != "up" {
+		t.Errorf("custom stream: status = %s, message = %s", res.Status, res.Message)
+	}
+	if res := check(`{"mode":"stream"}`); res.Status != "down" || !strings.Contains(res.Message, "NOT_SERVING") {
+		t.Errorf("health watch: status = %s, message = %s", res.Status, res.Message)
+	}
+	if res := check(`{"mode":"stream","method":"/test.Feed/Missing"}`); res.Status != "down" || !strings.Contains(res.Message, "status=12") {
+		t.Errorf("unimplemented: status = %s, message = %s", res.Status, res.Message)
+	}
+}
+
+// testEchoDescriptor is a FileDescriptorProto for:
+//
+//	package test;
+//	enum Kind { KIND_A = 0; KIND_B = 1; }
+//	message PingRequest {
+//	  string user_id = 1;
+//	  int32 count = 2;
+//	  repeated string tags = 3;
+//	  Kind kind = 4;
+//	  map<string, sint64> labels = 5;
+//	}
+//	service Echo {
+//	  rpc Ping(PingRequest) returns (PingRequest);
+//	  rpc Watch(PingRequest) returns (stream PingRequest);
+//	}
+func testEchoDescriptor() []byte {
+	field := func(name string, num, label, typ int, typeName string) []byte {
+		f := appendProtoBytes(nil, 1, []byte(name))
+		f = appendProtoVarint(f, 3, uint64(num))
+		f = appendProtoVarint(f, 4, uint64(label))
+		f = appendProtoVarint(f, 5, uint64(typ))
+		if typeName != "" {
+			f = appendProtoBytes(f, 6, []byte(typeName))
+		}
+		return f
+	}
+	entry := appendProtoBytes(nil, 1, []byte("LabelsEntry"))
+	entry = appendProtoBytes(entry, 2, field("key", 1, 1, protoTypeString, ""))
+	entry = appendProtoBytes(entry, 2, field("value", 2, 1, protoTypeSint64, ""))
+	entry = appendProtoBytes(entry, 7, appendProtoVarint(nil, 7, 1))
+
+	msg := appendProtoBytes(nil, 1, []byte("PingRequest"))
+	msg = appendProtoBytes(msg, 2, field("user_id", 1, 1, protoTypeString, ""))
+	msg = appendProtoBytes(msg, 2, field("count", 2, 1, protoTypeInt32, ""))
+	msg = appendProtoBytes(msg, 2, field("tags", 3, 3, protoTypeString, ""))
+	msg = appendProtoBytes(msg, 2, field("kind", 4, 1, protoTypeEnum, ".test.Kind"))
+	msg = appendProtoBytes(msg, 2, field("labels", 5, 3, protoTypeMessage, ".test.PingRequest.LabelsEntry"))
+	msg = appendProtoBytes(msg, 3, entry)
+
+	enumValue := func(name string, num int) []byte {
+		return appendProtoVarint(appendProtoBytes(nil, 1, []byte(name)), 2, uint64(num))
+	}
+	enum := appendProtoBytes(nil, 1, []byte("Kind"))
+	enum = appendProtoBytes(enum, 2, enumValue("KIND_A", 0))
+	enum = appendProtoBytes(enum, 2, enumValue("KIND_B", 1))
+
+	method := func(name string, serverStreaming bool) []byte {
+		m := appendProtoBytes(nil, 1, []byte(name))
+		m = appendProtoBytes(m, 2, []byte(".test.PingRequest"))
+		m = appendProtoBytes(m, 3, []byte(".test.PingRequest"))
+		if serverStreaming {
+			m = appendProtoVarint(m, 6, 1)
+		}
+		return m
+	}
+	svc := appendProtoBytes(nil, 1, []byte("Echo"))
+	svc = appendProtoBytes(svc, 2, method("Ping", false))
+	svc = appendProtoBytes(svc, 2, method("Watch", true))
+
+	file := appendProtoBytes(nil, 1, []byte("test.proto"))
+	file = appendProtoBytes(file, 2, []byte("test"))
+	file = appendProtoBytes(file, 4, msg)
+	file = appendProtoBytes(file, 5, enum)
+	file = appendProtoBytes(file, 6, svc)
+	return file
+}
+
+func TestProtoEncodeJSON(t *testing.T) {
+	reg := newProtoRegistry()
+	if err := reg.addFile(testEchoDescriptor()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := reg.encodeJSON("test.PingRequest", json.RawMessage(`{"userId":"u1","count":-1,"tags":["a","b"],"kind":"KIND_B","labels":{"x":-2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fields are written in JSON key order: count, kind, labels, tags, userId.
+	want := appendProtoVarint(nil, 2, 0xffffffffffffffff)
+	want = appendProtoVarint(want, 4, 1)
+	want = appendProtoBytes(want, 5, appendProtoVarint(appendProtoBytes(nil, 1, []byte("x")), 2, 3))
+	want = appendProtoBytes(want, 3, []byte("a"))
+	want = appendProtoBytes(want, 3, []byte("b"))
+	want = appendProtoBytes(want, 1, []byte("u1"))
+	if !bytes.Equal(got, want) {
+		t.Errorf("encoded = %x, want %x", got, want)
+	}
+
+	if got, err := reg.encodeJSON("test.PingRequest", json.RawMessage(`{"user_id":"u1"}`)); err != nil || !bytes.Equal(got, appendProtoBytes(nil, 1, []byte("u1"))) {
+		t.Errorf("proto field name: got %x, %v", got, err)
+	}
+
+	for _, bad := range []string{
+		`{"nope":1}`,
+		`{"count":"lots"}`,
+		`{"count":4294967296}`,
+		`{"kind":"KIND_C"}`,
+		`{"tags":"a"}`,
+		`[1]`,
+	} {
+		if _, err := reg.encodeJSON("test.PingRequest", json.RawMessage(bad)); err == nil {
+			t.Errorf("%s: expected error", bad)
+		}
+	}
+}
+
+func TestGRPCUnaryCheck(t *testing.T) {
+	descriptor := testEchoDescriptor()
+	var reflection bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "grpc-status, grpc-message")
+		req, _ := readGRPCMessage(r.Body)
+		switch r.URL.Path {
+		case grpcReflectionServices[0]:
+			if !reflection {
+				w.Header().Set("grpc-status", "12")
+				return
+			}
+			var symbol string
+			protoWalk(req, func(num int32, _ int, _ uint64, b []byte) error {
+				if num == 4 {
+					symbol = string(b)
+				}
+				return nil
+			})
+			if symbol != "test.Echo" {
+				w.Write(encodeGRPCFrame(appendProtoBytes(nil, 7, appendProtoVarint(nil, 1, 5))))
+			} else {
+				w.Write(encodeGRPCFrame(appendProtoBytes(nil, 4, appendProtoBytes(nil, 1, descriptor))))
+			}
+			w.Header().Set("grpc-status", "0")
+		case "/test.Echo/Ping":
+			if r.Header.Get("grpc-timeout") == "" {
+				w.Header().Set("grpc-status", "3")
+				w.Header().Set("grpc-message", "missing deadline")
+				return
+			}
+			if !bytes.Equal(req, appendProtoBytes(nil, 1, []byte("u1"))) {
+				w.Header().Set("grpc-status", "5")
+				w.Header().Set("grpc-message", "user not found")
+				return
+			}
+			w.Write(encodeGRPCFrame(req))
+			w.Header().Set("grpc-status", "0")
+		default:
+			w.Header().Set("grpc-status", "12")
+		}
+	})
+	srv := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer srv.Close()
+	target := strings.TrimPrefix(srv.URL, "http://")
+
+	check := func(settings string) *Result {
+		t.Helper()
+		c := &GRPCChecker{AllowPrivate: true}
+		res, err := c.Check(context.Background(), &storage.Monitor{
+			Type: "grpc", Target: target, Timeout: 5, Settings: json.RawMessage(settings),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	if res := check(`{"method":"/test.Echo/Ping","request":{"userId":"u1"}}`); res.Status != "down" || !strings.Contains(res.Message, "reflection is not enabled") {
+		t.Errorf("no reflection: status = %s, message = %s", res.Status, res.Message)
+	}
+
+	reflection = true
+	if res := check(`{"method":"/test.Echo/Ping","request":{"userId":"u1"}}`); res.Status != "up" || !strings.Contains(res.Message, "status=0 (OK)") {
+		t.Errorf("ok: status = %s, message = %s", res.Status, res.Message)
+	}
+	if res := check(`{"method":"/test.Echo/Ping","request":{"userId":"u2"}}`); res.Status != "down" || !strings.Contains(res.Message, "status=5 (NOT_FOUND) message=user not found") {
+		t.Errorf("not found: status = %s, message = %s", res.Status, res.Message)
+	}
+	if res := check(`{"method":"/test.Echo/Missing"}`); res.Status != "down" || !strings.Contains(res.Message, "not found") {
+		t.Errorf("unknown method: status = %s, message = %s", res.Status, res.Message)
+	}
+	if res := check(`{"method":"/test.Echo/Watch"}`); res.Status != "down" || !strings.Contains(res.Message, "streaming") {
+		t.Errorf("streaming method: status = %s, message = %s", res.Status, res.Message)
+	}
+	if res := check(`{"method":"/other.Svc/Get"}`); res.Status != "down" || !strings.Contains(res.Message, "NOT_FOUND") {
+		t.Errorf("unknown service: status = %s, message = %s", res.Status, res.Message)
+	}
+	if res := check(`{"method":"/test.Echo/Ping","request":{"bogus":1}}`); res.Status != "down" || !strings.Contains(res.Message, "invalid request") {
+		t.Errorf("bad request: status = %s, message = %s", res.Status, res.Message)
+	}
+}
@@ -1,15 +1,25 @@
 package checker
 
 import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"crypto/sha256"
 	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
 	"net/url"
 	"strconv"
 	"strings"
@@ -21,6 +31,10 @@ import (
 
 const maxBodyRead = 1 << 20 // 1MB
 
+// maxBodyDrain caps how much of an oversized body without Content-Length is
+// read to measure its size.
+const maxBodyDrain = 16 << 20
+
 type HTTPChecker struct {
 	AllowPrivate bool
 }
@@ -34,6 +48,9 @@ func (c *HTTPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: %v", err)}, nil
 		}
 	}
+	if _, err := settings.ExpectedStatus.Ranges(); err != nil {
+		return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: expected_status: %v", err)}, nil
+	}
 
 	method := settings.Method
 	if method == "" {
@@ -46,7 +63,14 @@ func (c *HTTPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 	}
 
 	var bodyReader io.Reader
-	if settings.Body != "" {
+	var multipartType string
+	if settings.BodyEncoding == "multipart" {
+		body, ct, err := buildMultipartBody(settings)
+		if err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid multipart body: %v", err)}, nil
+		}
+		bodyReader, multipartType = body, ct
+	} else if settings.Body != "" {
 		bodyReader = strings.NewReader(settings.Body)
 	}
 
@@ -56,18 +80,35 @@ func (c *HTTPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 	}
 
 	applyBodyAndHeaders(req, settings)
+	if multipartType != "" {
+		// The boundary is fixed by the body, so a custom header can't replace it.
+		req.Header.Set("Content-Type", multipartType)
+	}
 	applyAuthentication(req, settings)
+	if settings.RangeBytes > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", settings.RangeBytes-1))
+	}
+
+	// Negotiate compression here rather than in the transport, which would
+	// hide the encoded size, so BodySize reflects the bytes the server sent.
+	// The first bytes of an encoded body can't be decoded on their own, so
+	// range requests are left alone.
+	decompress := !settings.NoDecompress && req.Method != http.MethodHead && req.Header.Get("Range") == ""
+	if decompress && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
 
 	timeout := time.Duration(monitor.Timeout) * time.Second
-	baseDial := (&net.Dialer{
-		Timeout: timeout,
+	baseDial := sourceDial(&net.Dialer{
+		Timeout: connectTimeout(monitor),
 		Control: safenet.MaybeDialControl(c.AllowPrivate),
-	}).DialContext
+	}, monitor.SourceAddr)
 
 	transport := &http.Transport{
-		DialContext:       baseDial,
-		TLSClientConfig:   &tls.Config{InsecureSkipVerify: settings.SkipTLSVerify},
-		DisableKeepAlives: true,
+		DialContext:        baseDial,
+		TLSClientConfig:    &tls.Config{InsecureSkipVerify: settings.SkipTLSVerify},
+		DisableKeepAlives:  true,
+		DisableCompression: true,
 	}
 	applyHTTPProxy(transport, monitor.ProxyURL, baseDial)
 
@@ -78,6 +119,10 @@ func (c *HTTPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 	}
 
 	start := time.Now()
+	var ttfb time.Duration
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { ttfb = time.Since(start) },
+	}))
 	resp, err := client.Do(req)
 	elapsed := time.Since(start).Milliseconds()
 
@@ -94,7 +139,15 @@ func (c *HTTPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 	}
 	defer resp.Body.Close()
 
-	return buildHTTPResult(resp, elapsed, settings)
+	result, err := buildHTTPResult(resp, start, settings, decompress)
+	if err == nil && settings.RangeBytes > 0 && ttfb > 0 {
+		result.ResponseTime = ttfb.Milliseconds()
+	}
+	if err == nil && result.Status == "up" && settings.MaxTTFBMs > 0 && ttfb > time.Duration(settings.MaxTTFBMs)*time.Millisecond {
+		result.Status = "degraded"
+		result.Message = fmt.Sprintf("time to first byte %dms exceeds %dms", ttfb.Milliseconds(), settings.MaxTTFBMs)
+	}
+	return result, err
 }
 
 func applyHTTPProxy(transport *http.Transport, proxyURL string, baseDial func(context.Context, string, string) (net.Conn, error)) {
@@ -122,20 +175,48 @@ func redirectPolicy(maxRedirects int) func(*http.Request, []*http.Request) error
 	}
 }
 
-func buildHTTPResult(resp *http.Response, elapsed int64, settings storage.HTTPSettings) (*Result, error) {
-	bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodyRead))
+// buildHTTPResult reads the body and evaluates the response. The response
+// time runs from start until the body has been read.
+func buildHTTPResult(resp *http.Response, start time.Time, settings storage.HTTPSettings, decompress bool) (*Result, error) {
+	limit := int64(maxBodyRead)
+	if settings.RangeBytes > 0 && int64(settings.RangeBytes) < limit {
+		limit = int64(settings.RangeBytes)
+	}
+	body, bodyErr := readHTTPBody(resp, limit, decompress)
+	elapsed := time.Since(start).Milliseconds()
 
-	h := sha256.Sum256(bodyBytes)
-	headers := make(map[string]string)
-	for k := range resp.Header {
-		headers[k] = resp.Header.Get(k)
+	h := sha256.Sum256(body.data)
+	// Repeated fields are joined per RFC 9110 section 5.3.
+	headers := make(map[string]string, len(resp.Header))
+	for k, vals := range resp.Header {
+		headers[k] = strings.Join(vals, ", ")
 	}
 
+	// Check validates the spec before sending the request.
+	accepted, _ := settings.ExpectedStatus.Ranges()
 	status := "up"
 	var msg string
-	if settings.ExpectedStatus > 0 && resp.StatusCode != settings.ExpectedStatus {
+	switch {
+	case settings.ExpectedStatus == "" && settings.RangeBytes > 0:
+		if resp.StatusCode != http.StatusPartialContent {
+			status = "down"
+			msg = fmt.Sprintf("expected 206 Partial Content for range request, got %d", resp.StatusCode)
+		}
+	case !storage.MatchStatus(accepted, resp.StatusCode):
+		status = "down"
+		if settings.ExpectedStatus == "" {
+			msg = fmt.Sprintf("expected a 2xx or 3xx status, got %d", resp.StatusCode)
+		} else {
+			msg = fmt.Sprintf("expected status %s, got %d", settings.ExpectedStatus, resp.StatusCode)
+		}
+	}
+	if bodyErr != nil {
 		status = "down"
-		msg = fmt.Sprintf("expected status %d, got %d", settings.ExpectedStatus, resp.StatusCode)
+		if msg == "" {
+			msg = bodyErr.Error()
+		} else {
+			msg += "; " + bodyErr.Error()
+		}
 	}
 
 	result := &Result{
@@ -143,7 +224,9 @@ func buildHTTPResult(resp *http.Response, elapsed int64, settings storage.HTTPSe
 		ResponseTime: elapsed,
 		StatusCode:   resp.StatusCode,
 		Message:      msg,
-		Body:         string(bodyBytes),
+		Body:         string(body.data),
+		BodySize:     body.size,
+		DecodedSize:  body.decoded,
 		BodyHash:     hex.EncodeToString(h[:]),
 		Headers:      headers,
 	}
@@ -154,10 +237,95 @@ func buildHTTPResult(resp *http.Response, elapsed int64, settings storage.HTTPSe
 		result.CertExpiry = &expiry
 		sum := sha256.Sum256(cert.Raw)
 		result.CertFingerprint = hex.EncodeToString(sum[:])
+		result.TLS = tlsDetails(*resp.TLS)
 	}
 	return result, nil
 }
 
+// httpBody is what readHTTPBody read of a response body.
+type httpBody struct {
+	data    []byte // up to the read limit, decompressed
+	size    int64  // full size as sent
+	decoded int64  // full size after decompression; 0 if not compressed
+}
+
+// readHTTPBody returns up to limit bytes of the body, decoded when decompress
+// is set and the body is gzip or deflate encoded, with the full size of the
+// body as sent. When the body is cut off and the server sent no
+// Content-Length, the rest is drained, up to maxBodyDrain, to measure it,
+// except for range requests. Encoded bodies are always drained, to measure
+// the decoded size and verify the stream. The error reports a corrupt
+// encoded body.
+func readHTTPBody(resp *http.Response, limit int64, decompress bool) (httpBody, error) {
+	wire := &countingReader{r: resp.Body}
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if !decompress || (encoding != "gzip" && encoding != "deflate") {
+		b, _ := io.ReadAll(io.LimitReader(wire, limit))
+		if int64(len(b)) == limit && resp.ContentLength >= 0 {
+			return httpBody{data: b, size: resp.ContentLength}, nil
+		}
+		// A range request is meant to avoid downloading the whole body.
+		if resp.Request != nil && resp.Request.Header.Get("Range") != "" {
+			return httpBody{data: b, size: wire.n}, nil
+		}
+		io.Copy(io.Discard, io.LimitReader(wire, maxBodyDrain))
+		return httpBody{data: b, size: wire.n}, nil
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	zr, err := newBodyDecoder(encoding, wire)
+	if errors.Is(err, io.EOF) {
+		// An empty body has nothing to decode.
+		return httpBody{}, nil
+	}
+	if err != nil {
+		return httpBody{size: wire.n}, fmt.Errorf("corrupt %s response body: %v", encoding, err)
+	}
+	decoded := &countingReader{r: zr}
+	b, err := io.ReadAll(io.LimitReader(decoded, limit))
+	if err == nil {
+		_, err = io.Copy(io.Discard, io.LimitReader(decoded, maxBodyDrain))
+	}
+	body := httpBody{data: b, size: wire.n, decoded: decoded.n}
+	if err != nil {
+		return body, fmt.Errorf("corrupt %s response body: %v", encoding, err)
+	}
+	return body, nil
+}
+
+// newBodyDecoder returns a reader decoding a gzip or deflate body. HTTP
+// deflate is a zlib stream, but some servers send raw deflate; the zlib
+// header tells them apart.
+func newBodyDecoder(encoding string, r io.Reader) (io.Reader, error) {
+	if encoding == "gzip" {
+		return gzip.NewReader(r)
+	}
+	br := bufio.NewReader(r)
+	hdr, err := br.Peek(2)
+	if err != nil {
+		if len(hdr) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if hdr[0]&0x0f == 8 && (uint16(hdr[0])<<8|uint16(hdr[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 func cacheBustURL(target string) string {
 	sep := "?"
 	if strings.Contains(target, "?") {
@@ -182,6 +350,45 @@ func applyBodyAndHeaders(req *http.Request, settings storage.HTTPSettings) {
 	}
 }
 
+// buildMultipartBody encodes the key=value lines of the body, and the file
+// part if any, as multipart/form-data under a fresh random boundary. It
+// returns the body and its Content-Type.
+func buildMultipartBody(settings storage.HTTPSettings) (*bytes.Reader, string, error) {
+	fields, err := settings.MultipartFields()
+	if err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, f := range fields {
+		if err := mw.WriteField(f[0], f[1]); err != nil {
+			return nil, "", err
+		}
+	}
+	if file := settings.MultipartFile; file != nil {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+			"name": file.Field, "filename": file.Filename,
+		}))
+		ct := file.ContentType
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		h.Set("Content-Type", ct)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := io.WriteString(part, file.Content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(buf.Bytes()), mw.FormDataContentType(), nil
+}
+
 func applyAuthentication(req *http.Request, settings storage.HTTPSettings) {
 	switch settings.AuthMethod {
 	case "basic":
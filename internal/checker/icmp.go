@@ -2,9 +2,12 @@ package checker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/y0f/asura/internal/safenet"
@@ -20,8 +23,28 @@ type ICMPChecker struct {
 
 func (c *ICMPChecker) Type() string { return "icmp" }
 
+// Default ICMP echo settings.
+const (
+	defaultICMPCount          = 3
+	defaultICMPIntervalMs     = 500
+	defaultICMPMaxLossPercent = 50
+)
+
 func (c *ICMPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Result, error) {
+	var settings storage.ICMPSettings
+	if len(monitor.Settings) > 0 {
+		if err := json.Unmarshal(monitor.Settings, &settings); err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: %v", err)}, nil
+		}
+	}
+	if settings.Count <= 0 {
+		settings.Count = defaultICMPCount
+	}
 	timeout := time.Duration(monitor.Timeout) * time.Second
+	if settings.IntervalMs <= 0 {
+		// Leave at least half the timeout for the last reply.
+		settings.IntervalMs = min(defaultICMPIntervalMs, int(timeout.Milliseconds())/(2*settings.Count))
+	}
 	start := time.Now()
 
 	// Try IPv4 first, then IPv6.
@@ -41,21 +64,62 @@ func (c *ICMPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 		}, nil
 	}
 
-	conn, err := listenICMP(isIPv6)
+	conn, err := listenICMP(isIPv6, monitor.SourceAddr)
 	if err != nil {
 		return &Result{Status: "down", Message: fmt.Sprintf("ICMP listen failed: %v", err)}, nil
 	}
 	defer conn.Close()
 
-	if err := sendEchoRequest(conn, dst, isIPv6); err != nil {
+	deadline := start.Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	rtts, lastErr, err := pingEcho(conn, dst, isIPv6, settings, deadline)
+	if err != nil {
 		return &Result{
 			Status:       "down",
 			ResponseTime: time.Since(start).Milliseconds(),
 			Message:      fmt.Sprintf("send failed: %v", err),
 		}, nil
 	}
+	res := icmpResult(dst, settings, rtts, lastErr)
+	if len(rtts) == 0 {
+		res.ResponseTime = time.Since(start).Milliseconds()
+	}
+	return res, nil
+}
+
+// icmpResult maps the round-trip times of the replies that came back to a
+// result. Losing every packet is down; losing more than MaxLossPercent is
+// degraded, with 0 meaning the default of 50%.
+func icmpResult(dst net.IP, settings storage.ICMPSettings, rtts []time.Duration, lastErr string) *Result {
+	lost := settings.Count - len(rtts)
+	loss := lost * 100 / settings.Count
+	if len(rtts) == 0 {
+		msg := fmt.Sprintf("ping %s: 100%% packet loss (%d/%d lost)", dst, lost, settings.Count)
+		if lastErr != "" {
+			msg += ": " + lastErr
+		}
+		return &Result{Status: "down", Message: msg}
+	}
+
+	var total time.Duration
+	for _, rtt := range rtts {
+		total += rtt
+	}
+	avg := (total / time.Duration(len(rtts))).Milliseconds()
+	msg := fmt.Sprintf("ping %s: avg %dms, %d%% packet loss (%d/%d lost)", dst, avg, loss, lost, settings.Count)
 
-	return readEchoReply(conn, dst, start, timeout, isIPv6)
+	maxLoss := settings.MaxLossPercent
+	if maxLoss <= 0 {
+		maxLoss = defaultICMPMaxLossPercent
+	}
+	status := "up"
+	if loss > maxLoss {
+		status = "degraded"
+		msg += fmt.Sprintf(", exceeds threshold %d%%", maxLoss)
+	}
+	return &Result{Status: status, ResponseTime: avg, Message: msg}
 }
 
 func resolveICMPTarget(ctx context.Context, target string) (net.IP, bool) {
@@ -68,22 +132,118 @@ func resolveICMPTarget(ctx context.Context, target string) (net.IP, bool) {
 	return nil, false
 }
 
-func listenICMP(isIPv6 bool) (*icmp.PacketConn, error) {
+// listenICMP opens an ICMP socket on source, or on all addresses when source
+// is empty.
+func listenICMP(isIPv6 bool, source string) (*icmp.PacketConn, error) {
 	if isIPv6 {
-		conn, err := icmp.ListenPacket("ip6:ipv6-icmp", "::")
+		if source == "" {
+			source = "::"
+		}
+		conn, err := icmp.ListenPacket("ip6:ipv6-icmp", source)
 		if err != nil {
-			conn, err = icmp.ListenPacket("udp6", "::")
+			conn, err = icmp.ListenPacket("udp6", source)
 		}
 		return conn, err
 	}
-	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if source == "" {
+		source = "0.0.0.0"
+	}
+	conn, err := icmp.ListenPacket("ip4:icmp", source)
 	if err != nil {
-		conn, err = icmp.ListenPacket("udp4", "0.0.0.0")
+		conn, err = icmp.ListenPacket("udp4", source)
 	}
 	return conn, err
 }
 
-func sendEchoRequest(conn *icmp.PacketConn, dst net.IP, isIPv6 bool) error {
+// echoConn is the part of *icmp.PacketConn that pingEcho uses.
+type echoConn interface {
+	ReadFrom(b []byte) (int, net.Addr, error)
+	WriteTo(b []byte, dst net.Addr) (int, error)
+	SetReadDeadline(t time.Time) error
+	LocalAddr() net.Addr
+}
+
+// _echoIDs numbers the checks so that concurrent pings on raw sockets, which
+// all see each other's replies, send distinct echo IDs.
+var _echoIDs atomic.Uint32
+
+// pingEcho sends settings.Count echo requests IntervalMs apart and collects
+// replies until all have arrived or deadline passes. It returns the round-trip
+// time of every reply and a description of the last unexpected message.
+func pingEcho(conn echoConn, dst net.IP, isIPv6 bool, settings storage.ICMPSettings, deadline time.Time) ([]time.Duration, string, error) {
+	id := int(uint32(os.Getpid())+_echoIDs.Add(1)) & 0xffff
+	interval := time.Duration(settings.IntervalMs) * time.Millisecond
+	sentAt := make([]time.Time, settings.Count)
+	rtts := make([]time.Duration, 0, settings.Count)
+	proto := icmpProto(conn, isIPv6)
+	// Raw sockets see every echo reply on the host; unprivileged datagram
+	// sockets only see their own, with the ID rewritten by the kernel.
+	raw := !strings.HasPrefix(conn.LocalAddr().Network(), "udp")
+	rb := make([]byte, 1500)
+	var lastErr string
+	sent := 0
+	nextSend := time.Now()
+
+	for len(rtts) < settings.Count {
+		now := time.Now()
+		if sent < settings.Count && !now.Before(nextSend) {
+			sentAt[sent] = now
+			if err := sendEchoRequest(conn, dst, isIPv6, id, sent+1); err != nil {
+				return nil, "", err
+			}
+			sent++
+			nextSend = nextSend.Add(interval)
+		}
+
+		wait := deadline
+		if sent < settings.Count && nextSend.Before(wait) {
+			wait = nextSend
+		}
+		if !now.Before(deadline) {
+			break
+		}
+		conn.SetReadDeadline(wait)
+		n, peer, err := conn.ReadFrom(rb)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			lastErr = fmt.Sprintf("receive failed: %v", err)
+			break
+		}
+
+		rm, err := icmp.ParseMessage(proto, rb[:n])
+		if err != nil {
+			lastErr = fmt.Sprintf("parse reply failed: %v", err)
+			continue
+		}
+		if rm.Type != ipv4.ICMPTypeEchoReply && rm.Type != ipv6.ICMPTypeEchoReply {
+			lastErr = fmt.Sprintf("unexpected ICMP type: %v", rm.Type)
+			continue
+		}
+		// Only replies from dst to this check's own requests count.
+		echo, ok := rm.Body.(*icmp.Echo)
+		if !ok || !addrIP(peer).Equal(dst) || (raw && echo.ID != id) || echo.Seq < 1 || echo.Seq > sent || sentAt[echo.Seq-1].IsZero() {
+			continue
+		}
+		rtts = append(rtts, time.Since(sentAt[echo.Seq-1]))
+		sentAt[echo.Seq-1] = time.Time{}
+	}
+	return rtts, lastErr, nil
+}
+
+// addrIP returns the IP of an address read from an ICMP socket.
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.IPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	}
+	return nil
+}
+
+func sendEchoRequest(conn echoConn, dst net.IP, isIPv6 bool, id, seq int) error {
 	var msgType icmp.Type
 	if isIPv6 {
 		msgType = ipv6.ICMPTypeEchoRequest
@@ -95,8 +255,8 @@ func sendEchoRequest(conn *icmp.PacketConn, dst net.IP, isIPv6 bool) error {
 		Type: msgType,
 		Code: 0,
 		Body: &icmp.Echo{
-			ID:   os.Getpid() & 0xffff,
-			Seq:  1,
+			ID:   id,
+			Seq:  seq,
 			Data: []byte("asura-ping"),
 		},
 	}
@@ -119,55 +279,16 @@ func sendEchoRequest(conn *icmp.PacketConn, dst net.IP, isIPv6 bool) error {
 	return err
 }
 
-func readEchoReply(conn *icmp.PacketConn, dst net.IP, start time.Time, timeout time.Duration, isIPv6 bool) (*Result, error) {
-	conn.SetReadDeadline(time.Now().Add(timeout))
-	rb := make([]byte, 1500)
-	n, _, err := conn.ReadFrom(rb)
-	elapsed := time.Since(start).Milliseconds()
-
-	if err != nil {
-		return &Result{
-			Status:       "down",
-			ResponseTime: elapsed,
-			Message:      fmt.Sprintf("receive failed: %v", err),
-		}, nil
-	}
-
-	var proto int
-	network := conn.LocalAddr().Network()
-	switch network {
+func icmpProto(conn echoConn, isIPv6 bool) int {
+	switch conn.LocalAddr().Network() {
 	case "udp4":
-		proto = 1
+		return 1
 	case "udp6":
-		proto = 58
+		return 58
 	default:
 		if isIPv6 {
-			proto = 58
-		} else {
-			proto = 1
+			return 58
 		}
+		return 1
 	}
-
-	rm, err := icmp.ParseMessage(proto, rb[:n])
-	if err != nil {
-		return &Result{
-			Status:       "down",
-			ResponseTime: elapsed,
-			Message:      fmt.Sprintf("parse reply failed: %v", err),
-		}, nil
-	}
-
-	if rm.Type == ipv4.ICMPTypeEchoReply || rm.Type == ipv6.ICMPTypeEchoReply {
-		return &Result{
-			Status:       "up",
-			ResponseTime: elapsed,
-			Message:      fmt.Sprintf("ping %s: %dms", dst, elapsed),
-		}, nil
-	}
-
-	return &Result{
-		Status:       "down",
-		ResponseTime: elapsed,
-		Message:      fmt.Sprintf("unexpected ICMP type: %v", rm.Type),
-	}, nil
 }
@@ -0,0 +1,147 @@
+package checker
+
+import (
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+func TestICMPResult(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name       string
+		settings   storage.ICMPSettings
+		rtts       []time.Duration
+		lastErr    string
+		wantStatus string
+		wantRT     int64
+		wantMsg    string
+	}{
+		{"all replies", storage.ICMPSettings{Count: 3}, []time.Duration{10 * ms, 20 * ms, 30 * ms}, "", "up", 20, "avg 20ms, 0% packet loss (0/3 lost)"},
+		{"loss within threshold", storage.ICMPSettings{Count: 4, MaxLossPercent: 25}, []time.Duration{10 * ms, 10 * ms, 10 * ms}, "", "up", 10, "25% packet loss (1/4 lost)"},
+		{"single loss within default", storage.ICMPSettings{Count: 3}, []time.Duration{10 * ms, 10 * ms}, "", "up", 10, "33% packet loss (1/3 lost)"},
+		{"loss above default", storage.ICMPSettings{Count: 3}, []time.Duration{10 * ms}, "", "degraded", 10, "exceeds threshold 50%"},
+		{"loss above threshold", storage.ICMPSettings{Count: 4, MaxLossPercent: 25}, []time.Duration{10 * ms, 10 * ms}, "", "degraded", 10, "exceeds threshold 25%"},
+		{"total loss", storage.ICMPSettings{Count: 3, MaxLossPercent: 50}, nil, "unexpected ICMP type: destination unreachable", "down", 0, "100% packet loss (3/3 lost): unexpected ICMP type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := icmpResult(net.ParseIP("192.0.2.1"), tt.settings, tt.rtts, tt.lastErr)
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q (%s)", result.Status, tt.wantStatus, result.Message)
+			}
+			if result.ResponseTime != tt.wantRT {
+				t.Errorf("response time = %d, want %d", result.ResponseTime, tt.wantRT)
+			}
+			if !strings.Contains(result.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want substring %q", result.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+// rawICMPHub stands in for a host's raw ICMP sockets: every reply from
+// responder is delivered to every open socket.
+type rawICMPHub struct {
+	responder net.IP
+	mu        sync.Mutex
+	conns     []*rawICMPConn
+}
+
+type rawICMPPacket struct {
+	b    []byte
+	from net.Addr
+}
+
+type rawICMPConn struct {
+	hub      *rawICMPHub
+	in       chan rawICMPPacket
+	deadline time.Time
+}
+
+func (h *rawICMPHub) listen() *rawICMPConn {
+	c := &rawICMPConn{hub: h, in: make(chan rawICMPPacket, 16)}
+	h.mu.Lock()
+	h.conns = append(h.conns, c)
+	h.mu.Unlock()
+	return c
+}
+
+func (c *rawICMPConn) WriteTo(b []byte, dst net.Addr) (int, error) {
+	if !addrIP(dst).Equal(c.hub.responder) {
+		return len(b), nil
+	}
+	req, err := icmp.ParseMessage(1, b)
+	if err != nil {
+		return 0, err
+	}
+	reply, err := (&icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: req.Body}).Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	for _, other := range c.hub.conns {
+		other.in <- rawICMPPacket{b: reply, from: &net.IPAddr{IP: c.hub.responder}}
+	}
+	return len(b), nil
+}
+
+func (c *rawICMPConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	timer := time.NewTimer(time.Until(c.deadline))
+	defer timer.Stop()
+	select {
+	case p := <-c.in:
+		return copy(b, p.b), p.from, nil
+	case <-timer.C:
+		return 0, nil, os.ErrDeadlineExceeded
+	}
+}
+
+func (c *rawICMPConn) SetReadDeadline(t time.Time) error {
+	c.deadline = t
+	return nil
+}
+
+func (c *rawICMPConn) LocalAddr() net.Addr { return &net.IPAddr{IP: net.IPv4zero} }
+
+func TestPingEchoIgnoresOtherChecksReplies(t *testing.T) {
+	up, silent := net.ParseIP("192.0.2.1").To4(), net.ParseIP("192.0.2.2").To4()
+	hub := &rawICMPHub{responder: up}
+	settings := storage.ICMPSettings{Count: 3, IntervalMs: 20}
+	deadline := time.Now().Add(300 * time.Millisecond)
+
+	targets := []net.IP{up, silent}
+	rtts := make([][]time.Duration, len(targets))
+	errs := make([]error, len(targets))
+	conns := []*rawICMPConn{hub.listen(), hub.listen()}
+	var wg sync.WaitGroup
+	for i, dst := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rtts[i], _, errs[i] = pingEcho(conns[i], dst, false, settings, deadline)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("ping %s: %v", targets[i], err)
+		}
+	}
+	if len(rtts[0]) != settings.Count {
+		t.Errorf("answering target got %d replies, want %d", len(rtts[0]), settings.Count)
+	}
+	if len(rtts[1]) != 0 {
+		t.Errorf("silent target got %d replies, want 0", len(rtts[1]))
+	}
+}
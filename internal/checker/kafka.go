@@ -0,0 +1,346 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/safenet"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// maxKafkaResponse caps the size of a single response frame. Metadata is
+// requested without topics, so replies stay small.
+const maxKafkaResponse = 1 << 20
+
+const kafkaClientID = "asura"
+
+// Kafka API keys and error codes used by the check.
+const (
+	kafkaAPIMetadata         int16 = 3
+	kafkaAPISaslHandshake    int16 = 17
+	kafkaAPIVersions         int16 = 18
+	kafkaAPISaslAuthenticate int16 = 36
+
+	kafkaErrUnsupportedSASLMechanism int16 = 33
+	kafkaErrIllegalSASLState         int16 = 34
+	kafkaErrSASLAuthenticationFailed int16 = 58
+)
+
+type KafkaChecker struct {
+	AllowPrivate bool
+}
+
+func (c *KafkaChecker) Type() string { return "kafka" }
+
+// kafkaBroker is one entry of a Metadata response.
+type kafkaBroker struct {
+	NodeID int32  `json:"node_id"`
+	Host   string `json:"host"`
+	Port   int32  `json:"port"`
+	Rack   string `json:"rack,omitempty"`
+}
+
+type kafkaMetadata struct {
+	ControllerID int32         `json:"controller_id"`
+	Brokers      []kafkaBroker `json:"brokers"`
+}
+
+func (c *KafkaChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Result, error) {
+	var settings storage.KafkaSettings
+	if len(monitor.Settings) > 0 {
+		if err := json.Unmarshal(monitor.Settings, &settings); err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: %v", err)}, nil
+		}
+	}
+
+	target := monitor.Target
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target += ":9092"
+	}
+
+	timeout := time.Duration(monitor.Timeout) * time.Second
+	baseDial := sourceDial(&net.Dialer{Timeout: timeout, Control: safenet.MaybeDialControl(c.AllowPrivate)}, monitor.SourceAddr)
+
+	dialFn := baseDial
+	if socks := ProxyDialer(monitor.ProxyURL, baseDial); socks != nil {
+		dialFn = socks
+	}
+
+	start := time.Now()
+	conn, err := dialFn(ctx, "tcp", target)
+	if err != nil {
+		return &Result{
+			Status:       "down",
+			ResponseTime: time.Since(start).Milliseconds(),
+			Message:      fmt.Sprintf("Kafka connection failed: %v", err),
+		}, nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if settings.UseTLS {
+		host, _, _ := net.SplitHostPort(target)
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return &Result{
+				Status:       "down",
+				ResponseTime: time.Since(start).Milliseconds(),
+				Message:      fmt.Sprintf("Kafka TLS handshake failed: %v", err),
+			}, nil
+		}
+		conn = tlsConn
+	}
+
+	kc := &kafkaConn{conn: conn}
+	if err := kc.apiVersions(); err != nil {
+		return &Result{Status: "down", ResponseTime: time.Since(start).Milliseconds(), Message: kafkaFailure("ApiVersions", err)}, nil
+	}
+	if settings.SASLUser != "" {
+		if err := kc.saslPlain(settings.SASLUser, settings.SASLPass); err != nil {
+			return &Result{Status: "down", ResponseTime: time.Since(start).Milliseconds(), Message: kafkaFailure("SASL authentication", err)}, nil
+		}
+	}
+
+	mdStart := time.Now()
+	md, err := kc.metadata()
+	elapsed := time.Since(mdStart).Milliseconds()
+	if err != nil {
+		return &Result{Status: "down", ResponseTime: elapsed, Message: kafkaFailure("Metadata", err)}, nil
+	}
+
+	body, _ := json.Marshal(md)
+	status := "up"
+	msg := fmt.Sprintf("%d brokers, controller %d, metadata in %dms", len(md.Brokers), md.ControllerID, elapsed)
+	if len(md.Brokers) == 0 {
+		status = "down"
+		msg = "broker returned no brokers in metadata"
+	} else if settings.MinBrokers > 0 && len(md.Brokers) < settings.MinBrokers {
+		status = "degraded"
+		msg = fmt.Sprintf("%d brokers, below minimum %d", len(md.Brokers), settings.MinBrokers)
+	}
+	return &Result{
+		Status:       status,
+		ResponseTime: elapsed,
+		Body:         string(body),
+		Message:      msg,
+	}, nil
+}
+
+// kafkaFailure describes a failed request. A broker that hangs up instead of
+// replying usually wants TLS or SASL on that listener.
+func kafkaFailure(step string, err error) string {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Sprintf("Kafka %s failed: connection closed by broker (TLS or SASL may be required)", step)
+	}
+	return fmt.Sprintf("Kafka %s failed: %v", step, err)
+}
+
+// kafkaConn speaks the request/response framing of the Kafka protocol: a
+// 4-byte size, a request header and the body.
+type kafkaConn struct {
+	conn          net.Conn
+	correlationID int32
+}
+
+func (k *kafkaConn) roundTrip(apiKey, apiVersion int16, body []byte) (*kafkaReader, error) {
+	k.correlationID++
+	var req kafkaWriter
+	req.int16(apiKey)
+	req.int16(apiVersion)
+	req.int32(k.correlationID)
+	req.string(kafkaClientID)
+	req.buf.Write(body)
+
+	frame := make([]byte, 4, 4+req.buf.Len())
+	binary.BigEndian.PutUint32(frame, uint32(req.buf.Len()))
+	frame = append(frame, req.buf.Bytes()...)
+	if _, err := k.conn.Write(frame); err != nil {
+		return nil, err
+	}
+
+	var hdr [4]byte
+	if _, err := io.ReadFull(k.conn, hdr[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(hdr[:])
+	if size < 4 || size > maxKafkaResponse {
+		return nil, fmt.Errorf("invalid response size %d", size)
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(k.conn, resp); err != nil {
+		return nil, err
+	}
+	r := &kafkaReader{b: resp}
+	if id := r.int32(); id != k.correlationID {
+		return nil, fmt.Errorf("correlation id mismatch: got %d, want %d", id, k.correlationID)
+	}
+	return r, nil
+}
+
+// apiVersions sends ApiVersions v0, which every broker answers before
+// authentication, to confirm the peer speaks Kafka.
+func (k *kafkaConn) apiVersions() error {
+	r, err := k.roundTrip(kafkaAPIVersions, 0, nil)
+	if err != nil {
+		return err
+	}
+	code := r.int16()
+	if r.err != nil {
+		return r.err
+	}
+	if code != 0 {
+		return fmt.Errorf("error code %d", code)
+	}
+	return nil
+}
+
+// saslPlain runs SaslHandshake v1 and SaslAuthenticate v0 with the PLAIN
+// mechanism.
+func (k *kafkaConn) saslPlain(user, pass string) error {
+	var hs kafkaWriter
+	hs.string("PLAIN")
+	r, err := k.roundTrip(kafkaAPISaslHandshake, 1, hs.buf.Bytes())
+	if err != nil {
+		return err
+	}
+	code := r.int16()
+	var mechanisms []string
+	for n := r.int32(); n > 0 && r.err == nil; n-- {
+		mechanisms = append(mechanisms, r.string())
+	}
+	if r.err != nil {
+		return r.err
+	}
+	switch code {
+	case 0:
+	case kafkaErrUnsupportedSASLMechanism:
+		return fmt.Errorf("PLAIN mechanism not enabled (broker offers %s)", strings.Join(mechanisms, ", "))
+	case kafkaErrIllegalSASLState:
+		return errors.New("illegal SASL state")
+	default:
+		return fmt.Errorf("handshake error code %d", code)
+	}
+
+	var auth kafkaWriter
+	auth.bytes([]byte("\x00" + user + "\x00" + pass))
+	r, err = k.roundTrip(kafkaAPISaslAuthenticate, 0, auth.buf.Bytes())
+	if err != nil {
+		return err
+	}
+	code = r.int16()
+	errMsg := r.nullableString()
+	if r.err != nil {
+		return r.err
+	}
+	switch code {
+	case 0:
+		return nil
+	case kafkaErrSASLAuthenticationFailed:
+		if errMsg == "" {
+			errMsg = "invalid credentials"
+		}
+		return fmt.Errorf("authentication failed: %s", errMsg)
+	default:
+		if errMsg != "" {
+			return fmt.Errorf("error code %d: %s", code, errMsg)
+		}
+		return fmt.Errorf("error code %d", code)
+	}
+}
+
+// metadata sends Metadata v1 with an empty topic list, so the reply only
+// carries the brokers and the controller.
+func (k *kafkaConn) metadata() (*kafkaMetadata, error) {
+	var req kafkaWriter
+	req.int32(0)
+	r, err := k.roundTrip(kafkaAPIMetadata, 1, req.buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	md := &kafkaMetadata{Brokers: []kafkaBroker{}}
+	for n := r.int32(); n > 0 && r.err == nil; n-- {
+		b := kafkaBroker{NodeID: r.int32(), Host: r.string(), Port: r.int32(), Rack: r.nullableString()}
+		md.Brokers = append(md.Brokers, b)
+	}
+	md.ControllerID = r.int32()
+	if r.err != nil {
+		return nil, r.err
+	}
+	sort.Slice(md.Brokers, func(i, j int) bool { return md.Brokers[i].NodeID < md.Brokers[j].NodeID })
+	return md, nil
+}
+
+type kafkaWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *kafkaWriter) int16(v int16) { binary.Write(&w.buf, binary.BigEndian, v) }
+func (w *kafkaWriter) int32(v int32) { binary.Write(&w.buf, binary.BigEndian, v) }
+
+func (w *kafkaWriter) string(s string) {
+	w.int16(int16(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *kafkaWriter) bytes(b []byte) {
+	w.int32(int32(len(b)))
+	w.buf.Write(b)
+}
+
+// kafkaReader decodes big-endian fields from a response. The first short
+// read sets err and later reads return zero values.
+type kafkaReader struct {
+	b   []byte
+	err error
+}
+
+func (r *kafkaReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.b) < n {
+		r.err = errors.New("truncated response")
+		return nil
+	}
+	p := r.b[:n]
+	r.b = r.b[n:]
+	return p
+}
+
+func (r *kafkaReader) int16() int16 {
+	p := r.next(2)
+	if p == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(p))
+}
+
+func (r *kafkaReader) int32() int32 {
+	p := r.next(4)
+	if p == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(p))
+}
+
+func (r *kafkaReader) string() string {
+	return string(r.next(int(r.int16())))
+}
+
+func (r *kafkaReader) nullableString() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
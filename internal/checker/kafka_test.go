@@ -0,0 +1,150 @@
+package checker
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// fakeKafkaBroker answers ApiVersions, SASL/PLAIN for user "monitor" with
+// password "secret", and Metadata listing the given broker hosts. Wrong
+// credentials are refused after authFailDelay.
+func fakeKafkaBroker(t *testing.T, brokers []string, requireSASL bool) string {
+	return tcpServer(t, func(c net.Conn) {
+		authed := false
+		for {
+			var hdr [4]byte
+			if _, err := io.ReadFull(c, hdr[:]); err != nil {
+				return
+			}
+			req := make([]byte, binary.BigEndian.Uint32(hdr[:]))
+			if _, err := io.ReadFull(c, req); err != nil {
+				return
+			}
+			r := &kafkaReader{b: req}
+			apiKey, _, corrID := r.int16(), r.int16(), r.int32()
+			r.string()
+
+			var resp kafkaWriter
+			resp.int32(corrID)
+			switch apiKey {
+			case kafkaAPIVersions:
+				resp.int16(0)
+				resp.int32(0)
+			case kafkaAPISaslHandshake:
+				if r.string() != "PLAIN" {
+					resp.int16(kafkaErrUnsupportedSASLMechanism)
+				} else {
+					resp.int16(0)
+				}
+				resp.int32(1)
+				resp.string("PLAIN")
+			case kafkaAPISaslAuthenticate:
+				n := r.int32()
+				auth := string(r.next(int(n)))
+				if auth == "\x00monitor\x00secret" {
+					authed = true
+					resp.int16(0)
+					resp.int16(-1)
+				} else {
+					time.Sleep(authFailDelay)
+					resp.int16(kafkaErrSASLAuthenticationFailed)
+					resp.string("Invalid username or password")
+				}
+				resp.bytes(nil)
+			case kafkaAPIMetadata:
+				if requireSASL && !authed {
+					return
+				}
+				resp.int32(int32(len(brokers)))
+				for i, host := range brokers {
+					resp.int32(int32(len(brokers) - i))
+					resp.string(host)
+					resp.int32(9092)
+					resp.int16(-1)
+				}
+				resp.int32(1)
+				resp.int32(0)
+			default:
+				return
+			}
+
+			frame := binary.BigEndian.AppendUint32(nil, uint32(resp.buf.Len()))
+			c.Write(append(frame, resp.buf.Bytes()...))
+		}
+	})
+}
+
+func TestKafkaChecker(t *testing.T) {
+	open := fakeKafkaBroker(t, []string{"kafka-a", "kafka-b"}, false)
+	secured := fakeKafkaBroker(t, []string{"kafka-a"}, true)
+
+	tests := []struct {
+		name       string
+		target     string
+		settings   storage.KafkaSettings
+		wantStatus string
+		wantMsg    string
+	}{
+		{"metadata", open, storage.KafkaSettings{}, "up", "2 brokers, controller 1"},
+		{"below min brokers", open, storage.KafkaSettings{MinBrokers: 3}, "degraded", "below minimum 3"},
+		{"sasl", secured, storage.KafkaSettings{SASLUser: "monitor", SASLPass: "secret"}, "up", "1 brokers"},
+		{"sasl wrong password", secured, storage.KafkaSettings{SASLUser: "monitor", SASLPass: "wrong"}, "down", "authentication failed: Invalid username or password"},
+		{"sasl required", secured, storage.KafkaSettings{}, "down", "connection closed by broker"},
+	}
+
+	c := &KafkaChecker{AllowPrivate: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, _ := json.Marshal(tt.settings)
+			mon := &storage.Monitor{Type: "kafka", Target: tt.target, Timeout: 2, Settings: settings}
+			result, err := c.Check(context.Background(), mon)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q (%s)", result.Status, tt.wantStatus, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want substring %q", result.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestKafkaCheckerAuthFailureResponseTime(t *testing.T) {
+	settings, _ := json.Marshal(storage.KafkaSettings{SASLUser: "monitor", SASLPass: "wrong"})
+	c := &KafkaChecker{AllowPrivate: true}
+	mon := &storage.Monitor{Type: "kafka", Target: fakeKafkaBroker(t, []string{"kafka-a"}, true), Timeout: 2, Settings: settings}
+	result, err := c.Check(context.Background(), mon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "down" || result.ResponseTime < authFailDelay.Milliseconds() {
+		t.Fatalf("got %s in %dms, want down after at least %v", result.Status, result.ResponseTime, authFailDelay)
+	}
+}
+
+func TestKafkaCheckerBody(t *testing.T) {
+	addr := fakeKafkaBroker(t, []string{"kafka-a", "kafka-b"}, false)
+	c := &KafkaChecker{AllowPrivate: true}
+	result, err := c.Check(context.Background(), &storage.Monitor{Type: "kafka", Target: addr, Timeout: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var md kafkaMetadata
+	if err := json.Unmarshal([]byte(result.Body), &md); err != nil {
+		t.Fatalf("body = %q: %v", result.Body, err)
+	}
+	if md.ControllerID != 1 || len(md.Brokers) != 2 || md.Brokers[0].NodeID != 1 || md.Brokers[0].Host != "kafka-b" {
+		t.Errorf("metadata = %+v", md)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/y0f/asura/internal/safenet"
@@ -42,8 +44,17 @@ func (c *MQTTChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 		clientID = fmt.Sprintf("asura-%d", rand.Int63())
 	}
 
+	var expectRe *regexp.Regexp
+	if settings.ExpectRegex != "" {
+		re, err := regexp.Compile(settings.ExpectRegex)
+		if err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: expect_regex: %v", err)}, nil
+		}
+		expectRe = re
+	}
+
 	timeout := time.Duration(monitor.Timeout) * time.Second
-	baseDial := (&net.Dialer{Timeout: timeout, Control: safenet.MaybeDialControl(c.AllowPrivate)}).DialContext
+	baseDial := sourceDial(&net.Dialer{Timeout: connectTimeout(monitor), Control: safenet.MaybeDialControl(c.AllowPrivate)}, monitor.SourceAddr)
 
 	dialFn := baseDial
 	if socks := ProxyDialer(monitor.ProxyURL, baseDial); socks != nil {
@@ -81,9 +92,10 @@ func (c *MQTTChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 			Message:      err.Error(),
 		}, nil
 	}
+	defer mqttDisconnect(conn)
 
 	if settings.Topic != "" {
-		if err := mqttSubscribe(conn, settings.Topic); err != nil {
+		if err := mqttSubscribe(conn, settings.Topic, settings.QoS); err != nil {
 			return &Result{
 				Status:       "down",
 				ResponseTime: time.Since(start).Milliseconds(),
@@ -92,12 +104,54 @@ func (c *MQTTChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Res
 		}
 	}
 
-	conn.Write([]byte{0xe0, 0x00})
+	if settings.Topic == "" || (settings.ExpectMessage == "" && expectRe == nil && !settings.WaitRetained) {
+		return &Result{
+			Status:       "up",
+			ResponseTime: time.Since(start).Milliseconds(),
+			Message:      "MQTT connection successful",
+		}, nil
+	}
+
+	payload, err := mqttAwaitMessage(conn, settings.WaitRetained)
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			kind := "message"
+			if settings.WaitRetained {
+				kind = "retained message"
+			}
+			return &Result{
+				Status:       "down",
+				ResponseTime: elapsed,
+				Message:      fmt.Sprintf("no %s on %s within %s", kind, settings.Topic, timeout),
+			}, nil
+		}
+		return &Result{Status: "down", ResponseTime: elapsed, Message: err.Error()}, nil
+	}
+
+	body := string(payload)
+	if settings.ExpectMessage != "" && !strings.Contains(body, settings.ExpectMessage) {
+		return &Result{
+			Status:       "degraded",
+			ResponseTime: elapsed,
+			Message:      fmt.Sprintf("message on %s does not contain %q", settings.Topic, settings.ExpectMessage),
+			Body:         body,
+		}, nil
+	}
+	if expectRe != nil && !expectRe.MatchString(body) {
+		return &Result{
+			Status:       "degraded",
+			ResponseTime: elapsed,
+			Message:      fmt.Sprintf("message on %s does not match %q", settings.Topic, settings.ExpectRegex),
+			Body:         body,
+		}, nil
+	}
 
 	return &Result{
 		Status:       "up",
-		ResponseTime: time.Since(start).Milliseconds(),
-		Message:      "MQTT connection successful",
+		ResponseTime: elapsed,
+		Message:      fmt.Sprintf("MQTT message received on %s", settings.Topic),
+		Body:         body,
 	}, nil
 }
 
@@ -130,8 +184,8 @@ func mqttHandshake(conn net.Conn, clientID string, settings storage.MQTTSettings
 	return nil
 }
 
-func mqttSubscribe(conn net.Conn, topic string) error {
-	if _, err := conn.Write(buildSubscribePacket(1, topic)); err != nil {
+func mqttSubscribe(conn net.Conn, topic string, qos int) error {
+	if _, err := conn.Write(buildSubscribePacket(1, topic, qos)); err != nil {
 		return fmt.Errorf("MQTT SUBSCRIBE send failed: %w", err)
 	}
 
@@ -150,6 +204,114 @@ func mqttSubscribe(conn net.Conn, topic string) error {
 	return nil
 }
 
+// mqttAwaitMessage reads packets until a PUBLISH arrives and returns its
+// payload, acknowledging QoS 1 and 2 deliveries. A QoS 2 message is returned
+// once the broker's PUBREL has been answered with PUBCOMP, so the exchange
+// completes before the checker disconnects. With retainedOnly, messages
+// without the retain flag are skipped. It returns the read error once the
+// connection deadline passes.
+func mqttAwaitMessage(conn net.Conn, retainedOnly bool) ([]byte, error) {
+	var pending []byte
+	var pendingID uint16
+	for {
+		header, body, err := readMQTTPacket(conn)
+		if err != nil {
+			return nil, err
+		}
+		if header>>4 == 6 { // PUBREL
+			if len(body) < 2 {
+				return nil, fmt.Errorf("MQTT malformed PUBREL packet")
+			}
+			if _, err := conn.Write([]byte{0x70, 0x02, body[0], body[1]}); err != nil {
+				return nil, fmt.Errorf("MQTT PUBCOMP send failed: %w", err)
+			}
+			if pending != nil && binary.BigEndian.Uint16(body[0:2]) == pendingID {
+				return pending, nil
+			}
+			continue
+		}
+		if header>>4 != 3 {
+			continue
+		}
+		retain := header&0x01 != 0
+		qos := (header >> 1) & 0x03
+		if len(body) < 2 {
+			return nil, fmt.Errorf("MQTT malformed PUBLISH packet")
+		}
+		offset := 2 + int(binary.BigEndian.Uint16(body[0:2]))
+		if qos > 0 {
+			if len(body) < offset+2 {
+				return nil, fmt.Errorf("MQTT malformed PUBLISH packet")
+			}
+			ack := byte(0x40) // PUBACK
+			if qos == 2 {
+				ack = 0x50 // PUBREC
+			}
+			if _, err := conn.Write([]byte{ack, 0x02, body[offset], body[offset+1]}); err != nil {
+				return nil, fmt.Errorf("MQTT acknowledgement send failed: %w", err)
+			}
+			offset += 2
+		}
+		if len(body) < offset {
+			return nil, fmt.Errorf("MQTT malformed PUBLISH packet")
+		}
+		if retainedOnly && !retain {
+			continue
+		}
+		if qos == 2 {
+			if pending == nil {
+				pending, pendingID = body[offset:], binary.BigEndian.Uint16(body[offset-2:offset])
+			}
+			continue
+		}
+		return body[offset:], nil
+	}
+}
+
+// mqttMaxPacketSize caps the packets readMQTTPacket accepts. A health
+// message is small; the cap stops a broker from declaring a remaining length
+// of up to 256 MB and having the checker allocate it.
+const mqttMaxPacketSize = 64 << 10
+
+// readMQTTPacket reads one control packet and returns its fixed header byte
+// and variable header plus payload.
+func readMQTTPacket(conn net.Conn) (byte, []byte, error) {
+	var header [1]byte
+	if _, err := readFull(conn, header[:]); err != nil {
+		return 0, nil, err
+	}
+	var lenBytes []byte
+	for {
+		var b [1]byte
+		if _, err := readFull(conn, b[:]); err != nil {
+			return 0, nil, err
+		}
+		lenBytes = append(lenBytes, b[0])
+		if b[0]&0x80 == 0 {
+			break
+		}
+		if len(lenBytes) == 4 {
+			return 0, nil, fmt.Errorf("MQTT malformed remaining length")
+		}
+	}
+	length, _ := decodeRemainingLength(lenBytes)
+	if length > mqttMaxPacketSize {
+		return 0, nil, fmt.Errorf("MQTT packet of %d bytes exceeds the %d byte limit", length, mqttMaxPacketSize)
+	}
+	body := make([]byte, length)
+	if _, err := readFull(conn, body); err != nil {
+		return 0, nil, err
+	}
+	return header[0], body, nil
+}
+
+// mqttDisconnect sends DISCONNECT, extending the deadline so a check that
+// timed out waiting for a message still leaves the broker cleanly.
+func mqttDisconnect(conn net.Conn) {
+	conn.SetWriteDeadline(time.Now().Add(time.Second))
+	conn.Write([]byte{0xe0, 0x00})
+}
+
 func readFull(conn net.Conn, buf []byte) (int, error) {
 	total := 0
 	for total < len(buf) {
@@ -218,12 +380,12 @@ func buildConnectPacket(clientID, username, password string) []byte {
 	return pkt
 }
 
-func buildSubscribePacket(packetID uint16, topic string) []byte {
+func buildSubscribePacket(packetID uint16, topic string, qos int) []byte {
 	var payload []byte
 	// packet identifier
 	payload = append(payload, byte(packetID>>8), byte(packetID))
 	payload = append(payload, encodeMQTTString(topic)...)
-	payload = append(payload, 0) // QoS 0
+	payload = append(payload, byte(qos)) // requested QoS
 
 	var pkt []byte
 	pkt = append(pkt, 0x82) // SUBSCRIBE packet type with QoS 1
@@ -1,7 +1,14 @@
 package checker
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
 	"testing"
+
+	"github.com/y0f/asura/internal/storage"
 )
 
 func TestEncodeMQTTString(t *testing.T) {
@@ -121,10 +128,186 @@ func TestBuildConnectPacket(t *testing.T) {
 }
 
 func TestBuildSubscribePacket(t *testing.T) {
-	pkt := buildSubscribePacket(1, "test/topic")
+	pkt := buildSubscribePacket(1, "test/topic", 1)
 	if pkt[0] != 0x82 {
 		t.Errorf("packet type = %02x, want 0x82", pkt[0])
 	}
+	if pkt[len(pkt)-1] != 1 {
+		t.Errorf("requested QoS = %d, want 1", pkt[len(pkt)-1])
+	}
+}
+
+// fakeMQTTBroker accepts one client, acknowledges CONNECT and SUBSCRIBE,
+// then sends the given PUBLISH packets and drains until the client leaves.
+// fakeMQTTBroker accepts one client, sends publish after its subscription
+// and answers PUBREC with PUBREL. The packet types the client sends after
+// subscribing are reported on the returned channel.
+func fakeMQTTBroker(t *testing.T, publish ...[]byte) (string, <-chan byte) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	received := make(chan byte, 16)
+	go func() {
+		defer close(received)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, _, err := readMQTTPacket(conn); err != nil {
+			return
+		}
+		conn.Write([]byte{0x20, 0x02, 0x00, 0x00})
+		_, sub, err := readMQTTPacket(conn)
+		if err != nil {
+			return
+		}
+		conn.Write([]byte{0x90, 0x03, sub[0], sub[1], sub[len(sub)-1]})
+		for _, pkt := range publish {
+			conn.Write(pkt)
+		}
+		for {
+			header, body, err := readMQTTPacket(conn)
+			if err != nil {
+				return
+			}
+			received <- header >> 4
+			if header>>4 == 5 && len(body) >= 2 { // PUBREC
+				conn.Write([]byte{0x62, 0x02, body[0], body[1]})
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func buildPublishPacket(topic, payload string, qos byte, retain bool) []byte {
+	header := byte(0x30) | qos<<1
+	if retain {
+		header |= 0x01
+	}
+	body := encodeMQTTString(topic)
+	if qos > 0 {
+		body = append(body, 0x00, 0x07)
+	}
+	body = append(body, payload...)
+	pkt := append([]byte{header}, encodeRemainingLength(len(body))...)
+	return append(pkt, body...)
+}
+
+func TestMQTTCheckerMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		settings   storage.MQTTSettings
+		publish    [][]byte
+		wantStatus string
+		wantMsg    string
+	}{
+		{
+			name:       "connect only",
+			settings:   storage.MQTTSettings{Topic: "health"},
+			wantStatus: "up",
+			wantMsg:    "MQTT connection successful",
+		},
+		{
+			name:       "expected message",
+			settings:   storage.MQTTSettings{Topic: "health", ExpectMessage: "ok"},
+			publish:    [][]byte{buildPublishPacket("health", "status=ok", 0, false)},
+			wantStatus: "up",
+			wantMsg:    "MQTT message received",
+		},
+		{
+			name:       "regex at qos 1",
+			settings:   storage.MQTTSettings{Topic: "health", ExpectRegex: `^status=(ok|ready)$`, QoS: 1},
+			publish:    [][]byte{buildPublishPacket("health", "status=ready", 1, false)},
+			wantStatus: "up",
+		},
+		{
+			name:       "mismatch is degraded",
+			settings:   storage.MQTTSettings{Topic: "health", ExpectMessage: "ok"},
+			publish:    [][]byte{buildPublishPacket("health", "status=failing", 0, false)},
+			wantStatus: "degraded",
+			wantMsg:    `does not contain "ok"`,
+		},
+		{
+			name:       "regex mismatch at qos 2",
+			settings:   storage.MQTTSettings{Topic: "health", ExpectRegex: `^ok$`, QoS: 2},
+			publish:    [][]byte{buildPublishPacket("health", "nope", 2, false)},
+			wantStatus: "degraded",
+			wantMsg:    "does not match",
+		},
+		{
+			name:       "retained message",
+			settings:   storage.MQTTSettings{Topic: "health", WaitRetained: true},
+			publish:    [][]byte{buildPublishPacket("health", "fresh", 0, false), buildPublishPacket("health", "kept", 0, true)},
+			wantStatus: "up",
+		},
+		{
+			name:       "no message",
+			settings:   storage.MQTTSettings{Topic: "health", ExpectMessage: "ok"},
+			wantStatus: "down",
+			wantMsg:    "no message on health within 1s",
+		},
+		{
+			name:       "no retained message",
+			settings:   storage.MQTTSettings{Topic: "health", WaitRetained: true},
+			publish:    [][]byte{buildPublishPacket("health", "fresh", 0, false)},
+			wantStatus: "down",
+			wantMsg:    "no retained message on health",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, _ := fakeMQTTBroker(t, tt.publish...)
+			settings, _ := json.Marshal(tt.settings)
+			c := &MQTTChecker{AllowPrivate: true}
+			res, err := c.Check(context.Background(), &storage.Monitor{Target: addr, Timeout: 1, Settings: settings})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != tt.wantStatus {
+				t.Fatalf("status = %q, want %q (%s)", res.Status, tt.wantStatus, res.Message)
+			}
+			if !strings.Contains(res.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want it to contain %q", res.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestMQTTCheckerQoS2Handshake(t *testing.T) {
+	addr, received := fakeMQTTBroker(t, buildPublishPacket("health", "ok", 2, false))
+	settings, _ := json.Marshal(storage.MQTTSettings{Topic: "health", ExpectMessage: "ok", QoS: 2})
+	c := &MQTTChecker{AllowPrivate: true}
+	res, err := c.Check(context.Background(), &storage.Monitor{Target: addr, Timeout: 1, Settings: settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != "up" {
+		t.Fatalf("status = %q, want up (%s)", res.Status, res.Message)
+	}
+	var got []byte
+	for typ := range received {
+		got = append(got, typ)
+	}
+	if want := []byte{5, 7, 14}; !bytes.Equal(got, want) {
+		t.Errorf("client sent packet types %v, want PUBREC, PUBCOMP, DISCONNECT %v", got, want)
+	}
+}
+
+func TestReadMQTTPacketLimit(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		// A PUBLISH declaring the maximum remaining length of 256 MB.
+		server.Write([]byte{0x30, 0xff, 0xff, 0xff, 0x7f})
+		server.Close()
+	}()
+	if _, _, err := readMQTTPacket(client); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("expected the oversized packet rejected, got %v", err)
+	}
 }
 
 func TestMqttConnackError(t *testing.T) {
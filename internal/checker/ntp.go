@@ -0,0 +1,196 @@
+package checker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/y0f/asura/internal/safenet"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// ntpEpochOffset is the number of seconds between the NTP era 0 epoch
+// (1900-01-01) and the Unix epoch.
+const ntpEpochOffset = 2208988800
+
+const ntpPacketSize = 48
+
+type NTPChecker struct {
+	AllowPrivate bool
+}
+
+func (c *NTPChecker) Type() string { return "ntp" }
+
+// ntpReply holds the fields of a server reply the check looks at.
+type ntpReply struct {
+	Leap      uint8
+	Mode      uint8
+	Stratum   uint8
+	RefID     [4]byte
+	Originate [8]byte
+	Receive   time.Time
+	Transmit  time.Time
+}
+
+func (c *NTPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Result, error) {
+	var settings storage.NTPSettings
+	if len(monitor.Settings) > 0 {
+		if err := json.Unmarshal(monitor.Settings, &settings); err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: %v", err)}, nil
+		}
+	}
+
+	target := monitor.Target
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target += ":123"
+	}
+
+	timeout := time.Duration(monitor.Timeout) * time.Second
+	dialFn := sourceDial(&net.Dialer{Timeout: timeout, Control: safenet.MaybeDialControl(c.AllowPrivate)}, monitor.SourceAddr)
+
+	start := time.Now()
+	conn, err := dialFn(ctx, "udp", target)
+	if err != nil {
+		return &Result{
+			Status:       "down",
+			ResponseTime: time.Since(start).Milliseconds(),
+			Message:      fmt.Sprintf("NTP connection failed: %v", err),
+		}, nil
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	// The transmit timestamp is random rather than the local clock so a
+	// reply can be matched to this request without leaking the local time.
+	req := make([]byte, ntpPacketSize)
+	req[0] = 0<<6 | 4<<3 | 3 // LI 0, version 4, mode 3 (client)
+	rand.Read(req[40:48])
+
+	t1 := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return &Result{Status: "down", ResponseTime: time.Since(start).Milliseconds(), Message: fmt.Sprintf("NTP request failed: %v", err)}, nil
+	}
+	buf := make([]byte, 512)
+	var reply ntpReply
+	var t4 time.Time
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return &Result{Status: "down", ResponseTime: time.Since(start).Milliseconds(), Message: fmt.Sprintf("NTP read failed: %v", err)}, nil
+		}
+		t4 = time.Now()
+		if n < ntpPacketSize {
+			continue
+		}
+		reply = parseNTPReply(buf[:n])
+		if string(reply.Originate[:]) == string(req[40:48]) {
+			break
+		}
+	}
+
+	rtt := t4.Sub(t1) - reply.Transmit.Sub(reply.Receive)
+	if rtt < 0 {
+		rtt = 0
+	}
+	elapsed := rtt.Milliseconds()
+
+	if reply.Mode != 4 && reply.Mode != 5 {
+		return &Result{Status: "down", ResponseTime: elapsed, Message: fmt.Sprintf("unexpected NTP mode %d in reply", reply.Mode)}, nil
+	}
+	if reply.Stratum == 0 {
+		return &Result{Status: "down", ResponseTime: elapsed, Message: fmt.Sprintf("NTP kiss-of-death: %s", ntpRefID(reply))}, nil
+	}
+	if reply.Leap == 3 {
+		return &Result{Status: "down", ResponseTime: elapsed, Message: "NTP server clock is not synchronized"}, nil
+	}
+
+	// offset = ((T2 - T1) + (T3 - T4)) / 2, with T1 and T4 on the local
+	// clock and T2 and T3 on the server's.
+	offset := (reply.Receive.Sub(t1) + reply.Transmit.Sub(t4)) / 2
+	offsetMs := float64(offset.Microseconds()) / 1000
+
+	body, _ := json.Marshal(map[string]any{
+		"offset_ms":    offsetMs,
+		"rtt_ms":       float64(rtt.Microseconds()) / 1000,
+		"stratum":      reply.Stratum,
+		"reference_id": ntpRefID(reply),
+		"leap":         reply.Leap,
+	})
+	msg := fmt.Sprintf("offset %+.3fms, stratum %d (%s), rtt %dms", offsetMs, reply.Stratum, ntpRefID(reply), elapsed)
+	status, reason := ntpStatus(settings, offsetMs, reply.Stratum)
+	if reason != "" {
+		msg += ": " + reason
+	}
+	return &Result{
+		Status:       status,
+		ResponseTime: elapsed,
+		Body:         string(body),
+		Message:      msg,
+	}, nil
+}
+
+func ntpStatus(settings storage.NTPSettings, offsetMs float64, stratum uint8) (string, string) {
+	abs := offsetMs
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case settings.MaxStratum > 0 && int(stratum) > settings.MaxStratum:
+		return "down", fmt.Sprintf("stratum exceeds maximum %d", settings.MaxStratum)
+	case settings.CritOffsetMs > 0 && abs > float64(settings.CritOffsetMs):
+		return "down", fmt.Sprintf("offset exceeds critical threshold %dms", settings.CritOffsetMs)
+	case settings.MaxOffsetMs > 0 && abs > float64(settings.MaxOffsetMs):
+		return "degraded", fmt.Sprintf("offset exceeds threshold %dms", settings.MaxOffsetMs)
+	default:
+		return "up", ""
+	}
+}
+
+func parseNTPReply(b []byte) ntpReply {
+	r := ntpReply{
+		Leap:     b[0] >> 6,
+		Mode:     b[0] & 0x7,
+		Stratum:  b[1],
+		Receive:  ntpTime(b[32:40]),
+		Transmit: ntpTime(b[40:48]),
+	}
+	copy(r.RefID[:], b[12:16])
+	copy(r.Originate[:], b[24:32])
+	return r
+}
+
+// ntpTime converts a 64-bit NTP timestamp to a time.Time. Seconds with the
+// high bit clear are taken to be in era 1 (from 2036), as RFC 4330 suggests.
+func ntpTime(b []byte) time.Time {
+	sec := int64(binary.BigEndian.Uint32(b[0:4]))
+	if sec&0x80000000 == 0 {
+		sec += 1 << 32
+	}
+	sec -= ntpEpochOffset
+	frac := int64(binary.BigEndian.Uint32(b[4:8]))
+	return time.Unix(sec, frac*1e9>>32)
+}
+
+// ntpRefID renders the reference ID: a four-character code for stratum 0
+// and 1 (kiss code or clock source), an IPv4 address otherwise.
+func ntpRefID(r ntpReply) string {
+	if r.Stratum <= 1 {
+		code := make([]byte, 0, 4)
+		for _, c := range r.RefID {
+			if c == 0 {
+				break
+			}
+			code = append(code, c)
+		}
+		return string(code)
+	}
+	return net.IP(r.RefID[:]).String()
+}
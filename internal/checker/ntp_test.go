@@ -0,0 +1,137 @@
+package checker
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// fakeNTP answers SNTP requests with a clock running skew ahead of the local
+// one.
+func fakeNTP(t *testing.T, skew time.Duration, stratum, leap byte) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < ntpPacketSize {
+				continue
+			}
+			now := time.Now().Add(skew)
+			resp := make([]byte, ntpPacketSize)
+			resp[0] = leap<<6 | 4<<3 | 4
+			resp[1] = stratum
+			copy(resp[12:16], []byte{10, 0, 0, 1})
+			if stratum == 0 {
+				copy(resp[12:16], "RATE")
+			}
+			copy(resp[24:32], buf[40:48])
+			putNTPTime(resp[32:40], now)
+			putNTPTime(resp[40:48], now)
+			pc.WriteTo(resp, addr)
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+func putNTPTime(b []byte, t time.Time) {
+	binary.BigEndian.PutUint32(b[0:4], uint32(t.Unix()+ntpEpochOffset))
+	binary.BigEndian.PutUint32(b[4:8], uint32((int64(t.Nanosecond())<<32)/1e9))
+}
+
+func TestNTPChecker(t *testing.T) {
+	tests := []struct {
+		name       string
+		skew       time.Duration
+		stratum    byte
+		leap       byte
+		settings   storage.NTPSettings
+		wantStatus string
+		wantMsg    string
+	}{
+		{"in sync", 0, 2, 0, storage.NTPSettings{MaxOffsetMs: 100}, "up", "stratum 2 (10.0.0.1)"},
+		{"degraded", 300 * time.Millisecond, 2, 0, storage.NTPSettings{MaxOffsetMs: 100, CritOffsetMs: 1000}, "degraded", "exceeds threshold 100ms"},
+		{"critical", -2 * time.Second, 2, 0, storage.NTPSettings{MaxOffsetMs: 100, CritOffsetMs: 1000}, "down", "critical threshold 1000ms"},
+		{"no threshold", 2 * time.Second, 2, 0, storage.NTPSettings{}, "up", "offset +2"},
+		{"stratum", 0, 4, 0, storage.NTPSettings{MaxStratum: 3}, "down", "stratum exceeds maximum 3"},
+		{"kiss of death", 0, 0, 0, storage.NTPSettings{}, "down", "kiss-of-death: RATE"},
+		{"unsynchronized", 0, 2, 3, storage.NTPSettings{}, "down", "not synchronized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := fakeNTP(t, tt.skew, tt.stratum, tt.leap)
+			settings, _ := json.Marshal(tt.settings)
+			c := &NTPChecker{AllowPrivate: true}
+			result, err := c.Check(context.Background(), &storage.Monitor{
+				Type: "ntp", Target: addr, Timeout: 5, Settings: settings,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q (%s)", result.Status, tt.wantStatus, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want substring %q", result.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestNTPCheckerBody(t *testing.T) {
+	addr := fakeNTP(t, 500*time.Millisecond, 1, 0)
+	c := &NTPChecker{AllowPrivate: true}
+	result, _ := c.Check(context.Background(), &storage.Monitor{Type: "ntp", Target: addr, Timeout: 5})
+
+	var body struct {
+		OffsetMs float64 `json:"offset_ms"`
+		Stratum  int     `json:"stratum"`
+	}
+	if err := json.Unmarshal([]byte(result.Body), &body); err != nil {
+		t.Fatalf("body %q: %v", result.Body, err)
+	}
+	if body.OffsetMs < 400 || body.OffsetMs > 600 || body.Stratum != 1 {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestNTPCheckerTimeout(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	c := &NTPChecker{AllowPrivate: true}
+	start := time.Now()
+	result, _ := c.Check(context.Background(), &storage.Monitor{Type: "ntp", Target: pc.LocalAddr().String(), Timeout: 1})
+	if result.Status != "down" || !strings.Contains(result.Message, "read failed") {
+		t.Errorf("got %q: %s", result.Status, result.Message)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("check took %s, want about the 1s timeout", time.Since(start))
+	}
+}
+
+func TestNTPCheckerBlocksPrivate(t *testing.T) {
+	c := &NTPChecker{}
+	result, _ := c.Check(context.Background(), &storage.Monitor{Type: "ntp", Target: "127.0.0.1", Timeout: 1})
+	if result.Status != "down" || !strings.Contains(result.Message, "connection failed") {
+		t.Errorf("got %q: %s", result.Status, result.Message)
+	}
+}
@@ -0,0 +1,204 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/safenet"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// maxRedisReply caps how much of a reply is read, enough for INFO.
+const maxRedisReply = 64 * 1024
+
+type RedisChecker struct {
+	AllowPrivate bool
+}
+
+func (c *RedisChecker) Type() string { return "redis" }
+
+func (c *RedisChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Result, error) {
+	var settings storage.RedisSettings
+	if len(monitor.Settings) > 0 {
+		if err := json.Unmarshal(monitor.Settings, &settings); err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: %v", err)}, nil
+		}
+	}
+
+	target := monitor.Target
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target += ":6379"
+	}
+
+	timeout := time.Duration(monitor.Timeout) * time.Second
+	baseDial := sourceDial(&net.Dialer{Timeout: timeout, Control: safenet.MaybeDialControl(c.AllowPrivate)}, monitor.SourceAddr)
+
+	dialFn := baseDial
+	if socks := ProxyDialer(monitor.ProxyURL, baseDial); socks != nil {
+		dialFn = socks
+	}
+
+	start := time.Now()
+	conn, err := dialFn(ctx, "tcp", target)
+	if err != nil {
+		return &Result{
+			Status:       "down",
+			ResponseTime: time.Since(start).Milliseconds(),
+			Message:      fmt.Sprintf("Redis connection failed: %v", err),
+		}, nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if settings.UseTLS {
+		host, _, _ := net.SplitHostPort(target)
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return &Result{
+				Status:       "down",
+				ResponseTime: time.Since(start).Milliseconds(),
+				Message:      fmt.Sprintf("Redis TLS handshake failed: %v", err),
+			}, nil
+		}
+		conn = tlsConn
+	}
+
+	r := bufio.NewReader(conn)
+	if settings.Password != "" {
+		args := []string{"AUTH", settings.Password}
+		if settings.Username != "" {
+			args = []string{"AUTH", settings.Username, settings.Password}
+		}
+		if _, err := redisDo(conn, r, args...); err != nil {
+			return &Result{
+				Status:       "down",
+				ResponseTime: time.Since(start).Milliseconds(),
+				Message:      fmt.Sprintf("Redis AUTH failed: %v", err),
+			}, nil
+		}
+	}
+	if settings.DB > 0 {
+		if _, err := redisDo(conn, r, "SELECT", strconv.Itoa(settings.DB)); err != nil {
+			return &Result{
+				Status:       "down",
+				ResponseTime: time.Since(start).Milliseconds(),
+				Message:      fmt.Sprintf("Redis SELECT %d failed: %v", settings.DB, err),
+			}, nil
+		}
+	}
+
+	args := strings.Fields(settings.Command)
+	if len(args) == 0 {
+		args = []string{"PING"}
+	}
+	cmdStart := time.Now()
+	reply, err := redisDo(conn, r, args...)
+	elapsed := time.Since(cmdStart).Milliseconds()
+	redisDo(conn, r, "QUIT")
+
+	if err != nil {
+		return &Result{
+			Status:       "down",
+			ResponseTime: elapsed,
+			Message:      fmt.Sprintf("Redis %s failed: %v", strings.ToUpper(args[0]), err),
+		}, nil
+	}
+	if strings.EqualFold(args[0], "PING") && len(args) == 1 && reply != "PONG" {
+		return &Result{
+			Status:       "down",
+			ResponseTime: elapsed,
+			Body:         reply,
+			Message:      fmt.Sprintf("expected PONG, got %q", reply),
+		}, nil
+	}
+
+	msg := fmt.Sprintf("%s in %dms", strings.ToUpper(args[0]), elapsed)
+	if !strings.Contains(reply, "\n") && len(reply) <= 64 {
+		msg += ": " + reply
+	}
+	return &Result{
+		Status:       "up",
+		ResponseTime: elapsed,
+		Body:         reply,
+		Message:      msg,
+	}, nil
+}
+
+// redisError is an error reply from the server.
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+// redisDo sends args as a RESP array and reads one reply. Arrays are
+// flattened to newline-separated elements and nil replies are empty.
+func redisDo(w io.Writer, r *bufio.Reader, args ...string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return "", err
+	}
+	budget := maxRedisReply
+	return readRESP(r, &budget)
+}
+
+func readRESP(r *bufio.Reader, budget *int) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New("empty reply")
+	}
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", redisError(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid bulk length %q", line[1:])
+		}
+		if n < 0 {
+			return "", nil
+		}
+		if n > *budget {
+			return "", fmt.Errorf("reply larger than %d bytes", maxRedisReply)
+		}
+		*budget -= n
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid array length %q", line[1:])
+		}
+		elems := make([]string, 0, max(n, 0))
+		for i := 0; i < n; i++ {
+			e, err := readRESP(r, budget)
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, e)
+		}
+		return strings.Join(elems, "\n"), nil
+	default:
+		return "", fmt.Errorf("unexpected reply %q", line)
+	}
+}
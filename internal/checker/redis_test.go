@@ -0,0 +1,149 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// fakeRedis answers RESP commands with a password of "secret" and a few
+// canned replies. A wrong password is refused after authFailDelay.
+func fakeRedis(t *testing.T) string {
+	return tcpServer(t, func(c net.Conn) {
+		r := bufio.NewReader(c)
+		authed := false
+		for {
+			args, err := readRESPCommand(r)
+			if err != nil {
+				return
+			}
+			switch strings.ToUpper(args[0]) {
+			case "AUTH":
+				if args[len(args)-1] != "secret" {
+					time.Sleep(authFailDelay)
+					c.Write([]byte("-WRONGPASS invalid username-password pair\r\n"))
+					continue
+				}
+				authed = true
+				c.Write([]byte("+OK\r\n"))
+			case "SELECT":
+				c.Write([]byte("+OK\r\n"))
+			case "PING":
+				if !authed {
+					c.Write([]byte("-NOAUTH Authentication required.\r\n"))
+					continue
+				}
+				c.Write([]byte("+PONG\r\n"))
+			case "DBSIZE":
+				c.Write([]byte(":42\r\n"))
+			case "INFO":
+				info := "# Replication\r\nrole:master\r\nconnected_slaves:0\r\n"
+				fmt.Fprintf(c, "$%d\r\n%s\r\n", len(info), info)
+			case "QUIT":
+				c.Write([]byte("+OK\r\n"))
+				return
+			default:
+				c.Write([]byte("-ERR unknown command\r\n"))
+			}
+		}
+	})
+}
+
+func readRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		hdr, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(hdr[1:]))
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func TestRedisChecker(t *testing.T) {
+	addr := fakeRedis(t)
+
+	tests := []struct {
+		name       string
+		settings   storage.RedisSettings
+		wantStatus string
+		wantMsg    string
+		wantBody   string
+	}{
+		{"ping", storage.RedisSettings{Password: "secret"}, "up", "PING", "PONG"},
+		{"acl user", storage.RedisSettings{Username: "monitor", Password: "secret", DB: 2}, "up", "PONG", "PONG"},
+		{"wrong password", storage.RedisSettings{Password: "nope"}, "down", "AUTH failed: WRONGPASS", ""},
+		{"no auth", storage.RedisSettings{}, "down", "NOAUTH", ""},
+		{"dbsize", storage.RedisSettings{Command: "DBSIZE"}, "up", "DBSIZE", "42"},
+		{"info", storage.RedisSettings{Command: "INFO replication"}, "up", "INFO", "role:master"},
+		{"error reply", storage.RedisSettings{Command: "GET missing"}, "down", "unknown command", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, _ := json.Marshal(tt.settings)
+			c := &RedisChecker{AllowPrivate: true}
+			result, err := c.Check(context.Background(), &storage.Monitor{
+				Type: "redis", Target: addr, Timeout: 5, Settings: settings,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q (%s)", result.Status, tt.wantStatus, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want substring %q", result.Message, tt.wantMsg)
+			}
+			if !strings.Contains(result.Body, tt.wantBody) {
+				t.Errorf("body = %q, want substring %q", result.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRedisCheckerAuthFailureResponseTime(t *testing.T) {
+	settings, _ := json.Marshal(storage.RedisSettings{Password: "nope"})
+	c := &RedisChecker{AllowPrivate: true}
+	result, err := c.Check(context.Background(), &storage.Monitor{
+		Type: "redis", Target: fakeRedis(t), Timeout: 5, Settings: settings,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "down" || result.ResponseTime < authFailDelay.Milliseconds() {
+		t.Fatalf("got %s in %dms, want down after at least %v", result.Status, result.ResponseTime, authFailDelay)
+	}
+}
+
+func TestRedisCheckerConnectionRefused(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+
+	c := &RedisChecker{AllowPrivate: true}
+	result, _ := c.Check(context.Background(), &storage.Monitor{Type: "redis", Target: addr, Timeout: 2})
+	if result.Status != "down" || !strings.Contains(result.Message, "connection failed") {
+		t.Errorf("got %q: %s", result.Status, result.Message)
+	}
+}
@@ -0,0 +1,215 @@
+package checker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/safenet"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Checker verifies that an object exists in S3-compatible storage and,
+// optionally, that it was modified recently.
+type S3Checker struct {
+	AllowPrivate bool
+}
+
+func (c *S3Checker) Type() string { return "s3" }
+
+func (c *S3Checker) Check(ctx context.Context, monitor *storage.Monitor) (*Result, error) {
+	var settings storage.S3Settings
+	if len(monitor.Settings) > 0 {
+		if err := json.Unmarshal(monitor.Settings, &settings); err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: %v", err)}, nil
+		}
+	}
+
+	objectURL, err := s3ObjectURL(monitor.Target, settings)
+	if err != nil {
+		return &Result{Status: "down", Message: err.Error()}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, objectURL, nil)
+	if err != nil {
+		return &Result{Status: "down", Message: fmt.Sprintf("invalid request: %v", err)}, nil
+	}
+	if settings.AccessKeyID != "" {
+		region := settings.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+		signS3Request(req, settings.AccessKeyID, settings.SecretAccessKey, region, time.Now().UTC())
+	}
+
+	timeout := time.Duration(monitor.Timeout) * time.Second
+	baseDial := sourceDial(&net.Dialer{
+		Timeout: timeout,
+		Control: safenet.MaybeDialControl(c.AllowPrivate),
+	}, monitor.SourceAddr)
+
+	transport := &http.Transport{
+		DialContext:       baseDial,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: settings.SkipTLSVerify},
+		DisableKeepAlives: true,
+	}
+	applyHTTPProxy(transport, monitor.ProxyURL, baseDial)
+	client := &http.Client{Transport: transport, Timeout: timeout}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		return &Result{
+			Status:       "down",
+			ResponseTime: elapsed,
+			Message:      fmt.Sprintf("S3 request failed: %v", err),
+		}, nil
+	}
+	resp.Body.Close()
+
+	result := &Result{ResponseTime: elapsed, StatusCode: resp.StatusCode, Status: "down"}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		result.Message = fmt.Sprintf("object s3://%s/%s not found", settings.Bucket, settings.Key)
+		return result, nil
+	case resp.StatusCode == http.StatusForbidden:
+		result.Message = "access denied (check credentials, region and bucket policy)"
+		return result, nil
+	case resp.StatusCode != http.StatusOK:
+		result.Message = fmt.Sprintf("S3 returned HTTP %d", resp.StatusCode)
+		return result, nil
+	}
+
+	result.Headers = map[string]string{}
+	for _, h := range []string{"Last-Modified", "Content-Length", "Content-Type", "ETag"} {
+		if v := resp.Header.Get(h); v != "" {
+			result.Headers[h] = v
+		}
+	}
+
+	lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if settings.MaxAgeSeconds > 0 {
+		if err != nil {
+			result.Message = "object has no valid Last-Modified header"
+			return result, nil
+		}
+		maxAge := time.Duration(settings.MaxAgeSeconds) * time.Second
+		if age := time.Since(lastModified); age > maxAge {
+			result.Message = fmt.Sprintf("object is %s old, exceeds max age %s", age.Truncate(time.Second), maxAge)
+			return result, nil
+		}
+	}
+
+	result.Status = "up"
+	result.Message = fmt.Sprintf("object exists (%s bytes)", resp.Header.Get("Content-Length"))
+	if err == nil {
+		result.Message += fmt.Sprintf(", modified %s ago", time.Since(lastModified).Truncate(time.Second))
+	}
+	return result, nil
+}
+
+// s3ObjectURL builds the object URL from the endpoint target, using
+// path-style addressing unless VirtualHosted is set.
+func s3ObjectURL(endpoint string, settings storage.S3Settings) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("target must be the S3 endpoint URL, e.g. https://s3.eu-west-1.amazonaws.com")
+	}
+	key := strings.TrimPrefix(settings.Key, "/")
+	if settings.VirtualHosted {
+		u.Host = settings.Bucket + "." + u.Host
+		u.Path = "/" + key
+	} else {
+		u.Path = "/" + settings.Bucket + "/" + key
+	}
+	u.RawQuery = ""
+	return u.String(), nil
+}
+
+// signS3Request adds an AWS Signature Version 4 Authorization header to req.
+// The payload hash is taken from X-Amz-Content-Sha256; host and every header
+// already set on req are signed.
+func signS3Request(req *http.Request, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(q url.Values) string {
+	if len(q) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vals := q[k]
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, url.QueryEscape(k)+"="+strings.ReplaceAll(url.QueryEscape(v), "+", "%20"))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,175 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/safenet"
+	"github.com/y0f/asura/internal/storage"
+)
+
+type SMTPChecker struct {
+	AllowPrivate bool
+}
+
+func (c *SMTPChecker) Type() string { return "smtp" }
+
+func (c *SMTPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Result, error) {
+	var settings storage.SMTPSettings
+	if len(monitor.Settings) > 0 {
+		if err := json.Unmarshal(monitor.Settings, &settings); err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: %v", err)}, nil
+		}
+	}
+
+	target := monitor.Target
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target += ":25"
+	}
+	host, _, _ := net.SplitHostPort(target)
+
+	timeout := time.Duration(monitor.Timeout) * time.Second
+	baseDial := sourceDial(&net.Dialer{Timeout: timeout, Control: safenet.MaybeDialControl(c.AllowPrivate)}, monitor.SourceAddr)
+
+	dialFn := baseDial
+	if socks := ProxyDialer(monitor.ProxyURL, baseDial); socks != nil {
+		dialFn = socks
+	}
+
+	start := time.Now()
+	down := func(format string, args ...any) (*Result, error) {
+		return &Result{
+			Status:       "down",
+			ResponseTime: time.Since(start).Milliseconds(),
+			Message:      fmt.Sprintf(format, args...),
+		}, nil
+	}
+
+	rawConn, err := dialFn(ctx, "tcp", target)
+	if err != nil {
+		return down("SMTP connection failed: %v", err)
+	}
+	defer rawConn.Close()
+	rawConn.SetDeadline(time.Now().Add(timeout))
+
+	conn := &greetingConn{Conn: rawConn}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return down("SMTP greeting failed: %v", err)
+	}
+	defer client.Close()
+	greeting := conn.greeting()
+
+	if err := client.Hello("asura.local"); err != nil {
+		return down("SMTP EHLO failed: %v", err)
+	}
+
+	result := &Result{Status: "up"}
+	var steps []string
+	if settings.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return down("server does not offer STARTTLS")
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: host, InsecureSkipVerify: settings.SkipTLSVerify}); err != nil {
+			return down("SMTP STARTTLS failed: %v", err)
+		}
+		if state, ok := client.TLSConnectionState(); ok {
+			result.TLS = tlsDetails(state)
+			if len(state.PeerCertificates) > 0 {
+				expiry := state.PeerCertificates[0].NotAfter.Unix()
+				result.CertExpiry = &expiry
+			}
+		}
+		steps = append(steps, "STARTTLS")
+	}
+
+	if settings.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", settings.Username, settings.Password, host)); err != nil {
+			return down("SMTP AUTH failed: %v", err)
+		}
+		steps = append(steps, "AUTH")
+	}
+
+	if settings.SendTestMail {
+		if err := sendSMTPTestMail(client, settings); err != nil {
+			return down("SMTP test mail failed: %v", err)
+		}
+		steps = append(steps, "test mail accepted")
+	}
+
+	client.Quit()
+
+	result.ResponseTime = time.Since(start).Milliseconds()
+	result.Body = greeting
+	result.Message = "SMTP " + greeting
+	if len(steps) > 0 {
+		result.Message += " (" + strings.Join(steps, ", ") + ")"
+	}
+	if settings.ExpectGreeting != "" && !strings.Contains(greeting, settings.ExpectGreeting) {
+		result.Status = "degraded"
+		result.Message = fmt.Sprintf("greeting %q does not contain %q", greeting, settings.ExpectGreeting)
+	}
+	return result, nil
+}
+
+func sendSMTPTestMail(client *smtp.Client, settings storage.SMTPSettings) error {
+	if err := client.Mail(settings.MailFrom); err != nil {
+		return err
+	}
+	if err := client.Rcpt(settings.MailTo); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: Asura SMTP check\r\nDate: %s\r\n\r\nThis is an automated delivery check from Asura.\r\n",
+		settings.MailFrom, settings.MailTo, time.Now().UTC().Format(time.RFC1123Z))
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// greetingConn records what the server sends before the client first
+// writes, which is the 220 greeting that smtp.NewClient consumes.
+type greetingConn struct {
+	net.Conn
+	buf   []byte
+	wrote bool
+}
+
+func (c *greetingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if !c.wrote && len(c.buf) < maxBannerRead {
+		c.buf = append(c.buf, p[:n]...)
+	}
+	return n, err
+}
+
+func (c *greetingConn) Write(p []byte) (int, error) {
+	c.wrote = true
+	return c.Conn.Write(p)
+}
+
+// greeting returns the greeting text without reply codes, joining
+// multi-line greetings with spaces.
+func (c *greetingConn) greeting() string {
+	var parts []string
+	for _, line := range strings.Split(strings.TrimSpace(string(c.buf)), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) >= 4 {
+			line = line[4:]
+		}
+		if line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, " ")
+}
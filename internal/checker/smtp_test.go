@@ -0,0 +1,168 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// fakeSMTP speaks just enough SMTP for the checker: greeting, EHLO, MAIL,
+// RCPT, DATA and QUIT. It records the accepted message bodies. With tlsCfg it
+// also offers STARTTLS and, once TLS is up, AUTH PLAIN for relay/secret.
+func fakeSMTP(t *testing.T, delivered chan<- string, tlsCfg *tls.Config) string {
+	return tcpServer(t, func(c net.Conn) {
+		r := bufio.NewReader(c)
+		c.Write([]byte("220-mail.test ESMTP Postfix\r\n220 ready\r\n"))
+		secure := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+			case "EHLO":
+				switch {
+				case secure:
+					c.Write([]byte("250-mail.test\r\n250-AUTH PLAIN\r\n250 8BITMIME\r\n"))
+				case tlsCfg != nil:
+					c.Write([]byte("250-mail.test\r\n250-STARTTLS\r\n250 8BITMIME\r\n"))
+				default:
+					c.Write([]byte("250-mail.test\r\n250 8BITMIME\r\n"))
+				}
+			case "STARTTLS":
+				c.Write([]byte("220 go ahead\r\n"))
+				tc := tls.Server(c, tlsCfg)
+				if err := tc.Handshake(); err != nil {
+					return
+				}
+				c, r, secure = tc, bufio.NewReader(tc), true
+			case "AUTH":
+				creds, _ := base64.StdEncoding.DecodeString(strings.Fields(line)[2])
+				if !secure || string(creds) != "\x00relay\x00secret" {
+					c.Write([]byte("535 authentication failed\r\n"))
+					continue
+				}
+				c.Write([]byte("235 authenticated\r\n"))
+			case "MAIL", "RCPT":
+				c.Write([]byte("250 ok\r\n"))
+			case "DATA":
+				c.Write([]byte("354 go ahead\r\n"))
+				var body strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					body.WriteString(l)
+				}
+				delivered <- body.String()
+				c.Write([]byte("250 queued\r\n"))
+			case "QUIT":
+				c.Write([]byte("221 bye\r\n"))
+				return
+			default:
+				c.Write([]byte("502 unsupported\r\n"))
+			}
+		}
+	})
+}
+
+func TestSMTPChecker(t *testing.T) {
+	delivered := make(chan string, 1)
+	addr := fakeSMTP(t, delivered, nil)
+
+	tests := []struct {
+		name       string
+		settings   storage.SMTPSettings
+		wantStatus string
+		wantMsg    string
+	}{
+		{"greeting", storage.SMTPSettings{}, "up", "SMTP mail.test ESMTP Postfix ready"},
+		{"greeting match", storage.SMTPSettings{ExpectGreeting: "Postfix"}, "up", "ESMTP Postfix"},
+		{"greeting mismatch", storage.SMTPSettings{ExpectGreeting: "Exim"}, "degraded", `does not contain "Exim"`},
+		{"starttls not offered", storage.SMTPSettings{StartTLS: true}, "down", "does not offer STARTTLS"},
+		{"test mail", storage.SMTPSettings{SendTestMail: true, MailFrom: "monitor@example.com", MailTo: "sink@example.com"}, "up", "test mail accepted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, _ := json.Marshal(tt.settings)
+			c := &SMTPChecker{AllowPrivate: true}
+			result, err := c.Check(context.Background(), &storage.Monitor{
+				Type: "smtp", Target: addr, Timeout: 5, Settings: settings,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q (%s)", result.Status, tt.wantStatus, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want substring %q", result.Message, tt.wantMsg)
+			}
+		})
+	}
+
+	select {
+	case body := <-delivered:
+		if !strings.Contains(body, "To: sink@example.com") {
+			t.Errorf("unexpected test mail: %q", body)
+		}
+	default:
+		t.Error("expected the test mail to be delivered")
+	}
+}
+
+func TestSMTPCheckerAuth(t *testing.T) {
+	leaf := issueCert(t, "localhost", nil, false)
+	cfg := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{leaf.cert.Raw}, PrivateKey: leaf.key}}}
+	addr := fakeSMTP(t, make(chan string, 1), cfg)
+
+	tests := []struct {
+		name       string
+		password   string
+		wantStatus string
+		wantMsg    string
+	}{
+		{"accepted", "secret", "up", "(STARTTLS, AUTH)"},
+		{"rejected", "wrong", "down", "SMTP AUTH failed: 535"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, _ := json.Marshal(storage.SMTPSettings{StartTLS: true, SkipTLSVerify: true, Username: "relay", Password: tt.password})
+			c := &SMTPChecker{AllowPrivate: true}
+			result, err := c.Check(context.Background(), &storage.Monitor{
+				Type: "smtp", Target: addr, Timeout: 5, Settings: settings,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus || !strings.Contains(result.Message, tt.wantMsg) {
+				t.Errorf("got %q %q, want %q containing %q", result.Status, result.Message, tt.wantStatus, tt.wantMsg)
+			}
+			if result.Status == "up" && result.TLS == nil {
+				t.Error("expected TLS details from STARTTLS")
+			}
+		})
+	}
+}
+
+func TestSMTPCheckerConnectionRefused(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+
+	c := &SMTPChecker{AllowPrivate: true}
+	result, _ := c.Check(context.Background(), &storage.Monitor{Type: "smtp", Target: addr, Timeout: 2})
+	if result.Status != "down" || !strings.Contains(result.Message, "connection failed") {
+		t.Errorf("got %q: %s", result.Status, result.Message)
+	}
+}
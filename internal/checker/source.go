@@ -0,0 +1,26 @@
+package checker
+
+import (
+	"context"
+	"net"
+	"strings"
+)
+
+// sourceDial returns d.DialContext with outgoing connections bound to the
+// local IP source, so a probe can pin checks to one interface or address.
+// An empty source leaves the choice to the OS.
+func sourceDial(d *net.Dialer, source string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	ip := net.ParseIP(source)
+	if ip == nil {
+		return d.DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		bound := *d
+		if strings.HasPrefix(network, "udp") {
+			bound.LocalAddr = &net.UDPAddr{IP: ip}
+		} else {
+			bound.LocalAddr = &net.TCPAddr{IP: ip}
+		}
+		return bound.DialContext(ctx, network, addr)
+	}
+}
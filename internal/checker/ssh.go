@@ -0,0 +1,467 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/safenet"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// sshClientVersion is the identification string sent to the server.
+const sshClientVersion = "SSH-2.0-Asura"
+
+const (
+	sshMsgDisconnect    = 1
+	sshMsgIgnore        = 2
+	sshMsgUnimplemented = 3
+	sshMsgDebug         = 4
+	sshMsgKexInit       = 20
+	sshMsgKexECDHInit   = 30
+	sshMsgKexECDHReply  = 31
+)
+
+// maxSSHPacket bounds handshake packets. KEXINIT lists and host keys are a
+// few kilobytes at most.
+const maxSSHPacket = 64 * 1024
+
+// The checker only runs the key exchange far enough to verify the host key,
+// so it offers the kex and host key algorithms it can verify and a common set
+// of ciphers and MACs it never uses.
+var (
+	_sshKexAlgos     = []string{"curve25519-sha256", "curve25519-sha256@libssh.org", "ecdh-sha2-nistp256"}
+	_sshHostKeyAlgos = []string{"ssh-ed25519", "ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521", "rsa-sha2-512", "rsa-sha2-256"}
+	_sshCiphers      = []string{"chacha20-poly1305@openssh.com", "aes128-gcm@openssh.com", "aes256-gcm@openssh.com", "aes128-ctr", "aes256-ctr"}
+	_sshMACs         = []string{"hmac-sha2-256-etm@openssh.com", "hmac-sha2-512-etm@openssh.com", "hmac-sha2-256", "hmac-sha2-512"}
+)
+
+type SSHChecker struct {
+	AllowPrivate bool
+}
+
+func (c *SSHChecker) Type() string { return "ssh" }
+
+func (c *SSHChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Result, error) {
+	var settings storage.SSHSettings
+	if len(monitor.Settings) > 0 {
+		if err := json.Unmarshal(monitor.Settings, &settings); err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: %v", err)}, nil
+		}
+	}
+
+	target := monitor.Target
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target += ":22"
+	}
+
+	timeout := time.Duration(monitor.Timeout) * time.Second
+	baseDial := sourceDial(&net.Dialer{Timeout: timeout, Control: safenet.MaybeDialControl(c.AllowPrivate)}, monitor.SourceAddr)
+
+	dialFn := baseDial
+	if socks := ProxyDialer(monitor.ProxyURL, baseDial); socks != nil {
+		dialFn = socks
+	}
+
+	start := time.Now()
+	down := func(format string, args ...any) (*Result, error) {
+		return &Result{
+			Status:       "down",
+			ResponseTime: time.Since(start).Milliseconds(),
+			Message:      fmt.Sprintf(format, args...),
+		}, nil
+	}
+
+	conn, err := dialFn(ctx, "tcp", target)
+	if err != nil {
+		return down("SSH connection failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	r := bufio.NewReader(conn)
+	banner, err := readSSHVersion(r)
+	if err != nil {
+		return down("SSH banner not received: %v", err)
+	}
+	if settings.ExpectBanner != "" && !strings.Contains(banner, settings.ExpectBanner) {
+		return down("banner %q does not contain %q", banner, settings.ExpectBanner)
+	}
+
+	if settings.BannerOnly {
+		h := sha256.Sum256([]byte(banner))
+		return &Result{
+			Status:       "up",
+			ResponseTime: time.Since(start).Milliseconds(),
+			Message:      banner,
+			Body:         banner,
+			BodyHash:     hex.EncodeToString(h[:]),
+		}, nil
+	}
+
+	if _, err := io.WriteString(conn, sshClientVersion+"\r\n"); err != nil {
+		return down("SSH version exchange failed: %v", err)
+	}
+	hostKey, err := sshKeyExchange(conn, r, banner)
+	if err != nil {
+		return down("SSH key exchange failed: %v", err)
+	}
+	elapsed := time.Since(start).Milliseconds()
+
+	fingerprint := sshFingerprint(hostKey)
+	keyType, _, _ := sshParseString(hostKey)
+	// The fingerprint is part of the body so track_changes reports key rotations.
+	body := banner + "\n" + string(keyType) + " " + fingerprint
+	h := sha256.Sum256([]byte(body))
+	result := &Result{
+		Status:       "up",
+		ResponseTime: elapsed,
+		Message:      fmt.Sprintf("%s, host key %s %s", banner, keyType, fingerprint),
+		Body:         body,
+		BodyHash:     hex.EncodeToString(h[:]),
+	}
+	if settings.ExpectedFingerprint != "" && !sshFingerprintMatches(fingerprint, settings.ExpectedFingerprint) {
+		result.Status = "down"
+		result.Message = fmt.Sprintf("host key %s %s does not match expected %s", keyType, fingerprint, settings.ExpectedFingerprint)
+	}
+	return result, nil
+}
+
+// readSSHVersion returns the server identification line, skipping any
+// preamble lines the server sends before it (RFC 4253 section 4.2).
+func readSSHVersion(r *bufio.Reader) (string, error) {
+	for range 32 {
+		line, err := r.ReadSlice('\n')
+		if err != nil {
+			if errors.Is(err, bufio.ErrBufferFull) {
+				return "", fmt.Errorf("identification line too long")
+			}
+			return "", err
+		}
+		s := strings.TrimRight(string(line), "\r\n")
+		if strings.HasPrefix(s, "SSH-") {
+			if !strings.HasPrefix(s, "SSH-2.0-") && !strings.HasPrefix(s, "SSH-1.99-") {
+				return "", fmt.Errorf("unsupported protocol version %q", s)
+			}
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("no identification line")
+}
+
+// sshKeyExchange runs an ECDH key exchange up to the server's reply and
+// verifies the host key signature over the exchange hash. It returns the
+// host key blob. The session is never completed.
+func sshKeyExchange(w io.Writer, r io.Reader, serverVersion string) ([]byte, error) {
+	clientInit := sshKexInit(_sshKexAlgos, _sshHostKeyAlgos)
+	if err := writeSSHPacket(w, clientInit); err != nil {
+		return nil, err
+	}
+	serverInit, err := readSSHMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	if serverInit[0] != sshMsgKexInit {
+		return nil, fmt.Errorf("expected KEXINIT, got message %d", serverInit[0])
+	}
+	kexAlgo, hostKeyAlgo, err := sshNegotiate(serverInit)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := ecdh.X25519()
+	if kexAlgo == "ecdh-sha2-nistp256" {
+		curve = ecdh.P256()
+	}
+	priv, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	clientPub := priv.PublicKey().Bytes()
+	if err := writeSSHPacket(w, append([]byte{sshMsgKexECDHInit}, sshString(clientPub)...)); err != nil {
+		return nil, err
+	}
+
+	reply, err := readSSHMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	if reply[0] != sshMsgKexECDHReply {
+		return nil, fmt.Errorf("expected KEX_ECDH_REPLY, got message %d", reply[0])
+	}
+	hostKey, rest, ok := sshParseString(reply[1:])
+	serverPub, rest, ok2 := sshParseString(rest)
+	sig, _, ok3 := sshParseString(rest)
+	if !ok || !ok2 || !ok3 {
+		return nil, fmt.Errorf("malformed KEX_ECDH_REPLY")
+	}
+
+	peer, err := curve.NewPublicKey(serverPub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server ephemeral key: %w", err)
+	}
+	secret, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeHash := sshExchangeHash(sha256.New(), sshClientVersion, serverVersion, clientInit, serverInit, hostKey, clientPub, serverPub, secret)
+	if err := verifySSHSignature(hostKey, hostKeyAlgo, exchangeHash, sig); err != nil {
+		return nil, err
+	}
+	return hostKey, nil
+}
+
+func sshKexInit(kexAlgos, hostKeyAlgos []string) []byte {
+	b := make([]byte, 17, 512)
+	b[0] = sshMsgKexInit
+	rand.Read(b[1:17])
+	for _, list := range [][]string{kexAlgos, hostKeyAlgos, _sshCiphers, _sshCiphers, _sshMACs, _sshMACs, {"none"}, {"none"}, nil, nil} {
+		b = append(b, sshString([]byte(strings.Join(list, ",")))...)
+	}
+	// first_kex_packet_follows and the reserved uint32.
+	return append(b, 0, 0, 0, 0, 0)
+}
+
+// sshNegotiate picks the first client kex and host key algorithm the server
+// also lists in its KEXINIT.
+func sshNegotiate(serverInit []byte) (kexAlgo, hostKeyAlgo string, err error) {
+	if len(serverInit) < 17 {
+		return "", "", fmt.Errorf("malformed KEXINIT")
+	}
+	kexList, rest, ok := sshParseString(serverInit[17:])
+	hostKeyList, _, ok2 := sshParseString(rest)
+	if !ok || !ok2 {
+		return "", "", fmt.Errorf("malformed KEXINIT")
+	}
+	if kexAlgo = sshFirstCommon(_sshKexAlgos, string(kexList)); kexAlgo == "" {
+		return "", "", fmt.Errorf("no common key exchange algorithm (server offers %s)", kexList)
+	}
+	if hostKeyAlgo = sshFirstCommon(_sshHostKeyAlgos, string(hostKeyList)); hostKeyAlgo == "" {
+		return "", "", fmt.Errorf("no common host key algorithm (server offers %s)", hostKeyList)
+	}
+	return kexAlgo, hostKeyAlgo, nil
+}
+
+func sshFirstCommon(client []string, server string) string {
+	offered := strings.Split(server, ",")
+	for _, c := range client {
+		for _, s := range offered {
+			if c == s {
+				return c
+			}
+		}
+	}
+	return ""
+}
+
+func sshExchangeHash(h hash.Hash, clientVersion, serverVersion string, clientInit, serverInit, hostKey, clientPub, serverPub, secret []byte) []byte {
+	for _, b := range [][]byte{[]byte(clientVersion), []byte(serverVersion), clientInit, serverInit, hostKey, clientPub, serverPub} {
+		h.Write(sshString(b))
+	}
+	h.Write(sshMpint(secret))
+	return h.Sum(nil)
+}
+
+// verifySSHSignature checks the server's signature over the exchange hash
+// with the host key it presented.
+func verifySSHSignature(hostKey []byte, algo string, data, sigBlob []byte) error {
+	sigAlgo, rest, ok := sshParseString(sigBlob)
+	sig, _, ok2 := sshParseString(rest)
+	if !ok || !ok2 {
+		return fmt.Errorf("malformed host key signature")
+	}
+	if string(sigAlgo) != algo {
+		return fmt.Errorf("signature algorithm %s does not match negotiated %s", sigAlgo, algo)
+	}
+	keyType, key, ok := sshParseString(hostKey)
+	if !ok {
+		return fmt.Errorf("malformed host key")
+	}
+
+	invalid := fmt.Errorf("host key signature does not verify")
+	switch algo {
+	case "ssh-ed25519":
+		pub, _, ok := sshParseString(key)
+		if string(keyType) != algo || !ok || len(pub) != ed25519.PublicKeySize {
+			return fmt.Errorf("malformed %s host key", algo)
+		}
+		if !ed25519.Verify(ed25519.PublicKey(pub), data, sig) {
+			return invalid
+		}
+	case "ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521":
+		curve, h := elliptic.P256(), crypto.SHA256
+		switch algo {
+		case "ecdsa-sha2-nistp384":
+			curve, h = elliptic.P384(), crypto.SHA384
+		case "ecdsa-sha2-nistp521":
+			curve, h = elliptic.P521(), crypto.SHA512
+		}
+		_, rest, ok := sshParseString(key)
+		point, _, ok2 := sshParseString(rest)
+		if string(keyType) != algo || !ok || !ok2 {
+			return fmt.Errorf("malformed %s host key", algo)
+		}
+		x, y := elliptic.Unmarshal(curve, point)
+		if x == nil {
+			return fmt.Errorf("invalid %s host key point", algo)
+		}
+		rb, rest, ok := sshParseString(sig)
+		sb, _, ok2 := sshParseString(rest)
+		if !ok || !ok2 {
+			return fmt.Errorf("malformed %s signature", algo)
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if !ecdsa.Verify(pub, sshDigest(h, data), new(big.Int).SetBytes(rb), new(big.Int).SetBytes(sb)) {
+			return invalid
+		}
+	case "rsa-sha2-256", "rsa-sha2-512":
+		eb, rest, ok := sshParseString(key)
+		nb, _, ok2 := sshParseString(rest)
+		if string(keyType) != "ssh-rsa" || !ok || !ok2 {
+			return fmt.Errorf("malformed ssh-rsa host key")
+		}
+		e := new(big.Int).SetBytes(eb)
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return fmt.Errorf("invalid ssh-rsa exponent")
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(nb), E: int(e.Int64())}
+		h := crypto.SHA256
+		if algo == "rsa-sha2-512" {
+			h = crypto.SHA512
+		}
+		if err := rsa.VerifyPKCS1v15(pub, h, sshDigest(h, data), sig); err != nil {
+			return invalid
+		}
+	default:
+		return fmt.Errorf("unsupported host key algorithm %s", algo)
+	}
+	return nil
+}
+
+func sshDigest(h crypto.Hash, data []byte) []byte {
+	switch h {
+	case crypto.SHA384:
+		d := sha512.Sum384(data)
+		return d[:]
+	case crypto.SHA512:
+		d := sha512.Sum512(data)
+		return d[:]
+	default:
+		d := sha256.Sum256(data)
+		return d[:]
+	}
+}
+
+// sshFingerprint formats a host key the way ssh-keygen -l does.
+func sshFingerprint(hostKey []byte) string {
+	sum := sha256.Sum256(hostKey)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// sshFingerprintMatches accepts the expected fingerprint with or without the
+// SHA256: prefix and base64 padding.
+func sshFingerprintMatches(fingerprint, expected string) bool {
+	expected = strings.TrimRight(strings.TrimPrefix(strings.TrimSpace(expected), "SHA256:"), "=")
+	return strings.TrimPrefix(fingerprint, "SHA256:") == expected
+}
+
+// writeSSHPacket writes an unencrypted binary packet (RFC 4253 section 6).
+func writeSSHPacket(w io.Writer, payload []byte) error {
+	padding := 8 - (5+len(payload))%8
+	if padding < 4 {
+		padding += 8
+	}
+	buf := make([]byte, 5+len(payload)+padding)
+	binary.BigEndian.PutUint32(buf, uint32(1+len(payload)+padding))
+	buf[4] = byte(padding)
+	copy(buf[5:], payload)
+	rand.Read(buf[5+len(payload):])
+	_, err := w.Write(buf)
+	return err
+}
+
+// readSSHMessage reads the next packet, skipping ignore, debug and
+// unimplemented messages and turning a disconnect into an error.
+func readSSHMessage(r io.Reader) ([]byte, error) {
+	for {
+		var hdr [5]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, err
+		}
+		length := binary.BigEndian.Uint32(hdr[:4])
+		padding := uint32(hdr[4])
+		if length < 2 || length > maxSSHPacket || padding >= length {
+			return nil, fmt.Errorf("invalid packet length %d", length)
+		}
+		body := make([]byte, length-1)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, err
+		}
+		payload := body[:len(body)-int(padding)]
+		if len(payload) == 0 {
+			return nil, fmt.Errorf("empty packet")
+		}
+		switch payload[0] {
+		case sshMsgIgnore, sshMsgDebug, sshMsgUnimplemented:
+			continue
+		case sshMsgDisconnect:
+			if len(payload) >= 5 {
+				if reason, _, ok := sshParseString(payload[5:]); ok {
+					return nil, fmt.Errorf("server disconnected: %s", reason)
+				}
+			}
+			return nil, fmt.Errorf("server disconnected")
+		}
+		return payload, nil
+	}
+}
+
+func sshString(b []byte) []byte {
+	out := make([]byte, 4+len(b))
+	binary.BigEndian.PutUint32(out, uint32(len(b)))
+	copy(out[4:], b)
+	return out
+}
+
+// sshMpint encodes an unsigned big-endian integer as an SSH mpint.
+func sshMpint(b []byte) []byte {
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return sshString(b)
+}
+
+func sshParseString(b []byte) (s, rest []byte, ok bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
@@ -0,0 +1,227 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+const fakeSSHBanner = "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13"
+
+type fakeSSHKey struct {
+	algo string
+	blob []byte
+	sign func(h []byte) []byte
+}
+
+func ed25519HostKey(t *testing.T) fakeSSHKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fakeSSHKey{
+		algo: "ssh-ed25519",
+		blob: append(sshString([]byte("ssh-ed25519")), sshString(pub)...),
+		sign: func(h []byte) []byte { return ed25519.Sign(priv, h) },
+	}
+}
+
+func ecdsaHostKey(t *testing.T) fakeSSHKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := key.PublicKey.ECDH()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := sshString([]byte("ecdsa-sha2-nistp256"))
+	blob = append(blob, sshString([]byte("nistp256"))...)
+	blob = append(blob, sshString(pub.Bytes())...)
+	return fakeSSHKey{
+		algo: "ecdsa-sha2-nistp256",
+		blob: blob,
+		sign: func(h []byte) []byte {
+			d := sha256.Sum256(h)
+			r, s, err := ecdsa.Sign(rand.Reader, key, d[:])
+			if err != nil {
+				t.Error(err)
+			}
+			return append(sshMpint(r.Bytes()), sshMpint(s.Bytes())...)
+		},
+	}
+}
+
+func rsaHostKey(t *testing.T) fakeSSHKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := sshString([]byte("ssh-rsa"))
+	blob = append(blob, sshMpint([]byte{byte(key.E >> 16), byte(key.E >> 8), byte(key.E)})...)
+	blob = append(blob, sshMpint(key.N.Bytes())...)
+	return fakeSSHKey{
+		algo: "rsa-sha2-512",
+		blob: blob,
+		sign: func(h []byte) []byte {
+			d := sha512.Sum512(h)
+			sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA512, d[:])
+			if err != nil {
+				t.Error(err)
+			}
+			return sig
+		},
+	}
+}
+
+// fakeSSH runs the server side of the key exchange and stops after its
+// KEX_ECDH_REPLY. With tamper set, the host key signature is corrupted.
+func fakeSSH(t *testing.T, key fakeSSHKey, kexAlgo string, tamper bool) string {
+	t.Helper()
+	return tcpServer(t, func(c net.Conn) {
+		c.SetDeadline(time.Now().Add(5 * time.Second))
+		io.WriteString(c, "Authorized access only\r\n"+fakeSSHBanner+"\r\n")
+
+		r := bufio.NewReader(c)
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		clientVersion := strings.TrimRight(line, "\r\n")
+
+		serverInit := sshKexInit([]string{kexAlgo}, []string{key.algo})
+		writeSSHPacket(c, serverInit)
+		clientInit, err := readSSHMessage(r)
+		if err != nil {
+			return
+		}
+		msg, err := readSSHMessage(r)
+		if err != nil || msg[0] != sshMsgKexECDHInit {
+			return
+		}
+		clientPub, _, _ := sshParseString(msg[1:])
+
+		curve := ecdh.X25519()
+		if kexAlgo == "ecdh-sha2-nistp256" {
+			curve = ecdh.P256()
+		}
+		priv, _ := curve.GenerateKey(rand.Reader)
+		peer, err := curve.NewPublicKey(clientPub)
+		if err != nil {
+			return
+		}
+		secret, _ := priv.ECDH(peer)
+		serverPub := priv.PublicKey().Bytes()
+
+		h := sshExchangeHash(sha256.New(), clientVersion, fakeSSHBanner, clientInit, serverInit, key.blob, clientPub, serverPub, secret)
+		sig := key.sign(h)
+		if tamper {
+			sig[len(sig)-1] ^= 0xff
+		}
+		reply := []byte{sshMsgKexECDHReply}
+		reply = append(reply, sshString(key.blob)...)
+		reply = append(reply, sshString(serverPub)...)
+		reply = append(reply, sshString(append(sshString([]byte(key.algo)), sshString(sig)...))...)
+		writeSSHPacket(c, reply)
+		io.Copy(io.Discard, r)
+	})
+}
+
+func TestSSHChecker(t *testing.T) {
+	edKey := ed25519HostKey(t)
+	ed := fakeSSH(t, edKey, "curve25519-sha256", false)
+	fingerprint := sshFingerprint(edKey.blob)
+
+	tests := []struct {
+		name       string
+		addr       string
+		settings   storage.SSHSettings
+		wantStatus string
+		wantMsg    string
+	}{
+		{"ed25519", ed, storage.SSHSettings{}, "up", "host key ssh-ed25519 " + fingerprint},
+		{"expected fingerprint", ed, storage.SSHSettings{ExpectedFingerprint: fingerprint, ExpectBanner: "OpenSSH_9"}, "up", fakeSSHBanner},
+		{"fingerprint without prefix", ed, storage.SSHSettings{ExpectedFingerprint: strings.TrimPrefix(fingerprint, "SHA256:") + "="}, "up", fingerprint},
+		{"fingerprint mismatch", ed, storage.SSHSettings{ExpectedFingerprint: "SHA256:" + strings.Repeat("A", 43)}, "down", "does not match expected"},
+		{"banner mismatch", ed, storage.SSHSettings{ExpectBanner: "dropbear"}, "down", "does not contain"},
+		{"banner only", ed, storage.SSHSettings{BannerOnly: true}, "up", fakeSSHBanner},
+		{"ecdsa over nistp256 kex", fakeSSH(t, ecdsaHostKey(t), "ecdh-sha2-nistp256", false), storage.SSHSettings{}, "up", "ecdsa-sha2-nistp256 SHA256:"},
+		{"rsa", fakeSSH(t, rsaHostKey(t), "curve25519-sha256@libssh.org", false), storage.SSHSettings{}, "up", "ssh-rsa SHA256:"},
+		{"bad signature", fakeSSH(t, ed25519HostKey(t), "curve25519-sha256", true), storage.SSHSettings{}, "down", "signature does not verify"},
+		{"unsupported kex", fakeSSH(t, edKey, "diffie-hellman-group14-sha256", false), storage.SSHSettings{}, "down", "no common key exchange"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, _ := json.Marshal(tt.settings)
+			c := &SSHChecker{AllowPrivate: true}
+			result, err := c.Check(context.Background(), &storage.Monitor{
+				Type: "ssh", Target: tt.addr, Timeout: 5, Settings: settings,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q (%s)", result.Status, tt.wantStatus, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want substring %q", result.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestSSHCheckerBodyTracksHostKey(t *testing.T) {
+	c := &SSHChecker{AllowPrivate: true}
+	check := func(addr string) *Result {
+		t.Helper()
+		result, err := c.Check(context.Background(), &storage.Monitor{Type: "ssh", Target: addr, Timeout: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	key := ed25519HostKey(t)
+	first := check(fakeSSH(t, key, "curve25519-sha256", false))
+	same := check(fakeSSH(t, key, "curve25519-sha256", false))
+	rotated := check(fakeSSH(t, ed25519HostKey(t), "curve25519-sha256", false))
+
+	if first.BodyHash == "" || first.BodyHash != same.BodyHash {
+		t.Errorf("same host key should hash the same: %q vs %q", first.BodyHash, same.BodyHash)
+	}
+	if first.BodyHash == rotated.BodyHash {
+		t.Error("rotated host key should change the body hash")
+	}
+}
+
+func TestSSHCheckerNotSSH(t *testing.T) {
+	addr := tcpServer(t, func(c net.Conn) {
+		io.WriteString(c, "HTTP/1.1 400 Bad Request\r\n\r\n")
+	})
+
+	c := &SSHChecker{AllowPrivate: true}
+	result, _ := c.Check(context.Background(), &storage.Monitor{Type: "ssh", Target: addr, Timeout: 2})
+	if result.Status != "down" || !strings.Contains(result.Message, "banner not received") {
+		t.Errorf("got %q: %s", result.Status, result.Message)
+	}
+}
@@ -3,8 +3,11 @@ package checker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -12,6 +15,8 @@ import (
 	"github.com/y0f/asura/internal/storage"
 )
 
+const maxBannerRead = 4096
+
 type TCPChecker struct {
 	AllowPrivate bool
 }
@@ -27,7 +32,7 @@ func (c *TCPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Resu
 	}
 
 	timeout := time.Duration(monitor.Timeout) * time.Second
-	baseDial := (&net.Dialer{Timeout: timeout, Control: safenet.MaybeDialControl(c.AllowPrivate)}).DialContext
+	baseDial := sourceDial(&net.Dialer{Timeout: connectTimeout(monitor), Control: safenet.MaybeDialControl(c.AllowPrivate)}, monitor.SourceAddr)
 
 	dialFn := baseDial
 	if socks := ProxyDialer(monitor.ProxyURL, baseDial); socks != nil {
@@ -47,6 +52,14 @@ func (c *TCPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Resu
 	}
 	defer conn.Close()
 
+	if settings.Mode == "connect" {
+		return &Result{
+			Status:       "up",
+			ResponseTime: elapsed,
+			Message:      fmt.Sprintf("connected in %dms", elapsed),
+		}, nil
+	}
+
 	conn.SetDeadline(time.Now().Add(timeout))
 
 	if settings.SendData != "" {
@@ -60,29 +73,82 @@ func (c *TCPChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Resu
 		}
 	}
 
-	if settings.ExpectData != "" {
-		buf := make([]byte, 4096)
-		n, err := conn.Read(buf)
+	// expect_regex takes precedence over expect_data.
+	var match func(string) bool
+	var mismatch string
+	if settings.ExpectRegex != "" {
+		re, err := regexp.Compile(settings.ExpectRegex)
 		if err != nil {
-			return &Result{
-				Status:       "down",
-				ResponseTime: elapsed,
-				Message:      fmt.Sprintf("read failed: %v", err),
-			}, nil
+			return &Result{Status: "down", ResponseTime: elapsed, Message: fmt.Sprintf("invalid expect_regex: %v", err)}, nil
 		}
-		received := string(buf[:n])
-		if !strings.Contains(received, settings.ExpectData) {
-			return &Result{
-				Status:       "down",
-				ResponseTime: elapsed,
-				Message:      "expected data not found in response",
-			}, nil
+		match = re.MatchString
+		mismatch = fmt.Sprintf("response did not match %q", settings.ExpectRegex)
+	} else if settings.ExpectData != "" {
+		match = func(s string) bool { return strings.Contains(s, settings.ExpectData) }
+		mismatch = "expected data not found in response"
+	}
+
+	if match == nil && settings.Mode != "banner" {
+		return &Result{
+			Status:       "up",
+			ResponseTime: elapsed,
+			Message:      "connection successful",
+		}, nil
+	}
+
+	bannerTimeout := timeout
+	if settings.BannerTimeoutMs > 0 {
+		bannerTimeout = time.Duration(settings.BannerTimeoutMs) * time.Millisecond
+	}
+	received, ttfb, err := readBanner(conn, bannerTimeout, match)
+	if err != nil && received == "" {
+		msg := fmt.Sprintf("connected in %dms, read failed: %v", elapsed, err)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			msg = fmt.Sprintf("connected in %dms but no data received within %dms", elapsed, bannerTimeout.Milliseconds())
 		}
+		return &Result{Status: "down", ResponseTime: elapsed, Message: msg}, nil
+	}
+	if match != nil && !match(received) {
+		return &Result{
+			Status:       "down",
+			ResponseTime: elapsed,
+			Message:      fmt.Sprintf("connected in %dms, banner received in %dms, %s", elapsed, ttfb.Milliseconds(), mismatch),
+			Body:         received,
+		}, nil
 	}
 
 	return &Result{
 		Status:       "up",
 		ResponseTime: elapsed,
-		Message:      "connection successful",
+		Message:      fmt.Sprintf("connected in %dms, banner received in %dms", elapsed, ttfb.Milliseconds()),
+		Body:         received,
 	}, nil
 }
+
+// readBanner reads from conn until the data read satisfies match (or, with a
+// nil match, until the first bytes arrive), maxBannerRead bytes are read, or
+// timeout passes. It returns the data read and the time until the first byte.
+func readBanner(conn net.Conn, timeout time.Duration, match func(string) bool) (string, time.Duration, error) {
+	start := time.Now()
+	conn.SetReadDeadline(start.Add(timeout))
+
+	var (
+		buf  = make([]byte, maxBannerRead)
+		n    int
+		ttfb time.Duration
+	)
+	for n < len(buf) {
+		m, err := conn.Read(buf[n:])
+		if m > 0 && n == 0 {
+			ttfb = time.Since(start)
+		}
+		n += m
+		if n > 0 && (match == nil || match(string(buf[:n]))) {
+			return string(buf[:n]), ttfb, nil
+		}
+		if err != nil {
+			return string(buf[:n]), ttfb, err
+		}
+	}
+	return string(buf[:n]), ttfb, nil
+}
@@ -0,0 +1,113 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// authFailDelay is how long fake servers wait before refusing credentials,
+// so tests can check that the time spent is reported.
+const authFailDelay = 20 * time.Millisecond
+
+func tcpServer(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestTCPCheckerModes(t *testing.T) {
+	banner := tcpServer(t, func(c net.Conn) {
+		time.Sleep(20 * time.Millisecond)
+		c.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+		time.Sleep(200 * time.Millisecond)
+	})
+	silent := tcpServer(t, func(c net.Conn) {
+		time.Sleep(500 * time.Millisecond)
+	})
+
+	tests := []struct {
+		name       string
+		target     string
+		settings   storage.TCPSettings
+		wantStatus string
+		wantMsg    string
+	}{
+		{"connect only", silent, storage.TCPSettings{Mode: "connect"}, "up", "connected in"},
+		{"banner any data", banner, storage.TCPSettings{Mode: "banner"}, "up", "banner received in"},
+		{"banner expect match", banner, storage.TCPSettings{Mode: "banner", ExpectData: "SSH-"}, "up", "banner received in"},
+		{"expect mismatch", banner, storage.TCPSettings{ExpectData: "SMTP"}, "down", "expected data not found"},
+		{"regex match", banner, storage.TCPSettings{Mode: "banner", ExpectRegex: `^SSH-2\.0-OpenSSH_\d+\.\d+`}, "up", "banner received in"},
+		{"regex over expect data", banner, storage.TCPSettings{ExpectData: "SMTP", ExpectRegex: `OpenSSH`}, "up", "banner received in"},
+		{"regex mismatch", banner, storage.TCPSettings{ExpectRegex: `^220 `}, "down", `response did not match "^220 "`},
+		{"silent server", silent, storage.TCPSettings{Mode: "banner", BannerTimeoutMs: 100}, "down", "no data received within 100ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, _ := json.Marshal(tt.settings)
+			c := &TCPChecker{AllowPrivate: true}
+			result, err := c.Check(context.Background(), &storage.Monitor{
+				Type: "tcp", Target: tt.target, Timeout: 2, Settings: settings,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q (%s)", result.Status, tt.wantStatus, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want substring %q", result.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestTCPCheckerSourceAddr(t *testing.T) {
+	remote := make(chan string, 1)
+	addr := tcpServer(t, func(c net.Conn) {
+		remote <- c.RemoteAddr().(*net.TCPAddr).IP.String()
+	})
+	c := &TCPChecker{AllowPrivate: true}
+
+	res, err := c.Check(context.Background(), &storage.Monitor{Type: "tcp", Target: addr, Timeout: 2, SourceAddr: "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != "up" {
+		t.Fatalf("expected up, got %s: %s", res.Status, res.Message)
+	}
+	if got := <-remote; got != "127.0.0.1" {
+		t.Fatalf("expected connection from 127.0.0.1, got %s", got)
+	}
+
+	// An address this host does not own cannot be bound.
+	res, err = c.Check(context.Background(), &storage.Monitor{Type: "tcp", Target: addr, Timeout: 2, SourceAddr: "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != "down" {
+		t.Fatalf("expected down when the source address cannot be bound, got %s", res.Status)
+	}
+}
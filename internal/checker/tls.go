@@ -4,10 +4,12 @@ import (
 	"context"
 	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/y0f/asura/internal/safenet"
@@ -16,6 +18,8 @@ import (
 
 type TLSChecker struct {
 	AllowPrivate bool
+
+	roots *x509.CertPool // verification roots, nil for the system roots
 }
 
 func (c *TLSChecker) Type() string { return "tls" }
@@ -41,10 +45,10 @@ func (c *TLSChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Resu
 
 	host, _, _ := net.SplitHostPort(target)
 
-	baseDial := (&net.Dialer{
-		Timeout: time.Duration(monitor.Timeout) * time.Second,
+	baseDial := sourceDial(&net.Dialer{
+		Timeout: connectTimeout(monitor),
 		Control: safenet.MaybeDialControl(c.AllowPrivate),
-	}).DialContext
+	}, monitor.SourceAddr)
 
 	dialFn := baseDial
 	if socks := ProxyDialer(monitor.ProxyURL, baseDial); socks != nil {
@@ -61,7 +65,9 @@ func (c *TLSChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Resu
 		}, nil
 	}
 
-	tlsConn := tls.Client(rawConn, &tls.Config{ServerName: host})
+	// The chain is verified after the handshake so an untrusted certificate
+	// still reports its expiry and details.
+	tlsConn := tls.Client(rawConn, &tls.Config{ServerName: host, InsecureSkipVerify: true})
 	if err := tlsConn.HandshakeContext(ctx); err != nil {
 		rawConn.Close()
 		return &Result{
@@ -95,16 +101,105 @@ func (c *TLSChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Resu
 		ResponseTime:    elapsed,
 		CertExpiry:      &expiryUnix,
 		CertFingerprint: fingerprint,
+		TLS:             tlsDetails(state),
 		Message:         fmt.Sprintf("cert expires in %d days (%s)", daysUntilExpiry, expiry.Format("2006-01-02")),
 	}
 
-	if daysUntilExpiry <= 0 {
+	if err := c.verifyChain(state.PeerCertificates, host, settings.RequireChainValid); err != nil {
+		result.Status = "down"
+		result.Message = fmt.Sprintf("certificate verification failed: %v", err)
+	} else if daysUntilExpiry <= 0 {
 		result.Status = "down"
 		result.Message = fmt.Sprintf("certificate expired on %s", expiry.Format("2006-01-02"))
+	} else if msg := certMismatch(cert, settings); msg != "" {
+		result.Status = "degraded"
+		result.Message = msg
 	} else if daysUntilExpiry <= warnDays {
 		result.Status = "degraded"
 		result.Message = fmt.Sprintf("cert expires in %d days (warning threshold: %d)", daysUntilExpiry, warnDays)
 	}
+	result.Message += "; chain: " + chainSummary(state.PeerCertificates)
 
 	return result, nil
 }
+
+// verifyChain verifies the presented certificates for host against the
+// checker's roots. With strict, each certificate must also be signed by the
+// next one presented and be within its validity period, which catches
+// misordered or stale intermediates that clients may work around.
+func (c *TLSChecker) verifyChain(certs []*x509.Certificate, host string, strict bool) error {
+	intermediates := x509.NewCertPool()
+	for _, ic := range certs[1:] {
+		intermediates.AddCert(ic)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         c.roots,
+		Intermediates: intermediates,
+	}); err != nil {
+		return err
+	}
+	if !strict {
+		return nil
+	}
+	now := time.Now()
+	for i, cert := range certs {
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return fmt.Errorf("%s is outside its validity period (%s to %s)", certName(cert),
+				cert.NotBefore.Format("2006-01-02"), cert.NotAfter.Format("2006-01-02"))
+		}
+		if i+1 < len(certs) {
+			if err := cert.CheckSignatureFrom(certs[i+1]); err != nil {
+				return fmt.Errorf("%s is not signed by the next certificate in the chain, %s", certName(cert), certName(certs[i+1]))
+			}
+		}
+	}
+	return nil
+}
+
+// certMismatch describes how the leaf differs from the expected issuer and
+// common name, or returns "" when it matches.
+func certMismatch(cert *x509.Certificate, settings storage.TLSSettings) string {
+	if want := settings.ExpectedIssuer; want != "" &&
+		!strings.EqualFold(cert.Issuer.CommonName, want) && !strings.EqualFold(cert.Issuer.String(), want) {
+		return fmt.Sprintf("certificate issuer %q, expected %q", cert.Issuer.String(), want)
+	}
+	if want := settings.ExpectedCN; want != "" && !strings.EqualFold(cert.Subject.CommonName, want) {
+		return fmt.Sprintf("certificate common name %q, expected %q", cert.Subject.CommonName, want)
+	}
+	return ""
+}
+
+// chainSummary lists the presented certificates from the leaf up, e.g.
+// "example.com -> R11".
+func chainSummary(certs []*x509.Certificate) string {
+	names := make([]string, len(certs))
+	for i, cert := range certs {
+		names[i] = certName(cert)
+	}
+	return strings.Join(names, " -> ")
+}
+
+func certName(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
+
+func tlsDetails(state tls.ConnectionState) *TLSDetails {
+	d := &TLSDetails{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+	}
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		d.Subject = cert.Subject.String()
+		d.Issuer = cert.Issuer.String()
+		d.DNSNames = cert.DNSNames
+		d.NotBefore = cert.NotBefore
+		d.NotAfter = cert.NotAfter
+	}
+	return d
+}
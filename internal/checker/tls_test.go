@@ -0,0 +1,129 @@
+package checker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issueCert signs a certificate named cn with parent, or self-signs it when
+// parent is nil.
+func issueCert(t *testing.T, cn string, parent *testCert, ca bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if ca {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		tmpl.DNSNames = []string{"localhost"}
+		tmpl.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key}
+}
+
+// tlsServer serves the given chain, leaf first, and returns its address.
+func tlsServer(t *testing.T, chain ...*testCert) string {
+	t.Helper()
+	cert := tls.Certificate{PrivateKey: chain[0].key}
+	for _, c := range chain {
+		cert.Certificate = append(cert.Certificate, c.cert.Raw)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+	return tcpServer(t, func(c net.Conn) {
+		tls.Server(c, cfg).Handshake()
+	})
+}
+
+func TestTLSChecker(t *testing.T) {
+	root := issueCert(t, "Test Root", nil, true)
+	inter := issueCert(t, "Test Intermediate", root, true)
+	leaf := issueCert(t, "localhost", inter, false)
+	stray := issueCert(t, "Stray CA", nil, true)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+
+	good := tlsServer(t, leaf, inter)
+	misordered := tlsServer(t, leaf, stray, inter)
+
+	tests := []struct {
+		name     string
+		addr     string
+		roots    *x509.CertPool
+		settings storage.TLSSettings
+		want     string
+		contains string
+	}{
+		{"valid chain", good, roots, storage.TLSSettings{}, "up", "chain: localhost -> Test Intermediate"},
+		{"untrusted root", good, x509.NewCertPool(), storage.TLSSettings{}, "down", "certificate verification failed"},
+		{"issuer common name", good, roots, storage.TLSSettings{ExpectedIssuer: "test intermediate", ExpectedCN: "localhost"}, "up", "cert expires in"},
+		{"issuer distinguished name", good, roots, storage.TLSSettings{ExpectedIssuer: "CN=Test Intermediate"}, "up", "cert expires in"},
+		{"issuer mismatch", good, roots, storage.TLSSettings{ExpectedIssuer: "Other CA"}, "degraded", `expected "Other CA"`},
+		{"common name mismatch", good, roots, storage.TLSSettings{ExpectedCN: "api.example.com"}, "degraded", "certificate common name"},
+		{"misordered chain", misordered, roots, storage.TLSSettings{}, "up", "localhost -> Stray CA -> Test Intermediate"},
+		{"misordered chain required valid", misordered, roots, storage.TLSSettings{RequireChainValid: true}, "down", "not signed by the next certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, _ := json.Marshal(tt.settings)
+			mon := &storage.Monitor{Type: "tls", Target: tt.addr, Timeout: 5, Settings: settings}
+			c := &TLSChecker{AllowPrivate: true, roots: tt.roots}
+			res, err := c.Check(context.Background(), mon)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != tt.want || !strings.Contains(res.Message, tt.contains) {
+				t.Fatalf("got %s %q, want %s containing %q", res.Status, res.Message, tt.want, tt.contains)
+			}
+			if res.CertExpiry == nil || *res.CertExpiry != leaf.cert.NotAfter.Unix() {
+				t.Errorf("cert expiry = %v, want the leaf's", res.CertExpiry)
+			}
+			if res.CertFingerprint == "" {
+				t.Error("expected a leaf fingerprint")
+			}
+		})
+	}
+}
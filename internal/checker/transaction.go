@@ -0,0 +1,210 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/assertion"
+	"github.com/y0f/asura/internal/safenet"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// transactionVar matches a {{name}} reference to a captured variable.
+var transactionVar = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TransactionChecker runs a sequence of HTTP requests sharing cookies and
+// captured variables, and fails on the first step that does not pass.
+type TransactionChecker struct {
+	AllowPrivate bool
+}
+
+func (c *TransactionChecker) Type() string { return "transaction" }
+
+func (c *TransactionChecker) Check(ctx context.Context, monitor *storage.Monitor) (*Result, error) {
+	var settings storage.TransactionSettings
+	if len(monitor.Settings) > 0 {
+		if err := json.Unmarshal(monitor.Settings, &settings); err != nil {
+			return &Result{Status: "down", Message: fmt.Sprintf("invalid settings: %v", err)}, nil
+		}
+	}
+	if len(settings.Steps) == 0 {
+		return &Result{Status: "down", Message: "invalid settings: no steps"}, nil
+	}
+	base, err := url.Parse(monitor.Target)
+	if err != nil {
+		return &Result{Status: "down", Message: fmt.Sprintf("invalid target: %v", err)}, nil
+	}
+
+	timeout := time.Duration(monitor.Timeout) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	baseDial := sourceDial(&net.Dialer{
+		Timeout: connectTimeout(monitor),
+		Control: safenet.MaybeDialControl(c.AllowPrivate),
+	}, monitor.SourceAddr)
+	transport := &http.Transport{
+		DialContext:       baseDial,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: settings.SkipTLSVerify},
+		DisableKeepAlives: true,
+	}
+	applyHTTPProxy(transport, monitor.ProxyURL, baseDial)
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Transport:     transport,
+		Jar:           jar,
+		CheckRedirect: redirectPolicy(10),
+	}
+
+	vars := map[string]string{}
+	start := time.Now()
+	var last *Result
+	for i, step := range settings.Steps {
+		res, err := runTransactionStep(ctx, client, base, step, vars)
+		if res != nil {
+			last = res
+		}
+		if err != nil || res.Status != "up" {
+			failed := &Result{Status: "down"}
+			if res != nil {
+				failed = res
+			}
+			msg := failed.Message
+			if err != nil {
+				msg = err.Error()
+			}
+			failed.ResponseTime = time.Since(start).Milliseconds()
+			failed.Message = fmt.Sprintf("step %d%s: %s", i+1, transactionStepName(step), msg)
+			return failed, nil
+		}
+	}
+
+	last.ResponseTime = time.Since(start).Milliseconds()
+	last.Message = fmt.Sprintf("transaction completed: %d steps", len(settings.Steps))
+	return last, nil
+}
+
+func transactionStepName(step storage.TransactionStep) string {
+	if step.Name == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", step.Name)
+}
+
+// runTransactionStep sends one step and checks its status and assertions.
+// A failing check is returned as a down or degraded result; err is for
+// failures that leave no response to report. Extracted values are added to
+// vars once the step passes.
+func runTransactionStep(ctx context.Context, client *http.Client, base *url.URL, step storage.TransactionStep, vars map[string]string) (*Result, error) {
+	rawURL, err := expandTransactionVars(step.URL, vars)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+	body, err := expandTransactionVars(step.Body, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	method := step.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, base.ResolveReference(ref).String(), bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %v", err)
+	}
+	for k, v := range step.Headers {
+		v, err := expandTransactionVars(v, vars)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(k, v)
+	}
+
+	stepStart := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	settings := storage.HTTPSettings{ExpectedStatus: step.ExpectedStatus}
+	if _, err := settings.ExpectedStatus.Ranges(); err != nil {
+		return nil, fmt.Errorf("invalid expected_status: %v", err)
+	}
+	result, err := buildHTTPResult(resp, stepStart, settings, false)
+	if err != nil {
+		return nil, err
+	}
+	if result.Status != "up" {
+		return result, nil
+	}
+
+	if len(step.Assertions) > 0 {
+		ar := assertion.Evaluate(step.Assertions, result.StatusCode, result.Body, result.BodySize,
+			result.Headers, result.ResponseTime, result.CertExpiry, nil)
+		if !ar.Pass {
+			result.Status = "down"
+			if ar.Degraded {
+				result.Status = "degraded"
+			}
+			result.Message = ar.Message
+			return result, nil
+		}
+	}
+
+	for _, ex := range step.Extract {
+		var val string
+		switch ex.Source {
+		case storage.ExtractHeader:
+			val = resp.Header.Get(ex.Path)
+			if val == "" {
+				return nil, fmt.Errorf("extract %s: header %s not set", ex.Var, ex.Path)
+			}
+		default:
+			val, err = assertion.JSONPathValue(result.Body, ex.Path)
+			if err != nil {
+				return nil, fmt.Errorf("extract %s: %v", ex.Var, err)
+			}
+		}
+		vars[ex.Var] = val
+	}
+	return result, nil
+}
+
+// expandTransactionVars replaces {{name}} references in s with captured
+// values, failing on a name no earlier step captured.
+func expandTransactionVars(s string, vars map[string]string) (string, error) {
+	var missing string
+	out := transactionVar.ReplaceAllStringFunc(s, func(ref string) string {
+		name := transactionVar.FindStringSubmatch(ref)[1]
+		v, ok := vars[name]
+		if !ok && missing == "" {
+			missing = name
+		}
+		return v
+	})
+	if missing != "" {
+		return "", fmt.Errorf("undefined variable %s", missing)
+	}
+	return out, nil
+}
@@ -0,0 +1,141 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// transactionServer issues a session cookie and token on POST /login and
+// serves /profile only to requests carrying both.
+func transactionServer(t *testing.T) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ User string }
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.User != "monitor" {
+			http.Error(w, "bad user", http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+		w.Header().Set("X-Request-Id", "req-7")
+		w.Write([]byte(`{"data":{"token":"abc"}}`))
+	})
+	mux.HandleFunc("GET /profile/{req}", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session"); err != nil || c.Value != "s1" {
+			http.Error(w, "no session", http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			http.Error(w, "no token", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"name":"monitor","request":"` + r.PathValue("req") + `"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestTransactionChecker(t *testing.T) {
+	login := storage.TransactionStep{
+		Name: "login", Method: "POST", URL: "/login", Body: `{"user":"monitor"}`,
+		Extract: []storage.TransactionExtractor{
+			{Var: "token", Source: storage.ExtractJSON, Path: "$.data.token"},
+			{Var: "req", Source: storage.ExtractHeader, Path: "X-Request-Id"},
+		},
+	}
+	profile := storage.TransactionStep{
+		Name: "profile", URL: "/profile/{{req}}",
+		Headers:    map[string]string{"Authorization": "Bearer {{ token }}"},
+		Assertions: json.RawMessage(`{"operator":"and","groups":[{"operator":"and","conditions":[{"type":"json_path","operator":"eq","target":"$.request","value":"req-7"}]}]}`),
+	}
+
+	tests := []struct {
+		name       string
+		steps      []storage.TransactionStep
+		wantStatus string
+		wantMsg    string
+	}{
+		{
+			name:       "login then profile",
+			steps:      []storage.TransactionStep{login, profile},
+			wantStatus: "up",
+			wantMsg:    "transaction completed: 2 steps",
+		},
+		{
+			name:       "missing session",
+			steps:      []storage.TransactionStep{{URL: "/profile/x"}},
+			wantStatus: "down",
+			wantMsg:    "step 1: expected a 2xx or 3xx status, got 403",
+		},
+		{
+			name:       "undefined variable",
+			steps:      []storage.TransactionStep{profile},
+			wantStatus: "down",
+			wantMsg:    "step 1 (profile): undefined variable",
+		},
+		{
+			name: "expected status",
+			steps: []storage.TransactionStep{
+				{Method: "POST", URL: "/login", Body: `{"user":"intruder"}`, ExpectedStatus: "401"},
+			},
+			wantStatus: "up",
+		},
+		{
+			name: "failing assertion",
+			steps: []storage.TransactionStep{login, {
+				Name: "profile", URL: "/profile/{{req}}",
+				Headers:    map[string]string{"Authorization": "Bearer {{token}}"},
+				Assertions: json.RawMessage(`{"operator":"and","groups":[{"operator":"and","conditions":[{"type":"body_contains","operator":"contains","value":"admin","degraded":true}]}]}`),
+			}},
+			wantStatus: "degraded",
+			wantMsg:    "step 2 (profile): ",
+		},
+		{
+			name: "missing extract",
+			steps: []storage.TransactionStep{{
+				Method: "POST", URL: "/login", Body: `{"user":"monitor"}`,
+				Extract: []storage.TransactionExtractor{{Var: "id", Path: "$.data.id"}},
+			}},
+			wantStatus: "down",
+			wantMsg:    "step 1: extract id:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, _ := json.Marshal(storage.TransactionSettings{Steps: tt.steps})
+			c := &TransactionChecker{AllowPrivate: true}
+			res, err := c.Check(context.Background(), &storage.Monitor{Target: transactionServer(t), Timeout: 5, Settings: settings})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != tt.wantStatus {
+				t.Fatalf("status = %q, want %q (%s)", res.Status, tt.wantStatus, res.Message)
+			}
+			if !strings.Contains(res.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want it to contain %q", res.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestExpandTransactionVars(t *testing.T) {
+	vars := map[string]string{"token": "abc", "id": "7"}
+	got, err := expandTransactionVars("/items/{{id}}?t={{ token }}", vars)
+	if err != nil || got != "/items/7?t=abc" {
+		t.Errorf("expand = %q, %v", got, err)
+	}
+	if _, err := expandTransactionVars("{{missing}}", vars); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("err = %v, want undefined variable missing", err)
+	}
+	if got, _ := expandTransactionVars("{{ not a var }}", vars); got != "{{ not a var }}" {
+		t.Errorf("expand = %q, want it unchanged", got)
+	}
+}
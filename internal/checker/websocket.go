@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
@@ -32,10 +33,10 @@ func (c *WebSocketChecker) Check(ctx context.Context, monitor *storage.Monitor)
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	baseDial := (&net.Dialer{
+	baseDial := sourceDial(&net.Dialer{
 		Timeout: timeout,
 		Control: safenet.MaybeDialControl(c.AllowPrivate),
-	}).DialContext
+	}, monitor.SourceAddr)
 
 	transport := &http.Transport{DialContext: baseDial}
 	if monitor.ProxyURL != "" {
@@ -56,54 +57,151 @@ func (c *WebSocketChecker) Check(ctx context.Context, monitor *storage.Monitor)
 		}
 		opts.HTTPHeader = header
 	}
+	if settings.Subprotocol != "" {
+		opts.Subprotocols = []string{settings.Subprotocol}
+	}
+
+	steps := wsSteps(settings)
 
 	start := time.Now()
 	conn, _, err := websocket.Dial(ctx, monitor.Target, opts)
-	elapsed := time.Since(start).Milliseconds()
-
 	if err != nil {
 		return &Result{
 			Status:       "down",
-			ResponseTime: elapsed,
+			ResponseTime: time.Since(start).Milliseconds(),
 			Message:      fmt.Sprintf("WebSocket dial failed: %v", err),
 		}, nil
 	}
 	defer conn.CloseNow()
 
-	if settings.SendMessage != "" {
-		err := conn.Write(ctx, websocket.MessageText, []byte(settings.SendMessage))
-		if err != nil {
-			return &Result{
-				Status:       "down",
-				ResponseTime: elapsed,
-				Message:      fmt.Sprintf("WebSocket write failed: %v", err),
-			}, nil
-		}
+	if settings.Subprotocol != "" && conn.Subprotocol() != settings.Subprotocol {
+		return &Result{
+			Status:       "down",
+			ResponseTime: time.Since(start).Milliseconds(),
+			Message:      fmt.Sprintf("server did not accept subprotocol %q", settings.Subprotocol),
+		}, nil
 	}
 
-	if settings.ExpectReply != "" {
-		_, msg, err := conn.Read(ctx)
+	var last []byte
+	for i, step := range steps {
+		last, err = runWSStep(ctx, conn, step)
 		if err != nil {
+			msg := err.Error()
+			if len(steps) > 1 {
+				msg = fmt.Sprintf("step %d: %s", i+1, msg)
+			}
 			return &Result{
 				Status:       "down",
-				ResponseTime: elapsed,
-				Message:      fmt.Sprintf("WebSocket read failed: %v", err),
-			}, nil
-		}
-		if !strings.Contains(string(msg), settings.ExpectReply) {
-			return &Result{
-				Status:       "down",
-				ResponseTime: elapsed,
-				Message:      "expected reply not found in WebSocket message",
+				ResponseTime: time.Since(start).Milliseconds(),
+				Message:      msg,
+				Body:         string(last),
 			}, nil
 		}
 	}
 
 	conn.Close(websocket.StatusNormalClosure, "check complete")
 
+	message := "WebSocket connection successful"
+	if len(steps) > 1 {
+		message = fmt.Sprintf("WebSocket script completed (%d steps)", len(steps))
+	}
 	return &Result{
 		Status:       "up",
 		ResponseTime: time.Since(start).Milliseconds(),
-		Message:      "WebSocket connection successful",
+		Message:      message,
+		Body:         string(last),
 	}, nil
 }
+
+// wsSteps returns the steps to run, with the send_message/expect_reply pair
+// as the first one when set.
+func wsSteps(settings storage.WebSocketSettings) []storage.WebSocketStep {
+	var steps []storage.WebSocketStep
+	if settings.SendMessage != "" || settings.ExpectReply != "" {
+		steps = append(steps, storage.WebSocketStep{Send: settings.SendMessage, Expect: settings.ExpectReply})
+	}
+	return append(steps, settings.Steps...)
+}
+
+// runWSStep sends the step's frame, then reads one frame and matches it
+// when the step expects a reply. It returns the frame read, if any.
+func runWSStep(ctx context.Context, conn *websocket.Conn, step storage.WebSocketStep) ([]byte, error) {
+	if step.Send != "" {
+		if err := conn.Write(ctx, websocket.MessageText, []byte(step.Send)); err != nil {
+			return nil, fmt.Errorf("WebSocket write failed: %v", err)
+		}
+	}
+	if step.Expect == "" {
+		return nil, nil
+	}
+	_, msg, err := conn.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("WebSocket read failed: %v", err)
+	}
+	ok, err := matchWSFrame(msg, step)
+	if err != nil {
+		return msg, err
+	}
+	if !ok {
+		return msg, fmt.Errorf("expected reply not found in WebSocket message")
+	}
+	return msg, nil
+}
+
+func matchWSFrame(msg []byte, step storage.WebSocketStep) (bool, error) {
+	switch step.Match {
+	case "", storage.WSMatchContains:
+		return strings.Contains(string(msg), step.Expect), nil
+	case storage.WSMatchRegex:
+		re, err := regexp.Compile(step.Expect)
+		if err != nil {
+			return false, fmt.Errorf("invalid expect regex: %v", err)
+		}
+		return re.Match(msg), nil
+	case storage.WSMatchJSON:
+		var want any
+		if err := json.Unmarshal([]byte(step.Expect), &want); err != nil {
+			return false, fmt.Errorf("invalid expect JSON: %v", err)
+		}
+		var got any
+		if err := json.Unmarshal(msg, &got); err != nil {
+			return false, fmt.Errorf("WebSocket message is not JSON")
+		}
+		return jsonSubset(want, got), nil
+	default:
+		return false, fmt.Errorf("unknown match mode %q", step.Match)
+	}
+}
+
+// jsonSubset reports whether got contains want: objects match when every
+// key in want matches in got, arrays element by element with equal length,
+// and scalars by equality.
+func jsonSubset(want, got any) bool {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, wv := range w {
+			gv, ok := g[k]
+			if !ok || !jsonSubset(wv, gv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for i := range w {
+			if !jsonSubset(w[i], g[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return want == got
+	}
+}
@@ -0,0 +1,140 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// wsAuthServer echoes frames only after an {"type":"auth"} frame, replying
+// to it with {"type":"ready","session":{"id":1}}.
+func wsAuthServer(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{"realtime.v1"}})
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		authed := false
+		for {
+			_, msg, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			reply := msg
+			switch {
+			case strings.Contains(string(msg), `"auth"`):
+				authed = true
+				reply = []byte(`{"type":"ready","session":{"id":1,"user":"monitor"},"tags":["a","b"]}`)
+			case !authed:
+				reply = []byte(`{"type":"error","reason":"unauthorized"}`)
+			}
+			if err := conn.Write(r.Context(), websocket.MessageText, reply); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestWebSocketCheckerSteps(t *testing.T) {
+	auth := storage.WebSocketStep{Send: `{"type":"auth","token":"x"}`, Expect: `{"type":"ready","session":{"id":1}}`, Match: storage.WSMatchJSON}
+	tests := []struct {
+		name       string
+		settings   storage.WebSocketSettings
+		wantStatus string
+		wantMsg    string
+	}{
+		{
+			name:       "connect only",
+			settings:   storage.WebSocketSettings{},
+			wantStatus: "up",
+			wantMsg:    "WebSocket connection successful",
+		},
+		{
+			name:       "legacy send and expect",
+			settings:   storage.WebSocketSettings{SendMessage: "ping", ExpectReply: "unauthorized"},
+			wantStatus: "up",
+		},
+		{
+			name: "auth then echo",
+			settings: storage.WebSocketSettings{Subprotocol: "realtime.v1", Steps: []storage.WebSocketStep{
+				auth,
+				{Send: "hello 42", Expect: `^hello \d+$`, Match: storage.WSMatchRegex},
+			}},
+			wantStatus: "up",
+			wantMsg:    "WebSocket script completed (2 steps)",
+		},
+		{
+			name: "echo before auth fails",
+			settings: storage.WebSocketSettings{Steps: []storage.WebSocketStep{
+				{Send: "hello", Expect: "hello"},
+				auth,
+			}},
+			wantStatus: "down",
+			wantMsg:    "step 1: expected reply not found",
+		},
+		{
+			name: "json subset mismatch",
+			settings: storage.WebSocketSettings{Steps: []storage.WebSocketStep{
+				{Send: "noop"},
+				{Send: `{"type":"auth"}`, Expect: `{"session":{"id":2}}`, Match: storage.WSMatchJSON},
+			}},
+			wantStatus: "down",
+			wantMsg:    "step 2: expected reply not found",
+		},
+		{
+			name:       "subprotocol rejected",
+			settings:   storage.WebSocketSettings{Subprotocol: "other"},
+			wantStatus: "down",
+			wantMsg:    `did not accept subprotocol "other"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, _ := json.Marshal(tt.settings)
+			c := &WebSocketChecker{AllowPrivate: true}
+			res, err := c.Check(context.Background(), &storage.Monitor{Target: wsAuthServer(t), Timeout: 5, Settings: settings})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != tt.wantStatus {
+				t.Fatalf("status = %q, want %q (%s)", res.Status, tt.wantStatus, res.Message)
+			}
+			if !strings.Contains(res.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want it to contain %q", res.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestJSONSubset(t *testing.T) {
+	tests := []struct {
+		want, got string
+		match     bool
+	}{
+		{`{"a":1}`, `{"a":1,"b":2}`, true},
+		{`{"a":{"b":true}}`, `{"a":{"b":true,"c":null}}`, true},
+		{`{"a":1}`, `{"a":"1"}`, false},
+		{`{"a":[1,{"b":2}]}`, `{"a":[1,{"b":2,"c":3}]}`, true},
+		{`{"a":[1]}`, `{"a":[1,2]}`, false},
+		{`{"missing":null}`, `{}`, false},
+		{`"ok"`, `"ok"`, true},
+	}
+	for _, tt := range tests {
+		var want, got any
+		json.Unmarshal([]byte(tt.want), &want)
+		json.Unmarshal([]byte(tt.got), &got)
+		if jsonSubset(want, got) != tt.match {
+			t.Errorf("jsonSubset(%s, %s) = %v, want %v", tt.want, tt.got, !tt.match, tt.match)
+		}
+	}
+}
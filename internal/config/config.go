@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -19,6 +20,7 @@ type Config struct {
 	Database DatabaseConfig `yaml:"database"`
 	Auth     AuthConfig     `yaml:"auth"`
 	Monitor  MonitorConfig  `yaml:"monitor"`
+	Notifier NotifierConfig `yaml:"notifier"`
 	Logging  LoggingConfig  `yaml:"logging"`
 
 	trustedNets []net.IPNet
@@ -36,6 +38,7 @@ type ServerConfig struct {
 	RateLimitPerSec float64       `yaml:"rate_limit_per_sec"`
 	RateLimitBurst  int           `yaml:"rate_limit_burst"`
 	WebUIEnabled    *bool         `yaml:"web_ui_enabled"`
+	DetailedMetrics *bool         `yaml:"detailed_metrics"`
 	FrameAncestors  []string      `yaml:"frame_ancestors"`
 	BasePath        string        `yaml:"base_path"`
 	ExternalURL     string        `yaml:"external_url"`
@@ -43,11 +46,17 @@ type ServerConfig struct {
 }
 
 type DatabaseConfig struct {
+	Driver                  string        `yaml:"driver"`
 	Path                    string        `yaml:"path"`
+	DSN                     string        `yaml:"dsn"`
 	MaxReadConns            int           `yaml:"max_read_conns"`
 	RetentionDays           int           `yaml:"retention_days"`
 	RetentionPeriod         time.Duration `yaml:"retention_period"`
 	RequestLogRetentionDays int           `yaml:"request_log_retention_days"`
+	TransitionRetentionDays int           `yaml:"transition_retention_days"`
+	WriteRetries            int           `yaml:"write_retries"`
+	WriteRetryBackoff       time.Duration `yaml:"write_retry_backoff"`
+	WriteBufferSize         int           `yaml:"write_buffer_size"`
 }
 
 type AuthConfig struct {
@@ -78,6 +87,10 @@ type APIKeyConfig struct {
 	SuperAdmin  bool     `yaml:"super_admin,omitempty"`
 	Permissions []string `yaml:"permissions,omitempty"`
 	TOTP        bool     `yaml:"totp,omitempty"`
+	// GroupIDs and TagIDs scope a key to the monitors in one of the groups
+	// or carrying one of the tags. Scoped keys are read-only and API-only.
+	GroupIDs []int64 `yaml:"group_ids,omitempty"`
+	TagIDs   []int64 `yaml:"tag_ids,omitempty"`
 }
 
 var AllPermissions = []string{
@@ -100,6 +113,12 @@ func (k *APIKeyConfig) HasPermission(perm string) bool {
 	return false
 }
 
+// Scoped reports whether the key only sees the monitors in its groups or
+// tags.
+func (k *APIKeyConfig) Scoped() bool {
+	return len(k.GroupIDs) > 0 || len(k.TagIDs) > 0
+}
+
 func (k *APIKeyConfig) PermissionMap() map[string]bool {
 	m := make(map[string]bool)
 	if k.SuperAdmin {
@@ -116,6 +135,8 @@ func (k *APIKeyConfig) PermissionMap() map[string]bool {
 
 type MonitorConfig struct {
 	Workers                int           `yaml:"workers"`
+	MaxQueue               int           `yaml:"max_queue"`     // 0 = twice workers
+	MaxInFlight            int           `yaml:"max_in_flight"` // 0 = workers
 	DefaultTimeout         time.Duration `yaml:"default_timeout"`
 	DefaultInterval        time.Duration `yaml:"default_interval"`
 	FailureThreshold       int           `yaml:"failure_threshold"`
@@ -126,6 +147,94 @@ type MonitorConfig struct {
 	HeartbeatCheckInterval time.Duration `yaml:"heartbeat_check_interval"`
 	AllowPrivateTargets    bool          `yaml:"allow_private_targets"`
 	AdaptiveIntervals      bool          `yaml:"adaptive_intervals"`
+	ScheduleJitter         int           `yaml:"schedule_jitter"`         // percent of the interval, 0 = off
+	PerHostMaxConcurrent   int           `yaml:"per_host_max_concurrent"` // 0 = unlimited
+	PerHostMinInterval     time.Duration `yaml:"per_host_min_interval"`   // 0 = off
+	PerHostMaxWait         time.Duration `yaml:"per_host_max_wait"`       // 0 = wait for the host indefinitely
+	AckSilencesReminders   bool          `yaml:"ack_silences_reminders"`
+	MaxMonitors            int           `yaml:"max_monitors"`           // 0 = unlimited
+	MaxMonitorsPerGroup    int           `yaml:"max_monitors_per_group"` // 0 = unlimited
+	BaselineInterval       time.Duration `yaml:"baseline_interval"`
+	BaselineDays           int           `yaml:"baseline_days"`
+	BaselineMinSamples     int           `yaml:"baseline_min_samples"`
+	// MaxStoredBodyBytes caps the response body stored with each check.
+	// Assertions and change detection still see the whole body. 0 stores none.
+	MaxStoredBodyBytes int `yaml:"max_stored_body_bytes"`
+	// ProxyCheckInterval is how often each proxy is checked by fetching
+	// ProxyCanaryURL through it. 0 turns proxy health checks off.
+	ProxyCheckInterval time.Duration `yaml:"proxy_check_interval"`
+	ProxyCanaryURL     string        `yaml:"proxy_canary_url"`
+	// ProxyDisableAfter disables a proxy after this many consecutive failed
+	// health checks (0 = never). With ProxyReenable, a proxy disabled this
+	// way is enabled again once a check passes.
+	ProxyDisableAfter int  `yaml:"proxy_disable_after"`
+	ProxyReenable     bool `yaml:"proxy_reenable"`
+	// DefaultNotificationChannel names the channel used by monitors that have
+	// no notification channels of their own. Empty sends them to every channel.
+	DefaultNotificationChannel string `yaml:"default_notification_channel"`
+	// OwnerChannels maps a monitor owner to the channel its monitors notify
+	// when they have no channels of their own. It takes precedence over
+	// DefaultNotificationChannel.
+	OwnerChannels map[string]string `yaml:"owner_channels"`
+	// AutoTagRules tag monitors automatically when they are created or updated.
+	AutoTagRules []AutoTagRule `yaml:"auto_tag_rules"`
+}
+
+// AutoTagRule applies Tag to every monitor matching all of the conditions
+// it sets. A rule must set at least one condition.
+type AutoTagRule struct {
+	Tag            string `yaml:"tag"`
+	Value          string `yaml:"value,omitempty"`
+	Color          string `yaml:"color,omitempty"` // used when the tag has to be created
+	Type           string `yaml:"type,omitempty"`
+	TargetContains string `yaml:"target_contains,omitempty"`
+	NameRegex      string `yaml:"name_regex,omitempty"`
+
+	nameRe *regexp.Regexp
+}
+
+// Matches reports whether a monitor with the given name, type and target
+// satisfies every condition of the rule.
+func (r *AutoTagRule) Matches(name, monType, target string) bool {
+	if r.Type == "" && r.TargetContains == "" && r.NameRegex == "" {
+		return false
+	}
+	if r.Type != "" && r.Type != monType {
+		return false
+	}
+	if r.TargetContains != "" && !strings.Contains(target, r.TargetContains) {
+		return false
+	}
+	if r.NameRegex != "" {
+		// Rules from Load are compiled by validateAutoTagRules. Matches only
+		// reads the rule, since rules are evaluated concurrently; a rule that
+		// was never validated compiles a local copy.
+		re := r.nameRe
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(r.NameRegex); err != nil {
+				return false
+			}
+		}
+		if !re.MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
+
+type NotifierConfig struct {
+	// MinNotifyInterval drops repeated notifications of the same event type
+	// for the same monitor within this interval. Resolutions always go out.
+	MinNotifyInterval time.Duration `yaml:"min_notify_interval"`
+	// ActionKey names the API key that ack and resolve links in incident
+	// notifications act as. Empty leaves the links out.
+	ActionKey string `yaml:"action_key"`
+	// ActionSecret signs action links. Changing it invalidates links
+	// already sent.
+	ActionSecret string `yaml:"action_secret"`
+	// ActionLinkTTL is how long an action link stays valid after it is sent.
+	ActionLinkTTL time.Duration `yaml:"action_link_ttl"`
 }
 
 type LoggingConfig struct {
@@ -145,11 +254,16 @@ func Defaults() *Config {
 			RateLimitBurst:  60,
 		},
 		Database: DatabaseConfig{
+			Driver:                  "sqlite",
 			Path:                    "asura.db",
 			MaxReadConns:            4,
 			RetentionDays:           90,
 			RetentionPeriod:         1 * time.Hour,
 			RequestLogRetentionDays: 7,
+			TransitionRetentionDays: 365,
+			WriteRetries:            3,
+			WriteRetryBackoff:       100 * time.Millisecond,
+			WriteBufferSize:         1000,
 		},
 		Auth: AuthConfig{
 			Session: SessionConfig{
@@ -170,6 +284,16 @@ func Defaults() *Config {
 			CommandTimeout:         30 * time.Second,
 			HeartbeatCheckInterval: 30 * time.Second,
 			AdaptiveIntervals:      true,
+			ScheduleJitter:         10,
+			PerHostMaxWait:         10 * time.Second,
+			BaselineInterval:       time.Hour,
+			BaselineDays:           14,
+			BaselineMinSamples:     30,
+			MaxStoredBodyBytes:     64 << 10,
+			ProxyCanaryURL:         "https://www.gstatic.com/generate_204",
+		},
+		Notifier: NotifierConfig{
+			ActionLinkTTL: 24 * time.Hour,
 		},
 		Logging: LoggingConfig{
 			Level:  "info",
@@ -218,9 +342,15 @@ func (c *Config) Validate() error {
 	if err := c.validateMonitorConfig(); err != nil {
 		return err
 	}
+	if c.Notifier.MinNotifyInterval < 0 {
+		return fmt.Errorf("notifier.min_notify_interval must not be negative")
+	}
 	if err := validateAPIKeys(c.Auth.APIKeys); err != nil {
 		return err
 	}
+	if err := c.validateActionLinks(); err != nil {
+		return err
+	}
 	return validateLogLevel(c.Logging.Level)
 }
 
@@ -252,8 +382,17 @@ func (c *Config) validateServer() error {
 }
 
 func (c *Config) validateDatabase() error {
-	if c.Database.Path == "" {
-		return fmt.Errorf("database.path is required")
+	switch c.Database.Driver {
+	case "sqlite":
+		if c.Database.Path == "" {
+			return fmt.Errorf("database.path is required")
+		}
+	case "postgres":
+		if c.Database.DSN == "" {
+			return fmt.Errorf("database.dsn is required for the postgres driver")
+		}
+	default:
+		return fmt.Errorf("database.driver must be sqlite or postgres, got %q", c.Database.Driver)
 	}
 	if c.Database.MaxReadConns <= 0 {
 		return fmt.Errorf("database.max_read_conns must be positive")
@@ -261,6 +400,12 @@ func (c *Config) validateDatabase() error {
 	if c.Database.RetentionDays <= 0 {
 		return fmt.Errorf("database.retention_days must be positive")
 	}
+	if c.Database.TransitionRetentionDays <= 0 {
+		return fmt.Errorf("database.transition_retention_days must be positive")
+	}
+	if c.Database.WriteRetries < 0 || c.Database.WriteRetryBackoff < 0 || c.Database.WriteBufferSize < 0 {
+		return fmt.Errorf("database.write_retries, write_retry_backoff and write_buffer_size must not be negative")
+	}
 	return nil
 }
 
@@ -280,6 +425,108 @@ func (c *Config) validateMonitorConfig() error {
 	if c.Monitor.SuccessThreshold <= 0 {
 		return fmt.Errorf("monitor.success_threshold must be positive")
 	}
+	if c.Monitor.MaxMonitors < 0 {
+		return fmt.Errorf("monitor.max_monitors must not be negative")
+	}
+	if c.Monitor.MaxMonitorsPerGroup < 0 {
+		return fmt.Errorf("monitor.max_monitors_per_group must not be negative")
+	}
+	if c.Monitor.BaselineInterval < time.Minute {
+		return fmt.Errorf("monitor.baseline_interval must be at least 1m")
+	}
+	if c.Monitor.BaselineDays <= 0 {
+		return fmt.Errorf("monitor.baseline_days must be positive")
+	}
+	if c.Monitor.BaselineMinSamples <= 0 {
+		return fmt.Errorf("monitor.baseline_min_samples must be positive")
+	}
+	if c.Monitor.MaxStoredBodyBytes < 0 {
+		return fmt.Errorf("monitor.max_stored_body_bytes must not be negative")
+	}
+	if err := c.validateProxyChecks(); err != nil {
+		return err
+	}
+	if c.Monitor.ScheduleJitter < 0 || c.Monitor.ScheduleJitter > 50 {
+		return fmt.Errorf("monitor.schedule_jitter must be between 0 and 50")
+	}
+	if c.Monitor.MaxQueue < 0 {
+		return fmt.Errorf("monitor.max_queue must not be negative")
+	}
+	if c.Monitor.MaxInFlight < 0 {
+		return fmt.Errorf("monitor.max_in_flight must not be negative")
+	}
+	if c.Monitor.PerHostMaxConcurrent < 0 {
+		return fmt.Errorf("monitor.per_host_max_concurrent must not be negative")
+	}
+	if c.Monitor.PerHostMinInterval < 0 || c.Monitor.PerHostMinInterval > 10*time.Second {
+		return fmt.Errorf("monitor.per_host_min_interval must be between 0 and 10s")
+	}
+	if c.Monitor.PerHostMaxWait < 0 {
+		return fmt.Errorf("monitor.per_host_max_wait must not be negative")
+	}
+	return validateAutoTagRules(c.Monitor.AutoTagRules)
+}
+
+func (c *Config) validateProxyChecks() error {
+	m := &c.Monitor
+	if m.ProxyCheckInterval < 0 {
+		return fmt.Errorf("monitor.proxy_check_interval must not be negative")
+	}
+	if m.ProxyDisableAfter < 0 {
+		return fmt.Errorf("monitor.proxy_disable_after must not be negative")
+	}
+	if m.ProxyCheckInterval == 0 {
+		return nil
+	}
+	if m.ProxyCheckInterval < 30*time.Second {
+		return fmt.Errorf("monitor.proxy_check_interval must be at least 30s")
+	}
+	u, err := url.Parse(m.ProxyCanaryURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("monitor.proxy_canary_url must be an http or https URL")
+	}
+	return nil
+}
+
+func validateAutoTagRules(rules []AutoTagRule) error {
+	for i := range rules {
+		r := &rules[i]
+		if strings.TrimSpace(r.Tag) == "" {
+			return fmt.Errorf("monitor.auto_tag_rules[%d]: tag is required", i)
+		}
+		if r.Type == "" && r.TargetContains == "" && r.NameRegex == "" {
+			return fmt.Errorf("monitor.auto_tag_rules[%d]: at least one of type, target_contains or name_regex is required", i)
+		}
+		if r.NameRegex != "" {
+			re, err := regexp.Compile(r.NameRegex)
+			if err != nil {
+				return fmt.Errorf("monitor.auto_tag_rules[%d]: invalid name_regex: %w", i, err)
+			}
+			r.nameRe = re
+		}
+	}
+	return nil
+}
+
+// validateActionLinks checks the notifier's action link settings against the
+// API keys, so must run after validateAPIKeys has expanded their roles.
+func (c *Config) validateActionLinks() error {
+	if c.Notifier.ActionKey == "" {
+		return nil
+	}
+	key := c.LookupAPIKeyByName(c.Notifier.ActionKey)
+	if key == nil {
+		return fmt.Errorf("notifier.action_key: no API key named %q", c.Notifier.ActionKey)
+	}
+	if !key.HasPermission("incidents.write") {
+		return fmt.Errorf("notifier.action_key: API key %q lacks incidents.write", key.Name)
+	}
+	if len(c.Notifier.ActionSecret) < 32 {
+		return fmt.Errorf("notifier.action_secret must be at least 32 characters")
+	}
+	if c.Notifier.ActionLinkTTL <= 0 || c.Notifier.ActionLinkTTL > 7*24*time.Hour {
+		return fmt.Errorf("notifier.action_link_ttl must be between 1s and 168h")
+	}
 	return nil
 }
 
@@ -305,6 +552,9 @@ func validateAPIKeys(keys []APIKeyConfig) error {
 				"monitors.read", "incidents.read",
 				"notifications.read", "maintenance.read", "metrics.read",
 			}
+			if key.Scoped() {
+				key.Permissions = []string{"monitors.read"}
+			}
 			key.Role = ""
 		}
 		if !key.SuperAdmin && len(key.Permissions) == 0 {
@@ -315,6 +565,35 @@ func validateAPIKeys(keys []APIKeyConfig) error {
 				return fmt.Errorf("auth.api_keys[%d] invalid permission: %s", i, p)
 			}
 		}
+		if key.Scoped() {
+			if err := validateKeyScope(i, key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateKeyScope checks a key with group_ids or tag_ids. Scope is only
+// enforced on monitor reads, so such keys may hold no other permission.
+func validateKeyScope(i int, key *APIKeyConfig) error {
+	if key.SuperAdmin {
+		return fmt.Errorf("auth.api_keys[%d]: group_ids and tag_ids cannot be combined with super_admin", i)
+	}
+	for _, p := range key.Permissions {
+		if p != "monitors.read" {
+			return fmt.Errorf("auth.api_keys[%d]: keys with group_ids or tag_ids may only have the monitors.read permission", i)
+		}
+	}
+	for _, id := range key.GroupIDs {
+		if id <= 0 {
+			return fmt.Errorf("auth.api_keys[%d].group_ids must be positive", i)
+		}
+	}
+	for _, id := range key.TagIDs {
+		if id <= 0 {
+			return fmt.Errorf("auth.api_keys[%d].tag_ids must be positive", i)
+		}
 	}
 	return nil
 }
@@ -340,6 +619,14 @@ func (c *Config) IsWebUIEnabled() bool {
 	return *c.Server.WebUIEnabled
 }
 
+// IsDetailedMetrics reports whether /metrics includes per-monitor series.
+func (c *Config) IsDetailedMetrics() bool {
+	if c.Server.DetailedMetrics == nil {
+		return true
+	}
+	return *c.Server.DetailedMetrics
+}
+
 // LookupAPIKeyByName finds an API key config by its name.
 func (c *Config) LookupAPIKeyByName(name string) *APIKeyConfig {
 	for i := range c.Auth.APIKeys {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -83,6 +84,16 @@ func TestValidate(t *testing.T) {
 			modify: func(c *Config) { c.Database.Path = "" },
 			errSub: "database.path",
 		},
+		{
+			name:   "unknown database driver",
+			modify: func(c *Config) { c.Database.Driver = "mysql" },
+			errSub: "database.driver",
+		},
+		{
+			name:   "postgres without dsn",
+			modify: func(c *Config) { c.Database.Driver = "postgres" },
+			errSub: "database.dsn",
+		},
 		{
 			name:   "zero read conns",
 			modify: func(c *Config) { c.Database.MaxReadConns = 0 },
@@ -93,6 +104,11 @@ func TestValidate(t *testing.T) {
 			modify: func(c *Config) { c.Database.RetentionDays = 0 },
 			errSub: "retention_days",
 		},
+		{
+			name:   "zero transition retention days",
+			modify: func(c *Config) { c.Database.TransitionRetentionDays = 0 },
+			errSub: "transition_retention_days",
+		},
 		{
 			name:   "zero workers",
 			modify: func(c *Config) { c.Monitor.Workers = 0 },
@@ -118,6 +134,97 @@ func TestValidate(t *testing.T) {
 			modify: func(c *Config) { c.Monitor.SuccessThreshold = 0 },
 			errSub: "success_threshold",
 		},
+		{
+			name:   "auto tag rule without tag",
+			modify: func(c *Config) { c.Monitor.AutoTagRules = []AutoTagRule{{Type: "http"}} },
+			errSub: "tag is required",
+		},
+		{
+			name:   "auto tag rule without condition",
+			modify: func(c *Config) { c.Monitor.AutoTagRules = []AutoTagRule{{Tag: "prod"}} },
+			errSub: "at least one of",
+		},
+		{
+			name:   "auto tag rule invalid regex",
+			modify: func(c *Config) { c.Monitor.AutoTagRules = []AutoTagRule{{Tag: "prod", NameRegex: "("}} },
+			errSub: "name_regex",
+		},
+		{
+			name:   "schedule jitter out of range",
+			modify: func(c *Config) { c.Monitor.ScheduleJitter = 60 },
+			errSub: "monitor.schedule_jitter",
+		},
+		{
+			name:   "per host min interval too long",
+			modify: func(c *Config) { c.Monitor.PerHostMinInterval = time.Minute },
+			errSub: "monitor.per_host_min_interval",
+		},
+		{
+			name:   "proxy check interval too short",
+			modify: func(c *Config) { c.Monitor.ProxyCheckInterval = 10 * time.Second },
+			errSub: "monitor.proxy_check_interval",
+		},
+		{
+			name: "proxy canary not http",
+			modify: func(c *Config) {
+				c.Monitor.ProxyCheckInterval = time.Minute
+				c.Monitor.ProxyCanaryURL = "ftp://example.com/"
+			},
+			errSub: "monitor.proxy_canary_url",
+		},
+		{
+			name:   "negative proxy disable after",
+			modify: func(c *Config) { c.Monitor.ProxyDisableAfter = -1 },
+			errSub: "monitor.proxy_disable_after",
+		},
+		{
+			name:   "negative max queue",
+			modify: func(c *Config) { c.Monitor.MaxQueue = -1 },
+			errSub: "monitor.max_queue",
+		},
+		{
+			name:   "negative max in flight",
+			modify: func(c *Config) { c.Monitor.MaxInFlight = -1 },
+			errSub: "monitor.max_in_flight",
+		},
+		{
+			name:   "negative per host concurrency",
+			modify: func(c *Config) { c.Monitor.PerHostMaxConcurrent = -1 },
+			errSub: "monitor.per_host_max_concurrent",
+		},
+		{
+			name:   "negative per host max wait",
+			modify: func(c *Config) { c.Monitor.PerHostMaxWait = -time.Second },
+			errSub: "monitor.per_host_max_wait",
+		},
+		{
+			name:   "negative min notify interval",
+			modify: func(c *Config) { c.Notifier.MinNotifyInterval = -time.Second },
+			errSub: "notifier.min_notify_interval",
+		},
+		{
+			name:   "unknown action key",
+			modify: func(c *Config) { c.Notifier.ActionKey = "chatops" },
+			errSub: "notifier.action_key",
+		},
+		{
+			name: "action key without incidents.write",
+			modify: func(c *Config) {
+				c.Auth.APIKeys = []APIKeyConfig{{Name: "chatops", Hash: "x", Role: "readonly"}}
+				c.Notifier.ActionKey = "chatops"
+				c.Notifier.ActionSecret = strings.Repeat("s", 32)
+			},
+			errSub: "lacks incidents.write",
+		},
+		{
+			name: "short action secret",
+			modify: func(c *Config) {
+				c.Auth.APIKeys = []APIKeyConfig{{Name: "chatops", Hash: "x", Permissions: []string{"incidents.write"}}}
+				c.Notifier.ActionKey = "chatops"
+				c.Notifier.ActionSecret = "short"
+			},
+			errSub: "notifier.action_secret",
+		},
 		{
 			name:   "invalid log level",
 			modify: func(c *Config) { c.Logging.Level = "trace" },
@@ -140,6 +247,51 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestAutoTagRuleMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   AutoTagRule
+		mon    [3]string // name, type, target
+		expect bool
+	}{
+		{"type match", AutoTagRule{Tag: "web", Type: "http"}, [3]string{"API", "http", "https://api.example.com"}, true},
+		{"type mismatch", AutoTagRule{Tag: "web", Type: "http"}, [3]string{"DB", "tcp", "db:5432"}, false},
+		{"target contains", AutoTagRule{Tag: "prod", TargetContains: ".prod."}, [3]string{"API", "http", "https://api.prod.example.com"}, true},
+		{"name regex", AutoTagRule{Tag: "edge", NameRegex: "^edge-"}, [3]string{"edge-ams", "ping", "10.0.0.1"}, true},
+		{"all conditions must match", AutoTagRule{Tag: "prod-web", Type: "http", NameRegex: "^api"}, [3]string{"web", "http", "https://x"}, false},
+		{"no conditions never matches", AutoTagRule{Tag: "all"}, [3]string{"API", "http", "https://x"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.mon[0], tt.mon[1], tt.mon[2]); got != tt.expect {
+				t.Errorf("Matches() = %v, want %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+// TestAutoTagRuleMatchesConcurrently catches Matches writing to a shared
+// rule when run with -race.
+func TestAutoTagRuleMatchesConcurrently(t *testing.T) {
+	rules := []AutoTagRule{{Tag: "edge", NameRegex: "^edge-"}}
+	if err := validateAutoTagRules(rules); err != nil {
+		t.Fatal(err)
+	}
+	unvalidated := AutoTagRule{Tag: "edge", NameRegex: "^edge-"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !rules[0].Matches("edge-ams", "ping", "10.0.0.1") || !unvalidated.Matches("edge-ams", "ping", "10.0.0.1") {
+				t.Error("expected edge-ams to match")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func TestValidateAPIKeys(t *testing.T) {
 	t.Run("admin role sets super admin", testValidateAPIKeysAdminRole)
 	t.Run("readonly role sets read permissions", testValidateAPIKeysReadonlyRole)
@@ -147,6 +299,8 @@ func TestValidateAPIKeys(t *testing.T) {
 	t.Run("missing hash", testValidateAPIKeysMissingHash)
 	t.Run("invalid permission", testValidateAPIKeysInvalidPerm)
 	t.Run("no perms and no super admin", testValidateAPIKeysNoPerm)
+	t.Run("scoped readonly role", testValidateAPIKeysScopedReadonly)
+	t.Run("scoped key rejects other permissions", testValidateAPIKeysScopedInvalid)
 }
 
 func testValidateAPIKeysAdminRole(t *testing.T) {
@@ -205,6 +359,34 @@ func testValidateAPIKeysNoPerm(t *testing.T) {
 	}
 }
 
+func testValidateAPIKeysScopedReadonly(t *testing.T) {
+	keys := []APIKeyConfig{{Name: "team", Hash: "abc123", Role: "readonly", GroupIDs: []int64{2}}}
+	if err := validateAPIKeys(keys); err != nil {
+		t.Fatal(err)
+	}
+	if !keys[0].Scoped() || len(keys[0].Permissions) != 1 || keys[0].Permissions[0] != "monitors.read" {
+		t.Fatalf("expected only monitors.read, got %v", keys[0].Permissions)
+	}
+}
+
+func testValidateAPIKeysScopedInvalid(t *testing.T) {
+	tests := []struct {
+		key  APIKeyConfig
+		want string
+	}{
+		{APIKeyConfig{Name: "a", Hash: "h", Role: "admin", TagIDs: []int64{1}}, "cannot be combined with super_admin"},
+		{APIKeyConfig{Name: "b", Hash: "h", Permissions: []string{"monitors.read", "monitors.write"}, GroupIDs: []int64{1}}, "may only have the monitors.read permission"},
+		{APIKeyConfig{Name: "c", Hash: "h", Permissions: []string{"monitors.read"}, GroupIDs: []int64{0}}, "group_ids must be positive"},
+		{APIKeyConfig{Name: "d", Hash: "h", Permissions: []string{"monitors.read"}, TagIDs: []int64{-1}}, "tag_ids must be positive"},
+	}
+	for _, tt := range tests {
+		err := validateAPIKeys([]APIKeyConfig{tt.key})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.key.Name, tt.want, err)
+		}
+	}
+}
+
 func TestValidateLogLevel(t *testing.T) {
 	for _, level := range []string{"debug", "info", "warn", "error"} {
 		t.Run(level, func(t *testing.T) {
@@ -0,0 +1,330 @@
+// Package htmltext extracts the text of HTML elements matched by a CSS
+// selector. It supports the subset of selectors useful for picking content
+// out of a page: type, universal, #id, .class and attribute selectors
+// ([attr], =, ~=, ^=, $=, *=), the descendant and child (>) combinators, and
+// comma-separated groups.
+package htmltext
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Selector is a compiled CSS selector.
+type Selector struct {
+	groups [][]step
+}
+
+// step is one compound selector and the combinator joining it to the step
+// before it (' ' for descendant, '>' for child).
+type step struct {
+	comb    byte
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrMatch
+}
+
+type attrMatch struct {
+	name, op, value string
+}
+
+// Compile parses a CSS selector.
+func Compile(sel string) (*Selector, error) {
+	p := &parser{s: sel}
+	s := &Selector{}
+	for {
+		chain, err := p.chain()
+		if err != nil {
+			return nil, err
+		}
+		s.groups = append(s.groups, chain)
+		if p.eof() {
+			return s, nil
+		}
+		p.i++ // ','
+	}
+}
+
+// Extract returns the text of every element in body matched by the selector,
+// one text run per line. ok is false when nothing matched.
+func (s *Selector) Extract(body string) (text string, ok bool) {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return "", false
+	}
+	var lines []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && s.matches(n) {
+			ok = true
+			lines = appendText(lines, n)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return strings.Join(lines, "\n"), ok
+}
+
+func (s *Selector) matches(n *html.Node) bool {
+	for _, chain := range s.groups {
+		if matchChain(n, chain, len(chain)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchChain matches chain[:i+1] right to left, with n matching chain[i].
+func matchChain(n *html.Node, chain []step, i int) bool {
+	if !chain[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	for a := n.Parent; a != nil && a.Type == html.ElementNode; a = a.Parent {
+		if matchChain(a, chain, i-1) {
+			return true
+		}
+		if chain[i].comb == '>' {
+			return false
+		}
+	}
+	return false
+}
+
+func (st step) matches(n *html.Node) bool {
+	if st.tag != "" && st.tag != "*" && n.Data != st.tag {
+		return false
+	}
+	if st.id != "" && attr(n, "id") != st.id {
+		return false
+	}
+	if len(st.classes) > 0 {
+		have := strings.Fields(attr(n, "class"))
+		for _, c := range st.classes {
+			if !contains(have, c) {
+				return false
+			}
+		}
+	}
+	for _, am := range st.attrs {
+		v, found := lookupAttr(n, am.name)
+		if !found {
+			return false
+		}
+		switch am.op {
+		case "=":
+			found = v == am.value
+		case "~=":
+			found = contains(strings.Fields(v), am.value)
+		case "^=":
+			found = am.value != "" && strings.HasPrefix(v, am.value)
+		case "$=":
+			found = am.value != "" && strings.HasSuffix(v, am.value)
+		case "*=":
+			found = am.value != "" && strings.Contains(v, am.value)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// appendText appends the whitespace-collapsed text runs under n, skipping
+// scripts and styles.
+func appendText(lines []string, n *html.Node) []string {
+	switch {
+	case n.Type == html.TextNode:
+		if t := strings.Join(strings.Fields(n.Data), " "); t != "" {
+			lines = append(lines, t)
+		}
+		return lines
+	case n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style || n.DataAtom == atom.Template):
+		return lines
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		lines = appendText(lines, c)
+	}
+	return lines
+}
+
+func attr(n *html.Node, name string) string {
+	v, _ := lookupAttr(n, name)
+	return v
+}
+
+func lookupAttr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+type parser struct {
+	s string
+	i int
+}
+
+func (p *parser) eof() bool { return p.i >= len(p.s) }
+
+func (p *parser) skipSpace() bool {
+	start := p.i
+	for !p.eof() && isSpace(p.s[p.i]) {
+		p.i++
+	}
+	return p.i > start
+}
+
+// chain parses compound selectors up to the next ',' or the end.
+func (p *parser) chain() ([]step, error) {
+	var chain []step
+	comb := byte(' ')
+	p.skipSpace()
+	for {
+		st, err := p.compound()
+		if err != nil {
+			return nil, err
+		}
+		st.comb = comb
+		chain = append(chain, st)
+
+		spaced := p.skipSpace()
+		switch {
+		case p.eof() || p.s[p.i] == ',':
+			return chain, nil
+		case p.s[p.i] == '>':
+			p.i++
+			p.skipSpace()
+			comb = '>'
+		case spaced:
+			comb = ' '
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", p.s[p.i], p.i)
+		}
+	}
+}
+
+func (p *parser) compound() (step, error) {
+	var st step
+	start := p.i
+	if !p.eof() && p.s[p.i] == '*' {
+		st.tag = "*"
+		p.i++
+	} else if name := p.ident(); name != "" {
+		st.tag = strings.ToLower(name)
+	}
+	for !p.eof() {
+		switch c := p.s[p.i]; c {
+		case '#', '.':
+			p.i++
+			name := p.ident()
+			if name == "" {
+				return st, fmt.Errorf("expected name after %q at offset %d", c, p.i)
+			}
+			if c == '#' {
+				st.id = name
+			} else {
+				st.classes = append(st.classes, name)
+			}
+		case '[':
+			am, err := p.attr()
+			if err != nil {
+				return st, err
+			}
+			st.attrs = append(st.attrs, am)
+		case ':':
+			return st, fmt.Errorf("pseudo-classes are not supported (offset %d)", p.i)
+		default:
+			if p.i == start {
+				return st, fmt.Errorf("expected a selector at offset %d", p.i)
+			}
+			return st, nil
+		}
+	}
+	if p.i == start {
+		return st, fmt.Errorf("expected a selector at offset %d", p.i)
+	}
+	return st, nil
+}
+
+func (p *parser) attr() (attrMatch, error) {
+	p.i++ // '['
+	p.skipSpace()
+	am := attrMatch{name: strings.ToLower(p.ident())}
+	if am.name == "" {
+		return am, fmt.Errorf("expected attribute name at offset %d", p.i)
+	}
+	p.skipSpace()
+	if p.eof() {
+		return am, fmt.Errorf("unterminated attribute selector")
+	}
+	if p.s[p.i] == ']' {
+		p.i++
+		return am, nil
+	}
+	for _, op := range []string{"=", "~=", "^=", "$=", "*="} {
+		if strings.HasPrefix(p.s[p.i:], op) {
+			am.op = op
+			p.i += len(op)
+			break
+		}
+	}
+	if am.op == "" {
+		return am, fmt.Errorf("unsupported attribute operator at offset %d", p.i)
+	}
+	p.skipSpace()
+	if !p.eof() && (p.s[p.i] == '"' || p.s[p.i] == '\'') {
+		q := p.s[p.i]
+		end := strings.IndexByte(p.s[p.i+1:], q)
+		if end < 0 {
+			return am, fmt.Errorf("unterminated string at offset %d", p.i)
+		}
+		am.value = p.s[p.i+1 : p.i+1+end]
+		p.i += end + 2
+	} else {
+		am.value = p.ident()
+	}
+	p.skipSpace()
+	if p.eof() || p.s[p.i] != ']' {
+		return am, fmt.Errorf("unterminated attribute selector")
+	}
+	p.i++
+	return am, nil
+}
+
+func (p *parser) ident() string {
+	start := p.i
+	for !p.eof() {
+		c := p.s[p.i]
+		if c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80 {
+			p.i++
+			continue
+		}
+		break
+	}
+	return p.s[start:p.i]
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,59 @@
+package htmltext
+
+import "testing"
+
+const page = `<html><head><title>Shop</title><script>var csrf = "abc";</script></head>
+<body>
+  <div class="ad banner">Buy now!</div>
+  <main id="content">
+    <h1>Widget</h1>
+    <p class="price sale">  $19.99
+    </p>
+    <ul><li data-sku="w-1">Red</li><li data-sku="w-2">Blue</li></ul>
+  </main>
+  <input type="hidden" name="csrf" value="xyz">
+  <footer><p class="price">$0.00</p></footer>
+</body></html>`
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+		wantOK   bool
+	}{
+		{"h1", "Widget", true},
+		{"#content .price", "$19.99", true},
+		{".price.sale", "$19.99", true},
+		{".price", "$19.99\n$0.00", true},
+		{"main > p", "$19.99", true},
+		{"body > p", "", false},
+		{"footer p", "$0.00", true},
+		{"li[data-sku]", "Red\nBlue", true},
+		{`li[data-sku="w-2"]`, "Blue", true},
+		{"li[data-sku^=w-]", "Red\nBlue", true},
+		{"[class~=banner]", "Buy now!", true},
+		{"h1, footer .price", "Widget\n$0.00", true},
+		{"head", "Shop", true},
+		{"#missing", "", false},
+		{"main", "Widget\n$19.99\nRed\nBlue", true},
+		{"MAIN UL > *", "Red\nBlue", true},
+	}
+	for _, tt := range tests {
+		sel, err := Compile(tt.selector)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.selector, err)
+		}
+		got, ok := sel.Extract(page)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("Extract(%q) = %q, %v; want %q, %v", tt.selector, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, sel := range []string{"", " ", "div >", "> p", "a,", ".", "#", "p:first-child", "[data-x", "[=x]", "[a|=b]", `[a="b]`, "a + b"} {
+		if _, err := Compile(sel); err == nil {
+			t.Errorf("Compile(%q): expected error", sel)
+		}
+	}
+}
@@ -3,10 +3,14 @@ package httputil
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 	"net"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,6 +27,7 @@ type ContextKey string
 const (
 	CtxKeyRequestID ContextKey = "request_id"
 	CtxKeyAPIKey    ContextKey = "api_key"
+	CtxKeyScope     ContextKey = "monitor_scope"
 )
 
 func GetRequestID(ctx context.Context) string {
@@ -46,6 +51,27 @@ func GetAPIKey(ctx context.Context) *config.APIKeyConfig {
 	return nil
 }
 
+// GetMonitorScope returns the monitor scope of a scoped API key, or nil when
+// the caller may see every monitor.
+func GetMonitorScope(ctx context.Context) *storage.MonitorScope {
+	if s, ok := ctx.Value(CtxKeyScope).(*storage.MonitorScope); ok {
+		return s
+	}
+	return nil
+}
+
+// ResolveOwner maps the owner filter "me" to the calling API key's name.
+// Other values are returned trimmed.
+func ResolveOwner(ctx context.Context, owner string) string {
+	owner = strings.TrimSpace(owner)
+	if owner == "me" {
+		if name := GetAPIKeyName(ctx); name != "" {
+			return name
+		}
+	}
+	return owner
+}
+
 func GenerateID() string {
 	b := make([]byte, 8)
 	rand.Read(b)
@@ -62,6 +88,12 @@ func (w *StatusWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer to flush
+// and set deadlines.
+func (w *StatusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func ParsePagination(r *http.Request) storage.Pagination {
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
@@ -74,6 +106,21 @@ func ParsePagination(r *http.Request) storage.Pagination {
 	return storage.Pagination{Page: page, PerPage: perPage}
 }
 
+// ParseTagFilter reads the monitor list tag filter: ?tag repeated once per
+// tag, and tag_match=any to match monitors with any of them instead of all.
+func ParseTagFilter(r *http.Request) ([]int64, string) {
+	var tags []int64
+	for _, v := range r.URL.Query()["tag"] {
+		if id, err := strconv.ParseInt(v, 10, 64); err == nil && !slices.Contains(tags, id) {
+			tags = append(tags, id)
+		}
+	}
+	if r.URL.Query().Get("tag_match") == storage.TagMatchAny {
+		return tags, storage.TagMatchAny
+	}
+	return tags, storage.TagMatchAll
+}
+
 func ParseID(r *http.Request) (int64, error) {
 	idStr := r.PathValue("id")
 	if idStr == "" {
@@ -266,3 +313,271 @@ func PublicIncidentsForPage(ctx context.Context, store storage.Store, sp *storag
 	}
 	return filtered
 }
+
+// statusRank orders monitor statuses from best to worst for component rollup.
+var statusRank = map[string]int{"up": 0, "pending": 1, "degraded": 2, "down": 3}
+
+// WorstStatus returns the worst status among monitors, or "pending" when
+// there are none.
+func WorstStatus(monitors []*storage.Monitor) string {
+	if len(monitors) == 0 {
+		return "pending"
+	}
+	worst := monitors[0].Status
+	for _, m := range monitors[1:] {
+		if statusRank[m.Status] > statusRank[worst] {
+			worst = m.Status
+		}
+	}
+	return worst
+}
+
+// ComponentSummary is the public rollup of a status page component.
+type ComponentSummary struct {
+	Component storage.StatusPageComponent
+	Monitors  []*storage.Monitor
+	Status    string
+	Uptime    float64
+	Daily     []*storage.DailyUptime
+}
+
+// SummarizeComponents rolls the page's monitors up into their components.
+// Component members that are not shown on the page are ignored. The returned
+// set holds the IDs of monitors that belong to a component.
+func SummarizeComponents(ctx context.Context, store storage.Store, components []storage.StatusPageComponent, monitors []*storage.Monitor, from, now time.Time) ([]ComponentSummary, map[int64]bool) {
+	byID := make(map[int64]*storage.Monitor, len(monitors))
+	for _, m := range monitors {
+		byID[m.ID] = m
+	}
+
+	members := make(map[int64]bool)
+	result := make([]ComponentSummary, 0, len(components))
+	for _, c := range components {
+		cs := ComponentSummary{Component: c}
+		var uptimes []float64
+		var dailies [][]*storage.DailyUptime
+		var weights []int
+		for _, cm := range c.Monitors {
+			m, ok := byID[cm.MonitorID]
+			if !ok {
+				continue
+			}
+			members[m.ID] = true
+			cs.Monitors = append(cs.Monitors, m)
+
+			uptime, err := store.GetUptimePercent(ctx, m.ID, from, now)
+			if err != nil {
+				uptime = 100
+			}
+			daily, err := store.GetDailyUptime(ctx, m.ID, from, now)
+			if err != nil {
+				daily = nil
+			}
+			uptimes = append(uptimes, uptime)
+			dailies = append(dailies, daily)
+			weights = append(weights, cm.Weight)
+		}
+		cs.Status = WorstStatus(cs.Monitors)
+		cs.Uptime = WeightedUptime(uptimes, weights)
+		cs.Daily = MergeDailyUptime(dailies, weights)
+		result = append(result, cs)
+	}
+	return result, members
+}
+
+// WeightedUptime averages uptime percentages by weight. Non-positive weights
+// count as 1. An empty input is reported as 100%.
+func WeightedUptime(uptimes []float64, weights []int) float64 {
+	var sum, total float64
+	for i, u := range uptimes {
+		w := 1
+		if i < len(weights) && weights[i] > 0 {
+			w = weights[i]
+		}
+		sum += u * float64(w)
+		total += float64(w)
+	}
+	if total == 0 {
+		return 100
+	}
+	return sum / total
+}
+
+// PreviousMonth returns the UTC calendar month before the one containing now,
+// the default range of SLA reports.
+func PreviousMonth(now time.Time) (from, to time.Time) {
+	now = now.UTC()
+	to = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return to.AddDate(0, -1, 0), to
+}
+
+// MergeDailyUptime combines per-monitor daily uptime series into one, using a
+// weighted average of each day's uptime across the monitors that have data
+// for it. Check counts are summed.
+func MergeDailyUptime(series [][]*storage.DailyUptime, weights []int) []*storage.DailyUptime {
+	type acc struct {
+		day    storage.DailyUptime
+		pctSum float64
+		wSum   float64
+	}
+	days := make(map[string]*acc)
+	var order []string
+	for i, daily := range series {
+		w := 1
+		if i < len(weights) && weights[i] > 0 {
+			w = weights[i]
+		}
+		for _, d := range daily {
+			a, ok := days[d.Date]
+			if !ok {
+				a = &acc{day: storage.DailyUptime{Date: d.Date}}
+				days[d.Date] = a
+				order = append(order, d.Date)
+			}
+			a.day.TotalChecks += d.TotalChecks
+			a.day.UpChecks += d.UpChecks
+			a.day.DownChecks += d.DownChecks
+			a.pctSum += d.UptimePct * float64(w)
+			a.wSum += float64(w)
+		}
+	}
+	sort.Strings(order)
+
+	result := make([]*storage.DailyUptime, 0, len(order))
+	for _, date := range order {
+		a := days[date]
+		a.day.UptimePct = a.pctSum / a.wSum
+		day := a.day
+		result = append(result, &day)
+	}
+	return result
+}
+
+// ActiveAnnouncement returns the page's announcement banner HTML if it is
+// enabled and now falls within its optional start/end window.
+func ActiveAnnouncement(sp *storage.StatusPage, now time.Time) string {
+	if !sp.AnnouncementEnabled || strings.TrimSpace(sp.AnnouncementHTML) == "" {
+		return ""
+	}
+	if sp.AnnouncementStart != nil && now.Before(*sp.AnnouncementStart) {
+		return ""
+	}
+	if sp.AnnouncementEnd != nil && !now.Before(*sp.AnnouncementEnd) {
+		return ""
+	}
+	return sp.AnnouncementHTML
+}
+
+// GenerateStatusPageToken returns a new random bearer token for a status
+// page's public API and the hash to store for it.
+func GenerateStatusPageToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(b)
+	return token, HashStatusPageToken(token), nil
+}
+
+// HashStatusPageToken returns the hex SHA-256 of a status page API token.
+func HashStatusPageToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// StatusPageTokenValid reports whether r carries the bearer token whose hash
+// is tokenHash. A page without a token accepts every request.
+func StatusPageTokenValid(r *http.Request, tokenHash string) bool {
+	if tokenHash == "" {
+		return true
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	got := HashStatusPageToken(strings.TrimSpace(token))
+	return subtle.ConstantTimeCompare([]byte(got), []byte(tokenHash)) == 1
+}
+
+// CheckSubscriberChannel verifies that sp's subscriber channel, if set, is an
+// existing email channel.
+func CheckSubscriberChannel(ctx context.Context, store storage.Store, sp *storage.StatusPage) error {
+	if sp.SubscriberChannelID == nil {
+		return nil
+	}
+	ch, err := store.GetNotificationChannel(ctx, *sp.SubscriberChannelID)
+	if err != nil || ch.Type != "email" {
+		return fmt.Errorf("subscriber_channel_id must reference an email notification channel")
+	}
+	return nil
+}
+
+// RelabelComponentIncidents replaces the monitor name on incidents for
+// component members with the component name, so public pages don't expose
+// the names of the monitors behind a component.
+func RelabelComponentIncidents(incidents []*storage.Incident, components []ComponentSummary) {
+	names := make(map[int64]string)
+	for _, cs := range components {
+		for _, m := range cs.Monitors {
+			if _, ok := names[m.ID]; !ok {
+				names[m.ID] = cs.Component.Name
+			}
+		}
+	}
+	for _, inc := range incidents {
+		if name, ok := names[inc.MonitorID]; ok {
+			inc.MonitorName = name
+		}
+	}
+}
+
+// ApplyAutoTags appends the tags of every rule matching m to tags, creating
+// missing tags by name. Tags already present are left alone. It returns the
+// resulting tag list and the names of the tags that were added.
+func ApplyAutoTags(ctx context.Context, store storage.Store, rules []config.AutoTagRule, m *storage.Monitor, tags []storage.MonitorTag) ([]storage.MonitorTag, []string, error) {
+	var matched []*config.AutoTagRule
+	for i := range rules {
+		if rules[i].Matches(m.Name, m.Type, m.Target) {
+			matched = append(matched, &rules[i])
+		}
+	}
+	if len(matched) == 0 {
+		return tags, nil, nil
+	}
+
+	allTags, err := store.ListTags(ctx)
+	if err != nil {
+		return tags, nil, fmt.Errorf("list tags: %w", err)
+	}
+	nameToID := make(map[string]int64, len(allTags))
+	for _, t := range allTags {
+		nameToID[t.Name] = t.ID
+	}
+	present := make(map[int64]bool, len(tags))
+	for _, t := range tags {
+		present[t.TagID] = true
+	}
+
+	var applied []string
+	for _, rule := range matched {
+		id, ok := nameToID[rule.Tag]
+		if !ok {
+			t := &storage.Tag{Name: rule.Tag, Color: rule.Color}
+			if t.Color == "" {
+				t.Color = "#808080"
+			}
+			if err := store.CreateTag(ctx, t); err != nil {
+				return tags, applied, fmt.Errorf("create tag %q: %w", rule.Tag, err)
+			}
+			id = t.ID
+			nameToID[rule.Tag] = id
+		}
+		if present[id] {
+			continue
+		}
+		present[id] = true
+		tags = append(tags, storage.MonitorTag{TagID: id, Name: rule.Tag, Value: rule.Value})
+		applied = append(applied, rule.Tag)
+	}
+	return tags, applied, nil
+}
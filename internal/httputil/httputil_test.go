@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
 )
 
 func TestExtractIP(t *testing.T) {
@@ -198,6 +201,42 @@ func TestGenerateID(t *testing.T) {
 	}
 }
 
+func TestStatusPageTokenValid(t *testing.T) {
+	token, hash, err := GenerateStatusPageToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(token) != 64 || hash != HashStatusPageToken(token) {
+		t.Fatalf("unexpected token %q with hash %q", token, hash)
+	}
+
+	tests := []struct {
+		name string
+		auth string
+		hash string
+		want bool
+	}{
+		{"no token set", "", "", true},
+		{"missing header", "", hash, false},
+		{"valid", "Bearer " + token, hash, true},
+		{"scheme is case-insensitive", "bearer " + token, hash, true},
+		{"wrong token", "Bearer " + token[1:], hash, false},
+		{"basic auth", "Basic " + token, hash, false},
+		{"empty bearer", "Bearer ", hash, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if got := StatusPageTokenValid(req, tt.hash); got != tt.want {
+				t.Errorf("StatusPageTokenValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestStatusWriter(t *testing.T) {
 	w := httptest.NewRecorder()
 	sw := &StatusWriter{ResponseWriter: w}
@@ -210,3 +249,86 @@ func TestStatusWriter(t *testing.T) {
 		t.Errorf("underlying Code = %d, want 404", w.Code)
 	}
 }
+
+func TestWorstStatus(t *testing.T) {
+	mons := func(statuses ...string) []*storage.Monitor {
+		var out []*storage.Monitor
+		for _, s := range statuses {
+			out = append(out, &storage.Monitor{Status: s})
+		}
+		return out
+	}
+	tests := []struct {
+		in   []*storage.Monitor
+		want string
+	}{
+		{nil, "pending"},
+		{mons("up", "up"), "up"},
+		{mons("up", "pending"), "pending"},
+		{mons("degraded", "up", "pending"), "degraded"},
+		{mons("up", "down", "degraded"), "down"},
+	}
+	for _, tt := range tests {
+		if got := WorstStatus(tt.in); got != tt.want {
+			t.Errorf("WorstStatus(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWeightedUptime(t *testing.T) {
+	if got := WeightedUptime(nil, nil); got != 100 {
+		t.Errorf("empty = %v, want 100", got)
+	}
+	if got := WeightedUptime([]float64{100, 90}, []int{3, 1}); got != 97.5 {
+		t.Errorf("weighted = %v, want 97.5", got)
+	}
+	if got := WeightedUptime([]float64{100, 90}, []int{0, 0}); got != 95 {
+		t.Errorf("zero weights = %v, want 95", got)
+	}
+}
+
+func TestMergeDailyUptime(t *testing.T) {
+	a := []*storage.DailyUptime{
+		{Date: "2024-01-02", TotalChecks: 10, UpChecks: 10, UptimePct: 100},
+		{Date: "2024-01-01", TotalChecks: 10, UpChecks: 5, DownChecks: 5, UptimePct: 50},
+	}
+	b := []*storage.DailyUptime{
+		{Date: "2024-01-02", TotalChecks: 4, UpChecks: 2, DownChecks: 2, UptimePct: 50},
+	}
+	got := MergeDailyUptime([][]*storage.DailyUptime{a, b}, []int{1, 3})
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2", len(got))
+	}
+	if got[0].Date != "2024-01-01" || got[0].UptimePct != 50 {
+		t.Errorf("day 1 = %+v", got[0])
+	}
+	if got[1].Date != "2024-01-02" || got[1].UptimePct != 62.5 || got[1].TotalChecks != 14 {
+		t.Errorf("day 2 = %+v", got[1])
+	}
+}
+
+func TestActiveAnnouncement(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		sp   storage.StatusPage
+		want string
+	}{
+		{"disabled", storage.StatusPage{AnnouncementHTML: "hi"}, ""},
+		{"enabled without window", storage.StatusPage{AnnouncementHTML: "hi", AnnouncementEnabled: true}, "hi"},
+		{"empty text", storage.StatusPage{AnnouncementHTML: "  ", AnnouncementEnabled: true}, ""},
+		{"within window", storage.StatusPage{AnnouncementHTML: "hi", AnnouncementEnabled: true, AnnouncementStart: &before, AnnouncementEnd: &after}, "hi"},
+		{"not started", storage.StatusPage{AnnouncementHTML: "hi", AnnouncementEnabled: true, AnnouncementStart: &after}, ""},
+		{"ended", storage.StatusPage{AnnouncementHTML: "hi", AnnouncementEnabled: true, AnnouncementEnd: &before}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ActiveAnnouncement(&tt.sp, now); got != tt.want {
+				t.Errorf("ActiveAnnouncement() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,51 @@
 package incident
 
+import (
+	"fmt"
+	"time"
+)
+
 const (
 	StatusOpen         = "open"
 	StatusAcknowledged = "acknowledged"
 	StatusResolved     = "resolved"
 )
 
+// Incident severities. Degraded incidents are warnings; everything else,
+// including hard-down incidents, is critical.
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+)
+
 const (
 	EventCreated        = "created"
 	EventAcknowledged   = "acknowledged"
 	EventResolved       = "resolved"
 	EventCheckFailed    = "check_failed"
 	EventCheckRecovered = "check_recovered"
+	EventEscalated      = "escalated"
+	EventAckExpired     = "ack_expired"
+	EventNote           = "note"
+	EventSuperseded     = "superseded"
+	EventReoccurred     = "reoccurred"
 )
+
+// AckDeadline returns when an acknowledgement made at now with the given
+// timeout lapses, or nil when the acknowledgement does not expire.
+func AckDeadline(now time.Time, timeoutMinutes int) *time.Time {
+	if timeoutMinutes <= 0 {
+		return nil
+	}
+	d := now.Add(time.Duration(timeoutMinutes) * time.Minute)
+	return &d
+}
+
+// AckMessage is the timeline message recorded when by acknowledges an
+// incident.
+func AckMessage(by string, timeoutMinutes int) string {
+	if timeoutMinutes > 0 {
+		return fmt.Sprintf("Acknowledged by %s for %d minutes", by, timeoutMinutes)
+	}
+	return "Acknowledged by " + by
+}
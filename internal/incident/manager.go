@@ -21,14 +21,26 @@ func NewManager(store storage.Store, logger *slog.Logger) *Manager {
 }
 
 // ProcessFailure checks if an incident should be created for the monitor.
-// Returns the incident and whether it was newly created.
+// Returns the incident and whether it was newly created. An open degraded
+// incident is resolved and replaced by a new critical one.
 func (m *Manager) ProcessFailure(ctx context.Context, monitorID int64, monitorName, cause string) (*storage.Incident, bool, error) {
+	return m.processFailure(ctx, monitorID, monitorName, cause, SeverityCritical)
+}
+
+// ProcessDegraded is ProcessFailure for a monitor that has been degraded for
+// its degraded threshold. It opens a warning incident, or records the check
+// on whatever incident is already open.
+func (m *Manager) ProcessDegraded(ctx context.Context, monitorID int64, monitorName, cause string) (*storage.Incident, bool, error) {
+	return m.processFailure(ctx, monitorID, monitorName, "Degraded: "+cause, SeverityWarning)
+}
+
+func (m *Manager) processFailure(ctx context.Context, monitorID int64, monitorName, cause, severity string) (*storage.Incident, bool, error) {
 	existing, err := m.store.GetOpenIncident(ctx, monitorID)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, false, err
 	}
 
-	if existing != nil {
+	if existing != nil && !(existing.Severity == SeverityWarning && severity == SeverityCritical) {
 		m.store.InsertIncidentEvent(ctx, &storage.IncidentEvent{
 			IncidentID: existing.ID,
 			Type:       EventCheckFailed,
@@ -36,12 +48,26 @@ func (m *Manager) ProcessFailure(ctx context.Context, monitorID int64, monitorNa
 		})
 		return existing, false, nil
 	}
+	if existing != nil {
+		if err := m.supersede(ctx, existing); err != nil {
+			return nil, false, err
+		}
+	} else {
+		reopened, err := m.reopenFlapping(ctx, monitorID, cause, severity)
+		if err != nil {
+			return nil, false, err
+		}
+		if reopened != nil {
+			return reopened, false, nil
+		}
+	}
 
 	inc := &storage.Incident{
 		MonitorID:   monitorID,
 		MonitorName: monitorName,
 		Status:      StatusOpen,
 		Cause:       cause,
+		Severity:    severity,
 	}
 	if err := m.store.CreateIncident(ctx, inc); err != nil {
 		return nil, false, err
@@ -76,6 +102,7 @@ func (m *Manager) ProcessRecovery(ctx context.Context, monitorID int64) (*storag
 	existing.Status = StatusResolved
 	existing.ResolvedAt = &now
 	existing.ResolvedBy = "auto"
+	existing.AckDeadline = nil
 
 	if err := m.store.UpdateIncident(ctx, existing); err != nil {
 		return nil, false, err
@@ -90,3 +117,60 @@ func (m *Manager) ProcessRecovery(ctx context.Context, monitorID int64) (*storag
 	m.logger.Info("incident resolved", "incident_id", existing.ID, "monitor_id", monitorID)
 	return existing, true, nil
 }
+
+// reopenFlapping reopens the monitor's last incident when it resolved within
+// the monitor's flap window, so a flapping monitor keeps a single incident
+// instead of paging for a new one every time it fails.
+func (m *Manager) reopenFlapping(ctx context.Context, monitorID int64, cause, severity string) (*storage.Incident, error) {
+	mon, err := m.store.GetMonitor(ctx, monitorID)
+	if err != nil || mon.FlapWindowSeconds <= 0 {
+		return nil, nil
+	}
+	since := time.Now().UTC().Add(-time.Duration(mon.FlapWindowSeconds) * time.Second)
+	inc, err := m.store.GetRecentlyResolvedIncident(ctx, monitorID, since)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if inc.Severity != severity {
+		return nil, nil
+	}
+
+	inc.Status = StatusOpen
+	inc.ResolvedAt = nil
+	inc.ResolvedBy = ""
+	inc.AcknowledgedAt = nil
+	inc.AcknowledgedBy = ""
+	inc.AckDeadline = nil
+	if err := m.store.UpdateIncident(ctx, inc); err != nil {
+		return nil, err
+	}
+	m.store.InsertIncidentEvent(ctx, &storage.IncidentEvent{
+		IncidentID: inc.ID,
+		Type:       EventReoccurred,
+		Message:    "Monitor failed again within the flap window, incident reopened: " + cause,
+	})
+	m.logger.Info("incident reopened", "incident_id", inc.ID, "monitor_id", monitorID, "cause", cause)
+	return inc, nil
+}
+
+// supersede resolves a degraded incident that a hard failure replaces.
+func (m *Manager) supersede(ctx context.Context, inc *storage.Incident) error {
+	now := time.Now().UTC()
+	inc.Status = StatusResolved
+	inc.ResolvedAt = &now
+	inc.ResolvedBy = "auto"
+	inc.AckDeadline = nil
+	if err := m.store.UpdateIncident(ctx, inc); err != nil {
+		return err
+	}
+	m.store.InsertIncidentEvent(ctx, &storage.IncidentEvent{
+		IncidentID: inc.ID,
+		Type:       EventSuperseded,
+		Message:    "Monitor went down, superseded by a critical incident",
+	})
+	m.logger.Info("incident superseded", "incident_id", inc.ID, "monitor_id", inc.MonitorID)
+	return nil
+}
@@ -92,3 +92,154 @@ func TestIncidentLifecycle(t *testing.T) {
 		t.Fatal("expected no resolution")
 	}
 }
+
+func TestDegradedIncidentSuperseded(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	m := &storage.Monitor{
+		Name:              "Test",
+		Type:              "http",
+		Target:            "https://example.com",
+		Interval:          60,
+		Timeout:           10,
+		Enabled:           true,
+		Tags:              []string{},
+		FailureThreshold:  3,
+		SuccessThreshold:  1,
+		DegradedThreshold: 2,
+	}
+	store.CreateMonitor(ctx, m)
+
+	mgr := NewManager(store, logger)
+
+	warning, created, err := mgr.ProcessDegraded(ctx, m.ID, m.Name, "slow response")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || warning.Severity != SeverityWarning {
+		t.Fatalf("expected new warning incident, got created=%v severity=%q", created, warning.Severity)
+	}
+
+	// Further degraded checks stay on the warning incident
+	_, created, err = mgr.ProcessDegraded(ctx, m.ID, m.Name, "slow response")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Fatal("expected no new incident")
+	}
+
+	// A hard failure replaces it with a critical incident
+	critical, created, err := mgr.ProcessFailure(ctx, m.ID, m.Name, "connection refused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || critical.Severity != SeverityCritical || critical.ID == warning.ID {
+		t.Fatalf("expected new critical incident, got created=%v severity=%q", created, critical.Severity)
+	}
+
+	old, err := store.GetIncident(ctx, warning.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Status != StatusResolved {
+		t.Fatalf("expected superseded incident resolved, got %s", old.Status)
+	}
+	events, err := store.ListIncidentEvents(ctx, warning.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[len(events)-1].Type != EventSuperseded {
+		t.Fatalf("expected superseded event, got %s", events[len(events)-1].Type)
+	}
+
+	// Degraded checks while critical are recorded on the critical incident
+	inc, created, err := mgr.ProcessDegraded(ctx, m.ID, m.Name, "slow response")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || inc.ID != critical.ID {
+		t.Fatal("expected degraded check to join the critical incident")
+	}
+}
+
+func TestFlapWindowReopensIncident(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	m := &storage.Monitor{
+		Name:              "Test",
+		Type:              "http",
+		Target:            "https://example.com",
+		Interval:          60,
+		Timeout:           10,
+		Enabled:           true,
+		Tags:              []string{},
+		FailureThreshold:  1,
+		SuccessThreshold:  1,
+		FlapWindowSeconds: 300,
+	}
+	store.CreateMonitor(ctx, m)
+
+	mgr := NewManager(store, logger)
+
+	first, _, err := mgr.ProcessFailure(ctx, m.ID, m.Name, "connection refused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := mgr.ProcessRecovery(ctx, m.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Failing again within the window reopens the resolved incident
+	inc, created, err := mgr.ProcessFailure(ctx, m.ID, m.Name, "connection refused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || inc.ID != first.ID {
+		t.Fatalf("expected incident %d reopened, got %d (created=%v)", first.ID, inc.ID, created)
+	}
+	got, err := store.GetIncident(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusOpen || got.ResolvedAt != nil {
+		t.Fatalf("expected reopened incident, got status %s", got.Status)
+	}
+	events, err := store.ListIncidentEvents(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[len(events)-1].Type != EventReoccurred {
+		t.Fatalf("expected reoccurred event, got %s", events[len(events)-1].Type)
+	}
+
+	// A degraded warning does not reopen a critical incident
+	if _, _, err := mgr.ProcessRecovery(ctx, m.ID); err != nil {
+		t.Fatal(err)
+	}
+	warning, created, err := mgr.ProcessDegraded(ctx, m.ID, m.Name, "slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || warning.ID == first.ID {
+		t.Fatal("expected a new warning incident")
+	}
+
+	// Outside the window a new incident is created
+	mgr.ProcessRecovery(ctx, m.ID)
+	m.FlapWindowSeconds = 0
+	if err := store.UpdateMonitor(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	_, created, err = mgr.ProcessFailure(ctx, m.ID, m.Name, "connection refused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created {
+		t.Fatal("expected a new incident without a flap window")
+	}
+}
@@ -0,0 +1,102 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// BaselineWorker periodically recomputes the hourly response-time baselines
+// of monitors that have latency anomaly detection enabled.
+type BaselineWorker struct {
+	store    storage.Store
+	interval time.Duration
+	days     int
+	logger   *slog.Logger
+}
+
+// NewBaselineWorker creates a worker that rebuilds baselines from the last
+// days of check results every interval.
+func NewBaselineWorker(store storage.Store, interval time.Duration, days int, logger *slog.Logger) *BaselineWorker {
+	return &BaselineWorker{
+		store:    store,
+		interval: interval,
+		days:     days,
+		logger:   logger,
+	}
+}
+
+// Run starts the baseline worker loop.
+func (w *BaselineWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	// Run once on startup
+	w.recompute(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.recompute(ctx)
+		}
+	}
+}
+
+func (w *BaselineWorker) recompute(ctx context.Context) {
+	monitors, err := w.store.GetAllEnabledMonitors(ctx)
+	if err != nil {
+		w.logger.Error("baseline worker: list monitors", "error", err)
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -w.days)
+	for _, m := range monitors {
+		if m.LatencyBaselineSigma <= 0 {
+			continue
+		}
+		if err := w.store.RecomputeLatencyBaselines(ctx, m.ID, since); err != nil {
+			w.logger.Error("baseline worker: recompute", "monitor_id", m.ID, "error", err)
+		}
+	}
+}
+
+// baselineThreshold returns the response time above which a check deviates
+// from b by more than sigma standard deviations. The deviation is floored at
+// 5% of the mean (and 1ms) so near-constant latencies don't flag jitter.
+func baselineThreshold(b *storage.LatencyBaseline, sigma float64) float64 {
+	spread := math.Max(b.StdDev, math.Max(b.Mean*0.05, 1))
+	return b.Mean + sigma*spread
+}
+
+// latencyAnomaly reports whether a successful check's response time deviates
+// from the monitor's baseline for the current hour. On an anomaly the reason
+// is appended to the result message.
+func (p *Pipeline) latencyAnomaly(ctx context.Context, mon *storage.Monitor, result *checker.Result, at time.Time) bool {
+	if mon.LatencyBaselineSigma <= 0 || mon.UpsideDown {
+		return false
+	}
+	b, err := p.store.GetLatencyBaseline(ctx, mon.ID, at.UTC().Hour())
+	if err != nil || b.Samples < int64(p.baselineMinSamples) {
+		return false
+	}
+	threshold := baselineThreshold(b, mon.LatencyBaselineSigma)
+	if float64(result.ResponseTime) <= threshold {
+		return false
+	}
+
+	msg := fmt.Sprintf("response time %dms exceeds baseline %.0fms (threshold %.0fms)",
+		result.ResponseTime, b.Mean, threshold)
+	if result.Message == "" {
+		result.Message = msg
+	} else {
+		result.Message += "; " + msg
+	}
+	return true
+}
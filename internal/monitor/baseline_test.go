@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestBaselineThreshold(t *testing.T) {
+	tests := []struct {
+		name  string
+		b     storage.LatencyBaseline
+		sigma float64
+		want  float64
+	}{
+		{"uses stddev", storage.LatencyBaseline{Mean: 100, StdDev: 20}, 3, 160},
+		{"floors stddev at 5% of mean", storage.LatencyBaseline{Mean: 200, StdDev: 1}, 2, 220},
+		{"floors stddev at 1ms", storage.LatencyBaseline{Mean: 10, StdDev: 0}, 3, 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := baselineThreshold(&tt.b, tt.sigma); got != tt.want {
+				t.Errorf("baselineThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatencyAnomaly(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{
+		Name: "Baseline HTTP", Type: "http", Target: "https://example.com",
+		Interval: 60, Timeout: 10, Enabled: true,
+		FailureThreshold: 3, SuccessThreshold: 1,
+		LatencyBaselineSigma: 3,
+	}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	for _, rt := range []int64{90, 100, 110, 100} {
+		store.InsertCheckResult(ctx, &storage.CheckResult{MonitorID: mon.ID, Status: "up", ResponseTime: rt})
+	}
+	if err := store.RecomputeLatencyBaselines(ctx, mon.ID, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+	p.SetBaselineMinSamples(4)
+
+	t.Run("within baseline stays up", func(t *testing.T) {
+		p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "up", ResponseTime: 105}})
+		status, _ := store.GetMonitorStatus(ctx, mon.ID)
+		if status.Status != "up" {
+			t.Fatalf("expected up, got %s", status.Status)
+		}
+	})
+
+	t.Run("deviation marks degraded and notifies once", func(t *testing.T) {
+		var result *checker.Result
+		for i := 0; i < 2; i++ {
+			result = &checker.Result{Status: "up", ResponseTime: 900}
+			p.handleResult(ctx, WorkerResult{Monitor: mon, Result: result})
+		}
+		status, _ := store.GetMonitorStatus(ctx, mon.ID)
+		if status.Status != "degraded" {
+			t.Fatalf("expected degraded, got %s", status.Status)
+		}
+		if !strings.Contains(result.Message, "exceeds baseline") {
+			t.Errorf("expected baseline message, got %q", result.Message)
+		}
+
+		var anomalies int
+		for len(p.notifyChan) > 0 {
+			if ev := <-p.notifyChan; ev.EventType == "monitor.latency_anomaly" {
+				anomalies++
+			}
+		}
+		if anomalies != 1 {
+			t.Errorf("expected 1 latency anomaly event, got %d", anomalies)
+		}
+	})
+
+	t.Run("too few samples is ignored", func(t *testing.T) {
+		p.SetBaselineMinSamples(100)
+		result := &checker.Result{Status: "up", ResponseTime: 900}
+		if p.latencyAnomaly(ctx, mon, result, time.Now()) {
+			t.Error("expected no anomaly below the minimum sample count")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// expireAcks reopens acknowledged incidents whose ack_deadline has passed
+// without a resolution. Reopened incidents are picked up again by escalate
+// and by the reminder logic in shouldResend.
+func (p *Pipeline) expireAcks(ctx context.Context, now time.Time) {
+	expired, err := p.store.ListExpiredAcks(ctx, now)
+	if err != nil {
+		p.logger.Error("list expired acks", "error", err)
+		return
+	}
+
+	for _, inc := range expired {
+		by := inc.AcknowledgedBy
+		inc.Status = incident.StatusOpen
+		inc.AcknowledgedAt = nil
+		inc.AcknowledgedBy = ""
+		inc.AckDeadline = nil
+		if err := p.store.UpdateIncident(ctx, inc); err != nil {
+			p.logger.Error("reopen incident after ack expiry", "incident_id", inc.ID, "error", err)
+			continue
+		}
+
+		msg := "Acknowledgement expired"
+		if by != "" {
+			msg = "Acknowledgement by " + by + " expired"
+		}
+		if err := p.store.InsertIncidentEvent(ctx, &storage.IncidentEvent{
+			IncidentID: inc.ID,
+			Type:       incident.EventAckExpired,
+			Message:    msg,
+		}); err != nil {
+			p.logger.Error("insert ack expired event", "incident_id", inc.ID, "error", err)
+		}
+		p.logger.Info("incident acknowledgement expired", "incident_id", inc.ID, "monitor_id", inc.MonitorID)
+	}
+}
+
+// escalate notifies the next step of each open incident's escalation policy
+// once that step's delay has elapsed. Acknowledged incidents are not listed,
+// so acknowledging stops escalation until the acknowledgement expires (see
+// expireAcks). At most one step fires per incident per
+// call; each one is recorded as an "escalated" incident event, which is also
+// how progress is tracked across restarts.
+func (p *Pipeline) escalate(ctx context.Context, now time.Time) {
+	pending, err := p.store.ListPendingEscalations(ctx)
+	if err != nil {
+		p.logger.Error("list pending escalations", "error", err)
+		return
+	}
+
+	policies := make(map[int64]*storage.EscalationPolicy)
+	for _, pe := range pending {
+		policy, ok := policies[pe.PolicyID]
+		if !ok {
+			policy, err = p.store.GetEscalationPolicy(ctx, pe.PolicyID)
+			if err != nil {
+				p.logger.Error("get escalation policy", "policy_id", pe.PolicyID, "error", err)
+				continue
+			}
+			policies[pe.PolicyID] = policy
+		}
+		if pe.Escalated >= len(policy.Steps) {
+			continue
+		}
+		step := policy.Steps[pe.Escalated]
+
+		inc, err := p.store.GetIncident(ctx, pe.IncidentID)
+		if err != nil || inc.Status != incident.StatusOpen {
+			continue
+		}
+		if now.Sub(inc.StartedAt) < time.Duration(step.DelayMinutes)*time.Minute {
+			continue
+		}
+		if inMaintenance, _ := p.store.IsMonitorInMaintenance(ctx, pe.MonitorID, now); inMaintenance {
+			continue
+		}
+		mon, err := p.store.GetMonitor(ctx, pe.MonitorID)
+		if err != nil {
+			p.logger.Error("get monitor for escalation", "monitor_id", pe.MonitorID, "error", err)
+			continue
+		}
+
+		if err := p.store.InsertIncidentEvent(ctx, &storage.IncidentEvent{
+			IncidentID: inc.ID,
+			Type:       incident.EventEscalated,
+			Message:    fmt.Sprintf("Escalated to step %d of %s", pe.Escalated+1, policy.Name),
+		}); err != nil {
+			p.logger.Error("insert escalation event", "incident_id", inc.ID, "error", err)
+			continue
+		}
+		p.logger.Info("incident escalated", "incident_id", inc.ID, "policy_id", policy.ID, "step", pe.Escalated+1)
+		p.publish(NotificationEvent{
+			EventType:  "incident.escalated",
+			Severity:   "critical",
+			MonitorID:  mon.ID,
+			Incident:   inc,
+			Monitor:    mon,
+			ChannelIDs: step.ChannelIDs,
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/storage"
+)
+
+const failureContextDNSTimeout = 5 * time.Second
+
+// FailureContext is the evidence stored with the check that transitions a
+// monitor to down when CaptureFailureContext is enabled.
+type FailureContext struct {
+	CapturedAt time.Time           `json:"captured_at"`
+	Host       string              `json:"host,omitempty"`
+	Addresses  []string            `json:"addresses,omitempty"`
+	DNSError   string              `json:"dns_error,omitempty"`
+	StatusCode int                 `json:"status_code,omitempty"`
+	Headers    map[string]string   `json:"headers,omitempty"`
+	Body       string              `json:"body,omitempty"`
+	TLS        *checker.TLSDetails `json:"tls,omitempty"`
+}
+
+// hostLookup is a resolution of a monitor's target host. Workers make it
+// when a check with CaptureFailureContext fails, so the serial result
+// pipeline never waits on DNS.
+type hostLookup struct {
+	Addresses []string
+	Err       string
+}
+
+// lookupTargetHost resolves the host of mon's target. It returns nil when
+// the target has no host or the host is an IP address.
+func lookupTargetHost(ctx context.Context, mon *storage.Monitor) *hostLookup {
+	host := targetHost(mon.Target)
+	if host == "" || net.ParseIP(host) != nil {
+		return nil
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, failureContextDNSTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(lookupCtx, host)
+	l := &hostLookup{Addresses: addrs}
+	if err != nil {
+		l.Err = err.Error()
+	}
+	return l
+}
+
+// captureFailureContext collects the checker output together with the
+// worker's resolution of the target host and returns it JSON encoded. body
+// is the response body as stored with the check, capped and redacted.
+func captureFailureContext(mon *storage.Monitor, result *checker.Result, body string, lookup *hostLookup) string {
+	fc := FailureContext{
+		CapturedAt: time.Now().UTC(),
+		Host:       targetHost(mon.Target),
+		StatusCode: result.StatusCode,
+		Headers:    storedHeaders(mon, result.Headers),
+		Body:       body,
+		TLS:        result.TLS,
+	}
+
+	if ip := net.ParseIP(fc.Host); ip != nil {
+		fc.Addresses = []string{ip.String()}
+	} else if lookup != nil {
+		fc.Addresses = lookup.Addresses
+		fc.DNSError = lookup.Err
+	}
+
+	b, err := json.Marshal(fc)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// targetHost extracts the host name from a URL, host:port or bare host target.
+func targetHost(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return host
+	}
+	return target
+}
@@ -45,6 +45,10 @@ func (w *HeartbeatWatcher) Run(ctx context.Context) {
 }
 
 func (w *HeartbeatWatcher) check(ctx context.Context) {
+	now := time.Now()
+	w.pipeline.expireAcks(ctx, now)
+	w.pipeline.escalate(ctx, now)
+
 	expired, err := w.store.ListExpiredHeartbeats(ctx)
 	if err != nil {
 		w.logger.Error("heartbeat watcher: list expired", "error", err)
@@ -57,6 +61,17 @@ func (w *HeartbeatWatcher) check(ctx context.Context) {
 			continue
 		}
 
+		// A job stopped for planned maintenance is expected to miss pings.
+		// Restarting the deadline gives it a full grace period once the
+		// window ends, instead of expiring on the ping from before it.
+		inMaintenance, _ := w.store.IsMonitorInMaintenance(ctx, hb.MonitorID, time.Now())
+		if inMaintenance {
+			if err := w.store.RestartHeartbeatDeadline(ctx, hb.MonitorID); err != nil {
+				w.logger.Error("heartbeat watcher: restart deadline", "error", err)
+			}
+			continue
+		}
+
 		w.logger.Info("heartbeat expired", "monitor_id", hb.MonitorID)
 
 		// Mark heartbeat as down
@@ -70,6 +85,9 @@ func (w *HeartbeatWatcher) check(ctx context.Context) {
 		}
 
 		// Update monitor status to down
+		if prev, err := w.store.GetMonitorStatus(ctx, mon.ID); err == nil && prev.Status != "" && prev.Status != "down" {
+			w.pipeline.recordTransition(ctx, mon.ID, prev.Status, "down", "heartbeat missed")
+		}
 		now := time.Now()
 		status := &storage.MonitorStatus{
 			MonitorID:   mon.ID,
@@ -81,14 +99,18 @@ func (w *HeartbeatWatcher) check(ctx context.Context) {
 			w.logger.Error("heartbeat watcher: upsert status", "error", err)
 		}
 
+		if w.pipeline.parentDown(ctx, mon) {
+			continue
+		}
+		w.pipeline.emitNotification("monitor.down", nil, mon, nil)
+
 		// Create incident through the incident manager
-		inMaintenance, _ := w.store.IsMonitorInMaintenance(ctx, mon.ID, now)
 		inc, created, err := w.incMgr.ProcessFailure(ctx, mon.ID, mon.Name, "heartbeat missed")
 		if err != nil {
 			w.logger.Error("heartbeat watcher: process failure", "error", err)
 			continue
 		}
-		if created && !inMaintenance {
+		if created {
 			w.pipeline.emitNotification("incident.created", inc, mon, nil)
 			w.pipeline.lastNotified.Store(mon.ID, time.Now())
 		}
@@ -100,7 +122,7 @@ func (w *HeartbeatWatcher) resendIfNeeded(ctx context.Context, hb *storage.Heart
 	if err != nil || mon.ResendInterval <= 0 {
 		return
 	}
-	if !w.pipeline.shouldResend(mon) {
+	if !w.pipeline.shouldResend(mon) || w.pipeline.parentDown(ctx, mon) {
 		return
 	}
 	inMaintenance, _ := w.store.IsMonitorInMaintenance(ctx, mon.ID, time.Now())
@@ -108,7 +130,7 @@ func (w *HeartbeatWatcher) resendIfNeeded(ctx context.Context, hb *storage.Heart
 		return
 	}
 	inc, err := w.store.GetOpenIncident(ctx, mon.ID)
-	if err != nil || inc == nil {
+	if err != nil || inc == nil || w.pipeline.remindersSilenced(mon, inc) {
 		return
 	}
 	w.pipeline.emitNotification("incident.reminder", inc, mon, nil)
@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestHeartbeatWatcherSkipsMaintenanceAndPaused(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	newHeartbeat := func(name, token string) *storage.Monitor {
+		t.Helper()
+		mon := &storage.Monitor{
+			Name: name, Type: "heartbeat", Target: "heartbeat",
+			Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1,
+		}
+		if err := store.CreateMonitor(ctx, mon); err != nil {
+			t.Fatal(err)
+		}
+		// A negative grace makes a fresh ping already overdue.
+		hb := &storage.Heartbeat{MonitorID: mon.ID, Token: token, Grace: -120, Status: "pending"}
+		if err := store.CreateHeartbeat(ctx, hb); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.UpdateHeartbeatPing(ctx, token); err != nil {
+			t.Fatal(err)
+		}
+		return mon
+	}
+	maint := newHeartbeat("nightly backup", "maint-token")
+	paused := newHeartbeat("weekly report", "paused-token")
+	if err := store.PauseHeartbeat(ctx, "paused-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	mw := &storage.MaintenanceWindow{
+		Name:       "backup host upgrade",
+		MonitorIDs: []int64{maint.ID},
+		StartTime:  time.Now().Add(-time.Hour),
+		EndTime:    time.Now().Add(time.Hour),
+	}
+	if err := store.CreateMaintenanceWindow(ctx, mw); err != nil {
+		t.Fatal(err)
+	}
+
+	incMgr := incident.NewManager(store, logger)
+	p := NewPipeline(store, checker.NewRegistry(), incMgr, 1, false, logger)
+	w := NewHeartbeatWatcher(store, incMgr, p, time.Minute, logger)
+
+	w.check(ctx)
+	for _, mon := range []*storage.Monitor{maint, paused} {
+		hb, _ := store.GetHeartbeatByMonitorID(ctx, mon.ID)
+		if hb.Status == "down" {
+			t.Errorf("%s: marked down", mon.Name)
+		}
+		if inc, _ := store.GetOpenIncident(ctx, mon.ID); inc != nil {
+			t.Errorf("%s: incident opened", mon.Name)
+		}
+	}
+	if hb, _ := store.GetHeartbeatByMonitorID(ctx, maint.ID); hb.Status != "pending" {
+		t.Errorf("expected maintenance to restart the deadline, got status %s", hb.Status)
+	}
+
+	if err := store.DeleteMaintenanceWindow(ctx, mw.ID); err != nil {
+		t.Fatal(err)
+	}
+	w.check(ctx)
+	hb, _ := store.GetHeartbeatByMonitorID(ctx, maint.ID)
+	if hb.Status != "down" {
+		t.Fatalf("expected down after maintenance ended, got %s", hb.Status)
+	}
+	if inc, _ := store.GetOpenIncident(ctx, maint.ID); inc == nil {
+		t.Fatal("expected an incident after maintenance ended")
+	}
+	if len(p.notifyChan) != 2 {
+		t.Fatalf("expected monitor.down and incident.created, got %d events", len(p.notifyChan))
+	}
+	if hb, _ := store.GetHeartbeatByMonitorID(ctx, paused.ID); hb.Status != "paused" {
+		t.Fatalf("expected paused heartbeat untouched, got %s", hb.Status)
+	}
+}
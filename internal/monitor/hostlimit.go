@@ -0,0 +1,179 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// hostIdleAfter is how long a host nobody checks is remembered, so hosts no
+// longer monitored are eventually forgotten.
+const hostIdleAfter = 10 * time.Minute
+
+// hostLimiter caps how many checks run against one target host at a time,
+// and how soon after one another they may start, across all monitors.
+type hostLimiter struct {
+	maxConcurrent int
+	minInterval   time.Duration
+	maxWait       time.Duration
+
+	mu        sync.Mutex
+	hosts     map[string]*hostState
+	lastPrune time.Time
+
+	queued  atomic.Int64 // checks that had to wait for their host
+	dropped atomic.Int64 // checks skipped after waiting maxWait
+}
+
+type hostState struct {
+	slots    chan struct{} // one entry per running check; nil without a concurrency cap
+	next     time.Time     // earliest start of the next check
+	users    int           // checks running or waiting
+	lastUsed time.Time
+}
+
+// newHostLimiter returns a limiter whose checks wait at most maxWait for their
+// host's turn before they are skipped until their next scheduled run. A zero
+// maxWait keeps them waiting until the host is free.
+func newHostLimiter(maxConcurrent int, minInterval, maxWait time.Duration) *hostLimiter {
+	return &hostLimiter{
+		maxConcurrent: maxConcurrent,
+		minInterval:   minInterval,
+		maxWait:       maxWait,
+		hosts:         make(map[string]*hostState),
+	}
+}
+
+// checkHost returns the host a monitor's checks connect to, or "" for types
+// that don't reach a remote host.
+func checkHost(mon *storage.Monitor) string {
+	switch mon.Type {
+	case "heartbeat", "command", "docker":
+		return ""
+	}
+	return strings.ToLower(targetHost(mon.Target))
+}
+
+// acquire waits for host's turn and returns a func that ends it. It returns
+// false, without a release func, when ctx ends or the turn doesn't come
+// within l.maxWait.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), bool) {
+	if host == "" || (l.maxConcurrent <= 0 && l.minInterval <= 0) {
+		return func() {}, true
+	}
+	var deadline time.Time // zero: wait as long as it takes
+	if l.maxWait > 0 {
+		deadline = time.Now().Add(l.maxWait)
+	}
+	h := l.enter(host)
+
+	queued := false
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+		default:
+			queued = true
+			l.queued.Add(1)
+			var timeout <-chan time.Time
+			if !deadline.IsZero() {
+				t := time.NewTimer(time.Until(deadline))
+				defer t.Stop()
+				timeout = t.C
+			}
+			select {
+			case h.slots <- struct{}{}:
+			case <-timeout:
+				l.dropped.Add(1)
+				l.leave(h)
+				return nil, false
+			case <-ctx.Done():
+				l.leave(h)
+				return nil, false
+			}
+		}
+	}
+	release := func() {
+		if h.slots != nil {
+			<-h.slots
+		}
+		l.leave(h)
+	}
+
+	if l.minInterval > 0 {
+		// Each check reserves its start time, so waiting checks start
+		// minInterval apart in arrival order.
+		l.mu.Lock()
+		start := time.Now()
+		if h.next.After(start) {
+			start = h.next
+		}
+		ok := deadline.IsZero() || !start.After(deadline)
+		if ok {
+			h.next = start.Add(l.minInterval)
+		}
+		l.mu.Unlock()
+		if !ok {
+			l.dropped.Add(1)
+			release()
+			return nil, false
+		}
+		if wait := time.Until(start); wait > 0 {
+			if !queued {
+				l.queued.Add(1)
+			}
+			if !sleepCtx(ctx, wait) {
+				release()
+				return nil, false
+			}
+		}
+	}
+	return release, true
+}
+
+func (l *hostLimiter) enter(host string) *hostState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if now.Sub(l.lastPrune) >= hostIdleAfter {
+		l.prune(now)
+		l.lastPrune = now
+	}
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostState{}
+		if l.maxConcurrent > 0 {
+			h.slots = make(chan struct{}, l.maxConcurrent)
+		}
+		l.hosts[host] = h
+	}
+	h.users++
+	h.lastUsed = now
+	return h
+}
+
+func (l *hostLimiter) leave(h *hostState) {
+	l.mu.Lock()
+	h.users--
+	h.lastUsed = time.Now()
+	l.mu.Unlock()
+}
+
+// prune forgets hosts that have been idle for hostIdleAfter. l.mu must be held.
+func (l *hostLimiter) prune(now time.Time) {
+	for host, h := range l.hosts {
+		if h.users == 0 && now.Sub(h.lastUsed) >= hostIdleAfter && !h.next.After(now) {
+			delete(l.hosts, host)
+		}
+	}
+}
+
+// size returns the number of hosts currently tracked.
+func (l *hostLimiter) size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.hosts)
+}
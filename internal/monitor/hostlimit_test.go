@@ -0,0 +1,179 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		typ, target, want string
+	}{
+		{"http", "https://API.example.com:8443/health", "api.example.com"},
+		{"tcp", "db.internal:5432", "db.internal"},
+		{"icmp", "192.0.2.1", "192.0.2.1"},
+		{"tls", "[2001:db8::1]:443", "2001:db8::1"},
+		{"command", "/usr/bin/check", ""},
+		{"heartbeat", "", ""},
+	}
+	for _, tt := range tests {
+		if got := checkHost(&storage.Monitor{Type: tt.typ, Target: tt.target}); got != tt.want {
+			t.Errorf("checkHost(%s %q) = %q, want %q", tt.typ, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestHostLimiterConcurrency(t *testing.T) {
+	l := newHostLimiter(1, 0, 10*time.Second)
+	ctx := context.Background()
+
+	release, ok := l.acquire(ctx, "a.example.com")
+	if !ok {
+		t.Fatal("expected the first check to start")
+	}
+	if other, ok := l.acquire(ctx, "b.example.com"); !ok {
+		t.Fatal("expected another host to be unaffected")
+	} else {
+		other()
+	}
+
+	started := make(chan struct{})
+	go func() {
+		r, ok := l.acquire(ctx, "a.example.com")
+		if ok {
+			r()
+		}
+		close(started)
+	}()
+	select {
+	case <-started:
+		t.Fatal("second check started while the host was busy")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("second check did not start after release")
+	}
+	if q, d := l.queued.Load(), l.dropped.Load(); q != 1 || d != 0 {
+		t.Fatalf("queued = %d, dropped = %d, want 1 and 0", q, d)
+	}
+}
+
+func TestHostLimiterMinInterval(t *testing.T) {
+	l := newHostLimiter(0, 40*time.Millisecond, 10*time.Second)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, ok := l.acquire(ctx, "api.example.com")
+		if !ok {
+			t.Fatalf("check %d was skipped", i)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("3 checks started within %s, want them 40ms apart", elapsed)
+	}
+	if q := l.queued.Load(); q != 2 {
+		t.Fatalf("queued = %d, want 2", q)
+	}
+}
+
+func TestHostLimiterDrops(t *testing.T) {
+	ctx := context.Background()
+
+	busy := newHostLimiter(1, 0, 30*time.Millisecond)
+	release, _ := busy.acquire(ctx, "api.example.com")
+	if _, ok := busy.acquire(ctx, "api.example.com"); ok {
+		t.Fatal("expected the check to be skipped while the host stayed busy")
+	}
+	release()
+	if d := busy.dropped.Load(); d != 1 {
+		t.Fatalf("dropped = %d, want 1", d)
+	}
+	if r, ok := busy.acquire(ctx, "api.example.com"); !ok {
+		t.Fatal("expected the slot back after a skipped check")
+	} else {
+		r()
+	}
+
+	spaced := newHostLimiter(0, time.Minute, 30*time.Millisecond)
+	release, _ = spaced.acquire(ctx, "api.example.com")
+	release()
+	if _, ok := spaced.acquire(ctx, "api.example.com"); ok {
+		t.Fatal("expected the check to be skipped instead of waiting a minute")
+	}
+	if d := spaced.dropped.Load(); d != 1 {
+		t.Fatalf("dropped = %d, want 1", d)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	release, _ = busy.acquire(ctx, "api.example.com")
+	defer release()
+	if _, ok := busy.acquire(cancelled, "api.example.com"); ok {
+		t.Fatal("expected a cancelled wait to fail")
+	}
+	if d := busy.dropped.Load(); d != 1 {
+		t.Fatalf("a cancelled wait counted as dropped: %d", d)
+	}
+}
+
+func TestHostLimiterWaitsWithoutMaxWait(t *testing.T) {
+	ctx := context.Background()
+
+	busy := newHostLimiter(1, 0, 0)
+	release, _ := busy.acquire(ctx, "api.example.com")
+	time.AfterFunc(100*time.Millisecond, release)
+	start := time.Now()
+	r, ok := busy.acquire(ctx, "api.example.com")
+	if !ok {
+		t.Fatal("expected the check to wait for the busy host")
+	}
+	r()
+	if waited := time.Since(start); waited < 90*time.Millisecond {
+		t.Fatalf("acquired after %v, before the host was free", waited)
+	}
+
+	spaced := newHostLimiter(0, 100*time.Millisecond, 0)
+	release, _ = spaced.acquire(ctx, "api.example.com")
+	release()
+	if r, ok := spaced.acquire(ctx, "api.example.com"); !ok {
+		t.Fatal("expected the check to wait for its start time")
+	} else {
+		r()
+	}
+	if d := busy.dropped.Load() + spaced.dropped.Load(); d != 0 {
+		t.Fatalf("dropped = %d, want 0", d)
+	}
+}
+
+func TestHostLimiterPrune(t *testing.T) {
+	l := newHostLimiter(2, 0, 10*time.Second)
+	ctx := context.Background()
+	for _, host := range []string{"old.example.com", "busy.example.com"} {
+		release, _ := l.acquire(ctx, host)
+		release()
+	}
+	hold, _ := l.acquire(ctx, "busy.example.com")
+	defer hold()
+
+	l.mu.Lock()
+	for _, h := range l.hosts {
+		h.lastUsed = time.Now().Add(-2 * hostIdleAfter)
+	}
+	l.prune(time.Now())
+	l.mu.Unlock()
+
+	if n := l.size(); n != 1 {
+		t.Fatalf("tracked hosts = %d, want only the busy one", n)
+	}
+	if _, ok := l.hosts["busy.example.com"]; !ok {
+		t.Fatal("pruned a host with a running check")
+	}
+}
@@ -3,9 +3,14 @@ package monitor
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -122,7 +127,7 @@ func TestBuildCheckResult(t *testing.T) {
 		DNSRecords:   []string{"1.2.3.4"},
 	}
 
-	cr := buildCheckResult(mon, result, "up")
+	cr := buildCheckResult(mon, result, "up", 1024)
 
 	if cr.MonitorID != 42 {
 		t.Fatalf("expected monitor_id 42, got %d", cr.MonitorID)
@@ -148,6 +153,60 @@ func TestBuildCheckResult(t *testing.T) {
 	if cr.Body != "<html>" {
 		t.Fatalf("expected body <html>, got %s", cr.Body)
 	}
+	if cr.ConfigHash == "" || len(cr.Config) == 0 {
+		t.Fatal("expected config snapshot to be set")
+	}
+}
+
+func TestBuildCheckResultHeaders(t *testing.T) {
+	mon := &storage.Monitor{
+		ID:             42,
+		Type:           "http",
+		Settings:       json.RawMessage(`{"headers":{"x-tenant-secret":"s3cr3t"}}`),
+		RedactPatterns: []string{`session=\w+`},
+	}
+	result := &checker.Result{
+		Status: "up",
+		Headers: map[string]string{
+			"Content-Type":    "text/html",
+			"Set-Cookie":      "id=1",
+			"Authorization":   "Bearer abc",
+			"X-Tenant-Secret": "s3cr3t",
+			"X-Debug":         "session=abc123",
+		},
+	}
+
+	cr := buildCheckResult(mon, result, "up", 1024)
+
+	var got map[string]string
+	if err := json.Unmarshal([]byte(cr.Headers), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"Content-Type": "text/html", "X-Debug": "***"}
+	if len(got) != len(want) {
+		t.Fatalf("stored headers = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("stored headers = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCheckConfigSnapshot(t *testing.T) {
+	mon := &storage.Monitor{Type: "http", Target: "https://example.com", Timeout: 10, Interval: 60}
+	_, h1 := CheckConfigSnapshot(mon)
+
+	mon.Interval = 300
+	mon.Name = "renamed"
+	if _, h := CheckConfigSnapshot(mon); h != h1 {
+		t.Fatal("interval and name should not affect the config hash")
+	}
+
+	mon.Settings = json.RawMessage(`{"method":"POST"}`)
+	if _, h := CheckConfigSnapshot(mon); h == h1 {
+		t.Fatal("settings change should affect the config hash")
+	}
 }
 
 func TestEmitNotification(t *testing.T) {
@@ -277,6 +336,158 @@ func TestHandleResult(t *testing.T) {
 	})
 }
 
+func TestHandleResultCertChanged(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	mon := &storage.Monitor{Name: "cert", Type: "tls", Target: "example.com:443", Interval: 60, Timeout: 10,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	logger := discardLogger()
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+
+	for _, fp := range []string{"aa11", "aa11", "bb22"} {
+		p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "up", CertFingerprint: fp}})
+	}
+	select {
+	case ev := <-p.notifyChan:
+		if ev.EventType != "cert.changed" {
+			t.Fatalf("event = %q, want cert.changed", ev.EventType)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected cert.changed event")
+	}
+	select {
+	case ev := <-p.notifyChan:
+		t.Fatalf("unexpected %s event", ev.EventType)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHandleResultCapturesFailureContext(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{
+		Name: "Capture", Type: "http", Target: "http://127.0.0.1:8080/health",
+		Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1,
+		CaptureFailureContext: true,
+	}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+	down := &checker.Result{
+		Status: "down", StatusCode: 503, Message: "expected status 200, got 503",
+		Headers: map[string]string{"Retry-After": "30"}, Body: "maintenance",
+	}
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "up", StatusCode: 200}})
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: down})
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: down})
+
+	list, err := store.ListCheckResults(ctx, mon.ID, storage.Pagination{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := list.Data.([]*storage.CheckResult)
+	captured := 0
+	for _, c := range checks {
+		full, err := store.GetCheckResult(ctx, c.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if full.FailureContext == "" {
+			continue
+		}
+		captured++
+		var fc FailureContext
+		if err := json.Unmarshal([]byte(full.FailureContext), &fc); err != nil {
+			t.Fatal(err)
+		}
+		if fc.Host != "127.0.0.1" || len(fc.Addresses) != 1 || fc.Headers["Retry-After"] != "30" || fc.Body != "maintenance" {
+			t.Errorf("unexpected failure context: %+v", fc)
+		}
+	}
+	if captured != 1 {
+		t.Fatalf("expected context on the first down check only, got %d of %d checks", captured, len(checks))
+	}
+}
+
+func TestCaptureFailureContextUsesWorkerLookup(t *testing.T) {
+	mon := &storage.Monitor{Type: "http", Target: "https://api.example.com/health"}
+	result := &checker.Result{Status: "down", Message: "timeout"}
+
+	var fc FailureContext
+	raw := captureFailureContext(mon, result, "", &hostLookup{Err: "no such host"})
+	if err := json.Unmarshal([]byte(raw), &fc); err != nil {
+		t.Fatal(err)
+	}
+	if fc.Host != "api.example.com" || fc.DNSError != "no such host" || len(fc.Addresses) != 0 {
+		t.Errorf("unexpected failure context: %+v", fc)
+	}
+
+	fc = FailureContext{}
+	raw = captureFailureContext(mon, result, "", &hostLookup{Addresses: []string{"192.0.2.10"}})
+	if err := json.Unmarshal([]byte(raw), &fc); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Addresses) != 1 || fc.Addresses[0] != "192.0.2.10" || fc.DNSError != "" {
+		t.Errorf("unexpected failure context: %+v", fc)
+	}
+}
+
+func TestHandleResultStreamsChecks(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{
+		Name: "Stream", Type: "http", Target: "https://example.com",
+		Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 5, SuccessThreshold: 1,
+		StreamChecksEvery: 3,
+	}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+	// pending->up streams, then every third check, then up->down streams.
+	for _, st := range []string{"up", "up", "up", "up", "down", "down"} {
+		p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: st, ResponseTime: 42}})
+	}
+
+	var streamed []string
+	for len(p.notifyChan) > 0 {
+		ev := <-p.notifyChan
+		if ev.EventType != "check.completed" {
+			continue
+		}
+		if ev.Check == nil || ev.Check.MonitorID != mon.ID || ev.Check.ResponseTime != 42 {
+			t.Fatalf("unexpected streamed check: %+v", ev.Check)
+		}
+		streamed = append(streamed, ev.Check.Status)
+	}
+	if strings.Join(streamed, ",") != "up,up,down" {
+		t.Errorf("streamed statuses = %v, want [up up down]", streamed)
+	}
+}
+
+func TestTargetHost(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com:8443/path": "example.com",
+		"db.internal:5432":              "db.internal",
+		"8.8.8.8":                       "8.8.8.8",
+	}
+	for target, want := range tests {
+		if got := targetHost(target); got != want {
+			t.Errorf("targetHost(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
 func TestProcessIncidents(t *testing.T) {
 	logger := discardLogger()
 	store := testStore(t)
@@ -376,6 +587,315 @@ func TestProcessIncidents(t *testing.T) {
 	})
 }
 
+func TestProcessIncidentsDegradedThreshold(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{
+		Name:              "Degraded Test",
+		Type:              "http",
+		Target:            "https://example.com",
+		Interval:          60,
+		Timeout:           10,
+		Enabled:           true,
+		FailureThreshold:  1,
+		SuccessThreshold:  1,
+		DegradedThreshold: 2,
+	}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+
+	p.processIncidents(ctx, mon, "degraded", &storage.MonitorStatus{MonitorID: mon.ID, ConsecDegraded: 1}, "slow")
+	if inc, _ := store.GetOpenIncident(ctx, mon.ID); inc != nil {
+		t.Fatal("expected no incident below the degraded threshold")
+	}
+
+	p.processIncidents(ctx, mon, "degraded", &storage.MonitorStatus{MonitorID: mon.ID, ConsecDegraded: 2}, "slow")
+	warning, err := store.GetOpenIncident(ctx, mon.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if warning.Severity != incident.SeverityWarning {
+		t.Fatalf("severity = %q, want warning", warning.Severity)
+	}
+	if warning.Cause != "Degraded: slow" {
+		t.Fatalf("cause = %q", warning.Cause)
+	}
+	select {
+	case ev := <-p.notifyChan:
+		if ev.Severity != incident.SeverityWarning {
+			t.Fatalf("event severity = %q, want warning", ev.Severity)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected notification event")
+	}
+
+	p.processIncidents(ctx, mon, "down", &storage.MonitorStatus{MonitorID: mon.ID, ConsecFails: 1}, "connection refused")
+	critical, err := store.GetOpenIncident(ctx, mon.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if critical.ID == warning.ID || critical.Severity != incident.SeverityCritical {
+		t.Fatalf("expected a new critical incident, got #%d %q", critical.ID, critical.Severity)
+	}
+	old, err := store.GetIncident(ctx, warning.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Status != incident.StatusResolved {
+		t.Fatalf("warning incident status = %q, want resolved", old.Status)
+	}
+	select {
+	case ev := <-p.notifyChan:
+		if ev.Severity != incident.SeverityCritical {
+			t.Fatalf("event severity = %q, want critical", ev.Severity)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected notification event")
+	}
+
+	// A degraded check joins the critical incident and reminds at its severity.
+	mon.ResendInterval = 60
+	p.lastNotified.Store(mon.ID, time.Now().Add(-time.Hour))
+	p.processIncidents(ctx, mon, "degraded", &storage.MonitorStatus{MonitorID: mon.ID, ConsecDegraded: 2}, "slow")
+	select {
+	case ev := <-p.notifyChan:
+		if ev.EventType != "incident.reminder" || ev.Severity != incident.SeverityCritical {
+			t.Fatalf("event %s severity = %q, want critical reminder", ev.EventType, ev.Severity)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected reminder event")
+	}
+}
+
+func TestComputeFinalStatusMaxResponseTime(t *testing.T) {
+	tests := []struct {
+		name        string
+		mon         storage.Monitor
+		result      checker.Result
+		wantStatus  string
+		wantMessage string
+	}{
+		{"disabled", storage.Monitor{}, checker.Result{Status: "up", ResponseTime: 5000}, "up", ""},
+		{"within limit", storage.Monitor{MaxResponseTimeMs: 500}, checker.Result{Status: "up", ResponseTime: 500}, "up", ""},
+		{"over limit", storage.Monitor{MaxResponseTimeMs: 500}, checker.Result{Status: "up", ResponseTime: 812}, "degraded", "response time 812ms exceeds 500ms"},
+		{"keeps message", storage.Monitor{MaxResponseTimeMs: 500}, checker.Result{Status: "up", ResponseTime: 812, Message: "connection successful"}, "degraded", "connection successful; response time 812ms exceeds 500ms"},
+		{"down stays down", storage.Monitor{MaxResponseTimeMs: 500}, checker.Result{Status: "down", ResponseTime: 812, Message: "timeout"}, "down", "timeout"},
+		{"upside down ignores it", storage.Monitor{MaxResponseTimeMs: 500, UpsideDown: true}, checker.Result{Status: "down", ResponseTime: 812}, "up", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeFinalStatus(&tt.mon, &tt.result)
+			if got != tt.wantStatus || tt.result.Message != tt.wantMessage {
+				t.Fatalf("got %q (%q), want %q (%q)", got, tt.result.Message, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestHandleResultSlowResponseOpensWarning(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{Name: "slow api", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1, DegradedThreshold: 2, MaxResponseTimeMs: 500}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+
+	for i := 0; i < 2; i++ {
+		p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "up", ResponseTime: 900}})
+	}
+	inc, err := store.GetOpenIncident(ctx, mon.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inc.Severity != incident.SeverityWarning || inc.Cause != "Degraded: response time 900ms exceeds 500ms" {
+		t.Fatalf("expected a warning incident for the slow responses, got %q %q", inc.Severity, inc.Cause)
+	}
+}
+
+func TestHandleResultSuppressedByParent(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	newMonitor := func(name string, parentID *int64) *storage.Monitor {
+		t.Helper()
+		mon := &storage.Monitor{Name: name, Type: "tcp", Target: "example.com:80", Interval: 60, Timeout: 10,
+			Enabled: true, FailureThreshold: 1, SuccessThreshold: 1, ParentID: parentID}
+		if err := store.CreateMonitor(ctx, mon); err != nil {
+			t.Fatal(err)
+		}
+		return mon
+	}
+	gateway := newMonitor("gateway", nil)
+	api := newMonitor("api", &gateway.ID)
+
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+	expectEvent := func(want string) {
+		t.Helper()
+		select {
+		case ev := <-p.notifyChan:
+			if ev.EventType != want {
+				t.Fatalf("event = %q, want %q", ev.EventType, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s event", want)
+		}
+	}
+	down := &checker.Result{Status: "down", Message: "connection refused"}
+	up := &checker.Result{Status: "up"}
+
+	p.handleResult(ctx, WorkerResult{Monitor: gateway, Result: down})
+	expectEvent("monitor.down")
+	expectEvent("incident.created")
+
+	cr := p.recordResult(ctx, WorkerResult{Monitor: api, Result: down})
+	if cr == nil || cr.Status != "down" || cr.Message != "connection refused; suppressed (parent down)" {
+		t.Fatalf("expected a stored, marked check, got %+v", cr)
+	}
+	if inc, _ := store.GetOpenIncident(ctx, api.ID); inc != nil {
+		t.Fatal("expected no child incident while the parent is down")
+	}
+	select {
+	case ev := <-p.notifyChan:
+		t.Fatalf("unexpected %s event for a suppressed child", ev.EventType)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	p.handleResult(ctx, WorkerResult{Monitor: gateway, Result: up})
+	expectEvent("monitor.recovered")
+	expectEvent("incident.resolved")
+
+	cr = p.recordResult(ctx, WorkerResult{Monitor: api, Result: down})
+	if cr.Message != "connection refused" {
+		t.Fatalf("message = %q, want it unmarked once the parent recovered", cr.Message)
+	}
+	if inc, err := store.GetOpenIncident(ctx, api.ID); err != nil || inc == nil {
+		t.Fatalf("expected a child incident after the parent recovered, got %v", err)
+	}
+	expectEvent("incident.created")
+
+	// A child incident opened before the parent went down still resolves,
+	// though its recovery isn't announced while the parent is down.
+	p.handleResult(ctx, WorkerResult{Monitor: gateway, Result: down})
+	expectEvent("monitor.down")
+	expectEvent("incident.created")
+	p.handleResult(ctx, WorkerResult{Monitor: api, Result: up})
+	expectEvent("incident.resolved")
+	if inc, _ := store.GetOpenIncident(ctx, api.ID); inc != nil {
+		t.Fatal("expected the child incident to resolve")
+	}
+}
+
+func TestHandleResultSuppressedDuringWarmup(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{Name: "fresh", Type: "tcp", Target: "example.com:80", Interval: 60, Timeout: 10,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1, WarmupSeconds: 300}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+	down := &checker.Result{Status: "down", Message: "connection refused"}
+
+	cr := p.recordResult(ctx, WorkerResult{Monitor: mon, Result: down})
+	if cr == nil || cr.Status != "down" || cr.Message != "connection refused; suppressed (warming up)" {
+		t.Fatalf("expected a stored, marked check, got %+v", cr)
+	}
+	if inc, _ := store.GetOpenIncident(ctx, mon.ID); inc != nil {
+		t.Fatal("expected no incident while warming up")
+	}
+	select {
+	case ev := <-p.notifyChan:
+		t.Fatalf("unexpected %s event while warming up", ev.EventType)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	mon.UpdatedAt = time.Now().Add(-301 * time.Second)
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: down})
+	if inc, err := store.GetOpenIncident(ctx, mon.ID); err != nil || inc == nil {
+		t.Fatalf("expected an incident once the warm-up ended, got %v", err)
+	}
+	for len(p.notifyChan) > 0 {
+		<-p.notifyChan
+	}
+
+	// Editing the monitor to fix it starts a new warm-up, but its recovery
+	// is still reported.
+	mon.UpdatedAt = time.Now()
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "up"}})
+	var events []string
+	for len(p.notifyChan) > 0 {
+		events = append(events, (<-p.notifyChan).EventType)
+	}
+	if !slices.Contains(events, "monitor.recovered") || !slices.Contains(events, "incident.resolved") {
+		t.Fatalf("events during warm-up = %v, want monitor.recovered and incident.resolved", events)
+	}
+}
+
+func TestTransitionEvent(t *testing.T) {
+	tests := []struct {
+		prev, next, want string
+	}{
+		{"up", "down", "monitor.down"},
+		{"degraded", "down", "monitor.down"},
+		{"", "down", "monitor.down"},
+		{"down", "down", ""},
+		{"down", "up", "monitor.recovered"},
+		{"down", "degraded", "monitor.recovered"},
+		{"up", "degraded", ""},
+		{"", "up", ""},
+	}
+	for _, tt := range tests {
+		if got := transitionEvent(tt.prev, tt.next); got != tt.want {
+			t.Errorf("transitionEvent(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+		}
+	}
+}
+
+func TestHandleResultTransitionEvents(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{Name: "api", Type: "tcp", Target: "example.com:80", Interval: 60, Timeout: 10,
+		Enabled: true, FailureThreshold: 3, SuccessThreshold: 1}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "up"}})
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "down", Message: "connection refused"}})
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "down", Message: "connection refused"}})
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "up"}})
+
+	var got []string
+	for len(p.notifyChan) > 0 {
+		ev := <-p.notifyChan
+		got = append(got, ev.EventType)
+		if ev.EventType == "monitor.down" && (ev.Check == nil || ev.Check.Message != "connection refused") {
+			t.Errorf("monitor.down carries check %+v, want the failing one", ev.Check)
+		}
+	}
+	if want := []string{"monitor.down", "monitor.recovered"}; !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v with no incident below the failure threshold", got, want)
+	}
+	if inc, _ := store.GetOpenIncident(ctx, mon.ID); inc != nil {
+		t.Fatal("expected no incident below the failure threshold")
+	}
+}
+
 func TestResendNotificationInterval(t *testing.T) {
 	logger := discardLogger()
 	store := testStore(t)
@@ -597,31 +1117,216 @@ func TestShouldResend(t *testing.T) {
 	})
 }
 
-func TestSchedulerDispatch(t *testing.T) {
+func TestRemindersSilenced(t *testing.T) {
+	logger := discardLogger()
 	store := testStore(t)
-	ctx := context.Background()
+	registry := checker.NewRegistry()
+	incMgr := incident.NewManager(store, logger)
+	p := NewPipeline(store, registry, incMgr, 1, false, logger)
+
+	yes, no := true, false
+	open := &storage.Incident{Status: incident.StatusOpen}
+	acked := &storage.Incident{Status: incident.StatusAcknowledged}
+
+	tests := []struct {
+		name          string
+		globalDefault bool
+		override      *bool
+		inc           *storage.Incident
+		want          bool
+	}{
+		{"open incident never silenced", true, &yes, open, false},
+		{"acked, default keeps reminding", false, nil, acked, false},
+		{"acked, default silences", true, nil, acked, true},
+		{"acked, monitor opts in", false, &yes, acked, true},
+		{"acked, monitor opts out", true, &no, acked, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.SetAckSilencesReminders(tt.globalDefault)
+			mon := &storage.Monitor{ID: 1, AckSilencesReminders: tt.override}
+			if got := p.remindersSilenced(mon, tt.inc); got != tt.want {
+				t.Errorf("remindersSilenced = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscalate(t *testing.T) {
 	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
 
-	// Create an enabled monitor
 	mon := &storage.Monitor{
-		Name:             "Scheduled",
-		Type:             "http",
-		Target:           "https://example.com",
-		Interval:         60,
-		Timeout:          10,
-		Enabled:          true,
-		FailureThreshold: 3,
-		SuccessThreshold: 1,
+		Name: "Escalating", Type: "http", Target: "https://example.com",
+		Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1,
 	}
 	if err := store.CreateMonitor(ctx, mon); err != nil {
 		t.Fatal(err)
 	}
+	policy := &storage.EscalationPolicy{Name: "tiers", Steps: []storage.EscalationStep{
+		{DelayMinutes: 15, ChannelIDs: []int64{2}},
+		{DelayMinutes: 45, ChannelIDs: []int64{3, 4}},
+	}}
+	if err := store.CreateEscalationPolicy(ctx, policy); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetMonitorEscalationPolicy(ctx, mon.ID, &policy.ID); err != nil {
+		t.Fatal(err)
+	}
 
-	// Create a heartbeat monitor (should be skipped)
-	hbMon := &storage.Monitor{
-		Name:             "Heartbeat",
-		Type:             "heartbeat",
-		Target:           "heartbeat",
+	incMgr := incident.NewManager(store, logger)
+	p := NewPipeline(store, checker.NewRegistry(), incMgr, 1, false, logger)
+	inc, _, err := incMgr.ProcessFailure(ctx, mon.ID, mon.Name, "timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drain := func() []NotificationEvent {
+		var evs []NotificationEvent
+		for len(p.notifyChan) > 0 {
+			evs = append(evs, <-p.notifyChan)
+		}
+		return evs
+	}
+
+	p.escalate(ctx, inc.StartedAt.Add(10*time.Minute))
+	if evs := drain(); len(evs) != 0 {
+		t.Fatalf("escalated before the first delay: %+v", evs)
+	}
+
+	p.escalate(ctx, inc.StartedAt.Add(20*time.Minute))
+	p.escalate(ctx, inc.StartedAt.Add(20*time.Minute))
+	evs := drain()
+	if len(evs) != 1 || evs[0].EventType != "incident.escalated" || fmt.Sprint(evs[0].ChannelIDs) != "[2]" {
+		t.Fatalf("first step: got %+v", evs)
+	}
+
+	p.escalate(ctx, inc.StartedAt.Add(50*time.Minute))
+	evs = drain()
+	if len(evs) != 1 || fmt.Sprint(evs[0].ChannelIDs) != "[3 4]" {
+		t.Fatalf("second step: got %+v", evs)
+	}
+
+	p.escalate(ctx, inc.StartedAt.Add(5*time.Hour))
+	if evs := drain(); len(evs) != 0 {
+		t.Fatalf("escalated past the last step: %+v", evs)
+	}
+
+	events, _ := store.ListIncidentEvents(ctx, inc.ID)
+	escalated := 0
+	for _, e := range events {
+		if e.Type == incident.EventEscalated {
+			escalated++
+		}
+	}
+	if escalated != 2 {
+		t.Errorf("escalated events = %d, want 2", escalated)
+	}
+}
+
+func TestEscalateStopsWhenAcknowledged(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{
+		Name: "Acked", Type: "http", Target: "https://example.com",
+		Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1,
+	}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	policy := &storage.EscalationPolicy{Name: "one", Steps: []storage.EscalationStep{{DelayMinutes: 5, ChannelIDs: []int64{1}}}}
+	store.CreateEscalationPolicy(ctx, policy)
+	store.SetMonitorEscalationPolicy(ctx, mon.ID, &policy.ID)
+
+	incMgr := incident.NewManager(store, logger)
+	p := NewPipeline(store, checker.NewRegistry(), incMgr, 1, false, logger)
+	inc, _, _ := incMgr.ProcessFailure(ctx, mon.ID, mon.Name, "timeout")
+	ackAt := time.Now()
+	inc.Status = incident.StatusAcknowledged
+	inc.AcknowledgedAt = &ackAt
+	inc.AcknowledgedBy = "alice"
+	if err := store.UpdateIncident(ctx, inc); err != nil {
+		t.Fatal(err)
+	}
+
+	p.escalate(ctx, inc.StartedAt.Add(time.Hour))
+	if len(p.notifyChan) != 0 {
+		t.Errorf("acknowledged incident escalated: %+v", <-p.notifyChan)
+	}
+}
+
+func TestExpireAcksReopensIncident(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{
+		Name: "Acked", Type: "http", Target: "https://example.com",
+		Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1,
+	}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+
+	incMgr := incident.NewManager(store, logger)
+	p := NewPipeline(store, checker.NewRegistry(), incMgr, 1, false, logger)
+	inc, _, _ := incMgr.ProcessFailure(ctx, mon.ID, mon.Name, "timeout")
+	ackAt := time.Now()
+	deadline := ackAt.Add(15 * time.Minute)
+	inc.Status = incident.StatusAcknowledged
+	inc.AcknowledgedAt = &ackAt
+	inc.AcknowledgedBy = "alice"
+	inc.AckDeadline = &deadline
+	if err := store.UpdateIncident(ctx, inc); err != nil {
+		t.Fatal(err)
+	}
+
+	p.expireAcks(ctx, ackAt.Add(10*time.Minute))
+	got, _ := store.GetIncident(ctx, inc.ID)
+	if got.Status != incident.StatusAcknowledged {
+		t.Fatalf("expired before deadline: status %s", got.Status)
+	}
+
+	p.expireAcks(ctx, ackAt.Add(20*time.Minute))
+	got, _ = store.GetIncident(ctx, inc.ID)
+	if got.Status != incident.StatusOpen || got.AckDeadline != nil || got.AcknowledgedAt != nil || got.AcknowledgedBy != "" {
+		t.Fatalf("expected reopened incident, got %+v", got)
+	}
+	events, _ := store.ListIncidentEvents(ctx, inc.ID)
+	last := events[len(events)-1]
+	if last.Type != incident.EventAckExpired || last.Message != "Acknowledgement by alice expired" {
+		t.Fatalf("unexpected last event: %+v", last)
+	}
+}
+
+func TestSchedulerDispatch(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	logger := discardLogger()
+
+	// Create an enabled monitor
+	mon := &storage.Monitor{
+		Name:             "Scheduled",
+		Type:             "http",
+		Target:           "https://example.com",
+		Interval:         60,
+		Timeout:          10,
+		Enabled:          true,
+		FailureThreshold: 3,
+		SuccessThreshold: 1,
+	}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a heartbeat monitor (should be skipped)
+	hbMon := &storage.Monitor{
+		Name:             "Heartbeat",
+		Type:             "heartbeat",
+		Target:           "heartbeat",
 		Interval:         60,
 		Timeout:          10,
 		Enabled:          true,
@@ -683,9 +1388,53 @@ func TestSchedulerDispatch(t *testing.T) {
 		if after <= before {
 			t.Fatal("expected dropped jobs to increment")
 		}
+		if fullSched.LastRun().IsZero() || !fullSched.LastDispatch().IsZero() {
+			t.Fatalf("last run = %v, last dispatch = %v, want a run but no dispatch", fullSched.LastRun(), fullSched.LastDispatch())
+		}
+	})
+
+	t.Run("records last run and dispatch", func(t *testing.T) {
+		now := time.Now().Add(20 * time.Minute)
+		s.dispatch(now)
+		if !s.LastRun().Equal(now) || !s.LastDispatch().Equal(now) {
+			t.Fatalf("last run = %v, last dispatch = %v, want %v", s.LastRun(), s.LastDispatch(), now)
+		}
 	})
 }
 
+func TestSchedulerEnqueueDropsOldest(t *testing.T) {
+	jobs := make(chan Job, 2)
+	s := NewScheduler(nil, jobs, discardLogger())
+
+	done := make(chan *storage.CheckResult, 1)
+	for i, j := range []Job{
+		{Monitor: &storage.Monitor{ID: 1}, Done: done},
+		{Monitor: &storage.Monitor{ID: 2}},
+		{Monitor: &storage.Monitor{ID: 3}},
+	} {
+		if !s.enqueue(j) {
+			t.Fatalf("job %d not queued", i+1)
+		}
+	}
+
+	if got := s.droppedJobs.Load(); got != 1 {
+		t.Fatalf("dropped = %d, want 1", got)
+	}
+	select {
+	case cr, ok := <-done:
+		if ok {
+			t.Fatalf("dropped job got result %+v, want Done closed", cr)
+		}
+	default:
+		t.Fatal("dropped job's Done was not signalled")
+	}
+	for _, want := range []int64{2, 3} {
+		if got := (<-jobs).Monitor.ID; got != want {
+			t.Fatalf("dequeued monitor %d, want %d", got, want)
+		}
+	}
+}
+
 func TestSchedulerHeapOrdering(t *testing.T) {
 	store := testStore(t)
 	ctx := context.Background()
@@ -755,6 +1504,42 @@ func TestSchedulerHeapOrdering(t *testing.T) {
 	}
 }
 
+func TestSchedulerSnapshot(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	logger := discardLogger()
+
+	for _, iv := range []int{300, 10, 60} {
+		mon := &storage.Monitor{
+			Name: fmt.Sprintf("Every %ds", iv), Type: "http", Target: "https://example.com",
+			Interval: iv, Timeout: 5, Enabled: true,
+			FailureThreshold: 3, SuccessThreshold: 1,
+		}
+		if err := store.CreateMonitor(ctx, mon); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	jobs := make(chan Job, 10)
+	s := NewScheduler(store, jobs, logger)
+	s.loadMonitors(ctx)
+	s.dispatch(time.Now().Add(time.Minute))
+
+	monitors, heapSize, next := s.Snapshot(2)
+	if monitors != 3 || heapSize != 3 {
+		t.Fatalf("expected 3 monitors in heap, got monitors=%d heap=%d", monitors, heapSize)
+	}
+	if len(next) != 2 {
+		t.Fatalf("expected 2 upcoming checks, got %d", len(next))
+	}
+	if next[0].Name != "Every 10s" || next[1].Name != "Every 60s" {
+		t.Errorf("expected checks ordered by due time, got %q then %q", next[0].Name, next[1].Name)
+	}
+	if next[0].Interval != 10 || next[0].Multiplier != 1 {
+		t.Errorf("unexpected interval %v / multiplier %v", next[0].Interval, next[0].Multiplier)
+	}
+}
+
 func TestSchedulerUpdateInterval(t *testing.T) {
 	store := testStore(t)
 	ctx := context.Background()
@@ -889,3 +1674,527 @@ func TestSchedulerReload(t *testing.T) {
 		t.Fatalf("expected empty heap after disabling only monitor, got %d entries", heapLen)
 	}
 }
+
+func TestSchedulerJitter(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{
+		Name: "Jittered", Type: "http", Target: "https://example.com",
+		Interval: 60, Timeout: 10, Enabled: true,
+		FailureThreshold: 3, SuccessThreshold: 1,
+	}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := make(chan Job, 1000)
+	s := NewScheduler(store, jobs, discardLogger())
+	s.SetJitter(10)
+	var draws []float64
+	s.randFloat = func() float64 {
+		v := draws[0]
+		draws = draws[1:]
+		return v
+	}
+
+	draws = []float64{0.5}
+	before := time.Now().UnixNano()
+	s.loadMonitors(ctx)
+	entry := s.entries[mon.ID]
+	load := entry.nextRun - entry.offset
+	if load < before || entry.offset != int64(3*time.Second) {
+		t.Fatalf("first run offset = %v, want 3s after load", time.Duration(entry.offset))
+	}
+
+	// Offsets are drawn around the unjittered schedule, load + k*interval,
+	// however late or early the previous run was.
+	iv := int64(60 * time.Second)
+	for k := int64(1); k <= 100; k++ {
+		draws = []float64{float64(k%4) / 4}
+		s.dispatch(time.Unix(0, entry.nextRun))
+		want := load + k*iv + int64((float64(k%4)/2-1)*float64(6*time.Second))
+		if entry.nextRun != want {
+			t.Fatalf("run %d: next run off by %v", k, time.Duration(entry.nextRun-want))
+		}
+	}
+	if len(jobs) != 100 {
+		t.Fatalf("expected 100 dispatched jobs, got %d", len(jobs))
+	}
+
+	t.Run("update interval rescales the pending offset", func(t *testing.T) {
+		base := entry.nextRun - entry.offset
+		draws = []float64{0}
+		s.UpdateInterval(mon.ID, 120*time.Second)
+		if entry.offset != -int64(12*time.Second) || entry.nextRun != base-int64(12*time.Second) {
+			t.Fatalf("offset = %v, want -12s from the same base", time.Duration(entry.offset))
+		}
+	})
+
+	t.Run("zero jitter runs on the interval", func(t *testing.T) {
+		s.SetJitter(0)
+		due := entry.nextRun - entry.offset
+		s.dispatch(time.Unix(0, entry.nextRun))
+		if entry.offset != 0 || entry.nextRun != due+int64(120*time.Second) {
+			t.Fatalf("next run = %v after the unjittered due time, want 2m", time.Duration(entry.nextRun-due))
+		}
+	})
+}
+
+// blockingChecker counts the checks running at once and holds each one
+// until release is closed.
+type blockingChecker struct {
+	running atomic.Int64
+	peak    atomic.Int64
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingChecker) Type() string { return "tcp" }
+
+func (c *blockingChecker) Check(ctx context.Context, mon *storage.Monitor) (*checker.Result, error) {
+	n := c.running.Add(1)
+	defer c.running.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	c.started <- struct{}{}
+	<-c.release
+	return &checker.Result{Status: "up"}, nil
+}
+
+func TestPipelineMaxInFlight(t *testing.T) {
+	c := &blockingChecker{started: make(chan struct{}, 10), release: make(chan struct{})}
+	registry := checker.NewRegistry()
+	registry.Register(c)
+	logger := discardLogger()
+	p := NewPipeline(nil, registry, nil, 4, false, logger)
+	p.SetMaxInFlight(2)
+	results := make(chan WorkerResult, 6)
+	p.pool.results = results
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.pool.Run(ctx)
+	for i := range 6 {
+		p.jobs <- Job{Monitor: &storage.Monitor{ID: int64(i + 1), Type: "tcp", Timeout: 5}}
+	}
+
+	for range 2 {
+		<-c.started
+	}
+	select {
+	case <-c.started:
+		t.Fatal("a third check started while two were running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got := len(p.jobs); got != 4 {
+		t.Errorf("queued = %d, want the other 4 jobs still queued", got)
+	}
+
+	close(c.release)
+	for range 6 {
+		<-results
+	}
+	if got := c.peak.Load(); got != 2 {
+		t.Fatalf("peak in flight = %d, want 2", got)
+	}
+}
+
+type flakyChecker struct {
+	failures int
+	calls    int
+}
+
+func (c *flakyChecker) Type() string { return "tcp" }
+
+func (c *flakyChecker) Check(ctx context.Context, mon *storage.Monitor) (*checker.Result, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return &checker.Result{Status: "down", Message: "connection refused"}, nil
+	}
+	return &checker.Result{Status: "up"}, nil
+}
+
+func TestPoolRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    int
+		retries     int
+		upsideDown  bool
+		wantStatus  string
+		wantCalls   int
+		wantRetries int
+	}{
+		{"no retries configured", 1, 0, false, "down", 1, 0},
+		{"recovers on retry", 2, 3, false, "up", 3, 2},
+		{"retries exhausted", 5, 2, false, "down", 3, 2},
+		{"up needs no retry", 0, 3, false, "up", 1, 0},
+		{"upside-down retries an up result", 0, 2, true, "up", 3, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &flakyChecker{failures: tt.failures}
+			registry := checker.NewRegistry()
+			registry.Register(c)
+			results := make(chan WorkerResult, 1)
+			pool := NewPool(1, registry, nil, results, discardLogger())
+
+			mon := &storage.Monitor{ID: 1, Type: "tcp", Timeout: 5, Retries: tt.retries, UpsideDown: tt.upsideDown}
+			pool.executeJob(context.Background(), Job{Monitor: mon})
+			wr := <-results
+
+			if wr.Result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", wr.Result.Status, tt.wantStatus)
+			}
+			if c.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", c.calls, tt.wantCalls)
+			}
+			if wr.Retries != tt.wantRetries {
+				t.Errorf("retries = %d, want %d", wr.Retries, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestPoolRetriesStopOnCancel(t *testing.T) {
+	c := &flakyChecker{failures: 10}
+	registry := checker.NewRegistry()
+	registry.Register(c)
+	results := make(chan WorkerResult, 1)
+	pool := NewPool(1, registry, nil, results, discardLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mon := &storage.Monitor{ID: 1, Type: "tcp", Timeout: 5, Retries: 5, RetryInterval: 30}
+	pool.executeJob(ctx, Job{Monitor: mon})
+	wr := <-results
+	if c.calls != 1 || wr.Retries != 0 {
+		t.Fatalf("expected no retries after cancel, got %d calls and %d retries", c.calls, wr.Retries)
+	}
+}
+
+func TestHandleResultNotesRetries(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	mon := &storage.Monitor{Name: "flaky", Type: "tcp", Target: "example.com:80", Interval: 60, Timeout: 10,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1, Retries: 2}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	logger := discardLogger()
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "down", Message: "connection refused"}, Retries: 2})
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "up"}, Retries: 1})
+
+	page, err := store.ListCheckResults(ctx, mon.ID, storage.Pagination{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, cr := range page.Data.([]*storage.CheckResult) {
+		got[cr.Status] = cr.Message
+	}
+	if got["down"] != "connection refused (after 2 retries)" {
+		t.Errorf("down message = %q", got["down"])
+	}
+	if got["up"] != "after 1 retry" {
+		t.Errorf("up message = %q", got["up"])
+	}
+}
+
+// erroringChecker fails every check with an error instead of a result.
+type erroringChecker struct{ calls int }
+
+func (c *erroringChecker) Type() string { return "tcp" }
+
+func (c *erroringChecker) Check(ctx context.Context, mon *storage.Monitor) (*checker.Result, error) {
+	c.calls++
+	return nil, errors.New("dial tcp: connection reset")
+}
+
+func TestRetryNoteWhenFinalAttemptErrors(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	mon := &storage.Monitor{Name: "erroring", Type: "tcp", Target: "example.com:80", Interval: 60, Timeout: 10,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1, Retries: 2}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	c := &erroringChecker{}
+	registry := checker.NewRegistry()
+	registry.Register(c)
+	logger := discardLogger()
+	p := NewPipeline(store, registry, incident.NewManager(store, logger), 1, false, logger)
+	results := make(chan WorkerResult, 1)
+	p.pool.results = results
+
+	p.pool.executeJob(ctx, Job{Monitor: mon})
+	wr := <-results
+	if c.calls != 3 || wr.Err == nil || wr.Retries != 2 {
+		t.Fatalf("calls = %d, err = %v, retries = %d; want 3 calls ending in an error after 2 retries", c.calls, wr.Err, wr.Retries)
+	}
+	cr := p.recordResult(ctx, wr)
+	if cr == nil || cr.Message != "dial tcp: connection reset (after 2 retries)" {
+		t.Fatalf("stored check = %+v, want the error with the retry note", cr)
+	}
+	if inc, _ := store.GetOpenIncident(ctx, mon.ID); inc == nil || inc.Cause != cr.Message {
+		t.Fatalf("incident = %+v, want its cause to carry the retry note", inc)
+	}
+}
+
+func TestHandleResultTracksSelectedContent(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	mon := &storage.Monitor{Name: "shop", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1, TrackChanges: true,
+		Settings: json.RawMessage(`{"content":{"selector":"#price"}}`)}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	logger := discardLogger()
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+
+	check := func(ad, price string) {
+		body := `<div class="ad">` + ad + `</div><p id="price">` + price + `</p>`
+		p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "up", Body: body, BodyHash: HashBody(body)}})
+	}
+	changes := func() []*storage.ContentChange {
+		page, err := store.ListContentChanges(ctx, mon.ID, storage.Pagination{Page: 1, PerPage: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return page.Data.([]*storage.ContentChange)
+	}
+
+	check("Buy now!", "$19.99")
+	check("Limited offer", "$19.99")
+	if n := len(changes()); n != 0 {
+		t.Fatalf("ad rotation recorded %d changes", n)
+	}
+
+	check("Limited offer", "$21.00")
+	got := changes()
+	if len(got) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(got))
+	}
+	if !strings.Contains(got[0].Diff, "-$19.99") || !strings.Contains(got[0].Diff, "+$21.00") || strings.Contains(got[0].Diff, "offer") {
+		t.Errorf("diff = %q", got[0].Diff)
+	}
+	if got[0].NewHash != HashBody("$21.00") {
+		t.Errorf("new hash is not the hash of the selected text")
+	}
+}
+
+func TestHandleResultIgnoresPatterns(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	mon := &storage.Monitor{Name: "status", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1, TrackChanges: true,
+		Settings: json.RawMessage(`{"content":{"ignore_patterns":["\\d{2}:\\d{2}:\\d{2}"]}}`)}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	logger := discardLogger()
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+
+	check := func(body string) {
+		p.handleResult(ctx, WorkerResult{Monitor: mon, Result: &checker.Result{Status: "up", Body: body, BodyHash: HashBody(body)}})
+	}
+	check("All systems operational\nGenerated at 10:00:00")
+	check("All systems operational\nGenerated at 10:01:00")
+
+	page, _ := store.ListContentChanges(ctx, mon.ID, storage.Pagination{Page: 1, PerPage: 10})
+	if n := len(page.Data.([]*storage.ContentChange)); n != 0 {
+		t.Fatalf("timestamp change recorded %d changes", n)
+	}
+
+	check("Partial outage\nGenerated at 10:02:00")
+	page, _ = store.ListContentChanges(ctx, mon.ID, storage.Pagination{Page: 1, PerPage: 10})
+	changes := page.Data.([]*storage.ContentChange)
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(changes))
+	}
+	d := changes[0].Diff
+	if !strings.Contains(d, "+Partial outage") || !strings.Contains(d, "Generated at [ignored]") || strings.Contains(d, "10:02") {
+		t.Errorf("diff = %q", d)
+	}
+}
+
+func TestTrackedContentFallsBack(t *testing.T) {
+	body := `<p id="price">$5</p>`
+	tests := []struct {
+		settings string
+		want     string
+	}{
+		{``, body},
+		{`{"method":"GET"}`, body},
+		{`{"content":{"selector":"#missing"}}`, body},
+		{`{"content":{"selector":"p:hover"}}`, body},
+		{`{"content":{"selector":"#price"}}`, "$5"},
+		{`{"content":{"selector":"#price","ignore_patterns":["\\d+"]}}`, "$[ignored]"},
+		{`{"content":{"ignore_patterns":["\\$\\d+","("]}}`, `<p id="price">[ignored]</p>`},
+	}
+	for _, tt := range tests {
+		mon := &storage.Monitor{Type: "http", Settings: json.RawMessage(tt.settings)}
+		if got := trackedContent(mon, body); got != tt.want {
+			t.Errorf("trackedContent(%s) = %q, want %q", tt.settings, got, tt.want)
+		}
+	}
+}
+
+func TestResultRulesCachedPerLoad(t *testing.T) {
+	mon := &storage.Monitor{ID: 4242, Type: "http", RedactPatterns: []string{`token=\w+`},
+		Settings: json.RawMessage(`{"content":{"selector":"#price"}}`)}
+	first := rulesFor(mon)
+	if first.selector == nil || len(first.redact) != 1 {
+		t.Fatalf("unexpected rules: %+v", first)
+	}
+	if rulesFor(mon) != first {
+		t.Error("expected the rules of the same monitor value to be reused")
+	}
+
+	// A reload hands the pipeline a new monitor value with the edited settings.
+	edited := *mon
+	edited.RedactPatterns = nil
+	if r := rulesFor(&edited); r == first || len(r.redact) != 0 {
+		t.Errorf("expected the reloaded monitor compiled again, got %+v", r)
+	}
+
+	cacheResultRules(nil)
+	if _, ok := _resultRules.Load(mon.ID); ok {
+		t.Error("expected rules of unloaded monitors dropped")
+	}
+}
+
+func TestHandleResultRedactsStoredBody(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	mon := &storage.Monitor{Name: "echo", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1, TrackChanges: true, CaptureFailureContext: true,
+		RedactPatterns: []string{`token=\w+`},
+		Assertions:     json.RawMessage(`{"operator":"and","groups":[{"operator":"and","conditions":[{"type":"body_contains","value":"END"}]}]}`)}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	logger := discardLogger()
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+	p.SetMaxStoredBodyBytes(40)
+
+	const secret = "s3cr3tvalue"
+	check := func(r *checker.Result) *storage.CheckResult {
+		t.Helper()
+		r.BodyHash = HashBody(r.Body)
+		cr := p.recordResult(ctx, WorkerResult{Monitor: mon, Result: r})
+		if cr == nil {
+			t.Fatal("check result not stored")
+		}
+		stored, err := store.GetCheckResult(ctx, cr.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stored
+	}
+
+	// The assertion matches past the cap, so it must see the whole body.
+	up := check(&checker.Result{Status: "up", Body: "echo token=" + secret + " " + strings.Repeat("x", 100) + " END"})
+	if up.Status != "up" {
+		t.Fatalf("expected up, got %s: %s", up.Status, up.Message)
+	}
+	if len(up.Body) > 40 || !strings.HasPrefix(up.Body, "echo ***") {
+		t.Errorf("stored body = %q", up.Body)
+	}
+
+	down := check(&checker.Result{Status: "down", Message: "rejected token=" + secret, Body: "denied token=" + secret})
+	for name, s := range map[string]string{"body": down.Body, "message": down.Message, "failure context": down.FailureContext} {
+		if strings.Contains(s, secret) {
+			t.Errorf("%s leaks the secret: %q", name, s)
+		}
+	}
+	if !strings.HasPrefix(down.Message, "rejected ***") {
+		t.Errorf("message = %q", down.Message)
+	}
+
+	inc, err := store.GetOpenIncident(ctx, mon.ID)
+	if err != nil || inc == nil {
+		t.Fatalf("expected an open incident: %v", err)
+	}
+	if strings.Contains(inc.Cause, secret) {
+		t.Errorf("incident cause leaks the secret: %q", inc.Cause)
+	}
+
+	page, _ := store.ListContentChanges(ctx, mon.ID, storage.Pagination{Page: 1, PerPage: 10})
+	changes := page.Data.([]*storage.ContentChange)
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 content change, got %d", len(changes))
+	}
+	if strings.Contains(changes[0].Diff, secret) {
+		t.Errorf("content diff leaks the secret: %q", changes[0].Diff)
+	}
+}
+
+func TestCapBody(t *testing.T) {
+	tests := []struct {
+		body string
+		max  int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel"},
+		{"hello", 0, ""},
+		{"héllo", 2, "h"},
+		{"héllo", 3, "hé"},
+	}
+	for _, tt := range tests {
+		if got := capBody(tt.body, tt.max); got != tt.want {
+			t.Errorf("capBody(%q, %d) = %q, want %q", tt.body, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestHandleResultRecordsTransitions(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{Name: "flappy", Type: "http", Target: "http://127.0.0.1:8080/", Interval: 60, Timeout: 10,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+
+	for _, r := range []*checker.Result{
+		{Status: "up"},
+		{Status: "up"},
+		{Status: "down", Message: "connection refused"},
+		{Status: "down", Message: "connection refused"},
+		{Status: "up"},
+	} {
+		p.handleResult(ctx, WorkerResult{Monitor: mon, Result: r})
+	}
+
+	got, err := store.ListMonitorTransitions(ctx, mon.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []storage.MonitorTransition{
+		{FromStatus: "down", ToStatus: "up"},
+		{FromStatus: "up", ToStatus: "down", Cause: "connection refused"},
+		{FromStatus: "pending", ToStatus: "up"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d transitions, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].FromStatus != w.FromStatus || got[i].ToStatus != w.ToStatus || got[i].Cause != w.Cause {
+			t.Errorf("transition %d = %s -> %s (%q), want %s -> %s (%q)", i,
+				got[i].FromStatus, got[i].ToStatus, got[i].Cause, w.FromStatus, w.ToStatus, w.Cause)
+		}
+	}
+}
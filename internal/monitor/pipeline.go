@@ -3,12 +3,17 @@ package monitor
 import (
 	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/y0f/asura/internal/assertion"
 	"github.com/y0f/asura/internal/checker"
@@ -25,22 +30,34 @@ type Pipeline struct {
 	incMgr               *incident.Manager
 	logger               *slog.Logger
 	scheduler            *Scheduler
+	pool                 *Pool
 	jobs                 chan Job
 	results              chan WorkerResult
 	notifyChan           chan NotificationEvent
 	workers              int
 	adaptiveIntervals    bool
+	ackSilencesReminders bool
+	baselineMinSamples   int
+	maxStoredBody        int
 	droppedNotifications atomic.Int64
 	lastNotified         sync.Map // map[int64]time.Time — tracks last resend per monitor
+	streamCounts         sync.Map // map[int64]*atomic.Int64 — checks since the last streamed result
+	lastTriggered        sync.Map // map[int64]time.Time — last on-demand check per monitor
 }
 
 // NotificationEvent is emitted when something noteworthy happens.
 type NotificationEvent struct {
 	EventType string
+	Severity  string // critical or warning for failures, empty otherwise
 	MonitorID int64
 	Incident  *storage.Incident
 	Monitor   *storage.Monitor
 	Change    *storage.ContentChange
+	Check     *storage.CheckResult
+	Proxy     *storage.Proxy
+	// ChannelIDs, when set, sends the event to exactly these channels
+	// instead of the monitor's routing. Used by escalation steps.
+	ChannelIDs []int64
 }
 
 func NewPipeline(store storage.Store, registry *checker.Registry, incMgr *incident.Manager, workers int, adaptiveIntervals bool, logger *slog.Logger) *Pipeline {
@@ -49,19 +66,76 @@ func NewPipeline(store storage.Store, registry *checker.Registry, incMgr *incide
 	notifyChan := make(chan NotificationEvent, 100)
 
 	return &Pipeline{
-		store:             store,
-		registry:          registry,
-		incMgr:            incMgr,
-		logger:            logger,
-		scheduler:         NewScheduler(store, jobs, logger),
-		jobs:              jobs,
-		results:           results,
-		notifyChan:        notifyChan,
-		workers:           workers,
-		adaptiveIntervals: adaptiveIntervals,
+		store:              store,
+		registry:           registry,
+		incMgr:             incMgr,
+		logger:             logger,
+		scheduler:          NewScheduler(store, jobs, logger),
+		pool:               NewPool(workers, registry, jobs, results, logger),
+		jobs:               jobs,
+		results:            results,
+		notifyChan:         notifyChan,
+		workers:            workers,
+		adaptiveIntervals:  adaptiveIntervals,
+		baselineMinSamples: 30,
+		maxStoredBody:      64 << 10,
 	}
 }
 
+// SetAckSilencesReminders sets the instance-wide default for whether an
+// acknowledged incident stops incident.reminder notifications. Monitors can
+// override it individually.
+func (p *Pipeline) SetAckSilencesReminders(v bool) {
+	p.ackSilencesReminders = v
+}
+
+// SetBaselineMinSamples sets how many checks an hourly latency baseline needs
+// before deviations from it are flagged.
+func (p *Pipeline) SetBaselineMinSamples(n int) {
+	p.baselineMinSamples = n
+}
+
+// SetMaxStoredBodyBytes caps the response body stored with each check.
+func (p *Pipeline) SetMaxStoredBodyBytes(n int) {
+	p.maxStoredBody = n
+}
+
+// SetScheduleJitter spreads checks randomly within ±percent of each
+// monitor's interval.
+func (p *Pipeline) SetScheduleJitter(percent int) {
+	p.scheduler.SetJitter(percent)
+}
+
+// SetHostLimits caps how many checks run against one target host at once and
+// how far apart they start. Zero disables either limit. A check that waits
+// longer than maxWait for its host is skipped; zero waits as long as it takes.
+func (p *Pipeline) SetHostLimits(maxConcurrent int, minInterval, maxWait time.Duration) {
+	p.pool.limiter = newHostLimiter(maxConcurrent, minInterval, maxWait)
+}
+
+// SetMaxQueue sets how many scheduled checks can wait for a free worker.
+// When the queue is full the oldest waiting check is dropped. It must be
+// called before Run; n <= 0 keeps the default of twice the worker count.
+func (p *Pipeline) SetMaxQueue(n int) {
+	if n <= 0 {
+		return
+	}
+	p.jobs = make(chan Job, n)
+	p.scheduler.jobs = p.jobs
+	p.pool.jobs = p.jobs
+}
+
+// SetMaxInFlight caps how many checks run at once, below the worker count.
+// Checks over the cap wait in the queue. It must be called before Run;
+// n <= 0 or n >= the worker count leaves the workers as the only limit.
+func (p *Pipeline) SetMaxInFlight(n int) {
+	if n <= 0 || n >= p.pool.workers {
+		p.pool.slots = nil
+		return
+	}
+	p.pool.slots = make(chan struct{}, n)
+}
+
 // NotifyChan returns the channel for notification events.
 func (p *Pipeline) NotifyChan() <-chan NotificationEvent {
 	return p.notifyChan
@@ -72,23 +146,113 @@ func (p *Pipeline) ReloadMonitors() {
 	p.scheduler.TriggerReload()
 }
 
-// DroppedJobs returns the total number of scheduler jobs dropped due to a full channel.
+// DroppedJobs returns the total number of scheduler jobs dropped due to a full queue.
 func (p *Pipeline) DroppedJobs() int64 {
 	return p.scheduler.droppedJobs.Load()
 }
 
+// QueuedJobs returns the number of checks waiting for a free worker.
+func (p *Pipeline) QueuedJobs() int {
+	return len(p.jobs)
+}
+
+// JobQueueCapacity returns how many checks can wait for a free worker.
+func (p *Pipeline) JobQueueCapacity() int {
+	return cap(p.jobs)
+}
+
+// InFlight returns the number of checks currently running.
+func (p *Pipeline) InFlight() int64 {
+	return p.pool.InFlight()
+}
+
+// QueuedHostChecks returns the total number of checks that waited for their
+// target host's concurrency or interval limit.
+func (p *Pipeline) QueuedHostChecks() int64 {
+	return p.pool.limiter.queued.Load()
+}
+
+// DroppedHostChecks returns the total number of checks skipped because their
+// target host stayed busy too long.
+func (p *Pipeline) DroppedHostChecks() int64 {
+	return p.pool.limiter.dropped.Load()
+}
+
 // DroppedNotifications returns the total number of notification events dropped due to a full channel.
 func (p *Pipeline) DroppedNotifications() int64 {
 	return p.droppedNotifications.Load()
 }
 
+// SchedulerStats is a point-in-time view of the scheduler and worker pool,
+// used to diagnose delayed checks.
+type SchedulerStats struct {
+	Monitors             int              `json:"monitors"`
+	HeapSize             int              `json:"heap_size"`
+	NextDue              []ScheduledCheck `json:"next_due"`
+	Workers              int              `json:"workers"`
+	InFlight             int64            `json:"in_flight"`
+	QueuedJobs           int              `json:"queued_jobs"`
+	JobQueueCapacity     int              `json:"job_queue_capacity"`
+	QueuedResults        int              `json:"queued_results"`
+	DroppedJobs          int64            `json:"dropped_jobs"`
+	DroppedNotifications int64            `json:"dropped_notifications"`
+	LimitedHosts         int              `json:"limited_hosts"`
+	QueuedHostChecks     int64            `json:"queued_host_checks"`
+	DroppedHostChecks    int64            `json:"dropped_host_checks"`
+}
+
+// SchedulerStats returns the current scheduler and worker pool state with the
+// next limit monitors due to run.
+func (p *Pipeline) SchedulerStats(limit int) SchedulerStats {
+	monitors, heapSize, next := p.scheduler.Snapshot(limit)
+	return SchedulerStats{
+		Monitors:             monitors,
+		HeapSize:             heapSize,
+		NextDue:              next,
+		Workers:              p.workers,
+		InFlight:             p.pool.InFlight(),
+		QueuedJobs:           len(p.jobs),
+		JobQueueCapacity:     cap(p.jobs),
+		QueuedResults:        len(p.results),
+		DroppedJobs:          p.DroppedJobs(),
+		DroppedNotifications: p.DroppedNotifications(),
+		LimitedHosts:         p.pool.limiter.size(),
+		QueuedHostChecks:     p.QueuedHostChecks(),
+		DroppedHostChecks:    p.DroppedHostChecks(),
+	}
+}
+
+// PipelineHealth reports whether the scheduler is still running and how
+// full the job and notification queues are.
+type PipelineHealth struct {
+	LastRun                   time.Time
+	LastDispatch              time.Time
+	QueuedJobs                int
+	JobQueueCapacity          int
+	QueuedNotifications       int
+	NotificationQueueCapacity int
+	DroppedNotifications      int64
+}
+
+// Health returns the pipeline's current liveness figures.
+func (p *Pipeline) Health() PipelineHealth {
+	return PipelineHealth{
+		LastRun:                   p.scheduler.LastRun(),
+		LastDispatch:              p.scheduler.LastDispatch(),
+		QueuedJobs:                len(p.jobs),
+		JobQueueCapacity:          cap(p.jobs),
+		QueuedNotifications:       len(p.notifyChan),
+		NotificationQueueCapacity: cap(p.notifyChan),
+		DroppedNotifications:      p.DroppedNotifications(),
+	}
+}
+
 func (p *Pipeline) Run(ctx context.Context) {
 	// Start scheduler
 	go p.scheduler.Run(ctx)
 
 	// Start worker pool
-	pool := NewPool(p.workers, p.registry, p.jobs, p.results, p.logger)
-	go pool.Run(ctx)
+	go p.pool.Run(ctx)
 
 	// Start result processor
 	p.processResults(ctx)
@@ -109,6 +273,16 @@ func (p *Pipeline) processResults(ctx context.Context) {
 }
 
 func (p *Pipeline) handleResult(ctx context.Context, wr WorkerResult) {
+	cr := p.recordResult(ctx, wr)
+	if wr.Done != nil {
+		wr.Done <- cr
+	}
+}
+
+// recordResult stores a check result, updates the monitor status and drives
+// incidents and notifications. It returns the stored result, or nil when it
+// could not be stored.
+func (p *Pipeline) recordResult(ctx context.Context, wr WorkerResult) *storage.CheckResult {
 	mon := wr.Monitor
 
 	if wr.Err != nil {
@@ -119,49 +293,118 @@ func (p *Pipeline) handleResult(ctx context.Context, wr WorkerResult) {
 		}
 	}
 
-	result := wr.Result
-	finalStatus := evaluateAssertions(mon, result)
+	// With probes, the worst probe's check stands in for the monitor and
+	// every probe's outcome is kept for the per-probe breakdown.
+	var probe *storage.Probe
+	var checks []*storage.CheckResult
+	var cr *storage.CheckResult
+	var result *checker.Result
+	var finalStatus string
+	if len(wr.Probes) > 0 {
+		var results []*checker.Result
+		var worst int
+		checks, results, worst = evaluateProbes(mon, wr.Probes, p.maxStoredBody)
+		probe, result, cr = wr.Probes[worst].Probe, results[worst], checks[worst]
+		finalStatus = cr.Status
+	} else {
+		result = wr.Result
+		finalStatus = computeFinalStatus(mon, result)
+	}
+	anomaly := finalStatus == "up" && p.latencyAnomaly(ctx, mon, result, time.Now())
+	if anomaly {
+		finalStatus = "degraded"
+	}
 
-	if mon.UpsideDown {
-		if finalStatus == "up" {
-			finalStatus = "down"
-		} else {
-			finalStatus = "up"
-			result.Message = ""
-		}
+	status, err := p.store.GetMonitorStatus(ctx, mon.ID)
+	if err != nil {
+		p.logger.Warn("get monitor status, using defaults", "monitor_id", mon.ID, "error", err)
+		status = &storage.MonitorStatus{MonitorID: mon.ID}
 	}
 
-	cr := buildCheckResult(mon, result, finalStatus)
+	if cr == nil {
+		cr = buildCheckResult(mon, result, finalStatus, p.maxStoredBody)
+		cr.Message = withRetryNote(cr.Message, wr.Retries)
+	}
+	cr.Status = finalStatus
+	if finalStatus != "up" {
+		if p.parentDown(ctx, mon) {
+			cr.Message = withSuppressedNote(cr.Message, "parent down")
+		} else if inWarmup(mon, time.Now()) {
+			cr.Message = withSuppressedNote(cr.Message, "warming up")
+		}
+	}
+	if mon.CaptureFailureContext && finalStatus == "down" && status.Status != "down" {
+		cr.FailureContext = captureFailureContext(mon, result, cr.Body, wr.Lookup)
+	}
 
+	// The previous check holds the old body for a content-change diff.
+	var prevCheckID int64
+	if mon.TrackChanges {
+		if prev, err := p.store.GetLatestCheckResult(ctx, mon.ID); err == nil {
+			prevCheckID = prev.ID
+		}
+	}
 	if err := p.store.InsertCheckResult(ctx, cr); err != nil {
 		p.logger.Error("insert check result", "error", err)
-		return
+		return nil
+	}
+	if len(checks) > 0 {
+		if err := p.store.InsertProbeChecks(ctx, checks); err != nil {
+			p.logger.Error("insert probe checks", "monitor_id", mon.ID, "error", err)
+		}
 	}
 
 	now := time.Now()
-	status, err := p.store.GetMonitorStatus(ctx, mon.ID)
-	if err != nil {
-		p.logger.Warn("get monitor status, using defaults", "monitor_id", mon.ID, "error", err)
-		status = &storage.MonitorStatus{MonitorID: mon.ID}
+
+	message := cr.Message
+	if probe != nil && message != "" {
+		message = probe.Name + ": " + message
+	}
+	if status.Status != "" && status.Status != finalStatus {
+		p.recordTransition(ctx, mon.ID, status.Status, finalStatus, message)
+	}
+
+	if anomaly && status.Status != "degraded" {
+		p.emitNotification("monitor.latency_anomaly", nil, mon, nil)
+	}
+	if ev := transitionEvent(status.Status, finalStatus); ev != "" && p.transitionNotifiable(ctx, mon, ev, now) {
+		p.publish(NotificationEvent{EventType: ev, MonitorID: mon.ID, Monitor: mon, Check: cr})
+	}
+
+	if mon.StreamChecksEvery > 0 {
+		p.streamCheck(mon, cr, finalStatus != status.Status)
 	}
 
 	status.Status = finalStatus
 	status.LastCheckAt = &now
 
-	if finalStatus == "up" {
+	switch {
+	case finalStatus == "up":
 		status.ConsecSuccesses++
 		status.ConsecFails = 0
-	} else {
+		status.ConsecDegraded = 0
+	case finalStatus == "degraded" && mon.DegradedThreshold > 0:
+		status.ConsecDegraded++
+		status.ConsecFails = 0
+		status.ConsecSuccesses = 0
+	default:
 		status.ConsecFails++
 		status.ConsecSuccesses = 0
+		status.ConsecDegraded = 0
 	}
 
 	if mon.TrackChanges && result.BodyHash != "" {
+		hash := result.BodyHash
+		if tracked := trackedContent(mon, result.Body); tracked != result.Body {
+			hash = HashBody(tracked)
+		}
 		oldHash := status.LastBodyHash
-		if oldHash != "" && oldHash != result.BodyHash {
-			p.handleContentChange(ctx, mon, oldHash, result.BodyHash, result.Body, status)
+		if oldHash != "" && oldHash != hash {
+			// Changes are detected on the whole body, but diffed between
+			// stored bodies so the diff is capped and redacted like them.
+			p.handleContentChange(ctx, mon, prevCheckID, oldHash, hash, trackedContent(mon, cr.Body))
 		}
-		status.LastBodyHash = result.BodyHash
+		status.LastBodyHash = hash
 	} else if result.BodyHash != "" {
 		status.LastBodyHash = result.BodyHash
 	}
@@ -185,7 +428,67 @@ func (p *Pipeline) handleResult(ctx context.Context, wr WorkerResult) {
 		p.scheduler.UpdateInterval(mon.ID, newInterval)
 	}
 
-	p.processIncidents(ctx, mon, finalStatus, status, cr.Message)
+	p.processIncidents(ctx, mon, finalStatus, status, message)
+	return cr
+}
+
+// recordTransition stores a change of the monitor's status for its flap
+// history.
+func (p *Pipeline) recordTransition(ctx context.Context, monitorID int64, from, to, cause string) {
+	t := &storage.MonitorTransition{MonitorID: monitorID, FromStatus: from, ToStatus: to, Cause: cause}
+	if err := p.store.InsertMonitorTransition(ctx, t); err != nil {
+		p.logger.Error("insert monitor transition", "monitor_id", monitorID, "error", err)
+	}
+}
+
+// withRetryNote appends how many retries preceded the stored result.
+func withRetryNote(msg string, retries int) string {
+	if retries == 0 {
+		return msg
+	}
+	note := fmt.Sprintf("after %d retries", retries)
+	if retries == 1 {
+		note = "after 1 retry"
+	}
+	if msg == "" {
+		return note
+	}
+	return msg + " (" + note + ")"
+}
+
+// withSuppressedNote marks a failure that won't open an incident, for
+// example because the monitor's parent is down.
+func withSuppressedNote(msg, reason string) string {
+	note := "suppressed (" + reason + ")"
+	if msg == "" {
+		return note
+	}
+	return msg + "; " + note
+}
+
+// computeFinalStatus applies assertions, the response time limit and
+// upside-down inversion to a raw checker result.
+func computeFinalStatus(mon *storage.Monitor, result *checker.Result) string {
+	finalStatus := evaluateAssertions(mon, result)
+	if mon.UpsideDown {
+		if finalStatus == "up" {
+			finalStatus = "down"
+		} else {
+			finalStatus = "up"
+			result.Message = ""
+		}
+		return finalStatus
+	}
+	if finalStatus == "up" && mon.MaxResponseTimeMs > 0 && result.ResponseTime > int64(mon.MaxResponseTimeMs) {
+		finalStatus = "degraded"
+		msg := fmt.Sprintf("response time %dms exceeds %dms", result.ResponseTime, mon.MaxResponseTimeMs)
+		if result.Message == "" {
+			result.Message = msg
+		} else {
+			result.Message += "; " + msg
+		}
+	}
+	return finalStatus
 }
 
 func evaluateAssertions(mon *storage.Monitor, result *checker.Result) string {
@@ -193,7 +496,11 @@ func evaluateAssertions(mon *storage.Monitor, result *checker.Result) string {
 	if len(mon.Assertions) == 0 || string(mon.Assertions) == "[]" {
 		return finalStatus
 	}
-	assertionResult := assertion.Evaluate(mon.Assertions, result.StatusCode, result.Body,
+	bodySize := result.BodySize
+	if bodySize == 0 {
+		bodySize = int64(len(result.Body))
+	}
+	assertionResult := assertion.Evaluate(mon.Assertions, result.StatusCode, result.Body, bodySize,
 		result.Headers, result.ResponseTime, result.CertExpiry, result.DNSRecords)
 	if !assertionResult.Pass {
 		if assertionResult.Degraded {
@@ -210,8 +517,10 @@ func evaluateAssertions(mon *storage.Monitor, result *checker.Result) string {
 	return finalStatus
 }
 
-func buildCheckResult(mon *storage.Monitor, result *checker.Result, finalStatus string) *storage.CheckResult {
-	headersJSON, _ := json.Marshal(result.Headers)
+// buildCheckResult turns a checker result into the row stored for it. The
+// body and message are redacted, and the body is capped at maxBody bytes.
+func buildCheckResult(mon *storage.Monitor, result *checker.Result, finalStatus string, maxBody int) *storage.CheckResult {
+	headersJSON, _ := json.Marshal(storedHeaders(mon, result.Headers))
 	dnsJSON, _ := json.Marshal(result.DNSRecords)
 
 	var certExpiry *time.Time
@@ -220,33 +529,94 @@ func buildCheckResult(mon *storage.Monitor, result *checker.Result, finalStatus
 		certExpiry = &t
 	}
 
+	config, configHash := CheckConfigSnapshot(mon)
+
 	return &storage.CheckResult{
 		MonitorID:       mon.ID,
 		Status:          finalStatus,
 		ResponseTime:    result.ResponseTime,
 		StatusCode:      result.StatusCode,
-		Message:         result.Message,
+		Message:         redact(mon, result.Message),
 		Headers:         string(headersJSON),
-		Body:            result.Body,
+		Body:            capBody(redact(mon, result.Body), maxBody),
 		BodyHash:        result.BodyHash,
 		CertExpiry:      certExpiry,
 		CertFingerprint: result.CertFingerprint,
 		DNSRecords:      string(dnsJSON),
+		ConfigHash:      configHash,
+		Config:          config,
 	}
 }
 
+// CheckConfigSnapshot returns the execution-relevant configuration of a
+// monitor as JSON together with its SHA-256 hash.
+func CheckConfigSnapshot(mon *storage.Monitor) (json.RawMessage, string) {
+	b, err := json.Marshal(storage.CheckConfig{
+		Type:           mon.Type,
+		Target:         mon.Target,
+		Timeout:        mon.Timeout,
+		ConnectTimeout: mon.ConnectTimeout,
+		Settings:       mon.Settings,
+		Assertions:     mon.Assertions,
+		UpsideDown:     mon.UpsideDown,
+	})
+	if err != nil {
+		return nil, ""
+	}
+	return b, HashBody(string(b))
+}
+
+// RunCheck executes a single check synchronously and evaluates it exactly as
+// the pipeline would, without persisting the result or touching incidents.
+func (p *Pipeline) RunCheck(ctx context.Context, mon *storage.Monitor) *storage.CheckResult {
+	p.scheduler.resolveProxyURLs(ctx, []*storage.Monitor{mon})
+
+	var result *checker.Result
+	c, err := p.registry.Get(mon.Type)
+	if err == nil {
+		checkCtx, cancel := context.WithTimeout(ctx, time.Duration(mon.Timeout)*time.Second)
+		result, err = c.Check(checkCtx, mon)
+		cancel()
+	}
+	if err != nil {
+		result = &checker.Result{Status: "down", Message: err.Error()}
+	}
+
+	finalStatus := computeFinalStatus(mon, result)
+	cr := buildCheckResult(mon, result, finalStatus, p.maxStoredBody)
+	cr.CreatedAt = time.Now().UTC()
+	return cr
+}
+
 func (p *Pipeline) processIncidents(ctx context.Context, mon *storage.Monitor, finalStatus string, status *storage.MonitorStatus, message string) {
+	if finalStatus != "up" && p.parentDown(ctx, mon) {
+		p.logger.Debug("parent down, suppressing incident", "monitor_id", mon.ID, "parent_id", *mon.ParentID)
+		return
+	}
+	if finalStatus != "up" && inWarmup(mon, time.Now()) {
+		p.logger.Debug("monitor warming up, suppressing incident", "monitor_id", mon.ID)
+		return
+	}
 	inMaintenance, _ := p.store.IsMonitorInMaintenance(ctx, mon.ID, time.Now())
 
-	if finalStatus != "up" && status.ConsecFails >= mon.FailureThreshold {
-		p.processFailure(ctx, mon, message, inMaintenance)
-	} else if finalStatus == "up" && status.ConsecSuccesses >= mon.SuccessThreshold {
+	switch {
+	case finalStatus == "degraded" && mon.DegradedThreshold > 0:
+		if status.ConsecDegraded >= mon.DegradedThreshold {
+			p.processFailure(ctx, mon, finalStatus, message, inMaintenance)
+		}
+	case finalStatus != "up" && status.ConsecFails >= mon.FailureThreshold:
+		p.processFailure(ctx, mon, finalStatus, message, inMaintenance)
+	case finalStatus == "up" && status.ConsecSuccesses >= mon.SuccessThreshold:
 		p.processRecovery(ctx, mon, inMaintenance)
 	}
 }
 
-func (p *Pipeline) processFailure(ctx context.Context, mon *storage.Monitor, message string, inMaintenance bool) {
-	inc, created, err := p.incMgr.ProcessFailure(ctx, mon.ID, mon.Name, message)
+func (p *Pipeline) processFailure(ctx context.Context, mon *storage.Monitor, finalStatus, message string, inMaintenance bool) {
+	process := p.incMgr.ProcessFailure
+	if finalStatus == "degraded" && mon.DegradedThreshold > 0 {
+		process = p.incMgr.ProcessDegraded
+	}
+	inc, created, err := process(ctx, mon.ID, mon.Name, message)
 	if err != nil {
 		p.logger.Error("process failure", "error", err)
 		return
@@ -254,11 +624,17 @@ func (p *Pipeline) processFailure(ctx context.Context, mon *storage.Monitor, mes
 	if inMaintenance {
 		return
 	}
+	// The incident's own severity, so a degraded check recorded on an open
+	// critical incident does not downgrade its reminders.
+	severity := inc.Severity
+	if severity == "" {
+		severity = incident.SeverityCritical
+	}
 	if created {
-		p.emitNotification("incident.created", inc, mon, nil)
+		p.publish(NotificationEvent{EventType: "incident.created", Severity: severity, MonitorID: mon.ID, Incident: inc, Monitor: mon})
 		p.lastNotified.Store(mon.ID, time.Now())
-	} else if p.shouldResend(mon) {
-		p.emitNotification("incident.reminder", inc, mon, nil)
+	} else if p.shouldResend(mon) && !p.remindersSilenced(mon, inc) {
+		p.publish(NotificationEvent{EventType: "incident.reminder", Severity: severity, MonitorID: mon.ID, Incident: inc, Monitor: mon})
 		p.lastNotified.Store(mon.ID, time.Now())
 	}
 }
@@ -275,6 +651,60 @@ func (p *Pipeline) processRecovery(ctx context.Context, mon *storage.Monitor, in
 	p.lastNotified.Delete(mon.ID)
 }
 
+// transitionEvent returns monitor.down when a check result moves a monitor
+// from any other status to down, monitor.recovered when it moves away from
+// down, and "" otherwise. Unlike incident events these follow every status
+// change, regardless of failure and success thresholds.
+func transitionEvent(prev, next string) string {
+	switch {
+	case next == "down" && prev != "down":
+		return "monitor.down"
+	case prev == "down" && next != "down":
+		return "monitor.recovered"
+	}
+	return ""
+}
+
+// transitionNotifiable reports whether transition event ev should be sent
+// for mon: not during maintenance, and not while its parent is down, in
+// either direction so a suppressed monitor.down isn't followed by a lone
+// monitor.recovered. Warm-up holds back only monitor.down, so a monitor
+// that was fixed and edited still reports its recovery.
+func (p *Pipeline) transitionNotifiable(ctx context.Context, mon *storage.Monitor, ev string, now time.Time) bool {
+	if ev == "monitor.down" && inWarmup(mon, now) {
+		return false
+	}
+	if inMaintenance, _ := p.store.IsMonitorInMaintenance(ctx, mon.ID, now); inMaintenance {
+		return false
+	}
+	return !p.parentDown(ctx, mon)
+}
+
+// inWarmup reports whether now is within mon's warm-up period, which starts
+// when the monitor was created, resumed or last edited.
+func inWarmup(mon *storage.Monitor, now time.Time) bool {
+	if mon.WarmupSeconds <= 0 {
+		return false
+	}
+	return now.Before(mon.UpdatedAt.Add(time.Duration(mon.WarmupSeconds) * time.Second))
+}
+
+// parentDown reports whether mon depends on a monitor that has an open or
+// acknowledged incident.
+func (p *Pipeline) parentDown(ctx context.Context, mon *storage.Monitor) bool {
+	if mon.ParentID == nil {
+		return false
+	}
+	inc, err := p.store.GetOpenIncident(ctx, *mon.ParentID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			p.logger.Warn("get parent incident", "monitor_id", mon.ID, "parent_id", *mon.ParentID, "error", err)
+		}
+		return false
+	}
+	return inc != nil
+}
+
 func (p *Pipeline) shouldResend(mon *storage.Monitor) bool {
 	if mon.ResendInterval <= 0 {
 		return false
@@ -286,11 +716,25 @@ func (p *Pipeline) shouldResend(mon *storage.Monitor) bool {
 	return time.Since(v.(time.Time)) >= time.Duration(mon.ResendInterval)*time.Second
 }
 
-func (p *Pipeline) handleContentChange(ctx context.Context, mon *storage.Monitor, oldHash, newHash, newBody string, status *storage.MonitorStatus) {
+// remindersSilenced reports whether reminders for inc are suppressed because
+// it has been acknowledged and the monitor (or instance default) says an
+// acknowledgement silences them.
+func (p *Pipeline) remindersSilenced(mon *storage.Monitor, inc *storage.Incident) bool {
+	if inc == nil || inc.Status != incident.StatusAcknowledged {
+		return false
+	}
+	if mon.AckSilencesReminders != nil {
+		return *mon.AckSilencesReminders
+	}
+	return p.ackSilencesReminders
+}
+
+func (p *Pipeline) handleContentChange(ctx context.Context, mon *storage.Monitor, prevCheckID int64, oldHash, newHash, newBody string) {
 	oldBody := ""
-	latest, err := p.store.GetLatestCheckResult(ctx, mon.ID)
-	if err == nil && latest != nil {
-		oldBody = latest.Body
+	if prevCheckID > 0 {
+		if prev, err := p.store.GetCheckResult(ctx, prevCheckID); err == nil {
+			oldBody = trackedContent(mon, prev.Body)
+		}
 	}
 
 	diffText := diff.Compute(oldBody, newBody)
@@ -321,22 +765,46 @@ func (p *Pipeline) emitNotification(eventType string, inc *storage.Incident, mon
 	} else if change != nil {
 		monitorID = change.MonitorID
 	}
-	select {
-	case p.notifyChan <- NotificationEvent{
+	p.publish(NotificationEvent{
 		EventType: eventType,
 		MonitorID: monitorID,
 		Incident:  inc,
 		Monitor:   mon,
 		Change:    change,
-	}:
+	})
+}
+
+func (p *Pipeline) publish(ev NotificationEvent) {
+	select {
+	case p.notifyChan <- ev:
 	default:
 		p.droppedNotifications.Add(1)
-		p.logger.Warn("notification channel full, dropping event", "event", eventType)
+		p.logger.Warn("notification channel full, dropping event", "event", ev.EventType)
 	}
 }
 
+// streamCheck publishes a check.completed event for every
+// StreamChecksEvery-th result and for every status change. Streamed results
+// give way to other notifications once the event queue is half full.
+func (p *Pipeline) streamCheck(mon *storage.Monitor, cr *storage.CheckResult, statusChanged bool) {
+	v, _ := p.streamCounts.LoadOrStore(mon.ID, new(atomic.Int64))
+	n := v.(*atomic.Int64).Add(1)
+	if !statusChanged && n < int64(mon.StreamChecksEvery) {
+		return
+	}
+	v.(*atomic.Int64).Store(0)
+	if len(p.notifyChan) >= cap(p.notifyChan)/2 {
+		p.droppedNotifications.Add(1)
+		return
+	}
+	p.publish(NotificationEvent{EventType: "check.completed", MonitorID: mon.ID, Monitor: mon, Check: cr})
+}
+
 // ProcessHeartbeatRecovery handles recovery when a heartbeat ping is received for a down monitor.
 func (p *Pipeline) ProcessHeartbeatRecovery(ctx context.Context, mon *storage.Monitor) {
+	if prev, err := p.store.GetMonitorStatus(ctx, mon.ID); err == nil && prev.Status != "" && prev.Status != "up" {
+		p.recordTransition(ctx, mon.ID, prev.Status, "up", "heartbeat received")
+	}
 	now := time.Now()
 	status := &storage.MonitorStatus{
 		MonitorID:       mon.ID,
@@ -355,11 +823,92 @@ func (p *Pipeline) ProcessHeartbeatRecovery(ctx context.Context, mon *storage.Mo
 		p.logger.Error("heartbeat recovery: process recovery", "error", err)
 		return
 	}
+	if !inMaintenance && !p.parentDown(ctx, mon) {
+		p.emitNotification("monitor.recovered", nil, mon, nil)
+	}
 	if resolved && !inMaintenance {
 		p.emitNotification("incident.resolved", inc, mon, nil)
 	}
 }
 
+// ignoredPlaceholder replaces matches of a content ignore pattern.
+const ignoredPlaceholder = "[ignored]"
+
+// trackedContent returns the part of a response body that content-change
+// tracking hashes and diffs: the text matched by the monitor's content
+// selector, or the whole body when there is none or it matches nothing,
+// with matches of the ignore patterns replaced by a placeholder.
+func trackedContent(mon *storage.Monitor, body string) string {
+	rules := rulesFor(mon)
+	if !rules.content {
+		return body
+	}
+	content := body
+	if rules.selector != nil {
+		if text, ok := rules.selector.Extract(body); ok {
+			content = text
+		}
+	}
+	for _, re := range rules.ignore {
+		content = re.ReplaceAllLiteralString(content, ignoredPlaceholder)
+	}
+	return content
+}
+
+// redactedPlaceholder replaces matches of a monitor's redact patterns.
+const redactedPlaceholder = "***"
+
+// redact replaces matches of the monitor's redact patterns in s.
+func redact(mon *storage.Monitor, s string) string {
+	for _, re := range rulesFor(mon).redact {
+		s = re.ReplaceAllLiteralString(s, redactedPlaceholder)
+	}
+	return s
+}
+
+// _sensitiveHeaders are never stored with a check, whichever side of the
+// exchange they came from.
+var _sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+	"X-Auth-Token":        true,
+}
+
+// storedHeaders returns the response headers kept with a check result. It
+// drops credentials and any header the monitor sends with its own request,
+// since servers that echo request headers would otherwise leak them, and
+// redacts the remaining values.
+func storedHeaders(mon *storage.Monitor, headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return headers
+	}
+	sent := rulesFor(mon).sentHeaders
+	out := make(map[string]string, len(headers))
+	for k, v := range headers {
+		key := http.CanonicalHeaderKey(k)
+		if _sensitiveHeaders[key] || sent[key] {
+			continue
+		}
+		out[k] = redact(mon, v)
+	}
+	return out
+}
+
+// capBody truncates body to at most max bytes, backing off so a UTF-8
+// sequence is not split.
+func capBody(body string, max int) string {
+	if len(body) <= max {
+		return body
+	}
+	for max > 0 && !utf8.RuneStart(body[max]) {
+		max--
+	}
+	return body[:max]
+}
+
 func HashBody(body string) string {
 	h := sha256.Sum256([]byte(body))
 	return hex.EncodeToString(h[:])
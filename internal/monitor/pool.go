@@ -3,31 +3,48 @@ package monitor
 import (
 	"context"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/y0f/asura/internal/checker"
 	"github.com/y0f/asura/internal/storage"
 )
 
-// Job represents a check to be executed.
+// Job represents a check to be executed. Done, when set, receives the
+// stored result once the check has been processed, or nil when it was
+// skipped. It is closed without a result when the job is dropped from a
+// full queue.
 type Job struct {
 	Monitor *storage.Monitor
+	Done    chan<- *storage.CheckResult
 }
 
-// WorkerResult holds the outcome of a check job.
+// WorkerResult holds the outcome of a check job. A monitor with probes has
+// one entry in Probes per probe instead of a Result. Retries counts the
+// extra attempts made before Result was accepted. Lookup holds the target
+// host's resolution for a failed check that captures failure context.
 type WorkerResult struct {
 	Monitor *storage.Monitor
 	Result  *checker.Result
 	Err     error
+	Probes  []ProbeResult
+	Retries int
+	Lookup  *hostLookup
+	Done    chan<- *storage.CheckResult
 }
 
-// Pool manages a fixed set of worker goroutines.
+// Pool manages a fixed set of worker goroutines. When slots is set, a
+// worker takes a job only while it holds a slot, so fewer checks than
+// workers run at once and the rest stay in the queue.
 type Pool struct {
 	workers  int
 	registry *checker.Registry
 	jobs     <-chan Job
 	results  chan<- WorkerResult
 	logger   *slog.Logger
+	inFlight atomic.Int64
+	limiter  *hostLimiter
+	slots    chan struct{}
 }
 
 func NewPool(workers int, registry *checker.Registry, jobs <-chan Job, results chan<- WorkerResult, logger *slog.Logger) *Pool {
@@ -37,6 +54,7 @@ func NewPool(workers int, registry *checker.Registry, jobs <-chan Job, results c
 		jobs:     jobs,
 		results:  results,
 		logger:   logger,
+		limiter:  newHostLimiter(0, 0, 0),
 	}
 }
 
@@ -49,36 +67,141 @@ func (p *Pool) Run(ctx context.Context) {
 
 func (p *Pool) worker(ctx context.Context, id int) {
 	for {
-		select {
-		case <-ctx.Done():
-			return
-		case job, ok := <-p.jobs:
-			if !ok {
+		if p.slots != nil {
+			select {
+			case p.slots <- struct{}{}:
+			case <-ctx.Done():
 				return
 			}
-			p.executeJob(ctx, job)
 		}
+		ok := p.next(ctx)
+		if p.slots != nil {
+			<-p.slots
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// next runs the next queued job. It reports false once the pool is stopping.
+func (p *Pool) next(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case job, ok := <-p.jobs:
+		if !ok {
+			return false
+		}
+		p.executeJob(ctx, job)
+		return true
 	}
 }
 
+// InFlight returns the number of checks currently being executed.
+func (p *Pool) InFlight() int64 {
+	return p.inFlight.Load()
+}
+
 func (p *Pool) executeJob(ctx context.Context, job Job) {
+	release, ok := p.limiter.acquire(ctx, checkHost(job.Monitor))
+	if !ok {
+		if ctx.Err() == nil {
+			p.logger.Warn("host busy, skipping check until its next run",
+				"monitor_id", job.Monitor.ID, "host", checkHost(job.Monitor), "waited", p.limiter.maxWait)
+		}
+		if job.Done != nil {
+			job.Done <- nil
+		}
+		return
+	}
+	p.inFlight.Add(1)
+	wr := p.runJob(ctx, job)
+	if job.Monitor.CaptureFailureContext && jobFailed(wr) {
+		wr.Lookup = lookupTargetHost(ctx, job.Monitor)
+	}
+	p.inFlight.Add(-1)
+	release()
+	p.results <- wr
+}
+
+// runJob runs a job's check, with retries or on every probe.
+func (p *Pool) runJob(ctx context.Context, job Job) WorkerResult {
 	c, err := p.registry.Get(job.Monitor.Type)
 	if err != nil {
-		p.results <- WorkerResult{
+		return WorkerResult{
 			Monitor: job.Monitor,
 			Err:     err,
+			Done:    job.Done,
 		}
-		return
 	}
 
-	timeout := time.Duration(job.Monitor.Timeout) * time.Second
-	checkCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+	if len(job.Monitor.Probes) > 0 {
+		return WorkerResult{
+			Monitor: job.Monitor,
+			Probes:  runProbes(ctx, c, job.Monitor),
+			Done:    job.Done,
+		}
+	}
 
-	result, err := c.Check(checkCtx, job.Monitor)
-	p.results <- WorkerResult{
+	result, err := runCheck(ctx, c, job.Monitor)
+	retries := 0
+	for retries < job.Monitor.Retries && checkFailed(job.Monitor, result, err) {
+		if !sleepCtx(ctx, time.Duration(job.Monitor.RetryInterval)*time.Second) {
+			break
+		}
+		retries++
+		result, err = runCheck(ctx, c, job.Monitor)
+	}
+	return WorkerResult{
 		Monitor: job.Monitor,
 		Result:  result,
 		Err:     err,
+		Retries: retries,
+		Done:    job.Done,
+	}
+}
+
+func runCheck(ctx context.Context, c checker.Checker, mon *storage.Monitor) (*checker.Result, error) {
+	checkCtx, cancel := context.WithTimeout(ctx, time.Duration(mon.Timeout)*time.Second)
+	defer cancel()
+	return c.Check(checkCtx, mon)
+}
+
+// checkFailed reports whether a check would mark mon down once assertions
+// and upside-down mode are applied. Degraded results are not retried.
+func checkFailed(mon *storage.Monitor, result *checker.Result, err error) bool {
+	if err != nil {
+		return true
+	}
+	r := *result
+	return computeFinalStatus(mon, &r) == "down"
+}
+
+// jobFailed reports whether the check, or any probe's check, in wr failed.
+func jobFailed(wr WorkerResult) bool {
+	if len(wr.Probes) == 0 {
+		return checkFailed(wr.Monitor, wr.Result, wr.Err)
+	}
+	for _, pr := range wr.Probes {
+		if checkFailed(wr.Monitor, pr.Result, pr.Err) {
+			return true
+		}
+	}
+	return false
+}
+
+// sleepCtx waits for d and reports false if ctx is done first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
 	}
 }
@@ -0,0 +1,154 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// Probe types.
+const (
+	ProbeTypeLocal = "local"
+	ProbeTypeAgent = "agent"
+)
+
+// agentOverhead is how long an agent probe may take beyond the check timeout
+// for the round trip to the agent.
+const agentOverhead = 5 * time.Second
+
+// maxAgentResponse caps the size of a result returned by a probe agent.
+const maxAgentResponse = 4 << 20
+
+var agentClient = &http.Client{}
+
+// ProbeResult is the outcome of a check run from one probe.
+type ProbeResult struct {
+	Probe  *storage.Probe
+	Result *checker.Result
+	Err    error
+}
+
+// runProbes checks mon from each of its probes concurrently.
+func runProbes(ctx context.Context, c checker.Checker, mon *storage.Monitor) []ProbeResult {
+	results := make([]ProbeResult, len(mon.Probes))
+	var wg sync.WaitGroup
+	for i, probe := range mon.Probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := runProbe(ctx, c, mon, probe)
+			results[i] = ProbeResult{Probe: probe, Result: res, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func runProbe(ctx context.Context, c checker.Checker, mon *storage.Monitor, probe *storage.Probe) (*checker.Result, error) {
+	timeout := time.Duration(mon.Timeout) * time.Second
+	if probe.Type == ProbeTypeAgent {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout+agentOverhead)
+		defer cancel()
+		return agentCheck(checkCtx, probe, mon)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	local := *mon
+	local.SourceAddr = probe.SourceAddr
+	return c.Check(checkCtx, &local)
+}
+
+// agentCheck forwards the check to the Asura instance at probe.URL (its
+// /api/v1/probe/check endpoint) and returns the raw result. Only what the
+// agent needs to run the check is sent; assertions are evaluated here.
+func agentCheck(ctx context.Context, probe *storage.Probe, mon *storage.Monitor) (*checker.Result, error) {
+	body, err := json.Marshal(&storage.Monitor{
+		Name:     mon.Name,
+		Type:     mon.Type,
+		Target:   mon.Target,
+		Interval: mon.Interval,
+		Timeout:  mon.Timeout,
+		Settings: mon.Settings,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, probe.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("probe agent: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if probe.Token != "" {
+		req.Header.Set("X-API-Key", probe.Token)
+	}
+
+	resp, err := agentClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("probe agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("probe agent returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	var result checker.Result
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAgentResponse)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("probe agent: decode result: %w", err)
+	}
+	return &result, nil
+}
+
+// ProbeCheck runs a check for a remote instance that uses this one as an
+// agent probe. It returns the raw checker result; assertions and upside-down
+// are applied by the caller.
+func (p *Pipeline) ProbeCheck(ctx context.Context, mon *storage.Monitor) (*checker.Result, error) {
+	c, err := p.registry.Get(mon.Type)
+	if err != nil {
+		return nil, err
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, time.Duration(mon.Timeout)*time.Second)
+	defer cancel()
+	result, err := c.Check(checkCtx, mon)
+	if err != nil {
+		return &checker.Result{Status: "down", Message: err.Error()}, nil
+	}
+	return result, nil
+}
+
+// probeStatusRank orders statuses from best to worst.
+var probeStatusRank = map[string]int{"up": 0, "degraded": 1, "down": 2}
+
+// evaluateProbes applies assertions to each probe's result and builds the
+// check result stored for it. The monitor takes the worst status across
+// probes; worst indexes the probe that determined it.
+func evaluateProbes(mon *storage.Monitor, probes []ProbeResult, maxBody int) (checks []*storage.CheckResult, results []*checker.Result, worst int) {
+	checks = make([]*storage.CheckResult, len(probes))
+	results = make([]*checker.Result, len(probes))
+	for i, pr := range probes {
+		result := pr.Result
+		if pr.Err != nil {
+			result = &checker.Result{Status: "down", Message: pr.Err.Error()}
+		}
+		cr := buildCheckResult(mon, result, computeFinalStatus(mon, result), maxBody)
+		probeID := pr.Probe.ID
+		cr.ProbeID = &probeID
+		checks[i] = cr
+		results[i] = result
+		if probeStatusRank[cr.Status] > probeStatusRank[checks[worst].Status] {
+			worst = i
+		}
+	}
+	return checks, results, worst
+}
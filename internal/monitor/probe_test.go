@@ -0,0 +1,164 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/storage"
+)
+
+type sourceRecorder struct {
+	sources chan string
+}
+
+func (c *sourceRecorder) Type() string { return "tcp" }
+
+func (c *sourceRecorder) Check(ctx context.Context, mon *storage.Monitor) (*checker.Result, error) {
+	c.sources <- mon.SourceAddr
+	return &checker.Result{Status: "up"}, nil
+}
+
+func TestAgentCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-API-Key") != "ak_agent" {
+			http.Error(w, `{"error":"invalid API key"}`, http.StatusUnauthorized)
+			return
+		}
+		var mon storage.Monitor
+		if err := json.NewDecoder(r.Body).Decode(&mon); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(mon.Assertions) != 0 {
+			http.Error(w, "assertions must not be forwarded", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(checker.Result{Status: "up", ResponseTime: 42, StatusCode: 200, Body: mon.Target})
+	}))
+	defer srv.Close()
+
+	mon := &storage.Monitor{
+		Name: "API", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 5,
+		Assertions: json.RawMessage(`[{"type":"status_code","operator":"eq","value":"200"}]`),
+	}
+
+	t.Run("returns agent result", func(t *testing.T) {
+		res, err := agentCheck(context.Background(), &storage.Probe{Type: ProbeTypeAgent, URL: srv.URL, Token: "ak_agent"}, mon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != "up" || res.ResponseTime != 42 || res.StatusCode != 200 || res.Body != mon.Target {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	})
+
+	t.Run("reports agent errors", func(t *testing.T) {
+		_, err := agentCheck(context.Background(), &storage.Probe{Type: ProbeTypeAgent, URL: srv.URL, Token: "wrong"}, mon)
+		if err == nil || !strings.Contains(err.Error(), "probe agent returned 401") {
+			t.Fatalf("expected agent status error, got %v", err)
+		}
+	})
+}
+
+func TestRunProbesBindsSourceAddr(t *testing.T) {
+	c := &sourceRecorder{sources: make(chan string, 2)}
+	mon := &storage.Monitor{
+		Type: "tcp", Target: "example.com:443", Timeout: 5,
+		Probes: []*storage.Probe{
+			{ID: 1, Name: "default", Type: ProbeTypeLocal},
+			{ID: 2, Name: "eth1", Type: ProbeTypeLocal, SourceAddr: "192.0.2.10"},
+		},
+	}
+
+	results := runProbes(context.Background(), c, mon)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for i, r := range results {
+		if r.Probe != mon.Probes[i] || r.Err != nil || r.Result.Status != "up" {
+			t.Errorf("result %d: %+v", i, r)
+		}
+	}
+	got := map[string]bool{<-c.sources: true, <-c.sources: true}
+	if !got[""] || !got["192.0.2.10"] {
+		t.Fatalf("expected checks from both sources, got %v", got)
+	}
+	if mon.SourceAddr != "" {
+		t.Fatal("runProbes must not modify the shared monitor")
+	}
+}
+
+func TestHandleResultProbes(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	mon := &storage.Monitor{
+		Name: "Regional", Type: "http", Target: "https://example.com",
+		Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1,
+	}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	us := &storage.Probe{Name: "us-east", Type: ProbeTypeLocal, Enabled: true}
+	eu := &storage.Probe{Name: "eu-west", Type: ProbeTypeAgent, URL: "https://eu.example.com/api/v1/probe/check", Enabled: true}
+	for _, pr := range []*storage.Probe{us, eu} {
+		if err := store.CreateProbe(ctx, pr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+	p.handleResult(ctx, WorkerResult{Monitor: mon, Probes: []ProbeResult{
+		{Probe: us, Result: &checker.Result{Status: "up", ResponseTime: 20}},
+		{Probe: eu, Err: errors.New("connection refused")},
+	}})
+
+	status, err := store.GetMonitorStatus(ctx, mon.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != "down" {
+		t.Fatalf("expected the worst probe status down, got %s", status.Status)
+	}
+
+	list, err := store.ListCheckResults(ctx, mon.ID, storage.Pagination{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := list.Data.([]*storage.CheckResult)
+	if len(checks) != 1 || checks[0].ProbeID == nil || *checks[0].ProbeID != eu.ID || checks[0].Status != "down" {
+		t.Fatalf("expected one check from the worst probe, got %+v", checks)
+	}
+
+	byProbe, err := store.GetUptimeByProbe(ctx, mon.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := make(map[string]string)
+	for _, u := range byProbe {
+		if u.ProbeID == nil || u.Total != 1 {
+			t.Fatalf("unexpected probe group %+v", u)
+		}
+		statuses[u.ProbeName] = u.LastStatus
+	}
+	if len(statuses) != 2 || statuses["us-east"] != "up" || statuses["eu-west"] != "down" {
+		t.Fatalf("unexpected per-probe statuses: %v", statuses)
+	}
+
+	inc, err := store.GetOpenIncident(ctx, mon.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inc.Cause != "eu-west: connection refused" {
+		t.Fatalf("expected the cause to name the probe, got %q", inc.Cause)
+	}
+}
@@ -0,0 +1,186 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// proxyCheckTimeout bounds one health check request through a proxy.
+const proxyCheckTimeout = 15 * time.Second
+
+// ProxyHealthWorker periodically fetches a canary URL through every proxy and
+// records the outcome. A proxy that keeps failing can be disabled, and
+// enabled again once it recovers.
+type ProxyHealthWorker struct {
+	store        storage.Store
+	pipeline     *Pipeline
+	interval     time.Duration
+	canaryURL    string
+	disableAfter int
+	reenable     bool
+	logger       *slog.Logger
+}
+
+// NewProxyHealthWorker creates a worker that checks proxies every interval.
+// A proxy is disabled after disableAfter consecutive failures (0 = never);
+// with reenable, one disabled that way is enabled on its next passing check.
+func NewProxyHealthWorker(store storage.Store, pipeline *Pipeline, interval time.Duration, canaryURL string, disableAfter int, reenable bool, logger *slog.Logger) *ProxyHealthWorker {
+	return &ProxyHealthWorker{
+		store:        store,
+		pipeline:     pipeline,
+		interval:     interval,
+		canaryURL:    canaryURL,
+		disableAfter: disableAfter,
+		reenable:     reenable,
+		logger:       logger,
+	}
+}
+
+// Run starts the proxy health check loop.
+func (w *ProxyHealthWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	// Run once on startup
+	w.checkAll(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.checkAll(ctx)
+		}
+	}
+}
+
+func (w *ProxyHealthWorker) checkAll(ctx context.Context) {
+	proxies, err := w.store.ListProxies(ctx)
+	if err != nil {
+		w.logger.Error("proxy health: list proxies", "error", err)
+		return
+	}
+
+	changed := false
+	for _, p := range proxies {
+		// Proxies the user disabled stay out of rotation and unchecked.
+		if !p.Enabled && !p.AutoDisabled {
+			continue
+		}
+		if w.update(ctx, p, w.probe(ctx, p)) {
+			changed = true
+		}
+	}
+	if changed {
+		w.pipeline.ReloadMonitors()
+	}
+}
+
+// update applies the outcome of one check to p, saves it and sends any
+// notification. It reports whether p was enabled or disabled.
+func (w *ProxyHealthWorker) update(ctx context.Context, p *storage.Proxy, checkErr error) bool {
+	now := time.Now().UTC()
+	p.LastCheckAt = &now
+	event := ""
+	if checkErr == nil {
+		p.LastOKAt = &now
+		p.LastError = ""
+		p.ConsecFails = 0
+		if p.AutoDisabled && w.reenable {
+			event = "proxy.enabled"
+		}
+	} else {
+		p.LastError = checkErr.Error()
+		p.ConsecFails++
+		if p.Enabled && w.disableAfter > 0 && p.ConsecFails >= w.disableAfter {
+			event = "proxy.disabled"
+		}
+	}
+
+	if err := w.store.UpdateProxyHealth(ctx, p); err != nil {
+		w.logger.Error("proxy health: save", "proxy_id", p.ID, "error", err)
+		return false
+	}
+	if event == "" {
+		return false
+	}
+	// p was read before the check, so the transition only applies if the
+	// user hasn't changed the proxy since.
+	changed, err := w.store.SetProxyAutoDisabled(ctx, p.ID, event == "proxy.disabled")
+	if err != nil {
+		w.logger.Error("proxy health: save", "proxy_id", p.ID, "error", err)
+		return false
+	}
+	if !changed {
+		return false
+	}
+	p.Enabled = event == "proxy.enabled"
+	p.AutoDisabled = !p.Enabled
+	w.logger.Info("proxy health changed", "proxy_id", p.ID, "event", event, "consec_fails", p.ConsecFails)
+	w.pipeline.publish(NotificationEvent{EventType: event, Proxy: p})
+	return true
+}
+
+// probe fetches the canary URL through p. Any response below 500 other than
+// 407 Proxy Authentication Required means the proxy forwarded the request.
+func (w *ProxyHealthWorker) probe(ctx context.Context, p *storage.Proxy) error {
+	ctx, cancel := context.WithTimeout(ctx, proxyCheckTimeout)
+	defer cancel()
+
+	baseDial := (&net.Dialer{Timeout: proxyCheckTimeout}).DialContext
+	transport := &http.Transport{
+		DialContext:       baseDial,
+		DisableKeepAlives: true,
+	}
+	pu := proxyURL(p)
+	if socks := checker.ProxyDialer(pu, baseDial); socks != nil {
+		transport.DialContext = socks
+	} else if hp := checker.HTTPProxyURL(pu); hp != nil {
+		transport.Proxy = http.ProxyURL(hp)
+	} else {
+		return fmt.Errorf("unsupported proxy protocol %q", p.Protocol)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.canaryURL, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusProxyAuthRequired {
+		return fmt.Errorf("canary returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// proxyURL returns the URL checkers use to connect through p.
+func proxyURL(p *storage.Proxy) string {
+	u := &url.URL{
+		Scheme: p.Protocol,
+		Host:   net.JoinHostPort(p.Host, fmt.Sprint(p.Port)),
+	}
+	if p.AuthUser != "" {
+		u.User = url.UserPassword(p.AuthUser, p.AuthPass)
+	}
+	return u.String()
+}
@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestProxyHealthWorker(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	// The fake HTTP proxy answers the canary request itself.
+	var failing atomic.Bool
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "canary.test" {
+			t.Errorf("proxy got request for %q", r.URL.String())
+		}
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxySrv.Close()
+
+	host, portStr, _ := net.SplitHostPort(proxySrv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	px := &storage.Proxy{Name: "edge", Protocol: "http", Host: host, Port: port, Enabled: true}
+	if err := store.CreateProxy(ctx, px); err != nil {
+		t.Fatal(err)
+	}
+	manual := &storage.Proxy{Name: "spare", Protocol: "http", Host: host, Port: port}
+	if err := store.CreateProxy(ctx, manual); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+	w := NewProxyHealthWorker(store, p, time.Minute, "http://canary.test/generate_204", 2, true, logger)
+
+	w.checkAll(ctx)
+	got, _ := store.GetProxy(ctx, px.ID)
+	if got.LastCheckAt == nil || got.LastOKAt == nil || got.ConsecFails != 0 || !got.Enabled {
+		t.Fatalf("after passing check: %+v", got)
+	}
+	if m, _ := store.GetProxy(ctx, manual.ID); m.LastCheckAt != nil {
+		t.Fatal("manually disabled proxy was checked")
+	}
+
+	failing.Store(true)
+	w.checkAll(ctx)
+	got, _ = store.GetProxy(ctx, px.ID)
+	if !got.Enabled || got.ConsecFails != 1 || got.LastError == "" {
+		t.Fatalf("after one failure: %+v", got)
+	}
+	if len(p.notifyChan) != 0 {
+		t.Fatalf("expected no events before the threshold, got %d", len(p.notifyChan))
+	}
+
+	w.checkAll(ctx)
+	got, _ = store.GetProxy(ctx, px.ID)
+	if got.Enabled || !got.AutoDisabled || got.ConsecFails != 2 {
+		t.Fatalf("after two failures: %+v", got)
+	}
+	ev := <-p.notifyChan
+	if ev.EventType != "proxy.disabled" || ev.Proxy == nil || ev.Proxy.ID != px.ID {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+
+	// An auto-disabled proxy keeps being checked and comes back.
+	failing.Store(false)
+	w.checkAll(ctx)
+	got, _ = store.GetProxy(ctx, px.ID)
+	if !got.Enabled || got.AutoDisabled || got.ConsecFails != 0 {
+		t.Fatalf("after recovery: %+v", got)
+	}
+	if ev := <-p.notifyChan; ev.EventType != "proxy.enabled" {
+		t.Fatalf("expected proxy.enabled, got %s", ev.EventType)
+	}
+}
+
+func TestProxyHealthWorkerNoReenable(t *testing.T) {
+	logger := discardLogger()
+	store := testStore(t)
+	ctx := context.Background()
+
+	// Nothing listens on the port, so every check fails to connect.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	px := &storage.Proxy{Name: "gone", Protocol: "socks5", Host: "127.0.0.1", Port: port, Enabled: true}
+	if err := store.CreateProxy(ctx, px); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+	w := NewProxyHealthWorker(store, p, time.Minute, "http://canary.test/", 1, false, logger)
+	w.checkAll(ctx)
+	got, _ := store.GetProxy(ctx, px.ID)
+	if got.Enabled || !got.AutoDisabled {
+		t.Fatalf("expected auto-disabled proxy: %+v", got)
+	}
+
+	// Editing the proxy hands it back to the user.
+	got.Enabled = true
+	if err := store.UpdateProxy(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = store.GetProxy(ctx, px.ID)
+	if !got.Enabled || got.AutoDisabled {
+		t.Fatalf("expected user-enabled proxy: %+v", got)
+	}
+
+	// A check that read the proxy before the user disabled it must not mark
+	// it auto-disabled, which would let the health check enable it again.
+	stale := *got
+	got.Enabled = false
+	if err := store.UpdateProxy(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	if w.update(ctx, &stale, errors.New("connection refused")) {
+		t.Fatal("stale check reported a state change")
+	}
+	got, _ = store.GetProxy(ctx, px.ID)
+	if got.Enabled || got.AutoDisabled || got.ConsecFails != 2 {
+		t.Fatalf("stale check overwrote the user's edit: %+v", got)
+	}
+	if len(p.notifyChan) != 1 {
+		t.Fatalf("expected only the first proxy.disabled event, got %d", len(p.notifyChan))
+	}
+}
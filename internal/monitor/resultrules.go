@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/y0f/asura/internal/htmltext"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// resultRules holds the parts of a monitor's configuration that shape how
+// its results are stored: the content selector and ignore patterns, the
+// redact patterns and the request headers it sends. They are parsed and
+// compiled once per loaded monitor rather than for every result.
+type resultRules struct {
+	content     bool
+	selector    *htmltext.Selector
+	ignore      []*regexp.Regexp
+	redact      []*regexp.Regexp
+	sentHeaders map[string]bool
+}
+
+// cachedRules ties compiled rules to the monitor value they came from.
+// Reloading monitors creates new values, so an edited monitor is compiled
+// again on its first result after the reload.
+type cachedRules struct {
+	mon   *storage.Monitor
+	rules *resultRules
+}
+
+// _resultRules maps monitor IDs to their cachedRules.
+var _resultRules sync.Map
+
+// rulesFor returns the compiled result rules of mon, compiling them when mon
+// is not the monitor value they were cached for.
+func rulesFor(mon *storage.Monitor) *resultRules {
+	if v, ok := _resultRules.Load(mon.ID); ok {
+		if c := v.(*cachedRules); c.mon == mon {
+			return c.rules
+		}
+	}
+	r := compileResultRules(mon)
+	_resultRules.Store(mon.ID, &cachedRules{mon: mon, rules: r})
+	return r
+}
+
+// cacheResultRules compiles the rules of freshly loaded monitors and drops
+// those of monitors that are no longer loaded.
+func cacheResultRules(monitors []*storage.Monitor) {
+	loaded := make(map[int64]bool, len(monitors))
+	for _, m := range monitors {
+		loaded[m.ID] = true
+		rulesFor(m)
+	}
+	_resultRules.Range(func(k, _ any) bool {
+		if !loaded[k.(int64)] {
+			_resultRules.Delete(k)
+		}
+		return true
+	})
+}
+
+// compileResultRules parses mon's settings and compiles its patterns.
+// Invalid selectors and patterns are skipped; validation rejects them when
+// the monitor is saved.
+func compileResultRules(mon *storage.Monitor) *resultRules {
+	r := &resultRules{}
+	for _, pattern := range mon.RedactPatterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			r.redact = append(r.redact, re)
+		}
+	}
+
+	var s struct {
+		Content *storage.ContentSettings `json:"content"`
+		Headers map[string]string        `json:"headers"`
+	}
+	if len(mon.Settings) == 0 || json.Unmarshal(mon.Settings, &s) != nil {
+		return r
+	}
+	if len(s.Headers) > 0 {
+		r.sentHeaders = make(map[string]bool, len(s.Headers))
+		for k := range s.Headers {
+			r.sentHeaders[http.CanonicalHeaderKey(k)] = true
+		}
+	}
+	if s.Content != nil {
+		r.content = true
+		if s.Content.Selector != "" {
+			if sel, err := htmltext.Compile(s.Content.Selector); err == nil {
+				r.selector = sel
+			}
+		}
+		for _, pattern := range s.Content.IgnorePatterns {
+			if re, err := regexp.Compile(pattern); err == nil {
+				r.ignore = append(r.ignore, re)
+			}
+		}
+	}
+	return r
+}
@@ -3,9 +3,9 @@ package monitor
 import (
 	"container/heap"
 	"context"
-	"fmt"
 	"log/slog"
-	"net/url"
+	"math/rand/v2"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,6 +16,7 @@ import (
 type schedulerEntry struct {
 	monitorID int64
 	nextRun   int64 // UnixNano for fast comparison
+	offset    int64 // jitter included in nextRun, nanoseconds
 	index     int
 }
 
@@ -48,7 +49,7 @@ func (h *schedulerHeap) Pop() any {
 // Scheduler dispatches check jobs using a min-heap ordered by next-run time.
 type Scheduler struct {
 	store             storage.Store
-	jobs              chan<- Job
+	jobs              chan Job
 	logger            *slog.Logger
 	mu                sync.RWMutex
 	monitors          map[int64]*storage.Monitor
@@ -57,9 +58,13 @@ type Scheduler struct {
 	effectiveInterval map[int64]int64 // nanoseconds
 	reload            chan struct{}
 	droppedJobs       atomic.Int64
+	lastRun           atomic.Int64   // unix nanoseconds of the last dispatch pass
+	lastDispatch      atomic.Int64   // unix nanoseconds of the last job handed to the pool
+	jitter            float64        // fraction of the interval, 0 = off
+	randFloat         func() float64 // in [0, 1)
 }
 
-func NewScheduler(store storage.Store, jobs chan<- Job, logger *slog.Logger) *Scheduler {
+func NewScheduler(store storage.Store, jobs chan Job, logger *slog.Logger) *Scheduler {
 	return &Scheduler{
 		store:             store,
 		jobs:              jobs,
@@ -68,9 +73,27 @@ func NewScheduler(store storage.Store, jobs chan<- Job, logger *slog.Logger) *Sc
 		entries:           make(map[int64]*schedulerEntry),
 		effectiveInterval: make(map[int64]int64),
 		reload:            make(chan struct{}, 1),
+		randFloat:         rand.Float64,
 	}
 }
 
+// SetJitter spreads each monitor's checks randomly within ±percent of its
+// interval so monitors sharing an interval don't all fire at once.
+func (s *Scheduler) SetJitter(percent int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jitter = float64(percent) / 100
+}
+
+// jitterOffset returns a random offset within ±jitter of iv.
+func (s *Scheduler) jitterOffset(iv int64) int64 {
+	if s.jitter <= 0 {
+		return 0
+	}
+	span := s.jitter * float64(iv)
+	return int64((s.randFloat()*2 - 1) * span)
+}
+
 // TriggerReload signals the scheduler to reload monitors.
 func (s *Scheduler) TriggerReload() {
 	select {
@@ -105,6 +128,8 @@ func (s *Scheduler) loadMonitors(ctx context.Context) {
 	}
 
 	s.resolveProxyURLs(ctx, monitors)
+	s.resolveProbes(ctx, monitors)
+	cacheResultRules(monitors)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -122,7 +147,13 @@ func (s *Scheduler) loadMonitors(ctx context.Context) {
 			if _, hasEff := s.effectiveInterval[m.ID]; !hasEff {
 				s.effectiveInterval[m.ID] = baseNano
 			}
-			entry := &schedulerEntry{monitorID: m.ID, nextRun: nowNano}
+			// First runs are spread forward over the jitter span instead of
+			// all firing at load.
+			var offset int64
+			if s.jitter > 0 {
+				offset = int64(s.randFloat() * s.jitter * float64(s.interval(m.ID, m.Interval)))
+			}
+			entry := &schedulerEntry{monitorID: m.ID, nextRun: nowNano + offset, offset: offset}
 			s.entries[m.ID] = entry
 			heap.Push(&s.heap, entry)
 		}
@@ -154,6 +185,7 @@ func (s *Scheduler) dispatch(now time.Time) {
 	defer s.mu.Unlock()
 
 	nowNano := now.UnixNano()
+	s.lastRun.Store(nowNano)
 
 	for s.heap.Len() > 0 && s.heap[0].nextRun <= nowNano {
 		entry := heap.Pop(&s.heap).(*schedulerEntry)
@@ -167,25 +199,75 @@ func (s *Scheduler) dispatch(now time.Time) {
 
 		iv := s.interval(entry.monitorID, mon.Interval)
 
+		// The next run is based on when this one was due before its jitter,
+		// so offsets don't accumulate and the average interval stays iv.
+		base := nowNano - entry.offset + iv
+		entry.offset = s.jitterOffset(iv)
+		entry.nextRun = base + entry.offset
+
 		if mon.Type == "heartbeat" {
-			entry.nextRun = nowNano + iv
 			heap.Push(&s.heap, entry)
 			continue
 		}
 
-		select {
-		case s.jobs <- Job{Monitor: mon}:
-			entry.nextRun = nowNano + iv
-		default:
-			s.droppedJobs.Add(1)
-			s.logger.Warn("scheduler: job channel full, skipping", "monitor_id", entry.monitorID)
-			entry.nextRun = nowNano + iv
+		if s.enqueue(Job{Monitor: mon}) {
+			s.lastDispatch.Store(nowNano)
 		}
 
 		heap.Push(&s.heap, entry)
 	}
 }
 
+// enqueue hands job to the worker pool. When the queue is full, the oldest
+// job not yet picked up by a worker is dropped to make room, so a backlog
+// never delays fresh checks behind stale ones. It reports whether job was
+// queued.
+func (s *Scheduler) enqueue(job Job) bool {
+	select {
+	case s.jobs <- job:
+		return true
+	default:
+	}
+
+	select {
+	case old := <-s.jobs:
+		s.droppedJobs.Add(1)
+		s.logger.Warn("scheduler: job queue full, dropping oldest", "monitor_id", old.Monitor.ID)
+		if old.Done != nil {
+			close(old.Done)
+		}
+	default:
+	}
+
+	select {
+	case s.jobs <- job:
+		return true
+	default:
+		s.droppedJobs.Add(1)
+		s.logger.Warn("scheduler: job queue full, skipping", "monitor_id", job.Monitor.ID)
+		return false
+	}
+}
+
+// LastRun returns when the scheduler last looked for due checks, or the
+// zero time before its first pass.
+func (s *Scheduler) LastRun() time.Time {
+	return unixNanoTime(s.lastRun.Load())
+}
+
+// LastDispatch returns when the scheduler last handed a check to the worker
+// pool, or the zero time if it hasn't yet.
+func (s *Scheduler) LastDispatch() time.Time {
+	return unixNanoTime(s.lastDispatch.Load())
+}
+
+func unixNanoTime(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
 // GetMultiplier returns the current effective interval multiplier for a monitor.
 func (s *Scheduler) GetMultiplier(monitorID int64) float64 {
 	s.mu.RLock()
@@ -205,6 +287,52 @@ func (s *Scheduler) GetMultiplier(monitorID int64) float64 {
 	return float64(eff) / float64(base)
 }
 
+// ScheduledCheck describes a monitor waiting in the scheduler heap.
+type ScheduledCheck struct {
+	MonitorID  int64     `json:"monitor_id"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	NextRun    time.Time `json:"next_run"`
+	Interval   float64   `json:"interval_seconds"` // effective, after adaptive adjustment
+	Multiplier float64   `json:"multiplier"`
+}
+
+// Snapshot returns the number of scheduled monitors, the heap size and the
+// next limit entries ordered by due time.
+func (s *Scheduler) Snapshot(limit int) (int, int, []ScheduledCheck) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]schedulerEntry, len(s.heap))
+	for i, e := range s.heap {
+		entries[i] = *e
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].nextRun < entries[j].nextRun })
+	if limit >= 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	next := make([]ScheduledCheck, 0, len(entries))
+	for _, e := range entries {
+		sc := ScheduledCheck{
+			MonitorID:  e.monitorID,
+			NextRun:    time.Unix(0, e.nextRun).UTC(),
+			Multiplier: 1.0,
+		}
+		if mon, ok := s.monitors[e.monitorID]; ok {
+			sc.Name = mon.Name
+			sc.Type = mon.Type
+			iv := s.interval(e.monitorID, mon.Interval)
+			sc.Interval = time.Duration(iv).Seconds()
+			if mon.Interval > 0 {
+				sc.Multiplier = float64(iv) / float64(int64(mon.Interval)*int64(time.Second))
+			}
+		}
+		next = append(next, sc)
+	}
+	return len(s.monitors), len(s.heap), next
+}
+
 // resolveProxyURLs populates ProxyURL for monitors that have a ProxyID set.
 func (s *Scheduler) resolveProxyURLs(ctx context.Context, monitors []*storage.Monitor) {
 	proxyIDs := make(map[int64]struct{})
@@ -223,14 +351,7 @@ func (s *Scheduler) resolveProxyURLs(ctx context.Context, monitors []*storage.Mo
 		if err != nil || !p.Enabled {
 			continue
 		}
-		u := &url.URL{
-			Scheme: p.Protocol,
-			Host:   fmt.Sprintf("%s:%d", p.Host, p.Port),
-		}
-		if p.AuthUser != "" {
-			u.User = url.UserPassword(p.AuthUser, p.AuthPass)
-		}
-		proxyCache[id] = u.String()
+		proxyCache[id] = proxyURL(p)
 	}
 
 	for _, m := range monitors {
@@ -240,6 +361,18 @@ func (s *Scheduler) resolveProxyURLs(ctx context.Context, monitors []*storage.Mo
 	}
 }
 
+// resolveProbes populates Probes with each monitor's enabled probes.
+func (s *Scheduler) resolveProbes(ctx context.Context, monitors []*storage.Monitor) {
+	probes, err := s.store.ListMonitorProbes(ctx)
+	if err != nil {
+		s.logger.Error("scheduler: load probes", "error", err)
+		return
+	}
+	for _, m := range monitors {
+		m.Probes = probes[m.ID]
+	}
+}
+
 // UpdateInterval sets the effective interval for a monitor and adjusts the heap.
 func (s *Scheduler) UpdateInterval(monitorID int64, interval time.Duration) {
 	s.mu.Lock()
@@ -258,6 +391,15 @@ func (s *Scheduler) UpdateInterval(monitorID int64, interval time.Duration) {
 		return
 	}
 
+	// Keep the pending run's jitter within the new interval's span.
+	if s.jitter > 0 {
+		offset := s.jitterOffset(nano)
+		entry.nextRun += offset - entry.offset
+		entry.offset = offset
+		heap.Fix(&s.heap, entry.index)
+		return
+	}
+
 	base := int64(mon.Interval) * int64(time.Second)
 	if base != nano {
 		heap.Fix(&s.heap, entry.index)
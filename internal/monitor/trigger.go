@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// TriggerCooldown is the minimum time between on-demand checks of one
+// monitor.
+const TriggerCooldown = 5 * time.Second
+
+// MaxTriggerBatch caps how many monitors one check-now request may name.
+const MaxTriggerBatch = 100
+
+var (
+	ErrTriggerHeartbeat = errors.New("heartbeat monitors are checked by incoming pings and cannot be checked on demand")
+	ErrTriggerPaused    = errors.New("monitor is paused")
+	ErrTriggerTooSoon   = fmt.Errorf("monitor was checked on demand less than %s ago", TriggerCooldown)
+	ErrTriggerNotStored = errors.New("check result could not be stored")
+	ErrTriggerDropped   = errors.New("check was dropped because the job queue is full")
+)
+
+// TriggerResult is the outcome of an on-demand check of one monitor. Err is
+// sql.ErrNoRows for an unknown monitor.
+type TriggerResult struct {
+	MonitorID int64
+	Check     *storage.CheckResult
+	Err       error
+}
+
+// TriggerNow checks the given monitors immediately, outside their schedule,
+// and waits until each result has been stored and evaluated like a scheduled
+// one. The jobs go straight to the worker pool; the scheduler heap is left
+// alone, so the next scheduled check still runs on time. Each monitor can be
+// triggered once per TriggerCooldown.
+func (p *Pipeline) TriggerNow(ctx context.Context, monitorIDs ...int64) []TriggerResult {
+	results := make([]TriggerResult, len(monitorIDs))
+	var pending []*storage.Monitor
+	var pendingIdx []int
+	now := time.Now()
+	for i, id := range monitorIDs {
+		results[i].MonitorID = id
+		mon, err := p.store.GetMonitor(ctx, id)
+		switch {
+		case err != nil:
+			results[i].Err = err
+		case mon.Type == "heartbeat":
+			results[i].Err = ErrTriggerHeartbeat
+		case !mon.Enabled:
+			results[i].Err = ErrTriggerPaused
+		case !p.allowTrigger(id, now):
+			results[i].Err = ErrTriggerTooSoon
+		default:
+			pending = append(pending, mon)
+			pendingIdx = append(pendingIdx, i)
+		}
+	}
+	if len(pending) == 0 {
+		return results
+	}
+
+	p.scheduler.resolveProxyURLs(ctx, pending)
+	p.scheduler.resolveProbes(ctx, pending)
+
+	done := make([]chan *storage.CheckResult, len(pending))
+	for j, mon := range pending {
+		done[j] = make(chan *storage.CheckResult, 1)
+		select {
+		case p.jobs <- Job{Monitor: mon, Done: done[j]}:
+		case <-ctx.Done():
+			done[j] = nil
+			results[pendingIdx[j]].Err = ctx.Err()
+		}
+	}
+	for j, ch := range done {
+		if ch == nil {
+			continue
+		}
+		select {
+		case cr, ok := <-ch:
+			switch {
+			case !ok:
+				results[pendingIdx[j]].Err = ErrTriggerDropped
+			case cr == nil:
+				results[pendingIdx[j]].Err = ErrTriggerNotStored
+			}
+			results[pendingIdx[j]].Check = cr
+		case <-ctx.Done():
+			results[pendingIdx[j]].Err = ctx.Err()
+		}
+	}
+	return results
+}
+
+// allowTrigger records an on-demand check of a monitor at now and reports
+// whether the cooldown since the previous one has passed.
+func (p *Pipeline) allowTrigger(monitorID int64, now time.Time) bool {
+	prev, loaded := p.lastTriggered.LoadOrStore(monitorID, now)
+	if !loaded {
+		return true
+	}
+	if now.Sub(prev.(time.Time)) < TriggerCooldown {
+		return false
+	}
+	return p.lastTriggered.CompareAndSwap(monitorID, prev, now)
+}
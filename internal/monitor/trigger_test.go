@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestTriggerNow(t *testing.T) {
+	store := testStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := checker.NewRegistry()
+	registry.Register(&flakyChecker{})
+	logger := discardLogger()
+	p := NewPipeline(store, registry, incident.NewManager(store, logger), 1, false, logger)
+	go p.pool.Run(ctx)
+	go p.processResults(ctx)
+
+	mk := func(name, typ string, enabled bool) int64 {
+		m := &storage.Monitor{
+			Name: name, Type: typ, Target: "127.0.0.1:1", Interval: 60, Timeout: 5,
+			Enabled: enabled, FailureThreshold: 1, SuccessThreshold: 1,
+		}
+		if err := store.CreateMonitor(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+		return m.ID
+	}
+	active := mk("active", "tcp", true)
+	paused := mk("paused", "tcp", false)
+	hb := mk("hb", "heartbeat", true)
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer waitCancel()
+	results := p.TriggerNow(waitCtx, active, paused, hb, 9999)
+
+	if r := results[0]; r.Err != nil || r.Check == nil || r.Check.ID == 0 || r.Check.Status != "up" {
+		t.Fatalf("active: %+v", r)
+	}
+	if !errors.Is(results[1].Err, ErrTriggerPaused) {
+		t.Errorf("paused: err = %v", results[1].Err)
+	}
+	if !errors.Is(results[2].Err, ErrTriggerHeartbeat) {
+		t.Errorf("heartbeat: err = %v", results[2].Err)
+	}
+	if !errors.Is(results[3].Err, sql.ErrNoRows) {
+		t.Errorf("unknown: err = %v", results[3].Err)
+	}
+
+	st, err := store.GetMonitorStatus(ctx, active)
+	if err != nil || st.Status != "up" {
+		t.Fatalf("status after trigger = %+v, %v", st, err)
+	}
+
+	again := p.TriggerNow(waitCtx, active)
+	if !errors.Is(again[0].Err, ErrTriggerTooSoon) {
+		t.Errorf("second trigger: err = %v, want ErrTriggerTooSoon", again[0].Err)
+	}
+}
+
+func TestTriggerNowContextDeadline(t *testing.T) {
+	store := testStore(t)
+	logger := discardLogger()
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+
+	m := &storage.Monitor{
+		Name: "m", Type: "tcp", Target: "127.0.0.1:1", Interval: 60, Timeout: 5,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1,
+	}
+	if err := store.CreateMonitor(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+
+	// No workers are running, so the job is queued but never completes.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	results := p.TriggerNow(ctx, m.ID)
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", results[0].Err)
+	}
+}
+
+func TestTriggerNowDropped(t *testing.T) {
+	store := testStore(t)
+	logger := discardLogger()
+	p := NewPipeline(store, checker.NewRegistry(), incident.NewManager(store, logger), 1, false, logger)
+	p.SetMaxQueue(1)
+
+	m := &storage.Monitor{
+		Name: "m", Type: "tcp", Target: "127.0.0.1:1", Interval: 60, Timeout: 5,
+		Enabled: true, FailureThreshold: 1, SuccessThreshold: 1,
+	}
+	if err := store.CreateMonitor(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+
+	// No workers are running, so a scheduled job pushes the queued trigger out.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan []TriggerResult, 1)
+	go func() { done <- p.TriggerNow(ctx, m.ID) }()
+	for len(p.jobs) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	p.scheduler.enqueue(Job{Monitor: &storage.Monitor{ID: m.ID + 1}})
+
+	results := <-done
+	if !errors.Is(results[0].Err, ErrTriggerDropped) {
+		t.Errorf("err = %v, want ErrTriggerDropped", results[0].Err)
+	}
+}
+
+func TestAllowTrigger(t *testing.T) {
+	p := &Pipeline{}
+	now := time.Now()
+	if !p.allowTrigger(1, now) {
+		t.Fatal("first trigger rejected")
+	}
+	if p.allowTrigger(1, now.Add(TriggerCooldown-time.Millisecond)) {
+		t.Error("trigger within cooldown allowed")
+	}
+	if !p.allowTrigger(2, now) {
+		t.Error("other monitor rejected")
+	}
+	if !p.allowTrigger(1, now.Add(TriggerCooldown)) {
+		t.Error("trigger after cooldown rejected")
+	}
+}
@@ -0,0 +1,92 @@
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// Incident actions that a notification link can perform.
+const (
+	ActionAck     = "ack"
+	ActionResolve = "resolve"
+)
+
+// ErrActionLinkExpired is returned for an action link token past its expiry.
+var ErrActionLinkExpired = errors.New("link expired")
+
+// SignActionLink returns a token that lets whoever holds it perform action
+// on incident id as the API key keyName until expires. The token is the
+// expiry in unix seconds, ".", and the hex HMAC-SHA256 of the incident,
+// action, key name and expiry.
+func SignActionLink(secret, keyName string, id int64, action string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + actionLinkMAC(secret, keyName, id, action, exp)
+}
+
+// VerifyActionLink checks a token made by SignActionLink for the same
+// incident, action and key name, and that it has not expired at now.
+func VerifyActionLink(secret, keyName string, id int64, action, token string, now time.Time) error {
+	exp, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return errors.New("malformed token")
+	}
+	ts, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return errors.New("malformed token")
+	}
+	if !hmac.Equal([]byte(mac), []byte(actionLinkMAC(secret, keyName, id, action, exp))) {
+		return errors.New("signature mismatch")
+	}
+	if now.Unix() >= ts {
+		return ErrActionLinkExpired
+	}
+	return nil
+}
+
+func actionLinkMAC(secret, keyName string, id int64, action, exp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d\n%s\n%s\n%s", id, action, keyName, exp)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetActionLinks makes incident notifications carry links that acknowledge
+// or resolve the incident as the API key keyName, signed with secret and
+// valid for ttl. An empty keyName leaves the links out.
+func (d *Dispatcher) SetActionLinks(keyName, secret string, ttl time.Duration) {
+	d.actionKey = keyName
+	d.actionSecret = secret
+	d.actionTTL = ttl
+}
+
+// addActionLinks fills in the payload's ack and resolve links for an
+// incident that is still unresolved. Acknowledged incidents only get a
+// resolve link.
+func (d *Dispatcher) addActionLinks(p *Payload) {
+	if d.actionKey == "" || p.Incident == nil || p.Incident.ID == 0 || p.EventType == "test" {
+		return
+	}
+	inc := p.Incident
+	if inc.Status == incident.StatusResolved {
+		return
+	}
+	expires := time.Now().Add(d.actionTTL)
+	if inc.Status == incident.StatusOpen {
+		p.AckURL = d.actionLink(inc, ActionAck, expires)
+	}
+	p.ResolveURL = d.actionLink(inc, ActionResolve, expires)
+}
+
+func (d *Dispatcher) actionLink(inc *storage.Incident, action string, expires time.Time) string {
+	token := SignActionLink(d.actionSecret, d.actionKey, inc.ID, action, expires)
+	return fmt.Sprintf("%s/api/v1/incidents/%d/%s?token=%s", d.publicURL, inc.ID, action, url.QueryEscape(token))
+}
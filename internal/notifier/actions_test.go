@@ -0,0 +1,102 @@
+package notifier
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestVerifyActionLink(t *testing.T) {
+	now := time.Now()
+	token := SignActionLink("secret", "chatops", 7, ActionAck, now.Add(time.Hour))
+
+	tests := []struct {
+		name    string
+		secret  string
+		key     string
+		id      int64
+		action  string
+		token   string
+		wantErr string
+	}{
+		{"valid", "secret", "chatops", 7, ActionAck, token, ""},
+		{"wrong secret", "other", "chatops", 7, ActionAck, token, "signature mismatch"},
+		{"other key", "secret", "admin", 7, ActionAck, token, "signature mismatch"},
+		{"other incident", "secret", "chatops", 8, ActionAck, token, "signature mismatch"},
+		{"other action", "secret", "chatops", 7, ActionResolve, token, "signature mismatch"},
+		{"expiry changed", "secret", "chatops", 7, ActionAck, "9999999999" + token[strings.Index(token, "."):], "signature mismatch"},
+		{"expired", "secret", "chatops", 7, ActionAck, SignActionLink("secret", "chatops", 7, ActionAck, now.Add(-time.Minute)), "expired"},
+		{"malformed", "secret", "chatops", 7, ActionAck, "abc", "malformed"},
+		{"empty", "secret", "chatops", 7, ActionAck, "", "malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyActionLink(tt.secret, tt.key, tt.id, tt.action, tt.token, now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddActionLinks(t *testing.T) {
+	d := &Dispatcher{publicURL: "https://asura.example.com"}
+
+	p := &Payload{EventType: "incident.created", Incident: &storage.Incident{ID: 3, Status: "open"}}
+	d.addActionLinks(p)
+	if p.AckURL != "" || p.ResolveURL != "" {
+		t.Fatalf("links set without an action key: %q %q", p.AckURL, p.ResolveURL)
+	}
+
+	d.SetActionLinks("chatops", "secret", time.Hour)
+	d.addActionLinks(p)
+	u, err := url.Parse(p.AckURL)
+	if err != nil || u.Path != "/api/v1/incidents/3/ack" {
+		t.Fatalf("ack url = %q", p.AckURL)
+	}
+	if err := VerifyActionLink("secret", "chatops", 3, ActionAck, u.Query().Get("token"), time.Now()); err != nil {
+		t.Fatalf("ack token: %v", err)
+	}
+	if !strings.HasPrefix(p.ResolveURL, "https://asura.example.com/api/v1/incidents/3/resolve?token=") {
+		t.Fatalf("resolve url = %q", p.ResolveURL)
+	}
+
+	acked := &Payload{EventType: "incident.reminder", Incident: &storage.Incident{ID: 3, Status: "acknowledged"}}
+	d.addActionLinks(acked)
+	if acked.AckURL != "" || acked.ResolveURL == "" {
+		t.Fatalf("acknowledged incident: ack %q, resolve %q", acked.AckURL, acked.ResolveURL)
+	}
+
+	resolved := &Payload{EventType: "incident.resolved", Incident: &storage.Incident{ID: 3, Status: "resolved"}}
+	d.addActionLinks(resolved)
+	if resolved.AckURL != "" || resolved.ResolveURL != "" {
+		t.Fatalf("resolved incident got links: %q %q", resolved.AckURL, resolved.ResolveURL)
+	}
+
+	buttons := slackActionButtons(p)
+	if len(buttons) != 2 || buttons[0]["url"] != p.AckURL || buttons[1]["url"] != p.ResolveURL {
+		t.Fatalf("slack buttons = %v", buttons)
+	}
+	if got := discordActionLinks(acked); got != "[Resolve]("+acked.ResolveURL+")" {
+		t.Fatalf("discord links = %q", got)
+	}
+}
+
+func TestActionLinkExpiredError(t *testing.T) {
+	token := SignActionLink("secret", "chatops", 1, ActionResolve, time.Now().Add(-time.Second))
+	err := VerifyActionLink("secret", "chatops", 1, ActionResolve, token, time.Now())
+	if !errors.Is(err, ErrActionLinkExpired) {
+		t.Fatalf("error = %v, want ErrActionLinkExpired", err)
+	}
+}
@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+type chatMessage struct {
+	Username    string `json:"username"`
+	Alias       string `json:"alias"`
+	Channel     string `json:"channel"`
+	Text        string `json:"text"`
+	Attachments []struct {
+		Title  string `json:"title"`
+		Color  string `json:"color"`
+		Fields []struct {
+			Title string `json:"title"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	} `json:"attachments"`
+}
+
+func chatServer(t *testing.T) (*httptest.Server, <-chan chatMessage) {
+	t.Helper()
+	msgs := make(chan chatMessage, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m chatMessage
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Errorf("invalid JSON: %s", body)
+		}
+		msgs <- m
+	}))
+	t.Cleanup(srv.Close)
+	return srv, msgs
+}
+
+func TestChatSenders(t *testing.T) {
+	inc := &storage.Incident{ID: 7, MonitorName: "api", Cause: "HTTP 503 @channel"}
+	for _, chType := range []string{"mattermost", "rocketchat"} {
+		t.Run(chType, func(t *testing.T) {
+			srv, msgs := chatServer(t)
+			d := NewDispatcher(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+			settings, _ := json.Marshal(MattermostSettings{WebhookURL: srv.URL, Channel: "alerts"})
+			sender := d.senders[chType]
+
+			err := sender.Send(t.Context(), &storage.NotificationChannel{Type: chType, Settings: settings},
+				&Payload{EventType: "incident.created", Incident: inc})
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := <-msgs
+			if m.Channel != "alerts" {
+				t.Errorf("channel = %q", m.Channel)
+			}
+			if m.Username+m.Alias != "Asura Monitor" {
+				t.Errorf("sender name = %q/%q", m.Username, m.Alias)
+			}
+			if len(m.Attachments) != 1 {
+				t.Fatalf("expected one attachment, got %+v", m)
+			}
+			a := m.Attachments[0]
+			if a.Color != "#E74C3C" {
+				t.Errorf("color = %q, want red for an open incident", a.Color)
+			}
+			if !strings.Contains(a.Title, "Incident #7 opened for api") {
+				t.Errorf("title = %q", a.Title)
+			}
+			fields := map[string]string{}
+			for _, f := range a.Fields {
+				fields[f.Title] = f.Value
+			}
+			if fields["Monitor"] != "api" || fields["Incident"] != "#7" {
+				t.Errorf("fields = %v", fields)
+			}
+			if strings.Contains(fields["Cause"], "@channel") || strings.Contains(a.Title, "@channel") {
+				t.Error("mentions in the cause should be neutralized")
+			}
+		})
+	}
+}
+
+func TestChatColor(t *testing.T) {
+	tests := map[string]string{
+		"incident.created":      "#E74C3C",
+		"incident.reminder":     "#E74C3C",
+		"incident.acknowledged": "#F39C12",
+		"incident.resolved":     "#2ECC71",
+		"test":                  "#2ECC71",
+	}
+	for ev, want := range tests {
+		if got := chatColor(ev); got != want {
+			t.Errorf("%s: got %s, want %s", ev, got, want)
+		}
+	}
+}
+
+func TestChatSendersBlockPrivateTargets(t *testing.T) {
+	srv, _ := chatServer(t)
+	settings, _ := json.Marshal(MattermostSettings{WebhookURL: srv.URL})
+	d := NewDispatcher(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), false)
+	for _, chType := range []string{"mattermost", "rocketchat"} {
+		err := d.SendTest(&storage.NotificationChannel{Type: chType, Settings: settings}, &storage.Incident{MonitorName: "Test Monitor"})
+		if err == nil {
+			t.Errorf("%s: expected test notification to a loopback webhook to be refused", chType)
+		}
+	}
+}
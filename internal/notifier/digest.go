@@ -0,0 +1,217 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// digestEvent is the event type of a summary of several buffered events.
+// Channels receive it instead of the events when they have a digest window.
+const digestEvent = "notification.digest"
+
+const (
+	// maxDigestEvents flushes a digest early so a long outage can't grow
+	// one without bound.
+	maxDigestEvents = 500
+	// maxDigestLines and maxDigestNames bound the summary message; the full
+	// list of events is still in the payload.
+	maxDigestLines = 20
+	maxDigestNames = 10
+)
+
+// SupportsDigest reports whether channels of the given type can batch events.
+// PagerDuty and Opsgenie open and close one alert per incident, which a
+// summary would break.
+func SupportsDigest(channelType string) bool {
+	switch channelType {
+	case "pagerduty", "opsgenie":
+		return false
+	}
+	return true
+}
+
+// digestBuffer holds the events waiting for one channel's digest window to
+// close.
+type digestBuffer struct {
+	sender   Sender
+	ch       *storage.NotificationChannel
+	payloads []*Payload
+	timer    *time.Timer
+}
+
+// digests buffers events per channel. The zero value is ready to use.
+type digests struct {
+	mu      sync.Mutex
+	buffers map[int64]*digestBuffer
+	closed  bool
+	sending sync.WaitGroup
+}
+
+// send delivers payload to ch now, or adds it to the channel's digest when
+// the channel has a digest window. Streamed check results are never batched.
+func (d *Dispatcher) send(sender Sender, ch *storage.NotificationChannel, payload *Payload) {
+	if ch.DigestWindowSeconds <= 0 || !SupportsDigest(ch.Type) || payload.EventType == streamEvent {
+		go d.sendWithRetry(sender, ch, payload)
+		return
+	}
+
+	d.digests.mu.Lock()
+	if d.digests.closed {
+		d.digests.mu.Unlock()
+		go d.sendWithRetry(sender, ch, payload)
+		return
+	}
+	if d.digests.buffers == nil {
+		d.digests.buffers = make(map[int64]*digestBuffer)
+	}
+	buf, ok := d.digests.buffers[ch.ID]
+	if !ok {
+		b := &digestBuffer{}
+		id := ch.ID
+		b.timer = time.AfterFunc(time.Duration(ch.DigestWindowSeconds)*time.Second, func() {
+			d.flushDigest(id, b)
+		})
+		buf = b
+		d.digests.buffers[ch.ID] = buf
+	}
+	// The latest copy of the channel wins, so edits made during the window
+	// apply to the digest.
+	buf.sender = sender
+	buf.ch = ch
+	buf.payloads = append(buf.payloads, payload)
+	flushNow := len(buf.payloads) >= maxDigestEvents ||
+		(ch.DigestFlushOnResolve && isResolution(payload.EventType))
+	d.digests.mu.Unlock()
+
+	if flushNow {
+		d.flushDigest(ch.ID, nil)
+	}
+}
+
+// flushDigest sends the events buffered for a channel, if any. A non-nil
+// only limits the flush to that buffer, so a window timer that fires late
+// can't cut short the next window.
+func (d *Dispatcher) flushDigest(channelID int64, only *digestBuffer) {
+	d.digests.mu.Lock()
+	buf, ok := d.digests.buffers[channelID]
+	if ok && only != nil && buf != only {
+		ok = false
+	}
+	if ok {
+		delete(d.digests.buffers, channelID)
+		buf.timer.Stop()
+		d.digests.sending.Add(1)
+	}
+	d.digests.mu.Unlock()
+	if !ok {
+		return
+	}
+	go func() {
+		defer d.digests.sending.Done()
+		d.sendWithRetry(buf.sender, buf.ch, digestPayload(buf.payloads))
+	}()
+}
+
+// FlushDigests sends every buffered digest and waits until they are
+// delivered or ctx is done. Events arriving afterwards are sent straight away.
+func (d *Dispatcher) FlushDigests(ctx context.Context) {
+	d.digests.mu.Lock()
+	d.digests.closed = true
+	ids := make([]int64, 0, len(d.digests.buffers))
+	for id := range d.digests.buffers {
+		ids = append(ids, id)
+	}
+	d.digests.mu.Unlock()
+
+	for _, id := range ids {
+		d.flushDigest(id, nil)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.digests.sending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		d.logger.Warn("notification digests still sending at shutdown", "error", ctx.Err())
+	}
+}
+
+func isResolution(eventType string) bool {
+	return eventType == "incident.resolved" || eventType == "monitor.recovered"
+}
+
+// digestPayload summarizes payloads. A single event is sent as it is.
+func digestPayload(payloads []*Payload) *Payload {
+	if len(payloads) == 1 {
+		return payloads[0]
+	}
+	p := &Payload{EventType: digestEvent, Severity: SeverityInfo, Digest: payloads}
+	for _, item := range payloads {
+		switch item.SeverityLevel() {
+		case SeverityCritical:
+			p.Severity = SeverityCritical
+		case SeverityWarning:
+			if p.Severity == SeverityInfo {
+				p.Severity = SeverityWarning
+			}
+		}
+	}
+	return p
+}
+
+// digestMonitorNames returns the names of the monitors the events are about,
+// in order of first appearance.
+func digestMonitorNames(payloads []*Payload) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range payloads {
+		name := ""
+		switch {
+		case p.Incident != nil:
+			name = p.Incident.MonitorName
+		case p.Monitor != nil:
+			name = p.Monitor.Name
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// formatDigest lists the affected monitors on the first line, followed by
+// one line per event.
+func formatDigest(p *Payload) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[DIGEST] %d notifications", len(p.Digest))
+	if names := digestMonitorNames(p.Digest); len(names) > 0 {
+		noun := "monitors"
+		if len(names) == 1 {
+			noun = "monitor"
+		}
+		fmt.Fprintf(&b, " for %d %s: ", len(names), noun)
+		if len(names) > maxDigestNames {
+			fmt.Fprintf(&b, "%s and %d more", strings.Join(names[:maxDigestNames], ", "), len(names)-maxDigestNames)
+		} else {
+			b.WriteString(strings.Join(names, ", "))
+		}
+	}
+	for i, item := range p.Digest {
+		if i == maxDigestLines {
+			fmt.Fprintf(&b, "\n- ... and %d more", len(p.Digest)-maxDigestLines)
+			break
+		}
+		b.WriteString("\n- ")
+		b.WriteString(FormatMessage(item))
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+type payloadSender struct {
+	sent chan *Payload
+}
+
+func (s *payloadSender) Type() string { return "record" }
+
+func (s *payloadSender) Send(_ context.Context, _ *storage.NotificationChannel, p *Payload) error {
+	s.sent <- p
+	return nil
+}
+
+func digestTestDispatcher(t *testing.T, ch *storage.NotificationChannel) (*Dispatcher, *payloadSender, *storage.SQLiteStore) {
+	t.Helper()
+	store := subscriberTestStore(t)
+	ch.Name, ch.Type, ch.Enabled, ch.Settings = "digest", "record", true, []byte("{}")
+	if err := store.CreateNotificationChannel(context.Background(), ch); err != nil {
+		t.Fatal(err)
+	}
+	rec := &payloadSender{sent: make(chan *Payload, 10)}
+	d := NewDispatcher(store, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+	d.RegisterSender(rec)
+	return d, rec, store
+}
+
+func incidentPayload(eventType string, id int64, monitor string) *Payload {
+	return &Payload{EventType: eventType, Incident: &storage.Incident{ID: id, MonitorName: monitor, Cause: "timeout"}}
+}
+
+func waitPayload(t *testing.T, sent <-chan *Payload) *Payload {
+	t.Helper()
+	select {
+	case p := <-sent:
+		return p
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a notification")
+		return nil
+	}
+}
+
+func TestDigestFlushOnResolve(t *testing.T) {
+	ch := &storage.NotificationChannel{DigestWindowSeconds: 600, DigestFlushOnResolve: true}
+	d, rec, store := digestTestDispatcher(t, ch)
+
+	d.NotifyWithPayload(incidentPayload("incident.created", 1, "api"))
+	d.NotifyWithPayload(incidentPayload("incident.created", 2, "db"))
+	select {
+	case p := <-rec.sent:
+		t.Fatalf("event sent before the digest closed: %s", p.EventType)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	d.NotifyWithPayload(incidentPayload("incident.resolved", 1, "api"))
+	p := waitPayload(t, rec.sent)
+	if p.EventType != digestEvent || len(p.Digest) != 3 || p.SeverityLevel() != SeverityCritical {
+		t.Fatalf("unexpected digest: %s with %d events, severity %s", p.EventType, len(p.Digest), p.SeverityLevel())
+	}
+	msg := FormatMessage(p)
+	if !strings.HasPrefix(msg, "[DIGEST] 3 notifications for 2 monitors: api, db\n") {
+		t.Errorf("unexpected digest message:\n%s", msg)
+	}
+	if !strings.Contains(msg, "\n- [RESOLVED] Incident #1 for api") {
+		t.Errorf("digest message misses the resolution:\n%s", msg)
+	}
+
+	// History keeps one row per delivered event.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		// Reads can hit SQLITE_BUSY while the rows are being written.
+		res, err := store.ListNotificationHistory(context.Background(), storage.NotifHistoryFilter{ChannelID: ch.ID}, storage.Pagination{Page: 1, PerPage: 10})
+		if err == nil && res.Total == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 3 history rows: %+v, %v", res, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestDigestWindowSendsSingleEvent(t *testing.T) {
+	d, rec, _ := digestTestDispatcher(t, &storage.NotificationChannel{DigestWindowSeconds: 1})
+
+	d.NotifyWithPayload(incidentPayload("incident.created", 1, "api"))
+	if p := waitPayload(t, rec.sent); p.EventType != "incident.created" || p.Digest != nil {
+		t.Fatalf("expected the lone event unchanged, got %s", p.EventType)
+	}
+}
+
+func TestFlushDigestsOnShutdown(t *testing.T) {
+	d, rec, _ := digestTestDispatcher(t, &storage.NotificationChannel{DigestWindowSeconds: 600})
+
+	d.NotifyWithPayload(incidentPayload("incident.created", 1, "api"))
+	d.NotifyWithPayload(incidentPayload("incident.resolved", 1, "api"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	d.FlushDigests(ctx)
+	select {
+	case p := <-rec.sent:
+		if p.EventType != digestEvent || len(p.Digest) != 2 {
+			t.Fatalf("unexpected digest: %s with %d events", p.EventType, len(p.Digest))
+		}
+	default:
+		t.Fatal("FlushDigests returned before the digest was sent")
+	}
+
+	// After shutdown events go out on their own.
+	d.NotifyWithPayload(incidentPayload("incident.created", 2, "db"))
+	if p := waitPayload(t, rec.sent); p.EventType != "incident.created" {
+		t.Fatalf("expected a direct send after shutdown, got %s", p.EventType)
+	}
+}
+
+func TestFormatDigestTruncates(t *testing.T) {
+	var items []*Payload
+	for i := range 25 {
+		items = append(items, incidentPayload("incident.created", int64(i+1), string(rune('a'+i))))
+	}
+	msg := FormatMessage(digestPayload(items))
+	lines := strings.Split(msg, "\n")
+	if len(lines) != maxDigestLines+2 {
+		t.Fatalf("expected %d lines, got %d", maxDigestLines+2, len(lines))
+	}
+	if !strings.HasSuffix(lines[0], "a, b, c, d, e, f, g, h, i, j and 15 more") {
+		t.Errorf("unexpected headline %q", lines[0])
+	}
+	if lines[len(lines)-1] != "- ... and 5 more" {
+		t.Errorf("unexpected last line %q", lines[len(lines)-1])
+	}
+}
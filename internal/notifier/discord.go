@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/y0f/asura/internal/storage"
@@ -41,16 +42,18 @@ func (s *DiscordSender) Send(ctx context.Context, channel *storage.NotificationC
 		color = 0xF39C12 // yellow
 	}
 
+	embed := map[string]any{
+		"title":       text,
+		"color":       color,
+		"description": string(marshalPayload(payload)),
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+	}
+	if links := discordActionLinks(payload); links != "" {
+		embed["fields"] = []map[string]any{{"name": "Actions", "value": links}}
+	}
 	body, _ := json.Marshal(map[string]any{
 		"username": "Asura Monitor",
-		"embeds": []map[string]any{
-			{
-				"title":       text,
-				"color":       color,
-				"description": string(marshalPayload(payload)),
-				"timestamp":   time.Now().UTC().Format(time.RFC3339),
-			},
-		},
+		"embeds":   []map[string]any{embed},
 	})
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, settings.WebhookURL, bytes.NewReader(body))
@@ -73,3 +76,16 @@ func (s *DiscordSender) Send(ctx context.Context, channel *storage.NotificationC
 
 	return nil
 }
+
+// discordActionLinks returns markdown links for the payload's incident
+// action links. Webhook messages can't carry link buttons.
+func discordActionLinks(p *Payload) string {
+	var links []string
+	if p.AckURL != "" {
+		links = append(links, "[Acknowledge]("+p.AckURL+")")
+	}
+	if p.ResolveURL != "" {
+		links = append(links, "[Resolve]("+p.ResolveURL+")")
+	}
+	return strings.Join(links, " · ")
+}
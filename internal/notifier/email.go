@@ -1,13 +1,21 @@
 package notifier
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
+	htmltemplate "html/template"
+	"io"
+	"mime/multipart"
+	"mime/quotedprintable"
 	"net/smtp"
+	"net/textproto"
 	"strings"
+	"text/template"
 
 	"github.com/y0f/asura/internal/storage"
 )
@@ -22,6 +30,34 @@ type EmailSettings struct {
 	CC       []string `json:"cc,omitempty"`
 	BCC      []string `json:"bcc,omitempty"`
 	TLSMode  string   `json:"tls_mode,omitempty"` // none, starttls (default), smtps
+	ReplyTo  string   `json:"reply_to,omitempty"`
+	// SubjectTemplate is a text/template rendered against the Payload.
+	SubjectTemplate string `json:"subject_template,omitempty"`
+	// HTMLTemplate is an html/template rendered against the Payload. When
+	// set, it replaces the built-in layout and the message carries a plain
+	// text alternative.
+	HTMLTemplate string `json:"html_template,omitempty"`
+}
+
+// ParseEmailSubjectTemplate parses an email subject template. It has the
+// same functions as webhook body templates.
+func ParseEmailSubjectTemplate(text string) (*template.Template, error) {
+	return template.New("subject").Funcs(webhookFuncs).Parse(text)
+}
+
+// ParseEmailHTMLTemplate parses an email body template. Payload values are
+// escaped for their HTML context. Escaping errors, which html/template only
+// reports on first execution, are returned too.
+func ParseEmailHTMLTemplate(text string) (*htmltemplate.Template, error) {
+	tmpl, err := htmltemplate.New("html").Funcs(htmltemplate.FuncMap(webhookFuncs)).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var escErr *htmltemplate.Error
+	if err := tmpl.Execute(io.Discard, nil); errors.As(err, &escErr) {
+		return nil, err
+	}
+	return tmpl, nil
 }
 
 type EmailSender struct{}
@@ -38,9 +74,49 @@ func (s *EmailSender) Send(ctx context.Context, channel *storage.NotificationCha
 		return fmt.Errorf("email host and recipients are required")
 	}
 
-	port := settings.Port
+	subject, err := emailSubject(settings, payload)
+	if err != nil {
+		return permanent(fmt.Errorf("render email subject template: %w", err))
+	}
+	msg, err := buildEmailMessage(settings, subject, payload)
+	if err != nil {
+		return permanent(fmt.Errorf("render email html template: %w", err))
+	}
+	allRcpt := make([]string, 0, len(settings.To)+len(settings.CC)+len(settings.BCC))
+	allRcpt = append(allRcpt, settings.To...)
+	allRcpt = append(allRcpt, settings.CC...)
+	allRcpt = append(allRcpt, settings.BCC...)
+
+	return deliverEmail(settings, allRcpt, msg)
+}
+
+// emailSubject renders the subject template, falling back to the default
+// summary line when none is set or it renders empty.
+func emailSubject(s EmailSettings, payload *Payload) (string, error) {
+	if s.SubjectTemplate != "" {
+		tmpl, err := ParseEmailSubjectTemplate(s.SubjectTemplate)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, payload); err != nil {
+			return "", err
+		}
+		if subject := strings.TrimSpace(sanitizeHeader(buf.String())); subject != "" {
+			return subject, nil
+		}
+	}
+	// A digest lists its events on the lines after the first.
+	subject, _, _ := strings.Cut(FormatMessage(payload), "\n")
+	return sanitizeHeader(subject), nil
+}
+
+// deliverEmail sends msg to rcpt through the SMTP server in s, defaulting the
+// port from the TLS mode.
+func deliverEmail(s EmailSettings, rcpt []string, msg []byte) error {
+	port := s.Port
 	if port == 0 {
-		switch settings.TLSMode {
+		switch s.TLSMode {
 		case "smtps":
 			port = 465
 		case "none":
@@ -50,23 +126,14 @@ func (s *EmailSender) Send(ctx context.Context, channel *storage.NotificationCha
 		}
 	}
 
-	subject := sanitizeHeader(FormatMessage(payload))
-	allRcpt := make([]string, 0, len(settings.To)+len(settings.CC)+len(settings.BCC))
-	allRcpt = append(allRcpt, settings.To...)
-	allRcpt = append(allRcpt, settings.CC...)
-	allRcpt = append(allRcpt, settings.BCC...)
-
-	msgBytes := buildEmailMessage(settings, subject, payload)
-	addr := fmt.Sprintf("%s:%d", settings.Host, port)
-	host := settings.Host
-
-	switch settings.TLSMode {
+	addr := fmt.Sprintf("%s:%d", s.Host, port)
+	switch s.TLSMode {
 	case "smtps":
-		return sendSMTPS(addr, host, settings, allRcpt, msgBytes)
+		return sendSMTPS(addr, s.Host, s, rcpt, msg)
 	case "none":
-		return sendPlain(addr, host, settings, allRcpt, msgBytes)
+		return sendPlain(addr, s.Host, s, rcpt, msg)
 	default:
-		return smtp.SendMail(addr, smtpAuth(settings, host), settings.From, allRcpt, msgBytes)
+		return smtp.SendMail(addr, smtpAuth(s, s.Host), s.From, rcpt, msg)
 	}
 }
 
@@ -123,19 +190,65 @@ func sendViaClient(client *smtp.Client, s EmailSettings, rcpt []string, msg []by
 	return w.Close()
 }
 
-func buildEmailMessage(s EmailSettings, subject string, payload *Payload) []byte {
+func buildEmailMessage(s EmailSettings, subject string, payload *Payload) ([]byte, error) {
 	var msg strings.Builder
 	msg.WriteString(fmt.Sprintf("From: %s\r\n", sanitizeHeader(s.From)))
 	msg.WriteString(fmt.Sprintf("To: %s\r\n", sanitizeHeader(strings.Join(s.To, ", "))))
 	if len(s.CC) > 0 {
 		msg.WriteString(fmt.Sprintf("Cc: %s\r\n", sanitizeHeader(strings.Join(s.CC, ", "))))
 	}
+	if s.ReplyTo != "" {
+		msg.WriteString(fmt.Sprintf("Reply-To: %s\r\n", sanitizeHeader(s.ReplyTo)))
+	}
 	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
 	msg.WriteString("MIME-Version: 1.0\r\n")
-	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	if s.HTMLTemplate == "" {
+		msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+		msg.WriteString("\r\n")
+		msg.WriteString(buildHTMLBody(subject, payload))
+		return []byte(msg.String()), nil
+	}
+
+	tmpl, err := ParseEmailHTMLTemplate(s.HTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var htmlBody bytes.Buffer
+	if err := tmpl.Execute(&htmlBody, payload); err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := writeEmailPart(mw, "text/plain; charset=UTF-8", []byte(FormatMessage(payload))); err != nil {
+		return nil, err
+	}
+	if err := writeEmailPart(mw, "text/html; charset=UTF-8", htmlBody.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	msg.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q\r\n", mw.Boundary()))
 	msg.WriteString("\r\n")
-	msg.WriteString(buildHTMLBody(subject, payload))
-	return []byte(msg.String())
+	msg.Write(body.Bytes())
+	return []byte(msg.String()), nil
+}
+
+// writeEmailPart adds a quoted-printable part, which keeps long template
+// lines within the SMTP line limit.
+func writeEmailPart(mw *multipart.Writer, contentType string, content []byte) error {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Transfer-Encoding", "quoted-printable")
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	qw := quotedprintable.NewWriter(part)
+	if _, err := qw.Write(content); err != nil {
+		return err
+	}
+	return qw.Close()
 }
 
 func buildHTMLBody(subject string, payload *Payload) string {
@@ -163,15 +276,44 @@ func buildHTMLBody(subject string, payload *Payload) string {
 		statusColor = "#fbbf24"
 		eventLabel = "Certificate Changed"
 		detail = "Certificate fingerprint changed for " + html.EscapeString(payload.Monitor.Name)
+	} else if payload.EventType == "monitor.down" && payload.Monitor != nil {
+		statusColor = "#f87171"
+		eventLabel = "Down"
+		detail = html.EscapeString(FormatMessage(payload))
+	} else if payload.EventType == "monitor.recovered" && payload.Monitor != nil {
+		statusColor = "#34d399"
+		eventLabel = "Recovered"
+		detail = html.EscapeString(FormatMessage(payload))
+	} else if payload.EventType == "monitor.latency_anomaly" && payload.Monitor != nil {
+		statusColor = "#fbbf24"
+		eventLabel = "Latency Anomaly"
+		detail = "Response time for " + html.EscapeString(payload.Monitor.Name) + " is well above its usual baseline"
 	} else if payload.EventType == "content.changed" {
 		statusColor = "#60a5fa"
 		eventLabel = "Content Changed"
+	} else if payload.EventType == digestEvent {
+		eventLabel = "Digest"
+		lines := strings.Split(FormatMessage(payload), "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		detail = strings.Join(lines, "<br>")
+		if payload.SeverityLevel() == SeverityCritical {
+			statusColor = "#f87171"
+		}
 	} else if payload.EventType == "test" {
 		statusColor = "#818cf8"
 		eventLabel = "Test"
 		detail = "This is a test notification from Asura"
 	}
 
+	return renderEmailHTML(statusColor, eventLabel, "Asura Alert", detail,
+		`Sent by <a href="#" style="color:#6b7280">Asura</a>`)
+}
+
+// renderEmailHTML lays out an email body with a colored top bar. detail and
+// footer are inserted as HTML and must already be escaped.
+func renderEmailHTML(color, label, heading, detail, footer string) string {
 	return `<!DOCTYPE html>
 <html>
 <head><meta charset="UTF-8"><meta name="viewport" content="width=device-width,initial-scale=1"></head>
@@ -179,16 +321,16 @@ func buildHTMLBody(subject string, payload *Payload) string {
 <table width="100%" cellpadding="0" cellspacing="0" style="background:#111827;padding:32px 0">
 <tr><td align="center">
 <table width="480" cellpadding="0" cellspacing="0" style="background:#1f2937;border:1px solid #374151;border-radius:8px;overflow:hidden">
-<tr><td style="background:` + statusColor + `;height:4px"></td></tr>
+<tr><td style="background:` + color + `;height:4px"></td></tr>
 <tr><td style="padding:24px 28px">
-<p style="margin:0 0 4px;font-size:11px;text-transform:uppercase;letter-spacing:.08em;color:#9ca3af">` + html.EscapeString(eventLabel) + `</p>
-<p style="margin:0;font-size:18px;font-weight:600;color:#f9fafb">Asura Alert</p>
+<p style="margin:0 0 4px;font-size:11px;text-transform:uppercase;letter-spacing:.08em;color:#9ca3af">` + html.EscapeString(label) + `</p>
+<p style="margin:0;font-size:18px;font-weight:600;color:#f9fafb">` + html.EscapeString(heading) + `</p>
 </td></tr>
 <tr><td style="padding:0 28px 24px">
 <p style="margin:0;font-size:14px;color:#d1d5db;line-height:1.6">` + detail + `</p>
 </td></tr>
 <tr><td style="padding:16px 28px;border-top:1px solid #374151">
-<p style="margin:0;font-size:11px;color:#6b7280">Sent by <a href="#" style="color:#6b7280">Asura</a></p>
+<p style="margin:0;font-size:11px;color:#6b7280">` + footer + `</p>
 </td></tr>
 </table>
 </td></tr>
@@ -2,9 +2,14 @@ package notifier
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net"
+	"net/mail"
 	"strings"
 	"testing"
 
@@ -134,7 +139,11 @@ func TestBuildEmailMessage(t *testing.T) {
 	payload := &Payload{EventType: "incident.created", Incident: &storage.Incident{MonitorName: "m", Cause: "c"}}
 	subject := "Test Subject"
 
-	msg := string(buildEmailMessage(s, subject, payload))
+	raw, err := buildEmailMessage(s, subject, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(raw)
 
 	checks := []string{
 		"From: from@example.com",
@@ -159,7 +168,11 @@ func TestBuildEmailMessageNoBCC(t *testing.T) {
 		BCC:  []string{"bcc@example.com"},
 	}
 	payload := &Payload{EventType: "test"}
-	msg := string(buildEmailMessage(s, "subj", payload))
+	raw, err := buildEmailMessage(s, "subj", payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(raw)
 
 	// BCC must not appear in headers
 	if strings.Contains(msg, "Bcc:") || strings.Contains(msg, "bcc@example.com") {
@@ -171,6 +184,94 @@ func TestBuildEmailMessageNoBCC(t *testing.T) {
 	}
 }
 
+func TestBuildEmailMessageHTMLTemplate(t *testing.T) {
+	s := EmailSettings{
+		From:            "from@example.com",
+		To:              []string{"to@example.com"},
+		ReplyTo:         "oncall@example.com",
+		SubjectTemplate: "[{{upper .EventType}}] {{.Incident.MonitorName}}",
+		HTMLTemplate:    `<h1 style="color:#b91c1c">{{.Incident.MonitorName}}</h1><p>{{.Incident.Cause}}</p>`,
+	}
+	payload := &Payload{EventType: "incident.created", Incident: &storage.Incident{MonitorName: "<api>", Cause: "timeout"}}
+
+	subject, err := emailSubject(s, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "[INCIDENT.CREATED] <api>" {
+		t.Fatalf("subject = %q", subject)
+	}
+	raw, err := buildEmailMessage(s, subject, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("Reply-To"); got != "oncall@example.com" {
+		t.Errorf("Reply-To = %q", got)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q", msg.Header.Get("Content-Type"))
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	bodies := map[string]string{}
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(p) // NextPart decodes quoted-printable
+		ct := p.Header.Get("Content-Type")
+		parts = append(parts, ct)
+		bodies[ct] = string(b)
+	}
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "text/plain") || !strings.HasPrefix(parts[1], "text/html") {
+		t.Fatalf("parts = %v, want text then html", parts)
+	}
+	html := bodies["text/html; charset=UTF-8"]
+	if !strings.Contains(html, "&lt;api&gt;") || !strings.Contains(html, `style="color:#b91c1c"`) {
+		t.Errorf("html part = %q", html)
+	}
+	if !strings.Contains(bodies["text/plain; charset=UTF-8"], "<api>") {
+		t.Errorf("text part = %q", bodies["text/plain; charset=UTF-8"])
+	}
+}
+
+func TestEmailSubjectFallback(t *testing.T) {
+	payload := &Payload{EventType: "test"}
+	subject, err := emailSubject(EmailSettings{SubjectTemplate: "{{if false}}x{{end}}"}, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != FormatMessage(payload) {
+		t.Errorf("expected default subject for an empty render, got %q", subject)
+	}
+	if _, err := emailSubject(EmailSettings{SubjectTemplate: "{{.Nope}}"}, payload); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestParseEmailHTMLTemplateEscapeError(t *testing.T) {
+	if _, err := ParseEmailHTMLTemplate(`<a href="{{.EventType}}">ok</a>`); err != nil {
+		t.Fatalf("valid template: %v", err)
+	}
+	// An unclosed attribute leaves the template in a non-text context.
+	if _, err := ParseEmailHTMLTemplate(`<a href="{{.EventType}}`); err == nil {
+		t.Fatal("expected an escaping error")
+	}
+	if _, err := ParseEmailHTMLTemplate(`{{.EventType`); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
+
 func TestEmailSettingsPortDefaults(t *testing.T) {
 	tests := []struct {
 		tlsMode  string
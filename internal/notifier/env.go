@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// envRef matches a ${NAME} reference to an environment variable. A bare
+// $NAME is left alone so secrets containing "$" don't need escaping.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// EnvPrefix is the prefix an environment variable needs before a channel
+// setting may reference it. Limiting references to dedicated variables keeps
+// the process environment, and any secrets in it, out of reach of anyone who
+// can edit a channel and send a test notification.
+const EnvPrefix = "ASURA_NOTIFY_"
+
+// _envSettingSuffixes selects the settings that may reference environment
+// variables: URLs, tokens, keys, secrets and passwords of every channel type.
+var _envSettingSuffixes = []string{"url", "token", "key", "secret", "password", "sid", "homeserver"}
+
+// HasEnvRef reports whether s references an environment variable.
+func HasEnvRef(s string) bool {
+	return envRef.MatchString(s)
+}
+
+// CheckSettingsEnv returns an error when a setting that is expanded at send
+// time references an environment variable without EnvPrefix.
+func CheckSettingsEnv(settings json.RawMessage) error {
+	if !strings.Contains(string(settings), "${") {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(settings, &fields); err != nil {
+		return nil
+	}
+	for key, raw := range fields {
+		if !envExpandable(key) {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			continue
+		}
+		for _, m := range envRef.FindAllStringSubmatch(s, -1) {
+			if !strings.HasPrefix(m[1], EnvPrefix) {
+				return fmt.Errorf("%s: environment variable %s must start with %s", key, m[1], EnvPrefix)
+			}
+		}
+	}
+	return nil
+}
+
+func envExpandable(key string) bool {
+	for _, suffix := range _envSettingSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandSettingsEnv replaces ${NAME} references in the URL, token, key,
+// secret and password fields of settings with values from lookup. Only names
+// starting with EnvPrefix are looked up; other references are left as
+// written. An unset variable expands to "" and its name is returned in
+// missing. It returns nil settings when nothing was expanded.
+func expandSettingsEnv(settings json.RawMessage, lookup func(string) (string, bool)) (expanded json.RawMessage, missing []string) {
+	if !strings.Contains(string(settings), "${") {
+		return nil, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(settings, &fields); err != nil {
+		return nil, nil
+	}
+	changed := false
+	for key, raw := range fields {
+		if !envExpandable(key) {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil || !HasEnvRef(s) {
+			continue
+		}
+		s = envRef.ReplaceAllStringFunc(s, func(ref string) string {
+			name := envRef.FindStringSubmatch(ref)[1]
+			if !strings.HasPrefix(name, EnvPrefix) {
+				return ref
+			}
+			changed = true
+			v, ok := lookup(name)
+			if !ok {
+				missing = append(missing, name)
+			}
+			return v
+		})
+		fields[key], _ = json.Marshal(s)
+	}
+	if !changed {
+		return nil, missing
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil, missing
+	}
+	return out, missing
+}
+
+// withEnv returns ch with environment variable references in its settings
+// resolved, or ch itself when it has none. The stored channel keeps the
+// references, so secrets never reach the database.
+func (d *Dispatcher) withEnv(ch *storage.NotificationChannel) *storage.NotificationChannel {
+	settings, missing := expandSettingsEnv(ch.Settings, os.LookupEnv)
+	for _, name := range missing {
+		d.logger.Warn("notification setting references unset environment variable",
+			"channel_id", ch.ID, "channel_type", ch.Type, "var", name)
+	}
+	if settings == nil {
+		return ch
+	}
+	expanded := *ch
+	expanded.Settings = settings
+	return &expanded
+}
@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestExpandSettingsEnv(t *testing.T) {
+	env := map[string]string{"ASURA_NOTIFY_SLACK": "https://hooks.slack.com/services/T0/B0/xyz", "ASURA_NOTIFY_PD": "r0ut1ng"}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name        string
+		settings    string
+		want        map[string]any // nil when nothing is expanded
+		wantMissing []string
+	}{
+		{"no references", `{"webhook_url":"https://example.com"}`, nil, nil},
+		{"whole value", `{"webhook_url":"${ASURA_NOTIFY_SLACK}","channel":"#ops"}`,
+			map[string]any{"webhook_url": "https://hooks.slack.com/services/T0/B0/xyz", "channel": "#ops"}, nil},
+		{"inside value", `{"routing_key":"prefix-${ASURA_NOTIFY_PD}"}`, map[string]any{"routing_key": "prefix-r0ut1ng"}, nil},
+		{"bare dollar untouched", `{"password":"pa$ASURA_NOTIFY_PD"}`, nil, nil},
+		{"other fields untouched", `{"channel":"${ASURA_NOTIFY_SLACK}"}`, nil, nil},
+		{"missing variable", `{"auth_token":"${ASURA_NOTIFY_NOPE}","account_sid":"${ASURA_NOTIFY_PD}"}`,
+			map[string]any{"auth_token": "", "account_sid": "r0ut1ng"}, []string{"ASURA_NOTIFY_NOPE"}},
+		{"non-string value", `{"api_key":123,"url":"${ASURA_NOTIFY_PD}"}`, map[string]any{"api_key": float64(123), "url": "r0ut1ng"}, nil},
+		{"unprefixed variable untouched", `{"url":"${HOME}"}`, nil, nil},
+		{"mixed prefixes", `{"url":"${ASURA_NOTIFY_PD}/${PATH}"}`, map[string]any{"url": "r0ut1ng/${PATH}"}, nil},
+		{"invalid json", `{"url":"${ASURA_NOTIFY_PD}"`, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, missing := expandSettingsEnv(json.RawMessage(tt.settings), lookup)
+			if !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Fatalf("missing = %v, want %v", missing, tt.wantMissing)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("expected no expansion, got %s", got)
+				}
+				return
+			}
+			var fields map[string]any
+			if err := json.Unmarshal(got, &fields); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Fatalf("settings = %v, want %v", fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendTestExpandsEnv(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+	defer srv.Close()
+	t.Setenv("ASURA_NOTIFY_TEST_HOOK", srv.URL+"/hooks/secret")
+
+	ch := &storage.NotificationChannel{ID: 1, Type: "webhook", Settings: json.RawMessage(`{"url":"${ASURA_NOTIFY_TEST_HOOK}"}`)}
+	d := NewDispatcher(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+	if err := d.SendTest(ch, nil); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/hooks/secret" {
+		t.Fatalf("path = %q, want /hooks/secret", gotPath)
+	}
+	if string(ch.Settings) != `{"url":"${ASURA_NOTIFY_TEST_HOOK}"}` {
+		t.Fatalf("stored settings changed: %s", ch.Settings)
+	}
+}
+
+func TestCheckSettingsEnv(t *testing.T) {
+	tests := []struct {
+		settings string
+		wantErr  bool
+	}{
+		{`{"url":"https://example.com"}`, false},
+		{`{"webhook_url":"${ASURA_NOTIFY_SLACK}"}`, false},
+		{`{"webhook_url":"${SLACK_HOOK}"}`, true},
+		{`{"api_key":"${ASURA_NOTIFY_KEY}-${AWS_SECRET_ACCESS_KEY}"}`, true},
+		{`{"channel":"${HOME}"}`, false},
+		{`{"password":"pa$HOME"}`, false},
+	}
+	for _, tt := range tests {
+		err := CheckSettingsEnv(json.RawMessage(tt.settings))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckSettingsEnv(%s) = %v, wantErr %v", tt.settings, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// linkFilters loads the notification filters on a monitor's channel links
+// and, when there are any, the monitor tag names they match against: its tag
+// entities and its plain tags.
+func (d *Dispatcher) linkFilters(ctx context.Context, monitorID int64, mon *storage.Monitor) (map[int64]*storage.NotificationFilter, []string) {
+	filters, err := d.store.GetMonitorNotificationFilters(ctx, monitorID)
+	if err != nil {
+		d.logger.Error("get monitor notification filters", "monitor_id", monitorID, "error", err)
+		return nil, nil
+	}
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	var tags []string
+	if mon != nil && mon.ID == monitorID {
+		tags = append(tags, mon.Tags...)
+	}
+	monTags, err := d.store.GetMonitorTags(ctx, monitorID)
+	if err != nil {
+		d.logger.Warn("get monitor tags for notification filters", "monitor_id", monitorID, "error", err)
+	}
+	for _, t := range monTags {
+		tags = append(tags, t.Name)
+	}
+	return filters, tags
+}
+
+// filterAllows reports whether the event in p passes f for a monitor
+// carrying tags. Tags match case-insensitively. Events without an incident,
+// such as content changes, are not held to the minimum severity.
+func filterAllows(f *storage.NotificationFilter, tags []string, p *Payload) bool {
+	if f.IsZero() {
+		return true
+	}
+	if len(f.Tags) > 0 && !slices.ContainsFunc(f.Tags, func(want string) bool {
+		return slices.ContainsFunc(tags, func(have string) bool { return strings.EqualFold(have, want) })
+	}) {
+		return false
+	}
+	if f.MinSeverity != "" && p.Incident != nil && severityRank(p.Incident.Severity) < severityRank(f.MinSeverity) {
+		return false
+	}
+	return true
+}
+
+// severityRank orders incident severities. Incidents from before severities
+// existed count as critical.
+func severityRank(severity string) int {
+	if severity == incident.SeverityWarning {
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestFilterAllows(t *testing.T) {
+	warning := &Payload{EventType: "incident.created", Incident: &storage.Incident{Severity: "warning"}}
+	critical := &Payload{EventType: "incident.created", Incident: &storage.Incident{Severity: "critical"}}
+	changed := &Payload{EventType: "content.changed"}
+
+	tests := []struct {
+		name    string
+		filter  *storage.NotificationFilter
+		tags    []string
+		payload *Payload
+		want    bool
+	}{
+		{"no filter", nil, nil, warning, true},
+		{"critical only, warning incident", &storage.NotificationFilter{MinSeverity: "critical"}, nil, warning, false},
+		{"critical only, critical incident", &storage.NotificationFilter{MinSeverity: "critical"}, nil, critical, true},
+		{"critical only, no incident", &storage.NotificationFilter{MinSeverity: "critical"}, nil, changed, true},
+		{"warning minimum", &storage.NotificationFilter{MinSeverity: "warning"}, nil, warning, true},
+		{"tag present", &storage.NotificationFilter{Tags: []string{"Production"}}, []string{"api", "production"}, warning, true},
+		{"tag missing", &storage.NotificationFilter{Tags: []string{"production"}}, []string{"staging"}, critical, false},
+		{"tag and severity", &storage.NotificationFilter{Tags: []string{"production"}, MinSeverity: "critical"}, []string{"production"}, warning, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterAllows(tt.filter, tt.tags, tt.payload); got != tt.want {
+				t.Errorf("filterAllows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyForMonitorFilters(t *testing.T) {
+	store := subscriberTestStore(t)
+	ctx := context.Background()
+
+	var channels []int64
+	for _, name := range []string{"pager", "chat"} {
+		ch := &storage.NotificationChannel{Name: name, Type: "record", Enabled: true, Settings: []byte("{}")}
+		if err := store.CreateNotificationChannel(ctx, ch); err != nil {
+			t.Fatal(err)
+		}
+		channels = append(channels, ch.ID)
+	}
+	pager, chat := channels[0], channels[1]
+
+	mon := &storage.Monitor{Name: "api", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10, Enabled: true, Tags: []string{"production"}, FailureThreshold: 1, SuccessThreshold: 1}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetMonitorNotificationChannels(ctx, mon.ID, channels); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetMonitorNotificationFilters(ctx, mon.ID, map[int64]*storage.NotificationFilter{
+		pager: {MinSeverity: "critical", Tags: []string{"production"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Saving the same channels again keeps the filter.
+	if err := store.SetMonitorNotificationChannels(ctx, mon.ID, channels); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &recordingSender{sent: make(chan int64, 10)}
+	d := NewDispatcher(store, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+	d.RegisterSender(rec)
+
+	collect := func() []int64 {
+		t.Helper()
+		var ids []int64
+		for {
+			select {
+			case id := <-rec.sent:
+				ids = append(ids, id)
+			case <-time.After(200 * time.Millisecond):
+				slices.Sort(ids)
+				return ids
+			}
+		}
+	}
+
+	d.NotifyForMonitor(mon.ID, &Payload{EventType: "incident.created", Monitor: mon, Incident: &storage.Incident{ID: 1, Severity: "warning"}})
+	if got, want := collect(), []int64{chat}; !slices.Equal(got, want) {
+		t.Errorf("warning incident sent to %v, want %v", got, want)
+	}
+
+	d.NotifyForMonitor(mon.ID, &Payload{EventType: "incident.created", Monitor: mon, Incident: &storage.Incident{ID: 2, Severity: "critical"}})
+	if got, want := collect(), []int64{pager, chat}; !slices.Equal(got, want) {
+		t.Errorf("critical incident sent to %v, want %v", got, want)
+	}
+}
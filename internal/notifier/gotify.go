@@ -17,6 +17,14 @@ type GotifySettings struct {
 	ServerURL string `json:"server_url"`
 	AppToken  string `json:"app_token"`
 	Priority  int    `json:"priority,omitempty"`
+	// SeverityPriorities overrides the priority (0-10) per severity.
+	SeverityPriorities map[string]int `json:"severity_priorities,omitempty"`
+}
+
+var gotifyDefaultPriorities = map[string]int{
+	SeverityCritical: 8,
+	SeverityWarning:  5,
+	SeverityInfo:     2,
 }
 
 type GotifySender struct{}
@@ -35,10 +43,7 @@ func (s *GotifySender) Send(ctx context.Context, channel *storage.NotificationCh
 		return fmt.Errorf("gotify app_token is required")
 	}
 
-	priority := settings.Priority
-	if priority == 0 {
-		priority = 5
-	}
+	priority := severityPriority(payload, settings.SeverityPriorities, settings.Priority, gotifyDefaultPriorities)
 
 	text := FormatMessage(payload)
 	title := "Asura Alert"
@@ -0,0 +1,112 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+type MattermostSettings struct {
+	WebhookURL string `json:"webhook_url"`
+	Channel    string `json:"channel,omitempty"`
+}
+
+type MattermostSender struct {
+	AllowPrivate bool
+}
+
+func (s *MattermostSender) Type() string { return "mattermost" }
+
+func (s *MattermostSender) Send(ctx context.Context, channel *storage.NotificationChannel, payload *Payload) error {
+	var settings MattermostSettings
+	if err := json.Unmarshal(channel.Settings, &settings); err != nil {
+		return fmt.Errorf("invalid mattermost settings: %w", err)
+	}
+	if settings.WebhookURL == "" {
+		return fmt.Errorf("mattermost webhook_url is required")
+	}
+
+	text := escapeChatMentions(FormatMessage(payload))
+	msg := map[string]any{
+		"username": "Asura Monitor",
+		"attachments": []map[string]any{
+			{
+				"fallback": text,
+				"color":    chatColor(payload.EventType),
+				"title":    text,
+				"fields":   chatFields(payload),
+			},
+		},
+	}
+	if settings.Channel != "" {
+		msg["channel"] = settings.Channel
+	}
+
+	body, _ := json.Marshal(msg)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, settings.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := safeClient(s.AllowPrivate).Do(req)
+	if err != nil {
+		return fmt.Errorf("mattermost request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("mattermost returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// chatColor is the attachment color for an event in Mattermost and
+// Rocket.Chat: red while an incident is open, yellow once acknowledged or
+// for warnings, green otherwise.
+func chatColor(eventType string) string {
+	switch eventType {
+	case "incident.created", "incident.reminder", "incident.escalated", "monitor.down":
+		return "#E74C3C"
+	case "incident.acknowledged", "cert.changed", "monitor.latency_anomaly", "proxy.disabled":
+		return "#F39C12"
+	default:
+		return "#2ECC71"
+	}
+}
+
+// chatFields lists the monitor, incident and cause as short attachment
+// fields, skipping any the payload does not carry.
+func chatFields(p *Payload) []map[string]any {
+	var fields []map[string]any
+	add := func(title, value string, short bool) {
+		if value != "" {
+			fields = append(fields, map[string]any{"title": title, "value": escapeChatMentions(value), "short": short})
+		}
+	}
+	switch {
+	case p.Incident != nil:
+		add("Monitor", p.Incident.MonitorName, true)
+		if p.Incident.ID != 0 {
+			add("Incident", "#"+strconv.FormatInt(p.Incident.ID, 10), true)
+		}
+		add("Cause", p.Incident.Cause, false)
+	case p.Monitor != nil:
+		add("Monitor", p.Monitor.Name, true)
+	}
+	return fields
+}
+
+// escapeChatMentions breaks @channel, @all and @here style mentions so that
+// monitor names and failure messages cannot ping a whole channel.
+func escapeChatMentions(s string) string {
+	return strings.ReplaceAll(s, "@", "@\u200b")
+}
@@ -3,8 +3,11 @@ package notifier
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/y0f/asura/internal/storage"
@@ -19,16 +22,74 @@ type Sender interface {
 // Payload contains the notification data.
 type Payload struct {
 	EventType string                 `json:"event_type"`
+	Severity  string                 `json:"severity,omitempty"` // critical, warning, info; derived from EventType when empty
 	Incident  *storage.Incident      `json:"incident,omitempty"`
 	Monitor   *storage.Monitor       `json:"monitor,omitempty"`
 	Change    *storage.ContentChange `json:"change,omitempty"`
+	Check     *storage.CheckResult   `json:"check,omitempty"`
+	Proxy     *storage.Proxy         `json:"proxy,omitempty"`
+	// AckURL and ResolveURL act on the incident without logging in. They
+	// are only set when action links are configured.
+	AckURL     string `json:"ack_url,omitempty"`
+	ResolveURL string `json:"resolve_url,omitempty"`
+	// Digest holds the events summarized by a notification.digest payload.
+	Digest []*Payload `json:"digest,omitempty"`
+}
+
+// Severity levels that push channels map onto their priority scales.
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// SeverityLevel returns the payload's severity, falling back to a default
+// for its event type.
+func (p *Payload) SeverityLevel() string {
+	if p.Severity != "" {
+		return p.Severity
+	}
+	switch p.EventType {
+	case "incident.created", "incident.reminder", "incident.escalated", "monitor.down":
+		return SeverityCritical
+	case "content.changed", "cert.changed", "monitor.latency_anomaly", "proxy.disabled":
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// severityPriority picks a provider priority for p. A per-severity override
+// wins, then the channel's fixed priority, then the provider defaults.
+func severityPriority(p *Payload, overrides map[string]int, fixed int, defaults map[string]int) int {
+	sev := p.SeverityLevel()
+	if v, ok := overrides[sev]; ok {
+		return v
+	}
+	if fixed != 0 {
+		return fixed
+	}
+	return defaults[sev]
 }
 
 type Dispatcher struct {
-	store   storage.Store
-	senders map[string]Sender
-	logger  *slog.Logger
-	sem     chan struct{}
+	store          storage.Store
+	senders        map[string]Sender
+	logger         *slog.Logger
+	sem            chan struct{}
+	defaultChannel string
+	ownerChannels  map[string]string
+	publicURL      string
+	actionKey      string
+	actionSecret   string
+	actionTTL      time.Duration
+
+	minNotifyInterval time.Duration
+	lastNotifyMu      sync.Mutex
+	lastNotify        map[string]time.Time
+
+	digests digests
+	oncall  oncallCache
 }
 
 const maxConcurrentSends = 10
@@ -53,6 +114,10 @@ func NewDispatcher(store storage.Store, logger *slog.Logger, allowPrivateTargets
 	d.RegisterSender(&GoogleChatSender{})
 	d.RegisterSender(&MatrixSender{})
 	d.RegisterSender(&GotifySender{})
+	d.RegisterSender(&MattermostSender{AllowPrivate: allowPrivateTargets})
+	d.RegisterSender(&RocketChatSender{AllowPrivate: allowPrivateTargets})
+	d.RegisterSender(&TwilioSender{})
+	d.RegisterSender(&SignalSender{AllowPrivate: allowPrivateTargets})
 	return d
 }
 
@@ -60,17 +125,80 @@ func (d *Dispatcher) RegisterSender(s Sender) {
 	d.senders[s.Type()] = s
 }
 
+// SetDefaultChannel names the channel that monitors without explicit
+// notification channels send to. Empty keeps sending them to every channel.
+func (d *Dispatcher) SetDefaultChannel(name string) {
+	d.defaultChannel = name
+}
+
+// SetOwnerChannels maps monitor owners to the channel that monitors without
+// explicit notification channels send to. It takes precedence over the
+// default channel.
+func (d *Dispatcher) SetOwnerChannels(m map[string]string) {
+	d.ownerChannels = m
+}
+
+// SetMinNotifyInterval drops a monitor notification when one of the same
+// event type was sent for the same monitor less than interval ago. Zero
+// disables deduplication.
+func (d *Dispatcher) SetMinNotifyInterval(interval time.Duration) {
+	d.minNotifyInterval = interval
+}
+
+// duplicate reports whether payload repeats an event sent for monitorID
+// within the minimum notify interval, and otherwise records it as sent.
+// Resolutions and streamed check results are never dropped.
+func (d *Dispatcher) duplicate(monitorID int64, payload *Payload) bool {
+	if d.minNotifyInterval <= 0 || monitorID == 0 {
+		return false
+	}
+	switch payload.EventType {
+	case "incident.resolved", "monitor.recovered", streamEvent:
+		return false
+	}
+
+	key := fmt.Sprintf("%s/%d", payload.EventType, monitorID)
+	now := time.Now()
+	d.lastNotifyMu.Lock()
+	defer d.lastNotifyMu.Unlock()
+	if last, ok := d.lastNotify[key]; ok && now.Sub(last) < d.minNotifyInterval {
+		return true
+	}
+	if d.lastNotify == nil {
+		d.lastNotify = make(map[string]time.Time)
+	}
+	for k, last := range d.lastNotify {
+		if now.Sub(last) >= d.minNotifyInterval {
+			delete(d.lastNotify, k)
+		}
+	}
+	d.lastNotify[key] = now
+	return false
+}
+
 func (d *Dispatcher) NotifyWithPayload(payload *Payload) {
+	if payload.Monitor != nil && d.duplicate(payload.Monitor.ID, payload) {
+		d.logger.Debug("duplicate notification dropped", "monitor_id", payload.Monitor.ID, "event", payload.EventType)
+		return
+	}
+	d.addActionLinks(payload)
+	go d.notifySubscribers(payload)
+
 	channels, err := d.store.ListNotificationChannels(context.Background())
 	if err != nil {
 		d.logger.Error("list notification channels", "error", err)
 		return
 	}
 
+	now := time.Now()
 	for _, ch := range channels {
 		if !ch.Enabled || !matchesEvent(ch.Events, payload.EventType) {
 			continue
 		}
+		if !channelActive(ch, now) {
+			d.logger.Debug("channel outside schedule, skipping", "channel_id", ch.ID, "event", payload.EventType)
+			continue
+		}
 
 		sender, ok := d.senders[ch.Type]
 		if !ok {
@@ -78,31 +206,48 @@ func (d *Dispatcher) NotifyWithPayload(payload *Payload) {
 			continue
 		}
 
-		go d.sendWithRetry(sender, ch, payload)
+		d.send(sender, ch, payload)
 	}
 }
 
 func (d *Dispatcher) NotifyForMonitor(monitorID int64, payload *Payload) {
+	if d.duplicate(monitorID, payload) {
+		d.logger.Debug("duplicate notification dropped", "monitor_id", monitorID, "event", payload.EventType)
+		return
+	}
+	d.addActionLinks(payload)
+	go d.notifySubscribers(payload)
+
 	channels, err := d.store.ListNotificationChannels(context.Background())
 	if err != nil {
 		d.logger.Error("list notification channels", "error", err)
 		return
 	}
 
-	assignedIDs, err := d.store.GetMonitorNotificationChannelIDs(context.Background(), monitorID)
+	assignedIDs, err := d.assignedChannelIDs(context.Background(), monitorID)
 	if err != nil {
 		d.logger.Error("get monitor notification channels", "error", err)
 		return
 	}
 
-	var allowed map[int64]bool
-	if len(assignedIDs) > 0 {
-		allowed = make(map[int64]bool, len(assignedIDs))
-		for _, id := range assignedIDs {
-			allowed[id] = true
+	skipDefault := false
+	ownerChannel := ""
+	if len(assignedIDs) == 0 && (d.defaultChannel != "" || len(d.ownerChannels) > 0) {
+		mon := payload.Monitor
+		if mon == nil || mon.ID != monitorID {
+			if mon, err = d.store.GetMonitor(context.Background(), monitorID); err != nil {
+				d.logger.Warn("get monitor for default channel", "monitor_id", monitorID, "error", err)
+			}
+		}
+		if mon != nil {
+			skipDefault = mon.SkipDefaultChannel
+			ownerChannel = d.ownerChannels[mon.Owner]
 		}
 	}
+	allowed := monitorChannels(channels, assignedIDs, ownerChannel, d.defaultChannel, skipDefault)
+	filters, monitorTags := d.linkFilters(context.Background(), monitorID, payload.Monitor)
 
+	now := time.Now()
 	for _, ch := range channels {
 		if !ch.Enabled || !matchesEvent(ch.Events, payload.EventType) {
 			continue
@@ -110,12 +255,84 @@ func (d *Dispatcher) NotifyForMonitor(monitorID int64, payload *Payload) {
 		if allowed != nil && !allowed[ch.ID] {
 			continue
 		}
+		if f := filters[ch.ID]; !filterAllows(f, monitorTags, payload) {
+			d.logger.Debug("notification filter excludes event, skipping", "channel_id", ch.ID, "monitor_id", monitorID, "event", payload.EventType)
+			continue
+		}
+		// Tagged channels only follow their tags, not the every-channel fallback.
+		if allowed == nil && len(ch.TagIDs) > 0 {
+			continue
+		}
+		if !channelActive(ch, now) {
+			d.logger.Debug("channel outside schedule, skipping", "channel_id", ch.ID, "event", payload.EventType)
+			continue
+		}
+		sender, ok := d.senders[ch.Type]
+		if !ok {
+			d.logger.Warn("no sender for channel type", "type", ch.Type)
+			continue
+		}
+		d.send(sender, ch, payload)
+	}
+}
+
+// assignedChannelIDs returns the channels attached to a monitor explicitly
+// plus those tagged with any of the monitor's tags, without duplicates.
+func (d *Dispatcher) assignedChannelIDs(ctx context.Context, monitorID int64) ([]int64, error) {
+	ids, err := d.store.GetMonitorNotificationChannelIDs(ctx, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := d.store.GetMonitorTags(ctx, monitorID)
+	if err != nil || len(tags) == 0 {
+		return ids, err
+	}
+	tagIDs := make([]int64, len(tags))
+	for i, t := range tags {
+		tagIDs[i] = t.TagID
+	}
+	tagged, err := d.store.GetChannelsByTag(ctx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range tagged {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// NotifyChannels sends payload to the given channels regardless of their
+// event filters, skipping disabled channels and those outside their schedule.
+func (d *Dispatcher) NotifyChannels(channelIDs []int64, payload *Payload) {
+	d.addActionLinks(payload)
+	channels, err := d.store.ListNotificationChannels(context.Background())
+	if err != nil {
+		d.logger.Error("list notification channels", "error", err)
+		return
+	}
+
+	wanted := make(map[int64]bool, len(channelIDs))
+	for _, id := range channelIDs {
+		wanted[id] = true
+	}
+
+	now := time.Now()
+	for _, ch := range channels {
+		if !ch.Enabled || !wanted[ch.ID] {
+			continue
+		}
+		if !channelActive(ch, now) {
+			d.logger.Debug("channel outside schedule, skipping", "channel_id", ch.ID, "event", payload.EventType)
+			continue
+		}
 		sender, ok := d.senders[ch.Type]
 		if !ok {
 			d.logger.Warn("no sender for channel type", "type", ch.Type)
 			continue
 		}
-		go d.sendWithRetry(sender, ch, payload)
+		d.send(sender, ch, payload)
 	}
 }
 
@@ -124,7 +341,7 @@ func (d *Dispatcher) SendTest(ch *storage.NotificationChannel, inc *storage.Inci
 	if !ok {
 		return fmt.Errorf("no sender for type: %s", ch.Type)
 	}
-	return sender.Send(context.Background(), ch, &Payload{
+	return sender.Send(context.Background(), d.withEnv(ch), &Payload{
 		EventType: "test",
 		Incident:  inc,
 	})
@@ -135,6 +352,15 @@ const (
 	baseBackoff = 2 * time.Second
 )
 
+// permanentError marks a send failure that retrying cannot fix, such as a
+// template that fails to render.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error { return &permanentError{err: err} }
+
 func (d *Dispatcher) sendWithRetry(sender Sender, ch *storage.NotificationChannel, payload *Payload) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -151,6 +377,14 @@ func (d *Dispatcher) sendWithRetry(sender Sender, ch *storage.NotificationChanne
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
+	ch = d.withEnv(d.routeToOncall(ctx, ch, time.Now()))
+
+	if payload.EventType == streamEvent {
+		if err := sender.Send(ctx, ch, payload); err != nil {
+			d.logger.Debug("check stream send failed", "channel_id", ch.ID, "error", err)
+		}
+		return
+	}
 
 	var lastErr error
 	for attempt := 0; attempt < maxRetries; attempt++ {
@@ -158,6 +392,17 @@ func (d *Dispatcher) sendWithRetry(sender Sender, ch *storage.NotificationChanne
 			time.Sleep(baseBackoff * time.Duration(1<<(attempt-1)))
 		}
 		if err := sender.Send(ctx, ch, payload); err != nil {
+			var perm *permanentError
+			if errors.As(err, &perm) {
+				d.logger.Error("notification skipped",
+					"channel_id", ch.ID,
+					"channel_type", ch.Type,
+					"event", payload.EventType,
+					"error", err,
+				)
+				d.recordHistory(ch, payload, "failed", err.Error())
+				return
+			}
 			lastErr = err
 			d.logger.Warn("notification send attempt failed",
 				"channel_id", ch.ID,
@@ -190,6 +435,13 @@ func (d *Dispatcher) sendWithRetry(sender Sender, ch *storage.NotificationChanne
 }
 
 func (d *Dispatcher) recordHistory(ch *storage.NotificationChannel, payload *Payload, status, errMsg string) {
+	// A digest is recorded as the events it delivered.
+	if len(payload.Digest) > 0 {
+		for _, item := range payload.Digest {
+			d.recordHistory(ch, item, status, errMsg)
+		}
+		return
+	}
 	h := &storage.NotificationHistory{
 		ChannelID: ch.ID,
 		EventType: payload.EventType,
@@ -209,9 +461,56 @@ func (d *Dispatcher) recordHistory(ch *storage.NotificationChannel, payload *Pay
 	}
 }
 
+// monitorChannels returns the IDs of the channels a monitor notifies, or nil
+// for every channel. A monitor without assigned channels uses its owner's
+// channel, then the default channel when one is configured and exists,
+// unless it opts out of the default, in which case it notifies no channel.
+func monitorChannels(channels []*storage.NotificationChannel, assignedIDs []int64, ownerName, defaultName string, skipDefault bool) map[int64]bool {
+	if len(assignedIDs) > 0 {
+		allowed := make(map[int64]bool, len(assignedIDs))
+		for _, id := range assignedIDs {
+			allowed[id] = true
+		}
+		return allowed
+	}
+	if ownerName != "" {
+		for _, ch := range channels {
+			if ch.Name == ownerName {
+				return map[int64]bool{ch.ID: true}
+			}
+		}
+	}
+	if defaultName == "" {
+		return nil
+	}
+	if skipDefault {
+		return map[int64]bool{}
+	}
+	for _, ch := range channels {
+		if ch.Name == defaultName {
+			return map[int64]bool{ch.ID: true}
+		}
+	}
+	return nil
+}
+
+// streamEvent is sent for every sampled check result. Channels must
+// subscribe to it explicitly, and it is sent once without retries or
+// history so a slow endpoint can't back up the dispatcher.
+const streamEvent = "check.completed"
+
+// _optInEvents are only sent to channels that list them in their events.
+// Status transitions would otherwise duplicate the incident events that
+// channels without an event filter already receive.
+var _optInEvents = map[string]bool{
+	streamEvent:         true,
+	"monitor.down":      true,
+	"monitor.recovered": true,
+}
+
 func matchesEvent(events []string, eventType string) bool {
 	if len(events) == 0 {
-		return true
+		return !_optInEvents[eventType]
 	}
 	for _, e := range events {
 		if e == eventType {
@@ -233,6 +532,11 @@ func FormatMessage(p *Payload) string {
 			return fmt.Sprintf("[REMINDER] Incident #%d still open for %s: %s",
 				p.Incident.ID, p.Incident.MonitorName, p.Incident.Cause)
 		}
+	case "incident.escalated":
+		if p.Incident != nil {
+			return fmt.Sprintf("[ESCALATED] Incident #%d still unacknowledged for %s: %s",
+				p.Incident.ID, p.Incident.MonitorName, p.Incident.Cause)
+		}
 	case "incident.acknowledged":
 		if p.Incident != nil {
 			return fmt.Sprintf("[ACK] Incident #%d for %s acknowledged by %s",
@@ -251,6 +555,43 @@ func FormatMessage(p *Payload) string {
 		if p.Monitor != nil {
 			return fmt.Sprintf("[CERT] Certificate fingerprint changed for %s", p.Monitor.Name)
 		}
+	case "monitor.down":
+		if p.Monitor != nil {
+			if p.Check != nil && p.Check.Message != "" {
+				return fmt.Sprintf("[DOWN] %s is down: %s", p.Monitor.Name, p.Check.Message)
+			}
+			return fmt.Sprintf("[DOWN] %s is down", p.Monitor.Name)
+		}
+	case "monitor.recovered":
+		if p.Monitor != nil {
+			if p.Check != nil && p.Check.Status == "degraded" {
+				return fmt.Sprintf("[RECOVERED] %s is no longer down (degraded)", p.Monitor.Name)
+			}
+			return fmt.Sprintf("[RECOVERED] %s is back up", p.Monitor.Name)
+		}
+	case "monitor.latency_anomaly":
+		if p.Monitor != nil {
+			return fmt.Sprintf("[LATENCY] Response time for %s is well above its usual baseline", p.Monitor.Name)
+		}
+	case "check.completed":
+		if p.Monitor != nil && p.Check != nil {
+			return fmt.Sprintf("[CHECK] %s is %s (%dms)", p.Monitor.Name, p.Check.Status, p.Check.ResponseTime)
+		}
+	case "proxy.disabled":
+		if p.Proxy != nil {
+			if p.Proxy.LastError != "" {
+				return fmt.Sprintf("[PROXY] Proxy %s disabled after %d failed health checks: %s", p.Proxy.Name, p.Proxy.ConsecFails, p.Proxy.LastError)
+			}
+			return fmt.Sprintf("[PROXY] Proxy %s disabled after %d failed health checks", p.Proxy.Name, p.Proxy.ConsecFails)
+		}
+	case "proxy.enabled":
+		if p.Proxy != nil {
+			return fmt.Sprintf("[PROXY] Proxy %s passed its health check and was enabled again", p.Proxy.Name)
+		}
+	case digestEvent:
+		if len(p.Digest) > 0 {
+			return formatDigest(p)
+		}
 	case "test":
 		return "[TEST] This is a test notification from Asura"
 	}
@@ -0,0 +1,264 @@
+package notifier
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestMonitorChannels(t *testing.T) {
+	channels := []*storage.NotificationChannel{
+		{ID: 1, Name: "slack"},
+		{ID: 2, Name: "ops-pager"},
+		{ID: 3, Name: "payments-team"},
+	}
+
+	tests := []struct {
+		name        string
+		assigned    []int64
+		owner       string
+		defaultName string
+		skip        bool
+		want        map[int64]bool
+	}{
+		{"no default sends to every channel", nil, "", "", false, nil},
+		{"assigned channels win", []int64{1}, "", "ops-pager", false, map[int64]bool{1: true}},
+		{"default used without assignment", nil, "", "ops-pager", false, map[int64]bool{2: true}},
+		{"opt-out sends nowhere", nil, "", "ops-pager", true, map[int64]bool{}},
+		{"opt-out ignored with assignment", []int64{1}, "", "ops-pager", true, map[int64]bool{1: true}},
+		{"missing default falls back to every channel", nil, "", "gone", false, nil},
+		{"owner channel beats default", nil, "payments-team", "ops-pager", false, map[int64]bool{3: true}},
+		{"owner channel ignores default opt-out", nil, "payments-team", "ops-pager", true, map[int64]bool{3: true}},
+		{"assigned channels beat owner", []int64{1}, "payments-team", "", false, map[int64]bool{1: true}},
+		{"missing owner channel falls back to default", nil, "gone", "ops-pager", false, map[int64]bool{2: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := monitorChannels(channels, tt.assigned, tt.owner, tt.defaultName, tt.skip)
+			if (got == nil) != (tt.want == nil) || len(got) != len(tt.want) {
+				t.Fatalf("monitorChannels() = %v, want %v", got, tt.want)
+			}
+			for id := range tt.want {
+				if !got[id] {
+					t.Errorf("expected channel %d allowed, got %v", id, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchesEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []string
+		event  string
+		want   bool
+	}{
+		{"no filter matches incidents", nil, "incident.created", true},
+		{"no filter skips check stream", nil, "check.completed", false},
+		{"explicit check stream", []string{"check.completed"}, "check.completed", true},
+		{"filtered out", []string{"incident.created"}, "incident.resolved", false},
+		{"no filter skips transitions", nil, "monitor.down", false},
+		{"recoveries only", []string{"monitor.recovered"}, "monitor.recovered", true},
+		{"recoveries only skips down", []string{"monitor.recovered"}, "monitor.down", false},
+	}
+	for _, tt := range tests {
+		if got := matchesEvent(tt.events, tt.event); got != tt.want {
+			t.Errorf("%s: matchesEvent(%v, %q) = %v, want %v", tt.name, tt.events, tt.event, got, tt.want)
+		}
+	}
+}
+
+type recordingSender struct {
+	sent chan int64
+}
+
+func (s *recordingSender) Type() string { return "record" }
+
+func (s *recordingSender) Send(_ context.Context, ch *storage.NotificationChannel, _ *Payload) error {
+	s.sent <- ch.ID
+	return nil
+}
+
+func TestNotifyForMonitorTagChannels(t *testing.T) {
+	store := subscriberTestStore(t)
+	ctx := context.Background()
+
+	newChannel := func(name string) int64 {
+		t.Helper()
+		ch := &storage.NotificationChannel{Name: name, Type: "record", Enabled: true, Settings: []byte("{}")}
+		if err := store.CreateNotificationChannel(ctx, ch); err != nil {
+			t.Fatal(err)
+		}
+		return ch.ID
+	}
+	newTag := func(name string) int64 {
+		t.Helper()
+		tag := &storage.Tag{Name: name, Color: "#808080"}
+		if err := store.CreateTag(ctx, tag); err != nil {
+			t.Fatal(err)
+		}
+		return tag.ID
+	}
+	newMonitor := func(name string) *storage.Monitor {
+		t.Helper()
+		mon := &storage.Monitor{Name: name, Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10, Enabled: true, Tags: []string{}, FailureThreshold: 1, SuccessThreshold: 1}
+		if err := store.CreateMonitor(ctx, mon); err != nil {
+			t.Fatal(err)
+		}
+		return mon
+	}
+
+	payments, search := newTag("payments"), newTag("search")
+	explicit := newChannel("payments-oncall")
+	tagged := newChannel("payments-team")
+	other := newChannel("search-team")
+	catchAll := newChannel("everything")
+	for ch, tags := range map[int64][]int64{explicit: {payments}, tagged: {payments}, other: {search}} {
+		if err := store.SetChannelTags(ctx, ch, tags); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkout := newMonitor("checkout")
+	if err := store.SetMonitorTags(ctx, checkout.ID, []storage.MonitorTag{{TagID: payments}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetMonitorNotificationChannels(ctx, checkout.ID, []int64{explicit}); err != nil {
+		t.Fatal(err)
+	}
+	untagged := newMonitor("docs site")
+
+	rec := &recordingSender{sent: make(chan int64, 10)}
+	d := NewDispatcher(store, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+	d.RegisterSender(rec)
+
+	collect := func() []int64 {
+		t.Helper()
+		var ids []int64
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case id := <-rec.sent:
+				ids = append(ids, id)
+			case <-time.After(200 * time.Millisecond):
+				slices.Sort(ids)
+				return ids
+			case <-timeout:
+				t.Fatal("timed out collecting sends")
+			}
+		}
+	}
+
+	d.NotifyForMonitor(checkout.ID, &Payload{EventType: "incident.created", Monitor: checkout})
+	if got, want := collect(), []int64{explicit, tagged}; !slices.Equal(got, want) {
+		t.Errorf("tagged monitor sent to %v, want %v", got, want)
+	}
+
+	d.NotifyForMonitor(untagged.ID, &Payload{EventType: "incident.created", Monitor: untagged})
+	if got, want := collect(), []int64{catchAll}; !slices.Equal(got, want) {
+		t.Errorf("untagged monitor sent to %v, want %v", got, want)
+	}
+}
+
+func TestDuplicateNotifications(t *testing.T) {
+	d := NewDispatcher(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+
+	created := &Payload{EventType: "incident.created"}
+	if d.duplicate(1, created) || d.duplicate(1, created) {
+		t.Fatal("expected no deduplication without an interval")
+	}
+
+	d.SetMinNotifyInterval(time.Minute)
+	if d.duplicate(1, created) {
+		t.Fatal("first event should be sent")
+	}
+	if !d.duplicate(1, created) {
+		t.Error("repeated event within the interval should be dropped")
+	}
+	if d.duplicate(2, created) {
+		t.Error("same event for another monitor should be sent")
+	}
+	if d.duplicate(1, &Payload{EventType: "incident.reminder"}) {
+		t.Error("other event type for the same monitor should be sent")
+	}
+	resolved := &Payload{EventType: "incident.resolved"}
+	if d.duplicate(1, resolved) || d.duplicate(1, resolved) {
+		t.Error("resolutions must never be dropped")
+	}
+
+	d.lastNotify["incident.created/1"] = time.Now().Add(-2 * time.Minute)
+	if d.duplicate(1, created) {
+		t.Error("event after the interval should be sent")
+	}
+}
+
+func TestRouteToOncall(t *testing.T) {
+	store := subscriberTestStore(t)
+	ctx := context.Background()
+
+	sched := &storage.OncallSchedule{
+		Name:          "Primary",
+		Timezone:      "UTC",
+		RotationStart: "2026-01-05T09:00",
+		ShiftHours:    168,
+		Members: []storage.OncallMember{
+			{Name: "Alice", Email: "alice@example.com"},
+			{Name: "Bob", Email: "bob@example.com", RoutingKey: "R0BOB"},
+		},
+	}
+	if err := store.CreateOncallSchedule(ctx, sched); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDispatcher(store, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+	secondWeek := time.Date(2026, 1, 13, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		typ      string
+		settings string
+		at       time.Time
+		want     string
+	}{
+		{"email recipient", "email", `{"to":["team@example.com"]}`, secondWeek, `"to":["bob@example.com"]`},
+		{"opsgenie responder", "opsgenie", `{"api_key":"k"}`, secondWeek, `"responders":["bob@example.com"]`},
+		{"pagerduty routing key", "pagerduty", `{"routing_key":"R0TEAM"}`, secondWeek, `"routing_key":"R0BOB"`},
+		{"pagerduty keeps key without member key", "pagerduty", `{"routing_key":"R0TEAM"}`, secondWeek.Add(-7 * 24 * time.Hour), `"routing_key":"R0TEAM"`},
+		{"before rotation keeps recipients", "email", `{"to":["team@example.com"]}`, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), `"to":["team@example.com"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := &storage.NotificationChannel{ID: 1, Type: tt.typ, Settings: []byte(tt.settings), OncallScheduleID: &sched.ID}
+			got := d.routeToOncall(ctx, ch, tt.at)
+			if !strings.Contains(string(got.Settings), tt.want) {
+				t.Fatalf("settings = %s, want %s", got.Settings, tt.want)
+			}
+		})
+	}
+
+	missing := int64(9999)
+	ch := &storage.NotificationChannel{ID: 2, Type: "email", Settings: []byte(`{"to":["team@example.com"]}`), OncallScheduleID: &missing}
+	if got := d.routeToOncall(ctx, ch, secondWeek); got != ch {
+		t.Fatal("expected channel unchanged for a missing schedule")
+	}
+
+	// Schedules are cached until invalidated.
+	sched.Members[1].Email = "robert@example.com"
+	if err := store.UpdateOncallSchedule(ctx, sched); err != nil {
+		t.Fatal(err)
+	}
+	ch = &storage.NotificationChannel{ID: 3, Type: "email", Settings: []byte(`{"to":["team@example.com"]}`), OncallScheduleID: &sched.ID}
+	if got := d.routeToOncall(ctx, ch, secondWeek); !strings.Contains(string(got.Settings), "bob@example.com") {
+		t.Fatalf("expected the cached schedule, got %s", got.Settings)
+	}
+	d.InvalidateOncall(sched.ID)
+	if got := d.routeToOncall(ctx, ch, secondWeek); !strings.Contains(string(got.Settings), "robert@example.com") {
+		t.Fatalf("expected the updated schedule after invalidation, got %s", got.Settings)
+	}
+}
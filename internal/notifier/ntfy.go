@@ -18,6 +18,14 @@ type NtfySettings struct {
 	Priority  int    `json:"priority,omitempty"`
 	Tags      string `json:"tags,omitempty"`
 	ClickURL  string `json:"click_url,omitempty"`
+	// SeverityPriorities overrides the priority (1-5) per severity.
+	SeverityPriorities map[string]int `json:"severity_priorities,omitempty"`
+}
+
+var ntfyDefaultPriorities = map[string]int{
+	SeverityCritical: 5,
+	SeverityWarning:  3,
+	SeverityInfo:     2,
 }
 
 type NtfySender struct{}
@@ -49,8 +57,8 @@ func (s *NtfySender) Send(ctx context.Context, channel *storage.NotificationChan
 	}
 
 	req.Header.Set("Title", fmt.Sprintf("Asura — %s", payload.EventType))
-	if settings.Priority > 0 && settings.Priority <= 5 {
-		req.Header.Set("Priority", fmt.Sprintf("%d", settings.Priority))
+	if priority := severityPriority(payload, settings.SeverityPriorities, settings.Priority, ntfyDefaultPriorities); priority > 0 && priority <= 5 {
+		req.Header.Set("Priority", fmt.Sprintf("%d", priority))
 	}
 	if settings.Tags != "" {
 		req.Header.Set("Tags", settings.Tags)
@@ -0,0 +1,140 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// SupportsOncall reports whether channels of the given type can address an
+// on-call schedule's current responder.
+func SupportsOncall(channelType string) bool {
+	switch channelType {
+	case "email", "pagerduty", "opsgenie":
+		return true
+	}
+	return false
+}
+
+// oncallCacheTTL bounds how long a cached schedule is used. Edits made
+// through the API or web UI invalidate it immediately; the TTL covers edits
+// made by another process sharing the database.
+const oncallCacheTTL = time.Minute
+
+// oncallCache keeps recently used schedules so routing a notification does
+// not read the database on every send.
+type oncallCache struct {
+	mu        sync.Mutex
+	schedules map[int64]cachedSchedule
+}
+
+type cachedSchedule struct {
+	sched   *storage.OncallSchedule
+	fetched time.Time
+}
+
+// oncallSchedule returns the schedule with id, from the cache while it is
+// fresh. Lookup errors are not cached.
+func (d *Dispatcher) oncallSchedule(ctx context.Context, id int64) (*storage.OncallSchedule, error) {
+	d.oncall.mu.Lock()
+	c, ok := d.oncall.schedules[id]
+	d.oncall.mu.Unlock()
+	if ok && time.Since(c.fetched) < oncallCacheTTL {
+		return c.sched, nil
+	}
+
+	sched, err := d.store.GetOncallSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	d.oncall.mu.Lock()
+	if d.oncall.schedules == nil {
+		d.oncall.schedules = make(map[int64]cachedSchedule)
+	}
+	d.oncall.schedules[id] = cachedSchedule{sched: sched, fetched: time.Now()}
+	d.oncall.mu.Unlock()
+	return sched, nil
+}
+
+// InvalidateOncall drops a schedule from the routing cache after it was
+// updated or deleted.
+func (d *Dispatcher) InvalidateOncall(id int64) {
+	d.oncall.mu.Lock()
+	delete(d.oncall.schedules, id)
+	d.oncall.mu.Unlock()
+}
+
+// routeToOncall returns ch addressed to its schedule's responder at now. The
+// channel is returned unchanged when it has no schedule, nobody is on call or
+// the responder has no address for this channel type.
+func (d *Dispatcher) routeToOncall(ctx context.Context, ch *storage.NotificationChannel, now time.Time) *storage.NotificationChannel {
+	if ch.OncallScheduleID == nil || !SupportsOncall(ch.Type) {
+		return ch
+	}
+	sched, err := d.oncallSchedule(ctx, *ch.OncallScheduleID)
+	if err != nil {
+		d.logger.Warn("resolve on-call responder", "channel_id", ch.ID, "schedule_id", *ch.OncallScheduleID, "error", err)
+		return ch
+	}
+	member := sched.ResponderAt(now)
+	if member == nil {
+		d.logger.Debug("nobody on call, using channel recipients", "channel_id", ch.ID, "schedule_id", *ch.OncallScheduleID)
+		return ch
+	}
+	routed, err := withResponder(ch, member)
+	if err != nil {
+		d.logger.Warn("address on-call responder", "channel_id", ch.ID, "error", err)
+		return ch
+	}
+	return routed
+}
+
+// withResponder returns a copy of ch whose settings address member: the
+// email recipient, the Opsgenie responder or the PagerDuty routing key.
+func withResponder(ch *storage.NotificationChannel, member *storage.OncallMember) (*storage.NotificationChannel, error) {
+	var settings any
+	switch ch.Type {
+	case "email":
+		if member.Email == "" {
+			return ch, nil
+		}
+		var s EmailSettings
+		if err := json.Unmarshal(ch.Settings, &s); err != nil {
+			return nil, err
+		}
+		s.To = []string{member.Email}
+		settings = s
+	case "opsgenie":
+		if member.Email == "" {
+			return ch, nil
+		}
+		var s OpsgenieSettings
+		if err := json.Unmarshal(ch.Settings, &s); err != nil {
+			return nil, err
+		}
+		s.Responders = []string{member.Email}
+		settings = s
+	case "pagerduty":
+		if member.RoutingKey == "" {
+			return ch, nil
+		}
+		var s PagerDutySettings
+		if err := json.Unmarshal(ch.Settings, &s); err != nil {
+			return nil, err
+		}
+		s.RoutingKey = member.RoutingKey
+		settings = s
+	default:
+		return ch, nil
+	}
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	routed := *ch
+	routed.Settings = raw
+	return &routed, nil
+}
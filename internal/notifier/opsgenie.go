@@ -15,6 +15,8 @@ import (
 type OpsgenieSettings struct {
 	APIKey string `json:"api_key"`
 	Region string `json:"region,omitempty"`
+	// Responders are Opsgenie usernames the alert is assigned to.
+	Responders []string `json:"responders,omitempty"`
 }
 
 type OpsgenieSender struct{}
@@ -43,11 +45,11 @@ func (s *OpsgenieSender) Send(ctx context.Context, channel *storage.Notification
 	case "incident.acknowledged":
 		return opsgenieAck(ctx, baseURL, settings.APIKey, alias)
 	default:
-		return opsgenieCreate(ctx, baseURL, settings.APIKey, alias, payload)
+		return opsgenieCreate(ctx, baseURL, settings.APIKey, alias, settings.Responders, payload)
 	}
 }
 
-func opsgenieCreate(ctx context.Context, baseURL, apiKey, alias string, payload *Payload) error {
+func opsgenieCreate(ctx context.Context, baseURL, apiKey, alias string, responders []string, payload *Payload) error {
 	priority := "P1"
 	if payload.EventType == "incident.reminder" || payload.EventType == "content.changed" {
 		priority = "P3"
@@ -60,6 +62,13 @@ func opsgenieCreate(ctx context.Context, baseURL, apiKey, alias string, payload
 		"priority":    priority,
 		"source":      "asura",
 	}
+	if len(responders) > 0 {
+		users := make([]map[string]string, len(responders))
+		for i, u := range responders {
+			users[i] = map[string]string{"type": "user", "username": u}
+		}
+		alert["responders"] = users
+	}
 
 	return opsgeniePost(ctx, baseURL+"/v2/alerts", apiKey, alert)
 }
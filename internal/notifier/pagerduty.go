@@ -71,7 +71,7 @@ func (s *PagerDutySender) Send(ctx context.Context, channel *storage.Notificatio
 
 func pagerdutyAction(eventType string) (string, string) {
 	switch eventType {
-	case "incident.created", "incident.reminder", "content.changed", "test":
+	case "incident.created", "incident.reminder", "incident.escalated", "content.changed", "test":
 		return "trigger", "critical"
 	case "incident.acknowledged":
 		return "acknowledge", ""
@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestPayloadSeverityLevel(t *testing.T) {
+	tests := []struct {
+		payload Payload
+		want    string
+	}{
+		{Payload{EventType: "incident.created"}, SeverityCritical},
+		{Payload{EventType: "incident.created", Severity: SeverityWarning}, SeverityWarning},
+		{Payload{EventType: "cert.changed"}, SeverityWarning},
+		{Payload{EventType: "incident.resolved"}, SeverityInfo},
+		{Payload{EventType: "test"}, SeverityInfo},
+	}
+	for _, tt := range tests {
+		if got := tt.payload.SeverityLevel(); got != tt.want {
+			t.Errorf("%s/%q: got %q, want %q", tt.payload.EventType, tt.payload.Severity, got, tt.want)
+		}
+	}
+}
+
+func TestNtfyPriorityBySeverity(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Priority")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		settings string
+		payload  *Payload
+		want     string
+	}{
+		{"critical default", `{}`, &Payload{EventType: "incident.created"}, "5"},
+		{"degraded incident", `{}`, &Payload{EventType: "incident.created", Severity: SeverityWarning}, "3"},
+		{"resolved", `{}`, &Payload{EventType: "incident.resolved"}, "2"},
+		{"fixed priority", `{"priority":4}`, &Payload{EventType: "incident.resolved"}, "4"},
+		{"override wins", `{"priority":4,"severity_priorities":{"info":1}}`, &Payload{EventType: "incident.resolved"}, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s map[string]any
+			json.Unmarshal([]byte(tt.settings), &s)
+			s["server_url"] = srv.URL
+			s["topic"] = "alerts"
+			raw, _ := json.Marshal(s)
+			ch := &storage.NotificationChannel{Type: "ntfy", Settings: raw}
+			if err := (&NtfySender{}).Send(context.Background(), ch, tt.payload); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Priority header = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGotifyPriorityBySeverity(t *testing.T) {
+	var got struct {
+		Priority int `json:"priority"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	settings, _ := json.Marshal(GotifySettings{
+		ServerURL: srv.URL, AppToken: "tok",
+		SeverityPriorities: map[string]int{SeverityWarning: 6},
+	})
+	ch := &storage.NotificationChannel{Type: "gotify", Settings: settings}
+
+	for _, tt := range []struct {
+		payload *Payload
+		want    int
+	}{
+		{&Payload{EventType: "incident.created"}, 8},
+		{&Payload{EventType: "incident.created", Severity: SeverityWarning}, 6},
+		{&Payload{EventType: "incident.acknowledged"}, 2},
+	} {
+		if err := (&GotifySender{}).Send(context.Background(), ch, tt.payload); err != nil {
+			t.Fatal(err)
+		}
+		if got.Priority != tt.want {
+			t.Errorf("%s/%q: priority = %d, want %d", tt.payload.EventType, tt.payload.Severity, got.Priority, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+type RocketChatSettings struct {
+	WebhookURL string `json:"webhook_url"`
+	Channel    string `json:"channel,omitempty"`
+}
+
+type RocketChatSender struct {
+	AllowPrivate bool
+}
+
+func (s *RocketChatSender) Type() string { return "rocketchat" }
+
+func (s *RocketChatSender) Send(ctx context.Context, channel *storage.NotificationChannel, payload *Payload) error {
+	var settings RocketChatSettings
+	if err := json.Unmarshal(channel.Settings, &settings); err != nil {
+		return fmt.Errorf("invalid rocketchat settings: %w", err)
+	}
+	if settings.WebhookURL == "" {
+		return fmt.Errorf("rocketchat webhook_url is required")
+	}
+
+	text := escapeChatMentions(FormatMessage(payload))
+	msg := map[string]any{
+		"alias": "Asura Monitor",
+		"text":  text,
+		"attachments": []map[string]any{
+			{
+				"title":  text,
+				"color":  chatColor(payload.EventType),
+				"fields": chatFields(payload),
+				"ts":     time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+	}
+	if settings.Channel != "" {
+		msg["channel"] = settings.Channel
+	}
+
+	body, _ := json.Marshal(msg)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, settings.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := safeClient(s.AllowPrivate).Do(req)
+	if err != nil {
+		return fmt.Errorf("rocketchat request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("rocketchat returned status %d", resp.StatusCode)
+	}
+	return nil
+}
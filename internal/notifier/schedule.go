@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // schedules name IANA zones; don't depend on the host's zoneinfo
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// ChannelSchedule limits a notification channel to recurring weekly windows.
+// A channel without a schedule is always active.
+type ChannelSchedule struct {
+	Timezone string           `json:"timezone,omitempty"` // IANA name, defaults to UTC
+	Windows  []ScheduleWindow `json:"windows"`
+}
+
+// ScheduleWindow is active from Start to End (HH:MM) on each of Days.
+// When End is not after Start the window runs past midnight into the next
+// day; equal Start and End cover the whole day.
+type ScheduleWindow struct {
+	Days  []string `json:"days,omitempty"` // mon..sun, empty = every day
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+var _weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseSchedule decodes and validates a channel schedule. It returns nil for
+// an empty schedule.
+func ParseSchedule(raw json.RawMessage) (*ChannelSchedule, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" || trimmed == "{}" {
+		return nil, nil
+	}
+	var s ChannelSchedule
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return nil, fmt.Errorf("schedule.timezone: unknown time zone %q", s.Timezone)
+		}
+	}
+	if len(s.Windows) == 0 {
+		return nil, fmt.Errorf("schedule.windows must contain at least one window")
+	}
+	for i, w := range s.Windows {
+		for _, d := range w.Days {
+			if _, ok := _weekdays[strings.ToLower(d)]; !ok {
+				return nil, fmt.Errorf("schedule.windows[%d]: invalid day %q (use mon..sun)", i, d)
+			}
+		}
+		if _, err := parseClock(w.Start); err != nil {
+			return nil, fmt.Errorf("schedule.windows[%d].start: %w", i, err)
+		}
+		if _, err := parseClock(w.End); err != nil {
+			return nil, fmt.Errorf("schedule.windows[%d].end: %w", i, err)
+		}
+	}
+	return &s, nil
+}
+
+// ActiveAt reports whether t falls inside any of the schedule's windows.
+func (s *ChannelSchedule) ActiveAt(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	loc := time.UTC
+	if s.Timezone != "" {
+		if l, err := time.LoadLocation(s.Timezone); err == nil {
+			loc = l
+		}
+	}
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	today := t.Weekday()
+	yesterday := (today + 6) % 7
+
+	for _, w := range s.Windows {
+		start, err1 := parseClock(w.Start)
+		end, err2 := parseClock(w.End)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		switch {
+		case start == end:
+			if w.onDay(today) {
+				return true
+			}
+		case start < end:
+			if w.onDay(today) && minute >= start && minute < end {
+				return true
+			}
+		default: // spans midnight
+			if w.onDay(today) && minute >= start {
+				return true
+			}
+			if w.onDay(yesterday) && minute < end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (w ScheduleWindow) onDay(d time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, name := range w.Days {
+		if wd, ok := _weekdays[strings.ToLower(name)]; ok && wd == d {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock converts "HH:MM" to minutes since midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("time must be HH:MM, got %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// channelActive reports whether ch's schedule allows sending at now. A
+// schedule that fails to parse never silences a channel.
+func channelActive(ch *storage.NotificationChannel, now time.Time) bool {
+	s, err := ParseSchedule(ch.Schedule)
+	if err != nil || s == nil {
+		return true
+	}
+	return s.ActiveAt(now)
+}
@@ -0,0 +1,87 @@
+package notifier
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestParseSchedule(t *testing.T) {
+	valid := []string{
+		``,
+		`{}`,
+		`{"windows":[{"start":"09:00","end":"17:00"}]}`,
+		`{"timezone":"America/New_York","windows":[{"days":["Mon","fri"],"start":"22:00","end":"06:00"}]}`,
+	}
+	for _, raw := range valid {
+		if _, err := ParseSchedule(json.RawMessage(raw)); err != nil {
+			t.Errorf("ParseSchedule(%s): unexpected error: %v", raw, err)
+		}
+	}
+
+	invalid := []string{
+		`{"windows":[]}`,
+		`{"timezone":"Mars/Olympus","windows":[{"start":"09:00","end":"17:00"}]}`,
+		`{"windows":[{"days":["funday"],"start":"09:00","end":"17:00"}]}`,
+		`{"windows":[{"start":"9am","end":"17:00"}]}`,
+		`{"windows":[{"start":"09:00","end":"25:00"}]}`,
+		`not json`,
+	}
+	for _, raw := range invalid {
+		if _, err := ParseSchedule(json.RawMessage(raw)); err == nil {
+			t.Errorf("ParseSchedule(%s): expected error", raw)
+		}
+	}
+}
+
+func TestScheduleActiveAt(t *testing.T) {
+	s, err := ParseSchedule(json.RawMessage(`{"timezone":"UTC","windows":[
+		{"days":["mon","tue","wed","thu","fri"],"start":"18:00","end":"09:00"},
+		{"days":["sat","sun"],"start":"00:00","end":"00:00"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2024-01-01 is a Monday.
+	tests := []struct {
+		at   string
+		want bool
+	}{
+		{"2024-01-01T12:00:00Z", false}, // Monday midday
+		{"2024-01-01T18:00:00Z", true},  // Monday evening
+		{"2024-01-02T08:59:00Z", true},  // Tuesday morning, spill-over from Monday
+		{"2024-01-02T09:00:00Z", false}, // Tuesday 09:00, window closed
+		{"2024-01-06T12:00:00Z", true},  // Saturday all day
+		{"2024-01-01T03:00:00Z", false}, // Monday early, Sunday window does not spill
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		if got := s.ActiveAt(at); got != tt.want {
+			t.Errorf("ActiveAt(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleTimezone(t *testing.T) {
+	s, err := ParseSchedule(json.RawMessage(`{"timezone":"Asia/Tokyo","windows":[{"start":"09:00","end":"17:00"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 01:00 UTC is 10:00 in Tokyo.
+	if !s.ActiveAt(time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)) {
+		t.Error("expected active at 10:00 Tokyo time")
+	}
+	if s.ActiveAt(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Error("expected inactive at 21:00 Tokyo time")
+	}
+}
+
+func TestChannelActiveWithoutSchedule(t *testing.T) {
+	ch := &storage.NotificationChannel{Type: "webhook"}
+	if !channelActive(ch, time.Now()) {
+		t.Error("channel without schedule should always be active")
+	}
+}
@@ -0,0 +1,64 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// SignalSettings targets a signal-cli REST API gateway
+// (github.com/bbernhard/signal-cli-rest-api).
+type SignalSettings struct {
+	APIURL     string   `json:"api_url"`
+	Number     string   `json:"number"`
+	Recipients []string `json:"recipients"`
+}
+
+type SignalSender struct {
+	AllowPrivate bool
+}
+
+func (s *SignalSender) Type() string { return "signal" }
+
+func (s *SignalSender) Send(ctx context.Context, channel *storage.NotificationChannel, payload *Payload) error {
+	var settings SignalSettings
+	if err := json.Unmarshal(channel.Settings, &settings); err != nil {
+		return fmt.Errorf("invalid signal settings: %w", err)
+	}
+	if settings.APIURL == "" {
+		return fmt.Errorf("signal api_url is required")
+	}
+	if settings.Number == "" || len(settings.Recipients) == 0 {
+		return fmt.Errorf("signal number and recipients are required")
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"message":    smsText(payload),
+		"number":     settings.Number,
+		"recipients": settings.Recipients,
+	})
+	endpoint := strings.TrimRight(settings.APIURL, "/") + "/v2/send"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := safeClient(s.AllowPrivate).Do(req)
+	if err != nil {
+		return fmt.Errorf("signal request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return providerError("signal", resp)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -34,18 +34,22 @@ func (s *SlackSender) Send(ctx context.Context, channel *storage.NotificationCha
 
 	text := escapeSlackMrkdwn(FormatMessage(payload))
 
-	msg := map[string]any{
-		"text": text,
-		"blocks": []map[string]any{
-			{
-				"type": "section",
-				"text": map[string]string{
-					"type": "mrkdwn",
-					"text": text,
-				},
+	blocks := []map[string]any{
+		{
+			"type": "section",
+			"text": map[string]string{
+				"type": "mrkdwn",
+				"text": text,
 			},
 		},
 	}
+	if buttons := slackActionButtons(payload); len(buttons) > 0 {
+		blocks = append(blocks, map[string]any{"type": "actions", "elements": buttons})
+	}
+	msg := map[string]any{
+		"text":   text,
+		"blocks": blocks,
+	}
 	if settings.Channel != "" {
 		msg["channel"] = settings.Channel
 	}
@@ -81,3 +85,26 @@ func escapeSlackMrkdwn(s string) string {
 	s = strings.ReplaceAll(s, ">", "&gt;")
 	return s
 }
+
+// slackActionButtons returns link buttons for the payload's incident action
+// links, if any.
+func slackActionButtons(p *Payload) []map[string]any {
+	var buttons []map[string]any
+	add := func(label, link, style string) {
+		if link == "" {
+			return
+		}
+		b := map[string]any{
+			"type": "button",
+			"text": map[string]string{"type": "plain_text", "text": label},
+			"url":  link,
+		}
+		if style != "" {
+			b["style"] = style
+		}
+		buttons = append(buttons, b)
+	}
+	add("Acknowledge", p.AckURL, "primary")
+	add("Resolve", p.ResolveURL, "")
+	return buttons
+}
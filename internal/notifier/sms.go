@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSMSLen keeps SMS-style messages within two concatenated segments.
+const maxSMSLen = 300
+
+// smsText is FormatMessage shortened for SMS and Signal, where long
+// failure causes would be split across many messages.
+func smsText(p *Payload) string {
+	text := FormatMessage(p)
+	if utf8.RuneCountInString(text) <= maxSMSLen {
+		return text
+	}
+	return string([]rune(text)[:maxSMSLen-1]) + "…"
+}
+
+// providerError describes a failed response, including the start of its body
+// so the provider's reason ends up in the logs and notification history.
+// Any of secrets echoed back by the provider is replaced before it is logged.
+func providerError(provider string, resp *http.Response, secrets ...string) error {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	body := strings.TrimSpace(string(b))
+	for _, s := range secrets {
+		if s != "" {
+			body = strings.ReplaceAll(body, s, "[redacted]")
+		}
+	}
+	if body == "" {
+		return fmt.Errorf("%s returned status %d", provider, resp.StatusCode)
+	}
+	return fmt.Errorf("%s returned status %d: %s", provider, resp.StatusCode, body)
+}
@@ -0,0 +1,110 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestTwilioSend(t *testing.T) {
+	var to []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" || user != "AC123" || pass != "secret-token" {
+			t.Errorf("unexpected request %s as %s", r.URL.Path, user)
+		}
+		r.ParseForm()
+		if r.Form.Get("From") != "+15550100" || !strings.HasPrefix(r.Form.Get("Body"), "[ALERT] Incident #7") {
+			t.Errorf("unexpected form %v", r.Form)
+		}
+		to = append(to, r.Form.Get("To"))
+		if r.Form.Get("To") == "+15550199" {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"code":21211,"message":"Invalid 'To' Phone Number","more_info":"token secret-token"}`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	settings, _ := json.Marshal(TwilioSettings{
+		AccountSID: "AC123", AuthToken: "secret-token",
+		FromNumber: "+15550100", ToNumbers: []string{"+15550123", "+15550199"},
+	})
+	ch := &storage.NotificationChannel{Type: "twilio", Settings: settings}
+	payload := &Payload{EventType: "incident.created", Incident: &storage.Incident{ID: 7, MonitorName: "API", Cause: "timeout"}}
+
+	err := (&TwilioSender{baseURL: srv.URL}).Send(context.Background(), ch, payload)
+	if len(to) != 2 {
+		t.Fatalf("sent to %v, want both numbers", to)
+	}
+	if err == nil || !strings.Contains(err.Error(), "Invalid 'To' Phone Number") {
+		t.Fatalf("error = %v, want the provider's reason", err)
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("error leaks the auth token: %v", err)
+	}
+}
+
+func TestSignalSend(t *testing.T) {
+	var got struct {
+		Message    string   `json:"message"`
+		Number     string   `json:"number"`
+		Recipients []string `json:"recipients"`
+	}
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/send" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"error":"Unregistered user"}`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	settings, _ := json.Marshal(SignalSettings{APIURL: srv.URL + "/", Number: "+15550100", Recipients: []string{"+15550123"}})
+	ch := &storage.NotificationChannel{Type: "signal", Settings: settings}
+
+	d := NewDispatcher(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+	if err := d.SendTest(ch, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.Number != "+15550100" || len(got.Recipients) != 1 || !strings.HasPrefix(got.Message, "[TEST]") {
+		t.Fatalf("unexpected request %+v", got)
+	}
+
+	fail = true
+	if err := d.SendTest(ch, nil); err == nil || !strings.Contains(err.Error(), "Unregistered user") {
+		t.Fatalf("error = %v, want the gateway's reason", err)
+	}
+
+	blocked := NewDispatcher(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), false)
+	if err := blocked.SendTest(ch, nil); err == nil {
+		t.Fatal("expected a private gateway to be refused")
+	}
+}
+
+func TestSMSText(t *testing.T) {
+	long := &Payload{EventType: "incident.created", Incident: &storage.Incident{ID: 1, MonitorName: "API", Cause: strings.Repeat("x", 500)}}
+	text := smsText(long)
+	if n := len([]rune(text)); n != maxSMSLen || !strings.HasSuffix(text, "…") {
+		t.Fatalf("got %d runes %q", n, text)
+	}
+	short := &Payload{EventType: "test"}
+	if smsText(short) != FormatMessage(short) {
+		t.Fatal("short messages should be unchanged")
+	}
+}
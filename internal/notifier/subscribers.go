@@ -0,0 +1,180 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+// subscriberUpdate describes how an incident event reads in a status page
+// subscriber email.
+type subscriberUpdate struct {
+	label string
+	color string
+}
+
+var subscriberUpdates = map[string]subscriberUpdate{
+	"incident.created":      {"Investigating", "#f87171"},
+	"incident.acknowledged": {"Identified", "#fbbf24"},
+	"incident.resolved":     {"Resolved", "#34d399"},
+}
+
+// SetPublicURL sets the base URL that links in status page subscriber
+// emails point at.
+func (d *Dispatcher) SetPublicURL(u string) {
+	d.publicURL = strings.TrimRight(u, "/")
+}
+
+// SendSubscriptionConfirmation emails sub the link that confirms its
+// subscription to sp.
+func (d *Dispatcher) SendSubscriptionConfirmation(ctx context.Context, sp *storage.StatusPage, sub *storage.StatusPageSubscriber) error {
+	settings, err := d.subscriberSettings(ctx, sp)
+	if err != nil {
+		return err
+	}
+
+	confirmURL := d.statusPageURL(sp) + "/subscribe/confirm?token=" + url.QueryEscape(sub.Token)
+	detail := "Confirm that you want to receive incident updates for " + html.EscapeString(sp.Title) +
+		`.<br><br><a href="` + html.EscapeString(confirmURL) + `" style="color:#60a5fa">Confirm subscription</a>`
+	footer := "If you did not subscribe, ignore this email."
+	body := renderEmailHTML("#818cf8", "Subscription", sp.Title, detail, footer)
+
+	subject := sanitizeHeader("Confirm your subscription to " + sp.Title)
+	return deliverEmail(settings, []string{sub.Email}, buildSubscriberEmail(settings.From, sub.Email, subject, body, ""))
+}
+
+// notifySubscribers emails confirmed subscribers of every status page that
+// shows the incident's monitor.
+func (d *Dispatcher) notifySubscribers(payload *Payload) {
+	inc := payload.Incident
+	if inc == nil || inc.MonitorID == 0 {
+		return
+	}
+	update, ok := subscriberUpdates[payload.EventType]
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	pages, err := d.store.ListSubscribableStatusPages(ctx, inc.MonitorID)
+	if err != nil {
+		d.logger.Error("list subscribable status pages", "error", err)
+		return
+	}
+	for _, sp := range pages {
+		subs, err := d.store.ListStatusPageSubscribers(ctx, sp.ID)
+		if err != nil {
+			d.logger.Error("list status page subscribers", "page_id", sp.ID, "error", err)
+			continue
+		}
+		settings, err := d.subscriberSettings(ctx, sp)
+		if err != nil {
+			d.logger.Warn("status page subscriber channel", "page_id", sp.ID, "error", err)
+			continue
+		}
+
+		name := d.subscriberComponentName(ctx, sp.ID, inc)
+		subject := sanitizeHeader(fmt.Sprintf("[%s] %s: %s", sp.Title, update.label, name))
+		for _, sub := range subs {
+			if !sub.Confirmed {
+				continue
+			}
+			unsubscribeURL := d.statusPageURL(sp) + "/unsubscribe?token=" + url.QueryEscape(sub.Token)
+			body := renderEmailHTML(update.color, update.label, sp.Title,
+				subscriberDetail(payload.EventType, name, inc.Cause, d.statusPageURL(sp)),
+				`You are receiving this because you subscribed to `+html.EscapeString(sp.Title)+
+					`. <a href="`+html.EscapeString(unsubscribeURL)+`" style="color:#6b7280">Unsubscribe</a>`)
+			msg := buildSubscriberEmail(settings.From, sub.Email, subject, body, unsubscribeURL)
+
+			d.sem <- struct{}{}
+			err := deliverEmail(settings, []string{sub.Email}, msg)
+			<-d.sem
+			if err != nil {
+				d.logger.Warn("status page subscriber email failed", "page_id", sp.ID, "subscriber_id", sub.ID, "error", err)
+			}
+		}
+	}
+}
+
+// subscriberSettings returns the SMTP settings of sp's subscriber channel.
+func (d *Dispatcher) subscriberSettings(ctx context.Context, sp *storage.StatusPage) (EmailSettings, error) {
+	var settings EmailSettings
+	if sp.SubscriberChannelID == nil {
+		return settings, fmt.Errorf("status page %d has no subscriber channel", sp.ID)
+	}
+	ch, err := d.store.GetNotificationChannel(ctx, *sp.SubscriberChannelID)
+	if err != nil {
+		return settings, fmt.Errorf("get subscriber channel: %w", err)
+	}
+	if ch.Type != "email" {
+		return settings, fmt.Errorf("subscriber channel %d is not an email channel", ch.ID)
+	}
+	if err := json.Unmarshal(ch.Settings, &settings); err != nil {
+		return settings, fmt.Errorf("invalid email settings: %w", err)
+	}
+	if settings.Host == "" || settings.From == "" {
+		return settings, fmt.Errorf("subscriber channel %d needs a host and from address", ch.ID)
+	}
+	return settings, nil
+}
+
+// subscriberComponentName names the incident the way the status page does,
+// using the component that contains the monitor when there is one.
+func (d *Dispatcher) subscriberComponentName(ctx context.Context, pageID int64, inc *storage.Incident) string {
+	comps, err := d.store.ListStatusPageComponents(ctx, pageID)
+	if err != nil {
+		d.logger.Warn("list status page components", "page_id", pageID, "error", err)
+	}
+	for _, c := range comps {
+		for _, m := range c.Monitors {
+			if m.MonitorID == inc.MonitorID {
+				return c.Name
+			}
+		}
+	}
+	return inc.MonitorName
+}
+
+func (d *Dispatcher) statusPageURL(sp *storage.StatusPage) string {
+	return d.publicURL + "/" + sp.Slug
+}
+
+func subscriberDetail(eventType, name, cause, pageURL string) string {
+	var detail string
+	switch eventType {
+	case "incident.created":
+		detail = "We are investigating an issue affecting " + html.EscapeString(name) + "."
+		if cause != "" {
+			detail += "<br>" + html.EscapeString(cause)
+		}
+	case "incident.acknowledged":
+		detail = "The issue affecting " + html.EscapeString(name) + " has been identified and is being worked on."
+	case "incident.resolved":
+		detail = "The issue affecting " + html.EscapeString(name) + " has been resolved."
+	}
+	return detail + `<br><br><a href="` + html.EscapeString(pageURL) + `" style="color:#60a5fa">View status page</a>`
+}
+
+// buildSubscriberEmail builds a message to a single subscriber. A non-empty
+// unsubscribeURL adds List-Unsubscribe headers so mail clients can offer
+// one-click unsubscribe.
+func buildSubscriberEmail(from, to, subject, body, unsubscribeURL string) []byte {
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("From: %s\r\n", sanitizeHeader(from)))
+	msg.WriteString(fmt.Sprintf("To: %s\r\n", sanitizeHeader(to)))
+	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
+	if unsubscribeURL != "" {
+		msg.WriteString(fmt.Sprintf("List-Unsubscribe: <%s>\r\n", sanitizeHeader(unsubscribeURL)))
+		msg.WriteString("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+	return []byte(msg.String())
+}
@@ -0,0 +1,215 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+type smtpMessage struct {
+	rcpt string
+	data string
+}
+
+// fakeSMTP accepts any number of SMTP sessions and returns the port it
+// listens on and a channel receiving each delivered message.
+func fakeSMTP(t *testing.T) (int, <-chan smtpMessage) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	msgs := make(chan smtpMessage, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				write := func(s string) { conn.Write([]byte(s + "\r\n")) }
+				write("220 testsmtp ESMTP")
+				var rcpt string
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					line = strings.TrimSpace(line)
+					switch {
+					case strings.HasPrefix(line, "RCPT TO:"):
+						rcpt = line
+						write("250 OK")
+					case line == "DATA":
+						write("354 Start input")
+						var sb strings.Builder
+						for {
+							l, _ := r.ReadString('\n')
+							if strings.TrimSpace(l) == "." {
+								break
+							}
+							sb.WriteString(l)
+						}
+						msgs <- smtpMessage{rcpt: rcpt, data: sb.String()}
+						write("250 OK")
+					case line == "QUIT":
+						write("221 Bye")
+						return
+					default:
+						write("250 OK")
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	n, _ := strconv.Atoi(port)
+	return n, msgs
+}
+
+func subscriberTestStore(t *testing.T) *storage.SQLiteStore {
+	t.Helper()
+	tmpFile, err := os.CreateTemp("", "asura-notifier-test-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	store, err := storage.NewSQLiteStore(tmpFile.Name(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// subscriberFixture creates a page showing one monitor behind a component,
+// with a confirmed and an unconfirmed subscriber.
+func subscriberFixture(t *testing.T, port int) (*Dispatcher, *storage.StatusPage, *storage.Monitor) {
+	t.Helper()
+	store := subscriberTestStore(t)
+	ctx := context.Background()
+
+	settings, _ := json.Marshal(EmailSettings{Host: "127.0.0.1", Port: port, From: "status@example.com", TLSMode: "none"})
+	ch := &storage.NotificationChannel{Name: "status mail", Type: "email", Enabled: true, Settings: settings}
+	if err := store.CreateNotificationChannel(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	mon := &storage.Monitor{Name: "api-internal-3", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10, Enabled: true, Tags: []string{}, FailureThreshold: 1, SuccessThreshold: 1}
+	if err := store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	sp := &storage.StatusPage{Title: "Acme Status", Slug: "acme", Enabled: true, ShowIncidents: true, SubscriberChannelID: &ch.ID}
+	if err := store.CreateStatusPage(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetStatusPageMonitors(ctx, sp.ID, []storage.StatusPageMonitor{{PageID: sp.ID, MonitorID: mon.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetStatusPageComponents(ctx, sp.ID, []storage.StatusPageComponent{
+		{Name: "Public API", Monitors: []storage.StatusPageComponentMonitor{{MonitorID: mon.ID}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []*storage.StatusPageSubscriber{
+		{PageID: sp.ID, Email: "confirmed@example.com", Token: "tok-confirmed"},
+		{PageID: sp.ID, Email: "pending@example.com", Token: "tok-pending"},
+	} {
+		if err := store.CreateStatusPageSubscriber(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+		if sub.Email == "confirmed@example.com" {
+			if err := store.ConfirmStatusPageSubscriber(ctx, sub.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	d := NewDispatcher(store, slog.New(slog.NewTextHandler(io.Discard, nil)), true)
+	d.SetPublicURL("https://status.example.com/")
+	return d, sp, mon
+}
+
+func TestNotifySubscribers(t *testing.T) {
+	port, msgs := fakeSMTP(t)
+	d, _, mon := subscriberFixture(t, port)
+
+	d.notifySubscribers(&Payload{
+		EventType: "incident.created",
+		Incident:  &storage.Incident{ID: 1, MonitorID: mon.ID, MonitorName: mon.Name, Cause: "HTTP 503"},
+	})
+
+	select {
+	case msg := <-msgs:
+		if !strings.Contains(msg.rcpt, "confirmed@example.com") {
+			t.Errorf("sent to %q, want the confirmed subscriber", msg.rcpt)
+		}
+		for _, want := range []string{
+			"Subject: [Acme Status] Investigating: Public API",
+			"List-Unsubscribe: <https://status.example.com/acme/unsubscribe?token=tok-confirmed>",
+			"HTTP 503",
+			`href="https://status.example.com/acme"`,
+		} {
+			if !strings.Contains(msg.data, want) {
+				t.Errorf("message missing %q:\n%s", want, msg.data)
+			}
+		}
+		if strings.Contains(msg.data, mon.Name) {
+			t.Error("message should name the component, not the monitor behind it")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no subscriber email sent")
+	}
+
+	select {
+	case msg := <-msgs:
+		t.Errorf("unexpected second email to %q", msg.rcpt)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	d.notifySubscribers(&Payload{
+		EventType: "incident.reminder",
+		Incident:  &storage.Incident{ID: 1, MonitorID: mon.ID, MonitorName: mon.Name},
+	})
+	select {
+	case msg := <-msgs:
+		t.Errorf("reminders should not reach subscribers, got email to %q", msg.rcpt)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSendSubscriptionConfirmation(t *testing.T) {
+	port, msgs := fakeSMTP(t)
+	d, sp, _ := subscriberFixture(t, port)
+
+	sub := &storage.StatusPageSubscriber{PageID: sp.ID, Email: "new@example.com", Token: "abc123"}
+	if err := d.SendSubscriptionConfirmation(context.Background(), sp, sub); err != nil {
+		t.Fatal(err)
+	}
+	msg := <-msgs
+	if !strings.Contains(msg.rcpt, "new@example.com") {
+		t.Errorf("sent to %q", msg.rcpt)
+	}
+	if !strings.Contains(msg.data, "https://status.example.com/acme/subscribe/confirm?token=abc123") {
+		t.Errorf("missing confirmation link:\n%s", msg.data)
+	}
+	if strings.Contains(msg.data, "List-Unsubscribe") {
+		t.Error("confirmation email should not carry List-Unsubscribe")
+	}
+}
@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+type TwilioSettings struct {
+	AccountSID string   `json:"account_sid"`
+	AuthToken  string   `json:"auth_token"`
+	FromNumber string   `json:"from_number"`
+	ToNumbers  []string `json:"to_numbers"`
+}
+
+type TwilioSender struct {
+	baseURL string // overrides the Twilio API in tests
+}
+
+func (s *TwilioSender) Type() string { return "twilio" }
+
+func (s *TwilioSender) Send(ctx context.Context, channel *storage.NotificationChannel, payload *Payload) error {
+	var settings TwilioSettings
+	if err := json.Unmarshal(channel.Settings, &settings); err != nil {
+		return fmt.Errorf("invalid twilio settings: %w", err)
+	}
+	if settings.AccountSID == "" || settings.AuthToken == "" {
+		return fmt.Errorf("twilio account_sid and auth_token are required")
+	}
+	if settings.FromNumber == "" || len(settings.ToNumbers) == 0 {
+		return fmt.Errorf("twilio from_number and to_numbers are required")
+	}
+
+	base := s.baseURL
+	if base == "" {
+		base = "https://api.twilio.com"
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", base, url.PathEscape(settings.AccountSID))
+	text := smsText(payload)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	var errs []error
+	for _, to := range settings.ToNumbers {
+		if err := s.sendOne(ctx, client, endpoint, &settings, to, text); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", to, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *TwilioSender) sendOne(ctx context.Context, client *http.Client, endpoint string, settings *TwilioSettings, to, text string) error {
+	form := url.Values{
+		"From": {settings.FromNumber},
+		"To":   {to},
+		"Body": {text},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(settings.AccountSID, settings.AuthToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return providerError("twilio", resp, settings.AuthToken)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -7,10 +7,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/y0f/asura/internal/safenet"
@@ -19,8 +23,76 @@ import (
 
 // WebhookSettings holds webhook-specific configuration.
 type WebhookSettings struct {
-	URL    string `json:"url"`
-	Secret string `json:"secret,omitempty"` // HMAC-SHA256 signing secret
+	URL           string `json:"url"`
+	SigningSecret string `json:"signing_secret,omitempty"` // signs timestamp + "." + body
+	Secret        string `json:"secret,omitempty"`         // legacy: signs the body only
+	BodyTemplate  string `json:"body_template,omitempty"`  // text/template rendered against the Payload
+	ContentType   string `json:"content_type,omitempty"`   // for templated bodies; default application/json
+}
+
+// SignWebhook returns the X-Asura-Signature value for a delivery: the hex
+// HMAC-SHA256 of timestamp + "." + body, prefixed with "sha256=".
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the X-Asura-Signature and X-Asura-Timestamp
+// headers of a delivery against the raw request body. A timestamp further
+// than tolerance from now is rejected to limit replays; zero skips the check.
+func VerifyWebhookSignature(secret string, body []byte, timestamp, signature string, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	if tolerance > 0 {
+		if age := time.Since(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+			return fmt.Errorf("timestamp outside tolerance of %s", tolerance)
+		}
+	}
+	if !hmac.Equal([]byte(signature), []byte(SignWebhook(secret, timestamp, body))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+var webhookFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ParseWebhookTemplate parses a webhook body template. Besides the builtins,
+// templates can use json, upper and lower.
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("body").Funcs(webhookFuncs).Parse(text)
+}
+
+// webhookBody returns the request body and its content type: the default
+// JSON payload, or the rendered body template when one is set.
+func webhookBody(settings WebhookSettings, payload *Payload) ([]byte, string, error) {
+	if settings.BodyTemplate == "" {
+		return marshalPayload(payload), "application/json", nil
+	}
+	tmpl, err := ParseWebhookTemplate(settings.BodyTemplate)
+	if err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return nil, "", err
+	}
+	contentType := settings.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return buf.Bytes(), contentType, nil
 }
 
 type WebhookSender struct {
@@ -38,34 +110,32 @@ func (s *WebhookSender) Send(ctx context.Context, channel *storage.NotificationC
 		return fmt.Errorf("webhook URL is required")
 	}
 
-	body := marshalPayload(payload)
+	body, contentType, err := webhookBody(settings, payload)
+	if err != nil {
+		return permanent(fmt.Errorf("render webhook template: %w", err))
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, settings.URL, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("User-Agent", "Asura/1.0")
 
-	// HMAC-SHA256 signature
-	if settings.Secret != "" {
+	// HMAC-SHA256 signature over the exact bytes sent
+	if settings.SigningSecret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Asura-Timestamp", timestamp)
+		req.Header.Set("X-Asura-Signature", SignWebhook(settings.SigningSecret, timestamp, body))
+	} else if settings.Secret != "" {
 		mac := hmac.New(sha256.New, []byte(settings.Secret))
 		mac.Write(body)
 		sig := hex.EncodeToString(mac.Sum(nil))
 		req.Header.Set("X-Asura-Signature", "sha256="+sig)
 	}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout: 10 * time.Second,
-				Control: safenet.MaybeDialControl(s.AllowPrivate),
-			}).DialContext,
-		},
-	}
-	resp, err := client.Do(req)
+	resp, err := safeClient(s.AllowPrivate).Do(req)
 	if err != nil {
 		return fmt.Errorf("webhook request failed: %w", err)
 	}
@@ -78,3 +148,17 @@ func (s *WebhookSender) Send(ctx context.Context, channel *storage.NotificationC
 
 	return nil
 }
+
+// safeClient returns an HTTP client for user-supplied URLs that refuses to
+// dial private addresses unless allowPrivate is set.
+func safeClient(allowPrivate bool) *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout: 10 * time.Second,
+				Control: safenet.MaybeDialControl(allowPrivate),
+			}).DialContext,
+		},
+	}
+}
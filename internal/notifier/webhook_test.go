@@ -6,11 +6,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/y0f/asura/internal/storage"
 )
@@ -92,3 +95,139 @@ func TestWebhookSenderFailure(t *testing.T) {
 		t.Fatal("expected error for 500 response")
 	}
 }
+
+func TestWebhookSenderBodyTemplate(t *testing.T) {
+	var receivedBody, receivedType string
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		b, _ := io.ReadAll(r.Body)
+		receivedBody = string(b)
+		receivedType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	payload := &Payload{
+		EventType: "incident.created",
+		Incident:  &storage.Incident{ID: 7, MonitorName: "API", Cause: `timeout "5s"`},
+	}
+	send := func(tmpl, contentType string) error {
+		settings, _ := json.Marshal(WebhookSettings{URL: server.URL, BodyTemplate: tmpl, ContentType: contentType})
+		ch := &storage.NotificationChannel{Settings: settings}
+		return (&WebhookSender{AllowPrivate: true}).Send(context.Background(), ch, payload)
+	}
+
+	t.Run("renders against the payload", func(t *testing.T) {
+		err := send(`{"kind":"{{upper .EventType}}","id":{{.Incident.ID}},"summary":{{json .Incident.Cause}}}`, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"kind":"INCIDENT.CREATED","id":7,"summary":"timeout \"5s\""}`
+		if receivedBody != want {
+			t.Fatalf("expected %s, got %s", want, receivedBody)
+		}
+		if receivedType != "application/json" {
+			t.Fatalf("expected default content type, got %q", receivedType)
+		}
+	})
+
+	t.Run("custom content type", func(t *testing.T) {
+		if err := send(`{{.Incident.MonitorName}} is down`, "text/plain"); err != nil {
+			t.Fatal(err)
+		}
+		if receivedBody != "API is down" || receivedType != "text/plain" {
+			t.Fatalf("unexpected request: %q (%s)", receivedBody, receivedType)
+		}
+	})
+
+	t.Run("render errors skip the send", func(t *testing.T) {
+		before := calls
+		err := send(`{{.Change.Diff}}`, "")
+		var perm *permanentError
+		if !errors.As(err, &perm) {
+			t.Fatalf("expected a permanent error, got %v", err)
+		}
+		if calls != before {
+			t.Fatal("expected no request for a failed render")
+		}
+	})
+}
+
+func TestWebhookSenderSigningSecret(t *testing.T) {
+	var receivedBody []byte
+	var receivedSig, receivedTS string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		receivedSig = r.Header.Get("X-Asura-Signature")
+		receivedTS = r.Header.Get("X-Asura-Timestamp")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	settings, _ := json.Marshal(WebhookSettings{
+		URL:           server.URL,
+		SigningSecret: "whsec",
+		BodyTemplate:  `{{.Incident.MonitorName}} is down`,
+		ContentType:   "text/plain",
+	})
+	ch := &storage.NotificationChannel{Settings: settings}
+	payload := &Payload{EventType: "incident.created", Incident: &storage.Incident{MonitorName: "API"}}
+	if err := (&WebhookSender{AllowPrivate: true}).Send(context.Background(), ch, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(receivedBody) != "API is down" {
+		t.Fatalf("body = %q", receivedBody)
+	}
+	if receivedTS == "" {
+		t.Fatal("no timestamp received")
+	}
+	mac := hmac.New(sha256.New, []byte("whsec"))
+	mac.Write([]byte(receivedTS + ".API is down"))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); receivedSig != want {
+		t.Fatalf("signature = %s, want %s", receivedSig, want)
+	}
+	if err := VerifyWebhookSignature("whsec", receivedBody, receivedTS, receivedSig, 5*time.Minute); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"event_type":"incident.created"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		timestamp string
+		signature string
+		tolerance time.Duration
+		wantErr   string
+	}{
+		{"valid", "whsec", body, now, SignWebhook("whsec", now, body), 5 * time.Minute, ""},
+		{"wrong secret", "other", body, now, SignWebhook("whsec", now, body), 5 * time.Minute, "signature mismatch"},
+		{"tampered body", "whsec", []byte(`{}`), now, SignWebhook("whsec", now, body), 5 * time.Minute, "signature mismatch"},
+		{"timestamp swapped", "whsec", body, old, SignWebhook("whsec", now, body), 0, "signature mismatch"},
+		{"stale", "whsec", body, old, SignWebhook("whsec", old, body), 5 * time.Minute, "outside tolerance"},
+		{"stale without tolerance", "whsec", body, old, SignWebhook("whsec", old, body), 0, ""},
+		{"bad timestamp", "whsec", body, "soon", SignWebhook("whsec", "soon", body), 0, "invalid timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyWebhookSignature(tt.secret, tt.body, tt.timestamp, tt.signature, tt.tolerance)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
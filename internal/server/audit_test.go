@@ -0,0 +1,36 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestListAuditLogAPI(t *testing.T) {
+	srv, key := testServer(t)
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+	srv.store.InsertAudit(ctx, &storage.AuditEntry{Action: "create", Entity: "monitor", EntityID: 1, APIKeyName: "ci"})
+	srv.store.InsertAudit(ctx, &storage.AuditEntry{Action: "delete", Entity: "monitor", EntityID: 1, APIKeyName: "admin"})
+
+	w := checkRequest(t, srv, key, "GET", "/api/v1/audit?action=create&api_key=ci&from=2020-01-01T00:00:00Z")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var res struct {
+		Data  []storage.AuditEntry `json:"data"`
+		Total int64                `json:"total"`
+	}
+	json.NewDecoder(w.Body).Decode(&res)
+	if res.Total != 1 || len(res.Data) != 1 || res.Data[0].Action != "create" {
+		t.Errorf("unexpected entries: %+v", res)
+	}
+
+	for _, q := range []string{"from=yesterday", "to=2026-13-01"} {
+		if w := checkRequest(t, srv, key, "GET", "/api/v1/audit?"+q); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+}
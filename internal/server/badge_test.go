@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -155,3 +156,108 @@ func TestBadgeInvalidID(t *testing.T) {
 		})
 	}
 }
+
+func getBadgeJSON(t *testing.T, srv *Server, path string) map[string]any {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("content-type = %q, want application/json", ct)
+	}
+	var badge map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &badge); err != nil {
+		t.Fatal(err)
+	}
+	if badge["schemaVersion"] != float64(1) {
+		t.Errorf("schemaVersion = %v, want 1", badge["schemaVersion"])
+	}
+	return badge
+}
+
+func TestBadgeIncident(t *testing.T) {
+	srv, adminKey := testServer(t)
+	id := createMonitorOnStatusPage(t, srv, adminKey)
+	ctx := context.Background()
+
+	badge := getBadgeJSON(t, srv, "/api/v1/badge/1/incident?format=json")
+	if badge["label"] != "incidents" || badge["message"] != "operational" || badge["color"] != "#4c1" {
+		t.Errorf("badge = %v, want operational in green", badge)
+	}
+
+	if err := srv.store.CreateIncident(ctx, &storage.Incident{MonitorID: id, Status: "open", Cause: "slow", Severity: "warning"}); err != nil {
+		t.Fatal(err)
+	}
+	badge = getBadgeJSON(t, srv, "/api/v1/badge/1/incident?format=json")
+	if badge["message"] != "1 open incident" || badge["color"] != "#dfb317" {
+		t.Errorf("badge = %v, want 1 open incident in yellow", badge)
+	}
+
+	if err := srv.store.CreateIncident(ctx, &storage.Incident{MonitorID: id, Status: "acknowledged", Cause: "timeout"}); err != nil {
+		t.Fatal(err)
+	}
+	badge = getBadgeJSON(t, srv, "/api/v1/badge/1/incident?format=json")
+	if badge["message"] != "2 open incidents" || badge["color"] != "#e05d44" {
+		t.Errorf("badge = %v, want 2 open incidents in red", badge)
+	}
+
+	// A newer warning does not hide the older critical incident.
+	if err := srv.store.CreateIncident(ctx, &storage.Incident{MonitorID: id, Status: "open", Cause: "slow again", Severity: "warning"}); err != nil {
+		t.Fatal(err)
+	}
+	badge = getBadgeJSON(t, srv, "/api/v1/badge/1/incident?format=json")
+	if badge["message"] != "3 open incidents" || badge["color"] != "#e05d44" {
+		t.Errorf("badge = %v, want 3 open incidents in red", badge)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/badge/1/incident", nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if ct := w.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("content-type = %q, want image/svg+xml", ct)
+	}
+	if !strings.Contains(w.Body.String(), "3 open incidents") {
+		t.Error("expected incident count in badge SVG")
+	}
+}
+
+func TestBadgeOverrides(t *testing.T) {
+	srv, adminKey := testServer(t)
+	createMonitorOnStatusPage(t, srv, adminKey)
+
+	tests := []struct {
+		query     string
+		wantLabel string
+		wantColor string
+	}{
+		{"", "Badge Test", "#9f9f9f"},
+		{"label=API&color=blue", "API", "#007ec6"},
+		{"color=FF8800", "Badge Test", "#ff8800"},
+		{"color=%23abc", "Badge Test", "#abc"},
+		{"color=url(javascript:x)", "Badge Test", "#9f9f9f"},
+		{"label=a%0Ab%09c", "abc", "#9f9f9f"},
+		{"label=" + strings.Repeat("x", 80), strings.Repeat("x", 64), "#9f9f9f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			badge := getBadgeJSON(t, srv, "/api/v1/badge/1/status?format=json&"+tt.query)
+			if badge["label"] != tt.wantLabel {
+				t.Errorf("label = %v, want %q", badge["label"], tt.wantLabel)
+			}
+			if badge["color"] != tt.wantColor {
+				t.Errorf("color = %v, want %q", badge["color"], tt.wantColor)
+			}
+		})
+	}
+
+	t.Run("errors ignore color", func(t *testing.T) {
+		badge := getBadgeJSON(t, srv, "/api/v1/badge/999/uptime?format=json&color=red")
+		if badge["message"] != "not found" || badge["color"] != "#9f9f9f" {
+			t.Errorf("badge = %v, want grey not found", badge)
+		}
+	})
+}
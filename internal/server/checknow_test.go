@@ -0,0 +1,143 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/y0f/asura/internal/checker"
+	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/monitor"
+	"github.com/y0f/asura/internal/storage"
+)
+
+type upChecker struct{}
+
+func (upChecker) Type() string { return "http" }
+
+func (upChecker) Check(ctx context.Context, mon *storage.Monitor) (*checker.Result, error) {
+	return &checker.Result{Status: "up", StatusCode: 200, Message: "OK"}, nil
+}
+
+// checkNowServer returns a server backed by a running pipeline whose HTTP
+// checks always succeed.
+func checkNowServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	base, key := testServer(t)
+	registry := checker.NewRegistry()
+	registry.Register(upChecker{})
+	p := monitor.NewPipeline(base.store, registry, incident.NewManager(base.store, base.logger), 1, false, base.logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go p.Run(ctx)
+	return NewServer(base.cfg, base.store, p, base.notifier, base.logger, "test"), key
+}
+
+func checkNowBulkRequest(t *testing.T, srv *Server, key string, ids []int64) *httptest.ResponseRecorder {
+	t.Helper()
+	b, _ := json.Marshal(map[string]any{"ids": ids})
+	req := httptest.NewRequest("POST", "/api/v1/monitors/check", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+func TestCheckMonitorNow(t *testing.T) {
+	srv, key := checkNowServer(t)
+	ids := seedMonitors(t, srv, 1)
+	path := fmt.Sprintf("/api/v1/monitors/%d/check", ids[0])
+
+	w := checkRequest(t, srv, key, "POST", path)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var cr storage.CheckResult
+	json.Unmarshal(w.Body.Bytes(), &cr)
+	if cr.ID == 0 || cr.MonitorID != ids[0] || cr.Status != "up" {
+		t.Errorf("result = %+v", cr)
+	}
+
+	w = checkRequest(t, srv, key, "POST", path)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("repeat: status %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	if w := checkRequest(t, srv, key, "POST", "/api/v1/monitors/9999/check"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown monitor: status %d", w.Code)
+	}
+}
+
+func TestCheckMonitorNowRejectsPausedAndHeartbeat(t *testing.T) {
+	srv, key := checkNowServer(t)
+	ctx := context.Background()
+	paused := &storage.Monitor{Name: "paused", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10, FailureThreshold: 3, SuccessThreshold: 1}
+	hb := &storage.Monitor{Name: "hb", Type: "heartbeat", Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1}
+	for _, m := range []*storage.Monitor{paused, hb} {
+		if err := srv.store.CreateMonitor(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if w := checkRequest(t, srv, key, "POST", fmt.Sprintf("/api/v1/monitors/%d/check", paused.ID)); w.Code != http.StatusConflict {
+		t.Errorf("paused: status %d", w.Code)
+	}
+	if w := checkRequest(t, srv, key, "POST", fmt.Sprintf("/api/v1/monitors/%d/check", hb.ID)); w.Code != http.StatusBadRequest {
+		t.Errorf("heartbeat: status %d", w.Code)
+	}
+}
+
+func TestCheckMonitorsNow(t *testing.T) {
+	srv, key := checkNowServer(t)
+	ids := seedMonitors(t, srv, 2)
+
+	w := checkNowBulkRequest(t, srv, key, append(ids, 9999))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Checked int `json:"checked"`
+		Results []struct {
+			MonitorID int64                `json:"monitor_id"`
+			Check     *storage.CheckResult `json:"check"`
+			Error     string               `json:"error"`
+		} `json:"results"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Checked != 2 || len(resp.Results) != 3 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	for _, r := range resp.Results[:2] {
+		if r.Check == nil || r.Check.Status != "up" || r.Error != "" {
+			t.Errorf("monitor %d: %+v", r.MonitorID, r)
+		}
+	}
+	if resp.Results[2].Error != "monitor not found" {
+		t.Errorf("unknown monitor error = %q", resp.Results[2].Error)
+	}
+
+	if w := checkNowBulkRequest(t, srv, key, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("empty ids: status %d", w.Code)
+	}
+	tooMany := make([]int64, monitor.MaxTriggerBatch+1)
+	if w := checkNowBulkRequest(t, srv, key, tooMany); w.Code != http.StatusBadRequest {
+		t.Errorf("too many ids: status %d", w.Code)
+	}
+}
+
+func TestCheckNowWithoutPipeline(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 1)
+
+	if w := checkRequest(t, srv, key, "POST", fmt.Sprintf("/api/v1/monitors/%d/check", ids[0])); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("single: status %d", w.Code)
+	}
+	if w := checkNowBulkRequest(t, srv, key, ids); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("bulk: status %d", w.Code)
+	}
+}
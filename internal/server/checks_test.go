@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func checkRequest(t *testing.T, srv *Server, key, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+func seedCheck(t *testing.T, srv *Server, monitorID int64) *storage.CheckResult {
+	t.Helper()
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+	cr := &storage.CheckResult{
+		MonitorID: monitorID, Status: "down", Message: "timeout",
+		ConfigHash: "abc123",
+		Config:     json.RawMessage(`{"type":"http","target":"https://example.com","timeout":10}`),
+	}
+	if err := srv.store.InsertCheckResult(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	return cr
+}
+
+func TestGetCheck(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 2)
+	cr := seedCheck(t, srv, ids[0])
+
+	w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/checks/%d", ids[0], cr.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Check  storage.CheckResult  `json:"check"`
+		Config *storage.CheckConfig `json:"config"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Check.ID != cr.ID || resp.Check.ConfigHash != "abc123" {
+		t.Errorf("unexpected check: %+v", resp.Check)
+	}
+	if resp.Config == nil || resp.Config.Target != "https://example.com" {
+		t.Errorf("expected config snapshot, got %+v", resp.Config)
+	}
+
+	// check belonging to another monitor
+	w = checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/checks/%d", ids[1], cr.ID))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for foreign check, got %d", w.Code)
+	}
+
+	w = checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/checks/99999", ids[0]))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for missing check, got %d", w.Code)
+	}
+}
+
+func TestRerunCheckWithoutPipeline(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 1)
+	cr := seedCheck(t, srv, ids[0])
+
+	w := checkRequest(t, srv, key, "POST", fmt.Sprintf("/api/v1/monitors/%d/checks/%d/rerun", ids[0], cr.ID))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestExportChecksCSV(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 1)
+	first := seedCheck(t, srv, ids[0])
+	second := seedCheck(t, srv, ids[0])
+
+	w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/checks.csv", ids[0]))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != fmt.Sprintf(`attachment; filename="asura-monitor-%d-checks.csv"`, ids[0]) {
+		t.Errorf("unexpected Content-Disposition %q", cd)
+	}
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header and 2 rows, got %v", rows)
+	}
+	if strings.Join(rows[0], ",") != "id,status,response_time,status_code,message,created_at" {
+		t.Errorf("unexpected header %v", rows[0])
+	}
+	if rows[1][0] != fmt.Sprint(first.ID) || rows[2][0] != fmt.Sprint(second.ID) || rows[1][4] != "timeout" {
+		t.Errorf("unexpected rows %v", rows[1:])
+	}
+	if _, err := time.Parse(time.RFC3339, rows[1][5]); err != nil || !strings.HasSuffix(rows[1][5], "Z") {
+		t.Errorf("created_at %q is not RFC3339 UTC", rows[1][5])
+	}
+
+	future := url.QueryEscape(time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	w = checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/checks.csv?from=%s", ids[0], future))
+	if rows, _ := csv.NewReader(w.Body).ReadAll(); len(rows) != 1 {
+		t.Errorf("expected only the header for a future range, got %v", rows)
+	}
+
+	w = checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/checks.csv?from=yesterday", ids[0]))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad from, got %d", w.Code)
+	}
+	w = checkRequest(t, srv, key, "GET", "/api/v1/monitors/9999/checks.csv")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing monitor, got %d", w.Code)
+	}
+}
+
+// rangeStore replaces ListCheckResultsRange with fn.
+type rangeStore struct {
+	storage.Store
+	fn    func(call int) ([]*storage.CheckResult, error)
+	calls int
+}
+
+func (s *rangeStore) ListCheckResultsRange(ctx context.Context, monitorID int64, from, to time.Time, afterID int64, limit int) ([]*storage.CheckResult, error) {
+	s.calls++
+	return s.fn(s.calls)
+}
+
+func TestExportChecksCSVOutlastsWriteTimeout(t *testing.T) {
+	base, key := testServer(t)
+	ids := seedMonitors(t, base, 1)
+	base.cfg.Server.WriteTimeout = 300 * time.Millisecond
+	store := &rangeStore{Store: base.store, fn: func(call int) ([]*storage.CheckResult, error) {
+		time.Sleep(150 * time.Millisecond)
+		n := 1000
+		if call == 4 {
+			n = 1
+		}
+		batch := make([]*storage.CheckResult, n)
+		for i := range batch {
+			batch[i] = &storage.CheckResult{ID: int64((call-1)*1000 + i + 1), Status: "up"}
+		}
+		return batch, nil
+	}}
+	srv := NewServer(base.cfg, store, nil, nil, base.logger, "test")
+
+	ts := httptest.NewUnstartedServer(srv)
+	ts.Config.WriteTimeout = base.cfg.Server.WriteTimeout
+	ts.Start()
+	defer ts.Close()
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/monitors/%d/checks.csv", ts.URL, ids[0]), nil)
+	req.Header.Set("X-API-Key", key)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("export cut off after %d rows: %v", len(rows), err)
+	}
+	if len(rows) != 3002 {
+		t.Fatalf("expected header and 3001 rows, got %d", len(rows))
+	}
+}
+
+func TestExportChecksCSVAbortsOnError(t *testing.T) {
+	base, key := testServer(t)
+	ids := seedMonitors(t, base, 1)
+	store := &rangeStore{Store: base.store, fn: func(call int) ([]*storage.CheckResult, error) {
+		if call == 2 {
+			return nil, errors.New("disk I/O error")
+		}
+		batch := make([]*storage.CheckResult, 1000)
+		for i := range batch {
+			batch[i] = &storage.CheckResult{ID: int64(i + 1), Status: "up"}
+		}
+		return batch, nil
+	}}
+	srv := NewServer(base.cfg, store, nil, nil, base.logger, "test")
+
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Fatalf("expected the export to abort with http.ErrAbortHandler, got %v", err)
+		}
+	}()
+	checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/checks.csv", ids[0]))
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -50,12 +51,15 @@ func TestCloneMonitorCopiesFields(t *testing.T) {
 	srv.store.CreateMonitorGroup(ctx, g)
 	gid = g.ID
 
+	parent := &storage.Monitor{Name: "Gateway", Type: "http", Target: "https://gw.example.com", Interval: 30, Timeout: 5, FailureThreshold: 1, SuccessThreshold: 1}
+	srv.store.CreateMonitor(ctx, parent)
+
 	m := &storage.Monitor{
 		Name: "Source", Type: "http", Target: "https://example.com",
 		Interval: 30, Timeout: 5, Enabled: true,
 		FailureThreshold: 5, SuccessThreshold: 2,
 		Tags: []string{"prod", "api"}, UpsideDown: true,
-		ResendInterval: 120, GroupID: &gid,
+		ResendInterval: 120, GroupID: &gid, ParentID: &parent.ID,
 	}
 	srv.store.CreateMonitor(ctx, m)
 
@@ -88,6 +92,9 @@ func TestCloneMonitorCopiesFields(t *testing.T) {
 	if clone.GroupID == nil || *clone.GroupID != gid {
 		t.Error("group_id should be copied")
 	}
+	if clone.ParentID == nil || *clone.ParentID != parent.ID {
+		t.Error("parent_id should be copied")
+	}
 }
 
 func TestCloneMonitorNotFound(t *testing.T) {
@@ -115,6 +122,7 @@ func TestCloneMonitorCopiesNotificationChannels(t *testing.T) {
 	}
 	srv.store.CreateNotificationChannel(ctx, ch)
 	srv.store.SetMonitorNotificationChannels(ctx, m.ID, []int64{ch.ID})
+	srv.store.SetMonitorNotificationFilters(ctx, m.ID, map[int64]*storage.NotificationFilter{ch.ID: {MinSeverity: "critical"}})
 
 	w := cloneRequest(t, srv, key, m.ID)
 	if w.Code != http.StatusCreated {
@@ -128,6 +136,10 @@ func TestCloneMonitorCopiesNotificationChannels(t *testing.T) {
 	if len(chIDs) != 1 || chIDs[0] != ch.ID {
 		t.Errorf("expected notification channels to be copied, got %v", chIDs)
 	}
+	filters, _ := srv.store.GetMonitorNotificationFilters(ctx, clone.ID)
+	if f := filters[ch.ID]; f == nil || f.MinSeverity != "critical" {
+		t.Errorf("expected the channel filter to be copied, got %v", filters)
+	}
 }
 
 func TestCloneRequiresAuth(t *testing.T) {
@@ -137,3 +149,125 @@ func TestCloneRequiresAuth(t *testing.T) {
 		t.Fatalf("expected 401, got %d", w.Code)
 	}
 }
+
+func templateRequest(t *testing.T, srv *Server, key string, body map[string]any) *httptest.ResponseRecorder {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/api/v1/monitors/template", bytes.NewReader(b))
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateMonitorsFromTemplate(t *testing.T) {
+	srv, key := testServer(t)
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+
+	ch := &storage.NotificationChannel{Name: "ops", Type: "webhook", Enabled: true, Settings: []byte(`{"url":"https://example.com/hook"}`)}
+	if err := srv.store.CreateNotificationChannel(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	region := &storage.Tag{Name: "region", Color: "#ff0000"}
+	team := &storage.Tag{Name: "team", Color: "#00ff00"}
+	srv.store.CreateTag(ctx, region)
+	srv.store.CreateTag(ctx, team)
+
+	base := &storage.Monitor{
+		Name: "API", Type: "http", Target: "https://example.com/health",
+		Interval: 30, Timeout: 5, Enabled: true, FailureThreshold: 2, SuccessThreshold: 1,
+		Settings:   json.RawMessage(`{"method":"HEAD"}`),
+		Assertions: json.RawMessage(`[{"type":"status_code","operator":"eq","value":"200"}]`),
+	}
+	if err := srv.store.CreateMonitor(ctx, base); err != nil {
+		t.Fatal(err)
+	}
+	srv.store.SetMonitorNotificationChannels(ctx, base.ID, []int64{ch.ID})
+	srv.store.SetMonitorNotificationFilters(ctx, base.ID, map[int64]*storage.NotificationFilter{ch.ID: {MinSeverity: "critical"}})
+	srv.store.SetMonitorTags(ctx, base.ID, []storage.MonitorTag{{TagID: team.ID, Value: "core"}})
+
+	w := templateRequest(t, srv, key, map[string]any{
+		"base_id": base.ID,
+		"items": []map[string]any{
+			{"name": "API eu", "target": "https://eu.example.com/health", "tag_values": map[string]string{"region": "eu"}},
+			{"name": "", "target": "https://bad.example.com"},
+			{"name": "API us", "tag_values": map[string]string{"team": "edge"}},
+			{"name": "API x", "tag_values": map[string]string{"missing": "x"}},
+		},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Created []int64 `json:"created"`
+		Errors  []struct {
+			Index int    `json:"index"`
+			Error string `json:"error"`
+		} `json:"errors"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Created) != 2 {
+		t.Fatalf("expected 2 created, got %v", resp.Created)
+	}
+	if len(resp.Errors) != 2 || resp.Errors[0].Index != 1 || resp.Errors[1].Index != 3 {
+		t.Fatalf("expected errors for items 1 and 3, got %+v", resp.Errors)
+	}
+
+	eu, err := srv.store.GetMonitor(ctx, resp.Created[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eu.Name != "API eu" || eu.Target != "https://eu.example.com/health" || !eu.Enabled {
+		t.Errorf("unexpected monitor: %+v", eu)
+	}
+	if eu.Interval != 30 || string(eu.Settings) != `{"method":"HEAD"}` || len(eu.Assertions) < 10 {
+		t.Errorf("config not copied: %+v", eu)
+	}
+	if ids, _ := srv.store.GetMonitorNotificationChannelIDs(ctx, eu.ID); len(ids) != 1 || ids[0] != ch.ID {
+		t.Errorf("channels = %v", ids)
+	}
+	if filters, _ := srv.store.GetMonitorNotificationFilters(ctx, eu.ID); filters[ch.ID] == nil {
+		t.Error("notification filter not copied")
+	}
+	tags, _ := srv.store.GetMonitorTags(ctx, eu.ID)
+	values := map[string]string{}
+	for _, tg := range tags {
+		values[tg.Name] = tg.Value
+	}
+	if values["team"] != "core" || values["region"] != "eu" {
+		t.Errorf("eu tags = %v", values)
+	}
+
+	us, _ := srv.store.GetMonitor(ctx, resp.Created[1])
+	if us.Target != base.Target {
+		t.Errorf("empty target should keep the base target, got %q", us.Target)
+	}
+	tags, _ = srv.store.GetMonitorTags(ctx, us.ID)
+	if len(tags) != 1 || tags[0].Value != "edge" {
+		t.Errorf("us tags = %+v", tags)
+	}
+}
+
+func TestCreateMonitorsFromTemplateRejects(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 1)
+
+	tests := []struct {
+		name   string
+		body   map[string]any
+		status int
+	}{
+		{"missing base", map[string]any{"items": []map[string]any{{"name": "a"}}}, http.StatusBadRequest},
+		{"unknown base", map[string]any{"base_id": 9999, "items": []map[string]any{{"name": "a"}}}, http.StatusNotFound},
+		{"no items", map[string]any{"base_id": ids[0]}, http.StatusBadRequest},
+		{"all invalid", map[string]any{"base_id": ids[0], "items": []map[string]any{{"name": ""}}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := templateRequest(t, srv, key, tt.body)
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
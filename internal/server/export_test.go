@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/y0f/asura/internal/api"
@@ -162,6 +164,13 @@ func TestExportWithData(t *testing.T) {
 func TestExportRedactSecrets(t *testing.T) {
 	srv, adminKey := testServer(t)
 	seedTestData(t, srv, adminKey)
+	if w := checkRequest(t, srv, adminKey, "POST", "/api/v1/status-pages/1/api-token"); w.Code != http.StatusOK {
+		t.Fatalf("regenerate token: expected 200, got %d", w.Code)
+	}
+
+	if full := getExport(t, srv, adminKey, ""); full.StatusPages[0].APITokenHash == "" {
+		t.Fatal("expected the status page API token hash in an unredacted export")
+	}
 
 	data := getExport(t, srv, adminKey, "redact_secrets=true")
 
@@ -171,6 +180,134 @@ func TestExportRedactSecrets(t *testing.T) {
 	if data.Proxies[0].AuthUser != "" || data.Proxies[0].AuthPass != "" {
 		t.Fatal("expected redacted proxy credentials")
 	}
+	if data.StatusPages[0].APITokenHash != "" {
+		t.Fatal("expected redacted status page API token")
+	}
+}
+
+func TestExportImportProbes(t *testing.T) {
+	srv, adminKey := testServer(t)
+	post(t, srv, adminKey, "/api/v1/probes", map[string]any{
+		"name": "eu-west", "type": "agent", "url": "https://eu.example.com/api/v1/probe/check",
+		"token": "ak_agent", "enabled": true,
+	}, http.StatusCreated)
+	post(t, srv, adminKey, "/api/v1/monitors", map[string]any{
+		"name": "Regional", "type": "http", "target": "https://example.com",
+		"interval": 60, "timeout": 5, "probe_ids": []int{1},
+	}, http.StatusCreated)
+
+	if redacted := getExport(t, srv, adminKey, "redact_secrets=true"); redacted.Probes[0].Token != "" {
+		t.Fatal("expected redacted probe token")
+	}
+	exportJSON := getRawExport(t, srv, adminKey)
+
+	srv2, adminKey2 := testServer(t)
+	if stats := doImport(t, srv2, adminKey2, exportJSON, "merge"); stats.Probes != 1 || stats.Monitors != 1 || stats.Errors != 0 {
+		t.Fatalf("unexpected import stats: %+v", stats)
+	}
+	data := getExport(t, srv2, adminKey2, "")
+	if len(data.Probes) != 1 || data.Probes[0].Token != "ak_agent" {
+		t.Fatalf("expected the probe with its token, got %+v", data.Probes)
+	}
+	if len(data.Monitors) != 1 || len(data.Monitors[0].ProbeNames) != 1 || data.Monitors[0].ProbeNames[0] != "eu-west" {
+		t.Fatalf("expected the probe assignment preserved, got %+v", data.Monitors)
+	}
+}
+
+func TestExportImportOncall(t *testing.T) {
+	srv, adminKey := testServer(t)
+	post(t, srv, adminKey, "/api/v1/oncall-schedules", map[string]any{
+		"name": "Primary", "rotation_start": "2026-01-05T09:00", "shift_hours": 168,
+		"members": []map[string]any{{"name": "Alice", "routing_key": "R0ALICE"}},
+	}, http.StatusCreated)
+	post(t, srv, adminKey, "/api/v1/notifications", map[string]any{
+		"name": "Pager", "type": "pagerduty", "enabled": true,
+		"settings":           map[string]any{"routing_key": "R0TEAM"},
+		"events":             []string{"incident.created"},
+		"oncall_schedule_id": 1,
+	}, http.StatusCreated)
+
+	if redacted := getExport(t, srv, adminKey, "redact_secrets=true"); redacted.OncallSchedules[0].Members[0].RoutingKey != "" {
+		t.Fatal("expected redacted member routing keys")
+	}
+	exportJSON := getRawExport(t, srv, adminKey)
+
+	srv2, adminKey2 := testServer(t)
+	if stats := doImport(t, srv2, adminKey2, exportJSON, "merge"); stats.OncallSchedules != 1 || stats.Channels != 1 || stats.Errors != 0 {
+		t.Fatalf("unexpected import stats: %+v", stats)
+	}
+	data := getExport(t, srv2, adminKey2, "")
+	if len(data.OncallSchedules) != 1 || data.OncallSchedules[0].Members[0].RoutingKey != "R0ALICE" {
+		t.Fatalf("expected the schedule with its members, got %+v", data.OncallSchedules)
+	}
+	if data.NotificationChannels[0].OncallScheduleName != "Primary" {
+		t.Fatalf("expected the channel linked to Primary, got %+v", data.NotificationChannels[0])
+	}
+}
+
+func TestExportImportChannelOptions(t *testing.T) {
+	srv, adminKey := testServer(t)
+	post(t, srv, adminKey, "/api/v1/tags", map[string]any{"name": "payments", "color": "#ff0000"}, http.StatusCreated)
+	post(t, srv, adminKey, "/api/v1/notifications", map[string]any{
+		"name": "Digest", "type": "webhook", "enabled": true,
+		"settings":                map[string]any{"url": "https://hooks.example.com/test"},
+		"events":                  []string{"incident.created"},
+		"tag_ids":                 []int{1},
+		"digest_window_seconds":   300,
+		"digest_flush_on_resolve": true,
+	}, http.StatusCreated)
+	exportJSON := getRawExport(t, srv, adminKey)
+
+	srv2, adminKey2 := testServer(t)
+	if stats := doImport(t, srv2, adminKey2, exportJSON, "merge"); stats.Channels != 1 || stats.Errors != 0 {
+		t.Fatalf("unexpected import stats: %+v", stats)
+	}
+	ch := getExport(t, srv2, adminKey2, "").NotificationChannels[0]
+	if ch.DigestWindowSeconds != 300 || !ch.DigestFlushOnResolve {
+		t.Errorf("digest options did not round-trip: %+v", ch)
+	}
+	if !reflect.DeepEqual(ch.TagNames, []string{"payments"}) {
+		t.Errorf("expected tag payments, got %v", ch.TagNames)
+	}
+}
+
+func TestExportImportParent(t *testing.T) {
+	srv, adminKey := testServer(t)
+	// The child is created, and so exported, before its parent.
+	post(t, srv, adminKey, "/api/v1/monitors", map[string]any{
+		"name": "API", "type": "http", "target": "https://api.example.com", "interval": 60, "timeout": 5,
+	}, http.StatusCreated)
+	post(t, srv, adminKey, "/api/v1/monitors", map[string]any{
+		"name": "Gateway", "type": "http", "target": "https://gw.example.com", "interval": 60, "timeout": 5,
+	}, http.StatusCreated)
+	w := probeRequest(t, srv, adminKey, "PUT", "/api/v1/monitors/1", map[string]any{
+		"name": "API", "type": "http", "target": "https://api.example.com", "interval": 60, "timeout": 5,
+		"failure_threshold": 1, "success_threshold": 1, "parent_id": 2,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("set parent: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var single api.ExportData
+	json.NewDecoder(checkRequest(t, srv, adminKey, "GET", "/api/v1/monitors/1/export").Body).Decode(&single)
+	if len(single.Monitors) != 1 || single.Monitors[0].ParentName != "Gateway" {
+		t.Fatalf("single monitor export: expected parent Gateway, got %+v", single.Monitors)
+	}
+	exportJSON := getRawExport(t, srv, adminKey)
+
+	srv2, adminKey2 := testServer(t)
+	if stats := doImport(t, srv2, adminKey2, exportJSON, "merge"); stats.Monitors != 2 || stats.Errors != 0 {
+		t.Fatalf("unexpected import stats: %+v", stats)
+	}
+	for _, em := range getExport(t, srv2, adminKey2, "").Monitors {
+		want := ""
+		if em.Name == "API" {
+			want = "Gateway"
+		}
+		if em.ParentName != want {
+			t.Errorf("%s: parent = %q, want %q", em.Name, em.ParentName, want)
+		}
+	}
 }
 
 func TestImportIntoEmpty(t *testing.T) {
@@ -207,6 +344,51 @@ func TestImportIntoEmpty(t *testing.T) {
 	}
 }
 
+func TestExportSingleMonitor(t *testing.T) {
+	srv, adminKey := testServer(t)
+	seedTestData(t, srv, adminKey)
+
+	req := httptest.NewRequest("GET", "/api/v1/monitors/1/export", nil)
+	req.Header.Set("X-API-Key", adminKey)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export monitor: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	exportJSON := w.Body.Bytes()
+
+	var data api.ExportData
+	if err := json.Unmarshal(exportJSON, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Monitors) != 1 || data.Monitors[0].Name != "API Health" {
+		t.Fatalf("expected only API Health, got %+v", data.Monitors)
+	}
+	em := data.Monitors[0]
+	if em.GroupName != "Backend" || em.ProxyName != "US Proxy" || len(em.NotificationChannelNames) != 1 {
+		t.Fatalf("expected resolved references, got group=%q proxy=%q channels=%v",
+			em.GroupName, em.ProxyName, em.NotificationChannelNames)
+	}
+
+	srv2, adminKey2 := testServer(t)
+	post(t, srv2, adminKey2, "/api/v1/groups", map[string]any{"name": "Backend"}, http.StatusCreated)
+	stats := doImport(t, srv2, adminKey2, exportJSON, "merge")
+	if stats.Monitors != 1 || stats.Errors != 0 {
+		t.Fatalf("expected 1 monitor imported, got %+v", stats)
+	}
+	if got := getExport(t, srv2, adminKey2, ""); got.Monitors[0].GroupName != "Backend" {
+		t.Errorf("expected existing group to be linked, got %q", got.Monitors[0].GroupName)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/monitors/99/export", nil)
+	req.Header.Set("X-API-Key", adminKey)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("missing monitor: expected 404, got %d", w.Code)
+	}
+}
+
 func TestImportMergeSkipsDuplicates(t *testing.T) {
 	srv, adminKey := testServer(t)
 	seedTestData(t, srv, adminKey)
@@ -295,3 +477,90 @@ func TestExportContentDisposition(t *testing.T) {
 		t.Fatalf("expected Content-Disposition attachment, got %q", cd)
 	}
 }
+
+func TestExportImportYAML(t *testing.T) {
+	srv, adminKey := testServer(t)
+	seedTestData(t, srv, adminKey)
+	post(t, srv, adminKey, "/api/v1/monitors", map[string]any{
+		"name": "Search", "type": "http", "target": "https://example.com/search",
+		"interval": 60, "timeout": 10,
+		"settings":   map[string]any{"method": "POST", "body": "{\"q\": \"status\"}", "headers": map[string]string{"X-Env": "prod"}},
+		"assertions": []map[string]any{{"type": "status_code", "operator": "eq", "value": "200"}},
+	}, http.StatusCreated)
+
+	req := httptest.NewRequest("GET", "/api/v1/export?format=yaml", nil)
+	req.Header.Set("X-API-Key", adminKey)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-yaml" {
+		t.Fatalf("expected Content-Type application/x-yaml, got %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="asura-export.yaml"` {
+		t.Fatalf("unexpected Content-Disposition %q", cd)
+	}
+	exportYAML := w.Body.Bytes()
+	if !bytes.Contains(exportYAML, []byte(" X-Env: prod\n")) {
+		t.Fatalf("expected settings as nested YAML, got:\n%s", exportYAML)
+	}
+
+	srv2, adminKey2 := testServer(t)
+	req = httptest.NewRequest("POST", "/api/v1/import?mode=merge", bytes.NewReader(exportYAML))
+	req.Header.Set("X-API-Key", adminKey2)
+	req.Header.Set("Content-Type", "application/x-yaml")
+	w = httptest.NewRecorder()
+	srv2.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var stats api.ImportStats
+	json.NewDecoder(w.Body).Decode(&stats)
+	if stats.Monitors != 3 || stats.Channels != 1 || stats.StatusPages != 1 || stats.Errors != 0 {
+		t.Fatalf("unexpected import stats %+v", stats)
+	}
+
+	orig := getExport(t, srv, adminKey, "")
+	data := getExport(t, srv2, adminKey2, "")
+	for i, m := range data.Monitors {
+		if !jsonEqual(m.Settings, orig.Monitors[i].Settings) || !jsonEqual(m.Assertions, orig.Monitors[i].Assertions) {
+			t.Fatalf("monitor %q did not round-trip: settings %s assertions %s", m.Name, m.Settings, m.Assertions)
+		}
+	}
+	if !jsonEqual(data.NotificationChannels[0].Settings, orig.NotificationChannels[0].Settings) {
+		t.Fatalf("channel settings did not round-trip: %s", data.NotificationChannels[0].Settings)
+	}
+}
+
+func TestImportYAMLInvalid(t *testing.T) {
+	srv, adminKey := testServer(t)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"unsupported version", "version: 2\nmonitors: []\n"},
+		{"unknown field", "version: 1\nmonitor: []\n"},
+		{"malformed", "version: [1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/import", strings.NewReader(tt.body))
+			req.Header.Set("X-API-Key", adminKey)
+			req.Header.Set("Content-Type", "application/yaml")
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb any
+	json.Unmarshal(a, &va)
+	json.Unmarshal(b, &vb)
+	return reflect.DeepEqual(va, vb)
+}
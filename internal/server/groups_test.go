@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestGroupStatus(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 2)
+
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+	g := &storage.MonitorGroup{Name: "Edge"}
+	srv.store.CreateMonitorGroup(ctx, g)
+	if _, err := srv.store.BulkSetMonitorGroup(ctx, ids, &g.ID); err != nil {
+		t.Fatal(err)
+	}
+	srv.store.UpsertMonitorStatus(ctx, &storage.MonitorStatus{MonitorID: ids[0], Status: "up"})
+	srv.store.UpsertMonitorStatus(ctx, &storage.MonitorStatus{MonitorID: ids[1], Status: "down"})
+
+	w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/groups/%d/status", g.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var gs storage.GroupStatus
+	json.NewDecoder(w.Body).Decode(&gs)
+	if gs.Status != "down" || gs.Up != 1 || gs.Down != 1 || gs.Total != 2 {
+		t.Errorf("unexpected rollup: %+v", gs)
+	}
+
+	if w := checkRequest(t, srv, key, "GET", "/api/v1/groups/9999/status"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestGroupUptime(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 2)
+
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+	g := &storage.MonitorGroup{Name: "Checkout"}
+	srv.store.CreateMonitorGroup(ctx, g)
+	if _, err := srv.store.BulkSetMonitorGroup(ctx, ids, &g.ID); err != nil {
+		t.Fatal(err)
+	}
+	srv.store.InsertCheckResult(ctx, &storage.CheckResult{MonitorID: ids[0], Status: "up"})
+	srv.store.InsertCheckResult(ctx, &storage.CheckResult{MonitorID: ids[1], Status: "down"})
+
+	from := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	to := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	uptime := func(mode string) storage.GroupUptime {
+		t.Helper()
+		w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/groups/%d/uptime?mode=%s&from=%s&to=%s", g.ID, mode, from, to))
+		if w.Code != http.StatusOK {
+			t.Fatalf("mode %q: expected 200, got %d: %s", mode, w.Code, w.Body.String())
+		}
+		var gu storage.GroupUptime
+		json.NewDecoder(w.Body).Decode(&gu)
+		return gu
+	}
+	if gu := uptime(""); gu.Mode != "all" || gu.UptimePct == nil || *gu.UptimePct != 0 {
+		t.Errorf("all: unexpected uptime: %+v", gu)
+	}
+	if gu := uptime("any"); gu.UptimePct == nil || *gu.UptimePct != 100 {
+		t.Errorf("any: unexpected uptime: %+v", gu)
+	}
+
+	if w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/groups/%d/uptime?mode=most", g.ID)); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown mode, got %d", w.Code)
+	}
+	if w := checkRequest(t, srv, key, "GET", "/api/v1/groups/9999/uptime"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestGroupSLA(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 2)
+
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+	g := &storage.MonitorGroup{Name: "Payments"}
+	srv.store.CreateMonitorGroup(ctx, g)
+	if _, err := srv.store.BulkSetMonitorGroup(ctx, ids, &g.ID); err != nil {
+		t.Fatal(err)
+	}
+	srv.store.InsertCheckResult(ctx, &storage.CheckResult{MonitorID: ids[0], Status: "up"})
+	srv.store.InsertCheckResult(ctx, &storage.CheckResult{MonitorID: ids[1], Status: "down"})
+
+	from := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	to := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/groups/%d/sla?from=%s&to=%s", g.ID, from, to))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rep storage.GroupSLAReport
+	json.NewDecoder(w.Body).Decode(&rep)
+	if rep.GroupID != g.ID || rep.NoData || len(rep.Monitors) != 2 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	if rep.TotalChecks != 2 || rep.FailedChecks != 1 {
+		t.Errorf("checks = %d/%d, want 2 with 1 failed", rep.TotalChecks, rep.FailedChecks)
+	}
+
+	w = checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/groups/%d/sla", g.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	rep = storage.GroupSLAReport{}
+	json.NewDecoder(w.Body).Decode(&rep)
+	if !rep.NoData || rep.UptimePct != nil {
+		t.Errorf("expected no data for last month, got %+v", rep)
+	}
+
+	if w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/groups/%d/sla?from=yesterday", g.ID)); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad range, got %d", w.Code)
+	}
+	if w := checkRequest(t, srv, key, "GET", "/api/v1/groups/9999/sla"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
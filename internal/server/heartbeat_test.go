@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestHeartbeatPauseResume(t *testing.T) {
+	srv, _ := testServer(t)
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+
+	mon := &storage.Monitor{
+		Name: "cron", Type: "heartbeat", Target: "heartbeat",
+		Interval: 300, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1,
+	}
+	if err := srv.store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.store.CreateHeartbeat(ctx, &storage.Heartbeat{MonitorID: mon.ID, Token: "tok123", Grace: 0, Status: "pending"}); err != nil {
+		t.Fatal(err)
+	}
+
+	status := func(w *httptest.ResponseRecorder) string {
+		t.Helper()
+		var resp struct {
+			Status string `json:"status"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp.Status
+	}
+	stored := func() string {
+		t.Helper()
+		hb, err := srv.store.GetHeartbeatByToken(ctx, "tok123")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hb.Status
+	}
+
+	w := checkRequest(t, srv, "", "POST", "/api/v1/heartbeat/tok123/pause")
+	if w.Code != http.StatusOK || status(w) != "paused" {
+		t.Fatalf("pause: expected 200 paused, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := stored(); got != "paused" {
+		t.Fatalf("expected stored status paused, got %s", got)
+	}
+
+	w = checkRequest(t, srv, "", "POST", "/api/v1/heartbeat/tok123")
+	if w.Code != http.StatusOK {
+		t.Fatalf("ping: expected 200, got %d", w.Code)
+	}
+	if got := stored(); got != "paused" {
+		t.Fatalf("expected a ping not to resume, got %s", got)
+	}
+
+	w = checkRequest(t, srv, "", "POST", "/api/v1/heartbeat/tok123/resume")
+	if w.Code != http.StatusOK || status(w) != "pending" {
+		t.Fatalf("resume: expected 200 pending, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := stored(); got != "pending" {
+		t.Fatalf("expected stored status pending, got %s", got)
+	}
+
+	w = checkRequest(t, srv, "", "POST", "/api/v1/heartbeat/tok123/resume")
+	if w.Code != http.StatusOK || status(w) != "pending" {
+		t.Fatalf("resume again: expected 200 pending, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, path := range []string{"/api/v1/heartbeat/nope/pause", "/api/v1/heartbeat/nope/resume"} {
+		if w := checkRequest(t, srv, "", "POST", path); w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, w.Code)
+		}
+	}
+}
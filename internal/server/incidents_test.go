@@ -0,0 +1,238 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/notifier"
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestAckIncidentTimeout(t *testing.T) {
+	srv, key := testServer(t)
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+
+	mon := &storage.Monitor{Name: "Ack", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 5, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1}
+	if err := srv.store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	newIncident := func() *storage.Incident {
+		inc := &storage.Incident{MonitorID: mon.ID, Status: "open", Cause: "timeout"}
+		if err := srv.store.CreateIncident(ctx, inc); err != nil {
+			t.Fatal(err)
+		}
+		return inc
+	}
+
+	inc := newIncident()
+	w := probeRequest(t, srv, key, "POST", fmt.Sprintf("/api/v1/incidents/%d/ack", inc.ID), map[string]any{"ack_timeout_minutes": 100000})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("out of range timeout: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	before := time.Now()
+	w = probeRequest(t, srv, key, "POST", fmt.Sprintf("/api/v1/incidents/%d/ack", inc.ID), map[string]any{"ack_timeout_minutes": 30})
+	if w.Code != http.StatusOK {
+		t.Fatalf("ack: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var acked storage.Incident
+	json.NewDecoder(w.Body).Decode(&acked)
+	if acked.AckDeadline == nil {
+		t.Fatal("expected ack_deadline to be set")
+	}
+	if d := acked.AckDeadline.Sub(before); d < 29*time.Minute || d > 31*time.Minute {
+		t.Fatalf("ack_deadline %v not ~30m after ack", acked.AckDeadline)
+	}
+
+	w = checkRequest(t, srv, key, "POST", fmt.Sprintf("/api/v1/incidents/%d/resolve", inc.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("resolve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	got, err := srv.store.GetIncident(ctx, inc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AckDeadline != nil {
+		t.Fatalf("resolving should clear ack_deadline, got %v", got.AckDeadline)
+	}
+
+	inc = newIncident()
+	w = checkRequest(t, srv, key, "POST", fmt.Sprintf("/api/v1/incidents/%d/ack", inc.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("ack without body: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	got, _ = srv.store.GetIncident(ctx, inc.ID)
+	if got.Status != "acknowledged" || got.AckDeadline != nil {
+		t.Fatalf("expected acknowledged incident without deadline, got %+v", got)
+	}
+
+	// A chunked request reports no ContentLength, even when it is empty.
+	inc = newIncident()
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/incidents/%d/ack", inc.ID), io.MultiReader())
+	req.Header.Set("X-API-Key", key)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("chunked ack: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	got, _ = srv.store.GetIncident(ctx, inc.ID)
+	if got.Status != "acknowledged" {
+		t.Fatalf("chunked ack: expected acknowledged incident, got %+v", got)
+	}
+}
+
+func TestIncidentPostmortemAndNotes(t *testing.T) {
+	srv, key := testServer(t)
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+
+	mon := &storage.Monitor{Name: "PM", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 5, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1}
+	if err := srv.store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	inc := &storage.Incident{MonitorID: mon.ID, Status: "open", Cause: "timeout"}
+	if err := srv.store.CreateIncident(ctx, inc); err != nil {
+		t.Fatal(err)
+	}
+	srv.store.InsertIncidentEvent(ctx, &storage.IncidentEvent{IncidentID: inc.ID, Type: "created", Message: "Incident created"})
+
+	w := probeRequest(t, srv, key, "POST", fmt.Sprintf("/api/v1/incidents/%d/events", inc.ID), map[string]any{"message": "  Rolled back deploy 412  "})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("note: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var note storage.IncidentEvent
+	json.NewDecoder(w.Body).Decode(&note)
+	if note.Type != "note" || note.Author != "admin" || note.Message != "Rolled back deploy 412" {
+		t.Fatalf("note = %+v", note)
+	}
+	if w := probeRequest(t, srv, key, "POST", fmt.Sprintf("/api/v1/incidents/%d/events", inc.ID), map[string]any{"message": " "}); w.Code != http.StatusBadRequest {
+		t.Errorf("empty note: expected 400, got %d", w.Code)
+	}
+	if w := probeRequest(t, srv, key, "POST", "/api/v1/incidents/9999/events", map[string]any{"message": "x"}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown incident: expected 404, got %d", w.Code)
+	}
+
+	w = probeRequest(t, srv, key, "PUT", fmt.Sprintf("/api/v1/incidents/%d/postmortem", inc.ID), map[string]any{"postmortem": "Bad config push."})
+	if w.Code != http.StatusOK {
+		t.Fatalf("postmortem: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := probeRequest(t, srv, key, "PUT", "/api/v1/incidents/9999/postmortem", map[string]any{"postmortem": "x"}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown incident postmortem: expected 404, got %d", w.Code)
+	}
+
+	w = checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/incidents/%d", inc.ID))
+	var resp struct {
+		Incident storage.Incident        `json:"incident"`
+		Timeline []storage.IncidentEvent `json:"timeline"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Incident.Postmortem != "Bad config push." {
+		t.Errorf("incident postmortem = %q", resp.Incident.Postmortem)
+	}
+	if len(resp.Timeline) != 2 || resp.Timeline[0].Type != "created" || resp.Timeline[1].Type != "note" {
+		t.Errorf("timeline = %+v", resp.Timeline)
+	}
+}
+
+func TestIncidentActionLinks(t *testing.T) {
+	srv, _ := testServer(t)
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+	const secret = "0123456789abcdef0123456789abcdef"
+
+	mon := &storage.Monitor{Name: "Links", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 5, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1}
+	if err := srv.store.CreateMonitor(ctx, mon); err != nil {
+		t.Fatal(err)
+	}
+	inc := &storage.Incident{MonitorID: mon.ID, Status: "open", Cause: "timeout"}
+	if err := srv.store.CreateIncident(ctx, inc); err != nil {
+		t.Fatal(err)
+	}
+
+	link := func(action string, expires time.Time) string {
+		return fmt.Sprintf("/api/v1/incidents/%d/%s?token=%s", inc.ID, action,
+			url.QueryEscape(notifier.SignActionLink(secret, "admin", inc.ID, action, expires)))
+	}
+	get := func(path string, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+	// post submits the confirmation form of a link.
+	confirm := func(path string, accept string) *httptest.ResponseRecorder {
+		u, _ := url.Parse(path)
+		req := httptest.NewRequest("POST", u.Path+"/confirm", strings.NewReader(u.RawQuery))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+	later := time.Now().Add(time.Hour)
+
+	if w := get(link(notifier.ActionAck, later), ""); w.Code != http.StatusNotFound {
+		t.Fatalf("disabled: expected 404, got %d", w.Code)
+	}
+
+	srv.cfg.Notifier.ActionKey = "admin"
+	srv.cfg.Notifier.ActionSecret = secret
+
+	if w := get(link(notifier.ActionAck, time.Now().Add(-time.Minute)), ""); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "link expired") {
+		t.Fatalf("expired: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := get(fmt.Sprintf("/api/v1/incidents/%d/ack?token=bogus", inc.ID), ""); w.Code != http.StatusForbidden {
+		t.Fatalf("bad token: expected 403, got %d", w.Code)
+	}
+	if w := get(strings.Replace(link(notifier.ActionResolve, later), "/resolve?", "/ack?", 1), ""); w.Code != http.StatusForbidden {
+		t.Fatalf("resolve token used to ack: expected 403, got %d", w.Code)
+	}
+
+	// GET only asks for confirmation.
+	w := get(link(notifier.ActionAck, later), "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Type"), "text/html") ||
+		!strings.Contains(w.Body.String(), `action="ack/confirm"`) {
+		t.Fatalf("ack page: got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	if got, _ := srv.store.GetIncident(ctx, inc.ID); got.Status != "open" {
+		t.Fatalf("GET changed the incident to %q", got.Status)
+	}
+
+	w = confirm(link(notifier.ActionAck, later), "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), fmt.Sprintf("Incident #%d acknowledged", inc.ID)) {
+		t.Fatalf("ack: got %d: %s", w.Code, w.Body.String())
+	}
+	got, _ := srv.store.GetIncident(ctx, inc.ID)
+	if got.Status != "acknowledged" || got.AcknowledgedBy != "admin" {
+		t.Fatalf("after ack: status %q by %q", got.Status, got.AcknowledgedBy)
+	}
+	if w := confirm(link(notifier.ActionAck, later), ""); w.Code != http.StatusConflict {
+		t.Fatalf("second ack: expected 409, got %d", w.Code)
+	}
+	if w := confirm(fmt.Sprintf("/api/v1/incidents/%d/resolve?token=bogus", inc.ID), ""); w.Code != http.StatusForbidden {
+		t.Fatalf("confirm with bad token: expected 403, got %d", w.Code)
+	}
+
+	w = confirm(link(notifier.ActionResolve, later), "application/json")
+	if w.Code != http.StatusOK {
+		t.Fatalf("resolve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resolved storage.Incident
+	json.NewDecoder(w.Body).Decode(&resolved)
+	if resolved.Status != "resolved" || resolved.ResolvedBy != "admin" {
+		t.Fatalf("resolve response = %+v", resolved)
+	}
+	if w := get(link(notifier.ActionAck, later), "application/json"); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "already resolved") {
+		t.Fatalf("ack after resolve: expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestMonitorLimitRejectsCreate(t *testing.T) {
+	srv, key := testServer(t)
+	srv.store.(*storage.SQLiteStore).SetMonitorLimits(storage.MonitorLimits{MaxMonitors: 1})
+	srv.cfg.Monitor.MaxMonitors = 1
+	seedMonitors(t, srv, 1)
+
+	body, _ := json.Marshal(map[string]any{
+		"name": "Over", "type": "http", "target": "https://example.com",
+	})
+	req := httptest.NewRequest("POST", "/api/v1/monitors", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/limits", nil)
+	req.Header.Set("X-API-Key", key)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		MaxMonitors int   `json:"max_monitors"`
+		Monitors    int64 `json:"monitors"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.MaxMonitors != 1 || resp.Monitors != 1 {
+		t.Errorf("unexpected limits response: %+v", resp)
+	}
+}
+
+func TestMonitorLimitRejectsBulkSetGroup(t *testing.T) {
+	srv, key := testServer(t)
+	srv.store.(*storage.SQLiteStore).SetMonitorLimits(storage.MonitorLimits{MaxMonitorsPerGroup: 1})
+	ids := seedMonitors(t, srv, 2)
+
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+	g := &storage.MonitorGroup{Name: "Small"}
+	srv.store.CreateMonitorGroup(ctx, g)
+
+	w := bulkRequest(t, srv, key, map[string]any{"action": "set_group", "ids": ids, "group_id": g.ID})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
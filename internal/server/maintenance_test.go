@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestNextMaintenance(t *testing.T) {
+	srv, adminKey := testServer(t)
+	ctx := context.Background()
+
+	daily := &storage.MaintenanceWindow{
+		Name:       "nightly",
+		MonitorIDs: []int64{},
+		StartTime:  time.Date(2026, 1, 5, 2, 0, 0, 0, time.UTC),
+		EndTime:    time.Date(2026, 1, 5, 3, 0, 0, 0, time.UTC),
+		Recurring:  "daily",
+	}
+	past := &storage.MaintenanceWindow{
+		Name:       "migration",
+		MonitorIDs: []int64{},
+		StartTime:  time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC),
+		EndTime:    time.Date(2025, 3, 1, 3, 0, 0, 0, time.UTC),
+	}
+	for _, mw := range []*storage.MaintenanceWindow{daily, past} {
+		if err := srv.store.CreateMaintenanceWindow(ctx, mw); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type next struct {
+		Next *struct {
+			Start  time.Time `json:"start"`
+			End    time.Time `json:"end"`
+			Active bool      `json:"active"`
+		} `json:"next"`
+	}
+	get := func(path string) next {
+		t.Helper()
+		w := checkRequest(t, srv, adminKey, "GET", path)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", path, w.Code, w.Body.String())
+		}
+		var resp next
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := get(fmt.Sprintf("/api/v1/maintenance/%d/next?after=2026-10-14T12:00:00Z", daily.ID))
+	if resp.Next == nil || !resp.Next.Start.Equal(time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)) || resp.Next.Active {
+		t.Errorf("unexpected next occurrence: %+v", resp.Next)
+	}
+	resp = get(fmt.Sprintf("/api/v1/maintenance/%d/next?after=2026-10-14T02:30:00Z", daily.ID))
+	if resp.Next == nil || !resp.Next.Active || !resp.Next.End.Equal(time.Date(2026, 10, 14, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the open occurrence, got %+v", resp.Next)
+	}
+	if resp := get(fmt.Sprintf("/api/v1/maintenance/%d/next", past.ID)); resp.Next != nil {
+		t.Errorf("past one-off window should have no next occurrence, got %+v", resp.Next)
+	}
+
+	if w := checkRequest(t, srv, adminKey, "GET", fmt.Sprintf("/api/v1/maintenance/%d/next?after=tomorrow", daily.ID)); w.Code != http.StatusBadRequest {
+		t.Errorf("bad after: expected 400, got %d", w.Code)
+	}
+	if w := checkRequest(t, srv, adminKey, "GET", "/api/v1/maintenance/999/next"); w.Code != http.StatusNotFound {
+		t.Errorf("missing window: expected 404, got %d", w.Code)
+	}
+}
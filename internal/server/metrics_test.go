@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestPrometheusMonitorSeries(t *testing.T) {
+	srv, key := testServer(t)
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+
+	long := strings.Repeat("n", 300)
+	enabled := &storage.Monitor{Name: long, Type: "http", Target: "https://example.com", Interval: 60, Timeout: 5, Enabled: true}
+	paused := &storage.Monitor{Name: "Paused", Type: "tcp", Target: "example.com:22", Interval: 60, Timeout: 5}
+	for _, m := range []*storage.Monitor{enabled, paused} {
+		if err := srv.store.CreateMonitor(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv.store.UpsertMonitorStatus(ctx, &storage.MonitorStatus{MonitorID: enabled.ID, Status: "down", ConsecFails: 3})
+
+	w := checkRequest(t, srv, key, "GET", "/metrics")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+
+	// Names are capped at 128 bytes.
+	labels := `monitor_id="1",name="` + long[:128] + `",type="http"`
+	for _, want := range []string{
+		"asura_monitor_up{" + labels + "} 0",
+		"asura_monitor_consecutive_failures{" + labels + "} 3",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `name="Paused"`) {
+		t.Error("disabled monitors must not get per-monitor series")
+	}
+
+	lean := false
+	srv.cfg.Server.DetailedMetrics = &lean
+	body = checkRequest(t, srv, key, "GET", "/metrics").Body.String()
+	if strings.Contains(body, "asura_monitor_up{") {
+		t.Error("expected no per-monitor series with detailed_metrics off")
+	}
+	if !strings.Contains(body, `asura_monitors_total{status="down"} 1`) {
+		t.Errorf("expected aggregate series to remain:\n%s", body)
+	}
+}
@@ -16,6 +16,11 @@ func recovery(logger *slog.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
+					if err == http.ErrAbortHandler {
+						// The handler chose to abort a response it had
+						// started; let net/http drop the connection.
+						panic(err)
+					}
 					logger.Error("panic recovered", "error", fmt.Sprintf("%v", err), "path", r.URL.Path)
 					writeError(w, http.StatusInternalServerError, "internal server error")
 				}
@@ -105,7 +110,7 @@ func cors(allowedOrigins []string) func(http.Handler) http.Handler {
 			if origin != "" && isAllowedOrigin(origin, allowedOrigins) {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key, Authorization")
 				w.Header().Set("Access-Control-Max-Age", "86400")
 				w.Header().Set("Vary", "Origin")
 			}
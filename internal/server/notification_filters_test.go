@@ -0,0 +1,74 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestMonitorNotificationFilters(t *testing.T) {
+	srv, key := testServer(t)
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+
+	ch := &storage.NotificationChannel{
+		Name: "Pager", Type: "webhook",
+		Settings: json.RawMessage(`{"url":"https://hook.example.com"}`),
+	}
+	if err := srv.store.CreateNotificationChannel(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(method, path string, body map[string]any) *httptest.ResponseRecorder {
+		t.Helper()
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("X-API-Key", key)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+	monitor := func(filters map[string]any) map[string]any {
+		return map[string]any{
+			"name": "API", "type": "http", "target": "https://example.com", "interval": 60, "timeout": 10,
+			"failure_threshold": 1, "success_threshold": 1,
+			"notification_channel_ids": []int64{ch.ID},
+			"notification_filters":     filters,
+		}
+	}
+
+	w := send("POST", "/api/v1/monitors", monitor(map[string]any{
+		fmt.Sprint(ch.ID): map[string]any{"min_severity": "critical", "tags": []string{"production"}},
+	}))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created storage.Monitor
+	json.NewDecoder(w.Body).Decode(&created)
+
+	w = checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d", created.ID))
+	var got storage.Monitor
+	json.NewDecoder(w.Body).Decode(&got)
+	if f := got.NotificationFilters[ch.ID]; f == nil || f.MinSeverity != "critical" || len(f.Tags) != 1 || f.Tags[0] != "production" {
+		t.Fatalf("filters = %v", got.NotificationFilters)
+	}
+
+	w = send("POST", "/api/v1/monitors", monitor(map[string]any{"999": map[string]any{"min_severity": "critical"}}))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("filter for an unlinked channel: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Updating without filters clears them, like the channel list itself.
+	w = send("PUT", fmt.Sprintf("/api/v1/monitors/%d", created.ID), monitor(nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if filters, _ := srv.store.GetMonitorNotificationFilters(ctx, created.ID); len(filters) != 0 {
+		t.Fatalf("expected filters cleared, got %v", filters)
+	}
+}
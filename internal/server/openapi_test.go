@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var apiRouteRe = regexp.MustCompile(`"(GET|POST|PUT|DELETE|PATCH) "\+s\.p\("(/api/v1/[^"]+)"\)`)
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	srv, _ := testServer(t)
+
+	w := checkRequest(t, srv, "", "GET", "/api/v1/openapi.json")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	raw := w.Body.String()
+	var spec struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(raw), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.0") {
+		t.Errorf("openapi = %q", spec.OpenAPI)
+	}
+
+	src, err := os.ReadFile("routes.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered := map[string]bool{}
+	for _, m := range apiRouteRe.FindAllStringSubmatch(string(src), -1) {
+		method, path := strings.ToLower(m[1]), m[2]
+		registered[method+" "+path] = true
+		if spec.Paths[path][method] == nil {
+			t.Errorf("route %s %s is missing from the OpenAPI document", m[1], path)
+		}
+	}
+	if len(registered) < 50 {
+		t.Fatalf("found only %d routes in routes.go; has the registration syntax changed?", len(registered))
+	}
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			if !registered[method+" "+path] {
+				t.Errorf("OpenAPI document describes %s %s, which is not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+
+	for _, ref := range regexp.MustCompile(`"#/components/schemas/([A-Za-z]+)"`).FindAllStringSubmatch(raw, -1) {
+		if _, ok := spec.Components.Schemas[ref[1]]; !ok {
+			t.Errorf("unresolved schema reference %s", ref[1])
+		}
+	}
+	for schema, props := range map[string][]string{
+		"Monitor":     {"id", "name", "type", "target", "settings", "assertions", "status"},
+		"Incident":    {"id", "monitor_id", "status", "started_at", "resolved_at"},
+		"CheckResult": {"id", "monitor_id", "status", "response_time"},
+	} {
+		for _, p := range props {
+			if _, ok := spec.Components.Schemas[schema].Properties[p]; !ok {
+				t.Errorf("schema %s lacks property %s", schema, p)
+			}
+		}
+	}
+	if _, ok := spec.Components.Schemas["Monitor"].Properties["SourceAddr"]; ok {
+		t.Error("fields tagged json:\"-\" should be left out")
+	}
+
+	list := spec.Paths["/api/v1/monitors"]["get"]["responses"].(map[string]any)["200"]
+	b, _ := json.Marshal(list)
+	for _, want := range []string{`"total_pages"`, `"#/components/schemas/Monitor"`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("monitor list response lacks %s: %s", want, b)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func probeRequest(t *testing.T, srv *Server, key, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("X-API-Key", key)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+func TestProbeAPI(t *testing.T) {
+	srv, key := testServer(t)
+
+	w := probeRequest(t, srv, key, "POST", "/api/v1/probes", map[string]any{
+		"name": "eu-west", "type": "agent", "url": "https://eu.example.com/api/v1/probe/check",
+		"token": "ak_secret", "enabled": true,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		storage.Probe
+		Token string `json:"token"`
+	}
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.Token != "ak_secret" {
+		t.Fatalf("expected the create response to return the token, got %q", created.Token)
+	}
+	probe := created.Probe
+
+	for _, path := range []string{"/api/v1/probes", fmt.Sprintf("/api/v1/probes/%d", probe.ID)} {
+		w = probeRequest(t, srv, key, "GET", path, nil)
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "ak_secret") || strings.Contains(w.Body.String(), `"token"`) {
+			t.Fatalf("GET %s: expected the token to be hidden, got %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	w = probeRequest(t, srv, key, "PUT", fmt.Sprintf("/api/v1/probes/%d", probe.ID), map[string]any{
+		"name": "eu-central", "type": "agent", "url": "https://eu.example.com/api/v1/probe/check", "enabled": true,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "ak_secret") {
+		t.Fatalf("update response leaks the token: %s", w.Body.String())
+	}
+	var updated storage.Probe
+	json.NewDecoder(w.Body).Decode(&updated)
+	if updated.Name != "eu-central" {
+		t.Fatalf("expected renamed probe, got %+v", updated)
+	}
+	if stored, err := srv.store.GetProbe(context.Background(), probe.ID); err != nil || stored.Token != "ak_secret" {
+		t.Fatalf("expected the stored token to be kept, got %+v (%v)", stored, err)
+	}
+
+	monitor := map[string]any{
+		"name": "Regional", "type": "http", "target": "https://example.com",
+		"interval": 60, "timeout": 5, "probe_ids": []int64{probe.ID},
+	}
+	w = probeRequest(t, srv, key, "POST", "/api/v1/monitors", monitor)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create monitor: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var mon storage.Monitor
+	json.NewDecoder(w.Body).Decode(&mon)
+
+	w = probeRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d", mon.ID), nil)
+	var got storage.Monitor
+	json.NewDecoder(w.Body).Decode(&got)
+	if len(got.ProbeIDs) != 1 || got.ProbeIDs[0] != probe.ID {
+		t.Fatalf("expected probe_ids [%d], got %v", probe.ID, got.ProbeIDs)
+	}
+
+	monitor["probe_ids"] = []int64{probe.ID + 100}
+	w = probeRequest(t, srv, key, "POST", "/api/v1/monitors", monitor)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown probe: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+	probeID := probe.ID
+	if err := srv.store.InsertProbeChecks(ctx, []*storage.CheckResult{{
+		MonitorID: mon.ID, ProbeID: &probeID, Status: "up", ResponseTime: 30,
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	to := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	w = probeRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/metrics?group_by=probe&to=%s", mon.ID, to), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("metrics: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var metrics struct {
+		Probes []storage.ProbeUptime `json:"probes"`
+	}
+	json.NewDecoder(w.Body).Decode(&metrics)
+	if len(metrics.Probes) != 1 || metrics.Probes[0].ProbeName != "eu-central" || metrics.Probes[0].Up != 1 {
+		t.Fatalf("unexpected per-probe metrics: %+v", metrics.Probes)
+	}
+
+	w = probeRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/metrics?group_by=tag", mon.ID), nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("bad group_by: expected 400, got %d", w.Code)
+	}
+
+	w = probeRequest(t, srv, key, "DELETE", fmt.Sprintf("/api/v1/probes/%d", probe.ID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	ids, err := srv.store.GetMonitorProbeIDs(ctx, mon.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 0 {
+		t.Fatalf("expected probe assignments removed, got %v", ids)
+	}
+}
@@ -129,7 +129,7 @@ func shouldSkipLog(path, basePath string) bool {
 	if strings.HasPrefix(trimmed, "/static/") {
 		return true
 	}
-	if trimmed == "/api/v1/health" {
+	if trimmed == "/api/v1/health" || trimmed == "/livez" {
 		return true
 	}
 	return false
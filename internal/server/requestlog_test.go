@@ -72,6 +72,7 @@ func TestShouldSkipLog(t *testing.T) {
 		{"/api/v1/health", "", true},
 		{"/myapp/static/htmx.min.js", "/myapp", true},
 		{"/myapp/api/v1/health", "/myapp", true},
+		{"/livez", "", true},
 		{"/", "", false},
 		{"/monitors", "", false},
 		{"/api/v1/monitors", "", false},
@@ -9,6 +9,10 @@ import (
 
 func (s *Server) registerRoutes(mux *http.ServeMux) {
 	monRead := s.api.Auth("monitors.read")
+	// monScoped also admits keys scoped to groups or tags; inScope hides
+	// monitors outside their scope.
+	monScoped := s.api.AuthScoped("monitors.read")
+	inScope := func(h http.HandlerFunc) http.Handler { return monScoped(s.api.MonitorScope(h)) }
 	monWrite := s.api.Auth("monitors.write")
 	incRead := s.api.Auth("incidents.read")
 	incWrite := s.api.Auth("incidents.write")
@@ -54,18 +58,26 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 		mux.Handle("POST "+s.p("/monitors/{id}/delete"), webPerm("monitors.write", s.web.MonitorDelete))
 		mux.Handle("POST "+s.p("/monitors/{id}/pause"), webPerm("monitors.write", s.web.MonitorPause))
 		mux.Handle("POST "+s.p("/monitors/{id}/resume"), webPerm("monitors.write", s.web.MonitorResume))
+		mux.Handle("POST "+s.p("/monitors/{id}/check"), webPerm("monitors.write", s.web.MonitorCheckNow))
+		mux.Handle("POST "+s.p("/monitors/{id}/heartbeat/pause"), webPerm("monitors.write", s.web.MonitorHeartbeatPause))
+		mux.Handle("POST "+s.p("/monitors/{id}/heartbeat/resume"), webPerm("monitors.write", s.web.MonitorHeartbeatResume))
 		mux.Handle("POST "+s.p("/monitors/{id}/clone"), webPerm("monitors.write", s.web.MonitorClone))
 		mux.Handle("POST "+s.p("/monitors/bulk"), webPerm("monitors.write", s.web.MonitorBulk))
 		mux.Handle("GET "+s.p("/monitors/{id}/chart"), webAuth(http.HandlerFunc(s.api.MonitorChart)))
+		mux.Handle("GET "+s.p("/monitors/{id}/series"), webAuth(http.HandlerFunc(s.api.MonitorSeries)))
+		mux.Handle("GET "+s.p("/monitors/{id}/sla"), webAuth(http.HandlerFunc(s.web.MonitorSLA)))
 
 		mux.Handle("GET "+s.p("/incidents"), webAuth(http.HandlerFunc(s.web.Incidents)))
 		mux.Handle("GET "+s.p("/incidents/{id}"), webAuth(http.HandlerFunc(s.web.IncidentDetail)))
 		mux.Handle("POST "+s.p("/incidents/{id}/ack"), webPerm("incidents.write", s.web.IncidentAck))
 		mux.Handle("POST "+s.p("/incidents/{id}/resolve"), webPerm("incidents.write", s.web.IncidentResolve))
 		mux.Handle("POST "+s.p("/incidents/{id}/delete"), webPerm("incidents.write", s.web.IncidentDelete))
+		mux.Handle("POST "+s.p("/incidents/{id}/postmortem"), webPerm("incidents.write", s.web.IncidentPostmortem))
+		mux.Handle("POST "+s.p("/incidents/{id}/notes"), webPerm("incidents.write", s.web.IncidentNote))
 
 		mux.Handle("GET "+s.p("/groups"), webAuth(http.HandlerFunc(s.web.Groups)))
 		mux.Handle("GET "+s.p("/groups/{id}"), webAuth(http.HandlerFunc(s.web.GroupDetail)))
+		mux.Handle("GET "+s.p("/groups/{id}/sla"), webAuth(http.HandlerFunc(s.web.GroupSLA)))
 		mux.Handle("POST "+s.p("/groups"), webPerm("monitors.write", s.web.GroupCreate))
 		mux.Handle("POST "+s.p("/groups/{id}"), webPerm("monitors.write", s.web.GroupUpdate))
 		mux.Handle("POST "+s.p("/groups/{id}/delete"), webPerm("monitors.write", s.web.GroupDelete))
@@ -81,6 +93,10 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 		mux.Handle("POST "+s.p("/notifications/{id}"), webPerm("notifications.write", s.web.NotificationUpdate))
 		mux.Handle("POST "+s.p("/notifications/{id}/delete"), webPerm("notifications.write", s.web.NotificationDelete))
 		mux.Handle("POST "+s.p("/notifications/{id}/test"), webPerm("notifications.write", s.web.NotificationTest))
+		mux.Handle("GET "+s.p("/oncall"), webAuth(http.HandlerFunc(s.web.OncallSchedules)))
+		mux.Handle("POST "+s.p("/oncall"), webPerm("notifications.write", s.web.OncallScheduleCreate))
+		mux.Handle("POST "+s.p("/oncall/{id}"), webPerm("notifications.write", s.web.OncallScheduleUpdate))
+		mux.Handle("POST "+s.p("/oncall/{id}/delete"), webPerm("notifications.write", s.web.OncallScheduleDelete))
 
 		mux.Handle("GET "+s.p("/maintenance"), webAuth(http.HandlerFunc(s.web.Maintenance)))
 		mux.Handle("POST "+s.p("/maintenance"), webPerm("maintenance.write", s.web.MaintenanceCreate))
@@ -103,6 +119,8 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 		mux.Handle("POST "+s.p("/status-pages"), webPerm("monitors.write", s.web.StatusPageCreate))
 		mux.Handle("POST "+s.p("/status-pages/{id}"), webPerm("monitors.write", s.web.StatusPageUpdate))
 		mux.Handle("POST "+s.p("/status-pages/{id}/delete"), webPerm("monitors.write", s.web.StatusPageDelete))
+		mux.Handle("POST "+s.p("/status-pages/{id}/api-token"), webPerm("monitors.write", s.web.StatusPageRegenerateToken))
+		mux.Handle("POST "+s.p("/status-pages/{id}/api-token/delete"), webPerm("monitors.write", s.web.StatusPageDeleteToken))
 
 		mux.Handle("GET "+s.p("/settings"), webAuth(http.HandlerFunc(s.web.Settings)))
 		mux.Handle("GET "+s.p("/settings/export"), webAuth(http.HandlerFunc(s.web.ExportConfig)))
@@ -111,20 +129,37 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	}
 
 	mux.HandleFunc("GET "+s.p("/api/v1/health"), s.api.Health)
+	mux.Handle("GET "+s.p("/api/v1/health/details"), metricsRead(http.HandlerFunc(s.api.HealthDetails)))
+	mux.HandleFunc("GET "+s.p("/livez"), s.api.Livez)
+	mux.HandleFunc("GET "+s.p("/api/v1/openapi.json"), s.api.OpenAPI)
 	mux.Handle("GET "+s.p("/metrics"), metricsRead(http.HandlerFunc(s.api.Metrics)))
 	mux.HandleFunc("POST "+s.p("/api/v1/heartbeat/{token}"), s.api.HeartbeatPing)
 	mux.HandleFunc("GET "+s.p("/api/v1/heartbeat/{token}"), s.api.HeartbeatPing)
+	mux.HandleFunc("POST "+s.p("/api/v1/heartbeat/{token}/pause"), s.api.HeartbeatPause)
+	mux.HandleFunc("POST "+s.p("/api/v1/heartbeat/{token}/resume"), s.api.HeartbeatResume)
 	mux.HandleFunc("GET "+s.p("/api/v1/badge/{id}/status"), s.api.BadgeStatus)
 	mux.HandleFunc("GET "+s.p("/api/v1/badge/{id}/uptime"), s.api.BadgeUptime)
 	mux.HandleFunc("GET "+s.p("/api/v1/badge/{id}/response"), s.api.BadgeResponseTime)
 	mux.HandleFunc("GET "+s.p("/api/v1/badge/{id}/cert"), s.api.BadgeCert)
+	mux.HandleFunc("GET "+s.p("/api/v1/badge/{id}/incident"), s.api.BadgeIncident)
+	mux.HandleFunc("GET "+s.p("/api/v1/incidents/{id}/ack"), s.api.AckIncidentLink)
+	mux.HandleFunc("GET "+s.p("/api/v1/incidents/{id}/resolve"), s.api.ResolveIncidentLink)
+	mux.HandleFunc("POST "+s.p("/api/v1/incidents/{id}/ack/confirm"), s.api.AckIncidentLink)
+	mux.HandleFunc("POST "+s.p("/api/v1/incidents/{id}/resolve/confirm"), s.api.ResolveIncidentLink)
 
-	mux.Handle("GET "+s.p("/api/v1/monitors"), monRead(http.HandlerFunc(s.api.ListMonitors)))
-	mux.Handle("GET "+s.p("/api/v1/monitors/{id}"), monRead(http.HandlerFunc(s.api.GetMonitor)))
-	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/checks"), monRead(http.HandlerFunc(s.api.ListChecks)))
-	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/metrics"), monRead(http.HandlerFunc(s.api.MonitorMetrics)))
-	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/changes"), monRead(http.HandlerFunc(s.api.ListChanges)))
-	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/chart"), monRead(http.HandlerFunc(s.api.MonitorChart)))
+	mux.Handle("GET "+s.p("/api/v1/monitors"), monScoped(http.HandlerFunc(s.api.ListMonitors)))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}"), inScope(s.api.GetMonitor))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/checks"), inScope(s.api.ListChecks))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/checks.csv"), inScope(s.api.ExportChecksCSV))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/checks/{checkID}"), inScope(s.api.GetCheck))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/metrics"), inScope(s.api.MonitorMetrics))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/sla"), inScope(s.api.MonitorSLA))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/apdex"), inScope(s.api.MonitorApdex))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/changes"), inScope(s.api.ListChanges))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/transitions"), inScope(s.api.ListTransitions))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/chart"), inScope(s.api.MonitorChart))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/series"), inScope(s.api.MonitorSeries))
+	mux.Handle("GET "+s.p("/api/v1/monitors/{id}/export"), inScope(s.api.ExportMonitor))
 
 	mux.Handle("GET "+s.p("/api/v1/incidents"), incRead(http.HandlerFunc(s.api.ListIncidents)))
 	mux.Handle("GET "+s.p("/api/v1/incidents/{id}"), incRead(http.HandlerFunc(s.api.GetIncident)))
@@ -132,12 +167,17 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.Handle("GET "+s.p("/api/v1/notifications"), notifRead(http.HandlerFunc(s.api.ListNotifications)))
 	mux.Handle("GET "+s.p("/api/v1/notifications/history"), notifRead(http.HandlerFunc(s.api.ListNotificationHistory)))
 	mux.Handle("GET "+s.p("/api/v1/maintenance"), maintRead(http.HandlerFunc(s.api.ListMaintenance)))
-	mux.Handle("GET "+s.p("/api/v1/groups"), monRead(http.HandlerFunc(s.api.ListGroups)))
+	mux.Handle("GET "+s.p("/api/v1/maintenance/{id}/next"), maintRead(http.HandlerFunc(s.api.NextMaintenance)))
+	mux.Handle("GET "+s.p("/api/v1/groups"), monScoped(http.HandlerFunc(s.api.ListGroups)))
+	mux.Handle("GET "+s.p("/api/v1/groups/{id}/status"), monRead(http.HandlerFunc(s.api.GroupStatus)))
+	mux.Handle("GET "+s.p("/api/v1/groups/{id}/uptime"), monRead(http.HandlerFunc(s.api.GroupUptime)))
+	mux.Handle("GET "+s.p("/api/v1/groups/{id}/sla"), monRead(http.HandlerFunc(s.api.GroupSLA)))
+	mux.Handle("GET "+s.p("/api/v1/limits"), monRead(http.HandlerFunc(s.api.MonitorLimits)))
 	mux.Handle("POST "+s.p("/api/v1/groups"), monWrite(http.HandlerFunc(s.api.CreateGroup)))
 	mux.Handle("PUT "+s.p("/api/v1/groups/{id}"), monWrite(http.HandlerFunc(s.api.UpdateGroup)))
 	mux.Handle("DELETE "+s.p("/api/v1/groups/{id}"), monWrite(http.HandlerFunc(s.api.DeleteGroup)))
 	mux.Handle("GET "+s.p("/api/v1/overview"), monRead(http.HandlerFunc(s.api.Overview)))
-	mux.Handle("GET "+s.p("/api/v1/tags"), monRead(http.HandlerFunc(s.api.ListTags)))
+	mux.Handle("GET "+s.p("/api/v1/tags"), monScoped(http.HandlerFunc(s.api.ListTags)))
 	mux.Handle("POST "+s.p("/api/v1/tags"), monWrite(http.HandlerFunc(s.api.CreateTag)))
 	mux.Handle("PUT "+s.p("/api/v1/tags/{id}"), monWrite(http.HandlerFunc(s.api.UpdateTag)))
 	mux.Handle("DELETE "+s.p("/api/v1/tags/{id}"), monWrite(http.HandlerFunc(s.api.DeleteTag)))
@@ -148,17 +188,36 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.Handle("POST "+s.p("/api/v1/monitors/{id}/pause"), monWrite(http.HandlerFunc(s.api.PauseMonitor)))
 	mux.Handle("POST "+s.p("/api/v1/monitors/{id}/resume"), monWrite(http.HandlerFunc(s.api.ResumeMonitor)))
 	mux.Handle("POST "+s.p("/api/v1/monitors/{id}/clone"), monWrite(http.HandlerFunc(s.api.CloneMonitor)))
+	mux.Handle("POST "+s.p("/api/v1/monitors/template"), monWrite(http.HandlerFunc(s.api.CreateMonitorsFromTemplate)))
+	mux.Handle("POST "+s.p("/api/v1/monitors/{id}/checks/{checkID}/rerun"), monWrite(http.HandlerFunc(s.api.RerunCheck)))
 	mux.Handle("POST "+s.p("/api/v1/monitors/bulk"), monWrite(http.HandlerFunc(s.api.BulkMonitors)))
+	mux.Handle("POST "+s.p("/api/v1/monitors/{id}/check"), monWrite(http.HandlerFunc(s.api.CheckMonitorNow)))
+	mux.Handle("POST "+s.p("/api/v1/monitors/check"), monWrite(http.HandlerFunc(s.api.CheckMonitorsNow)))
 
 	mux.Handle("POST "+s.p("/api/v1/incidents/{id}/ack"), incWrite(http.HandlerFunc(s.api.AckIncident)))
 	mux.Handle("POST "+s.p("/api/v1/incidents/{id}/resolve"), incWrite(http.HandlerFunc(s.api.ResolveIncident)))
 	mux.Handle("DELETE "+s.p("/api/v1/incidents/{id}"), incWrite(http.HandlerFunc(s.api.DeleteIncident)))
+	mux.Handle("PUT "+s.p("/api/v1/incidents/{id}/postmortem"), incWrite(http.HandlerFunc(s.api.SetIncidentPostmortem)))
+	mux.Handle("POST "+s.p("/api/v1/incidents/{id}/events"), incWrite(http.HandlerFunc(s.api.AddIncidentNote)))
 
 	mux.Handle("POST "+s.p("/api/v1/notifications"), notifWrite(http.HandlerFunc(s.api.CreateNotification)))
 	mux.Handle("PUT "+s.p("/api/v1/notifications/{id}"), notifWrite(http.HandlerFunc(s.api.UpdateNotification)))
 	mux.Handle("DELETE "+s.p("/api/v1/notifications/{id}"), notifWrite(http.HandlerFunc(s.api.DeleteNotification)))
 	mux.Handle("POST "+s.p("/api/v1/notifications/{id}/test"), notifWrite(http.HandlerFunc(s.api.TestNotification)))
 
+	mux.Handle("GET "+s.p("/api/v1/escalation-policies"), notifRead(http.HandlerFunc(s.api.ListEscalationPolicies)))
+	mux.Handle("GET "+s.p("/api/v1/escalation-policies/{id}"), notifRead(http.HandlerFunc(s.api.GetEscalationPolicy)))
+	mux.Handle("POST "+s.p("/api/v1/escalation-policies"), notifWrite(http.HandlerFunc(s.api.CreateEscalationPolicy)))
+	mux.Handle("PUT "+s.p("/api/v1/escalation-policies/{id}"), notifWrite(http.HandlerFunc(s.api.UpdateEscalationPolicy)))
+	mux.Handle("DELETE "+s.p("/api/v1/escalation-policies/{id}"), notifWrite(http.HandlerFunc(s.api.DeleteEscalationPolicy)))
+
+	mux.Handle("GET "+s.p("/api/v1/oncall-schedules"), notifRead(http.HandlerFunc(s.api.ListOncallSchedules)))
+	mux.Handle("GET "+s.p("/api/v1/oncall-schedules/{id}"), notifRead(http.HandlerFunc(s.api.GetOncallSchedule)))
+	mux.Handle("GET "+s.p("/api/v1/oncall-schedules/{id}/responder"), notifRead(http.HandlerFunc(s.api.OncallResponder)))
+	mux.Handle("POST "+s.p("/api/v1/oncall-schedules"), notifWrite(http.HandlerFunc(s.api.CreateOncallSchedule)))
+	mux.Handle("PUT "+s.p("/api/v1/oncall-schedules/{id}"), notifWrite(http.HandlerFunc(s.api.UpdateOncallSchedule)))
+	mux.Handle("DELETE "+s.p("/api/v1/oncall-schedules/{id}"), notifWrite(http.HandlerFunc(s.api.DeleteOncallSchedule)))
+
 	mux.Handle("POST "+s.p("/api/v1/maintenance"), maintWrite(http.HandlerFunc(s.api.CreateMaintenance)))
 	mux.Handle("PUT "+s.p("/api/v1/maintenance/{id}"), maintWrite(http.HandlerFunc(s.api.UpdateMaintenance)))
 	mux.Handle("DELETE "+s.p("/api/v1/maintenance/{id}"), maintWrite(http.HandlerFunc(s.api.DeleteMaintenance)))
@@ -169,11 +228,21 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.Handle("PUT "+s.p("/api/v1/proxies/{id}"), monWrite(http.HandlerFunc(s.api.UpdateProxy)))
 	mux.Handle("DELETE "+s.p("/api/v1/proxies/{id}"), monWrite(http.HandlerFunc(s.api.DeleteProxy)))
 
+	mux.Handle("GET "+s.p("/api/v1/probes"), monRead(http.HandlerFunc(s.api.ListProbes)))
+	mux.Handle("GET "+s.p("/api/v1/probes/{id}"), monRead(http.HandlerFunc(s.api.GetProbe)))
+	mux.Handle("POST "+s.p("/api/v1/probes"), monWrite(http.HandlerFunc(s.api.CreateProbe)))
+	mux.Handle("PUT "+s.p("/api/v1/probes/{id}"), monWrite(http.HandlerFunc(s.api.UpdateProbe)))
+	mux.Handle("DELETE "+s.p("/api/v1/probes/{id}"), monWrite(http.HandlerFunc(s.api.DeleteProbe)))
+	mux.Handle("POST "+s.p("/api/v1/probe/check"), monWrite(http.HandlerFunc(s.api.ProbeCheck)))
+
 	mux.Handle("GET "+s.p("/api/v1/status-pages"), monRead(http.HandlerFunc(s.api.ListStatusPages)))
 	mux.Handle("GET "+s.p("/api/v1/status-pages/{id}"), monRead(http.HandlerFunc(s.api.GetStatusPage)))
 	mux.Handle("POST "+s.p("/api/v1/status-pages"), monWrite(http.HandlerFunc(s.api.CreateStatusPage)))
 	mux.Handle("PUT "+s.p("/api/v1/status-pages/{id}"), monWrite(http.HandlerFunc(s.api.UpdateStatusPage)))
 	mux.Handle("DELETE "+s.p("/api/v1/status-pages/{id}"), monWrite(http.HandlerFunc(s.api.DeleteStatusPage)))
+	mux.Handle("GET "+s.p("/api/v1/status-pages/{id}/subscribers"), monRead(http.HandlerFunc(s.api.ListStatusPageSubscribers)))
+	mux.Handle("POST "+s.p("/api/v1/status-pages/{id}/api-token"), monWrite(http.HandlerFunc(s.api.RegenerateStatusPageToken)))
+	mux.Handle("DELETE "+s.p("/api/v1/status-pages/{id}/api-token"), monWrite(http.HandlerFunc(s.api.DeleteStatusPageToken)))
 	mux.HandleFunc("GET "+s.p("/api/v1/status-pages/{id}/public"), s.api.PublicStatusPage)
 
 	mux.Handle("GET "+s.p("/api/v1/request-logs"), metricsRead(http.HandlerFunc(s.api.ListRequestLogs)))
@@ -183,6 +252,7 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 
 	mux.Handle("GET "+s.p("/api/v1/db/size"), metricsRead(http.HandlerFunc(s.api.DBSize)))
 	mux.Handle("POST "+s.p("/api/v1/db/vacuum"), monWrite(http.HandlerFunc(s.api.DBVacuum)))
+	mux.Handle("GET "+s.p("/api/v1/debug/scheduler"), metricsRead(http.HandlerFunc(s.api.DebugScheduler)))
 
 	mux.Handle("GET "+s.p("/api/v1/export"), monRead(http.HandlerFunc(s.api.Export)))
 	mux.Handle("POST "+s.p("/api/v1/import"), monWrite(http.HandlerFunc(s.api.Import)))
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/y0f/asura/internal/config"
+	"github.com/y0f/asura/internal/storage"
+)
+
+// addScopedKey registers a monitors.read key limited to the given groups and
+// tags and returns the raw key.
+func addScopedKey(srv *Server, name string, groupIDs, tagIDs []int64) string {
+	key := "test-" + name + "-key"
+	srv.cfg.Auth.APIKeys = append(srv.cfg.Auth.APIKeys, config.APIKeyConfig{
+		Name: name, Hash: config.HashAPIKey(key), Permissions: []string{"monitors.read"},
+		GroupIDs: groupIDs, TagIDs: tagIDs,
+	})
+	return key
+}
+
+func TestScopedAPIKey(t *testing.T) {
+	srv, _ := testServer(t)
+	ctx := context.Background()
+
+	g := &storage.MonitorGroup{Name: "Payments"}
+	if err := srv.store.CreateMonitorGroup(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	tag := &storage.Tag{Name: "team-a", Color: "#000000"}
+	if err := srv.store.CreateTag(ctx, tag); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.store.CreateMonitorGroup(ctx, &storage.MonitorGroup{Name: "Billing"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.store.CreateTag(ctx, &storage.Tag{Name: "team-b", Color: "#000000"}); err != nil {
+		t.Fatal(err)
+	}
+	ids := seedMonitors(t, srv, 3)
+	grouped, tagged, other := ids[0], ids[1], ids[2]
+	m, _ := srv.store.GetMonitor(ctx, grouped)
+	m.GroupID = &g.ID
+	if err := srv.store.UpdateMonitor(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.store.SetMonitorTags(ctx, tagged, []storage.MonitorTag{{TagID: tag.ID}}); err != nil {
+		t.Fatal(err)
+	}
+
+	groupKey := addScopedKey(srv, "payments-team", []int64{g.ID}, nil)
+	tagKey := addScopedKey(srv, "team-a", nil, []int64{tag.ID})
+
+	listIDs := func(key string) []int64 {
+		t.Helper()
+		w := checkRequest(t, srv, key, "GET", "/api/v1/monitors")
+		if w.Code != http.StatusOK {
+			t.Fatalf("list: status %d", w.Code)
+		}
+		var resp struct {
+			Data  []storage.Monitor `json:"data"`
+			Total int64             `json:"total"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		var got []int64
+		for _, m := range resp.Data {
+			got = append(got, m.ID)
+		}
+		if resp.Total != int64(len(got)) {
+			t.Errorf("total %d, listed %d", resp.Total, len(got))
+		}
+		return got
+	}
+	if got := listIDs(groupKey); len(got) != 1 || got[0] != grouped {
+		t.Errorf("group key lists %v, want [%d]", got, grouped)
+	}
+	if got := listIDs(tagKey); len(got) != 1 || got[0] != tagged {
+		t.Errorf("tag key lists %v, want [%d]", got, tagged)
+	}
+
+	for _, path := range []string{"", "/checks", "/checks.csv", "/metrics", "/sla", "/changes", "/transitions", "/chart", "/series", "/export"} {
+		if w := checkRequest(t, srv, groupKey, "GET", fmt.Sprintf("/api/v1/monitors/%d%s", other, path)); w.Code != http.StatusNotFound {
+			t.Errorf("out-of-scope %q: status %d, want 404", path, w.Code)
+		}
+	}
+	if w := checkRequest(t, srv, groupKey, "GET", fmt.Sprintf("/api/v1/monitors/%d", grouped)); w.Code != http.StatusOK {
+		t.Errorf("in-scope get: status %d", w.Code)
+	}
+	if w := checkRequest(t, srv, tagKey, "GET", fmt.Sprintf("/api/v1/monitors/%d", grouped)); w.Code != http.StatusNotFound {
+		t.Errorf("tag key get grouped monitor: status %d, want 404", w.Code)
+	}
+
+	for _, path := range []string{"/api/v1/overview", "/api/v1/export", "/api/v1/status-pages", "/api/v1/groups/1/status"} {
+		if w := checkRequest(t, srv, groupKey, "GET", path); w.Code != http.StatusForbidden {
+			t.Errorf("%s: status %d, want 403", path, w.Code)
+		}
+	}
+	listed := func(key, path string) []int64 {
+		t.Helper()
+		w := checkRequest(t, srv, key, "GET", path)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, w.Code)
+		}
+		var resp struct {
+			Data []struct {
+				ID int64 `json:"id"`
+			} `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		var got []int64
+		for _, d := range resp.Data {
+			got = append(got, d.ID)
+		}
+		return got
+	}
+	if got := listed(groupKey, "/api/v1/groups"); len(got) != 1 || got[0] != g.ID {
+		t.Errorf("group key groups %v, want [%d]", got, g.ID)
+	}
+	if got := listed(groupKey, "/api/v1/tags"); len(got) != 0 {
+		t.Errorf("group key tags %v, want none", got)
+	}
+	if got := listed(tagKey, "/api/v1/groups"); len(got) != 0 {
+		t.Errorf("tag key groups %v, want none", got)
+	}
+	if got := listed(tagKey, "/api/v1/tags"); len(got) != 1 || got[0] != tag.ID {
+		t.Errorf("tag key tags %v, want [%d]", got, tag.ID)
+	}
+}
+
+func TestScopedAPIKeyAudit(t *testing.T) {
+	srv, _ := testServer(t)
+	ctx := context.Background()
+	tag := &storage.Tag{Name: "team-a", Color: "#000000"}
+	if err := srv.store.CreateTag(ctx, tag); err != nil {
+		t.Fatal(err)
+	}
+	id := seedMonitors(t, srv, 1)[0]
+	if err := srv.store.SetMonitorTags(ctx, id, []storage.MonitorTag{{TagID: tag.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	key := addScopedKey(srv, "team-a", nil, []int64{tag.ID})
+
+	if w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/export", id)); w.Code != http.StatusOK {
+		t.Fatalf("export: status %d", w.Code)
+	}
+	res, err := srv.store.ListAuditLog(ctx, storage.AuditLogFilter{Action: "export"}, storage.Pagination{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := res.Data.([]*storage.AuditEntry)
+	if len(entries) != 1 || entries[0].APIKeyName != "team-a" || entries[0].EntityID != id {
+		t.Errorf("audit = %+v", entries)
+	}
+}
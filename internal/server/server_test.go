@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/y0f/asura/internal/config"
@@ -49,7 +50,7 @@ func testServer(t *testing.T) (*Server, string) {
 }
 
 func TestHealthEndpoint(t *testing.T) {
-	srv, _ := testServer(t)
+	srv, key := testServer(t)
 
 	req := httptest.NewRequest("GET", "/api/v1/health", nil)
 	w := httptest.NewRecorder()
@@ -58,11 +59,61 @@ func TestHealthEndpoint(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
+	var public map[string]string
+	json.NewDecoder(w.Body).Decode(&public)
+	if len(public) != 2 || public["status"] != "ok" || public["uptime"] == "" {
+		t.Fatalf("public health should report the status and uptime only, got %v", public)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/health/details", nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("details without a key: expected 401, got %d", w.Code)
+	}
 
-	var resp map[string]any
+	w = checkRequest(t, srv, key, "GET", "/api/v1/health/details")
+	if w.Code != http.StatusOK {
+		t.Fatalf("details: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Status string `json:"status"`
+		Checks struct {
+			Database struct {
+				Status string `json:"status"`
+			} `json:"database"`
+		} `json:"checks"`
+	}
 	json.NewDecoder(w.Body).Decode(&resp)
-	if resp["status"] != "ok" {
-		t.Fatalf("expected status ok, got %v", resp["status"])
+	if resp.Status != "ok" || resp.Checks.Database.Status != "ok" {
+		t.Fatalf("expected status ok with a reachable database, got %+v", resp)
+	}
+}
+
+func TestHealthEndpointDatabaseDown(t *testing.T) {
+	srv, key := testServer(t)
+	srv.store.Close()
+
+	req := httptest.NewRequest("GET", "/api/v1/health", nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = checkRequest(t, srv, key, "GET", "/api/v1/health/details")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("details: expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"database":{"status":"unavailable"`) {
+		t.Fatalf("expected the database reported unavailable, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/livez", nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("livez: expected 200 without a database, got %d", w.Code)
 	}
 }
 
@@ -104,6 +155,38 @@ func TestListMonitorsEmpty(t *testing.T) {
 	}
 }
 
+func TestListMonitorsOwnerFilter(t *testing.T) {
+	srv, adminKey := testServer(t)
+	ctx := httptest.NewRequest("GET", "/", nil).Context()
+
+	for _, m := range []*storage.Monitor{
+		{Name: "Mine", Type: "http", Target: "https://a.example.com", Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1, Owner: "admin"},
+		{Name: "Payments", Type: "http", Target: "https://b.example.com", Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1, Owner: "payments"},
+		{Name: "Unowned", Type: "http", Target: "https://c.example.com", Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 1, SuccessThreshold: 1},
+	} {
+		if err := srv.store.CreateMonitor(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for owner, want := range map[string]string{"me": "Mine", "payments": "Payments"} {
+		req := httptest.NewRequest("GET", "/api/v1/monitors?owner="+owner, nil)
+		req.Header.Set("X-API-Key", adminKey)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("owner=%s: expected 200, got %d: %s", owner, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Data []storage.Monitor `json:"data"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		if len(resp.Data) != 1 || resp.Data[0].Name != want {
+			t.Errorf("owner=%s: got %+v, want only %s", owner, resp.Data, want)
+		}
+	}
+}
+
 func TestMonitorCRUD(t *testing.T) {
 	srv, adminKey := testServer(t)
 
@@ -150,6 +233,68 @@ func TestMonitorCRUD(t *testing.T) {
 	}
 }
 
+func TestCreateMonitorAppliesAutoTags(t *testing.T) {
+	srv, adminKey := testServer(t)
+	srv.cfg.Monitor.AutoTagRules = []config.AutoTagRule{
+		{Tag: "prod", TargetContains: ".prod."},
+		{Tag: "web", Type: "http", Value: "public"},
+		{Tag: "db", Type: "tcp"},
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"name":   "Prod API",
+		"type":   "http",
+		"target": "https://api.prod.example.com",
+	})
+	req := httptest.NewRequest("POST", "/api/v1/monitors", bytes.NewReader(body))
+	req.Header.Set("X-API-Key", adminKey)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var created storage.Monitor
+	json.NewDecoder(w.Body).Decode(&created)
+	if len(created.AutoTags) != 2 || created.AutoTags[0] != "prod" || created.AutoTags[1] != "web" {
+		t.Fatalf("expected auto tags [prod web], got %v", created.AutoTags)
+	}
+
+	tags, err := srv.store.GetMonitorTags(req.Context(), created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]string)
+	for _, mt := range tags {
+		values[mt.Name] = mt.Value
+	}
+	if len(values) != 2 || values["web"] != "public" {
+		t.Errorf("unexpected monitor tags: %+v", tags)
+	}
+}
+
+func TestDebugSchedulerRequiresAdmin(t *testing.T) {
+	srv, adminKey := testServer(t)
+
+	req := httptest.NewRequest("GET", "/api/v1/debug/scheduler", nil)
+	req.Header.Set("X-API-Key", "test-read-key")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("reader: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The test server runs without a pipeline.
+	req = httptest.NewRequest("GET", "/api/v1/debug/scheduler", nil)
+	req.Header.Set("X-API-Key", adminKey)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("admin: expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
 func TestSecureHeaders(t *testing.T) {
 	srv, _ := testServer(t)
 
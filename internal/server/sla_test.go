@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestMonitorSLA(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 1)
+	seedCheck(t, srv, ids[0])
+
+	now := time.Now().UTC()
+	q := url.Values{
+		"from": {now.Add(-time.Hour).Format(time.RFC3339)},
+		"to":   {now.Add(time.Hour).Format(time.RFC3339)},
+	}
+	w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/sla?%s", ids[0], q.Encode()))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rep storage.SLAReport
+	json.NewDecoder(w.Body).Decode(&rep)
+	if rep.MonitorID != ids[0] || rep.NoData || rep.TotalChecks != 1 || rep.FailedChecks != 1 {
+		t.Errorf("unexpected report: %+v", rep)
+	}
+
+	// The default range, last month, predates the monitor.
+	w = checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/sla", ids[0]))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	rep = storage.SLAReport{}
+	json.NewDecoder(w.Body).Decode(&rep)
+	if !rep.NoData || rep.UptimePct != nil {
+		t.Errorf("expected no data for last month, got %+v", rep)
+	}
+
+	for path, want := range map[string]int{
+		fmt.Sprintf("/api/v1/monitors/%d/sla?from=yesterday", ids[0]):                                    http.StatusBadRequest,
+		fmt.Sprintf("/api/v1/monitors/%d/sla?from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z", ids[0]): http.StatusBadRequest,
+		"/api/v1/monitors/9999/sla": http.StatusNotFound,
+	} {
+		if w := checkRequest(t, srv, key, "GET", path); w.Code != want {
+			t.Errorf("%s: expected %d, got %d: %s", path, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestMonitorApdex(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 1)
+	seedCheck(t, srv, ids[0])
+
+	now := time.Now().UTC()
+	q := url.Values{
+		"from": {now.Add(-time.Hour).Format(time.RFC3339)},
+		"to":   {now.Add(time.Hour).Format(time.RFC3339)},
+	}
+	w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/apdex?%s", ids[0], q.Encode()))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rep storage.ApdexReport
+	json.NewDecoder(w.Body).Decode(&rep)
+	if rep.ThresholdMs != storage.DefaultApdexThresholdMs || rep.Score != nil || rep.Excluded != 1 {
+		t.Errorf("expected the failed check excluded, got %+v", rep)
+	}
+
+	q.Set("threshold", "200")
+	q.Set("count_failed", "true")
+	w = checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/apdex?%s", ids[0], q.Encode()))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	rep = storage.ApdexReport{}
+	json.NewDecoder(w.Body).Decode(&rep)
+	if rep.ThresholdMs != 200 || rep.Score == nil || *rep.Score != 0 || rep.Frustrated != 1 {
+		t.Errorf("expected the failed check frustrated, got %+v", rep)
+	}
+
+	for path, want := range map[string]int{
+		fmt.Sprintf("/api/v1/monitors/%d/apdex?threshold=0", ids[0]):        http.StatusBadRequest,
+		fmt.Sprintf("/api/v1/monitors/%d/apdex?count_failed=maybe", ids[0]): http.StatusBadRequest,
+		fmt.Sprintf("/api/v1/monitors/%d/apdex?from=yesterday", ids[0]):     http.StatusBadRequest,
+		"/api/v1/monitors/9999/apdex":                                       http.StatusNotFound,
+	} {
+		if w := checkRequest(t, srv, key, "GET", path); w.Code != want {
+			t.Errorf("%s: expected %d, got %d: %s", path, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestMonitorSeries(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 1)
+	seedCheck(t, srv, ids[0])
+
+	now := time.Now().UTC()
+	q := url.Values{
+		"from":   {now.Add(-time.Hour).Format(time.RFC3339)},
+		"to":     {now.Add(time.Hour).Format(time.RFC3339)},
+		"points": {"120"},
+	}
+	w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/series?%s", ids[0], q.Encode()))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		BucketSeconds int64                     `json:"bucket_seconds"`
+		Points        []storage.TimeSeriesPoint `json:"points"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.BucketSeconds != 60 || len(resp.Points) != 120 {
+		t.Fatalf("expected 120 one-minute buckets, got %d of %ds", len(resp.Points), resp.BucketSeconds)
+	}
+	var checks int64
+	for _, p := range resp.Points {
+		checks += p.Checks
+		if p.Checks == 0 && p.Status != "" {
+			t.Errorf("empty bucket at %d has status %q", p.Timestamp, p.Status)
+		}
+		if p.Checks > 0 && p.Status != "down" {
+			t.Errorf("bucket at %d: status %q, want down", p.Timestamp, p.Status)
+		}
+	}
+	if checks != 1 {
+		t.Errorf("series counts %d checks, want 1", checks)
+	}
+
+	for path, want := range map[string]int{
+		fmt.Sprintf("/api/v1/monitors/%d/series?points=0", ids[0]):                                          http.StatusBadRequest,
+		fmt.Sprintf("/api/v1/monitors/%d/series?points=5000", ids[0]):                                       http.StatusBadRequest,
+		fmt.Sprintf("/api/v1/monitors/%d/series?from=yesterday", ids[0]):                                    http.StatusBadRequest,
+		fmt.Sprintf("/api/v1/monitors/%d/series?from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z", ids[0]): http.StatusBadRequest,
+		"/api/v1/monitors/9999/series":                                                                      http.StatusNotFound,
+	} {
+		if w := checkRequest(t, srv, key, "GET", path); w.Code != want {
+			t.Errorf("%s: expected %d, got %d: %s", path, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestMonitorTransitionsEndpoint(t *testing.T) {
+	srv, key := testServer(t)
+	ids := seedMonitors(t, srv, 1)
+	ctx := context.Background()
+
+	for _, to := range []string{"down", "up", "down"} {
+		from := map[string]string{"down": "up", "up": "down"}[to]
+		tr := &storage.MonitorTransition{MonitorID: ids[0], FromStatus: from, ToStatus: to, Cause: "check " + to}
+		if err := srv.store.InsertMonitorTransition(ctx, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := checkRequest(t, srv, key, "GET", fmt.Sprintf("/api/v1/monitors/%d/transitions?limit=2", ids[0]))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data []storage.MonitorTransition `json:"data"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Data) != 2 || resp.Data[0].ToStatus != "down" || resp.Data[1].ToStatus != "up" {
+		t.Fatalf("expected the two newest transitions, got %+v", resp.Data)
+	}
+
+	for path, want := range map[string]int{
+		fmt.Sprintf("/api/v1/monitors/%d/transitions?limit=0", ids[0]):   http.StatusBadRequest,
+		fmt.Sprintf("/api/v1/monitors/%d/transitions?limit=abc", ids[0]): http.StatusBadRequest,
+		"/api/v1/monitors/9999/transitions":                              http.StatusNotFound,
+	} {
+		if w := checkRequest(t, srv, key, "GET", path); w.Code != want {
+			t.Errorf("%s: expected %d, got %d: %s", path, want, w.Code, w.Body.String())
+		}
+	}
+}
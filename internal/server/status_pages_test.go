@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/y0f/asura/internal/storage"
@@ -113,3 +115,223 @@ func TestPublicStatusPageEnabled(t *testing.T) {
 		t.Errorf("expected 1 monitor, got %d", len(monitors))
 	}
 }
+
+func TestPublicStatusPageComponents(t *testing.T) {
+	srv, adminKey := testServer(t)
+	ctx := context.Background()
+
+	var ids []int64
+	for _, name := range []string{"api-eu", "api-us", "website"} {
+		mon := &storage.Monitor{
+			Name: name, Type: "http", Target: "https://example.com",
+			Interval: 30, Timeout: 5, FailureThreshold: 3, SuccessThreshold: 1, Enabled: true,
+		}
+		if err := srv.store.CreateMonitor(ctx, mon); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, mon.ID)
+	}
+	srv.store.UpsertMonitorStatus(ctx, &storage.MonitorStatus{MonitorID: ids[0], Status: "up"})
+	srv.store.UpsertMonitorStatus(ctx, &storage.MonitorStatus{MonitorID: ids[1], Status: "down"})
+	srv.store.UpsertMonitorStatus(ctx, &storage.MonitorStatus{MonitorID: ids[2], Status: "up"})
+
+	body := `{"title":"Public","slug":"public","enabled":true,"api_enabled":true,
+		"monitors":[{"monitor_id":1},{"monitor_id":2},{"monitor_id":3}],
+		"components":[{"name":"API","monitors":[{"monitor_id":1,"weight":2},{"monitor_id":2}]}]}`
+	req := httptest.NewRequest("POST", "/api/v1/status-pages", strings.NewReader(body))
+	req.Header.Set("X-API-Key", adminKey)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/status-pages/1/public", nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Components []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		} `json:"components"`
+		Monitors []struct {
+			Name string `json:"name"`
+		} `json:"monitors"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Components) != 1 || resp.Components[0].Name != "API" || resp.Components[0].Status != "down" {
+		t.Fatalf("unexpected components: %+v", resp.Components)
+	}
+	if len(resp.Monitors) != 1 || resp.Monitors[0].Name != "website" {
+		t.Fatalf("expected only the ungrouped monitor, got %+v", resp.Monitors)
+	}
+}
+
+func TestCreateStatusPageInvalidComponent(t *testing.T) {
+	srv, adminKey := testServer(t)
+
+	body := `{"title":"Public","slug":"public","components":[{"name":""}]}`
+	req := httptest.NewRequest("POST", "/api/v1/status-pages", strings.NewReader(body))
+	req.Header.Set("X-API-Key", adminKey)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCreateStatusPageSubscriberChannelMustBeEmail(t *testing.T) {
+	srv, adminKey := testServer(t)
+
+	ch := &storage.NotificationChannel{Name: "hook", Type: "webhook", Enabled: true, Settings: []byte(`{"url":"https://example.com"}`)}
+	if err := srv.store.CreateNotificationChannel(context.Background(), ch); err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"title":"Public","slug":"public","subscriber_channel_id":` + strconv.FormatInt(ch.ID, 10) + `}`
+	req := httptest.NewRequest("POST", "/api/v1/status-pages", strings.NewReader(body))
+	req.Header.Set("X-API-Key", adminKey)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "email notification channel") {
+		t.Fatalf("expected 400 for webhook subscriber channel, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestStatusPageSubscribeFlow(t *testing.T) {
+	srv, _ := testServer(t)
+	ctx := context.Background()
+
+	ch := &storage.NotificationChannel{Name: "mail", Type: "email", Enabled: true,
+		Settings: []byte(`{"host":"127.0.0.1","port":1,"from":"status@example.com","tls_mode":"none"}`)}
+	if err := srv.store.CreateNotificationChannel(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	sp := &storage.StatusPage{Title: "Acme", Slug: "acme", Enabled: true, ShowIncidents: true, SubscriberChannelID: &ch.ID}
+	if err := srv.store.CreateStatusPage(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	srv.refreshStatusSlugs()
+
+	post := func(path, form string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	page := get("/acme")
+	if page.Code != http.StatusOK || !strings.Contains(page.Body.String(), `action="/acme/subscribe"`) {
+		t.Fatalf("expected subscribe form on the page, got %d", page.Code)
+	}
+
+	if w := post("/acme/subscribe", "email=not-an-email"); w.Header().Get("Location") != "/acme?subscribe=invalid" {
+		t.Fatalf("invalid email: got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := post("/acme/subscribe", "email=Ops@Example.com"); w.Header().Get("Location") != "/acme?subscribe=sent" {
+		t.Fatalf("subscribe: got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	sub, err := srv.store.GetStatusPageSubscriberByEmail(ctx, sp.ID, "ops@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Confirmed {
+		t.Fatal("subscriber should start unconfirmed")
+	}
+
+	if w := get("/acme/subscribe/confirm?token=wrong"); !strings.Contains(w.Body.String(), "invalid") {
+		t.Errorf("wrong token should not confirm: %s", w.Body.String())
+	}
+	if w := get("/acme/subscribe/confirm?token=" + sub.Token); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "confirmed") {
+		t.Fatalf("confirm: got %d: %s", w.Code, w.Body.String())
+	}
+	if sub, _ = srv.store.GetStatusPageSubscriberByToken(ctx, sub.Token); !sub.Confirmed {
+		t.Fatal("expected subscriber confirmed")
+	}
+
+	if w := get("/acme/unsubscribe?token=" + sub.Token); !strings.Contains(w.Body.String(), `name="token"`) {
+		t.Fatalf("unsubscribe GET should ask for confirmation: %s", w.Body.String())
+	}
+	if _, err := srv.store.GetStatusPageSubscriberByToken(ctx, sub.Token); err != nil {
+		t.Fatal("unsubscribe GET should not remove the subscriber")
+	}
+	if w := post("/acme/unsubscribe?token="+sub.Token, "List-Unsubscribe=One-Click"); w.Code != http.StatusOK {
+		t.Fatalf("one-click unsubscribe: got %d", w.Code)
+	}
+	if _, err := srv.store.GetStatusPageSubscriberByToken(ctx, sub.Token); err == nil {
+		t.Fatal("expected subscriber removed")
+	}
+}
+
+func TestPublicStatusPageAPIToken(t *testing.T) {
+	srv, adminKey := testServer(t)
+
+	sp := &storage.StatusPage{Title: "Private", Slug: "private", Enabled: true, APIEnabled: true}
+	if err := srv.store.CreateStatusPage(context.Background(), sp); err != nil {
+		t.Fatal(err)
+	}
+	public := "/api/v1/status-pages/" + strconv.FormatInt(sp.ID, 10) + "/public"
+	tokenPath := "/api/v1/status-pages/" + strconv.FormatInt(sp.ID, 10) + "/api-token"
+
+	getPublic := func(auth string) int {
+		req := httptest.NewRequest("GET", public, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := getPublic(""); code != http.StatusOK {
+		t.Fatalf("page without token: expected 200, got %d", code)
+	}
+
+	w := checkRequest(t, srv, adminKey, "POST", tokenPath)
+	if w.Code != http.StatusOK {
+		t.Fatalf("regenerate: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		APIToken string `json:"api_token"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.APIToken == "" {
+		t.Fatalf("expected a token, got %+v (%v)", resp, err)
+	}
+
+	if code := getPublic(""); code != http.StatusUnauthorized {
+		t.Fatalf("missing token: expected 401, got %d", code)
+	}
+	if code := getPublic("Bearer wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: expected 401, got %d", code)
+	}
+	if code := getPublic("bearer " + resp.APIToken); code != http.StatusOK {
+		t.Fatalf("valid token: expected 200, got %d", code)
+	}
+
+	stored, err := srv.store.GetStatusPage(context.Background(), sp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.APITokenEnabled || stored.APITokenHash == resp.APIToken {
+		t.Fatalf("expected only the token hash to be stored, got %+v", stored)
+	}
+
+	if w := checkRequest(t, srv, adminKey, "DELETE", tokenPath); w.Code != http.StatusOK {
+		t.Fatalf("delete token: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if code := getPublic(""); code != http.StatusOK {
+		t.Fatalf("after removing the token: expected 200, got %d", code)
+	}
+}
@@ -58,6 +58,18 @@ func (s *Server) statusPageRouter(next http.Handler) http.Handler {
 						case r.Method == http.MethodPost && suffix == "auth":
 							s.web.StatusPageAuthPost(w, r, pageID, slug)
 							return
+						case r.Method == http.MethodPost && suffix == "subscribe":
+							s.web.StatusPageSubscribe(w, r, pageID, slug)
+							return
+						case r.Method == http.MethodGet && suffix == "subscribe/confirm":
+							s.web.StatusPageSubscribeConfirm(w, r, pageID, slug)
+							return
+						case r.Method == http.MethodGet && suffix == "unsubscribe":
+							s.web.StatusPageUnsubscribeGet(w, r, pageID, slug)
+							return
+						case r.Method == http.MethodPost && suffix == "unsubscribe":
+							s.web.StatusPageUnsubscribePost(w, r, pageID, slug)
+							return
 						}
 					}
 				}
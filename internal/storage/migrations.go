@@ -1,6 +1,6 @@
 package storage
 
-const schemaVersion = 22
+const schemaVersion = 56
 
 const schema = `
 CREATE TABLE IF NOT EXISTS schema_version (
@@ -34,26 +34,54 @@ CREATE TABLE IF NOT EXISTS monitors (
 	resend_interval INTEGER NOT NULL DEFAULT 0,
 	group_id        INTEGER DEFAULT NULL,
 	proxy_id        INTEGER DEFAULT NULL REFERENCES proxies(id) ON DELETE SET NULL,
+	ack_silences_reminders INTEGER DEFAULT NULL,
+	latency_baseline_sigma REAL NOT NULL DEFAULT 0,
+	skip_default_channel INTEGER NOT NULL DEFAULT 0,
+	capture_failure_context INTEGER NOT NULL DEFAULT 0,
+	owner           TEXT    NOT NULL DEFAULT '',
+	stream_checks_every INTEGER NOT NULL DEFAULT 0,
+	retries         INTEGER NOT NULL DEFAULT 0,
+	retry_interval  INTEGER NOT NULL DEFAULT 0,
+	redact_patterns TEXT    NOT NULL DEFAULT '[]',
+	degraded_threshold INTEGER NOT NULL DEFAULT 0,
+	flap_window_seconds INTEGER NOT NULL DEFAULT 0,
+	parent_id       INTEGER DEFAULT NULL REFERENCES monitors(id) ON DELETE SET NULL,
+	connect_timeout_secs INTEGER NOT NULL DEFAULT 0,
+	max_response_time_ms INTEGER NOT NULL DEFAULT 0,
+	warmup_seconds  INTEGER NOT NULL DEFAULT 0,
 	created_at      TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
 	updated_at      TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
 );
 
 CREATE INDEX IF NOT EXISTS idx_monitors_group_id ON monitors(group_id);
+CREATE INDEX IF NOT EXISTS idx_monitors_owner ON monitors(owner);
 
 CREATE TABLE IF NOT EXISTS monitor_notifications (
 	monitor_id INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
 	channel_id INTEGER NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+	event_filter TEXT  NOT NULL DEFAULT '',
 	PRIMARY KEY (monitor_id, channel_id)
 );
 
 CREATE INDEX IF NOT EXISTS idx_monitor_notif_channel ON monitor_notifications(channel_id);
 
+CREATE TABLE IF NOT EXISTS latency_baselines (
+	monitor_id INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	hour       INTEGER NOT NULL,
+	mean_ms    REAL    NOT NULL,
+	stddev_ms  REAL    NOT NULL,
+	samples    INTEGER NOT NULL,
+	updated_at TEXT    NOT NULL,
+	PRIMARY KEY (monitor_id, hour)
+);
+
 CREATE TABLE IF NOT EXISTS monitor_status (
 	monitor_id             INTEGER PRIMARY KEY REFERENCES monitors(id) ON DELETE CASCADE,
 	status                 TEXT    NOT NULL DEFAULT 'pending',
 	last_check_at          TEXT,
 	consec_fails           INTEGER NOT NULL DEFAULT 0,
 	consec_successes       INTEGER NOT NULL DEFAULT 0,
+	consec_degraded        INTEGER NOT NULL DEFAULT 0,
 	last_body_hash         TEXT    NOT NULL DEFAULT '',
 	last_cert_fingerprint  TEXT    NOT NULL DEFAULT ''
 );
@@ -71,11 +99,22 @@ CREATE TABLE IF NOT EXISTS check_results (
 	cert_expiry      TEXT,
 	cert_fingerprint TEXT    NOT NULL DEFAULT '',
 	dns_records      TEXT    NOT NULL DEFAULT '',
+	config_hash      TEXT    NOT NULL DEFAULT '',
+	failure_context  TEXT    NOT NULL DEFAULT '',
+	probe_id         INTEGER DEFAULT NULL,
 	created_at       TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
 );
 
 CREATE INDEX IF NOT EXISTS idx_check_results_monitor_id ON check_results(monitor_id, created_at DESC);
 
+CREATE TABLE IF NOT EXISTS check_configs (
+	monitor_id INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	hash       TEXT    NOT NULL,
+	config     TEXT    NOT NULL,
+	created_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
+	PRIMARY KEY (monitor_id, hash)
+);
+
 CREATE TABLE IF NOT EXISTS incidents (
 	id              INTEGER PRIMARY KEY AUTOINCREMENT,
 	monitor_id      INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
@@ -85,7 +124,10 @@ CREATE TABLE IF NOT EXISTS incidents (
 	acknowledged_at TEXT,
 	acknowledged_by TEXT    NOT NULL DEFAULT '',
 	resolved_at     TEXT,
-	resolved_by     TEXT    NOT NULL DEFAULT ''
+	resolved_by     TEXT    NOT NULL DEFAULT '',
+	ack_deadline    TEXT,
+	postmortem      TEXT    NOT NULL DEFAULT '',
+	severity        TEXT    NOT NULL DEFAULT 'critical'
 );
 
 CREATE INDEX IF NOT EXISTS idx_incidents_monitor_id ON incidents(monitor_id, status);
@@ -95,6 +137,7 @@ CREATE TABLE IF NOT EXISTS incident_events (
 	incident_id INTEGER NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
 	type        TEXT    NOT NULL,
 	message     TEXT    NOT NULL DEFAULT '',
+	author      TEXT    NOT NULL DEFAULT '',
 	created_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
 );
 
@@ -107,6 +150,10 @@ CREATE TABLE IF NOT EXISTS notification_channels (
 	enabled    INTEGER NOT NULL DEFAULT 1,
 	settings   TEXT    NOT NULL DEFAULT '{}',
 	events     TEXT    NOT NULL DEFAULT '[]',
+	schedule   TEXT    NOT NULL DEFAULT '',
+	oncall_schedule_id INTEGER,
+	digest_window_seconds   INTEGER NOT NULL DEFAULT 0,
+	digest_flush_on_resolve INTEGER NOT NULL DEFAULT 0,
 	created_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
 	updated_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
 );
@@ -118,6 +165,9 @@ CREATE TABLE IF NOT EXISTS maintenance_windows (
 	start_time  TEXT    NOT NULL,
 	end_time    TEXT    NOT NULL,
 	recurring   TEXT    NOT NULL DEFAULT '',
+	timezone    TEXT    NOT NULL DEFAULT '',
+	cron_expr   TEXT    NOT NULL DEFAULT '',
+	duration_minutes INTEGER NOT NULL DEFAULT 0,
 	created_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
 	updated_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
 );
@@ -195,6 +245,15 @@ CREATE TABLE IF NOT EXISTS request_log_rollups (
 	UNIQUE(date, route_group, monitor_id)
 );
 
+CREATE TABLE IF NOT EXISTS daily_uptime (
+	monitor_id   INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	date         TEXT    NOT NULL,
+	total_checks INTEGER NOT NULL DEFAULT 0,
+	up_checks    INTEGER NOT NULL DEFAULT 0,
+	down_checks  INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (monitor_id, date)
+);
+
 CREATE TABLE IF NOT EXISTS status_pages (
 	id                 INTEGER PRIMARY KEY AUTOINCREMENT,
 	slug               TEXT    NOT NULL UNIQUE,
@@ -209,7 +268,13 @@ CREATE TABLE IF NOT EXISTS status_pages (
 	favicon_url        TEXT    NOT NULL DEFAULT '',
 	custom_header_html TEXT    NOT NULL DEFAULT '',
 	password_hash      TEXT    NOT NULL DEFAULT '',
+	api_token_hash     TEXT    NOT NULL DEFAULT '',
 	analytics_script   TEXT    NOT NULL DEFAULT '',
+	announcement_html      TEXT    NOT NULL DEFAULT '',
+	announcement_enabled   INTEGER NOT NULL DEFAULT 0,
+	announcement_starts_at TEXT,
+	announcement_ends_at   TEXT,
+	subscriber_channel_id  INTEGER,
 	created_at         TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
 	updated_at         TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
 );
@@ -222,6 +287,34 @@ CREATE TABLE IF NOT EXISTS status_page_monitors (
 	PRIMARY KEY (page_id, monitor_id)
 );
 
+CREATE TABLE IF NOT EXISTS status_page_components (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	page_id     INTEGER NOT NULL REFERENCES status_pages(id) ON DELETE CASCADE,
+	name        TEXT    NOT NULL,
+	description TEXT    NOT NULL DEFAULT '',
+	sort_order  INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_page_components_page ON status_page_components(page_id);
+
+CREATE TABLE IF NOT EXISTS status_page_component_monitors (
+	component_id INTEGER NOT NULL REFERENCES status_page_components(id) ON DELETE CASCADE,
+	monitor_id   INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	weight       INTEGER NOT NULL DEFAULT 1,
+	PRIMARY KEY (component_id, monitor_id)
+);
+
+CREATE TABLE IF NOT EXISTS status_page_subscribers (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	page_id      INTEGER NOT NULL REFERENCES status_pages(id) ON DELETE CASCADE,
+	email        TEXT    NOT NULL,
+	token        TEXT    NOT NULL UNIQUE,
+	confirmed    INTEGER NOT NULL DEFAULT 0,
+	created_at   TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
+	confirmed_at TEXT,
+	UNIQUE(page_id, email)
+);
+
 CREATE INDEX IF NOT EXISTS idx_check_results_created_at ON check_results(created_at);
 CREATE INDEX IF NOT EXISTS idx_incidents_resolved_at ON incidents(status, resolved_at);
 CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
@@ -244,6 +337,11 @@ CREATE TABLE IF NOT EXISTS proxies (
 	auth_user  TEXT    NOT NULL DEFAULT '',
 	auth_pass  TEXT    NOT NULL DEFAULT '',
 	enabled    INTEGER NOT NULL DEFAULT 1,
+	last_check_at TEXT,
+	last_ok_at    TEXT,
+	last_error    TEXT    NOT NULL DEFAULT '',
+	consec_fails  INTEGER NOT NULL DEFAULT 0,
+	auto_disabled INTEGER NOT NULL DEFAULT 0,
 	created_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
 	updated_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
 );
@@ -264,6 +362,14 @@ CREATE TABLE IF NOT EXISTS monitor_tags (
 
 CREATE INDEX IF NOT EXISTS idx_monitor_tags_tag ON monitor_tags(tag_id);
 
+CREATE TABLE IF NOT EXISTS channel_tags (
+	channel_id INTEGER NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+	tag_id     INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (channel_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_tags_tag ON channel_tags(tag_id);
+
 CREATE TABLE IF NOT EXISTS notification_history (
 	id          INTEGER PRIMARY KEY AUTOINCREMENT,
 	channel_id  INTEGER NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
@@ -276,6 +382,72 @@ CREATE TABLE IF NOT EXISTS notification_history (
 );
 CREATE INDEX IF NOT EXISTS idx_notif_history_channel ON notification_history(channel_id, sent_at DESC);
 CREATE INDEX IF NOT EXISTS idx_notif_history_sent_at ON notification_history(sent_at DESC);
+
+CREATE TABLE IF NOT EXISTS escalation_policies (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	name       TEXT    NOT NULL,
+	steps      TEXT    NOT NULL DEFAULT '[]',
+	created_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
+	updated_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+);
+
+CREATE TABLE IF NOT EXISTS monitor_escalation_policies (
+	monitor_id INTEGER PRIMARY KEY REFERENCES monitors(id) ON DELETE CASCADE,
+	policy_id  INTEGER NOT NULL REFERENCES escalation_policies(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_escalation_policy ON monitor_escalation_policies(policy_id);
+
+CREATE TABLE IF NOT EXISTS probes (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	name        TEXT    NOT NULL,
+	type        TEXT    NOT NULL DEFAULT 'local',
+	source_addr TEXT    NOT NULL DEFAULT '',
+	url         TEXT    NOT NULL DEFAULT '',
+	token       TEXT    NOT NULL DEFAULT '',
+	enabled     INTEGER NOT NULL DEFAULT 1,
+	created_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
+	updated_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+);
+
+CREATE TABLE IF NOT EXISTS monitor_probes (
+	monitor_id INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	probe_id   INTEGER NOT NULL REFERENCES probes(id) ON DELETE CASCADE,
+	PRIMARY KEY (monitor_id, probe_id)
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_probes_probe ON monitor_probes(probe_id);
+
+CREATE TABLE IF NOT EXISTS oncall_schedules (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	name           TEXT    NOT NULL,
+	timezone       TEXT    NOT NULL DEFAULT '',
+	rotation_start TEXT    NOT NULL,
+	shift_hours    INTEGER NOT NULL DEFAULT 168,
+	members        TEXT    NOT NULL DEFAULT '[]',
+	overrides      TEXT    NOT NULL DEFAULT '[]',
+	created_at     TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
+	updated_at     TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+);
+
+CREATE TABLE IF NOT EXISTS monitor_transitions (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	monitor_id  INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	from_status TEXT    NOT NULL,
+	to_status   TEXT    NOT NULL,
+	cause       TEXT    NOT NULL DEFAULT '',
+	created_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_transitions_monitor ON monitor_transitions(monitor_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS probe_checks (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	monitor_id    INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	probe_id      INTEGER NOT NULL REFERENCES probes(id) ON DELETE CASCADE,
+	status        TEXT    NOT NULL,
+	response_time INTEGER NOT NULL DEFAULT 0,
+	message       TEXT    NOT NULL DEFAULT '',
+	created_at    TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+);
+CREATE INDEX IF NOT EXISTS idx_probe_checks_monitor ON probe_checks(monitor_id, created_at);
 `
 
 // migrations holds incremental schema changes after the baseline.
@@ -372,4 +544,272 @@ ALTER TABLE status_pages ADD COLUMN custom_header_html TEXT NOT NULL DEFAULT '';
 ALTER TABLE status_pages ADD COLUMN password_hash TEXT NOT NULL DEFAULT '';
 ALTER TABLE status_pages ADD COLUMN analytics_script TEXT NOT NULL DEFAULT '';`,
 	},
+	{
+		version: 23,
+		sql: `ALTER TABLE check_results ADD COLUMN config_hash TEXT NOT NULL DEFAULT '';
+CREATE TABLE IF NOT EXISTS check_configs (
+	monitor_id INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	hash       TEXT    NOT NULL,
+	config     TEXT    NOT NULL,
+	created_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
+	PRIMARY KEY (monitor_id, hash)
+);`,
+	},
+	{
+		version: 24,
+		sql:     `ALTER TABLE notification_channels ADD COLUMN schedule TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 25,
+		sql:     `ALTER TABLE monitors ADD COLUMN ack_silences_reminders INTEGER;`,
+	},
+	{
+		version: 26,
+		sql: `CREATE TABLE IF NOT EXISTS status_page_components (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	page_id     INTEGER NOT NULL REFERENCES status_pages(id) ON DELETE CASCADE,
+	name        TEXT    NOT NULL,
+	description TEXT    NOT NULL DEFAULT '',
+	sort_order  INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_page_components_page ON status_page_components(page_id);
+
+CREATE TABLE IF NOT EXISTS status_page_component_monitors (
+	component_id INTEGER NOT NULL REFERENCES status_page_components(id) ON DELETE CASCADE,
+	monitor_id   INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	weight       INTEGER NOT NULL DEFAULT 1,
+	PRIMARY KEY (component_id, monitor_id)
+);`,
+	},
+	{
+		version: 27,
+		sql: `ALTER TABLE monitors ADD COLUMN latency_baseline_sigma REAL NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS latency_baselines (
+	monitor_id INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	hour       INTEGER NOT NULL,
+	mean_ms    REAL    NOT NULL,
+	stddev_ms  REAL    NOT NULL,
+	samples    INTEGER NOT NULL,
+	updated_at TEXT    NOT NULL,
+	PRIMARY KEY (monitor_id, hour)
+);`,
+	},
+	{
+		version: 28,
+		sql:     `ALTER TABLE monitors ADD COLUMN skip_default_channel INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 29,
+		sql: `ALTER TABLE status_pages ADD COLUMN announcement_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE status_pages ADD COLUMN announcement_enabled INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE status_pages ADD COLUMN announcement_starts_at TEXT;
+ALTER TABLE status_pages ADD COLUMN announcement_ends_at TEXT;`,
+	},
+	{
+		version: 30,
+		sql: `ALTER TABLE monitors ADD COLUMN capture_failure_context INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE check_results ADD COLUMN failure_context TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 31,
+		sql: `ALTER TABLE monitors ADD COLUMN owner TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_monitors_owner ON monitors(owner);`,
+	},
+	{
+		version: 32,
+		sql:     `ALTER TABLE monitors ADD COLUMN stream_checks_every INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 33,
+		sql: `CREATE TABLE IF NOT EXISTS escalation_policies (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	name       TEXT    NOT NULL,
+	steps      TEXT    NOT NULL DEFAULT '[]',
+	created_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
+	updated_at TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+);
+
+CREATE TABLE IF NOT EXISTS monitor_escalation_policies (
+	monitor_id INTEGER PRIMARY KEY REFERENCES monitors(id) ON DELETE CASCADE,
+	policy_id  INTEGER NOT NULL REFERENCES escalation_policies(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_escalation_policy ON monitor_escalation_policies(policy_id);`,
+	},
+	{
+		version: 34,
+		sql:     `ALTER TABLE maintenance_windows ADD COLUMN timezone TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 35,
+		sql: `ALTER TABLE maintenance_windows ADD COLUMN cron_expr TEXT NOT NULL DEFAULT '';
+ALTER TABLE maintenance_windows ADD COLUMN duration_minutes INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 36,
+		sql: `CREATE TABLE IF NOT EXISTS probes (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	name        TEXT    NOT NULL,
+	type        TEXT    NOT NULL DEFAULT 'local',
+	source_addr TEXT    NOT NULL DEFAULT '',
+	url         TEXT    NOT NULL DEFAULT '',
+	token       TEXT    NOT NULL DEFAULT '',
+	enabled     INTEGER NOT NULL DEFAULT 1,
+	created_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
+	updated_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+);
+
+CREATE TABLE IF NOT EXISTS monitor_probes (
+	monitor_id INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	probe_id   INTEGER NOT NULL REFERENCES probes(id) ON DELETE CASCADE,
+	PRIMARY KEY (monitor_id, probe_id)
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_probes_probe ON monitor_probes(probe_id);
+ALTER TABLE check_results ADD COLUMN probe_id INTEGER DEFAULT NULL;`,
+	},
+	{
+		version: 37,
+		sql:     `ALTER TABLE incidents ADD COLUMN ack_deadline TEXT;`,
+	},
+	{
+		version: 38,
+		sql: `ALTER TABLE status_pages ADD COLUMN subscriber_channel_id INTEGER;
+
+CREATE TABLE IF NOT EXISTS status_page_subscribers (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	page_id      INTEGER NOT NULL REFERENCES status_pages(id) ON DELETE CASCADE,
+	email        TEXT    NOT NULL,
+	token        TEXT    NOT NULL UNIQUE,
+	confirmed    INTEGER NOT NULL DEFAULT 0,
+	created_at   TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
+	confirmed_at TEXT,
+	UNIQUE(page_id, email)
+);`,
+	},
+	{
+		version: 39,
+		sql: `ALTER TABLE monitors ADD COLUMN retries INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE monitors ADD COLUMN retry_interval INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 40,
+		sql: `ALTER TABLE incidents ADD COLUMN postmortem TEXT NOT NULL DEFAULT '';
+ALTER TABLE incident_events ADD COLUMN author TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 41,
+		sql:     `ALTER TABLE monitors ADD COLUMN redact_patterns TEXT NOT NULL DEFAULT '[]';`,
+	},
+	{
+		version: 42,
+		sql: `CREATE TABLE IF NOT EXISTS channel_tags (
+	channel_id INTEGER NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+	tag_id     INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (channel_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_channel_tags_tag ON channel_tags(tag_id);`,
+	},
+	{
+		version: 43,
+		sql: `ALTER TABLE monitors ADD COLUMN degraded_threshold INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE monitor_status ADD COLUMN consec_degraded INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE incidents ADD COLUMN severity TEXT NOT NULL DEFAULT 'critical';`,
+	},
+	{
+		version: 44,
+		sql: `CREATE TABLE IF NOT EXISTS daily_uptime (
+	monitor_id   INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	date         TEXT    NOT NULL,
+	total_checks INTEGER NOT NULL DEFAULT 0,
+	up_checks    INTEGER NOT NULL DEFAULT 0,
+	down_checks  INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (monitor_id, date)
+);`,
+	},
+	{
+		version: 45,
+		sql:     `ALTER TABLE monitors ADD COLUMN flap_window_seconds INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 46,
+		sql: `ALTER TABLE notification_channels ADD COLUMN oncall_schedule_id INTEGER;
+
+CREATE TABLE IF NOT EXISTS oncall_schedules (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	name           TEXT    NOT NULL,
+	timezone       TEXT    NOT NULL DEFAULT '',
+	rotation_start TEXT    NOT NULL,
+	shift_hours    INTEGER NOT NULL DEFAULT 168,
+	members        TEXT    NOT NULL DEFAULT '[]',
+	overrides      TEXT    NOT NULL DEFAULT '[]',
+	created_at     TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now')),
+	updated_at     TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+);`,
+	},
+	{
+		version: 47,
+		sql:     `ALTER TABLE monitors ADD COLUMN parent_id INTEGER DEFAULT NULL REFERENCES monitors(id) ON DELETE SET NULL;`,
+	},
+	{
+		version: 48,
+		sql:     `ALTER TABLE monitors ADD COLUMN connect_timeout_secs INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 49,
+		sql:     `ALTER TABLE monitors ADD COLUMN max_response_time_ms INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 50,
+		sql:     `ALTER TABLE monitors ADD COLUMN warmup_seconds INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 51,
+		sql:     `ALTER TABLE monitor_notifications ADD COLUMN event_filter TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 52,
+		sql: `ALTER TABLE proxies ADD COLUMN last_check_at TEXT;
+ALTER TABLE proxies ADD COLUMN last_ok_at TEXT;
+ALTER TABLE proxies ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE proxies ADD COLUMN consec_fails INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE proxies ADD COLUMN auto_disabled INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 53,
+		sql: `CREATE TABLE IF NOT EXISTS monitor_transitions (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	monitor_id  INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	from_status TEXT    NOT NULL,
+	to_status   TEXT    NOT NULL,
+	cause       TEXT    NOT NULL DEFAULT '',
+	created_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_transitions_monitor ON monitor_transitions(monitor_id, created_at DESC);`,
+	},
+	{
+		version: 54,
+		sql: `ALTER TABLE notification_channels ADD COLUMN digest_window_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE notification_channels ADD COLUMN digest_flush_on_resolve INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 55,
+		sql:     `ALTER TABLE status_pages ADD COLUMN api_token_hash TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 56,
+		sql: `CREATE TABLE IF NOT EXISTS probe_checks (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	monitor_id    INTEGER NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	probe_id      INTEGER NOT NULL REFERENCES probes(id) ON DELETE CASCADE,
+	status        TEXT    NOT NULL,
+	response_time INTEGER NOT NULL DEFAULT 0,
+	message       TEXT    NOT NULL DEFAULT '',
+	created_at    TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ','now'))
+);
+CREATE INDEX IF NOT EXISTS idx_probe_checks_monitor ON probe_checks(monitor_id, created_at);
+INSERT INTO probe_checks (monitor_id, probe_id, status, response_time, message, created_at)
+SELECT cr.monitor_id, cr.probe_id, cr.status, cr.response_time, cr.message, cr.created_at
+FROM check_results cr JOIN probes p ON p.id = cr.probe_id;`,
+	},
 }
@@ -2,6 +2,10 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,13 +29,67 @@ type Monitor struct {
 	ResendInterval   int             `json:"resend_interval"`
 	GroupID          *int64          `json:"group_id,omitempty"`
 	ProxyID          *int64          `json:"proxy_id,omitempty"`
-	CreatedAt        time.Time       `json:"created_at"`
-	UpdatedAt        time.Time       `json:"updated_at"`
+	// AckSilencesReminders overrides monitor.ack_silences_reminders; nil inherits it.
+	AckSilencesReminders *bool `json:"ack_silences_reminders,omitempty"`
+	// LatencyBaselineSigma marks a check degraded when its response time is
+	// more than this many standard deviations above the hourly baseline. 0 disables it.
+	LatencyBaselineSigma float64 `json:"latency_baseline_sigma,omitempty"`
+	// SkipDefaultChannel opts a monitor without notification channels out of
+	// monitor.default_notification_channel.
+	SkipDefaultChannel bool `json:"skip_default_channel,omitempty"`
+	// CaptureFailureContext stores headers, body, DNS resolution and TLS
+	// details with the check that transitions the monitor to down.
+	CaptureFailureContext bool `json:"capture_failure_context,omitempty"`
+	// Owner is the team or person accountable for the monitor. It is matched
+	// against monitor.owner_channels for notification routing.
+	Owner string `json:"owner,omitempty"`
+	// StreamChecksEvery sends every Nth check result, and every status change,
+	// as a check.completed event. 0 disables streaming.
+	StreamChecksEvery int `json:"stream_checks_every,omitempty"`
+	// Retries re-runs a failed check up to this many times, RetryInterval
+	// seconds apart, before the failure counts toward FailureThreshold.
+	Retries       int `json:"retries,omitempty"`
+	RetryInterval int `json:"retry_interval,omitempty"`
+	// RedactPatterns are regexes whose matches are replaced with *** in the
+	// body and message stored with each check.
+	RedactPatterns []string `json:"redact_patterns,omitempty"`
+	// DegradedThreshold opens a warning-severity incident after this many
+	// consecutive degraded results, which then no longer count toward
+	// FailureThreshold. 0 treats degraded results as failures.
+	DegradedThreshold int `json:"degraded_threshold,omitempty"`
+	// FlapWindowSeconds reopens the last incident instead of opening a new
+	// one when the monitor fails again this soon after it resolved.
+	FlapWindowSeconds int `json:"flap_window_seconds,omitempty"`
+	// ConnectTimeout bounds establishing the TCP connection of http, tcp and
+	// tls checks, in seconds, while Timeout bounds the whole check. 0 uses
+	// Timeout.
+	ConnectTimeout int `json:"connect_timeout,omitempty"`
+	// MaxResponseTimeMs marks an up check degraded when its response time
+	// exceeds this many milliseconds. 0 disables it.
+	MaxResponseTimeMs int `json:"max_response_time_ms,omitempty"`
+	// WarmupSeconds keeps failures from opening incidents or notifying for
+	// this long after the monitor is created, resumed or edited (UpdatedAt).
+	// Check results are still stored. 0 disables it.
+	WarmupSeconds int `json:"warmup_seconds,omitempty"`
+	// ParentID names a monitor this one depends on. While the parent has an
+	// open incident, this monitor's failures don't open incidents or notify.
+	ParentID  *int64    `json:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 
 	// Transient fields (not stored in monitors table)
 	NotificationChannelIDs []int64      `json:"notification_channel_ids,omitempty"`
+	EscalationPolicyID     *int64       `json:"escalation_policy_id,omitempty"`
 	MonitorTags            []MonitorTag `json:"monitor_tags,omitempty"`
-	ProxyURL               string       `json:"-"` // resolved at check time
+	AutoTags               []string     `json:"auto_tags,omitempty"` // tags added by auto_tag_rules on the last save
+	ProxyURL               string       `json:"-"`                   // resolved at check time
+	ProbeIDs               []int64      `json:"probe_ids,omitempty"`
+	Probes                 []*Probe     `json:"-"` // enabled assigned probes, resolved at load time
+	SourceAddr             string       `json:"-"` // local address checks bind to, set per probe
+
+	// NotificationFilters narrows the channels in NotificationChannelIDs,
+	// keyed by channel ID. Channels without an entry get every event.
+	NotificationFilters map[int64]*NotificationFilter `json:"notification_filters,omitempty"`
 
 	// Computed fields (not stored directly)
 	Status          string     `json:"status,omitempty"`
@@ -45,7 +103,7 @@ type HTTPSettings struct {
 	Method          string            `json:"method,omitempty"`
 	Headers         map[string]string `json:"headers,omitempty"`
 	Body            string            `json:"body,omitempty"`
-	BodyEncoding    string            `json:"body_encoding,omitempty"` // json, xml, form, raw
+	BodyEncoding    string            `json:"body_encoding,omitempty"` // json, xml, form, multipart, raw
 	FollowRedirects *bool             `json:"follow_redirects,omitempty"`
 	MaxRedirects    int               `json:"max_redirects,omitempty"`
 	SkipTLSVerify   bool              `json:"skip_tls_verify,omitempty"`
@@ -54,24 +112,176 @@ type HTTPSettings struct {
 	BasicAuthUser   string            `json:"basic_auth_user,omitempty"`
 	BasicAuthPass   string            `json:"basic_auth_pass,omitempty"`
 	BearerToken     string            `json:"bearer_token,omitempty"`
-	ExpectedStatus  int               `json:"expected_status,omitempty"`
+	ExpectedStatus  StatusSpec        `json:"expected_status,omitempty"`
+	// RangeBytes requests only the first RangeBytes bytes of the body via a
+	// Range header and expects 206 Partial Content. 0 fetches the whole body.
+	RangeBytes int `json:"range_bytes,omitempty"`
+	// MaxTTFBMs marks the check degraded when the first response byte takes
+	// longer than this many milliseconds. 0 disables it.
+	MaxTTFBMs int `json:"max_ttfb_ms,omitempty"`
+	// NoDecompress keeps gzip and deflate bodies as sent instead of
+	// decompressing them for assertions, hashing and storage.
+	NoDecompress bool `json:"no_decompress,omitempty"`
+	// MultipartFile is an optional file part sent after the fields of a
+	// multipart body.
+	MultipartFile *MultipartFile `json:"multipart_file,omitempty"`
+	// Content narrows what track_changes hashes and diffs.
+	Content *ContentSettings `json:"content,omitempty"`
+}
+
+// ContentSettings controls content-change tracking.
+type ContentSettings struct {
+	// Selector is a CSS selector; only the text of the matched elements is
+	// hashed and diffed. The whole body is used when it matches nothing.
+	Selector string `json:"selector,omitempty"`
+	// IgnorePatterns are regexes whose matches are replaced with a fixed
+	// placeholder before hashing and diffing, e.g. embedded timestamps.
+	IgnorePatterns []string `json:"ignore_patterns,omitempty"`
+}
+
+// MultipartFile is an inline file part of a multipart/form-data body.
+type MultipartFile struct {
+	Field       string `json:"field"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"` // default application/octet-stream
+	Content     string `json:"content"`
+}
+
+// MultipartFields parses a multipart body: one key=value field per line.
+// Blank lines are skipped; values may contain "=".
+func (s HTTPSettings) MultipartFields() ([][2]string, error) {
+	var fields [][2]string
+	for i, line := range strings.Split(s.Body, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("line %d: expected key=value", i+1)
+		}
+		fields = append(fields, [2]string{strings.TrimSpace(k), v})
+	}
+	return fields, nil
+}
+
+// StatusSpec lists the HTTP status codes an http monitor accepts as
+// comma-separated codes, ranges and classes, e.g. "200-299,401,5xx". Empty
+// accepts any 2xx or 3xx. Settings saved as a bare number still decode.
+type StatusSpec string
+
+func (s *StatusSpec) UnmarshalJSON(b []byte) error {
+	var code int
+	if err := json.Unmarshal(b, &code); err == nil {
+		*s = ""
+		if code != 0 {
+			*s = StatusSpec(strconv.Itoa(code))
+		}
+		return nil
+	}
+	var spec string
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return fmt.Errorf("expected_status must be a string or a number")
+	}
+	*s = StatusSpec(spec)
+	return nil
+}
+
+// StatusRange is an inclusive range of HTTP status codes.
+type StatusRange struct {
+	Min, Max int
+}
+
+// Ranges parses the spec. An empty spec yields 200-399.
+func (s StatusSpec) Ranges() ([]StatusRange, error) {
+	spec := strings.TrimSpace(string(s))
+	if spec == "" {
+		return []StatusRange{{200, 399}}, nil
+	}
+	var ranges []StatusRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		var r StatusRange
+		switch lo, hi, isRange := strings.Cut(part, "-"); {
+		case len(part) == 3 && part[0] >= '1' && part[0] <= '5' && part[1:] == "xx":
+			base := int(part[0]-'0') * 100
+			r = StatusRange{base, base + 99}
+		case isRange:
+			var err error
+			if r.Min, err = parseStatusCode(lo); err != nil {
+				return nil, err
+			}
+			if r.Max, err = parseStatusCode(hi); err != nil {
+				return nil, err
+			}
+			if r.Min > r.Max {
+				return nil, fmt.Errorf("status range %q is reversed", part)
+			}
+		default:
+			code, err := parseStatusCode(part)
+			if err != nil {
+				return nil, err
+			}
+			r = StatusRange{code, code}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func parseStatusCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("%q is not a status code between 100 and 599", s)
+	}
+	return code, nil
+}
+
+// MatchStatus reports whether code falls in one of ranges.
+func MatchStatus(ranges []StatusRange, code int) bool {
+	for _, r := range ranges {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
 }
 
 // TCPSettings holds TCP check configuration.
 type TCPSettings struct {
 	SendData   string `json:"send_data,omitempty"`
 	ExpectData string `json:"expect_data,omitempty"`
+	// ExpectRegex is matched against the response instead of ExpectData.
+	ExpectRegex string `json:"expect_regex,omitempty"`
+	// Mode is "" (expect_data or expect_regex when set), "connect" (connectability only,
+	// nothing is sent or read) or "banner" (wait for the first bytes).
+	Mode string `json:"mode,omitempty"`
+	// BannerTimeoutMs bounds the wait for the first response bytes after
+	// connecting. 0 uses the monitor timeout.
+	BannerTimeoutMs int `json:"banner_timeout_ms,omitempty"`
 }
 
 // DNSSettings holds DNS check configuration.
 type DNSSettings struct {
-	RecordType string `json:"record_type"` // A, AAAA, CNAME, MX, TXT, NS, SOA
-	Server     string `json:"server,omitempty"`
+	RecordType     string   `json:"record_type"`               // A, AAAA, CNAME, MX, TXT, NS; comma-separated for several
+	Server         string   `json:"server,omitempty"`          // host or host:port, port defaults to 53
+	DoHURL         string   `json:"doh_url,omitempty"`         // DNS-over-HTTPS endpoint, replaces server
+	ExpectedValues []string `json:"expected_values,omitempty"` // resolved values to compare against
+	MatchMode      string   `json:"match_mode,omitempty"`      // any (default), all, exact
+	ValidateDNSSEC bool     `json:"validate_dnssec,omitempty"` // down unless the answer is signed or validated
 }
 
 // TLSSettings holds TLS check configuration.
 type TLSSettings struct {
 	WarnDaysBefore int `json:"warn_days_before,omitempty"` // cert expiry warning threshold
+	// ExpectedIssuer and ExpectedCN mark the check degraded when the leaf
+	// certificate's issuer or subject common name differs. The issuer matches
+	// either its common name or its full distinguished name.
+	ExpectedIssuer string `json:"expected_issuer,omitempty"`
+	ExpectedCN     string `json:"expected_cn,omitempty"`
+	// RequireChainValid also requires the presented chain itself to be in
+	// order and within its validity period, not just some path to a root.
+	RequireChainValid bool `json:"require_chain_valid,omitempty"`
 }
 
 // WebSocketSettings holds WebSocket check configuration.
@@ -79,12 +289,71 @@ type WebSocketSettings struct {
 	Headers     map[string]string `json:"headers,omitempty"`
 	SendMessage string            `json:"send_message,omitempty"`
 	ExpectReply string            `json:"expect_reply,omitempty"`
+	Subprotocol string            `json:"subprotocol,omitempty"`
+	// Steps run in order after the send_message/expect_reply pair, which
+	// behaves as a leading contains step.
+	Steps []WebSocketStep `json:"steps,omitempty"`
+}
+
+// WebSocket step match modes.
+const (
+	WSMatchContains = "contains"
+	WSMatchRegex    = "regex"
+	WSMatchJSON     = "json"
+)
+
+// WebSocketStep sends a frame, reads one reply frame, or both. Expect is
+// compared using Match: contains (default), regex, or json, where Expect is
+// a JSON value the reply must contain as a subset.
+type WebSocketStep struct {
+	Send   string `json:"send,omitempty"`
+	Expect string `json:"expect,omitempty"`
+	Match  string `json:"match,omitempty"`
+}
+
+// TransactionSettings holds a transaction check: HTTP steps run in order
+// against one cookie jar.
+type TransactionSettings struct {
+	Steps         []TransactionStep `json:"steps"`
+	SkipTLSVerify bool              `json:"skip_tls_verify,omitempty"`
+}
+
+// TransactionStep is one request of a transaction. URL may be relative to
+// the monitor target. URL, header values and body may use {{name}} to insert
+// a variable captured by an earlier step.
+type TransactionStep struct {
+	Name           string                 `json:"name,omitempty"`
+	Method         string                 `json:"method,omitempty"`
+	URL            string                 `json:"url"`
+	Headers        map[string]string      `json:"headers,omitempty"`
+	Body           string                 `json:"body,omitempty"`
+	ExpectedStatus StatusSpec             `json:"expected_status,omitempty"`
+	Assertions     json.RawMessage        `json:"assertions,omitempty"`
+	Extract        []TransactionExtractor `json:"extract,omitempty"`
+}
+
+// Transaction extractor sources.
+const (
+	ExtractJSON   = "json"
+	ExtractHeader = "header"
+)
+
+// TransactionExtractor captures a value from a step's response into Var:
+// the JSON path Path of the body for source json, or the header Path for
+// source header.
+type TransactionExtractor struct {
+	Var    string `json:"var"`
+	Source string `json:"source"`
+	Path   string `json:"path"`
 }
 
 // CommandSettings holds command check configuration.
 type CommandSettings struct {
 	Command string   `json:"command"`
 	Args    []string `json:"args,omitempty"`
+	// ParseJSON requires stdout to be a JSON document, which becomes the
+	// check body for json_path assertions.
+	ParseJSON bool `json:"parse_json,omitempty"`
 }
 
 // DockerSettings holds Docker container check configuration.
@@ -104,18 +373,110 @@ type GRPCSettings struct {
 	ServiceName   string `json:"service_name,omitempty"`
 	UseTLS        bool   `json:"use_tls,omitempty"`
 	SkipTLSVerify bool   `json:"skip_tls_verify,omitempty"`
+	Mode          string `json:"mode,omitempty"` // "health" (default) or "stream"
+	// Method is /package.Service/Method. In health mode it names a unary
+	// method called instead of the health service, resolved through server
+	// reflection. In stream mode it defaults to grpc.health.v1.Health/Watch.
+	Method        string          `json:"method,omitempty"`
+	Request       json.RawMessage `json:"request,omitempty"`        // health mode with method: request message as JSON
+	RequestBase64 string          `json:"request_base64,omitempty"` // stream mode: serialized request message
 }
 
-// MQTTSettings holds MQTT connection check configuration.
+// MQTTSettings holds MQTT connection check configuration. When
+// ExpectMessage, ExpectRegex or WaitRetained is set the checker waits on
+// Topic for a message and compares its payload.
 type MQTTSettings struct {
 	ClientID      string `json:"client_id,omitempty"`
 	Username      string `json:"username,omitempty"`
 	Password      string `json:"password,omitempty"`
 	Topic         string `json:"topic,omitempty"`
 	ExpectMessage string `json:"expect_message,omitempty"`
+	ExpectRegex   string `json:"expect_regex,omitempty"`
+	QoS           int    `json:"qos,omitempty"`
+	WaitRetained  bool   `json:"wait_retained,omitempty"`
 	UseTLS        bool   `json:"use_tls,omitempty"`
 }
 
+// AMQPSettings holds RabbitMQ queue depth check configuration. The monitor
+// target is the management API base URL.
+type AMQPSettings struct {
+	VHost         string `json:"vhost,omitempty"` // default "/"
+	Queue         string `json:"queue"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	WarnDepth     int64  `json:"warn_depth,omitempty"` // degraded at or above, 0 = off
+	CritDepth     int64  `json:"crit_depth,omitempty"` // down at or above, 0 = off
+	SkipTLSVerify bool   `json:"skip_tls_verify,omitempty"`
+}
+
+// SMTPSettings holds mail server check configuration. The monitor target is
+// host or host:port (default port 25).
+type SMTPSettings struct {
+	StartTLS       bool   `json:"starttls,omitempty"`
+	ExpectGreeting string `json:"expect_greeting,omitempty"` // degraded when the 220 greeting lacks it
+	Username       string `json:"username,omitempty"`
+	Password       string `json:"password,omitempty"`
+	SendTestMail   bool   `json:"send_test_mail,omitempty"`
+	MailFrom       string `json:"mail_from,omitempty"`
+	MailTo         string `json:"mail_to,omitempty"`
+	SkipTLSVerify  bool   `json:"skip_tls_verify,omitempty"`
+}
+
+// RedisSettings holds Redis check configuration. The monitor target is host
+// or host:port (default port 6379).
+type RedisSettings struct {
+	Username string `json:"username,omitempty"` // ACL user, Redis 6+
+	Password string `json:"password,omitempty"`
+	DB       int    `json:"db,omitempty"`
+	UseTLS   bool   `json:"use_tls,omitempty"`
+	Command  string `json:"command,omitempty"` // default PING
+}
+
+// SSHSettings holds SSH check configuration. The monitor target is host or
+// host:port (default port 22).
+type SSHSettings struct {
+	ExpectBanner        string `json:"expect_banner,omitempty"`        // substring of the server identification line
+	ExpectedFingerprint string `json:"expected_fingerprint,omitempty"` // SHA256:... as printed by ssh-keygen -l
+	BannerOnly          bool   `json:"banner_only,omitempty"`          // skip the key exchange
+}
+
+// ICMPSettings holds ICMP check configuration.
+type ICMPSettings struct {
+	Count          int `json:"count,omitempty"`            // echo requests per check, default 3
+	IntervalMs     int `json:"interval_ms,omitempty"`      // between requests, default 500
+	MaxLossPercent int `json:"max_loss_percent,omitempty"` // degraded above, default 50; down at 100%
+}
+
+// NTPSettings holds NTP check configuration. The monitor target is host or
+// host:port (default port 123).
+type NTPSettings struct {
+	MaxOffsetMs  int `json:"max_offset_ms,omitempty"`  // degraded above, 0 = off
+	CritOffsetMs int `json:"crit_offset_ms,omitempty"` // down above, 0 = off
+	MaxStratum   int `json:"max_stratum,omitempty"`    // down above, 0 = off
+}
+
+// KafkaSettings holds Kafka check configuration. The monitor target is a
+// bootstrap broker as host or host:port (default port 9092).
+type KafkaSettings struct {
+	MinBrokers int    `json:"min_brokers,omitempty"` // degraded below, 0 = off
+	UseTLS     bool   `json:"use_tls,omitempty"`
+	SASLUser   string `json:"sasl_username,omitempty"` // SASL/PLAIN
+	SASLPass   string `json:"sasl_password,omitempty"`
+}
+
+// S3Settings holds S3-compatible object existence check configuration. The
+// monitor target is the storage endpoint URL.
+type S3Settings struct {
+	Region          string `json:"region,omitempty"` // default "us-east-1"
+	Bucket          string `json:"bucket"`
+	Key             string `json:"key"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	MaxAgeSeconds   int64  `json:"max_age_seconds,omitempty"` // down when LastModified is older, 0 = off
+	VirtualHosted   bool   `json:"virtual_hosted,omitempty"`  // bucket.endpoint instead of endpoint/bucket
+	SkipTLSVerify   bool   `json:"skip_tls_verify,omitempty"`
+}
+
 // SparklinePoint holds a single data point for sparkline rendering.
 type SparklinePoint struct {
 	Status       string
@@ -136,7 +497,26 @@ type CheckResult struct {
 	CertExpiry      *time.Time `json:"cert_expiry,omitempty"`
 	CertFingerprint string     `json:"cert_fingerprint,omitempty"`
 	DNSRecords      string     `json:"dns_records,omitempty"` // JSON encoded
+	ConfigHash      string     `json:"config_hash,omitempty"`
+	FailureContext  string     `json:"failure_context,omitempty"` // JSON encoded, first down check only
+	ProbeID         *int64     `json:"probe_id,omitempty"`        // nil when run without probes
 	CreatedAt       time.Time  `json:"created_at"`
+
+	// Transient: config snapshot persisted to check_configs keyed by ConfigHash
+	Config json.RawMessage `json:"-"`
+}
+
+// CheckConfig is the subset of a monitor's configuration that determines how
+// a check is executed. A snapshot is stored once per distinct hash so that a
+// historical check can be re-run exactly as it was configured.
+type CheckConfig struct {
+	Type           string          `json:"type"`
+	Target         string          `json:"target"`
+	Timeout        int             `json:"timeout"`
+	ConnectTimeout int             `json:"connect_timeout,omitempty"`
+	Settings       json.RawMessage `json:"settings,omitempty"`
+	Assertions     json.RawMessage `json:"assertions,omitempty"`
+	UpsideDown     bool            `json:"upside_down,omitempty"`
 }
 
 // Incident tracks a period of downtime or degradation.
@@ -151,14 +531,31 @@ type Incident struct {
 	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
 	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
 	ResolvedBy     string     `json:"resolved_by,omitempty"`
+	AckDeadline    *time.Time `json:"ack_deadline,omitempty"` // acknowledgement lapses back to open at this time
+	Postmortem     string     `json:"postmortem,omitempty"`   // written root-cause summary
+	Severity       string     `json:"severity"`               // critical, or warning for degraded incidents
+}
+
+// IncidentCauseGroup counts a monitor's incidents that share a normalized
+// cause. Example is the most recent raw cause in the group.
+type IncidentCauseGroup struct {
+	MonitorID      int64     `json:"monitor_id"`
+	MonitorName    string    `json:"monitor_name,omitempty"`
+	Cause          string    `json:"cause"`
+	Example        string    `json:"example"`
+	Count          int64     `json:"count"`
+	OpenCount      int64     `json:"open_count"`
+	LastIncidentID int64     `json:"last_incident_id"`
+	LastSeen       time.Time `json:"last_seen"`
 }
 
 // IncidentEvent is a timeline entry within an incident.
 type IncidentEvent struct {
 	ID         int64     `json:"id"`
 	IncidentID int64     `json:"incident_id"`
-	Type       string    `json:"type"` // created, acknowledged, resolved, check_failed, check_recovered
+	Type       string    `json:"type"` // created, acknowledged, resolved, check_failed, check_recovered, escalated, note
 	Message    string    `json:"message"`
+	Author     string    `json:"author,omitempty"` // API key name, for notes
 	CreatedAt  time.Time `json:"created_at"`
 }
 
@@ -169,9 +566,19 @@ type NotificationChannel struct {
 	Type      string          `json:"type"` // webhook, email, telegram, discord, slack
 	Enabled   bool            `json:"enabled"`
 	Settings  json.RawMessage `json:"settings"`
-	Events    []string        `json:"events"` // incident.created, incident.resolved, etc.
+	Events    []string        `json:"events"`             // incident.created, incident.resolved, etc.
+	Schedule  json.RawMessage `json:"schedule,omitempty"` // active windows, empty = always active
+	TagIDs    []int64         `json:"tag_ids,omitempty"`  // also notify for monitors carrying any of these tags
 	CreatedAt time.Time       `json:"created_at"`
 	UpdatedAt time.Time       `json:"updated_at"`
+	// OncallScheduleID sends email, pagerduty and opsgenie notifications to
+	// the schedule's current responder instead of the configured recipient.
+	OncallScheduleID *int64 `json:"oncall_schedule_id,omitempty"`
+	// DigestWindowSeconds buffers events for this long and sends them as one
+	// summary message (0 = send each event as it happens). With
+	// DigestFlushOnResolve a resolution sends the buffered digest at once.
+	DigestWindowSeconds  int  `json:"digest_window_seconds,omitempty"`
+	DigestFlushOnResolve bool `json:"digest_flush_on_resolve,omitempty"`
 }
 
 // MaintenanceWindow defines a period where alerts are suppressed.
@@ -181,9 +588,26 @@ type MaintenanceWindow struct {
 	MonitorIDs []int64   `json:"monitor_ids"` // empty = all monitors
 	StartTime  time.Time `json:"start_time"`
 	EndTime    time.Time `json:"end_time"`
-	Recurring  string    `json:"recurring,omitempty"` // "", "daily", "weekly", "monthly"
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	Recurring  string    `json:"recurring,omitempty"` // "", "daily", "weekly", "monthly", "cron"
+	Timezone   string    `json:"timezone,omitempty"`  // IANA name for recurrences; empty means UTC
+	// CronExpr and DurationMinutes define a "cron" window: it opens at each
+	// firing of the expression and stays open for the duration. StartTime and
+	// EndTime are not used.
+	CronExpr        string    `json:"cron_expr,omitempty"`
+	DurationMinutes int       `json:"duration_minutes,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Location returns the window's timezone, falling back to UTC when it is
+// empty or unknown.
+func (mw *MaintenanceWindow) Location() *time.Location {
+	if mw.Timezone != "" {
+		if loc, err := time.LoadLocation(mw.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
 }
 
 // ContentChange records when a monitored page's content changes.
@@ -198,6 +622,17 @@ type ContentChange struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// MonitorTransition records a change of a monitor's status, with the check
+// message that caused it.
+type MonitorTransition struct {
+	ID         int64     `json:"id"`
+	MonitorID  int64     `json:"monitor_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Cause      string    `json:"cause"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 // MonitorStatus holds the runtime state of a monitor.
 type MonitorStatus struct {
 	MonitorID           int64      `json:"monitor_id"`
@@ -205,6 +640,7 @@ type MonitorStatus struct {
 	LastCheckAt         *time.Time `json:"last_check_at,omitempty"`
 	ConsecFails         int        `json:"consec_fails"`
 	ConsecSuccesses     int        `json:"consec_successes"`
+	ConsecDegraded      int        `json:"consec_degraded"`
 	LastBodyHash        string     `json:"-"`
 	LastCertFingerprint string     `json:"-"`
 }
@@ -231,7 +667,18 @@ type Heartbeat struct {
 	Token      string     `json:"token"`
 	Grace      int        `json:"grace"` // grace period in seconds
 	LastPingAt *time.Time `json:"last_ping_at,omitempty"`
-	Status     string     `json:"status"` // up, down, pending
+	Status     string     `json:"status"` // up, down, pending, paused
+}
+
+// NextPingDue returns when the heartbeat expires if no ping arrives: the
+// last ping plus the monitor's interval and the grace period. It is nil
+// before the first ping.
+func (h *Heartbeat) NextPingDue(intervalSecs int) *time.Time {
+	if h.LastPingAt == nil {
+		return nil
+	}
+	due := h.LastPingAt.Add(time.Duration(intervalSecs+h.Grace) * time.Second)
+	return &due
 }
 
 // AuditEntry logs a mutation in the system.
@@ -291,6 +738,20 @@ type MonitorTag struct {
 	Value string `json:"value"`
 }
 
+// NotificationFilter limits which of a monitor's events reach one of its
+// notification channels. The channel only fires while the monitor carries
+// one of Tags, and for incident events only when the incident's severity is
+// at least MinSeverity. An empty filter lets everything through.
+type NotificationFilter struct {
+	Tags        []string `json:"tags,omitempty"`
+	MinSeverity string   `json:"min_severity,omitempty"` // warning or critical
+}
+
+// IsZero reports whether f filters nothing.
+func (f *NotificationFilter) IsZero() bool {
+	return f == nil || (len(f.Tags) == 0 && f.MinSeverity == "")
+}
+
 // MonitorGroup organizes monitors into logical groups.
 type MonitorGroup struct {
 	ID        int64     `json:"id"`
@@ -300,14 +761,90 @@ type MonitorGroup struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// GroupStatus rolls a group's monitors up into one status: the worst of
+// down, degraded and up among enabled monitors, or pending when the group is
+// empty or none of them has been checked yet. Paused monitors only add to Paused, and heartbeat
+// monitors never make the group down: a missed heartbeat is counted in Down
+// but left out of Status.
+type GroupStatus struct {
+	GroupID  int64  `json:"group_id"`
+	Status   string `json:"status"` // down, degraded, up, pending
+	Total    int64  `json:"total"`
+	Up       int64  `json:"up"`
+	Down     int64  `json:"down"`
+	Degraded int64  `json:"degraded"`
+	Pending  int64  `json:"pending"`
+	Paused   int64  `json:"paused"`
+}
+
+// Group uptime modes: with GroupUptimeAll the group is up in a bucket only
+// when every member is, with GroupUptimeAny when at least one member is.
+const (
+	GroupUptimeAll = "all"
+	GroupUptimeAny = "any"
+)
+
+// GroupUptime is a group's combined availability between From and To. The
+// range is cut into buckets of BucketSeconds, the longest check interval
+// among the group's monitors, aligned to the Unix epoch. A member is up in a
+// bucket when all of its checks there are up, and members with no check in a
+// bucket are left out of it, so monitors with shorter intervals, paused
+// monitors and monitors added mid-range don't count as down. Buckets with no
+// checks at all are NoDataBuckets; UptimePct is nil when every bucket is.
+type GroupUptime struct {
+	GroupID       int64     `json:"group_id"`
+	Mode          string    `json:"mode"` // all, any
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	Monitors      int64     `json:"monitors"`
+	BucketSeconds int64     `json:"bucket_seconds"`
+	UpBuckets     int64     `json:"up_buckets"`
+	DownBuckets   int64     `json:"down_buckets"`
+	NoDataBuckets int64     `json:"no_data_buckets"`
+	UptimePct     *float64  `json:"uptime_pct"`
+}
+
 // MonitorListFilter holds filter parameters for listing monitors.
 type MonitorListFilter struct {
 	Type    string
 	Search  string
 	GroupID *int64
-	TagID   *int64
 	Status  string // up, down, degraded, paused
+	Owner   string
 	Sort    string // name, status, last_check, response_time
+	Scope   *MonitorScope
+
+	// TagIDs keeps monitors carrying all of the tags, or any of them when
+	// TagMatch is TagMatchAny.
+	TagIDs   []int64
+	TagMatch string
+}
+
+// Tag match modes of MonitorListFilter.TagMatch.
+const (
+	TagMatchAll = "all"
+	TagMatchAny = "any"
+)
+
+// MonitorScope limits a scoped API key to the monitors in one of GroupIDs or
+// carrying one of TagIDs.
+type MonitorScope struct {
+	GroupIDs []int64
+	TagIDs   []int64
+}
+
+// Allows reports whether a monitor in groupID with the given tags is in
+// scope.
+func (s *MonitorScope) Allows(groupID *int64, tagIDs []int64) bool {
+	if groupID != nil && slices.Contains(s.GroupIDs, *groupID) {
+		return true
+	}
+	for _, id := range tagIDs {
+		if slices.Contains(s.TagIDs, id) {
+			return true
+		}
+	}
+	return false
 }
 
 // AuditLogFilter holds filter parameters for listing audit log entries.
@@ -353,37 +890,66 @@ type RequestLogFilter struct {
 	To         time.Time
 }
 
-// TimeSeriesPoint is a single data point for response time charts.
+// TimeSeriesPoint is a single data point for response time charts. A
+// downsampled point covers the checks of one bucket; a bucket without checks
+// has Checks 0 and an empty Status.
 type TimeSeriesPoint struct {
 	Timestamp    int64  `json:"ts"`
 	ResponseTime int64  `json:"rt"`
 	Status       string `json:"s"`
+	Checks       int64  `json:"n"`
 }
 
 // StatusPage represents a public status page with its own slug and monitor set.
 type StatusPage struct {
-	ID              int64     `json:"id"`
-	Slug            string    `json:"slug"`
-	Title           string    `json:"title"`
-	Description     string    `json:"description"`
-	CustomCSS       string    `json:"custom_css"`
-	ShowIncidents   bool      `json:"show_incidents"`
-	Enabled         bool      `json:"enabled"`
-	APIEnabled      bool      `json:"api_enabled"`
-	SortOrder       int       `json:"sort_order"`
-	LogoURL         string    `json:"logo_url"`
-	FaviconURL      string    `json:"favicon_url"`
-	CustomHeaderHTML string   `json:"custom_header_html"`
-	PasswordHash    string    `json:"-"`
-	PasswordEnabled bool      `json:"password_enabled"`
-	AnalyticsScript string    `json:"analytics_script"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID               int64  `json:"id"`
+	Slug             string `json:"slug"`
+	Title            string `json:"title"`
+	Description      string `json:"description"`
+	CustomCSS        string `json:"custom_css"`
+	ShowIncidents    bool   `json:"show_incidents"`
+	Enabled          bool   `json:"enabled"`
+	APIEnabled       bool   `json:"api_enabled"`
+	SortOrder        int    `json:"sort_order"`
+	LogoURL          string `json:"logo_url"`
+	FaviconURL       string `json:"favicon_url"`
+	CustomHeaderHTML string `json:"custom_header_html"`
+	PasswordHash     string `json:"-"`
+	PasswordEnabled  bool   `json:"password_enabled"`
+	AnalyticsScript  string `json:"analytics_script"`
+	// APITokenHash is the SHA-256 of the bearer token the public JSON API
+	// requires. Empty leaves the API open to everyone.
+	APITokenHash    string `json:"-"`
+	APITokenEnabled bool   `json:"api_token_enabled"`
+	// AnnouncementHTML is shown as a banner at the top of the public page
+	// while AnnouncementEnabled is set and now falls within the optional
+	// AnnouncementStart/AnnouncementEnd window.
+	AnnouncementHTML    string     `json:"announcement_html"`
+	AnnouncementEnabled bool       `json:"announcement_enabled"`
+	AnnouncementStart   *time.Time `json:"announcement_start,omitempty"`
+	AnnouncementEnd     *time.Time `json:"announcement_end,omitempty"`
+	// SubscriberChannelID names the email channel whose SMTP settings send
+	// incident updates to the page's subscribers. Nil turns subscriptions off.
+	SubscriberChannelID *int64    `json:"subscriber_channel_id,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 
 	// Transient fields
 	MonitorCount int `json:"monitor_count,omitempty"`
 }
 
+// StatusPageSubscriber is an email address that receives incident updates for
+// a status page once it has confirmed its subscription.
+type StatusPageSubscriber struct {
+	ID          int64      `json:"id"`
+	PageID      int64      `json:"page_id"`
+	Email       string     `json:"email"`
+	Token       string     `json:"-"`
+	Confirmed   bool       `json:"confirmed"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+}
+
 // StatusPageMonitor links a monitor to a status page with display options.
 type StatusPageMonitor struct {
 	PageID    int64  `json:"page_id"`
@@ -392,6 +958,35 @@ type StatusPageMonitor struct {
 	GroupName string `json:"group_name"`
 }
 
+// StatusPageComponent is a public-facing service on a status page made up of
+// one or more monitors. Its status is the worst status of its monitors.
+type StatusPageComponent struct {
+	ID          int64                        `json:"id"`
+	PageID      int64                        `json:"page_id"`
+	Name        string                       `json:"name"`
+	Description string                       `json:"description,omitempty"`
+	SortOrder   int                          `json:"sort_order"`
+	Monitors    []StatusPageComponentMonitor `json:"monitors"`
+}
+
+// StatusPageComponentMonitor links a monitor to a component. Weight controls
+// how much the monitor contributes to the component's uptime.
+type StatusPageComponentMonitor struct {
+	MonitorID int64 `json:"monitor_id"`
+	Weight    int   `json:"weight"`
+}
+
+// LatencyBaseline is the expected response time of a monitor for one UTC
+// hour of the day, derived from its successful checks.
+type LatencyBaseline struct {
+	MonitorID int64     `json:"monitor_id"`
+	Hour      int       `json:"hour"` // 0-23, UTC
+	Mean      float64   `json:"mean_ms"`
+	StdDev    float64   `json:"stddev_ms"`
+	Samples   int64     `json:"samples"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // DailyUptime holds uptime statistics for a single day.
 type DailyUptime struct {
 	Date        string  `json:"date"`
@@ -421,6 +1016,205 @@ type Proxy struct {
 	Enabled   bool      `json:"enabled"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Health from the proxy health check. AutoDisabled is set when the check
+	// disabled the proxy, so that it may enable it again.
+	LastCheckAt  *time.Time `json:"last_check_at,omitempty"`
+	LastOKAt     *time.Time `json:"last_ok_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	ConsecFails  int        `json:"consec_fails"`
+	AutoDisabled bool       `json:"auto_disabled"`
+}
+
+// Probe is a vantage point a monitor is checked from. A local probe runs
+// the check in this process, optionally bound to SourceAddr; an agent probe
+// forwards it to another Asura instance at URL.
+type Probe struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"` // local, agent
+	SourceAddr string    `json:"source_addr,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	Token      string    `json:"-"` // API key sent to the agent
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ProbeUptime summarises a monitor's checks from one probe over a period.
+// ProbeID is nil for checks run without probes.
+type ProbeUptime struct {
+	ProbeID      *int64     `json:"probe_id"`
+	ProbeName    string     `json:"probe_name"`
+	Total        int64      `json:"total"`
+	Up           int64      `json:"up"`
+	Down         int64      `json:"down"`
+	Degraded     int64      `json:"degraded"`
+	Uptime       float64    `json:"uptime"`
+	LastStatus   string     `json:"last_status,omitempty"`
+	LastCheckAt  *time.Time `json:"last_check_at,omitempty"`
+	ResponseTime int64      `json:"response_time"` // of the last check
+}
+
+// SLAReport summarises a monitor's availability between From and To.
+// Downtime is the union of its incident spans within MeasuredFrom and
+// MeasuredTo: the part of the range after the monitor was created and before
+// now. NoData is set, and UptimePct left nil, when that part is empty or
+// holds no checks and no incidents.
+type SLAReport struct {
+	MonitorID              int64     `json:"monitor_id"`
+	From                   time.Time `json:"from"`
+	To                     time.Time `json:"to"`
+	MeasuredFrom           time.Time `json:"measured_from"`
+	MeasuredTo             time.Time `json:"measured_to"`
+	NoData                 bool      `json:"no_data"`
+	UptimePct              *float64  `json:"uptime_pct"`
+	DowntimeSeconds        int64     `json:"downtime_seconds"`
+	IncidentCount          int64     `json:"incident_count"`
+	LongestIncidentID      *int64    `json:"longest_incident_id,omitempty"`
+	LongestIncidentSeconds int64     `json:"longest_incident_seconds"`
+	MTTRSeconds            int64     `json:"mttr_seconds"` // mean time to resolve incidents resolved in range
+	TotalChecks            int64     `json:"total_checks"`
+	FailedChecks           int64     `json:"failed_checks"`
+}
+
+// GroupSLAReport combines the SLA reports of a group's monitors over one
+// range. UptimePct averages the monitors' uptime weighted by how long each
+// was measured; the counts and downtime are sums. NoData is set, and
+// UptimePct left nil, when no monitor has data for the range.
+type GroupSLAReport struct {
+	GroupID         int64        `json:"group_id"`
+	From            time.Time    `json:"from"`
+	To              time.Time    `json:"to"`
+	NoData          bool         `json:"no_data"`
+	UptimePct       *float64     `json:"uptime_pct"`
+	DowntimeSeconds int64        `json:"downtime_seconds"`
+	IncidentCount   int64        `json:"incident_count"`
+	TotalChecks     int64        `json:"total_checks"`
+	FailedChecks    int64        `json:"failed_checks"`
+	Monitors        []*SLAReport `json:"monitors"`
+}
+
+// DefaultApdexThresholdMs is the Apdex target time T used when none is given.
+const DefaultApdexThresholdMs = 500
+
+// ApdexReport is a monitor's Apdex score over a time range for target time
+// T = ThresholdMs: up checks answering within T are satisfied, within 4T
+// tolerating and slower ones frustrated. Failed checks (down or degraded)
+// count as frustrated when FailedAsFrustrated is set and are otherwise left
+// out as Excluded. Score is nil when no checks were scored.
+type ApdexReport struct {
+	MonitorID          int64     `json:"monitor_id"`
+	From               time.Time `json:"from"`
+	To                 time.Time `json:"to"`
+	ThresholdMs        int64     `json:"threshold_ms"`
+	FailedAsFrustrated bool      `json:"failed_as_frustrated"`
+	Score              *float64  `json:"score"`
+	Samples            int64     `json:"samples"`
+	Satisfied          int64     `json:"satisfied"`
+	Tolerating         int64     `json:"tolerating"`
+	Frustrated         int64     `json:"frustrated"`
+	Excluded           int64     `json:"excluded"`
+}
+
+// EscalationPolicy notifies further channels while an incident stays open
+// and unacknowledged. Steps run in order, each once its delay since the
+// incident started has elapsed.
+type EscalationPolicy struct {
+	ID        int64            `json:"id"`
+	Name      string           `json:"name"`
+	Steps     []EscalationStep `json:"steps"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// EscalationStep is one tier of an escalation policy.
+type EscalationStep struct {
+	DelayMinutes int     `json:"delay_minutes"`
+	ChannelIDs   []int64 `json:"channel_ids"`
+}
+
+// PendingEscalation is an open incident whose monitor has an escalation
+// policy, with the number of steps already escalated.
+type PendingEscalation struct {
+	IncidentID int64
+	MonitorID  int64
+	PolicyID   int64
+	Escalated  int
+}
+
+// OncallSchedule rotates through Members, handing over every ShiftHours
+// from RotationStart. Shift boundaries follow the wall clock of Timezone, so
+// a daily 09:00 handoff stays at 09:00 across DST changes.
+type OncallSchedule struct {
+	ID            int64            `json:"id"`
+	Name          string           `json:"name"`
+	Timezone      string           `json:"timezone,omitempty"` // IANA name, empty = UTC
+	RotationStart string           `json:"rotation_start"`     // first handoff, local "2006-01-02T15:04"
+	ShiftHours    int              `json:"shift_hours"`
+	Members       []OncallMember   `json:"members"`
+	Overrides     []OncallOverride `json:"overrides,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// OncallRotationLayout is the format of OncallSchedule.RotationStart.
+const OncallRotationLayout = "2006-01-02T15:04"
+
+// OncallMember is a responder. Email addresses email channels and names the
+// Opsgenie user; RoutingKey, when set, replaces a PagerDuty channel's key.
+type OncallMember struct {
+	Name       string `json:"name"`
+	Email      string `json:"email,omitempty"`
+	RoutingKey string `json:"routing_key,omitempty"`
+}
+
+// OncallOverride puts Member on call from Start until End in place of the
+// rotation, e.g. to cover someone's shift.
+type OncallOverride struct {
+	Start  time.Time    `json:"start"`
+	End    time.Time    `json:"end"`
+	Member OncallMember `json:"member"`
+}
+
+// Location returns the schedule's timezone, falling back to UTC when it is
+// empty or unknown.
+func (s *OncallSchedule) Location() *time.Location {
+	if s.Timezone != "" {
+		if loc, err := time.LoadLocation(s.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// ResponderAt returns who is on call at t: the last override covering t,
+// otherwise the member whose shift contains t. It returns nil before the
+// rotation starts or when nobody is configured.
+func (s *OncallSchedule) ResponderAt(t time.Time) *OncallMember {
+	for i := len(s.Overrides) - 1; i >= 0; i-- {
+		o := s.Overrides[i]
+		if !t.Before(o.Start) && t.Before(o.End) {
+			m := o.Member
+			return &m
+		}
+	}
+	if len(s.Members) == 0 || s.ShiftHours <= 0 {
+		return nil
+	}
+	// Both sides are compared as wall clock times, read as if in UTC.
+	start, err := time.Parse(OncallRotationLayout, s.RotationStart)
+	if err != nil {
+		return nil
+	}
+	lt := t.In(s.Location())
+	wall := time.Date(lt.Year(), lt.Month(), lt.Day(), lt.Hour(), lt.Minute(), lt.Second(), lt.Nanosecond(), time.UTC)
+	if wall.Before(start) {
+		return nil
+	}
+	shift := int64(wall.Sub(start) / (time.Duration(s.ShiftHours) * time.Hour))
+	m := s.Members[shift%int64(len(s.Members))]
+	return &m
 }
 
 // Session represents a server-side web UI session.
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"runtime"
+
+	"github.com/lib/pq"
+)
+
+// PostgresStore implements Store using PostgreSQL. It shares its queries with
+// SQLiteStore; the connection translates them (see pgRewrite) and the methods
+// below replace the ones that touch SQLite files or pragmas.
+type PostgresStore struct {
+	*SQLiteStore
+}
+
+// NewPostgresStore connects to dsn with a single pool for reads and writes.
+func NewPostgresStore(dsn string, maxConns int) (*PostgresStore, error) {
+	if maxConns <= 0 {
+		maxConns = runtime.NumCPU()
+	}
+
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse dsn: %w", err)
+	}
+	db := sql.OpenDB(pgConnector{inner: connector})
+	db.SetMaxOpenConns(maxConns)
+	db.SetMaxIdleConns(maxConns)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	if err := runPostgresMigrations(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+
+	return &PostgresStore{SQLiteStore: &SQLiteStore{readDB: db, writeDB: db}}, nil
+}
+
+func (s *PostgresStore) DBSize() (int64, error) {
+	var size int64
+	err := s.readDB.QueryRow("SELECT pg_database_size(current_database())").Scan(&size)
+	return size, err
+}
+
+func (s *PostgresStore) Close() error {
+	var firstErr error
+	if err := s.FlushPendingWrites(context.Background()); err != nil {
+		firstErr = fmt.Errorf("flush pending writes: %w", err)
+	}
+	if err := s.writeDB.Close(); err != nil && firstErr == nil {
+		firstErr = fmt.Errorf("close db: %w", err)
+	}
+	return firstErr
+}
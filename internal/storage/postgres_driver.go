@@ -0,0 +1,275 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pgConnector wraps the lib/pq connector so the queries shared with
+// SQLiteStore run unchanged on PostgreSQL. Every statement goes through
+// pgRewrite before it reaches the server.
+type pgConnector struct {
+	inner driver.Connector
+}
+
+func (c pgConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.inner.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pc, ok := conn.(pqConn)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("postgres driver connection %T lacks context support", conn)
+	}
+	return &pgConn{pqConn: pc}, nil
+}
+
+func (c pgConnector) Driver() driver.Driver {
+	return c.inner.Driver()
+}
+
+// pqConn is the subset of the lib/pq connection that pgConn builds on.
+type pqConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+type pqStmt interface {
+	driver.Stmt
+	driver.StmtExecContext
+	driver.StmtQueryContext
+}
+
+type pgConn struct {
+	pqConn
+}
+
+func (c *pgConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *pgConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	q := pgRewrite(query)
+	st, err := c.pqConn.PrepareContext(ctx, q.sql)
+	if err != nil {
+		return nil, err
+	}
+	ps, ok := st.(pqStmt)
+	if !ok {
+		st.Close()
+		return nil, fmt.Errorf("postgres driver statement %T lacks context support", st)
+	}
+	return &pgStmt{pqStmt: ps, returningID: q.returningID}, nil
+}
+
+func (c *pgConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	q := pgRewrite(query)
+	if q.returningID {
+		rows, err := c.pqConn.QueryContext(ctx, q.sql, pgArgs(args))
+		if err != nil {
+			return nil, err
+		}
+		return pgInsertResult(rows)
+	}
+	return c.pqConn.ExecContext(ctx, q.sql, pgArgs(args))
+}
+
+func (c *pgConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.pqConn.QueryContext(ctx, pgRewrite(query).sql, pgArgs(args))
+}
+
+type pgStmt struct {
+	pqStmt
+	returningID bool
+}
+
+func (s *pgStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if s.returningID {
+		rows, err := s.pqStmt.QueryContext(ctx, pgArgs(args))
+		if err != nil {
+			return nil, err
+		}
+		return pgInsertResult(rows)
+	}
+	return s.pqStmt.ExecContext(ctx, pgArgs(args))
+}
+
+func (s *pgStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.pqStmt.QueryContext(ctx, pgArgs(args))
+}
+
+// pgArgs converts the argument types the SQLite driver accepts but Postgres
+// would reject for the shared schema: bools go into INTEGER flag columns and
+// byte slices (json.RawMessage) into TEXT columns.
+func pgArgs(args []driver.NamedValue) []driver.NamedValue {
+	out := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		switch v := a.Value.(type) {
+		case bool:
+			a.Value = int64(boolToInt(v))
+		case []byte:
+			a.Value = string(v)
+		}
+		out[i] = a
+	}
+	return out
+}
+
+// pgResult carries the id from INSERT ... RETURNING id, since Postgres has
+// no equivalent of SQLite's last_insert_rowid.
+type pgResult struct {
+	id   int64
+	rows int64
+}
+
+func (r pgResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r pgResult) RowsAffected() (int64, error) { return r.rows, nil }
+
+// pgInsertResult drains the rows of an INSERT ... RETURNING id. Like SQLite,
+// a multi-row insert reports the id of the last row.
+func pgInsertResult(rows driver.Rows) (driver.Result, error) {
+	defer rows.Close()
+	var res pgResult
+	dest := make([]driver.Value, len(rows.Columns()))
+	for {
+		err := rows.Next(dest)
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if id, ok := dest[0].(int64); ok {
+			res.id = id
+		}
+		res.rows++
+	}
+}
+
+type pgQuery struct {
+	sql         string
+	returningID bool
+}
+
+var (
+	pgInsertOr      = regexp.MustCompile(`(?i)^INSERT\s+OR\s+(IGNORE|REPLACE)\s+INTO\b`)
+	pgInsertInto    = regexp.MustCompile(`(?i)^INSERT\s+INTO\s+(\w+)\s*(?:\(([^)]*)\))?`)
+	pgReturning     = regexp.MustCompile(`(?i)\bRETURNING\b`)
+	pgUnixEpoch     = regexp.MustCompile(`(?i)CAST\(strftime\('%s',\s*([\w.]+)\)\s+AS\s+INTEGER\)`)
+	pgCollateNoCase = regexp.MustCompile(`(?i)([\w.]+)\s+COLLATE\s+NOCASE\b`)
+	pgLike          = regexp.MustCompile(`(?i)\bLIKE\b`)
+	pgDate          = regexp.MustCompile(`(?i)\bDATE\(([\w.]+)\)`)
+	pgSerialTable   = regexp.MustCompile(`CREATE TABLE IF NOT EXISTS (\w+) \(\s*id\s+BIGSERIAL`)
+)
+
+// pgConflictKeys is the unique key each INSERT OR REPLACE target is upserted
+// on. Postgres needs the conflict target spelled out.
+var pgConflictKeys = map[string][]string{
+	"request_log_rollups":            {"date", "route_group", "monitor_id"},
+	"daily_uptime":                   {"monitor_id", "date"},
+	"status_page_component_monitors": {"component_id", "monitor_id"},
+}
+
+// pgSerialTables holds the tables with a BIGSERIAL id. Inserts into them get
+// RETURNING id so LastInsertId works.
+var pgSerialTables = func() map[string]bool {
+	tables := make(map[string]bool)
+	for _, m := range pgSerialTable.FindAllStringSubmatch(postgresSchema, -1) {
+		tables[m[1]] = true
+	}
+	return tables
+}()
+
+// pgRewrite translates a query written for SQLite into Postgres: numbered
+// placeholders, ON CONFLICT for INSERT OR IGNORE/REPLACE, LOWER() for
+// COLLATE NOCASE, ILIKE for LIKE (which SQLite matches case-insensitively),
+// and the strftime/DATE forms used on the TEXT timestamp columns.
+func pgRewrite(query string) pgQuery {
+	q := strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+
+	var suffix string
+	if m := pgInsertOr.FindStringSubmatch(q); m != nil {
+		q = pgInsertOr.ReplaceAllString(q, "INSERT INTO")
+		suffix = " ON CONFLICT DO NOTHING"
+		if strings.EqualFold(m[1], "REPLACE") {
+			// Unknown tables keep no conflict target and fail loudly.
+			suffix = " ON CONFLICT DO UPDATE"
+			if t := pgInsertInto.FindStringSubmatch(q); t != nil && pgConflictKeys[t[1]] != nil {
+				suffix = " " + pgUpsertClause(pgConflictKeys[t[1]], strings.Split(t[2], ","))
+			}
+		}
+	}
+
+	q = pgUnixEpoch.ReplaceAllString(q, "CAST(EXTRACT(EPOCH FROM CAST(${1} AS TIMESTAMPTZ)) AS BIGINT)")
+
+	var b strings.Builder
+	n := 0
+	for q != "" {
+		end := strings.IndexByte(q, '\'')
+		code := q
+		if end >= 0 {
+			code = q[:end]
+		}
+		code = pgCollateNoCase.ReplaceAllString(code, "LOWER(${1})")
+		code = pgLike.ReplaceAllString(code, "ILIKE")
+		code = pgDate.ReplaceAllString(code, "substr(${1}, 1, 10)")
+		for _, r := range code {
+			if r == '?' {
+				n++
+				b.WriteString("$" + strconv.Itoa(n))
+				continue
+			}
+			b.WriteRune(r)
+		}
+		if end < 0 {
+			break
+		}
+		// Copy the string literal verbatim. A doubled quote inside it
+		// splits into two adjacent literals, which is equivalent.
+		closing := strings.IndexByte(q[end+1:], '\'')
+		if closing < 0 {
+			b.WriteString(q[end:])
+			break
+		}
+		b.WriteString(q[end : end+closing+2])
+		q = q[end+closing+2:]
+	}
+	out := b.String() + suffix
+
+	m := pgInsertInto.FindStringSubmatch(out)
+	if m == nil || !pgSerialTables[m[1]] || pgReturning.MatchString(out) {
+		return pgQuery{sql: out}
+	}
+	return pgQuery{sql: out + " RETURNING id", returningID: true}
+}
+
+func pgUpsertClause(keys, columns []string) string {
+	isKey := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		isKey[k] = true
+	}
+	var sets []string
+	for _, c := range columns {
+		c = strings.TrimSpace(c)
+		if !isKey[c] {
+			sets = append(sets, c+"=EXCLUDED."+c)
+		}
+	}
+	target := "ON CONFLICT (" + strings.Join(keys, ", ") + ")"
+	if len(sets) == 0 {
+		return target + " DO NOTHING"
+	}
+	return target + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
@@ -0,0 +1,88 @@
+package storage
+
+import "testing"
+
+func TestPgRewrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		want      string
+		returning bool
+	}{
+		{
+			name:  "placeholders",
+			query: "SELECT id FROM monitors WHERE id=? AND owner=?",
+			want:  "SELECT id FROM monitors WHERE id=$1 AND owner=$2",
+		},
+		{
+			name:  "placeholder inside literal untouched",
+			query: "SELECT id FROM monitors WHERE name='what?' AND id=?",
+			want:  "SELECT id FROM monitors WHERE name='what?' AND id=$1",
+		},
+		{
+			name:      "insert into serial table returns id",
+			query:     "INSERT INTO tags (name, color) VALUES (?, ?)",
+			want:      "INSERT INTO tags (name, color) VALUES ($1, $2) RETURNING id",
+			returning: true,
+		},
+		{
+			name:  "insert into keyed table",
+			query: "INSERT INTO monitor_tags (monitor_id, tag_id, value) VALUES (?, ?, ?)",
+			want:  "INSERT INTO monitor_tags (monitor_id, tag_id, value) VALUES ($1, $2, $3)",
+		},
+		{
+			name: "upsert kept as is",
+			query: `INSERT INTO monitor_escalation_policies (monitor_id, policy_id) VALUES (?, ?)
+		 ON CONFLICT(monitor_id) DO UPDATE SET policy_id=excluded.policy_id`,
+			want: `INSERT INTO monitor_escalation_policies (monitor_id, policy_id) VALUES ($1, $2)
+		 ON CONFLICT(monitor_id) DO UPDATE SET policy_id=excluded.policy_id`,
+		},
+		{
+			name:  "insert or ignore",
+			query: "INSERT OR IGNORE INTO check_configs (monitor_id, hash, config, created_at) VALUES (?, ?, ?, ?)",
+			want:  "INSERT INTO check_configs (monitor_id, hash, config, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING",
+		},
+		{
+			name:  "insert or replace",
+			query: "INSERT OR REPLACE INTO status_page_component_monitors (component_id, monitor_id, weight) VALUES (?, ?, ?)",
+			want:  "INSERT INTO status_page_component_monitors (component_id, monitor_id, weight) VALUES ($1, $2, $3) ON CONFLICT (component_id, monitor_id) DO UPDATE SET weight=EXCLUDED.weight",
+		},
+		{
+			name:      "insert or replace from select",
+			query:     "INSERT OR REPLACE INTO request_log_rollups (date, route_group, monitor_id, requests)\n SELECT ? AS date, route_group, monitor_id, COUNT(*) FROM request_logs GROUP BY route_group, monitor_id",
+			want:      "INSERT INTO request_log_rollups (date, route_group, monitor_id, requests)\n SELECT $1 AS date, route_group, monitor_id, COUNT(*) FROM request_logs GROUP BY route_group, monitor_id ON CONFLICT (date, route_group, monitor_id) DO UPDATE SET requests=EXCLUDED.requests RETURNING id",
+			returning: true,
+		},
+		{
+			name:  "collate nocase",
+			query: "SELECT id FROM tags ORDER BY t.name COLLATE NOCASE ASC",
+			want:  "SELECT id FROM tags ORDER BY LOWER(t.name) ASC",
+		},
+		{
+			name:  "like is case-insensitive",
+			query: "SELECT id FROM monitors WHERE m.name LIKE '%' || ? || '%'",
+			want:  "SELECT id FROM monitors WHERE m.name ILIKE '%' || $1 || '%'",
+		},
+		{
+			name:  "unix epoch",
+			query: "SELECT 1 FROM check_results GROUP BY (CAST(strftime('%s', created_at) AS INTEGER) / ?)",
+			want:  "SELECT 1 FROM check_results GROUP BY (CAST(EXTRACT(EPOCH FROM CAST(created_at AS TIMESTAMPTZ)) AS BIGINT) / $1)",
+		},
+		{
+			name:  "date of timestamp",
+			query: "SELECT DATE(created_at) AS day FROM check_results GROUP BY DATE(created_at)",
+			want:  "SELECT substr(created_at, 1, 10) AS day FROM check_results GROUP BY substr(created_at, 1, 10)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pgRewrite(tt.query)
+			if got.sql != tt.want {
+				t.Errorf("sql:\n got  %q\n want %q", got.sql, tt.want)
+			}
+			if got.returningID != tt.returning {
+				t.Errorf("returningID = %v, want %v", got.returningID, tt.returning)
+			}
+		})
+	}
+}
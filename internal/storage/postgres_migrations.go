@@ -0,0 +1,691 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// postgresSchema is schema translated to PostgreSQL at schemaVersion.
+// Timestamps stay TEXT in timeFormat so queries and scans are shared with
+// SQLite, and boolean flags stay integers.
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS schema_version (
+	version BIGINT  NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS monitor_groups (
+	id         BIGSERIAL PRIMARY KEY,
+	name       TEXT    NOT NULL,
+	sort_order BIGINT  NOT NULL DEFAULT 0,
+	created_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS proxies (
+	id         BIGSERIAL PRIMARY KEY,
+	name       TEXT    NOT NULL,
+	protocol   TEXT    NOT NULL DEFAULT 'http',
+	host       TEXT    NOT NULL,
+	port       BIGINT  NOT NULL,
+	auth_user  TEXT    NOT NULL DEFAULT '',
+	auth_pass  TEXT    NOT NULL DEFAULT '',
+	enabled    BIGINT  NOT NULL DEFAULT 1,
+	last_check_at TEXT,
+	last_ok_at    TEXT,
+	last_error    TEXT    NOT NULL DEFAULT '',
+	consec_fails  BIGINT  NOT NULL DEFAULT 0,
+	auto_disabled BIGINT  NOT NULL DEFAULT 0,
+	created_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS notification_channels (
+	id         BIGSERIAL PRIMARY KEY,
+	name       TEXT    NOT NULL,
+	type       TEXT    NOT NULL,
+	enabled    BIGINT  NOT NULL DEFAULT 1,
+	settings   TEXT    NOT NULL DEFAULT '{}',
+	events     TEXT    NOT NULL DEFAULT '[]',
+	schedule   TEXT    NOT NULL DEFAULT '',
+	oncall_schedule_id BIGINT,
+	digest_window_seconds   BIGINT NOT NULL DEFAULT 0,
+	digest_flush_on_resolve BIGINT NOT NULL DEFAULT 0,
+	created_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS monitors (
+	id              BIGSERIAL PRIMARY KEY,
+	name            TEXT    NOT NULL,
+	description     TEXT    NOT NULL DEFAULT '',
+	type            TEXT    NOT NULL,
+	target          TEXT    NOT NULL,
+	interval_secs   BIGINT  NOT NULL DEFAULT 60,
+	timeout_secs    BIGINT  NOT NULL DEFAULT 10,
+	enabled         BIGINT  NOT NULL DEFAULT 1,
+	tags            TEXT    NOT NULL DEFAULT '[]',
+	settings        TEXT    NOT NULL DEFAULT '{}',
+	assertions      TEXT    NOT NULL DEFAULT '[]',
+	track_changes   BIGINT  NOT NULL DEFAULT 0,
+	failure_threshold BIGINT  NOT NULL DEFAULT 3,
+	success_threshold BIGINT  NOT NULL DEFAULT 1,
+	upside_down     BIGINT  NOT NULL DEFAULT 0,
+	resend_interval BIGINT  NOT NULL DEFAULT 0,
+	group_id        BIGINT  DEFAULT NULL,
+	proxy_id        BIGINT  DEFAULT NULL REFERENCES proxies(id) ON DELETE SET NULL,
+	ack_silences_reminders BIGINT  DEFAULT NULL,
+	latency_baseline_sigma DOUBLE PRECISION NOT NULL DEFAULT 0,
+	skip_default_channel BIGINT  NOT NULL DEFAULT 0,
+	capture_failure_context BIGINT  NOT NULL DEFAULT 0,
+	owner           TEXT    NOT NULL DEFAULT '',
+	stream_checks_every BIGINT  NOT NULL DEFAULT 0,
+	retries         BIGINT  NOT NULL DEFAULT 0,
+	retry_interval  BIGINT  NOT NULL DEFAULT 0,
+	redact_patterns TEXT    NOT NULL DEFAULT '[]',
+	degraded_threshold BIGINT  NOT NULL DEFAULT 0,
+	flap_window_seconds BIGINT  NOT NULL DEFAULT 0,
+	parent_id       BIGINT  DEFAULT NULL REFERENCES monitors(id) ON DELETE SET NULL,
+	connect_timeout_secs BIGINT  NOT NULL DEFAULT 0,
+	max_response_time_ms BIGINT  NOT NULL DEFAULT 0,
+	warmup_seconds  BIGINT  NOT NULL DEFAULT 0,
+	created_at      TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at      TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_monitors_group_id ON monitors(group_id);
+CREATE INDEX IF NOT EXISTS idx_monitors_owner ON monitors(owner);
+
+CREATE TABLE IF NOT EXISTS monitor_notifications (
+	monitor_id BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	channel_id BIGINT  NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+	event_filter TEXT  NOT NULL DEFAULT '',
+	PRIMARY KEY (monitor_id, channel_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_monitor_notif_channel ON monitor_notifications(channel_id);
+
+CREATE TABLE IF NOT EXISTS latency_baselines (
+	monitor_id BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	hour       BIGINT  NOT NULL,
+	mean_ms    DOUBLE PRECISION NOT NULL,
+	stddev_ms  DOUBLE PRECISION NOT NULL,
+	samples    BIGINT  NOT NULL,
+	updated_at TEXT    NOT NULL,
+	PRIMARY KEY (monitor_id, hour)
+);
+
+CREATE TABLE IF NOT EXISTS monitor_status (
+	monitor_id             BIGINT  PRIMARY KEY REFERENCES monitors(id) ON DELETE CASCADE,
+	status                 TEXT    NOT NULL DEFAULT 'pending',
+	last_check_at          TEXT,
+	consec_fails           BIGINT  NOT NULL DEFAULT 0,
+	consec_successes       BIGINT  NOT NULL DEFAULT 0,
+	consec_degraded        BIGINT  NOT NULL DEFAULT 0,
+	last_body_hash         TEXT    NOT NULL DEFAULT '',
+	last_cert_fingerprint  TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS check_results (
+	id               BIGSERIAL PRIMARY KEY,
+	monitor_id       BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	status           TEXT    NOT NULL,
+	response_time    BIGINT  NOT NULL DEFAULT 0,
+	status_code      BIGINT  NOT NULL DEFAULT 0,
+	message          TEXT    NOT NULL DEFAULT '',
+	headers          TEXT    NOT NULL DEFAULT '',
+	body             TEXT    NOT NULL DEFAULT '',
+	body_hash        TEXT    NOT NULL DEFAULT '',
+	cert_expiry      TEXT,
+	cert_fingerprint TEXT    NOT NULL DEFAULT '',
+	dns_records      TEXT    NOT NULL DEFAULT '',
+	config_hash      TEXT    NOT NULL DEFAULT '',
+	failure_context  TEXT    NOT NULL DEFAULT '',
+	probe_id         BIGINT  DEFAULT NULL,
+	created_at       TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_check_results_monitor_id ON check_results(monitor_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS check_configs (
+	monitor_id BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	hash       TEXT    NOT NULL,
+	config     TEXT    NOT NULL,
+	created_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	PRIMARY KEY (monitor_id, hash)
+);
+
+CREATE TABLE IF NOT EXISTS incidents (
+	id              BIGSERIAL PRIMARY KEY,
+	monitor_id      BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	status          TEXT    NOT NULL DEFAULT 'open',
+	cause           TEXT    NOT NULL DEFAULT '',
+	started_at      TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	acknowledged_at TEXT,
+	acknowledged_by TEXT    NOT NULL DEFAULT '',
+	resolved_at     TEXT,
+	resolved_by     TEXT    NOT NULL DEFAULT '',
+	ack_deadline    TEXT,
+	postmortem      TEXT    NOT NULL DEFAULT '',
+	severity        TEXT    NOT NULL DEFAULT 'critical'
+);
+
+CREATE INDEX IF NOT EXISTS idx_incidents_monitor_id ON incidents(monitor_id, status);
+
+CREATE TABLE IF NOT EXISTS incident_events (
+	id          BIGSERIAL PRIMARY KEY,
+	incident_id BIGINT  NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+	type        TEXT    NOT NULL,
+	message     TEXT    NOT NULL DEFAULT '',
+	author      TEXT    NOT NULL DEFAULT '',
+	created_at  TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_events_incident_id ON incident_events(incident_id);
+
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+	id          BIGSERIAL PRIMARY KEY,
+	name        TEXT    NOT NULL,
+	monitor_ids TEXT    NOT NULL DEFAULT '[]',
+	start_time  TEXT    NOT NULL,
+	end_time    TEXT    NOT NULL,
+	recurring   TEXT    NOT NULL DEFAULT '',
+	timezone    TEXT    NOT NULL DEFAULT '',
+	cron_expr   TEXT    NOT NULL DEFAULT '',
+	duration_minutes BIGINT NOT NULL DEFAULT 0,
+	created_at  TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at  TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS content_changes (
+	id         BIGSERIAL PRIMARY KEY,
+	monitor_id BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	old_hash   TEXT    NOT NULL DEFAULT '',
+	new_hash   TEXT    NOT NULL,
+	diff       TEXT    NOT NULL DEFAULT '',
+	old_body   TEXT    NOT NULL DEFAULT '',
+	new_body   TEXT    NOT NULL DEFAULT '',
+	created_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_changes_monitor_id ON content_changes(monitor_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+	id           BIGSERIAL PRIMARY KEY,
+	action       TEXT    NOT NULL,
+	entity       TEXT    NOT NULL,
+	entity_id    BIGINT  NOT NULL DEFAULT 0,
+	api_key_name TEXT    NOT NULL DEFAULT '',
+	detail       TEXT    NOT NULL DEFAULT '',
+	created_at   TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS heartbeats (
+	id          BIGSERIAL PRIMARY KEY,
+	monitor_id  BIGINT  NOT NULL UNIQUE REFERENCES monitors(id) ON DELETE CASCADE,
+	token       TEXT    NOT NULL UNIQUE,
+	grace       BIGINT  NOT NULL DEFAULT 0,
+	last_ping_at TEXT,
+	status      TEXT    NOT NULL DEFAULT 'pending'
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+	id           BIGSERIAL PRIMARY KEY,
+	token_hash   TEXT    NOT NULL UNIQUE,
+	api_key_name TEXT    NOT NULL,
+	key_hash     TEXT    NOT NULL DEFAULT '',
+	ip_address   TEXT    NOT NULL DEFAULT '',
+	created_at   TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	expires_at   TEXT    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+
+CREATE TABLE IF NOT EXISTS request_logs (
+	id             BIGSERIAL PRIMARY KEY,
+	method         TEXT    NOT NULL,
+	path           TEXT    NOT NULL,
+	status_code    BIGINT  NOT NULL,
+	latency_ms     BIGINT  NOT NULL,
+	client_ip      TEXT    NOT NULL,
+	user_agent     TEXT    NOT NULL DEFAULT '',
+	referer        TEXT    NOT NULL DEFAULT '',
+	monitor_id     BIGINT  DEFAULT NULL,
+	route_group    TEXT    NOT NULL DEFAULT '',
+	created_at     TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_request_logs_created ON request_logs(created_at);
+CREATE INDEX IF NOT EXISTS idx_request_logs_monitor ON request_logs(monitor_id, created_at) WHERE monitor_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_request_logs_group   ON request_logs(route_group, created_at);
+
+CREATE TABLE IF NOT EXISTS request_log_rollups (
+	id              BIGSERIAL PRIMARY KEY,
+	date            TEXT    NOT NULL,
+	route_group     TEXT    NOT NULL DEFAULT '',
+	monitor_id      BIGINT  DEFAULT NULL,
+	requests        BIGINT  NOT NULL DEFAULT 0,
+	unique_visitors BIGINT  NOT NULL DEFAULT 0,
+	avg_latency_ms  BIGINT  NOT NULL DEFAULT 0,
+	UNIQUE(date, route_group, monitor_id)
+);
+
+CREATE TABLE IF NOT EXISTS daily_uptime (
+	monitor_id   BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	date         TEXT    NOT NULL,
+	total_checks BIGINT  NOT NULL DEFAULT 0,
+	up_checks    BIGINT  NOT NULL DEFAULT 0,
+	down_checks  BIGINT  NOT NULL DEFAULT 0,
+	PRIMARY KEY (monitor_id, date)
+);
+
+CREATE TABLE IF NOT EXISTS status_pages (
+	id                 BIGSERIAL PRIMARY KEY,
+	slug               TEXT    NOT NULL UNIQUE,
+	title              TEXT    NOT NULL DEFAULT 'Service Status',
+	description        TEXT    NOT NULL DEFAULT '',
+	custom_css         TEXT    NOT NULL DEFAULT '',
+	show_incidents     BIGINT  NOT NULL DEFAULT 1,
+	enabled            BIGINT  NOT NULL DEFAULT 0,
+	api_enabled        BIGINT  NOT NULL DEFAULT 0,
+	sort_order         BIGINT  NOT NULL DEFAULT 0,
+	logo_url           TEXT    NOT NULL DEFAULT '',
+	favicon_url        TEXT    NOT NULL DEFAULT '',
+	custom_header_html TEXT    NOT NULL DEFAULT '',
+	password_hash      TEXT    NOT NULL DEFAULT '',
+	api_token_hash     TEXT    NOT NULL DEFAULT '',
+	analytics_script   TEXT    NOT NULL DEFAULT '',
+	announcement_html      TEXT    NOT NULL DEFAULT '',
+	announcement_enabled   BIGINT  NOT NULL DEFAULT 0,
+	announcement_starts_at TEXT,
+	announcement_ends_at   TEXT,
+	subscriber_channel_id  BIGINT,
+	created_at         TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at         TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS status_page_monitors (
+	page_id    BIGINT  NOT NULL REFERENCES status_pages(id) ON DELETE CASCADE,
+	monitor_id BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	sort_order BIGINT  NOT NULL DEFAULT 0,
+	group_name TEXT    NOT NULL DEFAULT '',
+	PRIMARY KEY (page_id, monitor_id)
+);
+
+CREATE TABLE IF NOT EXISTS status_page_components (
+	id          BIGSERIAL PRIMARY KEY,
+	page_id     BIGINT  NOT NULL REFERENCES status_pages(id) ON DELETE CASCADE,
+	name        TEXT    NOT NULL,
+	description TEXT    NOT NULL DEFAULT '',
+	sort_order  BIGINT  NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_page_components_page ON status_page_components(page_id);
+
+CREATE TABLE IF NOT EXISTS status_page_component_monitors (
+	component_id BIGINT  NOT NULL REFERENCES status_page_components(id) ON DELETE CASCADE,
+	monitor_id   BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	weight       BIGINT  NOT NULL DEFAULT 1,
+	PRIMARY KEY (component_id, monitor_id)
+);
+
+CREATE TABLE IF NOT EXISTS status_page_subscribers (
+	id           BIGSERIAL PRIMARY KEY,
+	page_id      BIGINT  NOT NULL REFERENCES status_pages(id) ON DELETE CASCADE,
+	email        TEXT    NOT NULL,
+	token        TEXT    NOT NULL UNIQUE,
+	confirmed    BIGINT  NOT NULL DEFAULT 0,
+	created_at   TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	confirmed_at TEXT,
+	UNIQUE(page_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_check_results_created_at ON check_results(created_at);
+CREATE INDEX IF NOT EXISTS idx_incidents_resolved_at ON incidents(status, resolved_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_request_logs_client_ip ON request_logs(client_ip, created_at);
+CREATE INDEX IF NOT EXISTS idx_check_results_monitor_latest ON check_results(monitor_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS totp_keys (
+	id           BIGSERIAL PRIMARY KEY,
+	api_key_name TEXT NOT NULL UNIQUE,
+	secret       TEXT NOT NULL,
+	created_at   TEXT NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+	id         BIGSERIAL PRIMARY KEY,
+	name       TEXT    NOT NULL UNIQUE,
+	color      TEXT    NOT NULL DEFAULT '#808080',
+	created_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS monitor_tags (
+	monitor_id BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	tag_id     BIGINT  NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	value      TEXT    NOT NULL DEFAULT '',
+	PRIMARY KEY (monitor_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_monitor_tags_tag ON monitor_tags(tag_id);
+
+CREATE TABLE IF NOT EXISTS channel_tags (
+	channel_id BIGINT  NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+	tag_id     BIGINT  NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (channel_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_tags_tag ON channel_tags(tag_id);
+
+CREATE TABLE IF NOT EXISTS notification_history (
+	id          BIGSERIAL PRIMARY KEY,
+	channel_id  BIGINT  NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+	monitor_id  BIGINT,
+	incident_id BIGINT,
+	event_type  TEXT    NOT NULL DEFAULT '',
+	status      TEXT    NOT NULL DEFAULT 'sent',
+	error       TEXT    NOT NULL DEFAULT '',
+	sent_at     TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+CREATE INDEX IF NOT EXISTS idx_notif_history_channel ON notification_history(channel_id, sent_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notif_history_sent_at ON notification_history(sent_at DESC);
+
+CREATE TABLE IF NOT EXISTS escalation_policies (
+	id         BIGSERIAL PRIMARY KEY,
+	name       TEXT    NOT NULL,
+	steps      TEXT    NOT NULL DEFAULT '[]',
+	created_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS monitor_escalation_policies (
+	monitor_id BIGINT  PRIMARY KEY REFERENCES monitors(id) ON DELETE CASCADE,
+	policy_id  BIGINT  NOT NULL REFERENCES escalation_policies(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_escalation_policy ON monitor_escalation_policies(policy_id);
+
+CREATE TABLE IF NOT EXISTS probes (
+	id          BIGSERIAL PRIMARY KEY,
+	name        TEXT    NOT NULL,
+	type        TEXT    NOT NULL DEFAULT 'local',
+	source_addr TEXT    NOT NULL DEFAULT '',
+	url         TEXT    NOT NULL DEFAULT '',
+	token       TEXT    NOT NULL DEFAULT '',
+	enabled     BIGINT  NOT NULL DEFAULT 1,
+	created_at  TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at  TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS monitor_probes (
+	monitor_id BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	probe_id   BIGINT  NOT NULL REFERENCES probes(id) ON DELETE CASCADE,
+	PRIMARY KEY (monitor_id, probe_id)
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_probes_probe ON monitor_probes(probe_id);
+
+CREATE TABLE IF NOT EXISTS oncall_schedules (
+	id             BIGSERIAL PRIMARY KEY,
+	name           TEXT    NOT NULL,
+	timezone       TEXT    NOT NULL DEFAULT '',
+	rotation_start TEXT    NOT NULL,
+	shift_hours    BIGINT  NOT NULL DEFAULT 168,
+	members        TEXT    NOT NULL DEFAULT '[]',
+	overrides      TEXT    NOT NULL DEFAULT '[]',
+	created_at     TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at     TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS monitor_transitions (
+	id          BIGSERIAL PRIMARY KEY,
+	monitor_id  BIGINT NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	from_status TEXT   NOT NULL,
+	to_status   TEXT   NOT NULL,
+	cause       TEXT   NOT NULL DEFAULT '',
+	created_at  TEXT   NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_transitions_monitor ON monitor_transitions(monitor_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS probe_checks (
+	id            BIGSERIAL PRIMARY KEY,
+	monitor_id    BIGINT NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	probe_id      BIGINT NOT NULL REFERENCES probes(id) ON DELETE CASCADE,
+	status        TEXT   NOT NULL,
+	response_time BIGINT NOT NULL DEFAULT 0,
+	message       TEXT   NOT NULL DEFAULT '',
+	created_at    TEXT   NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+CREATE INDEX IF NOT EXISTS idx_probe_checks_monitor ON probe_checks(monitor_id, created_at);
+`
+
+// postgresMigrations upgrade a PostgreSQL database stamped with an older
+// schemaVersion. Each SQLite migration from here on needs a counterpart.
+var postgresMigrations = []struct {
+	version int
+	sql     string
+}{
+	{
+		version: 35,
+		sql: `ALTER TABLE maintenance_windows ADD COLUMN cron_expr TEXT NOT NULL DEFAULT '';
+ALTER TABLE maintenance_windows ADD COLUMN duration_minutes BIGINT NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 36,
+		sql: `CREATE TABLE IF NOT EXISTS probes (
+	id          BIGSERIAL PRIMARY KEY,
+	name        TEXT    NOT NULL,
+	type        TEXT    NOT NULL DEFAULT 'local',
+	source_addr TEXT    NOT NULL DEFAULT '',
+	url         TEXT    NOT NULL DEFAULT '',
+	token       TEXT    NOT NULL DEFAULT '',
+	enabled     BIGINT  NOT NULL DEFAULT 1,
+	created_at  TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at  TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+
+CREATE TABLE IF NOT EXISTS monitor_probes (
+	monitor_id BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	probe_id   BIGINT  NOT NULL REFERENCES probes(id) ON DELETE CASCADE,
+	PRIMARY KEY (monitor_id, probe_id)
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_probes_probe ON monitor_probes(probe_id);
+ALTER TABLE check_results ADD COLUMN probe_id BIGINT DEFAULT NULL;`,
+	},
+	{
+		version: 37,
+		sql:     `ALTER TABLE incidents ADD COLUMN ack_deadline TEXT;`,
+	},
+	{
+		version: 38,
+		sql: `ALTER TABLE status_pages ADD COLUMN subscriber_channel_id BIGINT;
+
+CREATE TABLE IF NOT EXISTS status_page_subscribers (
+	id           BIGSERIAL PRIMARY KEY,
+	page_id      BIGINT  NOT NULL REFERENCES status_pages(id) ON DELETE CASCADE,
+	email        TEXT    NOT NULL,
+	token        TEXT    NOT NULL UNIQUE,
+	confirmed    BIGINT  NOT NULL DEFAULT 0,
+	created_at   TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	confirmed_at TEXT,
+	UNIQUE(page_id, email)
+);`,
+	},
+	{
+		version: 39,
+		sql: `ALTER TABLE monitors ADD COLUMN retries BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE monitors ADD COLUMN retry_interval BIGINT NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 40,
+		sql: `ALTER TABLE incidents ADD COLUMN postmortem TEXT NOT NULL DEFAULT '';
+ALTER TABLE incident_events ADD COLUMN author TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 41,
+		sql:     `ALTER TABLE monitors ADD COLUMN redact_patterns TEXT NOT NULL DEFAULT '[]';`,
+	},
+	{
+		version: 42,
+		sql: `CREATE TABLE IF NOT EXISTS channel_tags (
+	channel_id BIGINT  NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+	tag_id     BIGINT  NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (channel_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_channel_tags_tag ON channel_tags(tag_id);`,
+	},
+	{
+		version: 43,
+		sql: `ALTER TABLE monitors ADD COLUMN degraded_threshold BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE monitor_status ADD COLUMN consec_degraded BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE incidents ADD COLUMN severity TEXT NOT NULL DEFAULT 'critical';`,
+	},
+	{
+		version: 44,
+		sql: `CREATE TABLE IF NOT EXISTS daily_uptime (
+	monitor_id   BIGINT  NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	date         TEXT    NOT NULL,
+	total_checks BIGINT  NOT NULL DEFAULT 0,
+	up_checks    BIGINT  NOT NULL DEFAULT 0,
+	down_checks  BIGINT  NOT NULL DEFAULT 0,
+	PRIMARY KEY (monitor_id, date)
+);`,
+	},
+	{
+		version: 45,
+		sql:     `ALTER TABLE monitors ADD COLUMN flap_window_seconds BIGINT NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 46,
+		sql: `ALTER TABLE notification_channels ADD COLUMN oncall_schedule_id BIGINT;
+
+CREATE TABLE IF NOT EXISTS oncall_schedules (
+	id             BIGSERIAL PRIMARY KEY,
+	name           TEXT    NOT NULL,
+	timezone       TEXT    NOT NULL DEFAULT '',
+	rotation_start TEXT    NOT NULL,
+	shift_hours    BIGINT  NOT NULL DEFAULT 168,
+	members        TEXT    NOT NULL DEFAULT '[]',
+	overrides      TEXT    NOT NULL DEFAULT '[]',
+	created_at     TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),
+	updated_at     TEXT    NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);`,
+	},
+	{
+		version: 47,
+		sql:     `ALTER TABLE monitors ADD COLUMN parent_id BIGINT DEFAULT NULL REFERENCES monitors(id) ON DELETE SET NULL;`,
+	},
+	{
+		version: 48,
+		sql:     `ALTER TABLE monitors ADD COLUMN connect_timeout_secs BIGINT NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 49,
+		sql:     `ALTER TABLE monitors ADD COLUMN max_response_time_ms BIGINT NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 50,
+		sql:     `ALTER TABLE monitors ADD COLUMN warmup_seconds BIGINT NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 51,
+		sql:     `ALTER TABLE monitor_notifications ADD COLUMN event_filter TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 52,
+		sql: `ALTER TABLE proxies ADD COLUMN last_check_at TEXT;
+ALTER TABLE proxies ADD COLUMN last_ok_at TEXT;
+ALTER TABLE proxies ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE proxies ADD COLUMN consec_fails BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE proxies ADD COLUMN auto_disabled BIGINT NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 53,
+		sql: `CREATE TABLE IF NOT EXISTS monitor_transitions (
+	id          BIGSERIAL PRIMARY KEY,
+	monitor_id  BIGINT NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	from_status TEXT   NOT NULL,
+	to_status   TEXT   NOT NULL,
+	cause       TEXT   NOT NULL DEFAULT '',
+	created_at  TEXT   NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+CREATE INDEX IF NOT EXISTS idx_monitor_transitions_monitor ON monitor_transitions(monitor_id, created_at DESC);`,
+	},
+	{
+		version: 54,
+		sql: `ALTER TABLE notification_channels ADD COLUMN digest_window_seconds BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE notification_channels ADD COLUMN digest_flush_on_resolve BIGINT NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 55,
+		sql:     `ALTER TABLE status_pages ADD COLUMN api_token_hash TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 56,
+		sql: `CREATE TABLE IF NOT EXISTS probe_checks (
+	id            BIGSERIAL PRIMARY KEY,
+	monitor_id    BIGINT NOT NULL REFERENCES monitors(id) ON DELETE CASCADE,
+	probe_id      BIGINT NOT NULL REFERENCES probes(id) ON DELETE CASCADE,
+	status        TEXT   NOT NULL,
+	response_time BIGINT NOT NULL DEFAULT 0,
+	message       TEXT   NOT NULL DEFAULT '',
+	created_at    TEXT   NOT NULL DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'))
+);
+CREATE INDEX IF NOT EXISTS idx_probe_checks_monitor ON probe_checks(monitor_id, created_at);
+INSERT INTO probe_checks (monitor_id, probe_id, status, response_time, message, created_at)
+SELECT cr.monitor_id, cr.probe_id, cr.status, cr.response_time, cr.message, cr.created_at
+FROM check_results cr JOIN probes p ON p.id = cr.probe_id;`,
+	},
+}
+
+func runPostgresMigrations(db *sql.DB) error {
+	var hasSchemaTbl bool
+	if err := db.QueryRow(`SELECT to_regclass('schema_version') IS NOT NULL`).Scan(&hasSchemaTbl); err != nil {
+		return fmt.Errorf("check schema_version table: %w", err)
+	}
+
+	if !hasSchemaTbl {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("apply base schema: %w", err)
+		}
+		if _, err := tx.Exec(postgresSchema); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("apply base schema: %w", err)
+		}
+		if _, err := tx.Exec("INSERT INTO schema_version (version) VALUES (?)", schemaVersion); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("stamp schema version: %w", err)
+		}
+		return tx.Commit()
+	}
+
+	var currentVersion int
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&currentVersion); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	for _, m := range postgresMigrations {
+		if m.version <= currentVersion {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("migration v%d begin: %w", m.version, err)
+		}
+		if _, err := tx.Exec(m.sql); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration v%d: %w", m.version, err)
+		}
+		if _, err := tx.Exec("UPDATE schema_version SET version = ?", m.version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration v%d version update: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration v%d commit: %w", m.version, err)
+		}
+		currentVersion = m.version
+	}
+
+	if currentVersion < schemaVersion {
+		return fmt.Errorf("database schema v%d has no postgres migration to v%d", currentVersion, schemaVersion)
+	}
+	return nil
+}
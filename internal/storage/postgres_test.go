@@ -0,0 +1,151 @@
+//go:build postgres
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPostgresDBSize(t *testing.T) {
+	store := testPostgresStore(t)
+	size, err := store.DBSize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size <= 0 {
+		t.Fatalf("DBSize = %d, want a positive size", size)
+	}
+}
+
+func TestPostgresClose(t *testing.T) {
+	store := testPostgresStore(t)
+	ctx := context.Background()
+	createTestMonitor(t, store.SQLiteStore, ctx, "api")
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := store.ListMonitorGroups(ctx); err == nil {
+		t.Fatal("expected queries to fail after Close")
+	}
+}
+
+// TestPostgresRewrites runs each SQLite form pgRewrite translates against a
+// real server, so a rewrite that produces invalid or wrong SQL fails here
+// rather than only in TestPgRewrite's string comparisons.
+func TestPostgresRewrites(t *testing.T) {
+	store := testPostgresStore(t)
+	ctx := context.Background()
+	db := store.writeDB
+
+	m := createTestMonitor(t, store.SQLiteStore, ctx, "Checkout API")
+	createTestMonitor(t, store.SQLiteStore, ctx, "billing")
+	created := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	if _, err := db.ExecContext(ctx, `UPDATE monitors SET created_at=? WHERE id=?`, formatTime(created), m.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("unix epoch", func(t *testing.T) {
+		var epoch int64
+		if err := db.QueryRowContext(ctx, `SELECT CAST(strftime('%s', created_at) AS INTEGER) FROM monitors WHERE id=?`, m.ID).Scan(&epoch); err != nil {
+			t.Fatal(err)
+		}
+		if epoch != created.Unix() {
+			t.Errorf("epoch = %d, want %d", epoch, created.Unix())
+		}
+	})
+
+	t.Run("date", func(t *testing.T) {
+		var day string
+		if err := db.QueryRowContext(ctx, `SELECT DATE(created_at) FROM monitors WHERE id=?`, m.ID).Scan(&day); err != nil {
+			t.Fatal(err)
+		}
+		if day != "2026-03-04" {
+			t.Errorf("date = %q, want 2026-03-04", day)
+		}
+	})
+
+	t.Run("like is case-insensitive", func(t *testing.T) {
+		var n int
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM monitors WHERE name LIKE ?`, "%checkout%").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("matched %d monitors, want 1", n)
+		}
+	})
+
+	t.Run("collate nocase", func(t *testing.T) {
+		rows, err := db.QueryContext(ctx, `SELECT name FROM monitors ORDER BY name COLLATE NOCASE`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var name string
+			rows.Scan(&name)
+			names = append(names, name)
+		}
+		if len(names) != 2 || names[0] != "billing" {
+			t.Errorf("order = %v, want billing first", names)
+		}
+	})
+
+	t.Run("placeholders skip string literals", func(t *testing.T) {
+		var lit string
+		if err := db.QueryRowContext(ctx, `SELECT 'it''s ?' FROM monitors WHERE name=?`, "billing").Scan(&lit); err != nil {
+			t.Fatal(err)
+		}
+		if lit != "it's ?" {
+			t.Errorf("literal = %q, want it's ?", lit)
+		}
+	})
+
+	t.Run("insert or ignore", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if _, err := db.ExecContext(ctx,
+				`INSERT OR IGNORE INTO check_configs (monitor_id, hash, config, created_at) VALUES (?, ?, ?, ?)`,
+				m.ID, "h1", "{}", formatTime(created)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var n int
+		db.QueryRowContext(ctx, `SELECT COUNT(*) FROM check_configs WHERE monitor_id=?`, m.ID).Scan(&n)
+		if n != 1 {
+			t.Errorf("%d rows, want 1", n)
+		}
+	})
+
+	t.Run("insert or replace", func(t *testing.T) {
+		for _, up := range []int{1, 5} {
+			if _, err := db.ExecContext(ctx,
+				`INSERT OR REPLACE INTO daily_uptime (monitor_id, date, total_checks, up_checks, down_checks) VALUES (?, ?, ?, ?, ?)`,
+				m.ID, "2026-03-04", 5, up, 5-up); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var up int
+		if err := db.QueryRowContext(ctx, `SELECT up_checks FROM daily_uptime WHERE monitor_id=? AND date=?`, m.ID, "2026-03-04").Scan(&up); err != nil {
+			t.Fatal(err)
+		}
+		if up != 5 {
+			t.Errorf("up_checks = %d, want the replaced 5", up)
+		}
+	})
+
+	t.Run("last insert id", func(t *testing.T) {
+		res, err := db.ExecContext(ctx, `INSERT INTO monitor_groups (name, sort_order, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+			"Edge", 0, formatTime(created), formatTime(created))
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, _ := res.LastInsertId()
+		g, err := store.GetMonitorGroup(ctx, id)
+		if err != nil || g.Name != "Edge" {
+			t.Errorf("LastInsertId %d does not find the group: %v %+v", id, err, g)
+		}
+	})
+}
@@ -11,15 +11,17 @@ type RetentionWorker struct {
 	store                   Store
 	retentionDays           int
 	requestLogRetentionDays int
+	transitionRetentionDays int
 	period                  time.Duration
 	logger                  *slog.Logger
 }
 
-func NewRetentionWorker(store Store, retentionDays, requestLogRetentionDays int, period time.Duration, logger *slog.Logger) *RetentionWorker {
+func NewRetentionWorker(store Store, retentionDays, requestLogRetentionDays, transitionRetentionDays int, period time.Duration, logger *slog.Logger) *RetentionWorker {
 	return &RetentionWorker{
 		store:                   store,
 		retentionDays:           retentionDays,
 		requestLogRetentionDays: requestLogRetentionDays,
+		transitionRetentionDays: transitionRetentionDays,
 		period:                  period,
 		logger:                  logger,
 	}
@@ -62,4 +64,14 @@ func (w *RetentionWorker) purge(ctx context.Context) {
 	if rlDeleted > 0 {
 		w.logger.Info("request log purge completed", "deleted", rlDeleted, "before", rlBefore.Format(time.RFC3339))
 	}
+
+	trBefore := time.Now().AddDate(0, 0, -w.transitionRetentionDays)
+	trDeleted, err := w.store.PurgeOldTransitions(ctx, trBefore)
+	if err != nil {
+		w.logger.Error("transition purge failed", "error", err)
+		return
+	}
+	if trDeleted > 0 {
+		w.logger.Info("transition purge completed", "deleted", trDeleted, "before", trBefore.Format(time.RFC3339))
+	}
 }
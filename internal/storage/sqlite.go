@@ -18,6 +18,10 @@ type SQLiteStore struct {
 	readDB  *sql.DB
 	writeDB *sql.DB
 	dbPath  string
+	limits  MonitorLimits
+
+	writeRetry WriteRetry
+	writeBuf   writeBuffer
 }
 
 // NewSQLiteStore opens the database with separate read and write pools.
@@ -53,6 +57,13 @@ func NewSQLiteStore(path string, maxReadConns int) (*SQLiteStore, error) {
 	return &SQLiteStore{readDB: readDB, writeDB: writeDB, dbPath: path}, nil
 }
 
+// SetMonitorLimits configures the limits enforced by CreateMonitor,
+// UpdateMonitor and BulkSetMonitorGroup. It must be called before the store
+// is used concurrently.
+func (s *SQLiteStore) SetMonitorLimits(l MonitorLimits) {
+	s.limits = l
+}
+
 func runMigrations(db *sql.DB) error {
 	var hasSchemaTbl int
 	if err := db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type='table' AND name='schema_version'`).Scan(&hasSchemaTbl); err != nil {
@@ -123,8 +134,17 @@ func (s *SQLiteStore) DBSize() (int64, error) {
 	return info.Size(), nil
 }
 
+// Ping runs a trivial query to confirm the database answers.
+func (s *SQLiteStore) Ping(ctx context.Context) error {
+	var n int
+	return s.readDB.QueryRowContext(ctx, "SELECT 1").Scan(&n)
+}
+
 func (s *SQLiteStore) Close() error {
 	var firstErr error
+	if err := s.FlushPendingWrites(context.Background()); err != nil {
+		firstErr = fmt.Errorf("flush pending writes: %w", err)
+	}
 	if err := s.readDB.Close(); err != nil && firstErr == nil {
 		firstErr = fmt.Errorf("close read db: %w", err)
 	}
@@ -166,6 +186,13 @@ func boolToInt(b bool) int {
 	return 0
 }
 
+func nullBool(b *bool) any {
+	if b == nil {
+		return nil
+	}
+	return boolToInt(*b)
+}
+
 func nullStr(s string) any {
 	if s == "" {
 		return nil
@@ -173,19 +200,41 @@ func nullStr(s string) any {
 	return s
 }
 
+func nullInt64(v *int64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func int64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
+
+func nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
 type scanner interface {
 	Scan(dest ...any) error
 }
 
 func scanMonitor(row scanner) (*Monitor, error) {
 	var m Monitor
-	var tagsStr, settingsStr, assertionsStr string
+	var tagsStr, settingsStr, assertionsStr, redactStr string
 	var createdAt, updatedAt string
 	var lastCheck sql.NullString
-	var groupID, proxyID sql.NullInt64
+	var groupID, proxyID, parentID sql.NullInt64
+	var ackSilences sql.NullBool
 	err := row.Scan(&m.ID, &m.Name, &m.Description, &m.Type, &m.Target, &m.Interval, &m.Timeout, &m.Enabled,
 		&tagsStr, &settingsStr, &assertionsStr, &m.TrackChanges, &m.FailureThreshold, &m.SuccessThreshold,
-		&m.UpsideDown, &m.ResendInterval, &groupID, &proxyID, &createdAt, &updatedAt,
+		&m.UpsideDown, &m.ResendInterval, &groupID, &proxyID, &ackSilences, &m.LatencyBaselineSigma, &m.SkipDefaultChannel, &m.CaptureFailureContext, &m.Owner, &m.StreamChecksEvery, &m.Retries, &m.RetryInterval, &redactStr, &m.DegradedThreshold, &m.FlapWindowSeconds, &parentID, &m.ConnectTimeout, &m.MaxResponseTimeMs, &m.WarmupSeconds, &createdAt, &updatedAt,
 		&m.Status, &lastCheck, &m.ConsecFails, &m.ConsecSuccesses)
 	if err != nil {
 		return nil, err
@@ -198,9 +247,15 @@ func scanMonitor(row scanner) (*Monitor, error) {
 		pid := proxyID.Int64
 		m.ProxyID = &pid
 	}
+	m.ParentID = int64Ptr(parentID)
+	if ackSilences.Valid {
+		v := ackSilences.Bool
+		m.AckSilencesReminders = &v
+	}
 	m.CreatedAt = parseTime(createdAt)
 	m.UpdatedAt = parseTime(updatedAt)
 	json.Unmarshal([]byte(tagsStr), &m.Tags)
+	json.Unmarshal([]byte(redactStr), &m.RedactPatterns)
 	m.Settings = json.RawMessage(settingsStr)
 	m.Assertions = json.RawMessage(assertionsStr)
 	m.LastCheckAt = parseTimePtr(lastCheck)
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (s *SQLiteStore) CreateEscalationPolicy(ctx context.Context, ep *EscalationPolicy) error {
+	steps, err := json.Marshal(ep.Steps)
+	if err != nil {
+		return err
+	}
+	now := formatTime(time.Now())
+	res, err := s.writeDB.ExecContext(ctx,
+		`INSERT INTO escalation_policies (name, steps, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+		ep.Name, string(steps), now, now)
+	if err != nil {
+		return err
+	}
+	id, _ := res.LastInsertId()
+	ep.ID = id
+	ep.CreatedAt = parseTime(now)
+	ep.UpdatedAt = parseTime(now)
+	return nil
+}
+
+func (s *SQLiteStore) GetEscalationPolicy(ctx context.Context, id int64) (*EscalationPolicy, error) {
+	var ep EscalationPolicy
+	var steps, createdAt, updatedAt string
+	err := s.readDB.QueryRowContext(ctx,
+		`SELECT id, name, steps, created_at, updated_at FROM escalation_policies WHERE id=?`, id).
+		Scan(&ep.ID, &ep.Name, &steps, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal([]byte(steps), &ep.Steps)
+	ep.CreatedAt = parseTime(createdAt)
+	ep.UpdatedAt = parseTime(updatedAt)
+	return &ep, nil
+}
+
+func (s *SQLiteStore) ListEscalationPolicies(ctx context.Context) ([]*EscalationPolicy, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT id, name, steps, created_at, updated_at FROM escalation_policies ORDER BY name COLLATE NOCASE`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []*EscalationPolicy
+	for rows.Next() {
+		var ep EscalationPolicy
+		var steps, createdAt, updatedAt string
+		if err := rows.Scan(&ep.ID, &ep.Name, &steps, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(steps), &ep.Steps)
+		ep.CreatedAt = parseTime(createdAt)
+		ep.UpdatedAt = parseTime(updatedAt)
+		policies = append(policies, &ep)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if policies == nil {
+		policies = []*EscalationPolicy{}
+	}
+	return policies, nil
+}
+
+func (s *SQLiteStore) UpdateEscalationPolicy(ctx context.Context, ep *EscalationPolicy) error {
+	steps, err := json.Marshal(ep.Steps)
+	if err != nil {
+		return err
+	}
+	now := formatTime(time.Now())
+	_, err = s.writeDB.ExecContext(ctx,
+		`UPDATE escalation_policies SET name=?, steps=?, updated_at=? WHERE id=?`,
+		ep.Name, string(steps), now, ep.ID)
+	return err
+}
+
+func (s *SQLiteStore) DeleteEscalationPolicy(ctx context.Context, id int64) error {
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("delete escalation policy begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM monitor_escalation_policies WHERE policy_id=?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM escalation_policies WHERE id=?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetMonitorEscalationPolicyID returns the policy assigned to a monitor, or
+// nil when it has none.
+func (s *SQLiteStore) GetMonitorEscalationPolicyID(ctx context.Context, monitorID int64) (*int64, error) {
+	var id int64
+	err := s.readDB.QueryRowContext(ctx,
+		`SELECT policy_id FROM monitor_escalation_policies WHERE monitor_id=?`, monitorID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// SetMonitorEscalationPolicy assigns a policy to a monitor. A nil policyID
+// removes the assignment.
+func (s *SQLiteStore) SetMonitorEscalationPolicy(ctx context.Context, monitorID int64, policyID *int64) error {
+	if policyID == nil {
+		_, err := s.writeDB.ExecContext(ctx, `DELETE FROM monitor_escalation_policies WHERE monitor_id=?`, monitorID)
+		return err
+	}
+	_, err := s.writeDB.ExecContext(ctx,
+		`INSERT INTO monitor_escalation_policies (monitor_id, policy_id) VALUES (?, ?)
+		 ON CONFLICT(monitor_id) DO UPDATE SET policy_id=excluded.policy_id`,
+		monitorID, *policyID)
+	return err
+}
+
+// ListPendingEscalations returns every open, unacknowledged incident whose
+// monitor has an escalation policy.
+func (s *SQLiteStore) ListPendingEscalations(ctx context.Context) ([]*PendingEscalation, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT i.id, i.monitor_id, mep.policy_id,
+		        (SELECT COUNT(*) FROM incident_events e WHERE e.incident_id = i.id AND e.type = 'escalated')
+		 FROM incidents i
+		 JOIN monitor_escalation_policies mep ON mep.monitor_id = i.monitor_id
+		 WHERE i.status = 'open'
+		 ORDER BY i.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pending []*PendingEscalation
+	for rows.Next() {
+		var pe PendingEscalation
+		if err := rows.Scan(&pe.IncidentID, &pe.MonitorID, &pe.PolicyID, &pe.Escalated); err != nil {
+			return nil, err
+		}
+		pending = append(pending, &pe)
+	}
+	return pending, rows.Err()
+}
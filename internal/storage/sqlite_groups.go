@@ -86,3 +86,65 @@ func (s *SQLiteStore) DeleteMonitorGroup(ctx context.Context, id int64) error {
 	return tx.Commit()
 }
 
+// GetGroupStatus returns the status rollup of a group's monitors, or
+// sql.ErrNoRows if the group does not exist.
+func (s *SQLiteStore) GetGroupStatus(ctx context.Context, groupID int64) (*GroupStatus, error) {
+	var exists int
+	if err := s.readDB.QueryRowContext(ctx, `SELECT 1 FROM monitor_groups WHERE id=?`, groupID).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT m.type, m.enabled, COALESCE(ms.status, 'pending')
+		 FROM monitors m
+		 LEFT JOIN monitor_status ms ON ms.monitor_id = m.id
+		 WHERE m.group_id=?`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gs := &GroupStatus{GroupID: groupID}
+	var outages int64 // down monitors that can make the group down
+	for rows.Next() {
+		var typ, status string
+		var enabled bool
+		if err := rows.Scan(&typ, &enabled, &status); err != nil {
+			return nil, err
+		}
+		gs.Total++
+		if !enabled {
+			gs.Paused++
+			continue
+		}
+		switch status {
+		case "up":
+			gs.Up++
+		case "down":
+			gs.Down++
+			if typ != "heartbeat" {
+				outages++
+			}
+		case "degraded":
+			gs.Degraded++
+		default:
+			gs.Pending++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A missed heartbeat is counted but doesn't take the group down.
+	switch {
+	case outages > 0:
+		gs.Status = "down"
+	case gs.Degraded > 0:
+		gs.Status = "degraded"
+	case gs.Up > 0:
+		gs.Status = "up"
+	default:
+		gs.Status = "pending"
+	}
+	return gs, nil
+}
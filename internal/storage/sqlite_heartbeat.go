@@ -44,10 +44,38 @@ func (s *SQLiteStore) GetHeartbeatByMonitorID(ctx context.Context, monitorID int
 	return &h, nil
 }
 
+// UpdateHeartbeatPing records a ping. A paused heartbeat stays paused.
 func (s *SQLiteStore) UpdateHeartbeatPing(ctx context.Context, token string) error {
 	now := formatTime(time.Now())
 	_, err := s.writeDB.ExecContext(ctx,
-		`UPDATE heartbeats SET last_ping_at=?, status='up' WHERE token=?`, now, token)
+		`UPDATE heartbeats SET last_ping_at=?, status=CASE WHEN status='paused' THEN 'paused' ELSE 'up' END WHERE token=?`, now, token)
+	return err
+}
+
+// PauseHeartbeat stops expiry checking for a heartbeat until it is resumed.
+func (s *SQLiteStore) PauseHeartbeat(ctx context.Context, token string) error {
+	_, err := s.writeDB.ExecContext(ctx,
+		`UPDATE heartbeats SET status='paused' WHERE token=?`, token)
+	return err
+}
+
+// ResumeHeartbeat restarts expiry checking for a paused heartbeat. The
+// deadline runs from now, and the heartbeat is pending until its next ping.
+func (s *SQLiteStore) ResumeHeartbeat(ctx context.Context, token string) error {
+	now := formatTime(time.Now())
+	_, err := s.writeDB.ExecContext(ctx,
+		`UPDATE heartbeats SET last_ping_at=?, status='pending' WHERE token=? AND status='paused'`, now, token)
+	return err
+}
+
+// RestartHeartbeatDeadline moves an expired heartbeat's deadline to run
+// from now, leaving it pending until its next ping. The watcher calls it
+// while the monitor is in maintenance, so a job stopped for the window gets
+// a full interval and grace period after the window ends.
+func (s *SQLiteStore) RestartHeartbeatDeadline(ctx context.Context, monitorID int64) error {
+	now := formatTime(time.Now())
+	_, err := s.writeDB.ExecContext(ctx,
+		`UPDATE heartbeats SET last_ping_at=?, status='pending' WHERE monitor_id=? AND status NOT IN ('paused', 'down')`, now, monitorID)
 	return err
 }
 
@@ -57,8 +85,10 @@ func (s *SQLiteStore) ListExpiredHeartbeats(ctx context.Context) ([]*Heartbeat,
 		 FROM heartbeats h
 		 JOIN monitors m ON m.id = h.monitor_id
 		 WHERE m.enabled = 1
+		   AND h.status != 'paused'
 		   AND h.last_ping_at IS NOT NULL
-		   AND datetime(h.last_ping_at, '+' || (m.interval_secs + h.grace) || ' seconds') < datetime('now')`)
+		   AND CAST(strftime('%s', h.last_ping_at) AS INTEGER) + m.interval_secs + h.grace < ?`,
+		time.Now().Unix())
 	if err != nil {
 		return nil, err
 	}
@@ -4,14 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"math"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 )
 
 func (s *SQLiteStore) CreateIncident(ctx context.Context, inc *Incident) error {
+	if inc.Severity == "" {
+		inc.Severity = "critical"
+	}
 	now := formatTime(time.Now())
 	res, err := s.writeDB.ExecContext(ctx,
-		`INSERT INTO incidents (monitor_id, status, cause, started_at) VALUES (?, ?, ?, ?)`,
-		inc.MonitorID, inc.Status, inc.Cause, now)
+		`INSERT INTO incidents (monitor_id, status, cause, severity, started_at) VALUES (?, ?, ?, ?, ?)`,
+		inc.MonitorID, inc.Status, inc.Cause, inc.Severity, now)
 	if err != nil {
 		return err
 	}
@@ -21,28 +27,35 @@ func (s *SQLiteStore) CreateIncident(ctx context.Context, inc *Incident) error {
 	return nil
 }
 
-func (s *SQLiteStore) GetIncident(ctx context.Context, id int64) (*Incident, error) {
+// incidentColumns selects an incident with its monitor name; the query
+// must join monitors as m.
+const incidentColumns = `i.id, i.monitor_id, i.status, i.cause, i.started_at,
+		        i.acknowledged_at, i.acknowledged_by, i.resolved_at, i.resolved_by, i.ack_deadline,
+		        i.postmortem, i.severity, COALESCE(m.name, '')`
+
+func scanIncident(row scanner) (*Incident, error) {
 	var inc Incident
 	var startedAt string
-	var ackAt, resAt sql.NullString
-	err := s.readDB.QueryRowContext(ctx,
-		`SELECT i.id, i.monitor_id, i.status, i.cause, i.started_at,
-		        i.acknowledged_at, i.acknowledged_by, i.resolved_at, i.resolved_by,
-		        COALESCE(m.name, '')
-		 FROM incidents i
-		 LEFT JOIN monitors m ON m.id = i.monitor_id
-		 WHERE i.id=?`, id).
-		Scan(&inc.ID, &inc.MonitorID, &inc.Status, &inc.Cause, &startedAt,
-			&ackAt, &inc.AcknowledgedBy, &resAt, &inc.ResolvedBy, &inc.MonitorName)
-	if err != nil {
+	var ackAt, resAt, ackDeadline sql.NullString
+	if err := row.Scan(&inc.ID, &inc.MonitorID, &inc.Status, &inc.Cause, &startedAt,
+		&ackAt, &inc.AcknowledgedBy, &resAt, &inc.ResolvedBy, &ackDeadline, &inc.Postmortem, &inc.Severity, &inc.MonitorName); err != nil {
 		return nil, err
 	}
 	inc.StartedAt = parseTime(startedAt)
 	inc.AcknowledgedAt = parseTimePtr(ackAt)
 	inc.ResolvedAt = parseTimePtr(resAt)
+	inc.AckDeadline = parseTimePtr(ackDeadline)
 	return &inc, nil
 }
 
+func (s *SQLiteStore) GetIncident(ctx context.Context, id int64) (*Incident, error) {
+	return scanIncident(s.readDB.QueryRowContext(ctx,
+		`SELECT `+incidentColumns+`
+		 FROM incidents i
+		 LEFT JOIN monitors m ON m.id = i.monitor_id
+		 WHERE i.id=?`, id))
+}
+
 func (s *SQLiteStore) ListIncidents(ctx context.Context, monitorID int64, status string, search string, p Pagination) (*PaginatedResult, error) {
 	where := "1=1"
 	args := []any{}
@@ -71,9 +84,7 @@ func (s *SQLiteStore) ListIncidents(ctx context.Context, monitorID int64, status
 	offset := (p.Page - 1) * p.PerPage
 	args = append(args, p.PerPage, offset)
 	rows, err := s.readDB.QueryContext(ctx,
-		`SELECT i.id, i.monitor_id, i.status, i.cause, i.started_at,
-		        i.acknowledged_at, i.acknowledged_by, i.resolved_at, i.resolved_by,
-		        COALESCE(m.name, '')
+		`SELECT `+incidentColumns+`
 		 FROM incidents i
 		 LEFT JOIN monitors m ON m.id = i.monitor_id
 		 WHERE `+where+` ORDER BY i.started_at DESC LIMIT ? OFFSET ?`, args...)
@@ -84,18 +95,11 @@ func (s *SQLiteStore) ListIncidents(ctx context.Context, monitorID int64, status
 
 	var incidents []*Incident
 	for rows.Next() {
-		var inc Incident
-		var startedAt string
-		var ackAt, resAt sql.NullString
-		err := rows.Scan(&inc.ID, &inc.MonitorID, &inc.Status, &inc.Cause, &startedAt,
-			&ackAt, &inc.AcknowledgedBy, &resAt, &inc.ResolvedBy, &inc.MonitorName)
+		inc, err := scanIncident(rows)
 		if err != nil {
 			return nil, err
 		}
-		inc.StartedAt = parseTime(startedAt)
-		inc.AcknowledgedAt = parseTimePtr(ackAt)
-		inc.ResolvedAt = parseTimePtr(resAt)
-		incidents = append(incidents, &inc)
+		incidents = append(incidents, inc)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -113,20 +117,148 @@ func (s *SQLiteStore) ListIncidents(ctx context.Context, monitorID int64, status
 	}, nil
 }
 
+// GroupIncidentsByCause aggregates incidents started at or after from by
+// monitor and normalized cause, largest groups first.
+func (s *SQLiteStore) GroupIncidentsByCause(ctx context.Context, monitorID int64, status string, search string, from time.Time) ([]*IncidentCauseGroup, error) {
+	where := "i.started_at >= ?"
+	args := []any{formatTime(from)}
+	if monitorID > 0 {
+		where += " AND i.monitor_id=?"
+		args = append(args, monitorID)
+	}
+	if status != "" {
+		where += " AND i.status=?"
+		args = append(args, status)
+	}
+	if search != "" {
+		where += " AND (COALESCE(m.name, '') LIKE '%' || ? || '%' OR i.cause LIKE '%' || ? || '%')"
+		args = append(args, search, search)
+	}
+
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT i.id, i.monitor_id, i.status, i.cause, i.started_at, COALESCE(m.name, '')
+		 FROM incidents i
+		 LEFT JOIN monitors m ON m.id = i.monitor_id
+		 WHERE `+where+` ORDER BY i.started_at DESC, i.id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type groupKey struct {
+		monitorID int64
+		cause     string
+	}
+	index := map[groupKey]*IncidentCauseGroup{}
+	groups := []*IncidentCauseGroup{}
+	for rows.Next() {
+		var id, monID int64
+		var incStatus, cause, startedAt, monName string
+		if err := rows.Scan(&id, &monID, &incStatus, &cause, &startedAt, &monName); err != nil {
+			return nil, err
+		}
+		key := groupKey{monID, NormalizeCause(cause)}
+		g, ok := index[key]
+		if !ok {
+			// Rows arrive newest first, so the first incident seen is the latest.
+			g = &IncidentCauseGroup{
+				MonitorID:      monID,
+				MonitorName:    monName,
+				Cause:          key.cause,
+				Example:        cause,
+				LastIncidentID: id,
+				LastSeen:       parseTime(startedAt),
+			}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.Count++
+		if incStatus != "resolved" {
+			g.OpenCount++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(groups, func(a, b int) bool {
+		return groups[a].Count > groups[b].Count
+	})
+	return groups, nil
+}
+
+var (
+	causeAddrRe   = regexp.MustCompile(`\[[0-9a-f:]+\](:\d+)?|\d{1,3}(\.\d{1,3}){3}(:\d+)?`)
+	causeHexRe    = regexp.MustCompile(`\b[0-9a-f]{8,}\b`)
+	causeNumberRe = regexp.MustCompile(`\d+(\.\d+)?`)
+)
+
+// NormalizeCause reduces an incident cause to a pattern by replacing
+// addresses, hex identifiers and numbers, so "timeout after 10.2s" and
+// "timeout after 9.8s" group together.
+func NormalizeCause(cause string) string {
+	c := strings.ToLower(strings.TrimSpace(cause))
+	c = causeAddrRe.ReplaceAllString(c, "<addr>")
+	c = causeHexRe.ReplaceAllString(c, "<id>")
+	c = causeNumberRe.ReplaceAllString(c, "N")
+	return strings.Join(strings.Fields(c), " ")
+}
+
 func (s *SQLiteStore) UpdateIncident(ctx context.Context, inc *Incident) error {
-	var ackAt, resAt any
+	var ackAt, resAt, ackDeadline any
 	if inc.AcknowledgedAt != nil {
 		ackAt = formatTime(*inc.AcknowledgedAt)
 	}
 	if inc.ResolvedAt != nil {
 		resAt = formatTime(*inc.ResolvedAt)
 	}
+	if inc.AckDeadline != nil {
+		ackDeadline = formatTime(*inc.AckDeadline)
+	}
 	_, err := s.writeDB.ExecContext(ctx,
-		`UPDATE incidents SET status=?, cause=?, acknowledged_at=?, acknowledged_by=?, resolved_at=?, resolved_by=? WHERE id=?`,
-		inc.Status, inc.Cause, ackAt, inc.AcknowledgedBy, resAt, inc.ResolvedBy, inc.ID)
+		`UPDATE incidents SET status=?, cause=?, severity=?, acknowledged_at=?, acknowledged_by=?, resolved_at=?, resolved_by=?, ack_deadline=? WHERE id=?`,
+		inc.Status, inc.Cause, inc.Severity, ackAt, inc.AcknowledgedBy, resAt, inc.ResolvedBy, ackDeadline, inc.ID)
 	return err
 }
 
+// SetIncidentPostmortem replaces the postmortem of an incident. It returns
+// sql.ErrNoRows if the incident does not exist.
+func (s *SQLiteStore) SetIncidentPostmortem(ctx context.Context, id int64, postmortem string) error {
+	res, err := s.writeDB.ExecContext(ctx, `UPDATE incidents SET postmortem=? WHERE id=?`, postmortem, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListExpiredAcks returns acknowledged incidents whose ack deadline is at or
+// before now.
+func (s *SQLiteStore) ListExpiredAcks(ctx context.Context, now time.Time) ([]*Incident, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT `+incidentColumns+`
+		 FROM incidents i
+		 LEFT JOIN monitors m ON m.id = i.monitor_id
+		 WHERE i.status='acknowledged' AND i.ack_deadline IS NOT NULL AND i.ack_deadline <= ?
+		 ORDER BY i.ack_deadline`, formatTime(now))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []*Incident
+	for rows.Next() {
+		inc, err := scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, inc)
+	}
+	return incidents, rows.Err()
+}
+
 func (s *SQLiteStore) DeleteIncident(ctx context.Context, id int64) error {
 	_, err := s.writeDB.ExecContext(ctx, "DELETE FROM incidents WHERE id=?", id)
 	return err
@@ -135,29 +267,42 @@ func (s *SQLiteStore) DeleteIncident(ctx context.Context, id int64) error {
 func (s *SQLiteStore) GetOpenIncident(ctx context.Context, monitorID int64) (*Incident, error) {
 	var inc Incident
 	var startedAt string
-	var ackAt, resAt sql.NullString
+	var ackAt, resAt, ackDeadline sql.NullString
 	err := s.readDB.QueryRowContext(ctx,
-		`SELECT id, monitor_id, status, cause, started_at, acknowledged_at, acknowledged_by, resolved_at, resolved_by
+		`SELECT id, monitor_id, status, cause, severity, started_at, acknowledged_at, acknowledged_by, resolved_at, resolved_by, ack_deadline
 		 FROM incidents WHERE monitor_id=? AND status IN ('open','acknowledged') ORDER BY started_at DESC LIMIT 1`,
 		monitorID).
-		Scan(&inc.ID, &inc.MonitorID, &inc.Status, &inc.Cause, &startedAt,
-			&ackAt, &inc.AcknowledgedBy, &resAt, &inc.ResolvedBy)
+		Scan(&inc.ID, &inc.MonitorID, &inc.Status, &inc.Cause, &inc.Severity, &startedAt,
+			&ackAt, &inc.AcknowledgedBy, &resAt, &inc.ResolvedBy, &ackDeadline)
 	if err != nil {
 		return nil, err
 	}
 	inc.StartedAt = parseTime(startedAt)
 	inc.AcknowledgedAt = parseTimePtr(ackAt)
 	inc.ResolvedAt = parseTimePtr(resAt)
+	inc.AckDeadline = parseTimePtr(ackDeadline)
 	return &inc, nil
 }
 
+// GetRecentlyResolvedIncident returns the monitor's most recently resolved
+// incident if it was resolved at or after since.
+func (s *SQLiteStore) GetRecentlyResolvedIncident(ctx context.Context, monitorID int64, since time.Time) (*Incident, error) {
+	return scanIncident(s.readDB.QueryRowContext(ctx,
+		`SELECT `+incidentColumns+`
+		 FROM incidents i
+		 LEFT JOIN monitors m ON m.id = i.monitor_id
+		 WHERE i.monitor_id=? AND i.status='resolved' AND i.resolved_at >= ?
+		 ORDER BY i.resolved_at DESC LIMIT 1`,
+		monitorID, formatTime(since)))
+}
+
 // --- Incident Events ---
 
 func (s *SQLiteStore) InsertIncidentEvent(ctx context.Context, e *IncidentEvent) error {
 	now := formatTime(time.Now())
 	res, err := s.writeDB.ExecContext(ctx,
-		`INSERT INTO incident_events (incident_id, type, message, created_at) VALUES (?, ?, ?, ?)`,
-		e.IncidentID, e.Type, e.Message, now)
+		`INSERT INTO incident_events (incident_id, type, message, author, created_at) VALUES (?, ?, ?, ?, ?)`,
+		e.IncidentID, e.Type, e.Message, e.Author, now)
 	if err != nil {
 		return err
 	}
@@ -169,8 +314,8 @@ func (s *SQLiteStore) InsertIncidentEvent(ctx context.Context, e *IncidentEvent)
 
 func (s *SQLiteStore) ListIncidentEvents(ctx context.Context, incidentID int64) ([]*IncidentEvent, error) {
 	rows, err := s.readDB.QueryContext(ctx,
-		`SELECT id, incident_id, type, message, created_at
-		 FROM incident_events WHERE incident_id=? ORDER BY created_at`, incidentID)
+		`SELECT id, incident_id, type, message, author, created_at
+		 FROM incident_events WHERE incident_id=? ORDER BY created_at, id`, incidentID)
 	if err != nil {
 		return nil, err
 	}
@@ -180,7 +325,7 @@ func (s *SQLiteStore) ListIncidentEvents(ctx context.Context, incidentID int64)
 	for rows.Next() {
 		var e IncidentEvent
 		var createdAt string
-		if err := rows.Scan(&e.ID, &e.IncidentID, &e.Type, &e.Message, &createdAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.IncidentID, &e.Type, &e.Message, &e.Author, &createdAt); err != nil {
 			return nil, err
 		}
 		e.CreatedAt = parseTime(createdAt)
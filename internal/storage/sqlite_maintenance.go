@@ -4,15 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"time"
+
+	"github.com/hashicorp/cronexpr"
 )
 
 func (s *SQLiteStore) CreateMaintenanceWindow(ctx context.Context, mw *MaintenanceWindow) error {
 	monitorIDs, _ := json.Marshal(mw.MonitorIDs)
 	now := formatTime(time.Now())
 	res, err := s.writeDB.ExecContext(ctx,
-		`INSERT INTO maintenance_windows (name, monitor_ids, start_time, end_time, recurring, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		mw.Name, string(monitorIDs), formatTime(mw.StartTime), formatTime(mw.EndTime), mw.Recurring, now, now)
+		`INSERT INTO maintenance_windows (name, monitor_ids, start_time, end_time, recurring, timezone, cron_expr, duration_minutes, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		mw.Name, string(monitorIDs), formatTime(mw.StartTime), formatTime(mw.EndTime), mw.Recurring, mw.Timezone, mw.CronExpr, mw.DurationMinutes, now, now)
 	if err != nil {
 		return err
 	}
@@ -27,9 +29,9 @@ func (s *SQLiteStore) GetMaintenanceWindow(ctx context.Context, id int64) (*Main
 	var mw MaintenanceWindow
 	var monitorIDsStr, startTime, endTime, createdAt, updatedAt string
 	err := s.readDB.QueryRowContext(ctx,
-		`SELECT id, name, monitor_ids, start_time, end_time, recurring, created_at, updated_at
+		`SELECT id, name, monitor_ids, start_time, end_time, recurring, timezone, cron_expr, duration_minutes, created_at, updated_at
 		 FROM maintenance_windows WHERE id=?`, id).
-		Scan(&mw.ID, &mw.Name, &monitorIDsStr, &startTime, &endTime, &mw.Recurring, &createdAt, &updatedAt)
+		Scan(&mw.ID, &mw.Name, &monitorIDsStr, &startTime, &endTime, &mw.Recurring, &mw.Timezone, &mw.CronExpr, &mw.DurationMinutes, &createdAt, &updatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +45,7 @@ func (s *SQLiteStore) GetMaintenanceWindow(ctx context.Context, id int64) (*Main
 
 func (s *SQLiteStore) ListMaintenanceWindows(ctx context.Context) ([]*MaintenanceWindow, error) {
 	rows, err := s.readDB.QueryContext(ctx,
-		`SELECT id, name, monitor_ids, start_time, end_time, recurring, created_at, updated_at
+		`SELECT id, name, monitor_ids, start_time, end_time, recurring, timezone, cron_expr, duration_minutes, created_at, updated_at
 		 FROM maintenance_windows ORDER BY start_time DESC`)
 	if err != nil {
 		return nil, err
@@ -54,7 +56,7 @@ func (s *SQLiteStore) ListMaintenanceWindows(ctx context.Context) ([]*Maintenanc
 	for rows.Next() {
 		var mw MaintenanceWindow
 		var monitorIDsStr, startTime, endTime, createdAt, updatedAt string
-		if err := rows.Scan(&mw.ID, &mw.Name, &monitorIDsStr, &startTime, &endTime, &mw.Recurring, &createdAt, &updatedAt); err != nil {
+		if err := rows.Scan(&mw.ID, &mw.Name, &monitorIDsStr, &startTime, &endTime, &mw.Recurring, &mw.Timezone, &mw.CronExpr, &mw.DurationMinutes, &createdAt, &updatedAt); err != nil {
 			return nil, err
 		}
 		mw.StartTime = parseTime(startTime)
@@ -77,8 +79,8 @@ func (s *SQLiteStore) UpdateMaintenanceWindow(ctx context.Context, mw *Maintenan
 	monitorIDs, _ := json.Marshal(mw.MonitorIDs)
 	now := formatTime(time.Now())
 	_, err := s.writeDB.ExecContext(ctx,
-		`UPDATE maintenance_windows SET name=?, monitor_ids=?, start_time=?, end_time=?, recurring=?, updated_at=? WHERE id=?`,
-		mw.Name, string(monitorIDs), formatTime(mw.StartTime), formatTime(mw.EndTime), mw.Recurring, now, mw.ID)
+		`UPDATE maintenance_windows SET name=?, monitor_ids=?, start_time=?, end_time=?, recurring=?, timezone=?, cron_expr=?, duration_minutes=?, updated_at=? WHERE id=?`,
+		mw.Name, string(monitorIDs), formatTime(mw.StartTime), formatTime(mw.EndTime), mw.Recurring, mw.Timezone, mw.CronExpr, mw.DurationMinutes, now, mw.ID)
 	return err
 }
 
@@ -89,7 +91,7 @@ func (s *SQLiteStore) DeleteMaintenanceWindow(ctx context.Context, id int64) err
 
 func (s *SQLiteStore) IsMonitorInMaintenance(ctx context.Context, monitorID int64, at time.Time) (bool, error) {
 	rows, err := s.readDB.QueryContext(ctx,
-		`SELECT id, name, monitor_ids, start_time, end_time, recurring, created_at, updated_at
+		`SELECT id, name, monitor_ids, start_time, end_time, recurring, timezone, cron_expr, duration_minutes, created_at, updated_at
 		 FROM maintenance_windows
 		 WHERE recurring != '' OR end_time > ?`,
 		formatTime(at))
@@ -101,7 +103,7 @@ func (s *SQLiteStore) IsMonitorInMaintenance(ctx context.Context, monitorID int6
 	for rows.Next() {
 		var mw MaintenanceWindow
 		var monitorIDsStr, startTime, endTime, createdAt, updatedAt string
-		if err := rows.Scan(&mw.ID, &mw.Name, &monitorIDsStr, &startTime, &endTime, &mw.Recurring, &createdAt, &updatedAt); err != nil {
+		if err := rows.Scan(&mw.ID, &mw.Name, &monitorIDsStr, &startTime, &endTime, &mw.Recurring, &mw.Timezone, &mw.CronExpr, &mw.DurationMinutes, &createdAt, &updatedAt); err != nil {
 			return false, err
 		}
 		mw.StartTime = parseTime(startTime)
@@ -132,36 +134,130 @@ func (s *SQLiteStore) IsMonitorInMaintenance(ctx context.Context, monitorID int6
 	return false, nil
 }
 
+// isInWindow reports whether at falls inside mw. Recurrences compare the
+// time of day, weekday and day of month in the window's timezone, so a daily
+// 02:00 window stays at 02:00 local time across DST changes.
 func isInWindow(mw *MaintenanceWindow, at time.Time) bool {
 	if mw.Recurring == "" {
 		return !at.Before(mw.StartTime) && at.Before(mw.EndTime)
 	}
+	if mw.Recurring == "cron" {
+		return isInCronWindow(mw, at)
+	}
+
+	loc := mw.Location()
+	start := mw.StartTime.In(loc)
+	at = at.In(loc)
 
 	duration := mw.EndTime.Sub(mw.StartTime)
+	startSec := start.Hour()*3600 + start.Minute()*60 + start.Second()
+	atSec := at.Hour()*3600 + at.Minute()*60 + at.Second()
 	switch mw.Recurring {
 	case "daily":
 		// Check if current time-of-day falls within the window
-		startSec := mw.StartTime.Hour()*3600 + mw.StartTime.Minute()*60 + mw.StartTime.Second()
-		atSec := at.Hour()*3600 + at.Minute()*60 + at.Second()
 		endSec := startSec + int(duration.Seconds())
 		if endSec > 86400 {
 			return atSec >= startSec || atSec < (endSec-86400)
 		}
 		return atSec >= startSec && atSec < endSec
 	case "weekly":
-		startDay := mw.StartTime.Weekday()
-		atDay := at.Weekday()
-		if startDay == atDay {
-			startSec := mw.StartTime.Hour()*3600 + mw.StartTime.Minute()*60 + mw.StartTime.Second()
-			atSec := at.Hour()*3600 + at.Minute()*60 + at.Second()
+		if start.Weekday() == at.Weekday() {
 			return atSec >= startSec && atSec < startSec+int(duration.Seconds())
 		}
 	case "monthly":
-		if mw.StartTime.Day() == at.Day() {
-			startSec := mw.StartTime.Hour()*3600 + mw.StartTime.Minute()*60 + mw.StartTime.Second()
-			atSec := at.Hour()*3600 + at.Minute()*60 + at.Second()
+		if start.Day() == at.Day() {
 			return atSec >= startSec && atSec < startSec+int(duration.Seconds())
 		}
 	}
 	return false
 }
+
+// isInCronWindow reports whether the cron schedule fired within the window's
+// duration before at, evaluating the expression in the window's timezone.
+// Next returns the first firing strictly after its argument, so a firing f
+// covers at exactly when at-duration < f <= at.
+func isInCronWindow(mw *MaintenanceWindow, at time.Time) bool {
+	if mw.DurationMinutes <= 0 {
+		return false
+	}
+	expr, err := cronexpr.Parse(mw.CronExpr)
+	if err != nil {
+		return false
+	}
+	at = at.In(mw.Location())
+	fired := expr.Next(at.Add(-time.Duration(mw.DurationMinutes) * time.Minute))
+	return !fired.IsZero() && !fired.After(at)
+}
+
+// maxRecurrenceSearchDays bounds the day-by-day search for the next
+// occurrence. A monthly window on the 31st can skip months, but always
+// recurs within a year.
+const maxRecurrenceSearchDays = 400
+
+// NextMaintenanceOccurrence returns the first occurrence of mw that ends
+// after the given time, following the same rules as isInWindow. If after
+// falls inside an occurrence, that occurrence is returned. ok is false when
+// the window never opens again, such as a one-off window in the past.
+func NextMaintenanceOccurrence(mw *MaintenanceWindow, after time.Time) (start, end time.Time, ok bool) {
+	switch mw.Recurring {
+	case "":
+		if mw.EndTime.After(after) {
+			return mw.StartTime, mw.EndTime, true
+		}
+		return time.Time{}, time.Time{}, false
+	case "cron":
+		if mw.DurationMinutes <= 0 {
+			return time.Time{}, time.Time{}, false
+		}
+		expr, err := cronexpr.Parse(mw.CronExpr)
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		duration := time.Duration(mw.DurationMinutes) * time.Minute
+		start = expr.Next(after.In(mw.Location()).Add(-duration))
+		if start.IsZero() {
+			return time.Time{}, time.Time{}, false
+		}
+		return start, start.Add(duration), true
+	}
+
+	loc := mw.Location()
+	first := mw.StartTime.In(loc)
+	duration := mw.EndTime.Sub(mw.StartTime)
+	if duration <= 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	// Start a day early so a daily window that wraps past midnight and is
+	// still open is found.
+	day := after.In(loc).AddDate(0, 0, -1)
+	for i := 0; i < maxRecurrenceSearchDays; i++ {
+		d := day.AddDate(0, 0, i)
+		switch mw.Recurring {
+		case "daily":
+		case "weekly":
+			if d.Weekday() != first.Weekday() {
+				continue
+			}
+		case "monthly":
+			if d.Day() != first.Day() {
+				continue
+			}
+		default:
+			return time.Time{}, time.Time{}, false
+		}
+		start = time.Date(d.Year(), d.Month(), d.Day(), first.Hour(), first.Minute(), first.Second(), 0, loc)
+		end = start.Add(duration)
+		if mw.Recurring != "daily" {
+			// isInWindow only matches weekly and monthly windows on their
+			// own day, so they close at midnight.
+			if midnight := time.Date(d.Year(), d.Month(), d.Day()+1, 0, 0, 0, 0, loc); end.After(midnight) {
+				end = midnight
+			}
+		}
+		if end.After(after) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
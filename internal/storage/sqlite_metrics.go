@@ -84,7 +84,7 @@ func (s *SQLiteStore) GetResponseTimeSeries(ctx context.Context, monitorID int64
 	var rows *sql.Rows
 	if count <= int64(maxPoints) {
 		rows, err = s.readDB.QueryContext(ctx,
-			`SELECT created_at, response_time, status
+			`SELECT created_at, response_time, 1, status
 			 FROM check_results
 			 WHERE monitor_id=? AND created_at >= ? AND created_at < ?
 			 ORDER BY created_at ASC`,
@@ -97,6 +97,7 @@ func (s *SQLiteStore) GetResponseTimeSeries(ctx context.Context, monitorID int64
 		rows, err = s.readDB.QueryContext(ctx,
 			`SELECT MIN(created_at) as created_at,
 			        CAST(AVG(response_time) AS INTEGER) as response_time,
+			        COUNT(*) as checks,
 			        CASE
 			            WHEN SUM(CASE WHEN status='down' THEN 1 ELSE 0 END) > 0 THEN 'down'
 			            WHEN SUM(CASE WHEN status='degraded' THEN 1 ELSE 0 END) > 0 THEN 'degraded'
@@ -116,9 +117,9 @@ func (s *SQLiteStore) GetResponseTimeSeries(ctx context.Context, monitorID int64
 	var points []*TimeSeriesPoint
 	for rows.Next() {
 		var createdAt string
-		var rt int64
+		var rt, checks int64
 		var status string
-		if err := rows.Scan(&createdAt, &rt, &status); err != nil {
+		if err := rows.Scan(&createdAt, &rt, &checks, &status); err != nil {
 			return nil, fmt.Errorf("scan time series: %w", err)
 		}
 		t := parseTime(createdAt)
@@ -126,6 +127,7 @@ func (s *SQLiteStore) GetResponseTimeSeries(ctx context.Context, monitorID int64
 			Timestamp:    t.Unix(),
 			ResponseTime: rt,
 			Status:       status,
+			Checks:       checks,
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -137,6 +139,67 @@ func (s *SQLiteStore) GetResponseTimeSeries(ctx context.Context, monitorID int64
 	return points, nil
 }
 
+// GetTimeSeries splits [from, to) into at most maxPoints equal buckets and
+// returns one point per bucket, in order: the bucket start, the average
+// response time and the worst status of its checks. Buckets without checks
+// are kept with Checks 0 so charts can draw them as gaps.
+func (s *SQLiteStore) GetTimeSeries(ctx context.Context, monitorID int64, from, to time.Time, maxPoints int) ([]TimeSeriesPoint, error) {
+	span := int64(to.Sub(from).Seconds())
+	if span < 1 {
+		span = 1
+	}
+	if maxPoints < 1 {
+		maxPoints = 1
+	}
+	bucketSecs := (span + int64(maxPoints) - 1) / int64(maxPoints)
+	buckets := (span + bucketSecs - 1) / bucketSecs
+
+	start := from.Unix()
+	points := make([]TimeSeriesPoint, buckets)
+	for i := range points {
+		points[i].Timestamp = start + int64(i)*bucketSecs
+	}
+
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT (CAST(strftime('%s', created_at) AS INTEGER) - ?) / ? AS bucket,
+		        COUNT(*),
+		        CAST(AVG(response_time) AS INTEGER),
+		        MAX(CASE status WHEN 'down' THEN 2 WHEN 'degraded' THEN 1 ELSE 0 END)
+		 FROM check_results
+		 WHERE monitor_id=? AND created_at >= ? AND created_at < ?
+		 GROUP BY bucket`,
+		start, bucketSecs, monitorID, formatTime(from), formatTime(to))
+	if err != nil {
+		return nil, fmt.Errorf("query time series: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, checks, rt, severity int64
+		if err := rows.Scan(&bucket, &checks, &rt, &severity); err != nil {
+			return nil, fmt.Errorf("scan time series: %w", err)
+		}
+		if bucket < 0 || bucket >= buckets {
+			continue
+		}
+		p := &points[bucket]
+		p.Checks = checks
+		p.ResponseTime = rt
+		switch severity {
+		case 2:
+			p.Status = "down"
+		case 1:
+			p.Status = "degraded"
+		default:
+			p.Status = "up"
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate time series: %w", err)
+	}
+	return points, nil
+}
+
 func (s *SQLiteStore) GetUptimePercent(ctx context.Context, monitorID int64, from, to time.Time) (float64, error) {
 	var total, up int64
 	err := s.readDB.QueryRowContext(ctx,
@@ -170,6 +233,42 @@ func (s *SQLiteStore) GetResponseTimePercentiles(ctx context.Context, monitorID
 	return
 }
 
+func (s *SQLiteStore) GetApdex(ctx context.Context, monitorID int64, from, to time.Time, thresholdMs int64, failedAsFrustrated bool) (*ApdexReport, error) {
+	rep := &ApdexReport{
+		MonitorID:          monitorID,
+		From:               from,
+		To:                 to,
+		ThresholdMs:        thresholdMs,
+		FailedAsFrustrated: failedAsFrustrated,
+	}
+	var slow, failed int64
+	err := s.readDB.QueryRowContext(ctx,
+		`SELECT
+		   COALESCE(SUM(CASE WHEN status='up' AND response_time <= ? THEN 1 ELSE 0 END), 0),
+		   COALESCE(SUM(CASE WHEN status='up' AND response_time > ? AND response_time <= ? THEN 1 ELSE 0 END), 0),
+		   COALESCE(SUM(CASE WHEN status='up' AND response_time > ? THEN 1 ELSE 0 END), 0),
+		   COALESCE(SUM(CASE WHEN status<>'up' THEN 1 ELSE 0 END), 0)
+		 FROM check_results WHERE monitor_id=? AND created_at >= ? AND created_at < ?`,
+		thresholdMs, thresholdMs, 4*thresholdMs, 4*thresholdMs,
+		monitorID, formatTime(from), formatTime(to)).Scan(&rep.Satisfied, &rep.Tolerating, &slow, &failed)
+	if err != nil {
+		return nil, err
+	}
+
+	rep.Frustrated = slow
+	if failedAsFrustrated {
+		rep.Frustrated += failed
+	} else {
+		rep.Excluded = failed
+	}
+	rep.Samples = rep.Satisfied + rep.Tolerating + rep.Frustrated
+	if rep.Samples > 0 {
+		score := (float64(rep.Satisfied) + float64(rep.Tolerating)/2) / float64(rep.Samples)
+		rep.Score = &score
+	}
+	return rep, nil
+}
+
 func (s *SQLiteStore) GetLatestResponseTimes(ctx context.Context) (map[int64]int64, error) {
 	rows, err := s.readDB.QueryContext(ctx,
 		`SELECT cr.monitor_id, cr.response_time
@@ -206,6 +305,162 @@ func (s *SQLiteStore) GetCheckCounts(ctx context.Context, monitorID int64, from,
 	return
 }
 
+// GetSLAReport computes a monitor's SLA report. Incidents overlapping the
+// measured range count toward it; an incident still open runs until now.
+func (s *SQLiteStore) GetSLAReport(ctx context.Context, monitorID int64, from, to time.Time) (*SLAReport, error) {
+	var createdAt string
+	err := s.readDB.QueryRowContext(ctx, `SELECT created_at FROM monitors WHERE id=?`, monitorID).Scan(&createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	rep := &SLAReport{MonitorID: monitorID, From: from.UTC(), To: to.UTC()}
+	start, end := rep.From, rep.To
+	if c := parseTime(createdAt); c.After(start) {
+		start = c
+	}
+	if end.After(now) {
+		end = now
+	}
+	rep.MeasuredFrom, rep.MeasuredTo = start, end
+	if !start.Before(end) {
+		rep.NoData = true
+		return rep, nil
+	}
+
+	// No check runs in the future, so count up to To rather than now: a check
+	// from the current second is still included.
+	rep.TotalChecks, _, rep.FailedChecks, _, err = s.GetCheckCounts(ctx, monitorID, start, rep.To)
+	if err != nil {
+		return nil, fmt.Errorf("sla check counts: %w", err)
+	}
+
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT id, started_at, resolved_at FROM incidents
+		 WHERE monitor_id=? AND started_at < ? AND (resolved_at IS NULL OR resolved_at > ?)
+		 ORDER BY started_at`,
+		monitorID, formatTime(end), formatTime(start))
+	if err != nil {
+		return nil, fmt.Errorf("sla incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var downtime, longest, repairTotal time.Duration
+	var resolvedCount int64
+	var covered time.Time
+	for rows.Next() {
+		var id int64
+		var startedAt string
+		var resolvedAt sql.NullString
+		if err := rows.Scan(&id, &startedAt, &resolvedAt); err != nil {
+			return nil, fmt.Errorf("scan sla incident: %w", err)
+		}
+		rep.IncidentCount++
+
+		started := parseTime(startedAt)
+		stop := now
+		if resolved := parseTimePtr(resolvedAt); resolved != nil {
+			stop = *resolved
+			if stop.Before(end) {
+				repairTotal += stop.Sub(started)
+				resolvedCount++
+			}
+		}
+		if d := stop.Sub(started); rep.LongestIncidentID == nil || d > longest {
+			longest = d
+			rep.LongestIncidentID = &id
+		}
+
+		// Clip to the measured range and skip time an earlier incident
+		// already covered.
+		a, b := started, stop
+		if a.Before(start) {
+			a = start
+		}
+		if a.Before(covered) {
+			a = covered
+		}
+		if b.After(end) {
+			b = end
+		}
+		if b.After(a) {
+			downtime += b.Sub(a)
+			covered = b
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sla incidents: %w", err)
+	}
+
+	if rep.TotalChecks == 0 && rep.IncidentCount == 0 {
+		rep.NoData = true
+		return rep, nil
+	}
+	rep.DowntimeSeconds = int64(downtime.Seconds())
+	rep.LongestIncidentSeconds = int64(longest.Seconds())
+	if resolvedCount > 0 {
+		rep.MTTRSeconds = int64(repairTotal.Seconds()) / resolvedCount
+	}
+	uptime := float64(end.Sub(start)-downtime) / float64(end.Sub(start)) * 100
+	rep.UptimePct = &uptime
+	return rep, nil
+}
+
+// GetGroupSLAReport builds the SLA report of each monitor in a group and
+// combines them. It returns sql.ErrNoRows when the group does not exist.
+func (s *SQLiteStore) GetGroupSLAReport(ctx context.Context, groupID int64, from, to time.Time) (*GroupSLAReport, error) {
+	var exists int
+	if err := s.readDB.QueryRowContext(ctx, `SELECT 1 FROM monitor_groups WHERE id=?`, groupID).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.readDB.QueryContext(ctx, `SELECT id FROM monitors WHERE group_id=? ORDER BY name`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("group sla monitors: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan group sla monitor: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate group sla monitors: %w", err)
+	}
+
+	rep := &GroupSLAReport{GroupID: groupID, From: from.UTC(), To: to.UTC(), Monitors: []*SLAReport{}}
+	var measured, up float64
+	for _, id := range ids {
+		mr, err := s.GetSLAReport(ctx, id, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("group sla monitor %d: %w", id, err)
+		}
+		rep.Monitors = append(rep.Monitors, mr)
+		if mr.NoData {
+			continue
+		}
+		span := mr.MeasuredTo.Sub(mr.MeasuredFrom).Seconds()
+		measured += span
+		up += span * *mr.UptimePct / 100
+		rep.DowntimeSeconds += mr.DowntimeSeconds
+		rep.IncidentCount += mr.IncidentCount
+		rep.TotalChecks += mr.TotalChecks
+		rep.FailedChecks += mr.FailedChecks
+	}
+	if measured == 0 {
+		rep.NoData = true
+		return rep, nil
+	}
+	uptime := up / measured * 100
+	rep.UptimePct = &uptime
+	return rep, nil
+}
+
 func (s *SQLiteStore) CountMonitorsByStatus(ctx context.Context) (up, down, degraded, paused int64, err error) {
 	err = s.readDB.QueryRowContext(ctx,
 		`SELECT
@@ -218,3 +473,196 @@ func (s *SQLiteStore) CountMonitorsByStatus(ctx context.Context) (up, down, degr
 		Scan(&up, &down, &degraded, &paused)
 	return
 }
+
+// RecomputeLatencyBaselines replaces a monitor's hourly baselines with the
+// mean and standard deviation of its successful checks since the given time.
+func (s *SQLiteStore) RecomputeLatencyBaselines(ctx context.Context, monitorID int64, since time.Time) error {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT CAST(substr(created_at, 12, 2) AS INTEGER) AS hour,
+		        AVG(response_time), AVG(response_time * response_time), COUNT(*)
+		 FROM check_results
+		 WHERE monitor_id=? AND created_at >= ? AND status='up'
+		 GROUP BY hour`,
+		monitorID, formatTime(since))
+	if err != nil {
+		return fmt.Errorf("compute latency baselines: %w", err)
+	}
+	var baselines []LatencyBaseline
+	for rows.Next() {
+		var b LatencyBaseline
+		var meanSq float64
+		if err := rows.Scan(&b.Hour, &b.Mean, &meanSq, &b.Samples); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan latency baseline: %w", err)
+		}
+		b.StdDev = math.Sqrt(math.Max(meanSq-b.Mean*b.Mean, 0))
+		baselines = append(baselines, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate latency baselines: %w", err)
+	}
+
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("latency baselines begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM latency_baselines WHERE monitor_id=?`, monitorID); err != nil {
+		return err
+	}
+	now := formatTime(time.Now())
+	for _, b := range baselines {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO latency_baselines (monitor_id, hour, mean_ms, stddev_ms, samples, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?)`,
+			monitorID, b.Hour, b.Mean, b.StdDev, b.Samples, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetLatencyBaseline(ctx context.Context, monitorID int64, hour int) (*LatencyBaseline, error) {
+	var b LatencyBaseline
+	var updatedAt string
+	err := s.readDB.QueryRowContext(ctx,
+		`SELECT monitor_id, hour, mean_ms, stddev_ms, samples, updated_at
+		 FROM latency_baselines WHERE monitor_id=? AND hour=?`, monitorID, hour).
+		Scan(&b.MonitorID, &b.Hour, &b.Mean, &b.StdDev, &b.Samples, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	b.UpdatedAt = parseTime(updatedAt)
+	return &b, nil
+}
+
+func (s *SQLiteStore) ListLatencyBaselines(ctx context.Context, monitorID int64) ([]*LatencyBaseline, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT monitor_id, hour, mean_ms, stddev_ms, samples, updated_at
+		 FROM latency_baselines WHERE monitor_id=? ORDER BY hour`, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*LatencyBaseline{}
+	for rows.Next() {
+		var b LatencyBaseline
+		var updatedAt string
+		if err := rows.Scan(&b.MonitorID, &b.Hour, &b.Mean, &b.StdDev, &b.Samples, &updatedAt); err != nil {
+			return nil, err
+		}
+		b.UpdatedAt = parseTime(updatedAt)
+		result = append(result, &b)
+	}
+	return result, rows.Err()
+}
+
+// maxGroupUptimeBuckets caps the buckets GetGroupUptime tracks per monitor;
+// longer ranges get wider buckets.
+const maxGroupUptimeBuckets = 100000
+
+// GetGroupUptime computes a group's combined uptime in mode GroupUptimeAll or
+// GroupUptimeAny, or returns sql.ErrNoRows if the group does not exist. A
+// check that isn't up marks its monitor down for the whole bucket.
+func (s *SQLiteStore) GetGroupUptime(ctx context.Context, groupID int64, from, to time.Time, mode string) (*GroupUptime, error) {
+	if mode != GroupUptimeAll && mode != GroupUptimeAny {
+		return nil, fmt.Errorf("unknown group uptime mode %q", mode)
+	}
+	var exists int
+	if err := s.readDB.QueryRowContext(ctx, `SELECT 1 FROM monitor_groups WHERE id=?`, groupID).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	gu := &GroupUptime{GroupID: groupID, Mode: mode, From: from.UTC(), To: to.UTC()}
+	var maxInterval sql.NullInt64
+	err := s.readDB.QueryRowContext(ctx,
+		`SELECT COUNT(*), MAX(interval_secs) FROM monitors WHERE group_id=?`, groupID).Scan(&gu.Monitors, &maxInterval)
+	if err != nil {
+		return nil, fmt.Errorf("group uptime monitors: %w", err)
+	}
+	bucket := maxInterval.Int64
+	if bucket < 1 {
+		bucket = 60
+	}
+	start, end := gu.From.Unix(), gu.To.Unix()
+	if span := end - start; span/bucket >= maxGroupUptimeBuckets {
+		bucket = span/maxGroupUptimeBuckets + 1
+	}
+	gu.BucketSeconds = bucket
+	if end <= start {
+		return gu, nil
+	}
+	aligned := start - start%bucket
+	n := (end - aligned + bucket - 1) / bucket
+
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT cr.monitor_id, cr.status, cr.created_at
+		 FROM check_results cr
+		 JOIN monitors m ON m.id = cr.monitor_id
+		 WHERE m.group_id=? AND cr.created_at >= ? AND cr.created_at < ?`,
+		groupID, formatTime(from), formatTime(to))
+	if err != nil {
+		return nil, fmt.Errorf("group uptime checks: %w", err)
+	}
+	defer rows.Close()
+
+	const (
+		bucketUp   = 1
+		bucketDown = 2
+	)
+	states := make(map[int64][]int8)
+	for rows.Next() {
+		var monitorID int64
+		var status, createdAt string
+		if err := rows.Scan(&monitorID, &status, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan group uptime check: %w", err)
+		}
+		i := (parseTime(createdAt).Unix() - aligned) / bucket
+		if i < 0 || i >= n {
+			continue
+		}
+		st := states[monitorID]
+		if st == nil {
+			st = make([]int8, n)
+			states[monitorID] = st
+		}
+		switch {
+		case status != "up":
+			st[i] = bucketDown
+		case st[i] == 0:
+			st[i] = bucketUp
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate group uptime checks: %w", err)
+	}
+
+	for i := int64(0); i < n; i++ {
+		var seen, up int
+		for _, st := range states {
+			if st[i] == 0 {
+				continue
+			}
+			seen++
+			if st[i] == bucketUp {
+				up++
+			}
+		}
+		switch {
+		case seen == 0:
+			gu.NoDataBuckets++
+		case mode == GroupUptimeAll && up == seen, mode == GroupUptimeAny && up > 0:
+			gu.UpBuckets++
+		default:
+			gu.DownBuckets++
+		}
+	}
+	if measured := gu.UpBuckets + gu.DownBuckets; measured > 0 {
+		pct := float64(gu.UpBuckets) / float64(measured) * 100
+		gu.UptimePct = &pct
+	}
+	return gu, nil
+}
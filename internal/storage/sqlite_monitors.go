@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"strings"
@@ -11,21 +12,83 @@ import (
 )
 
 func (s *SQLiteStore) CreateMonitor(ctx context.Context, m *Monitor) error {
-	tags, _ := json.Marshal(m.Tags)
-	if m.Settings == nil {
-		m.Settings = json.RawMessage("{}")
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("create monitor begin: %w", err)
 	}
-	if m.Assertions == nil {
-		m.Assertions = json.RawMessage("[]")
+	defer tx.Rollback()
+
+	if err := s.checkMonitorLimits(ctx, tx, m.GroupID, 1, nil); err != nil {
+		return err
 	}
 	now := formatTime(time.Now())
+	if err := insertMonitor(ctx, tx, m, now); err != nil {
+		return err
+	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("create monitor commit: %w", err)
+	}
+	setMonitorCreated(m, now)
+	return nil
+}
+
+// CreateMonitors inserts monitors in one transaction together with the
+// notification channels and filters, escalation policy, probes and tags set
+// on each. Either all of them are created or none.
+func (s *SQLiteStore) CreateMonitors(ctx context.Context, monitors []*Monitor) error {
 	tx, err := s.writeDB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("create monitor begin: %w", err)
+		return fmt.Errorf("create monitors begin: %w", err)
 	}
 	defer tx.Rollback()
 
+	if err := s.checkMonitorLimits(ctx, tx, nil, len(monitors), nil); err != nil {
+		return err
+	}
+	byGroup := make(map[int64]int)
+	for _, m := range monitors {
+		if m.GroupID != nil {
+			byGroup[*m.GroupID]++
+		}
+	}
+	for groupID, n := range byGroup {
+		if err := s.checkMonitorLimits(ctx, tx, &groupID, n, nil); err != nil {
+			return err
+		}
+	}
+
+	now := formatTime(time.Now())
+	for _, m := range monitors {
+		if err := insertMonitor(ctx, tx, m, now); err != nil {
+			return err
+		}
+		if err := insertMonitorLinks(ctx, tx, m); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("create monitors commit: %w", err)
+	}
+	for _, m := range monitors {
+		setMonitorCreated(m, now)
+	}
+	return nil
+}
+
+// insertMonitor inserts m and its pending status row. m.ID is set on
+// success; the timestamps are left for the caller to set after commit.
+func insertMonitor(ctx context.Context, tx *sql.Tx, m *Monitor, now string) error {
+	tags, _ := json.Marshal(m.Tags)
+	redact, _ := json.Marshal(m.RedactPatterns)
+	if m.Settings == nil {
+		m.Settings = json.RawMessage("{}")
+	}
+	if m.Assertions == nil {
+		m.Assertions = json.RawMessage("[]")
+	}
+
 	var groupID any
 	if m.GroupID != nil {
 		groupID = *m.GroupID
@@ -35,11 +98,12 @@ func (s *SQLiteStore) CreateMonitor(ctx context.Context, m *Monitor) error {
 		proxyID = *m.ProxyID
 	}
 	res, err := tx.ExecContext(ctx,
-		`INSERT INTO monitors (name, description, type, target, interval_secs, timeout_secs, enabled, tags, settings, assertions, track_changes, failure_threshold, success_threshold, upside_down, resend_interval, group_id, proxy_id, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO monitors (name, description, type, target, interval_secs, timeout_secs, enabled, tags, settings, assertions, track_changes, failure_threshold, success_threshold, upside_down, resend_interval, group_id, proxy_id, ack_silences_reminders, latency_baseline_sigma, skip_default_channel, capture_failure_context, owner, stream_checks_every, retries, retry_interval, redact_patterns, degraded_threshold, flap_window_seconds, parent_id, connect_timeout_secs, max_response_time_ms, warmup_seconds, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		m.Name, m.Description, m.Type, m.Target, m.Interval, m.Timeout, boolToInt(m.Enabled),
 		string(tags), string(m.Settings), string(m.Assertions), boolToInt(m.TrackChanges),
-		m.FailureThreshold, m.SuccessThreshold, boolToInt(m.UpsideDown), m.ResendInterval, groupID, proxyID, now, now,
+		m.FailureThreshold, m.SuccessThreshold, boolToInt(m.UpsideDown), m.ResendInterval, groupID, proxyID,
+		nullBool(m.AckSilencesReminders), m.LatencyBaselineSigma, boolToInt(m.SkipDefaultChannel), boolToInt(m.CaptureFailureContext), m.Owner, m.StreamChecksEvery, m.Retries, m.RetryInterval, string(redact), m.DegradedThreshold, m.FlapWindowSeconds, nullInt64(m.ParentID), m.ConnectTimeout, m.MaxResponseTimeMs, m.WarmupSeconds, now, now,
 	)
 	if err != nil {
 		return err
@@ -53,22 +117,60 @@ func (s *SQLiteStore) CreateMonitor(ctx context.Context, m *Monitor) error {
 		`INSERT INTO monitor_status (monitor_id, status) VALUES (?, 'pending')`, id); err != nil {
 		return err
 	}
+	m.ID = id
+	return nil
+}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("create monitor commit: %w", err)
+// insertMonitorLinks writes the channel, filter, escalation policy, probe and
+// tag links carried on a freshly inserted monitor.
+func insertMonitorLinks(ctx context.Context, tx *sql.Tx, m *Monitor) error {
+	for _, cid := range m.NotificationChannelIDs {
+		var filter string
+		if f := m.NotificationFilters[cid]; f != nil && !f.IsZero() {
+			raw, err := json.Marshal(f)
+			if err != nil {
+				return err
+			}
+			filter = string(raw)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO monitor_notifications (monitor_id, channel_id, event_filter) VALUES (?, ?, ?)`,
+			m.ID, cid, filter); err != nil {
+			return err
+		}
+	}
+	if m.EscalationPolicyID != nil {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO monitor_escalation_policies (monitor_id, policy_id) VALUES (?, ?)`,
+			m.ID, *m.EscalationPolicyID); err != nil {
+			return err
+		}
+	}
+	for _, pid := range m.ProbeIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO monitor_probes (monitor_id, probe_id) VALUES (?, ?)`, m.ID, pid); err != nil {
+			return err
+		}
 	}
+	for _, t := range m.MonitorTags {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO monitor_tags (monitor_id, tag_id, value) VALUES (?, ?, ?)`, m.ID, t.TagID, t.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	m.ID = id
+func setMonitorCreated(m *Monitor, now string) {
 	m.CreatedAt = parseTime(now)
 	m.UpdatedAt = parseTime(now)
-	return nil
 }
 
 func (s *SQLiteStore) GetMonitor(ctx context.Context, id int64) (*Monitor, error) {
 	row := s.readDB.QueryRowContext(ctx,
 		`SELECT m.id, m.name, m.description, m.type, m.target, m.interval_secs, m.timeout_secs, m.enabled,
 		        m.tags, m.settings, m.assertions, m.track_changes, m.failure_threshold, m.success_threshold,
-		        m.upside_down, m.resend_interval, m.group_id, m.proxy_id, m.created_at, m.updated_at,
+		        m.upside_down, m.resend_interval, m.group_id, m.proxy_id, m.ack_silences_reminders, m.latency_baseline_sigma, m.skip_default_channel, m.capture_failure_context, m.owner, m.stream_checks_every, m.retries, m.retry_interval, m.redact_patterns, m.degraded_threshold, m.flap_window_seconds, m.parent_id, m.connect_timeout_secs, m.max_response_time_ms, m.warmup_seconds, m.created_at, m.updated_at,
 		        COALESCE(ms.status, 'pending'), ms.last_check_at, COALESCE(ms.consec_fails, 0), COALESCE(ms.consec_successes, 0)
 		 FROM monitors m
 		 LEFT JOIN monitor_status ms ON ms.monitor_id = m.id
@@ -92,9 +194,35 @@ func (s *SQLiteStore) ListMonitors(ctx context.Context, f MonitorListFilter, p P
 		where += " AND m.group_id=?"
 		args = append(args, *f.GroupID)
 	}
-	if f.TagID != nil {
-		where += " AND EXISTS (SELECT 1 FROM monitor_tags WHERE monitor_id=m.id AND tag_id=?)"
-		args = append(args, *f.TagID)
+	if len(f.TagIDs) > 0 {
+		if f.TagMatch == TagMatchAny {
+			placeholders, idArgs := bulkArgs(f.TagIDs)
+			where += " AND EXISTS (SELECT 1 FROM monitor_tags WHERE monitor_id=m.id AND tag_id IN (" + placeholders + "))"
+			args = append(args, idArgs...)
+		} else {
+			for _, id := range f.TagIDs {
+				where += " AND EXISTS (SELECT 1 FROM monitor_tags WHERE monitor_id=m.id AND tag_id=?)"
+				args = append(args, id)
+			}
+		}
+	}
+	if f.Owner != "" {
+		where += " AND m.owner=?"
+		args = append(args, f.Owner)
+	}
+	if f.Scope != nil {
+		cond := "0=1"
+		if len(f.Scope.GroupIDs) > 0 {
+			placeholders, idArgs := bulkArgs(f.Scope.GroupIDs)
+			cond += " OR m.group_id IN (" + placeholders + ")"
+			args = append(args, idArgs...)
+		}
+		if len(f.Scope.TagIDs) > 0 {
+			placeholders, idArgs := bulkArgs(f.Scope.TagIDs)
+			cond += " OR EXISTS (SELECT 1 FROM monitor_tags WHERE monitor_id=m.id AND tag_id IN (" + placeholders + "))"
+			args = append(args, idArgs...)
+		}
+		where += " AND (" + cond + ")"
 	}
 	if f.Status == "paused" {
 		where += " AND m.enabled=0"
@@ -127,7 +255,7 @@ func (s *SQLiteStore) ListMonitors(ctx context.Context, f MonitorListFilter, p P
 	rows, err := s.readDB.QueryContext(ctx,
 		`SELECT m.id, m.name, m.description, m.type, m.target, m.interval_secs, m.timeout_secs, m.enabled,
 		        m.tags, m.settings, m.assertions, m.track_changes, m.failure_threshold, m.success_threshold,
-		        m.upside_down, m.resend_interval, m.group_id, m.proxy_id, m.created_at, m.updated_at,
+		        m.upside_down, m.resend_interval, m.group_id, m.proxy_id, m.ack_silences_reminders, m.latency_baseline_sigma, m.skip_default_channel, m.capture_failure_context, m.owner, m.stream_checks_every, m.retries, m.retry_interval, m.redact_patterns, m.degraded_threshold, m.flap_window_seconds, m.parent_id, m.connect_timeout_secs, m.max_response_time_ms, m.warmup_seconds, m.created_at, m.updated_at,
 		        COALESCE(ms.status, 'pending'), ms.last_check_at, COALESCE(ms.consec_fails, 0), COALESCE(ms.consec_successes, 0)
 		 FROM monitors m
 		 LEFT JOIN monitor_status ms ON ms.monitor_id = m.id
@@ -165,6 +293,7 @@ func (s *SQLiteStore) ListMonitors(ctx context.Context, f MonitorListFilter, p P
 
 func (s *SQLiteStore) UpdateMonitor(ctx context.Context, m *Monitor) error {
 	tags, _ := json.Marshal(m.Tags)
+	redact, _ := json.Marshal(m.RedactPatterns)
 	now := formatTime(time.Now())
 	var groupID any
 	if m.GroupID != nil {
@@ -174,21 +303,56 @@ func (s *SQLiteStore) UpdateMonitor(ctx context.Context, m *Monitor) error {
 	if m.ProxyID != nil {
 		proxyID = *m.ProxyID
 	}
-	_, err := s.writeDB.ExecContext(ctx,
+
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("update monitor begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if m.GroupID != nil {
+		var current sql.NullInt64
+		err := tx.QueryRowContext(ctx, "SELECT group_id FROM monitors WHERE id=?", m.ID).Scan(&current)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if !current.Valid || current.Int64 != *m.GroupID {
+			if err := s.checkMonitorLimits(ctx, tx, m.GroupID, 0, []int64{m.ID}); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
 		`UPDATE monitors SET name=?, description=?, type=?, target=?, interval_secs=?, timeout_secs=?, enabled=?,
 		 tags=?, settings=?, assertions=?, track_changes=?, failure_threshold=?, success_threshold=?,
-		 upside_down=?, resend_interval=?, group_id=?, proxy_id=?, updated_at=?
+		 upside_down=?, resend_interval=?, group_id=?, proxy_id=?, ack_silences_reminders=?, latency_baseline_sigma=?, skip_default_channel=?, capture_failure_context=?, owner=?, stream_checks_every=?, retries=?, retry_interval=?, redact_patterns=?, degraded_threshold=?, flap_window_seconds=?, parent_id=?, connect_timeout_secs=?, max_response_time_ms=?, warmup_seconds=?, updated_at=?
 		 WHERE id=?`,
 		m.Name, m.Description, m.Type, m.Target, m.Interval, m.Timeout, boolToInt(m.Enabled),
 		string(tags), string(m.Settings), string(m.Assertions), boolToInt(m.TrackChanges),
-		m.FailureThreshold, m.SuccessThreshold, boolToInt(m.UpsideDown), m.ResendInterval, groupID, proxyID, now, m.ID,
+		m.FailureThreshold, m.SuccessThreshold, boolToInt(m.UpsideDown), m.ResendInterval, groupID, proxyID,
+		nullBool(m.AckSilencesReminders), m.LatencyBaselineSigma, boolToInt(m.SkipDefaultChannel), boolToInt(m.CaptureFailureContext), m.Owner, m.StreamChecksEvery, m.Retries, m.RetryInterval, string(redact), m.DegradedThreshold, m.FlapWindowSeconds, nullInt64(m.ParentID), m.ConnectTimeout, m.MaxResponseTimeMs, m.WarmupSeconds, now, m.ID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *SQLiteStore) DeleteMonitor(ctx context.Context, id int64) error {
-	_, err := s.writeDB.ExecContext(ctx, "DELETE FROM monitors WHERE id=?", id)
-	return err
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("delete monitor begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE monitors SET parent_id=NULL WHERE parent_id=?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM monitors WHERE id=?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *SQLiteStore) SetMonitorEnabled(ctx context.Context, id int64, enabled bool) error {
@@ -218,12 +382,27 @@ func (s *SQLiteStore) BulkDeleteMonitors(ctx context.Context, ids []int64) (int6
 		return 0, nil
 	}
 	placeholders, args := bulkArgs(ids)
-	res, err := s.writeDB.ExecContext(ctx,
+
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("bulk delete monitors begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE monitors SET parent_id=NULL WHERE parent_id IN ("+placeholders+")", args...); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx,
 		"DELETE FROM monitors WHERE id IN ("+placeholders+")", args...)
 	if err != nil {
 		return 0, err
 	}
-	return res.RowsAffected()
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
 }
 
 func (s *SQLiteStore) BulkSetMonitorGroup(ctx context.Context, ids []int64, groupID *int64) (int64, error) {
@@ -236,12 +415,104 @@ func (s *SQLiteStore) BulkSetMonitorGroup(ctx context.Context, ids []int64, grou
 		gid = *groupID
 	}
 	args = append([]any{gid, formatTime(time.Now())}, args...)
-	res, err := s.writeDB.ExecContext(ctx,
+
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("bulk set group begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.checkMonitorLimits(ctx, tx, groupID, 0, ids); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx,
 		"UPDATE monitors SET group_id=?, updated_at=? WHERE id IN ("+placeholders+")", args...)
 	if err != nil {
 		return 0, err
 	}
-	return res.RowsAffected()
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// checkMonitorLimits verifies that adding new monitors to groupID and moving
+// the monitors in moved into it keeps both configured limits satisfied.
+func (s *SQLiteStore) checkMonitorLimits(ctx context.Context, tx *sql.Tx, groupID *int64, adding int, moved []int64) error {
+	if adding > 0 && s.limits.MaxMonitors > 0 {
+		var total int64
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM monitors").Scan(&total); err != nil {
+			return fmt.Errorf("count monitors: %w", err)
+		}
+		if total+int64(adding) > int64(s.limits.MaxMonitors) {
+			return fmt.Errorf("%w: instance allows at most %d monitors", ErrMonitorLimit, s.limits.MaxMonitors)
+		}
+	}
+
+	if groupID == nil || s.limits.MaxMonitorsPerGroup <= 0 {
+		return nil
+	}
+	q := "SELECT COUNT(*) FROM monitors WHERE group_id=?"
+	args := []any{*groupID}
+	if len(moved) > 0 {
+		placeholders, idArgs := bulkArgs(moved)
+		q += " OR id IN (" + placeholders + ")"
+		args = append(args, idArgs...)
+	}
+	var inGroup int64
+	if err := tx.QueryRowContext(ctx, q, args...).Scan(&inGroup); err != nil {
+		return fmt.Errorf("count group monitors: %w", err)
+	}
+	if inGroup+int64(adding) > int64(s.limits.MaxMonitorsPerGroup) {
+		return fmt.Errorf("%w: group allows at most %d monitors", ErrMonitorLimit, s.limits.MaxMonitorsPerGroup)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) CountMonitors(ctx context.Context) (int64, map[int64]int64, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		"SELECT group_id, COUNT(*) FROM monitors GROUP BY group_id")
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	var total int64
+	byGroup := make(map[int64]int64)
+	for rows.Next() {
+		var gid sql.NullInt64
+		var n int64
+		if err := rows.Scan(&gid, &n); err != nil {
+			return 0, nil, err
+		}
+		total += n
+		if gid.Valid {
+			byGroup[gid.Int64] = n
+		}
+	}
+	return total, byGroup, rows.Err()
+}
+
+// ListMonitorParents maps each monitor that has a parent to its parent's ID.
+func (s *SQLiteStore) ListMonitorParents(ctx context.Context) (map[int64]int64, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		"SELECT id, parent_id FROM monitors WHERE parent_id IS NOT NULL")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parents := make(map[int64]int64)
+	for rows.Next() {
+		var id, parentID int64
+		if err := rows.Scan(&id, &parentID); err != nil {
+			return nil, err
+		}
+		parents[id] = parentID
+	}
+	return parents, rows.Err()
 }
 
 func bulkArgs(ids []int64) (string, []any) {
@@ -261,7 +532,7 @@ func (s *SQLiteStore) GetAllEnabledMonitors(ctx context.Context) ([]*Monitor, er
 	rows, err := s.readDB.QueryContext(ctx,
 		`SELECT m.id, m.name, m.description, m.type, m.target, m.interval_secs, m.timeout_secs, m.enabled,
 		        m.tags, m.settings, m.assertions, m.track_changes, m.failure_threshold, m.success_threshold,
-		        m.upside_down, m.resend_interval, m.group_id, m.proxy_id, m.created_at, m.updated_at,
+		        m.upside_down, m.resend_interval, m.group_id, m.proxy_id, m.ack_silences_reminders, m.latency_baseline_sigma, m.skip_default_channel, m.capture_failure_context, m.owner, m.stream_checks_every, m.retries, m.retry_interval, m.redact_patterns, m.degraded_threshold, m.flap_window_seconds, m.parent_id, m.connect_timeout_secs, m.max_response_time_ms, m.warmup_seconds, m.created_at, m.updated_at,
 		        COALESCE(ms.status, 'pending'), ms.last_check_at, COALESCE(ms.consec_fails, 0), COALESCE(ms.consec_successes, 0)
 		 FROM monitors m
 		 LEFT JOIN monitor_status ms ON ms.monitor_id = m.id
@@ -292,9 +563,9 @@ func (s *SQLiteStore) GetMonitorStatus(ctx context.Context, monitorID int64) (*M
 	var ms MonitorStatus
 	var lastCheck sql.NullString
 	err := s.readDB.QueryRowContext(ctx,
-		`SELECT monitor_id, status, last_check_at, consec_fails, consec_successes, last_body_hash, last_cert_fingerprint
+		`SELECT monitor_id, status, last_check_at, consec_fails, consec_successes, consec_degraded, last_body_hash, last_cert_fingerprint
 		 FROM monitor_status WHERE monitor_id=?`, monitorID).
-		Scan(&ms.MonitorID, &ms.Status, &lastCheck, &ms.ConsecFails, &ms.ConsecSuccesses, &ms.LastBodyHash, &ms.LastCertFingerprint)
+		Scan(&ms.MonitorID, &ms.Status, &lastCheck, &ms.ConsecFails, &ms.ConsecSuccesses, &ms.ConsecDegraded, &ms.LastBodyHash, &ms.LastCertFingerprint)
 	if err != nil {
 		return nil, err
 	}
@@ -302,36 +573,61 @@ func (s *SQLiteStore) GetMonitorStatus(ctx context.Context, monitorID int64) (*M
 	return &ms, nil
 }
 
+// UpsertMonitorStatus retries and buffers while the database is locked; see
+// SetWriteRetry.
 func (s *SQLiteStore) UpsertMonitorStatus(ctx context.Context, st *MonitorStatus) error {
+	snapshot := *st
+	write := func(ctx context.Context) error { return s.upsertMonitorStatus(ctx, &snapshot) }
+	return s.guardedWrite(ctx, write, write)
+}
+
+func (s *SQLiteStore) upsertMonitorStatus(ctx context.Context, st *MonitorStatus) error {
 	var lastCheck string
 	if st.LastCheckAt != nil {
 		lastCheck = formatTime(*st.LastCheckAt)
 	}
 	_, err := s.writeDB.ExecContext(ctx,
-		`INSERT INTO monitor_status (monitor_id, status, last_check_at, consec_fails, consec_successes, last_body_hash, last_cert_fingerprint)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		`INSERT INTO monitor_status (monitor_id, status, last_check_at, consec_fails, consec_successes, consec_degraded, last_body_hash, last_cert_fingerprint)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		 ON CONFLICT(monitor_id) DO UPDATE SET
 		   status=excluded.status, last_check_at=excluded.last_check_at,
 		   consec_fails=excluded.consec_fails, consec_successes=excluded.consec_successes,
+		   consec_degraded=excluded.consec_degraded,
 		   last_body_hash=excluded.last_body_hash,
 		   last_cert_fingerprint=excluded.last_cert_fingerprint`,
-		st.MonitorID, st.Status, nullStr(lastCheck), st.ConsecFails, st.ConsecSuccesses, st.LastBodyHash, st.LastCertFingerprint)
+		st.MonitorID, st.Status, nullStr(lastCheck), st.ConsecFails, st.ConsecSuccesses, st.ConsecDegraded, st.LastBodyHash, st.LastCertFingerprint)
 	return err
 }
 
 // --- Check Results ---
 
+// InsertCheckResult retries and buffers while the database is locked; see
+// SetWriteRetry. A buffered result has no ID until it is written.
 func (s *SQLiteStore) InsertCheckResult(ctx context.Context, r *CheckResult) error {
+	snapshot := *r
+	return s.guardedWrite(ctx,
+		func(ctx context.Context) error { return s.insertCheckResult(ctx, r) },
+		func(ctx context.Context) error { return s.insertCheckResult(ctx, &snapshot) })
+}
+
+func (s *SQLiteStore) insertCheckResult(ctx context.Context, r *CheckResult) error {
 	var certExpiry string
 	if r.CertExpiry != nil {
 		certExpiry = formatTime(*r.CertExpiry)
 	}
 	now := formatTime(time.Now())
+	if r.ConfigHash != "" && len(r.Config) > 0 {
+		if _, err := s.writeDB.ExecContext(ctx,
+			`INSERT OR IGNORE INTO check_configs (monitor_id, hash, config, created_at) VALUES (?, ?, ?, ?)`,
+			r.MonitorID, r.ConfigHash, string(r.Config), now); err != nil {
+			return fmt.Errorf("insert check config: %w", err)
+		}
+	}
 	res, err := s.writeDB.ExecContext(ctx,
-		`INSERT INTO check_results (monitor_id, status, response_time, status_code, message, headers, body, body_hash, cert_expiry, cert_fingerprint, dns_records, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO check_results (monitor_id, status, response_time, status_code, message, headers, body, body_hash, cert_expiry, cert_fingerprint, dns_records, config_hash, failure_context, probe_id, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		r.MonitorID, r.Status, r.ResponseTime, r.StatusCode, r.Message, r.Headers,
-		r.Body, r.BodyHash, nullStr(certExpiry), r.CertFingerprint, r.DNSRecords, now)
+		r.Body, r.BodyHash, nullStr(certExpiry), r.CertFingerprint, r.DNSRecords, r.ConfigHash, r.FailureContext, nullInt64(r.ProbeID), now)
 	if err != nil {
 		return err
 	}
@@ -351,7 +647,7 @@ func (s *SQLiteStore) ListCheckResults(ctx context.Context, monitorID int64, p P
 
 	offset := (p.Page - 1) * p.PerPage
 	rows, err := s.readDB.QueryContext(ctx,
-		`SELECT id, monitor_id, status, response_time, status_code, message, body_hash, cert_expiry, dns_records, created_at
+		`SELECT id, monitor_id, status, response_time, status_code, message, headers, body_hash, cert_expiry, dns_records, config_hash, probe_id, created_at
 		 FROM check_results WHERE monitor_id=? ORDER BY created_at DESC LIMIT ? OFFSET ?`,
 		monitorID, p.PerPage, offset)
 	if err != nil {
@@ -363,14 +659,16 @@ func (s *SQLiteStore) ListCheckResults(ctx context.Context, monitorID int64, p P
 	for rows.Next() {
 		var r CheckResult
 		var certExp sql.NullString
+		var probeID sql.NullInt64
 		var createdAt string
 		err := rows.Scan(&r.ID, &r.MonitorID, &r.Status, &r.ResponseTime, &r.StatusCode,
-			&r.Message, &r.BodyHash, &certExp, &r.DNSRecords, &createdAt)
+			&r.Message, &r.Headers, &r.BodyHash, &certExp, &r.DNSRecords, &r.ConfigHash, &probeID, &createdAt)
 		if err != nil {
 			return nil, err
 		}
 		r.CreatedAt = parseTime(createdAt)
 		r.CertExpiry = parseTimePtr(certExp)
+		r.ProbeID = int64Ptr(probeID)
 		results = append(results, &r)
 	}
 	if err := rows.Err(); err != nil {
@@ -389,23 +687,88 @@ func (s *SQLiteStore) ListCheckResults(ctx context.Context, monitorID int64, p P
 	}, nil
 }
 
+// ListCheckResultsRange returns up to limit results for a monitor created in
+// [from, to] with an ID greater than afterID, oldest first. Callers page by
+// passing the last ID they received, which stays fast on large histories
+// where OFFSET would rescan every earlier row.
+func (s *SQLiteStore) ListCheckResultsRange(ctx context.Context, monitorID int64, from, to time.Time, afterID int64, limit int) ([]*CheckResult, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT id, monitor_id, status, response_time, status_code, message, created_at
+		 FROM check_results
+		 WHERE monitor_id=? AND created_at >= ? AND created_at <= ? AND id > ?
+		 ORDER BY id LIMIT ?`,
+		monitorID, formatTime(from), formatTime(to), afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*CheckResult
+	for rows.Next() {
+		var r CheckResult
+		var createdAt string
+		if err := rows.Scan(&r.ID, &r.MonitorID, &r.Status, &r.ResponseTime, &r.StatusCode, &r.Message, &createdAt); err != nil {
+			return nil, err
+		}
+		r.CreatedAt = parseTime(createdAt)
+		results = append(results, &r)
+	}
+	return results, rows.Err()
+}
+
 func (s *SQLiteStore) GetLatestCheckResult(ctx context.Context, monitorID int64) (*CheckResult, error) {
 	var r CheckResult
 	var certExp sql.NullString
+	var probeID sql.NullInt64
 	var createdAt string
 	err := s.readDB.QueryRowContext(ctx,
-		`SELECT id, monitor_id, status, response_time, status_code, message, body_hash, cert_expiry, dns_records, created_at
-		 FROM check_results WHERE monitor_id=? ORDER BY created_at DESC LIMIT 1`, monitorID).
+		`SELECT id, monitor_id, status, response_time, status_code, message, headers, body_hash, cert_expiry, dns_records, config_hash, probe_id, created_at
+		 FROM check_results WHERE monitor_id=? ORDER BY created_at DESC, id DESC LIMIT 1`, monitorID).
 		Scan(&r.ID, &r.MonitorID, &r.Status, &r.ResponseTime, &r.StatusCode,
-			&r.Message, &r.BodyHash, &certExp, &r.DNSRecords, &createdAt)
+			&r.Message, &r.Headers, &r.BodyHash, &certExp, &r.DNSRecords, &r.ConfigHash, &probeID, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	r.CreatedAt = parseTime(createdAt)
+	r.CertExpiry = parseTimePtr(certExp)
+	r.ProbeID = int64Ptr(probeID)
+	return &r, nil
+}
+
+func (s *SQLiteStore) GetCheckResult(ctx context.Context, id int64) (*CheckResult, error) {
+	var r CheckResult
+	var certExp sql.NullString
+	var probeID sql.NullInt64
+	var createdAt string
+	err := s.readDB.QueryRowContext(ctx,
+		`SELECT id, monitor_id, status, response_time, status_code, message, headers, body, body_hash,
+		        cert_expiry, cert_fingerprint, dns_records, config_hash, failure_context, probe_id, created_at
+		 FROM check_results WHERE id=?`, id).
+		Scan(&r.ID, &r.MonitorID, &r.Status, &r.ResponseTime, &r.StatusCode, &r.Message, &r.Headers, &r.Body,
+			&r.BodyHash, &certExp, &r.CertFingerprint, &r.DNSRecords, &r.ConfigHash, &r.FailureContext, &probeID, &createdAt)
 	if err != nil {
 		return nil, err
 	}
 	r.CreatedAt = parseTime(createdAt)
 	r.CertExpiry = parseTimePtr(certExp)
+	r.ProbeID = int64Ptr(probeID)
 	return &r, nil
 }
 
+func (s *SQLiteStore) GetCheckConfig(ctx context.Context, monitorID int64, hash string) (*CheckConfig, error) {
+	var raw string
+	err := s.readDB.QueryRowContext(ctx,
+		`SELECT config FROM check_configs WHERE monitor_id=? AND hash=?`, monitorID, hash).Scan(&raw)
+	if err != nil {
+		return nil, err
+	}
+	var cfg CheckConfig
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return nil, fmt.Errorf("decode check config: %w", err)
+	}
+	return &cfg, nil
+}
+
 func (s *SQLiteStore) GetMonitorSparklines(ctx context.Context, monitorIDs []int64, n int) (map[int64][]*SparklinePoint, error) {
 	result := make(map[int64][]*SparklinePoint, len(monitorIDs))
 	if len(monitorIDs) == 0 {
@@ -422,7 +785,7 @@ func (s *SQLiteStore) GetMonitorSparklines(ctx context.Context, monitorIDs []int
 	query := fmt.Sprintf(`
 		SELECT monitor_id, status, response_time FROM (
 			SELECT monitor_id, status, response_time,
-				ROW_NUMBER() OVER (PARTITION BY monitor_id ORDER BY created_at DESC) AS rn
+				ROW_NUMBER() OVER (PARTITION BY monitor_id ORDER BY created_at DESC, id DESC) AS rn
 			FROM check_results WHERE monitor_id IN (%s)
 		) t WHERE rn <= ? ORDER BY monitor_id, rn DESC`,
 		strings.Join(placeholders, ","))
@@ -463,6 +826,8 @@ func (s *SQLiteStore) GetMonitorNotificationChannelIDs(ctx context.Context, moni
 	return ids, rows.Err()
 }
 
+// SetMonitorNotificationChannels replaces a monitor's channels. Channels it
+// keeps keep their notification filters.
 func (s *SQLiteStore) SetMonitorNotificationChannels(ctx context.Context, monitorID int64, channelIDs []int64) error {
 	tx, err := s.writeDB.BeginTx(ctx, nil)
 	if err != nil {
@@ -470,18 +835,38 @@ func (s *SQLiteStore) SetMonitorNotificationChannels(ctx context.Context, monito
 	}
 	defer tx.Rollback()
 
+	filters := make(map[int64]string)
+	rows, err := tx.QueryContext(ctx,
+		`SELECT channel_id, event_filter FROM monitor_notifications WHERE monitor_id=? AND event_filter != ''`, monitorID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int64
+		var filter string
+		if err := rows.Scan(&id, &filter); err != nil {
+			rows.Close()
+			return err
+		}
+		filters[id] = filter
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
 	if _, err := tx.ExecContext(ctx, `DELETE FROM monitor_notifications WHERE monitor_id=?`, monitorID); err != nil {
 		return err
 	}
 
 	if len(channelIDs) > 0 {
-		stmt, err := tx.PrepareContext(ctx, `INSERT INTO monitor_notifications (monitor_id, channel_id) VALUES (?, ?)`)
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO monitor_notifications (monitor_id, channel_id, event_filter) VALUES (?, ?, ?)`)
 		if err != nil {
 			return err
 		}
 		defer stmt.Close()
 		for _, cid := range channelIDs {
-			if _, err := stmt.ExecContext(ctx, monitorID, cid); err != nil {
+			if _, err := stmt.ExecContext(ctx, monitorID, cid, filters[cid]); err != nil {
 				return err
 			}
 		}
@@ -489,3 +874,59 @@ func (s *SQLiteStore) SetMonitorNotificationChannels(ctx context.Context, monito
 
 	return tx.Commit()
 }
+
+// GetMonitorNotificationFilters returns the filters on a monitor's channel
+// links, keyed by channel ID. Unfiltered channels are left out.
+func (s *SQLiteStore) GetMonitorNotificationFilters(ctx context.Context, monitorID int64) (map[int64]*NotificationFilter, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT channel_id, event_filter FROM monitor_notifications WHERE monitor_id=? AND event_filter != ''`, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	filters := make(map[int64]*NotificationFilter)
+	for rows.Next() {
+		var id int64
+		var raw string
+		if err := rows.Scan(&id, &raw); err != nil {
+			return nil, err
+		}
+		var f NotificationFilter
+		if err := json.Unmarshal([]byte(raw), &f); err != nil {
+			return nil, fmt.Errorf("decode notification filter for channel %d: %w", id, err)
+		}
+		filters[id] = &f
+	}
+	return filters, rows.Err()
+}
+
+// SetMonitorNotificationFilters replaces the filters on a monitor's existing
+// channel links. Links without an entry in filters are unfiltered; entries
+// for channels the monitor isn't linked to are ignored.
+func (s *SQLiteStore) SetMonitorNotificationFilters(ctx context.Context, monitorID int64, filters map[int64]*NotificationFilter) error {
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("set monitor notification filters begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE monitor_notifications SET event_filter='' WHERE monitor_id=?`, monitorID); err != nil {
+		return err
+	}
+	for cid, f := range filters {
+		if f.IsZero() {
+			continue
+		}
+		raw, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE monitor_notifications SET event_filter=? WHERE monitor_id=? AND channel_id=?`,
+			string(raw), monitorID, cid); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -10,9 +12,10 @@ func (s *SQLiteStore) CreateNotificationChannel(ctx context.Context, ch *Notific
 	events, _ := json.Marshal(ch.Events)
 	now := formatTime(time.Now())
 	res, err := s.writeDB.ExecContext(ctx,
-		`INSERT INTO notification_channels (name, type, enabled, settings, events, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		ch.Name, ch.Type, boolToInt(ch.Enabled), string(ch.Settings), string(events), now, now)
+		`INSERT INTO notification_channels (name, type, enabled, settings, events, schedule, oncall_schedule_id, digest_window_seconds, digest_flush_on_resolve, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		ch.Name, ch.Type, boolToInt(ch.Enabled), string(ch.Settings), string(events), string(ch.Schedule), nullInt64(ch.OncallScheduleID),
+		ch.DigestWindowSeconds, boolToInt(ch.DigestFlushOnResolve), now, now)
 	if err != nil {
 		return err
 	}
@@ -25,24 +28,41 @@ func (s *SQLiteStore) CreateNotificationChannel(ctx context.Context, ch *Notific
 
 func (s *SQLiteStore) GetNotificationChannel(ctx context.Context, id int64) (*NotificationChannel, error) {
 	var ch NotificationChannel
-	var settingsStr, eventsStr, createdAt, updatedAt string
+	var settingsStr, eventsStr, scheduleStr, createdAt, updatedAt string
+	var oncallID sql.NullInt64
 	err := s.readDB.QueryRowContext(ctx,
-		`SELECT id, name, type, enabled, settings, events, created_at, updated_at
+		`SELECT id, name, type, enabled, settings, events, schedule, oncall_schedule_id, digest_window_seconds, digest_flush_on_resolve, created_at, updated_at
 		 FROM notification_channels WHERE id=?`, id).
-		Scan(&ch.ID, &ch.Name, &ch.Type, &ch.Enabled, &settingsStr, &eventsStr, &createdAt, &updatedAt)
+		Scan(&ch.ID, &ch.Name, &ch.Type, &ch.Enabled, &settingsStr, &eventsStr, &scheduleStr, &oncallID, &ch.DigestWindowSeconds, &ch.DigestFlushOnResolve, &createdAt, &updatedAt)
 	if err != nil {
 		return nil, err
 	}
 	ch.Settings = json.RawMessage(settingsStr)
+	ch.OncallScheduleID = int64Ptr(oncallID)
+	if scheduleStr != "" {
+		ch.Schedule = json.RawMessage(scheduleStr)
+	}
 	ch.CreatedAt = parseTime(createdAt)
 	ch.UpdatedAt = parseTime(updatedAt)
 	json.Unmarshal([]byte(eventsStr), &ch.Events)
-	return &ch, nil
+	rows, err := s.readDB.QueryContext(ctx, `SELECT tag_id FROM channel_tags WHERE channel_id=? ORDER BY tag_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tagID int64
+		if err := rows.Scan(&tagID); err != nil {
+			return nil, err
+		}
+		ch.TagIDs = append(ch.TagIDs, tagID)
+	}
+	return &ch, rows.Err()
 }
 
 func (s *SQLiteStore) ListNotificationChannels(ctx context.Context) ([]*NotificationChannel, error) {
 	rows, err := s.readDB.QueryContext(ctx,
-		`SELECT id, name, type, enabled, settings, events, created_at, updated_at
+		`SELECT id, name, type, enabled, settings, events, schedule, oncall_schedule_id, digest_window_seconds, digest_flush_on_resolve, created_at, updated_at
 		 FROM notification_channels ORDER BY id`)
 	if err != nil {
 		return nil, err
@@ -52,11 +72,16 @@ func (s *SQLiteStore) ListNotificationChannels(ctx context.Context) ([]*Notifica
 	var channels []*NotificationChannel
 	for rows.Next() {
 		var ch NotificationChannel
-		var settingsStr, eventsStr, createdAt, updatedAt string
-		if err := rows.Scan(&ch.ID, &ch.Name, &ch.Type, &ch.Enabled, &settingsStr, &eventsStr, &createdAt, &updatedAt); err != nil {
+		var settingsStr, eventsStr, scheduleStr, createdAt, updatedAt string
+		var oncallID sql.NullInt64
+		if err := rows.Scan(&ch.ID, &ch.Name, &ch.Type, &ch.Enabled, &settingsStr, &eventsStr, &scheduleStr, &oncallID, &ch.DigestWindowSeconds, &ch.DigestFlushOnResolve, &createdAt, &updatedAt); err != nil {
 			return nil, err
 		}
 		ch.Settings = json.RawMessage(settingsStr)
+		ch.OncallScheduleID = int64Ptr(oncallID)
+		if scheduleStr != "" {
+			ch.Schedule = json.RawMessage(scheduleStr)
+		}
 		ch.CreatedAt = parseTime(createdAt)
 		ch.UpdatedAt = parseTime(updatedAt)
 		json.Unmarshal([]byte(eventsStr), &ch.Events)
@@ -68,6 +93,13 @@ func (s *SQLiteStore) ListNotificationChannels(ctx context.Context) ([]*Notifica
 	if channels == nil {
 		channels = []*NotificationChannel{}
 	}
+	tags, err := s.channelTagIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range channels {
+		ch.TagIDs = tags[ch.ID]
+	}
 	return channels, nil
 }
 
@@ -75,8 +107,9 @@ func (s *SQLiteStore) UpdateNotificationChannel(ctx context.Context, ch *Notific
 	events, _ := json.Marshal(ch.Events)
 	now := formatTime(time.Now())
 	_, err := s.writeDB.ExecContext(ctx,
-		`UPDATE notification_channels SET name=?, type=?, enabled=?, settings=?, events=?, updated_at=? WHERE id=?`,
-		ch.Name, ch.Type, boolToInt(ch.Enabled), string(ch.Settings), string(events), now, ch.ID)
+		`UPDATE notification_channels SET name=?, type=?, enabled=?, settings=?, events=?, schedule=?, oncall_schedule_id=?, digest_window_seconds=?, digest_flush_on_resolve=?, updated_at=? WHERE id=?`,
+		ch.Name, ch.Type, boolToInt(ch.Enabled), string(ch.Settings), string(events), string(ch.Schedule), nullInt64(ch.OncallScheduleID),
+		ch.DigestWindowSeconds, boolToInt(ch.DigestFlushOnResolve), now, ch.ID)
 	return err
 }
 
@@ -84,3 +117,74 @@ func (s *SQLiteStore) DeleteNotificationChannel(ctx context.Context, id int64) e
 	_, err := s.writeDB.ExecContext(ctx, "DELETE FROM notification_channels WHERE id=?", id)
 	return err
 }
+
+// channelTagIDs returns the tag IDs of every tagged channel, keyed by channel.
+func (s *SQLiteStore) channelTagIDs(ctx context.Context) (map[int64][]int64, error) {
+	rows, err := s.readDB.QueryContext(ctx, `SELECT channel_id, tag_id FROM channel_tags ORDER BY channel_id, tag_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64][]int64)
+	for rows.Next() {
+		var chID, tagID int64
+		if err := rows.Scan(&chID, &tagID); err != nil {
+			return nil, err
+		}
+		result[chID] = append(result[chID], tagID)
+	}
+	return result, rows.Err()
+}
+
+func (s *SQLiteStore) SetChannelTags(ctx context.Context, channelID int64, tagIDs []int64) error {
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("set channel tags begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM channel_tags WHERE channel_id=?`, channelID); err != nil {
+		return err
+	}
+
+	if len(tagIDs) > 0 {
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO channel_tags (channel_id, tag_id) VALUES (?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, tid := range tagIDs {
+			if _, err := stmt.ExecContext(ctx, channelID, tid); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetChannelsByTag returns the IDs of channels linked to any of tagIDs.
+func (s *SQLiteStore) GetChannelsByTag(ctx context.Context, tagIDs []int64) ([]int64, error) {
+	if len(tagIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders, args := bulkArgs(tagIDs)
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT DISTINCT channel_id FROM channel_tags WHERE tag_id IN (`+placeholders+`) ORDER BY channel_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
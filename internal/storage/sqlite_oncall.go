@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+func (s *SQLiteStore) CreateOncallSchedule(ctx context.Context, sched *OncallSchedule) error {
+	members, overrides, err := marshalOncallRotation(sched)
+	if err != nil {
+		return err
+	}
+	now := formatTime(time.Now())
+	res, err := s.writeDB.ExecContext(ctx,
+		`INSERT INTO oncall_schedules (name, timezone, rotation_start, shift_hours, members, overrides, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		sched.Name, sched.Timezone, sched.RotationStart, sched.ShiftHours, members, overrides, now, now)
+	if err != nil {
+		return err
+	}
+	id, _ := res.LastInsertId()
+	sched.ID = id
+	sched.CreatedAt = parseTime(now)
+	sched.UpdatedAt = parseTime(now)
+	return nil
+}
+
+func (s *SQLiteStore) GetOncallSchedule(ctx context.Context, id int64) (*OncallSchedule, error) {
+	var sched OncallSchedule
+	var members, overrides, createdAt, updatedAt string
+	err := s.readDB.QueryRowContext(ctx,
+		`SELECT id, name, timezone, rotation_start, shift_hours, members, overrides, created_at, updated_at
+		 FROM oncall_schedules WHERE id=?`, id).
+		Scan(&sched.ID, &sched.Name, &sched.Timezone, &sched.RotationStart, &sched.ShiftHours, &members, &overrides, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal([]byte(members), &sched.Members)
+	json.Unmarshal([]byte(overrides), &sched.Overrides)
+	sched.CreatedAt = parseTime(createdAt)
+	sched.UpdatedAt = parseTime(updatedAt)
+	return &sched, nil
+}
+
+func (s *SQLiteStore) ListOncallSchedules(ctx context.Context) ([]*OncallSchedule, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT id, name, timezone, rotation_start, shift_hours, members, overrides, created_at, updated_at
+		 FROM oncall_schedules ORDER BY name COLLATE NOCASE`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []*OncallSchedule
+	for rows.Next() {
+		var sched OncallSchedule
+		var members, overrides, createdAt, updatedAt string
+		if err := rows.Scan(&sched.ID, &sched.Name, &sched.Timezone, &sched.RotationStart, &sched.ShiftHours, &members, &overrides, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(members), &sched.Members)
+		json.Unmarshal([]byte(overrides), &sched.Overrides)
+		sched.CreatedAt = parseTime(createdAt)
+		sched.UpdatedAt = parseTime(updatedAt)
+		schedules = append(schedules, &sched)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if schedules == nil {
+		schedules = []*OncallSchedule{}
+	}
+	return schedules, nil
+}
+
+func (s *SQLiteStore) UpdateOncallSchedule(ctx context.Context, sched *OncallSchedule) error {
+	members, overrides, err := marshalOncallRotation(sched)
+	if err != nil {
+		return err
+	}
+	now := formatTime(time.Now())
+	_, err = s.writeDB.ExecContext(ctx,
+		`UPDATE oncall_schedules SET name=?, timezone=?, rotation_start=?, shift_hours=?, members=?, overrides=?, updated_at=? WHERE id=?`,
+		sched.Name, sched.Timezone, sched.RotationStart, sched.ShiftHours, members, overrides, now, sched.ID)
+	return err
+}
+
+// DeleteOncallSchedule removes a schedule. Channels that referenced it go
+// back to their configured recipients.
+func (s *SQLiteStore) DeleteOncallSchedule(ctx context.Context, id int64) error {
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("delete oncall schedule begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE notification_channels SET oncall_schedule_id=NULL WHERE oncall_schedule_id=?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM oncall_schedules WHERE id=?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CurrentResponder returns who is on call for a schedule at the given time,
+// or nil when nobody is. A missing schedule returns sql.ErrNoRows.
+func (s *SQLiteStore) CurrentResponder(ctx context.Context, scheduleID int64, at time.Time) (*OncallMember, error) {
+	sched, err := s.GetOncallSchedule(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	return sched.ResponderAt(at), nil
+}
+
+func marshalOncallRotation(sched *OncallSchedule) (members, overrides string, err error) {
+	if sched.Members == nil {
+		sched.Members = []OncallMember{}
+	}
+	m, err := json.Marshal(sched.Members)
+	if err != nil {
+		return "", "", err
+	}
+	o, err := json.Marshal(sched.Overrides)
+	if err != nil {
+		return "", "", err
+	}
+	if sched.Overrides == nil {
+		o = []byte("[]")
+	}
+	return string(m), string(o), nil
+}
@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const probeColumns = `id, name, type, source_addr, url, token, enabled, created_at, updated_at`
+
+func scanProbe(row scanner) (*Probe, error) {
+	var p Probe
+	var createdAt, updatedAt string
+	if err := row.Scan(&p.ID, &p.Name, &p.Type, &p.SourceAddr, &p.URL, &p.Token, &p.Enabled, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	p.CreatedAt = parseTime(createdAt)
+	p.UpdatedAt = parseTime(updatedAt)
+	return &p, nil
+}
+
+func (s *SQLiteStore) CreateProbe(ctx context.Context, p *Probe) error {
+	now := formatTime(time.Now())
+	res, err := s.writeDB.ExecContext(ctx,
+		`INSERT INTO probes (name, type, source_addr, url, token, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.Name, p.Type, p.SourceAddr, p.URL, p.Token, boolToInt(p.Enabled), now, now)
+	if err != nil {
+		return err
+	}
+	id, _ := res.LastInsertId()
+	p.ID = id
+	p.CreatedAt = parseTime(now)
+	p.UpdatedAt = parseTime(now)
+	return nil
+}
+
+func (s *SQLiteStore) GetProbe(ctx context.Context, id int64) (*Probe, error) {
+	return scanProbe(s.readDB.QueryRowContext(ctx,
+		`SELECT `+probeColumns+` FROM probes WHERE id=?`, id))
+}
+
+func (s *SQLiteStore) ListProbes(ctx context.Context) ([]*Probe, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT `+probeColumns+` FROM probes ORDER BY name COLLATE NOCASE`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	probes := []*Probe{}
+	for rows.Next() {
+		p, err := scanProbe(rows)
+		if err != nil {
+			return nil, err
+		}
+		probes = append(probes, p)
+	}
+	return probes, rows.Err()
+}
+
+func (s *SQLiteStore) UpdateProbe(ctx context.Context, p *Probe) error {
+	now := formatTime(time.Now())
+	_, err := s.writeDB.ExecContext(ctx,
+		`UPDATE probes SET name=?, type=?, source_addr=?, url=?, token=?, enabled=?, updated_at=? WHERE id=?`,
+		p.Name, p.Type, p.SourceAddr, p.URL, p.Token, boolToInt(p.Enabled), now, p.ID)
+	return err
+}
+
+func (s *SQLiteStore) DeleteProbe(ctx context.Context, id int64) error {
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("delete probe begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM monitor_probes WHERE probe_id=?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM probes WHERE id=?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetMonitorProbeIDs(ctx context.Context, monitorID int64) ([]int64, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT probe_id FROM monitor_probes WHERE monitor_id=? ORDER BY probe_id`, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *SQLiteStore) SetMonitorProbes(ctx context.Context, monitorID int64, probeIDs []int64) error {
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("set monitor probes begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM monitor_probes WHERE monitor_id=?`, monitorID); err != nil {
+		return err
+	}
+
+	if len(probeIDs) > 0 {
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO monitor_probes (monitor_id, probe_id) VALUES (?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, pid := range probeIDs {
+			if _, err := stmt.ExecContext(ctx, monitorID, pid); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListMonitorProbes returns the enabled probes assigned to each monitor,
+// keyed by monitor ID. Monitors without probes are absent.
+func (s *SQLiteStore) ListMonitorProbes(ctx context.Context) (map[int64][]*Probe, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT mp.monitor_id, p.id, p.name, p.type, p.source_addr, p.url, p.token, p.enabled, p.created_at, p.updated_at
+		 FROM monitor_probes mp
+		 JOIN probes p ON p.id = mp.probe_id
+		 WHERE p.enabled=1
+		 ORDER BY mp.monitor_id, p.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64][]*Probe)
+	for rows.Next() {
+		var monitorID int64
+		var p Probe
+		var createdAt, updatedAt string
+		if err := rows.Scan(&monitorID, &p.ID, &p.Name, &p.Type, &p.SourceAddr, &p.URL, &p.Token, &p.Enabled, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		p.CreatedAt = parseTime(createdAt)
+		p.UpdatedAt = parseTime(updatedAt)
+		result[monitorID] = append(result[monitorID], &p)
+	}
+	return result, rows.Err()
+}
+
+// InsertProbeChecks stores each probe's outcome for one check cycle. They
+// feed the per-probe breakdown only: check_results keeps a single row per
+// cycle, so a monitor's uptime doesn't depend on how many probes it has.
+// Checks without a ProbeID are ignored.
+func (s *SQLiteStore) InsertProbeChecks(ctx context.Context, checks []*CheckResult) error {
+	rows := make([]CheckResult, 0, len(checks))
+	for _, c := range checks {
+		if c.ProbeID != nil {
+			rows = append(rows, *c)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	write := func(ctx context.Context) error { return s.insertProbeChecks(ctx, rows) }
+	return s.guardedWrite(ctx, write, write)
+}
+
+func (s *SQLiteStore) insertProbeChecks(ctx context.Context, rows []CheckResult) error {
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("insert probe checks begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO probe_checks (monitor_id, probe_id, status, response_time, message, created_at) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	now := formatTime(time.Now())
+	for _, r := range rows {
+		if _, err := stmt.ExecContext(ctx, r.MonitorID, *r.ProbeID, r.Status, r.ResponseTime, r.Message, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetUptimeByProbe groups a monitor's checks in [from, to) by the probe that
+// ran them, with the latest check from each. Checks run without probes form
+// a group with a nil ProbeID, listed first.
+func (s *SQLiteStore) GetUptimeByProbe(ctx context.Context, monitorID int64, from, to time.Time) ([]*ProbeUptime, error) {
+	fromStr, toStr := formatTime(from), formatTime(to)
+	rows, err := s.readDB.QueryContext(ctx,
+		`WITH c AS (
+		   SELECT probe_id, status, response_time, created_at,
+		          ROW_NUMBER() OVER (PARTITION BY probe_id ORDER BY created_at DESC, id DESC) AS rn
+		   FROM (
+		     SELECT id, probe_id, status, response_time, created_at FROM check_results
+		     WHERE monitor_id=? AND probe_id IS NULL AND created_at >= ? AND created_at < ?
+		     UNION ALL
+		     SELECT id, probe_id, status, response_time, created_at FROM probe_checks
+		     WHERE monitor_id=? AND created_at >= ? AND created_at < ?
+		   ) checks
+		 )
+		 SELECT c.probe_id, COALESCE(p.name, ''), COUNT(*),
+		        COALESCE(SUM(CASE WHEN c.status='up' THEN 1 ELSE 0 END), 0),
+		        COALESCE(SUM(CASE WHEN c.status='down' THEN 1 ELSE 0 END), 0),
+		        COALESCE(SUM(CASE WHEN c.status='degraded' THEN 1 ELSE 0 END), 0),
+		        MAX(CASE WHEN c.rn=1 THEN c.status END),
+		        MAX(CASE WHEN c.rn=1 THEN c.created_at END),
+		        MAX(CASE WHEN c.rn=1 THEN c.response_time END)
+		 FROM c
+		 LEFT JOIN probes p ON p.id = c.probe_id
+		 GROUP BY c.probe_id, p.name
+		 ORDER BY c.probe_id IS NOT NULL, p.name COLLATE NOCASE, c.probe_id`,
+		monitorID, fromStr, toStr, monitorID, fromStr, toStr)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*ProbeUptime{}
+	for rows.Next() {
+		var u ProbeUptime
+		var probeID, responseTime sql.NullInt64
+		var lastStatus, lastCheck sql.NullString
+		if err := rows.Scan(&probeID, &u.ProbeName, &u.Total, &u.Up, &u.Down, &u.Degraded,
+			&lastStatus, &lastCheck, &responseTime); err != nil {
+			return nil, err
+		}
+		if probeID.Valid {
+			id := probeID.Int64
+			u.ProbeID = &id
+		}
+		u.Uptime = 100
+		if u.Total > 0 {
+			u.Uptime = float64(u.Up) / float64(u.Total) * 100
+		}
+		u.LastStatus = lastStatus.String
+		u.LastCheckAt = parseTimePtr(lastCheck)
+		u.ResponseTime = responseTime.Int64
+		result = append(result, &u)
+	}
+	return result, rows.Err()
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -24,21 +25,27 @@ func (s *SQLiteStore) CreateProxy(ctx context.Context, p *Proxy) error {
 func (s *SQLiteStore) GetProxy(ctx context.Context, id int64) (*Proxy, error) {
 	var p Proxy
 	var createdAt, updatedAt string
+	var lastCheck, lastOK sql.NullString
 	err := s.readDB.QueryRowContext(ctx,
-		`SELECT id, name, protocol, host, port, auth_user, auth_pass, enabled, created_at, updated_at
+		`SELECT id, name, protocol, host, port, auth_user, auth_pass, enabled, created_at, updated_at,
+		        last_check_at, last_ok_at, last_error, consec_fails, auto_disabled
 		 FROM proxies WHERE id=?`, id).
-		Scan(&p.ID, &p.Name, &p.Protocol, &p.Host, &p.Port, &p.AuthUser, &p.AuthPass, &p.Enabled, &createdAt, &updatedAt)
+		Scan(&p.ID, &p.Name, &p.Protocol, &p.Host, &p.Port, &p.AuthUser, &p.AuthPass, &p.Enabled, &createdAt, &updatedAt,
+			&lastCheck, &lastOK, &p.LastError, &p.ConsecFails, &p.AutoDisabled)
 	if err != nil {
 		return nil, err
 	}
 	p.CreatedAt = parseTime(createdAt)
 	p.UpdatedAt = parseTime(updatedAt)
+	p.LastCheckAt = parseTimePtr(lastCheck)
+	p.LastOKAt = parseTimePtr(lastOK)
 	return &p, nil
 }
 
 func (s *SQLiteStore) ListProxies(ctx context.Context) ([]*Proxy, error) {
 	rows, err := s.readDB.QueryContext(ctx,
-		`SELECT id, name, protocol, host, port, auth_user, auth_pass, enabled, created_at, updated_at
+		`SELECT id, name, protocol, host, port, auth_user, auth_pass, enabled, created_at, updated_at,
+		        last_check_at, last_ok_at, last_error, consec_fails, auto_disabled
 		 FROM proxies ORDER BY name COLLATE NOCASE`)
 	if err != nil {
 		return nil, err
@@ -49,11 +56,15 @@ func (s *SQLiteStore) ListProxies(ctx context.Context) ([]*Proxy, error) {
 	for rows.Next() {
 		var p Proxy
 		var createdAt, updatedAt string
-		if err := rows.Scan(&p.ID, &p.Name, &p.Protocol, &p.Host, &p.Port, &p.AuthUser, &p.AuthPass, &p.Enabled, &createdAt, &updatedAt); err != nil {
+		var lastCheck, lastOK sql.NullString
+		if err := rows.Scan(&p.ID, &p.Name, &p.Protocol, &p.Host, &p.Port, &p.AuthUser, &p.AuthPass, &p.Enabled, &createdAt, &updatedAt,
+			&lastCheck, &lastOK, &p.LastError, &p.ConsecFails, &p.AutoDisabled); err != nil {
 			return nil, err
 		}
 		p.CreatedAt = parseTime(createdAt)
 		p.UpdatedAt = parseTime(updatedAt)
+		p.LastCheckAt = parseTimePtr(lastCheck)
+		p.LastOKAt = parseTimePtr(lastOK)
 		proxies = append(proxies, &p)
 	}
 	if err := rows.Err(); err != nil {
@@ -65,14 +76,46 @@ func (s *SQLiteStore) ListProxies(ctx context.Context) ([]*Proxy, error) {
 	return proxies, nil
 }
 
+// UpdateProxy saves a user's edit of p. Enabling the proxy clears
+// auto_disabled, and so does disabling it: either way the user now decides.
 func (s *SQLiteStore) UpdateProxy(ctx context.Context, p *Proxy) error {
 	now := formatTime(time.Now())
 	_, err := s.writeDB.ExecContext(ctx,
-		`UPDATE proxies SET name=?, protocol=?, host=?, port=?, auth_user=?, auth_pass=?, enabled=?, updated_at=? WHERE id=?`,
-		p.Name, p.Protocol, p.Host, p.Port, p.AuthUser, p.AuthPass, boolToInt(p.Enabled), now, p.ID)
+		`UPDATE proxies SET name=?, protocol=?, host=?, port=?, auth_user=?, auth_pass=?,
+		 auto_disabled=CASE WHEN enabled=? THEN auto_disabled ELSE 0 END, enabled=?, updated_at=? WHERE id=?`,
+		p.Name, p.Protocol, p.Host, p.Port, p.AuthUser, p.AuthPass, boolToInt(p.Enabled), boolToInt(p.Enabled), now, p.ID)
 	return err
 }
 
+// UpdateProxyHealth records the outcome of a proxy health check: the check
+// time, last success, error and failure streak. It leaves enabled alone, so
+// a user's edit made while the check ran is kept.
+func (s *SQLiteStore) UpdateProxyHealth(ctx context.Context, p *Proxy) error {
+	_, err := s.writeDB.ExecContext(ctx,
+		`UPDATE proxies SET last_check_at=?, last_ok_at=?, last_error=?, consec_fails=? WHERE id=?`,
+		nullTime(p.LastCheckAt), nullTime(p.LastOKAt), p.LastError, p.ConsecFails, p.ID)
+	return err
+}
+
+// SetProxyAutoDisabled disables an enabled proxy on behalf of the health
+// check, or enables one the health check disabled. It reports whether the
+// proxy changed; a proxy the user enabled or disabled meanwhile does not.
+func (s *SQLiteStore) SetProxyAutoDisabled(ctx context.Context, id int64, disabled bool) (bool, error) {
+	query := "UPDATE proxies SET enabled=0, auto_disabled=1 WHERE id=? AND enabled=1"
+	if !disabled {
+		query = "UPDATE proxies SET enabled=1, auto_disabled=0 WHERE id=? AND auto_disabled=1"
+	}
+	res, err := s.writeDB.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
 func (s *SQLiteStore) DeleteProxy(ctx context.Context, id int64) error {
 	_, err := s.writeDB.ExecContext(ctx, "DELETE FROM proxies WHERE id=?", id)
 	return err
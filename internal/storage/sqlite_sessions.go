@@ -222,6 +222,22 @@ func (s *SQLiteStore) PurgeOldData(ctx context.Context, before time.Time) (int64
 	n, _ := res.RowsAffected()
 	totalDeleted += n
 
+	res, err = s.writeDB.ExecContext(ctx, "DELETE FROM probe_checks WHERE created_at < ?", ts)
+	if err != nil {
+		return totalDeleted, err
+	}
+	n, _ = res.RowsAffected()
+	totalDeleted += n
+
+	res, err = s.writeDB.ExecContext(ctx,
+		`DELETE FROM check_configs WHERE NOT EXISTS
+		 (SELECT 1 FROM check_results cr WHERE cr.monitor_id = check_configs.monitor_id AND cr.config_hash = check_configs.hash)`)
+	if err != nil {
+		return totalDeleted, err
+	}
+	n, _ = res.RowsAffected()
+	totalDeleted += n
+
 	res, err = s.writeDB.ExecContext(ctx,
 		`DELETE FROM incident_events WHERE incident_id IN
 		 (SELECT id FROM incidents WHERE status='resolved' AND resolved_at < ?)`, ts)
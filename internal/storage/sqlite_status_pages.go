@@ -23,8 +23,32 @@ func (s *SQLiteStore) IsMonitorOnStatusPage(ctx context.Context, monitorID int64
 	return exists == 1, nil
 }
 
+// GetDailyUptime returns per-day check counts between from and to. Days
+// before today come from the daily_uptime rollup; today is aggregated from
+// check_results since the rollup only covers finished days.
 func (s *SQLiteStore) GetDailyUptime(ctx context.Context, monitorID int64, from, to time.Time) ([]*DailyUptime, error) {
-	rows, err := s.readDB.QueryContext(ctx,
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	rollupTo, liveFrom := to, from
+	if rollupTo.After(today) {
+		rollupTo = today
+	}
+	if liveFrom.Before(today) {
+		liveFrom = today
+	}
+	results, err := s.queryDailyUptime(ctx,
+		`SELECT date, total_checks, up_checks, down_checks
+		 FROM daily_uptime
+		 WHERE monitor_id=? AND date >= ? AND date < ?
+		 ORDER BY date ASC`,
+		monitorID, from.UTC().Format("2006-01-02"), rollupTo.UTC().Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	if !to.After(today) {
+		return results, nil
+	}
+
+	live, err := s.queryDailyUptime(ctx,
 		`SELECT DATE(created_at) as day,
 		        COUNT(*) as total,
 		        COALESCE(SUM(CASE WHEN status='up' THEN 1 ELSE 0 END), 0),
@@ -33,7 +57,15 @@ func (s *SQLiteStore) GetDailyUptime(ctx context.Context, monitorID int64, from,
 		 WHERE monitor_id=? AND created_at >= ? AND created_at < ?
 		 GROUP BY DATE(created_at)
 		 ORDER BY day ASC`,
-		monitorID, formatTime(from), formatTime(to))
+		monitorID, formatTime(liveFrom), formatTime(to))
+	if err != nil {
+		return nil, err
+	}
+	return append(results, live...), nil
+}
+
+func (s *SQLiteStore) queryDailyUptime(ctx context.Context, query string, args ...any) ([]*DailyUptime, error) {
+	rows, err := s.readDB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get daily uptime: %w", err)
 	}
@@ -53,6 +85,27 @@ func (s *SQLiteStore) GetDailyUptime(ctx context.Context, monitorID int64, from,
 	return results, rows.Err()
 }
 
+// RollupDailyUptime aggregates check_results into daily_uptime for every UTC
+// day in [from, to), replacing rows that already exist.
+func (s *SQLiteStore) RollupDailyUptime(ctx context.Context, from, to time.Time) error {
+	_, err := s.writeDB.ExecContext(ctx,
+		`INSERT OR REPLACE INTO daily_uptime (monitor_id, date, total_checks, up_checks, down_checks)
+		 SELECT
+		   monitor_id,
+		   DATE(created_at),
+		   COUNT(*),
+		   COALESCE(SUM(CASE WHEN status='up' THEN 1 ELSE 0 END), 0),
+		   COALESCE(SUM(CASE WHEN status='down' THEN 1 ELSE 0 END), 0)
+		 FROM check_results
+		 WHERE created_at >= ? AND created_at < ?
+		 GROUP BY monitor_id, DATE(created_at)`,
+		formatTime(from.UTC().Truncate(24*time.Hour)), formatTime(to.UTC().Truncate(24*time.Hour)))
+	if err != nil {
+		return fmt.Errorf("rollup daily uptime: %w", err)
+	}
+	return nil
+}
+
 // --- Status Pages ---
 
 func (s *SQLiteStore) CreateStatusPage(ctx context.Context, sp *StatusPage) error {
@@ -60,11 +113,15 @@ func (s *SQLiteStore) CreateStatusPage(ctx context.Context, sp *StatusPage) erro
 	res, err := s.writeDB.ExecContext(ctx,
 		`INSERT INTO status_pages
 		 (slug, title, description, custom_css, show_incidents, enabled, api_enabled, sort_order,
-		  logo_url, favicon_url, custom_header_html, password_hash, analytics_script, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		  logo_url, favicon_url, custom_header_html, password_hash, api_token_hash, analytics_script,
+		  announcement_html, announcement_enabled, announcement_starts_at, announcement_ends_at, subscriber_channel_id,
+		  created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		sp.Slug, sp.Title, sp.Description, sp.CustomCSS, boolToInt(sp.ShowIncidents),
 		boolToInt(sp.Enabled), boolToInt(sp.APIEnabled), sp.SortOrder,
-		sp.LogoURL, sp.FaviconURL, sp.CustomHeaderHTML, sp.PasswordHash, sp.AnalyticsScript, now, now)
+		sp.LogoURL, sp.FaviconURL, sp.CustomHeaderHTML, sp.PasswordHash, sp.APITokenHash, sp.AnalyticsScript,
+		sp.AnnouncementHTML, boolToInt(sp.AnnouncementEnabled), nullTime(sp.AnnouncementStart), nullTime(sp.AnnouncementEnd),
+		nullInt64(sp.SubscriberChannelID), now, now)
 	if err != nil {
 		return err
 	}
@@ -79,21 +136,30 @@ func scanStatusPage(sp *StatusPage, row interface {
 	Scan(...any) error
 }) error {
 	var createdAt, updatedAt string
+	var annStart, annEnd sql.NullString
+	var subscriberChannel sql.NullInt64
 	err := row.Scan(&sp.ID, &sp.Slug, &sp.Title, &sp.Description, &sp.CustomCSS,
 		&sp.ShowIncidents, &sp.Enabled, &sp.APIEnabled, &sp.SortOrder,
-		&sp.LogoURL, &sp.FaviconURL, &sp.CustomHeaderHTML, &sp.PasswordHash, &sp.AnalyticsScript,
+		&sp.LogoURL, &sp.FaviconURL, &sp.CustomHeaderHTML, &sp.PasswordHash, &sp.APITokenHash, &sp.AnalyticsScript,
+		&sp.AnnouncementHTML, &sp.AnnouncementEnabled, &annStart, &annEnd, &subscriberChannel,
 		&createdAt, &updatedAt)
 	if err != nil {
 		return err
 	}
+	sp.SubscriberChannelID = int64Ptr(subscriberChannel)
+	sp.AnnouncementStart = parseTimePtr(annStart)
+	sp.AnnouncementEnd = parseTimePtr(annEnd)
 	sp.CreatedAt = parseTime(createdAt)
 	sp.UpdatedAt = parseTime(updatedAt)
 	sp.PasswordEnabled = sp.PasswordHash != ""
+	sp.APITokenEnabled = sp.APITokenHash != ""
 	return nil
 }
 
 const statusPageColumns = `id, slug, title, description, custom_css, show_incidents, enabled, api_enabled, sort_order,
-	logo_url, favicon_url, custom_header_html, password_hash, analytics_script, created_at, updated_at`
+	logo_url, favicon_url, custom_header_html, password_hash, api_token_hash, analytics_script,
+	announcement_html, announcement_enabled, announcement_starts_at, announcement_ends_at, subscriber_channel_id,
+	created_at, updated_at`
 
 func (s *SQLiteStore) GetStatusPage(ctx context.Context, id int64) (*StatusPage, error) {
 	var sp StatusPage
@@ -119,8 +185,9 @@ func (s *SQLiteStore) ListStatusPages(ctx context.Context) ([]*StatusPage, error
 	rows, err := s.readDB.QueryContext(ctx,
 		`SELECT sp.id, sp.slug, sp.title, sp.description, sp.custom_css, sp.show_incidents,
 		        sp.enabled, sp.api_enabled, sp.sort_order,
-		        sp.logo_url, sp.favicon_url, sp.custom_header_html, sp.password_hash, sp.analytics_script,
-		        sp.created_at, sp.updated_at, COALESCE(cnt.c, 0)
+		        sp.logo_url, sp.favicon_url, sp.custom_header_html, sp.password_hash, sp.api_token_hash, sp.analytics_script,
+		        sp.announcement_html, sp.announcement_enabled, sp.announcement_starts_at, sp.announcement_ends_at,
+		        sp.subscriber_channel_id, sp.created_at, sp.updated_at, COALESCE(cnt.c, 0)
 		 FROM status_pages sp
 		 LEFT JOIN (SELECT page_id, COUNT(*) as c FROM status_page_monitors GROUP BY page_id) cnt ON cnt.page_id = sp.id
 		 ORDER BY sp.sort_order, sp.title COLLATE NOCASE`)
@@ -133,15 +200,22 @@ func (s *SQLiteStore) ListStatusPages(ctx context.Context) ([]*StatusPage, error
 	for rows.Next() {
 		var sp StatusPage
 		var createdAt, updatedAt string
+		var annStart, annEnd sql.NullString
+		var subscriberChannel sql.NullInt64
 		if err := rows.Scan(&sp.ID, &sp.Slug, &sp.Title, &sp.Description, &sp.CustomCSS,
 			&sp.ShowIncidents, &sp.Enabled, &sp.APIEnabled, &sp.SortOrder,
-			&sp.LogoURL, &sp.FaviconURL, &sp.CustomHeaderHTML, &sp.PasswordHash, &sp.AnalyticsScript,
-			&createdAt, &updatedAt, &sp.MonitorCount); err != nil {
+			&sp.LogoURL, &sp.FaviconURL, &sp.CustomHeaderHTML, &sp.PasswordHash, &sp.APITokenHash, &sp.AnalyticsScript,
+			&sp.AnnouncementHTML, &sp.AnnouncementEnabled, &annStart, &annEnd,
+			&subscriberChannel, &createdAt, &updatedAt, &sp.MonitorCount); err != nil {
 			return nil, err
 		}
+		sp.SubscriberChannelID = int64Ptr(subscriberChannel)
+		sp.AnnouncementStart = parseTimePtr(annStart)
+		sp.AnnouncementEnd = parseTimePtr(annEnd)
 		sp.CreatedAt = parseTime(createdAt)
 		sp.UpdatedAt = parseTime(updatedAt)
 		sp.PasswordEnabled = sp.PasswordHash != ""
+		sp.APITokenEnabled = sp.APITokenHash != ""
 		pages = append(pages, &sp)
 	}
 	if err := rows.Err(); err != nil {
@@ -158,18 +232,31 @@ func (s *SQLiteStore) UpdateStatusPage(ctx context.Context, sp *StatusPage) erro
 	_, err := s.writeDB.ExecContext(ctx,
 		`UPDATE status_pages SET slug=?, title=?, description=?, custom_css=?, show_incidents=?,
 		 enabled=?, api_enabled=?, sort_order=?,
-		 logo_url=?, favicon_url=?, custom_header_html=?, password_hash=?, analytics_script=?,
-		 updated_at=? WHERE id=?`,
+		 logo_url=?, favicon_url=?, custom_header_html=?, password_hash=?, api_token_hash=?, analytics_script=?,
+		 announcement_html=?, announcement_enabled=?, announcement_starts_at=?, announcement_ends_at=?,
+		 subscriber_channel_id=?, updated_at=? WHERE id=?`,
 		sp.Slug, sp.Title, sp.Description, sp.CustomCSS, boolToInt(sp.ShowIncidents),
 		boolToInt(sp.Enabled), boolToInt(sp.APIEnabled), sp.SortOrder,
-		sp.LogoURL, sp.FaviconURL, sp.CustomHeaderHTML, sp.PasswordHash, sp.AnalyticsScript,
-		now, sp.ID)
+		sp.LogoURL, sp.FaviconURL, sp.CustomHeaderHTML, sp.PasswordHash, sp.APITokenHash, sp.AnalyticsScript,
+		sp.AnnouncementHTML, boolToInt(sp.AnnouncementEnabled), nullTime(sp.AnnouncementStart), nullTime(sp.AnnouncementEnd),
+		nullInt64(sp.SubscriberChannelID), now, sp.ID)
 	return err
 }
 
 func (s *SQLiteStore) DeleteStatusPage(ctx context.Context, id int64) error {
-	_, err := s.writeDB.ExecContext(ctx, "DELETE FROM status_pages WHERE id=?", id)
-	return err
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("delete status page begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM status_page_subscribers WHERE page_id=?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM status_pages WHERE id=?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *SQLiteStore) SetStatusPageMonitors(ctx context.Context, pageID int64, monitors []StatusPageMonitor) error {
@@ -302,3 +389,169 @@ func (s *SQLiteStore) ListStatusPageMonitorsWithStatus(ctx context.Context, page
 	}
 	return monitors, spms, nil
 }
+
+func (s *SQLiteStore) SetStatusPageComponents(ctx context.Context, pageID int64, components []StatusPageComponent) error {
+	tx, err := s.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("set status page components begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM status_page_components WHERE page_id=?`, pageID); err != nil {
+		return err
+	}
+
+	for i := range components {
+		c := &components[i]
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO status_page_components (page_id, name, description, sort_order) VALUES (?, ?, ?, ?)`,
+			pageID, c.Name, c.Description, c.SortOrder)
+		if err != nil {
+			return err
+		}
+		c.ID, _ = res.LastInsertId()
+		c.PageID = pageID
+		for _, m := range c.Monitors {
+			weight := m.Weight
+			if weight <= 0 {
+				weight = 1
+			}
+			if _, err := tx.ExecContext(ctx,
+				`INSERT OR REPLACE INTO status_page_component_monitors (component_id, monitor_id, weight) VALUES (?, ?, ?)`,
+				c.ID, m.MonitorID, weight); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) ListStatusPageComponents(ctx context.Context, pageID int64) ([]StatusPageComponent, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT c.id, c.page_id, c.name, c.description, c.sort_order, cm.monitor_id, cm.weight
+		 FROM status_page_components c
+		 LEFT JOIN status_page_component_monitors cm
+		   ON cm.component_id = c.id AND cm.monitor_id IN (SELECT id FROM monitors)
+		 WHERE c.page_id=?
+		 ORDER BY c.sort_order, c.id, cm.monitor_id`, pageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []StatusPageComponent{}
+	for rows.Next() {
+		var c StatusPageComponent
+		var monitorID, weight sql.NullInt64
+		if err := rows.Scan(&c.ID, &c.PageID, &c.Name, &c.Description, &c.SortOrder, &monitorID, &weight); err != nil {
+			return nil, err
+		}
+		if n := len(result); n == 0 || result[n-1].ID != c.ID {
+			c.Monitors = []StatusPageComponentMonitor{}
+			result = append(result, c)
+		}
+		if monitorID.Valid {
+			last := &result[len(result)-1]
+			last.Monitors = append(last.Monitors, StatusPageComponentMonitor{
+				MonitorID: monitorID.Int64,
+				Weight:    int(weight.Int64),
+			})
+		}
+	}
+	return result, rows.Err()
+}
+
+// --- Status Page Subscribers ---
+
+const statusPageSubscriberColumns = `id, page_id, email, token, confirmed, created_at, confirmed_at`
+
+func scanStatusPageSubscriber(row scanner) (*StatusPageSubscriber, error) {
+	var sub StatusPageSubscriber
+	var createdAt string
+	var confirmedAt sql.NullString
+	if err := row.Scan(&sub.ID, &sub.PageID, &sub.Email, &sub.Token, &sub.Confirmed, &createdAt, &confirmedAt); err != nil {
+		return nil, err
+	}
+	sub.CreatedAt = parseTime(createdAt)
+	sub.ConfirmedAt = parseTimePtr(confirmedAt)
+	return &sub, nil
+}
+
+func (s *SQLiteStore) CreateStatusPageSubscriber(ctx context.Context, sub *StatusPageSubscriber) error {
+	now := formatTime(time.Now())
+	res, err := s.writeDB.ExecContext(ctx,
+		`INSERT INTO status_page_subscribers (page_id, email, token, confirmed, created_at) VALUES (?, ?, ?, ?, ?)`,
+		sub.PageID, sub.Email, sub.Token, boolToInt(sub.Confirmed), now)
+	if err != nil {
+		return fmt.Errorf("create status page subscriber: %w", err)
+	}
+	sub.ID, _ = res.LastInsertId()
+	sub.CreatedAt = parseTime(now)
+	return nil
+}
+
+func (s *SQLiteStore) GetStatusPageSubscriberByEmail(ctx context.Context, pageID int64, email string) (*StatusPageSubscriber, error) {
+	return scanStatusPageSubscriber(s.readDB.QueryRowContext(ctx,
+		`SELECT `+statusPageSubscriberColumns+` FROM status_page_subscribers WHERE page_id=? AND email=?`, pageID, email))
+}
+
+func (s *SQLiteStore) GetStatusPageSubscriberByToken(ctx context.Context, token string) (*StatusPageSubscriber, error) {
+	return scanStatusPageSubscriber(s.readDB.QueryRowContext(ctx,
+		`SELECT `+statusPageSubscriberColumns+` FROM status_page_subscribers WHERE token=?`, token))
+}
+
+func (s *SQLiteStore) ConfirmStatusPageSubscriber(ctx context.Context, id int64) error {
+	_, err := s.writeDB.ExecContext(ctx,
+		`UPDATE status_page_subscribers SET confirmed=1, confirmed_at=? WHERE id=? AND confirmed=0`,
+		formatTime(time.Now()), id)
+	return err
+}
+
+func (s *SQLiteStore) DeleteStatusPageSubscriber(ctx context.Context, id int64) error {
+	_, err := s.writeDB.ExecContext(ctx, `DELETE FROM status_page_subscribers WHERE id=?`, id)
+	return err
+}
+
+func (s *SQLiteStore) ListStatusPageSubscribers(ctx context.Context, pageID int64) ([]*StatusPageSubscriber, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT `+statusPageSubscriberColumns+` FROM status_page_subscribers WHERE page_id=? ORDER BY id`, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("list status page subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []*StatusPageSubscriber{}
+	for rows.Next() {
+		sub, err := scanStatusPageSubscriber(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// ListSubscribableStatusPages returns the enabled pages showing monitorID
+// that publish incidents and have a subscriber channel set.
+func (s *SQLiteStore) ListSubscribableStatusPages(ctx context.Context, monitorID int64) ([]*StatusPage, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT `+statusPageColumns+` FROM status_pages
+		 WHERE enabled=1 AND show_incidents=1 AND subscriber_channel_id IS NOT NULL
+		   AND id IN (SELECT page_id FROM status_page_monitors WHERE monitor_id=?)
+		 ORDER BY id`, monitorID)
+	if err != nil {
+		return nil, fmt.Errorf("list subscribable status pages: %w", err)
+	}
+	defer rows.Close()
+
+	var pages []*StatusPage
+	for rows.Next() {
+		var sp StatusPage
+		if err := scanStatusPage(&sp, rows); err != nil {
+			return nil, err
+		}
+		pages = append(pages, &sp)
+	}
+	return pages, rows.Err()
+}
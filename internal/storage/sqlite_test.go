@@ -3,29 +3,16 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"math"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 )
 
-func testStore(t *testing.T) *SQLiteStore {
-	t.Helper()
-	tmpFile, err := os.CreateTemp("", "asura-test-*.db")
-	if err != nil {
-		t.Fatal(err)
-	}
-	tmpFile.Close()
-	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
-
-	store, err := NewSQLiteStore(tmpFile.Name(), 2)
-	if err != nil {
-		t.Fatal(err)
-	}
-	t.Cleanup(func() { store.Close() })
-	return store
-}
-
 func TestMonitorCRUD(t *testing.T) {
 	store := testStore(t)
 	ctx := context.Background()
@@ -74,6 +61,8 @@ func TestMonitorCRUD(t *testing.T) {
 	// Update
 	m.Name = "Updated HTTP"
 	m.Tags = []string{"prod", "web"}
+	m.DegradedThreshold = 4
+	m.ConnectTimeout = 3
 	err = store.UpdateMonitor(ctx, m)
 	if err != nil {
 		t.Fatal(err)
@@ -83,6 +72,12 @@ func TestMonitorCRUD(t *testing.T) {
 	if got.Name != "Updated HTTP" {
 		t.Fatalf("expected 'Updated HTTP', got %q", got.Name)
 	}
+	if got.DegradedThreshold != 4 {
+		t.Fatalf("expected degraded threshold 4, got %d", got.DegradedThreshold)
+	}
+	if got.ConnectTimeout != 3 {
+		t.Fatalf("expected connect timeout 3, got %d", got.ConnectTimeout)
+	}
 
 	// Pause/Resume
 	err = store.SetMonitorEnabled(ctx, m.ID, false)
@@ -118,6 +113,7 @@ func TestCheckResults(t *testing.T) {
 		ResponseTime: 150,
 		StatusCode:   200,
 		Message:      "OK",
+		Headers:      `{"Content-Type":"text/html","Server":"nginx"}`,
 		BodyHash:     "abc123",
 	}
 	err := store.InsertCheckResult(ctx, cr)
@@ -132,6 +128,9 @@ func TestCheckResults(t *testing.T) {
 	if result.Total != 1 {
 		t.Fatalf("expected 1 result, got %d", result.Total)
 	}
+	if got := result.Data.([]*CheckResult)[0].Headers; got != cr.Headers {
+		t.Fatalf("listed headers = %q, want %q", got, cr.Headers)
+	}
 
 	latest, err := store.GetLatestCheckResult(ctx, m.ID)
 	if err != nil {
@@ -140,6 +139,54 @@ func TestCheckResults(t *testing.T) {
 	if latest.StatusCode != 200 {
 		t.Fatalf("expected status 200, got %d", latest.StatusCode)
 	}
+	if latest.Headers != cr.Headers {
+		t.Fatalf("latest headers = %q, want %q", latest.Headers, cr.Headers)
+	}
+}
+
+func TestCheckConfigSnapshot(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	m := &Monitor{Name: "Test", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10, Enabled: true, Tags: []string{}, FailureThreshold: 3, SuccessThreshold: 1}
+	store.CreateMonitor(ctx, m)
+
+	cfg := `{"type":"http","target":"https://example.com","timeout":10}`
+	for i := 0; i < 2; i++ {
+		cr := &CheckResult{MonitorID: m.ID, Status: "down", Message: "timeout", ConfigHash: "h1", Config: []byte(cfg)}
+		if err := store.InsertCheckResult(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latest, err := store.GetLatestCheckResult(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetCheckResult(ctx, latest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ConfigHash != "h1" || got.Message != "timeout" {
+		t.Fatalf("unexpected check result: %+v", got)
+	}
+
+	snap, err := store.GetCheckConfig(ctx, m.ID, "h1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Target != "https://example.com" || snap.Timeout != 10 {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+
+	if _, err := store.GetCheckConfig(ctx, m.ID, "missing"); err != sql.ErrNoRows {
+		t.Fatalf("expected ErrNoRows, got %v", err)
+	}
+
+	store.PurgeOldData(ctx, time.Now().Add(time.Hour))
+	if _, err := store.GetCheckConfig(ctx, m.ID, "h1"); err != sql.ErrNoRows {
+		t.Fatalf("expected orphaned snapshot to be purged, got %v", err)
+	}
 }
 
 func TestIncidents(t *testing.T) {
@@ -182,6 +229,157 @@ func TestIncidents(t *testing.T) {
 	}
 }
 
+func TestIncidentPostmortemAndNotes(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	m := createTestMonitor(t, store, ctx, "Test")
+	inc := &Incident{MonitorID: m.ID, Status: "open", Cause: "timeout"}
+	if err := store.CreateIncident(ctx, inc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SetIncidentPostmortem(ctx, inc.ID, "Expired TLS certificate on the load balancer."); err != nil {
+		t.Fatal(err)
+	}
+	// Updating status must leave the postmortem alone.
+	inc.Status = "resolved"
+	if err := store.UpdateIncident(ctx, inc); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetIncident(ctx, inc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Postmortem != "Expired TLS certificate on the load balancer." {
+		t.Fatalf("postmortem = %q", got.Postmortem)
+	}
+	if err := store.SetIncidentPostmortem(ctx, 9999, "x"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("missing incident: err = %v, want sql.ErrNoRows", err)
+	}
+
+	// Events in the same second keep insertion order.
+	store.InsertIncidentEvent(ctx, &IncidentEvent{IncidentID: inc.ID, Type: "created", Message: "Incident created"})
+	store.InsertIncidentEvent(ctx, &IncidentEvent{IncidentID: inc.ID, Type: "note", Message: "Rolling back", Author: "alice"})
+	store.InsertIncidentEvent(ctx, &IncidentEvent{IncidentID: inc.ID, Type: "resolved", Message: "Resolved"})
+	events, err := store.ListIncidentEvents(ctx, inc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || events[0].Type != "created" || events[1].Type != "note" || events[2].Type != "resolved" {
+		t.Fatalf("events out of order: %+v", events)
+	}
+	if events[1].Author != "alice" || events[0].Author != "" {
+		t.Errorf("authors = %q, %q", events[0].Author, events[1].Author)
+	}
+}
+
+func TestListExpiredAcks(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	m := &Monitor{Name: "Test", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10, Enabled: true, Tags: []string{}, FailureThreshold: 3, SuccessThreshold: 1}
+	store.CreateMonitor(ctx, m)
+
+	now := time.Now().UTC()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+	ack := func(deadline *time.Time) *Incident {
+		inc := &Incident{MonitorID: m.ID, Status: "open", Cause: "timeout"}
+		if err := store.CreateIncident(ctx, inc); err != nil {
+			t.Fatal(err)
+		}
+		inc.Status = "acknowledged"
+		inc.AcknowledgedAt = &past
+		inc.AcknowledgedBy = "alice"
+		inc.AckDeadline = deadline
+		if err := store.UpdateIncident(ctx, inc); err != nil {
+			t.Fatal(err)
+		}
+		return inc
+	}
+	expired := ack(&past)
+	ack(&future)
+	ack(nil)
+
+	got, err := store.ListExpiredAcks(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != expired.ID {
+		t.Fatalf("expected only incident %d, got %+v", expired.ID, got)
+	}
+	if got[0].AckDeadline == nil || !got[0].AckDeadline.Equal(past.Truncate(time.Second)) {
+		t.Fatalf("ack_deadline round trip: got %v, want %v", got[0].AckDeadline, past)
+	}
+}
+
+func TestGroupIncidentsByCause(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	api := createTestMonitor(t, store, ctx, "API")
+	web := createTestMonitor(t, store, ctx, "Web")
+
+	causes := []struct {
+		monitorID int64
+		cause     string
+	}{
+		{api.ID, "timeout after 10.2s"},
+		{api.ID, "timeout after 9.8s"},
+		{api.ID, "dial tcp 10.0.0.5:443: connection refused"},
+		{api.ID, "timeout after 12s"},
+		{web.ID, "timeout after 10s"},
+	}
+	var ids []int64
+	for _, c := range causes {
+		inc := &Incident{MonitorID: c.monitorID, Status: "resolved", Cause: c.cause}
+		if err := store.CreateIncident(ctx, inc); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, inc.ID)
+	}
+	old := formatTime(time.Now().Add(-48 * time.Hour))
+	if _, err := store.writeDB.ExecContext(ctx, "UPDATE incidents SET started_at=? WHERE id=?", old, ids[3]); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := store.GroupIncidentsByCause(ctx, 0, "", "", time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+	top := groups[0]
+	if top.MonitorID != api.ID || top.Cause != "timeout after Ns" || top.Count != 2 {
+		t.Errorf("top group = %+v, want 2 API timeouts", top)
+	}
+	if top.Example != "timeout after 9.8s" || top.LastIncidentID != ids[1] {
+		t.Errorf("top group example = %q (#%d), want latest incident", top.Example, top.LastIncidentID)
+	}
+
+	groups, _ = store.GroupIncidentsByCause(ctx, web.ID, "", "", time.Now().Add(-24*time.Hour))
+	if len(groups) != 1 || groups[0].MonitorName != "Web" {
+		t.Errorf("monitor filter returned %+v", groups)
+	}
+}
+
+func TestNormalizeCause(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Timeout after 10.2s", "timeout after Ns"},
+		{"dial tcp 10.0.0.5:443: connection refused", "dial tcp <addr>: connection refused"},
+		{"dial tcp [2001:db8::1]:443: i/o timeout", "dial tcp <addr>: i/o timeout"},
+		{"unexpected body hash 9f86d081884c7d65", "unexpected body hash <id>"},
+		{"  HTTP 503  ", "http N"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeCause(tt.in); got != tt.want {
+			t.Errorf("NormalizeCause(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
 func TestAnalytics(t *testing.T) {
 	store := testStore(t)
 	ctx := context.Background()
@@ -219,6 +417,113 @@ func TestAnalytics(t *testing.T) {
 	}
 }
 
+func TestGetApdex(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	m := createTestMonitor(t, store, ctx, "Apdex")
+
+	results := []struct {
+		status string
+		rt     int64
+	}{
+		{"up", 100}, {"up", 500}, {"up", 501}, {"up", 2000}, {"up", 2001}, {"down", 0}, {"degraded", 3000},
+	}
+	for _, r := range results {
+		if err := store.InsertCheckResult(ctx, &CheckResult{MonitorID: m.ID, Status: r.status, ResponseTime: r.rt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name               string
+		failedAsFrustrated bool
+		wantFrustrated     int64
+		wantExcluded       int64
+		wantScore          float64
+	}{
+		{"failed excluded", false, 1, 2, 3.0 / 5},
+		{"failed frustrated", true, 3, 0, 3.0 / 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep, err := store.GetApdex(ctx, m.ID, from, to, 500, tt.failedAsFrustrated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rep.Satisfied != 2 || rep.Tolerating != 2 || rep.Frustrated != tt.wantFrustrated || rep.Excluded != tt.wantExcluded {
+				t.Fatalf("buckets = %d/%d/%d excluded %d", rep.Satisfied, rep.Tolerating, rep.Frustrated, rep.Excluded)
+			}
+			if rep.Score == nil || math.Abs(*rep.Score-tt.wantScore) > 1e-9 {
+				t.Fatalf("score = %v, want %v", rep.Score, tt.wantScore)
+			}
+		})
+	}
+
+	rep, err := store.GetApdex(ctx, m.ID, to, to.Add(time.Hour), 500, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Score != nil || rep.Samples != 0 {
+		t.Fatalf("expected no score without checks, got %v from %d samples", *rep.Score, rep.Samples)
+	}
+}
+
+func TestGetTimeSeries(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	m := createTestMonitor(t, store, ctx, "Series")
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	results := []struct {
+		offset time.Duration
+		status string
+		rt     int64
+	}{
+		{10 * time.Second, "up", 100},
+		{20 * time.Second, "degraded", 300},
+		{2*time.Minute + 5*time.Second, "up", 50},
+		{2*time.Minute + 30*time.Second, "down", 0},
+		{2*time.Minute + 40*time.Second, "up", 150},
+		{5 * time.Minute, "up", 999}, // outside [from, to)
+	}
+	for _, r := range results {
+		if _, err := store.writeDB.Exec(`INSERT INTO check_results (monitor_id, status, response_time, created_at) VALUES (?, ?, ?, ?)`,
+			m.ID, r.status, r.rt, formatTime(from.Add(r.offset))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	points, err := store.GetTimeSeries(ctx, m.ID, from, from.Add(5*time.Minute), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TimeSeriesPoint{
+		{Timestamp: from.Unix(), ResponseTime: 200, Status: "degraded", Checks: 2},
+		{Timestamp: from.Unix() + 60},
+		{Timestamp: from.Unix() + 120, ResponseTime: 66, Status: "down", Checks: 3},
+		{Timestamp: from.Unix() + 180},
+		{Timestamp: from.Unix() + 240},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d: %+v", len(points), len(want), points)
+	}
+	for i := range want {
+		if points[i] != want[i] {
+			t.Errorf("point %d = %+v, want %+v", i, points[i], want[i])
+		}
+	}
+
+	// A range shorter than the requested points uses one-second buckets.
+	points, err = store.GetTimeSeries(ctx, m.ID, from, from.Add(30*time.Second), 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 30 || points[10].Checks != 1 || points[20].Status != "degraded" || points[15].Checks != 0 {
+		t.Fatalf("unexpected one-second series: %d points", len(points))
+	}
+}
+
 func createTestHeartbeat(t *testing.T) (*SQLiteStore, context.Context, *Monitor) {
 	t.Helper()
 	store := testStore(t)
@@ -302,6 +607,101 @@ func TestHeartbeatCRUD(t *testing.T) {
 	})
 }
 
+func TestHeartbeatPause(t *testing.T) {
+	store, ctx, m := createTestHeartbeat(t)
+	const token = "abc123def456"
+
+	stale := formatTime(time.Now().Add(-time.Hour))
+	if _, err := store.writeDB.ExecContext(ctx, "UPDATE heartbeats SET last_ping_at=?, status='up' WHERE token=?", stale, token); err != nil {
+		t.Fatal(err)
+	}
+	expired, err := store.ListExpiredHeartbeats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0].MonitorID != m.ID {
+		t.Fatalf("expected the stale heartbeat to be expired, got %d", len(expired))
+	}
+
+	if err := store.PauseHeartbeat(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	expired, _ = store.ListExpiredHeartbeats(ctx)
+	if len(expired) != 0 {
+		t.Fatalf("expected paused heartbeat to be excluded, got %d", len(expired))
+	}
+
+	if err := store.UpdateHeartbeatPing(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := store.GetHeartbeatByToken(ctx, token)
+	if got.Status != "paused" {
+		t.Fatalf("expected a ping to keep the heartbeat paused, got %s", got.Status)
+	}
+
+	if _, err := store.writeDB.ExecContext(ctx, "UPDATE heartbeats SET last_ping_at=? WHERE token=?", stale, token); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ResumeHeartbeat(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = store.GetHeartbeatByToken(ctx, token)
+	if got.Status != "pending" {
+		t.Fatalf("expected status pending after resume, got %s", got.Status)
+	}
+	if got.LastPingAt == nil || time.Since(*got.LastPingAt) > time.Minute {
+		t.Fatalf("expected resume to restart the deadline, last_ping_at=%v", got.LastPingAt)
+	}
+	expired, _ = store.ListExpiredHeartbeats(ctx)
+	if len(expired) != 0 {
+		t.Fatalf("expected resumed heartbeat not to be expired yet, got %d", len(expired))
+	}
+
+	due := got.NextPingDue(m.Interval)
+	if want := got.LastPingAt.Add(360 * time.Second); due == nil || !due.Equal(want) {
+		t.Fatalf("NextPingDue = %v, want %v", due, want)
+	}
+	if (&Heartbeat{Grace: 60}).NextPingDue(300) != nil {
+		t.Fatal("expected no deadline before the first ping")
+	}
+}
+
+func TestRestartHeartbeatDeadline(t *testing.T) {
+	store, ctx, m := createTestHeartbeat(t)
+	const token = "abc123def456"
+
+	stale := formatTime(time.Now().Add(-time.Hour))
+	if _, err := store.writeDB.ExecContext(ctx, "UPDATE heartbeats SET last_ping_at=?, status='up' WHERE token=?", stale, token); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RestartHeartbeatDeadline(ctx, m.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := store.GetHeartbeatByToken(ctx, token)
+	if got.Status != "pending" {
+		t.Fatalf("expected status pending after restart, got %s", got.Status)
+	}
+	if got.LastPingAt == nil || time.Since(*got.LastPingAt) > time.Minute {
+		t.Fatalf("expected the deadline to run from now, last_ping_at=%v", got.LastPingAt)
+	}
+	if expired, _ := store.ListExpiredHeartbeats(ctx); len(expired) != 0 {
+		t.Fatalf("expected restarted heartbeat not to be expired, got %d", len(expired))
+	}
+
+	for _, status := range []string{"paused", "down"} {
+		if _, err := store.writeDB.ExecContext(ctx, "UPDATE heartbeats SET last_ping_at=?, status=? WHERE token=?", stale, status, token); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.RestartHeartbeatDeadline(ctx, m.ID); err != nil {
+			t.Fatal(err)
+		}
+		got, _ = store.GetHeartbeatByToken(ctx, token)
+		if got.Status != status || time.Since(*got.LastPingAt) < 30*time.Minute {
+			t.Fatalf("expected a %s heartbeat untouched, got %s at %v", status, got.Status, got.LastPingAt)
+		}
+	}
+}
+
 func TestIsMonitorOnStatusPage(t *testing.T) {
 	store := testStore(t)
 	ctx := context.Background()
@@ -348,78 +748,280 @@ func TestIsMonitorOnStatusPage(t *testing.T) {
 	}
 }
 
-func TestSessionCRUD(t *testing.T) {
-	t.Run("CreateAndGet", testSessionCreateAndGet)
-	t.Run("GetNotFound", testSessionGetNotFound)
-	t.Run("Delete", testSessionDelete)
-	t.Run("DeleteExpired", testSessionDeleteExpired)
-	t.Run("DeleteByAPIKeyName", testSessionDeleteByAPIKeyName)
-	t.Run("DeleteExceptKeyNames", testSessionDeleteExceptKeyNames)
-	t.Run("DeleteExceptKeyNamesEmpty", testSessionDeleteExceptEmpty)
-	t.Run("KeyHashStored", testSessionKeyHashStored)
-}
-
-func testSessionCreateAndGet(t *testing.T) {
+func TestStatusPageAnnouncement(t *testing.T) {
 	store := testStore(t)
 	ctx := context.Background()
-	sess := &Session{
-		TokenHash: "abc123hash", APIKeyName: "admin",
-		IPAddress: "192.168.1.1", ExpiresAt: time.Now().Add(24 * time.Hour),
+
+	end := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	sp := &StatusPage{
+		Title: "Test Page", Slug: "test", Enabled: true,
+		AnnouncementHTML: "<p>Checkout is degraded</p>", AnnouncementEnabled: true, AnnouncementEnd: &end,
 	}
-	if err := store.CreateSession(ctx, sess); err != nil {
+	if err := store.CreateStatusPage(ctx, sp); err != nil {
 		t.Fatal(err)
 	}
-	if sess.ID == 0 {
-		t.Fatal("expected non-zero ID")
-	}
-	got, err := store.GetSessionByTokenHash(ctx, "abc123hash")
+
+	got, err := store.GetStatusPage(ctx, sp.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if got.APIKeyName != "admin" {
-		t.Fatalf("expected api_key_name 'admin', got %q", got.APIKeyName)
+	if !got.AnnouncementEnabled || got.AnnouncementHTML != sp.AnnouncementHTML {
+		t.Errorf("announcement not round-tripped: %+v", got)
 	}
-	if got.IPAddress != "192.168.1.1" {
-		t.Fatalf("expected ip '192.168.1.1', got %q", got.IPAddress)
+	if got.AnnouncementStart != nil || got.AnnouncementEnd == nil || !got.AnnouncementEnd.Equal(end) {
+		t.Errorf("unexpected window: start=%v end=%v", got.AnnouncementStart, got.AnnouncementEnd)
 	}
-}
 
-func testSessionGetNotFound(t *testing.T) {
-	store := testStore(t)
-	ctx := context.Background()
-	_, err := store.GetSessionByTokenHash(ctx, "nonexistent")
-	if err == nil {
-		t.Fatal("expected error for nonexistent session")
+	got.AnnouncementEnabled = false
+	got.AnnouncementEnd = nil
+	if err := store.UpdateStatusPage(ctx, got); err != nil {
+		t.Fatal(err)
 	}
-}
-
-func testSessionDelete(t *testing.T) {
-	store := testStore(t)
-	ctx := context.Background()
-	store.CreateSession(ctx, &Session{TokenHash: "deleteme", APIKeyName: "admin", ExpiresAt: time.Now().Add(24 * time.Hour)})
-	if err := store.DeleteSession(ctx, "deleteme"); err != nil {
+	pages, err := store.ListStatusPages(ctx)
+	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err := store.GetSessionByTokenHash(ctx, "deleteme"); err == nil {
-		t.Fatal("expected error after delete")
+	if len(pages) != 1 || pages[0].AnnouncementEnabled || pages[0].AnnouncementEnd != nil {
+		t.Errorf("expected cleared announcement, got %+v", pages[0])
 	}
 }
 
-func testSessionDeleteExpired(t *testing.T) {
+func TestStatusPageSubscribers(t *testing.T) {
 	store := testStore(t)
 	ctx := context.Background()
-	store.CreateSession(ctx, &Session{TokenHash: "expired_token", APIKeyName: "admin", ExpiresAt: time.Now().Add(-1 * time.Hour)})
-	store.CreateSession(ctx, &Session{TokenHash: "valid_token", APIKeyName: "admin", ExpiresAt: time.Now().Add(24 * time.Hour)})
 
-	deleted, err := store.DeleteExpiredSessions(ctx)
-	if err != nil {
+	m := &Monitor{Name: "API", Type: "http", Target: "https://example.com", Interval: 60, Timeout: 10, Enabled: true, Tags: []string{}, FailureThreshold: 3, SuccessThreshold: 1}
+	if err := store.CreateMonitor(ctx, m); err != nil {
 		t.Fatal(err)
 	}
-	if deleted == 0 {
-		t.Fatal("expected at least 1 expired session deleted")
+	ch := &NotificationChannel{Name: "mail", Type: "email", Enabled: true, Settings: []byte(`{}`)}
+	if err := store.CreateNotificationChannel(ctx, ch); err != nil {
+		t.Fatal(err)
 	}
-	if _, err = store.GetSessionByTokenHash(ctx, "expired_token"); err == nil {
-		t.Fatal("expected expired session to be deleted")
+	sp := &StatusPage{Title: "Status", Slug: "status", Enabled: true, ShowIncidents: true, SubscriberChannelID: &ch.ID}
+	if err := store.CreateStatusPage(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetStatusPageMonitors(ctx, sp.ID, []StatusPageMonitor{{PageID: sp.ID, MonitorID: m.ID}}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.GetStatusPage(ctx, sp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SubscriberChannelID == nil || *got.SubscriberChannelID != ch.ID {
+		t.Fatalf("subscriber channel not round-tripped: %v", got.SubscriberChannelID)
+	}
+
+	sub := &StatusPageSubscriber{PageID: sp.ID, Email: "ops@example.com", Token: "tok1"}
+	if err := store.CreateStatusPageSubscriber(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateStatusPageSubscriber(ctx, &StatusPageSubscriber{PageID: sp.ID, Email: "ops@example.com", Token: "tok2"}); err == nil {
+		t.Error("expected duplicate email on the same page to fail")
+	}
+
+	byEmail, err := store.GetStatusPageSubscriberByEmail(ctx, sp.ID, "ops@example.com")
+	if err != nil || byEmail.ID != sub.ID || byEmail.Confirmed {
+		t.Fatalf("GetStatusPageSubscriberByEmail() = %+v, %v", byEmail, err)
+	}
+	if err := store.ConfirmStatusPageSubscriber(ctx, sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	byToken, err := store.GetStatusPageSubscriberByToken(ctx, "tok1")
+	if err != nil || !byToken.Confirmed || byToken.ConfirmedAt == nil {
+		t.Fatalf("expected confirmed subscriber, got %+v, %v", byToken, err)
+	}
+
+	pages, err := store.ListSubscribableStatusPages(ctx, m.ID)
+	if err != nil || len(pages) != 1 || pages[0].ID != sp.ID {
+		t.Fatalf("ListSubscribableStatusPages() = %v, %v", pages, err)
+	}
+	sp.ShowIncidents = false
+	if err := store.UpdateStatusPage(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	if pages, _ := store.ListSubscribableStatusPages(ctx, m.ID); len(pages) != 0 {
+		t.Errorf("page hiding incidents should not be subscribable, got %d", len(pages))
+	}
+
+	if err := store.DeleteStatusPage(ctx, sp.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetStatusPageSubscriberByToken(ctx, "tok1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected subscribers deleted with the page, got %v", err)
+	}
+}
+
+func TestStatusPageComponents(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	m1 := createTestMonitor(t, store, ctx, "api-1")
+	m2 := createTestMonitor(t, store, ctx, "api-2")
+	sp := &StatusPage{Title: "Test Page", Slug: "test", Enabled: true}
+	if err := store.CreateStatusPage(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+
+	comps := []StatusPageComponent{
+		{Name: "API", Description: "Public API", Monitors: []StatusPageComponentMonitor{
+			{MonitorID: m1.ID, Weight: 3}, {MonitorID: m2.ID},
+		}},
+		{Name: "Empty", SortOrder: 1},
+	}
+	if err := store.SetStatusPageComponents(ctx, sp.ID, comps); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.ListStatusPageComponents(ctx, sp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 components, got %d", len(got))
+	}
+	if got[0].Name != "API" || got[0].Description != "Public API" || len(got[0].Monitors) != 2 {
+		t.Fatalf("unexpected first component: %+v", got[0])
+	}
+	if got[0].Monitors[0].Weight != 3 || got[0].Monitors[1].Weight != 1 {
+		t.Errorf("weights = %d, %d; want 3, 1", got[0].Monitors[0].Weight, got[0].Monitors[1].Weight)
+	}
+	if got[1].Name != "Empty" || len(got[1].Monitors) != 0 {
+		t.Errorf("unexpected second component: %+v", got[1])
+	}
+
+	// Deleting a monitor drops its membership
+	if err := store.DeleteMonitor(ctx, m2.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = store.ListStatusPageComponents(ctx, sp.ID)
+	if len(got[0].Monitors) != 1 {
+		t.Errorf("expected 1 member after delete, got %d", len(got[0].Monitors))
+	}
+
+	// Setting replaces all components
+	if err := store.SetStatusPageComponents(ctx, sp.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = store.ListStatusPageComponents(ctx, sp.ID)
+	if len(got) != 0 {
+		t.Errorf("expected no components, got %d", len(got))
+	}
+}
+
+func TestLatencyBaselines(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	m := createTestMonitor(t, store, ctx, "baseline")
+
+	for _, rt := range []int64{100, 100, 200, 200} {
+		if err := store.InsertCheckResult(ctx, &CheckResult{MonitorID: m.ID, Status: "up", ResponseTime: rt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Failed checks don't count toward the baseline
+	if err := store.InsertCheckResult(ctx, &CheckResult{MonitorID: m.ID, Status: "down", ResponseTime: 5000}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.RecomputeLatencyBaselines(ctx, m.ID, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := store.ListLatencyBaselines(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("expected 1 hourly baseline, got %d", len(all))
+	}
+	b, err := store.GetLatencyBaseline(ctx, m.ID, all[0].Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Mean != 150 || b.StdDev != 50 || b.Samples != 4 {
+		t.Errorf("baseline = mean %v stddev %v samples %d, want 150/50/4", b.Mean, b.StdDev, b.Samples)
+	}
+
+	if _, err := store.GetLatencyBaseline(ctx, m.ID, (all[0].Hour+1)%24); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for hour without data, got %v", err)
+	}
+}
+
+func TestSessionCRUD(t *testing.T) {
+	t.Run("CreateAndGet", testSessionCreateAndGet)
+	t.Run("GetNotFound", testSessionGetNotFound)
+	t.Run("Delete", testSessionDelete)
+	t.Run("DeleteExpired", testSessionDeleteExpired)
+	t.Run("DeleteByAPIKeyName", testSessionDeleteByAPIKeyName)
+	t.Run("DeleteExceptKeyNames", testSessionDeleteExceptKeyNames)
+	t.Run("DeleteExceptKeyNamesEmpty", testSessionDeleteExceptEmpty)
+	t.Run("KeyHashStored", testSessionKeyHashStored)
+}
+
+func testSessionCreateAndGet(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	sess := &Session{
+		TokenHash: "abc123hash", APIKeyName: "admin",
+		IPAddress: "192.168.1.1", ExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+	if err := store.CreateSession(ctx, sess); err != nil {
+		t.Fatal(err)
+	}
+	if sess.ID == 0 {
+		t.Fatal("expected non-zero ID")
+	}
+	got, err := store.GetSessionByTokenHash(ctx, "abc123hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.APIKeyName != "admin" {
+		t.Fatalf("expected api_key_name 'admin', got %q", got.APIKeyName)
+	}
+	if got.IPAddress != "192.168.1.1" {
+		t.Fatalf("expected ip '192.168.1.1', got %q", got.IPAddress)
+	}
+}
+
+func testSessionGetNotFound(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	_, err := store.GetSessionByTokenHash(ctx, "nonexistent")
+	if err == nil {
+		t.Fatal("expected error for nonexistent session")
+	}
+}
+
+func testSessionDelete(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	store.CreateSession(ctx, &Session{TokenHash: "deleteme", APIKeyName: "admin", ExpiresAt: time.Now().Add(24 * time.Hour)})
+	if err := store.DeleteSession(ctx, "deleteme"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetSessionByTokenHash(ctx, "deleteme"); err == nil {
+		t.Fatal("expected error after delete")
+	}
+}
+
+func testSessionDeleteExpired(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	store.CreateSession(ctx, &Session{TokenHash: "expired_token", APIKeyName: "admin", ExpiresAt: time.Now().Add(-1 * time.Hour)})
+	store.CreateSession(ctx, &Session{TokenHash: "valid_token", APIKeyName: "admin", ExpiresAt: time.Now().Add(24 * time.Hour)})
+
+	deleted, err := store.DeleteExpiredSessions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted == 0 {
+		t.Fatal("expected at least 1 expired session deleted")
+	}
+	if _, err = store.GetSessionByTokenHash(ctx, "expired_token"); err == nil {
+		t.Fatal("expected expired session to be deleted")
 	}
 	got, err := store.GetSessionByTokenHash(ctx, "valid_token")
 	if err != nil {
@@ -639,6 +1241,61 @@ func TestRequestLogRollup(t *testing.T) {
 	}
 }
 
+func TestDailyUptimeRollup(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	m := createTestMonitor(t, store, ctx, "Uptime")
+
+	now := time.Now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	twoDaysAgo := today.AddDate(0, 0, -2).Add(6 * time.Hour)
+	insert := func(status string, at time.Time) {
+		t.Helper()
+		if _, err := store.writeDB.Exec(`INSERT INTO check_results (monitor_id, status, created_at) VALUES (?, ?, ?)`,
+			m.ID, status, formatTime(at)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	insert("up", twoDaysAgo)
+	insert("up", twoDaysAgo)
+	insert("up", twoDaysAgo)
+	insert("down", twoDaysAgo)
+	insert("up", today)
+
+	from := today.AddDate(0, 0, -89)
+
+	// Past days are read from the rollup only.
+	daily, err := store.GetDailyUptime(ctx, m.ID, from, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(daily) != 1 || daily[0].Date != today.Format("2006-01-02") {
+		t.Fatalf("expected only today before the rollup, got %+v", daily)
+	}
+
+	if err := store.RollupDailyUptime(ctx, from, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RollupDailyUptime(ctx, from, now); err != nil {
+		t.Fatal("second rollup should not error:", err)
+	}
+
+	daily, err = store.GetDailyUptime(ctx, m.ID, from, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(daily) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(daily))
+	}
+	d := daily[0]
+	if d.Date != twoDaysAgo.Format("2006-01-02") || d.TotalChecks != 4 || d.UpChecks != 3 || d.DownChecks != 1 || d.UptimePct != 75 {
+		t.Fatalf("unexpected rollup day %+v", d)
+	}
+	if daily[1].TotalChecks != 1 || daily[1].UptimePct != 100 {
+		t.Fatalf("unexpected live day %+v", daily[1])
+	}
+}
+
 func TestRequestLogPurge(t *testing.T) {
 	store := testStore(t)
 	ctx := context.Background()
@@ -732,6 +1389,7 @@ func TestInsertRequestLogBatchEmpty(t *testing.T) {
 }
 
 func TestMigrationFromOldVersionFails(t *testing.T) {
+	sqliteOnly(t)
 	tmpFile, err := os.CreateTemp("", "asura-migrate-*.db")
 	if err != nil {
 		t.Fatal(err)
@@ -771,15 +1429,13 @@ func TestMigrationFreshDB(t *testing.T) {
 
 	// Verify status_pages table exists
 	var spCount int
-	if err := store.readDB.QueryRow("SELECT count(*) FROM sqlite_master WHERE type='table' AND name='status_pages'").Scan(&spCount); err != nil {
-		t.Fatal(err)
-	}
-	if spCount != 1 {
-		t.Fatal("fresh DB: status_pages table missing")
+	if err := store.readDB.QueryRow("SELECT count(*) FROM status_pages").Scan(&spCount); err != nil {
+		t.Fatalf("fresh DB: status_pages table missing: %v", err)
 	}
 }
 
 func TestMigrationIdempotent(t *testing.T) {
+	sqliteOnly(t)
 	tmpFile, err := os.CreateTemp("", "asura-idem-*.db")
 	if err != nil {
 		t.Fatal(err)
@@ -1028,6 +1684,64 @@ func TestTags(t *testing.T) {
 	}
 }
 
+func TestChannelTags(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	web, prod := &Tag{Name: "web"}, &Tag{Name: "prod"}
+	for _, tag := range []*Tag{web, prod} {
+		if err := store.CreateTag(ctx, tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ch1 := &NotificationChannel{Name: "web-team", Type: "webhook", Enabled: true, Settings: []byte(`{}`)}
+	ch2 := &NotificationChannel{Name: "prod-pager", Type: "webhook", Enabled: true, Settings: []byte(`{}`)}
+	for _, ch := range []*NotificationChannel{ch1, ch2} {
+		if err := store.CreateNotificationChannel(ctx, ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SetChannelTags(ctx, ch1.ID, []int64{web.ID, prod.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetChannelTags(ctx, ch2.ID, []int64{prod.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.GetNotificationChannel(ctx, ch1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.TagIDs, []int64{web.ID, prod.ID}) {
+		t.Errorf("GetNotificationChannel tag IDs = %v", got.TagIDs)
+	}
+	channels, err := store.ListNotificationChannels(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 2 || len(channels[0].TagIDs) != 2 || len(channels[1].TagIDs) != 1 {
+		t.Errorf("ListNotificationChannels tag IDs = %v, %v", channels[0].TagIDs, channels[1].TagIDs)
+	}
+
+	ids, err := store.GetChannelsByTag(ctx, []int64{web.ID, prod.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids, []int64{ch1.ID, ch2.ID}) {
+		t.Errorf("GetChannelsByTag(web, prod) = %v", ids)
+	}
+	if ids, _ := store.GetChannelsByTag(ctx, []int64{web.ID}); !slices.Equal(ids, []int64{ch1.ID}) {
+		t.Errorf("GetChannelsByTag(web) = %v", ids)
+	}
+
+	if err := store.SetChannelTags(ctx, ch1.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.GetNotificationChannel(ctx, ch1.ID); len(got.TagIDs) != 0 {
+		t.Errorf("expected cleared tags, got %v", got.TagIDs)
+	}
+}
+
 func createTestMonitor(t *testing.T, store *SQLiteStore, ctx context.Context, name string) *Monitor {
 	t.Helper()
 	m := &Monitor{
@@ -1161,3 +1875,1220 @@ func TestBulkSetMonitorGroup(t *testing.T) {
 		t.Error("m1 should have no group")
 	}
 }
+
+func TestMonitorAckSilencesRemindersRoundTrip(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	m := createTestMonitor(t, store, ctx, "Ack")
+	got, _ := store.GetMonitor(ctx, m.ID)
+	if got.AckSilencesReminders != nil {
+		t.Fatal("expected nil override by default")
+	}
+
+	v := true
+	got.AckSilencesReminders = &v
+	if err := store.UpdateMonitor(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = store.GetMonitor(ctx, m.ID)
+	if got.AckSilencesReminders == nil || !*got.AckSilencesReminders {
+		t.Fatal("expected override to persist as true")
+	}
+}
+
+func TestMonitorRetriesPersist(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	m := createTestMonitor(t, store, ctx, "flaky")
+	m.Retries, m.RetryInterval = 3, 2
+	if err := store.UpdateMonitor(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetMonitor(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Retries != 3 || got.RetryInterval != 2 {
+		t.Fatalf("expected retries 3 every 2s, got %d every %ds", got.Retries, got.RetryInterval)
+	}
+}
+
+func TestMonitorRedactPatternsPersist(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	m := createTestMonitor(t, store, ctx, "echo")
+	if got, _ := store.GetMonitor(ctx, m.ID); len(got.RedactPatterns) != 0 {
+		t.Fatalf("expected no redact patterns, got %v", got.RedactPatterns)
+	}
+	m.RedactPatterns = []string{`token=\w+`, "Bearer \\S+"}
+	if err := store.UpdateMonitor(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetMonitor(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.RedactPatterns, m.RedactPatterns) {
+		t.Fatalf("expected %v, got %v", m.RedactPatterns, got.RedactPatterns)
+	}
+}
+
+func TestGetSLAReport(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	m := createTestMonitor(t, store, ctx, "api")
+	day := func(d, h int) time.Time { return time.Date(2025, 6, d, h, 0, 0, 0, time.UTC) }
+	if _, err := store.writeDB.Exec(`UPDATE monitors SET created_at=? WHERE id=?`, formatTime(day(1, 0)), m.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, span := range [][2]time.Time{
+		{day(2, 10), day(2, 11)},
+		{day(5, 0), day(5, 3)},
+		{day(8, 0), day(8, 2)},
+		{day(8, 1), day(8, 3)},    // overlaps the previous one
+		{day(10, 23), day(11, 1)}, // resolved after the range ends
+	} {
+		if _, err := store.writeDB.Exec(
+			`INSERT INTO incidents (monitor_id, status, cause, started_at, resolved_at) VALUES (?, 'resolved', 'down', ?, ?)`,
+			m.ID, formatTime(span[0]), formatTime(span[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, st := range []string{"up", "down"} {
+		if _, err := store.writeDB.Exec(`INSERT INTO check_results (monitor_id, status, created_at) VALUES (?, ?, ?)`,
+			m.ID, st, formatTime(day(3, 0))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("incident spans", func(t *testing.T) {
+		rep, err := store.GetSLAReport(ctx, m.ID, day(1, 0), day(11, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rep.NoData || rep.UptimePct == nil {
+			t.Fatalf("expected data, got %+v", rep)
+		}
+		// 1h + 3h + 3h (merged overlap) + 1h clipped at the end.
+		if rep.DowntimeSeconds != 8*3600 {
+			t.Errorf("downtime = %ds, want %d", rep.DowntimeSeconds, 8*3600)
+		}
+		if want := float64(240-8) / 240 * 100; math.Abs(*rep.UptimePct-want) > 1e-9 {
+			t.Errorf("uptime = %f, want %f", *rep.UptimePct, want)
+		}
+		if rep.IncidentCount != 5 {
+			t.Errorf("incident count = %d, want 5", rep.IncidentCount)
+		}
+		if rep.LongestIncidentSeconds != 3*3600 || rep.LongestIncidentID == nil {
+			t.Errorf("longest = %ds (id %v), want 3h", rep.LongestIncidentSeconds, rep.LongestIncidentID)
+		}
+		// Only the four incidents resolved inside the range: (1+3+2+2)/4 h.
+		if rep.MTTRSeconds != 2*3600 {
+			t.Errorf("mttr = %ds, want %d", rep.MTTRSeconds, 2*3600)
+		}
+		if rep.TotalChecks != 2 || rep.FailedChecks != 1 {
+			t.Errorf("checks = %d/%d, want 2 with 1 failed", rep.TotalChecks, rep.FailedChecks)
+		}
+	})
+
+	t.Run("range starts before the monitor existed", func(t *testing.T) {
+		rep, err := store.GetSLAReport(ctx, m.ID, day(1, 0).AddDate(0, -1, 0), day(11, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rep.MeasuredFrom.Equal(day(1, 0)) {
+			t.Errorf("measured from %v, want monitor creation", rep.MeasuredFrom)
+		}
+		if rep.DowntimeSeconds != 8*3600 {
+			t.Errorf("downtime = %ds", rep.DowntimeSeconds)
+		}
+	})
+
+	t.Run("monitor did not exist yet", func(t *testing.T) {
+		rep, err := store.GetSLAReport(ctx, m.ID, day(1, 0).AddDate(0, -2, 0), day(1, 0).AddDate(0, -1, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rep.NoData || rep.UptimePct != nil {
+			t.Fatalf("expected no data, got %+v", rep)
+		}
+	})
+
+	t.Run("no checks or incidents", func(t *testing.T) {
+		rep, err := store.GetSLAReport(ctx, m.ID, day(20, 0), day(25, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rep.NoData {
+			t.Fatalf("expected no data, got %+v", rep)
+		}
+	})
+
+	t.Run("unknown monitor", func(t *testing.T) {
+		if _, err := store.GetSLAReport(ctx, 9999, day(1, 0), day(11, 0)); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows, got %v", err)
+		}
+	})
+}
+
+func TestListAuditLog(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, e := range []AuditEntry{
+		{Action: "create", Entity: "monitor", EntityID: 1, APIKeyName: "ci"},
+		{Action: "update", Entity: "monitor", EntityID: 1, APIKeyName: "ci"},
+		{Action: "delete", Entity: "tag", EntityID: 2, APIKeyName: "admin"},
+	} {
+		if _, err := store.writeDB.Exec(`INSERT INTO audit_log (action, entity, entity_id, api_key_name, detail, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			e.Action, e.Entity, e.EntityID, e.APIKeyName, "", formatTime(base.Add(time.Duration(i)*time.Hour))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := func(f AuditLogFilter) []*AuditEntry {
+		t.Helper()
+		res, err := store.ListAuditLog(ctx, f, Pagination{Page: 1, PerPage: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Data.([]*AuditEntry)
+	}
+
+	all := list(AuditLogFilter{})
+	if len(all) != 3 || all[0].Action != "delete" || all[2].Action != "create" {
+		t.Fatalf("expected newest first, got %+v", all)
+	}
+	if got := list(AuditLogFilter{APIKeyName: "ci", Entity: "monitor"}); len(got) != 2 {
+		t.Errorf("api key and entity filter: got %d entries", len(got))
+	}
+	got := list(AuditLogFilter{From: base.Add(time.Hour), To: base.Add(2 * time.Hour)})
+	if len(got) != 1 || got[0].Action != "update" {
+		t.Errorf("date range: got %+v", got)
+	}
+	if got := list(AuditLogFilter{Action: "export"}); len(got) != 0 {
+		t.Errorf("expected no entries, got %d", len(got))
+	}
+}
+
+func TestGetGroupStatus(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	g := &MonitorGroup{Name: "api"}
+	if err := store.CreateMonitorGroup(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	add := func(name, typ, status string, enabled bool) {
+		t.Helper()
+		m := &Monitor{
+			Name: name, Type: typ, Target: "https://example.com",
+			Interval: 60, Timeout: 10, Enabled: enabled, GroupID: &g.ID,
+			FailureThreshold: 3, SuccessThreshold: 1,
+		}
+		if err := store.CreateMonitor(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+		if status != "" {
+			if err := store.UpsertMonitorStatus(ctx, &MonitorStatus{MonitorID: m.ID, Status: status}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	status := func() *GroupStatus {
+		t.Helper()
+		gs, err := store.GetGroupStatus(ctx, g.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gs
+	}
+
+	if gs := status(); gs.Status != "pending" || gs.Total != 0 {
+		t.Fatalf("empty group: got %+v", gs)
+	}
+
+	add("web", "http", "up", true)
+	add("new", "http", "", true)
+	if gs := status(); gs.Status != "up" || gs.Up != 1 || gs.Pending != 1 {
+		t.Fatalf("expected up, got %+v", gs)
+	}
+
+	add("paused", "http", "down", false)
+	add("cron", "heartbeat", "down", true)
+	gs := status()
+	if gs.Status != "up" {
+		t.Fatalf("paused and heartbeat monitors should not take the group down, got %+v", gs)
+	}
+	if gs.Total != 4 || gs.Paused != 1 || gs.Down != 1 {
+		t.Fatalf("unexpected counts: %+v", gs)
+	}
+
+	add("slow", "http", "degraded", true)
+	if gs := status(); gs.Status != "degraded" {
+		t.Fatalf("expected degraded, got %+v", gs)
+	}
+
+	add("db", "tcp", "down", true)
+	if gs := status(); gs.Status != "down" || gs.Down != 2 {
+		t.Fatalf("expected down, got %+v", gs)
+	}
+
+	if _, err := store.GetGroupStatus(ctx, 9999); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestGetGroupUptime(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	g := &MonitorGroup{Name: "checkout"}
+	if err := store.CreateMonitorGroup(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	add := func(name string, interval int) int64 {
+		t.Helper()
+		m := &Monitor{
+			Name: name, Type: "http", Target: "https://example.com",
+			Interval: interval, Timeout: 10, Enabled: true, GroupID: &g.ID,
+			FailureThreshold: 3, SuccessThreshold: 1,
+		}
+		if err := store.CreateMonitor(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+		return m.ID
+	}
+	web := add("web", 60)
+	db := add("db", 300)
+	add("cache", 30) // never checked
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	check := func(monitorID int64, status string, offset time.Duration) {
+		t.Helper()
+		if _, err := store.writeDB.Exec(`INSERT INTO check_results (monitor_id, status, created_at) VALUES (?, ?, ?)`,
+			monitorID, status, formatTime(from.Add(offset))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Buckets are 5 minutes, the db monitor's interval. web is down once in
+	// the second bucket, db in the third, and nothing runs in the fourth.
+	for i := 0; i < 15; i++ {
+		status := "up"
+		if i == 7 {
+			status = "down"
+		}
+		check(web, status, time.Duration(i)*time.Minute)
+	}
+	check(db, "up", 0)
+	check(db, "up", 5*time.Minute)
+	check(db, "down", 10*time.Minute)
+	to := from.Add(20 * time.Minute)
+
+	all, err := store.GetGroupUptime(ctx, g.ID, from, to, GroupUptimeAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.BucketSeconds != 300 || all.Monitors != 3 {
+		t.Fatalf("unexpected buckets: %+v", all)
+	}
+	if all.UpBuckets != 1 || all.DownBuckets != 2 || all.NoDataBuckets != 1 {
+		t.Fatalf("all: unexpected counts: %+v", all)
+	}
+	if all.UptimePct == nil || math.Abs(*all.UptimePct-100.0/3) > 0.01 {
+		t.Fatalf("all: expected 33.3%% uptime, got %v", all.UptimePct)
+	}
+
+	anyUp, err := store.GetGroupUptime(ctx, g.ID, from, to, GroupUptimeAny)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anyUp.UpBuckets != 3 || anyUp.DownBuckets != 0 || anyUp.UptimePct == nil || *anyUp.UptimePct != 100 {
+		t.Fatalf("any: unexpected result: %+v", anyUp)
+	}
+
+	empty, err := store.GetGroupUptime(ctx, g.ID, to, to.Add(time.Hour), GroupUptimeAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.UptimePct != nil || empty.NoDataBuckets != 12 {
+		t.Fatalf("expected no data, got %+v", empty)
+	}
+
+	if _, err := store.GetGroupUptime(ctx, g.ID, from, to, "most"); err == nil {
+		t.Fatal("expected an error for an unknown mode")
+	}
+	if _, err := store.GetGroupUptime(ctx, 9999, from, to, GroupUptimeAll); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestListMonitorsByOwner(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	a := createTestMonitor(t, store, ctx, "A")
+	createTestMonitor(t, store, ctx, "B")
+	a.Owner = "payments"
+	if err := store.UpdateMonitor(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := store.ListMonitors(ctx, MonitorListFilter{Owner: "payments"}, Pagination{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mons := res.Data.([]*Monitor)
+	if res.Total != 1 || len(mons) != 1 || mons[0].ID != a.ID || mons[0].Owner != "payments" {
+		t.Fatalf("expected only monitor A owned by payments, got %+v", mons)
+	}
+}
+
+func TestListMonitorsByTags(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	var tagIDs []int64
+	for _, name := range []string{"team:core", "env:prod", "env:staging"} {
+		tag := &Tag{Name: name, Color: "#000000"}
+		if err := store.CreateTag(ctx, tag); err != nil {
+			t.Fatal(err)
+		}
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	team, prod, staging := tagIDs[0], tagIDs[1], tagIDs[2]
+
+	both := createTestMonitor(t, store, ctx, "Both")
+	teamOnly := createTestMonitor(t, store, ctx, "TeamOnly")
+	stagingOnly := createTestMonitor(t, store, ctx, "StagingOnly")
+	createTestMonitor(t, store, ctx, "Untagged")
+	for id, tags := range map[int64][]int64{both.ID: {team, prod}, teamOnly.ID: {team}, stagingOnly.ID: {staging}} {
+		var mts []MonitorTag
+		for _, tid := range tags {
+			mts = append(mts, MonitorTag{TagID: tid})
+		}
+		if err := store.SetMonitorTags(ctx, id, mts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := func(tags []int64, match string) []string {
+		t.Helper()
+		res, err := store.ListMonitors(ctx, MonitorListFilter{TagIDs: tags, TagMatch: match}, Pagination{Page: 1, PerPage: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, m := range res.Data.([]*Monitor) {
+			names = append(names, m.Name)
+		}
+		if res.Total != int64(len(names)) {
+			t.Errorf("total %d, listed %d", res.Total, len(names))
+		}
+		return names
+	}
+
+	tests := []struct {
+		name  string
+		tags  []int64
+		match string
+		want  []string
+	}{
+		{"single tag", []int64{team}, "", []string{"Both", "TeamOnly"}},
+		{"all of two", []int64{team, prod}, TagMatchAll, []string{"Both"}},
+		{"all is the default", []int64{team, prod}, "", []string{"Both"}},
+		{"all with no monitor", []int64{prod, staging}, TagMatchAll, nil},
+		{"any of two", []int64{team, prod}, TagMatchAny, []string{"Both", "TeamOnly"}},
+		{"any across monitors", []int64{prod, staging}, TagMatchAny, []string{"Both", "StagingOnly"}},
+		{"no tags", nil, TagMatchAny, []string{"Both", "StagingOnly", "TeamOnly", "Untagged"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := list(tt.tags, tt.match); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListMonitorsScope(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	g := &MonitorGroup{Name: "Payments"}
+	if err := store.CreateMonitorGroup(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+	tag := &Tag{Name: "team-a", Color: "#000000"}
+	if err := store.CreateTag(ctx, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	grouped := createTestMonitor(t, store, ctx, "Grouped")
+	grouped.GroupID = &g.ID
+	if err := store.UpdateMonitor(ctx, grouped); err != nil {
+		t.Fatal(err)
+	}
+	tagged := createTestMonitor(t, store, ctx, "Tagged")
+	if err := store.SetMonitorTags(ctx, tagged.ID, []MonitorTag{{TagID: tag.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	createTestMonitor(t, store, ctx, "Other")
+
+	list := func(scope *MonitorScope) []string {
+		t.Helper()
+		res, err := store.ListMonitors(ctx, MonitorListFilter{Scope: scope}, Pagination{Page: 1, PerPage: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, m := range res.Data.([]*Monitor) {
+			names = append(names, m.Name)
+		}
+		if res.Total != int64(len(names)) {
+			t.Errorf("total %d, listed %d", res.Total, len(names))
+		}
+		return names
+	}
+
+	if got := list(&MonitorScope{GroupIDs: []int64{g.ID}}); len(got) != 1 || got[0] != "Grouped" {
+		t.Errorf("group scope: %v", got)
+	}
+	if got := list(&MonitorScope{TagIDs: []int64{tag.ID}}); len(got) != 1 || got[0] != "Tagged" {
+		t.Errorf("tag scope: %v", got)
+	}
+	if got := list(&MonitorScope{GroupIDs: []int64{g.ID}, TagIDs: []int64{tag.ID}}); len(got) != 2 {
+		t.Errorf("group or tag scope: %v", got)
+	}
+	if got := list(&MonitorScope{}); len(got) != 0 {
+		t.Errorf("empty scope: %v", got)
+	}
+}
+
+func TestMonitorScopeAllows(t *testing.T) {
+	gid, other := int64(1), int64(2)
+	s := &MonitorScope{GroupIDs: []int64{gid}, TagIDs: []int64{7}}
+	if !s.Allows(&gid, nil) || !s.Allows(nil, []int64{3, 7}) {
+		t.Error("expected in scope")
+	}
+	if s.Allows(&other, []int64{3}) || s.Allows(nil, nil) {
+		t.Error("expected out of scope")
+	}
+}
+
+func TestMonitorLimits(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	store.SetMonitorLimits(MonitorLimits{MaxMonitors: 3, MaxMonitorsPerGroup: 2})
+
+	g := &MonitorGroup{Name: "Team"}
+	if err := store.CreateMonitorGroup(ctx, g); err != nil {
+		t.Fatal(err)
+	}
+
+	m1 := createTestMonitor(t, store, ctx, "L1")
+	m2 := createTestMonitor(t, store, ctx, "L2")
+	m3 := createTestMonitor(t, store, ctx, "L3")
+
+	err := store.CreateMonitor(ctx, &Monitor{Name: "L4", Type: "http", Target: "https://example.com"})
+	if !errors.Is(err, ErrMonitorLimit) {
+		t.Fatalf("expected ErrMonitorLimit for instance limit, got %v", err)
+	}
+
+	if _, err := store.BulkSetMonitorGroup(ctx, []int64{m1.ID, m2.ID}, &g.ID); err != nil {
+		t.Fatal(err)
+	}
+	// re-assigning monitors already in the group does not count twice
+	if _, err := store.BulkSetMonitorGroup(ctx, []int64{m1.ID, m2.ID}, &g.ID); err != nil {
+		t.Fatalf("re-assigning same monitors: %v", err)
+	}
+	if _, err := store.BulkSetMonitorGroup(ctx, []int64{m3.ID}, &g.ID); !errors.Is(err, ErrMonitorLimit) {
+		t.Fatalf("expected ErrMonitorLimit for group limit, got %v", err)
+	}
+
+	m3.GroupID = &g.ID
+	if err := store.UpdateMonitor(ctx, m3); !errors.Is(err, ErrMonitorLimit) {
+		t.Fatalf("expected ErrMonitorLimit on update, got %v", err)
+	}
+	got, _ := store.GetMonitor(ctx, m3.ID)
+	if got.GroupID != nil {
+		t.Error("m3 should not have been moved")
+	}
+
+	// updating a monitor that is already in a full group is allowed
+	got1, _ := store.GetMonitor(ctx, m1.ID)
+	got1.Name = "L1 renamed"
+	if err := store.UpdateMonitor(ctx, got1); err != nil {
+		t.Fatalf("update within group: %v", err)
+	}
+
+	total, byGroup, err := store.CountMonitors(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || byGroup[g.ID] != 2 {
+		t.Errorf("expected 3 total and 2 in group, got %d and %d", total, byGroup[g.ID])
+	}
+}
+
+func TestCreateMonitors(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	ch := &NotificationChannel{Name: "mail", Type: "email", Enabled: true, Settings: []byte(`{}`)}
+	if err := store.CreateNotificationChannel(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	tag := &Tag{Name: "region", Color: "#ff0000"}
+	if err := store.CreateTag(ctx, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	var mons []*Monitor
+	for _, region := range []string{"eu", "us"} {
+		mons = append(mons, &Monitor{
+			Name: "API " + region, Type: "http", Target: "https://" + region + ".example.com",
+			Interval: 60, Timeout: 10, Enabled: true, FailureThreshold: 3, SuccessThreshold: 1,
+			NotificationChannelIDs: []int64{ch.ID},
+			NotificationFilters:    map[int64]*NotificationFilter{ch.ID: {MinSeverity: "critical"}},
+			MonitorTags:            []MonitorTag{{TagID: tag.ID, Value: region}},
+		})
+	}
+	if err := store.CreateMonitors(ctx, mons); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, m := range mons {
+		if m.ID == 0 || m.CreatedAt.IsZero() {
+			t.Fatalf("monitor %d not created: %+v", i, m)
+		}
+		ids, _ := store.GetMonitorNotificationChannelIDs(ctx, m.ID)
+		if len(ids) != 1 || ids[0] != ch.ID {
+			t.Errorf("monitor %d channels = %v", i, ids)
+		}
+		filters, _ := store.GetMonitorNotificationFilters(ctx, m.ID)
+		if f := filters[ch.ID]; f == nil || f.MinSeverity != "critical" {
+			t.Errorf("monitor %d filter = %+v", i, f)
+		}
+		tags, _ := store.GetMonitorTags(ctx, m.ID)
+		if len(tags) != 1 || tags[0].Value != []string{"eu", "us"}[i] {
+			t.Errorf("monitor %d tags = %+v", i, tags)
+		}
+		if _, err := store.GetMonitorStatus(ctx, m.ID); err != nil {
+			t.Errorf("monitor %d status: %v", i, err)
+		}
+	}
+
+	store.SetMonitorLimits(MonitorLimits{MaxMonitors: 3})
+	err := store.CreateMonitors(ctx, []*Monitor{
+		{Name: "X1", Type: "http", Target: "https://example.com"},
+		{Name: "X2", Type: "http", Target: "https://example.com"},
+	})
+	if !errors.Is(err, ErrMonitorLimit) {
+		t.Fatalf("expected ErrMonitorLimit, got %v", err)
+	}
+	total, _, _ := store.CountMonitors(ctx)
+	if total != 2 {
+		t.Errorf("expected no monitors from the rejected batch, got %d total", total)
+	}
+}
+
+func TestWriteRetryBuffersWhileLocked(t *testing.T) {
+	sqliteOnly(t)
+	store := testStore(t)
+	ctx := context.Background()
+	m := createTestMonitor(t, store, ctx, "Locked")
+
+	store.SetWriteRetry(WriteRetry{Attempts: 2, Backoff: time.Millisecond, BufferSize: 2})
+	if _, err := store.writeDB.Exec("PRAGMA busy_timeout=10"); err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := sql.Open("sqlite", store.dbPath+"?_busy_timeout=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	lock, err := other.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Close()
+	if _, err := lock.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := store.InsertCheckResult(ctx, &CheckResult{MonitorID: m.ID, Status: "up"}); err != nil {
+			t.Fatalf("buffered insert %d: %v", i, err)
+		}
+	}
+	if err := store.InsertCheckResult(ctx, &CheckResult{MonitorID: m.ID, Status: "up"}); err == nil {
+		t.Fatal("expected an error once the buffer is full")
+	}
+	if store.PendingWrites() != 2 || store.DroppedWrites() != 1 {
+		t.Fatalf("pending=%d dropped=%d, want 2 and 1", store.PendingWrites(), store.DroppedWrites())
+	}
+
+	if _, err := lock.ExecContext(ctx, "ROLLBACK"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertMonitorStatus(ctx, &MonitorStatus{MonitorID: m.ID, Status: "up"}); err != nil {
+		t.Fatal(err)
+	}
+	if store.PendingWrites() != 0 {
+		t.Fatalf("expected buffer flushed, %d pending", store.PendingWrites())
+	}
+	result, err := store.ListCheckResults(ctx, m.ID, Pagination{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 {
+		t.Errorf("expected 2 flushed check results, got %d", result.Total)
+	}
+}
+
+func TestEscalationPolicies(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	ep := &EscalationPolicy{Name: "payments", Steps: []EscalationStep{
+		{DelayMinutes: 15, ChannelIDs: []int64{1}},
+		{DelayMinutes: 45, ChannelIDs: []int64{2, 3}},
+	}}
+	if err := store.CreateEscalationPolicy(ctx, ep); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetEscalationPolicy(ctx, ep.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "payments" || len(got.Steps) != 2 || got.Steps[1].DelayMinutes != 45 || len(got.Steps[1].ChannelIDs) != 2 {
+		t.Fatalf("get mismatch: %+v", got)
+	}
+
+	mon := createTestMonitor(t, store, ctx, "Checkout")
+	other := createTestMonitor(t, store, ctx, "Unassigned")
+	if id, _ := store.GetMonitorEscalationPolicyID(ctx, mon.ID); id != nil {
+		t.Fatalf("expected no policy, got %d", *id)
+	}
+	if err := store.SetMonitorEscalationPolicy(ctx, mon.ID, &ep.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetMonitorEscalationPolicy(ctx, mon.ID, &ep.ID); err != nil {
+		t.Fatalf("reassigning: %v", err)
+	}
+	if id, _ := store.GetMonitorEscalationPolicyID(ctx, mon.ID); id == nil || *id != ep.ID {
+		t.Fatalf("expected policy %d, got %v", ep.ID, id)
+	}
+
+	inc := &Incident{MonitorID: mon.ID, Status: "open", Cause: "down"}
+	store.CreateIncident(ctx, inc)
+	store.CreateIncident(ctx, &Incident{MonitorID: other.ID, Status: "open", Cause: "down"})
+	store.InsertIncidentEvent(ctx, &IncidentEvent{IncidentID: inc.ID, Type: "escalated", Message: "step 1"})
+
+	pending, err := store.ListPendingEscalations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].IncidentID != inc.ID || pending[0].PolicyID != ep.ID || pending[0].Escalated != 1 {
+		t.Fatalf("pending mismatch: %+v", pending)
+	}
+
+	inc.Status = "acknowledged"
+	store.UpdateIncident(ctx, inc)
+	if pending, _ = store.ListPendingEscalations(ctx); len(pending) != 0 {
+		t.Fatalf("acknowledged incident still pending: %+v", pending)
+	}
+
+	if err := store.DeleteEscalationPolicy(ctx, ep.ID); err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := store.GetMonitorEscalationPolicyID(ctx, mon.ID); id != nil {
+		t.Fatalf("assignment survived policy delete: %d", *id)
+	}
+}
+
+func TestMaintenanceWindowTimezone(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+
+	mon := createTestMonitor(t, store, ctx, "Nightly")
+	mw := &MaintenanceWindow{
+		Name:       "nightly backup",
+		MonitorIDs: []int64{mon.ID},
+		StartTime:  time.Date(2025, 1, 6, 2, 0, 0, 0, ny),
+		EndTime:    time.Date(2025, 1, 6, 3, 0, 0, 0, ny),
+		Recurring:  "daily",
+		Timezone:   "America/New_York",
+	}
+	if err := store.CreateMaintenanceWindow(ctx, mw); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := store.GetMaintenanceWindow(ctx, mw.ID)
+	if got.Timezone != "America/New_York" {
+		t.Fatalf("timezone = %q", got.Timezone)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"02:30 local in winter", time.Date(2025, 2, 10, 7, 30, 0, 0, time.UTC), true},
+		{"02:30 local in summer", time.Date(2025, 7, 10, 6, 30, 0, 0, time.UTC), true},
+		{"02:30 UTC", time.Date(2025, 7, 10, 2, 30, 0, 0, time.UTC), false},
+		{"03:30 local", time.Date(2025, 7, 10, 7, 30, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := store.IsMonitorInMaintenance(ctx, mon.ID, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if in != tt.want {
+				t.Errorf("in maintenance at %s = %v, want %v", tt.at, in, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowCron(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+
+	mon := createTestMonitor(t, store, ctx, "Monthly")
+	other := createTestMonitor(t, store, ctx, "Other")
+	mw := &MaintenanceWindow{
+		Name:            "first monday",
+		MonitorIDs:      []int64{mon.ID},
+		Recurring:       "cron",
+		Timezone:        "America/New_York",
+		CronExpr:        "0 1 * * MON#1",
+		DurationMinutes: 120,
+	}
+	if err := store.CreateMaintenanceWindow(ctx, mw); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := store.GetMaintenanceWindow(ctx, mw.ID)
+	if got.CronExpr != mw.CronExpr || got.DurationMinutes != 120 {
+		t.Fatalf("round trip: cron_expr=%q duration=%d", got.CronExpr, got.DurationMinutes)
+	}
+
+	tests := []struct {
+		name      string
+		monitorID int64
+		at        time.Time
+		want      bool
+	}{
+		{"first monday 01:00", mon.ID, time.Date(2026, 10, 5, 1, 0, 0, 0, ny), true},
+		{"first monday 02:59", mon.ID, time.Date(2026, 10, 5, 2, 59, 0, 0, ny), true},
+		{"first monday 03:00", mon.ID, time.Date(2026, 10, 5, 3, 0, 0, 0, ny), false},
+		{"first monday 00:59", mon.ID, time.Date(2026, 10, 5, 0, 59, 0, 0, ny), false},
+		{"second monday", mon.ID, time.Date(2026, 10, 12, 1, 30, 0, 0, ny), false},
+		{"01:30 UTC", mon.ID, time.Date(2026, 10, 5, 1, 30, 0, 0, time.UTC), false},
+		{"monitor not in window", other.ID, time.Date(2026, 10, 5, 1, 30, 0, 0, ny), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := store.IsMonitorInMaintenance(ctx, tt.monitorID, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if in != tt.want {
+				t.Errorf("in maintenance at %s = %v, want %v", tt.at, in, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextMaintenanceOccurrence(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	utc := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, time.UTC)
+	}
+	// 2026-10-14 is a Wednesday.
+	noon := utc(10, 14, 12, 0)
+
+	tests := []struct {
+		name      string
+		mw        MaintenanceWindow
+		after     time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+		wantOK    bool
+	}{
+		{"one-off upcoming", MaintenanceWindow{StartTime: utc(10, 20, 10, 0), EndTime: utc(10, 20, 11, 0)}, noon, utc(10, 20, 10, 0), utc(10, 20, 11, 0), true},
+		{"one-off in progress", MaintenanceWindow{StartTime: utc(10, 14, 11, 0), EndTime: utc(10, 14, 13, 0)}, noon, utc(10, 14, 11, 0), utc(10, 14, 13, 0), true},
+		{"one-off in the past", MaintenanceWindow{StartTime: utc(10, 1, 10, 0), EndTime: utc(10, 1, 11, 0)}, noon, time.Time{}, time.Time{}, false},
+		{"daily later today", MaintenanceWindow{StartTime: utc(1, 5, 22, 0), EndTime: utc(1, 5, 23, 0), Recurring: "daily"}, noon, utc(10, 14, 22, 0), utc(10, 14, 23, 0), true},
+		{"daily already passed today", MaintenanceWindow{StartTime: utc(1, 5, 2, 0), EndTime: utc(1, 5, 3, 0), Recurring: "daily"}, noon, utc(10, 15, 2, 0), utc(10, 15, 3, 0), true},
+		{"daily wrapping midnight, still open", MaintenanceWindow{StartTime: utc(1, 5, 23, 0), EndTime: utc(1, 6, 1, 0), Recurring: "daily"}, utc(10, 14, 0, 30), utc(10, 13, 23, 0), utc(10, 14, 1, 0), true},
+		{"daily wrapping midnight, upcoming", MaintenanceWindow{StartTime: utc(1, 5, 23, 0), EndTime: utc(1, 6, 1, 0), Recurring: "daily"}, noon, utc(10, 14, 23, 0), utc(10, 15, 1, 0), true},
+		{"weekly today, not yet started", MaintenanceWindow{StartTime: utc(10, 7, 13, 0), EndTime: utc(10, 7, 14, 0), Recurring: "weekly"}, noon, utc(10, 14, 13, 0), utc(10, 14, 14, 0), true},
+		{"weekly today, already passed", MaintenanceWindow{StartTime: utc(10, 7, 9, 0), EndTime: utc(10, 7, 10, 0), Recurring: "weekly"}, noon, utc(10, 21, 9, 0), utc(10, 21, 10, 0), true},
+		{"weekly closes at midnight", MaintenanceWindow{StartTime: utc(10, 7, 23, 0), EndTime: utc(10, 8, 1, 0), Recurring: "weekly"}, noon, utc(10, 14, 23, 0), utc(10, 15, 0, 0), true},
+		{"monthly on the 31st skips short months", MaintenanceWindow{StartTime: utc(8, 31, 1, 0), EndTime: utc(8, 31, 2, 0), Recurring: "monthly"}, utc(11, 5, 0, 0), utc(12, 31, 1, 0), utc(12, 31, 2, 0), true},
+		{"daily in local time", MaintenanceWindow{StartTime: time.Date(2026, 1, 5, 2, 0, 0, 0, ny), EndTime: time.Date(2026, 1, 5, 3, 0, 0, 0, ny), Recurring: "daily", Timezone: "America/New_York"}, noon, time.Date(2026, 10, 15, 2, 0, 0, 0, ny), time.Date(2026, 10, 15, 3, 0, 0, 0, ny), true},
+		{"cron in progress", MaintenanceWindow{Recurring: "cron", Timezone: "America/New_York", CronExpr: "0 1 * * MON#1", DurationMinutes: 120}, time.Date(2026, 10, 5, 2, 0, 0, 0, ny), time.Date(2026, 10, 5, 1, 0, 0, 0, ny), time.Date(2026, 10, 5, 3, 0, 0, 0, ny), true},
+		{"cron next month", MaintenanceWindow{Recurring: "cron", Timezone: "America/New_York", CronExpr: "0 1 * * MON#1", DurationMinutes: 120}, time.Date(2026, 10, 5, 3, 0, 0, 0, ny), time.Date(2026, 11, 2, 1, 0, 0, 0, ny), time.Date(2026, 11, 2, 3, 0, 0, 0, ny), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := NextMaintenanceOccurrence(&tt.mw, tt.after)
+			if ok != tt.wantOK || !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Fatalf("NextMaintenanceOccurrence() = %s, %s, %v; want %s, %s, %v", start, end, ok, tt.wantStart, tt.wantEnd, tt.wantOK)
+			}
+			if ok && (!isInWindow(&tt.mw, start) || isInWindow(&tt.mw, end)) {
+				t.Errorf("occurrence %s-%s disagrees with isInWindow", start, end)
+			}
+		})
+	}
+}
+
+func TestListCheckResultsRange(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+	mon := createTestMonitor(t, store, ctx, "Ranged")
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		cr := &CheckResult{MonitorID: mon.ID, Status: "up", ResponseTime: int64(i)}
+		if err := store.InsertCheckResult(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, cr.ID)
+	}
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	var got []int64
+	var afterID int64
+	for {
+		batch, err := store.ListCheckResultsRange(ctx, mon.ID, from, to, afterID, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, cr := range batch {
+			got = append(got, cr.ID)
+		}
+		if len(batch) < 2 {
+			break
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+	if fmt.Sprint(got) != fmt.Sprint(ids) {
+		t.Errorf("paged ids = %v, want %v", got, ids)
+	}
+
+	batch, _ := store.ListCheckResultsRange(ctx, mon.ID, to.Add(time.Minute), to.Add(time.Hour), 0, 10)
+	if len(batch) != 0 {
+		t.Errorf("expected no results outside the range, got %d", len(batch))
+	}
+}
+
+func TestProbes(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	local := &Probe{Name: "us-east", Type: "local", SourceAddr: "192.0.2.10", Enabled: true}
+	agent := &Probe{Name: "EU-west", Type: "agent", URL: "https://eu.example.com/api/v1/probe/check", Token: "ak_x", Enabled: true}
+	off := &Probe{Name: "ap-south", Type: "local", Enabled: false}
+	for _, p := range []*Probe{local, agent, off} {
+		if err := store.CreateProbe(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := store.GetProbe(ctx, agent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != "agent" || got.URL != agent.URL || got.Token != "ak_x" || !got.Enabled {
+		t.Fatalf("get mismatch: %+v", got)
+	}
+	probes, err := store.ListProbes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(probes) != 3 || probes[0].Name != "ap-south" || probes[1].Name != "EU-west" {
+		t.Fatalf("expected probes ordered by name, got %+v", probes)
+	}
+
+	mon := createTestMonitor(t, store, ctx, "Regional")
+	if err := store.SetMonitorProbes(ctx, mon.ID, []int64{local.ID, agent.ID, off.ID}); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := store.GetMonitorProbeIDs(ctx, mon.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("expected 3 assigned probes, got %v", ids)
+	}
+	assigned, err := store.ListMonitorProbes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assigned[mon.ID]) != 2 || assigned[mon.ID][0].SourceAddr != "192.0.2.10" {
+		t.Fatalf("expected the 2 enabled probes, got %+v", assigned[mon.ID])
+	}
+
+	for _, c := range []struct {
+		probe  *int64
+		status string
+	}{
+		{nil, "up"},
+		{&local.ID, "up"},
+		{&local.ID, "up"},
+		{&agent.ID, "up"},
+		{&agent.ID, "down"},
+	} {
+		cr := &CheckResult{MonitorID: mon.ID, Status: c.status, ResponseTime: 10, ProbeID: c.probe}
+		if err := store.InsertCheckResult(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		got, err := store.GetCheckResult(ctx, cr.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (got.ProbeID == nil) != (c.probe == nil) || (c.probe != nil && *got.ProbeID != *c.probe) {
+			t.Fatalf("probe_id round trip: got %v, want %v", got.ProbeID, c.probe)
+		}
+		if err := store.InsertProbeChecks(ctx, []*CheckResult{cr}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	uptime, err := store.GetUptimeByProbe(ctx, mon.ID, now.Add(-time.Hour), now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uptime) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(uptime))
+	}
+	if uptime[0].ProbeID != nil || uptime[0].Total != 1 {
+		t.Errorf("expected checks without a probe first, got %+v", uptime[0])
+	}
+	eu := uptime[1]
+	if eu.ProbeID == nil || *eu.ProbeID != agent.ID || eu.ProbeName != "EU-west" {
+		t.Fatalf("expected the agent probe second, got %+v", eu)
+	}
+	if eu.Total != 2 || eu.Up != 1 || eu.Down != 1 || eu.Uptime != 50 || eu.LastStatus != "down" || eu.LastCheckAt == nil {
+		t.Errorf("unexpected agent probe uptime: %+v", eu)
+	}
+	if us := uptime[2]; us.Total != 2 || us.Uptime != 100 || us.LastStatus != "up" {
+		t.Errorf("unexpected local probe uptime: %+v", us)
+	}
+
+	if err := store.DeleteProbe(ctx, agent.ID); err != nil {
+		t.Fatal(err)
+	}
+	ids, _ = store.GetMonitorProbeIDs(ctx, mon.ID)
+	if len(ids) != 2 {
+		t.Fatalf("expected deleting a probe to drop its assignment, got %v", ids)
+	}
+	if _, err := store.GetProbe(ctx, agent.ID); err != sql.ErrNoRows {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestOncallSchedules(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	alice := OncallMember{Name: "Alice", Email: "alice@example.com"}
+	bob := OncallMember{Name: "Bob", Email: "bob@example.com", RoutingKey: "R0BOB"}
+	carol := OncallMember{Name: "Carol", Email: "carol@example.com"}
+	sched := &OncallSchedule{
+		Name:          "Primary",
+		Timezone:      "Europe/Amsterdam",
+		RotationStart: "2026-03-27T09:00",
+		ShiftHours:    24,
+		Members:       []OncallMember{alice, bob, carol},
+	}
+	if err := store.CreateOncallSchedule(ctx, sched); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateOncallSchedule(ctx, &OncallSchedule{Name: "Backup", Timezone: "UTC", RotationStart: "2026-01-01T00:00", ShiftHours: 168}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.GetOncallSchedule(ctx, sched.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Timezone != "Europe/Amsterdam" || got.ShiftHours != 24 || len(got.Members) != 3 || got.Members[1] != bob {
+		t.Fatalf("get mismatch: %+v", got)
+	}
+	list, err := store.ListOncallSchedules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "Backup" || len(list[0].Members) != 0 {
+		t.Fatalf("expected schedules ordered by name, got %+v", list)
+	}
+
+	ams, _ := time.LoadLocation("Europe/Amsterdam")
+	for _, c := range []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 27, 8, 59, 0, 0, ams), ""},
+		{time.Date(2026, 3, 27, 9, 0, 0, 0, ams), "Alice"},
+		{time.Date(2026, 3, 28, 9, 30, 0, 0, ams), "Bob"},
+		// Clocks go forward on 29 March; handoffs stay at 09:00 local time.
+		{time.Date(2026, 3, 30, 8, 59, 0, 0, ams), "Carol"},
+		{time.Date(2026, 3, 30, 9, 0, 0, 0, ams), "Alice"},
+	} {
+		m, err := store.CurrentResponder(ctx, sched.ID, c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (m == nil && c.want != "") || (m != nil && m.Name != c.want) {
+			t.Fatalf("responder at %s: got %+v, want %q", c.at, m, c.want)
+		}
+	}
+
+	cover := OncallMember{Name: "Dave", Email: "dave@example.com"}
+	got.Overrides = []OncallOverride{{
+		Start:  time.Date(2026, 3, 28, 12, 0, 0, 0, ams).UTC(),
+		End:    time.Date(2026, 3, 28, 18, 0, 0, 0, ams).UTC(),
+		Member: cover,
+	}}
+	if err := store.UpdateOncallSchedule(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 28, 11, 59, 0, 0, ams), "Bob"},
+		{time.Date(2026, 3, 28, 12, 0, 0, 0, ams), "Dave"},
+		{time.Date(2026, 3, 28, 18, 0, 0, 0, ams), "Bob"},
+	} {
+		m, err := store.CurrentResponder(ctx, sched.ID, c.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m == nil || m.Name != c.want {
+			t.Fatalf("responder at %s: got %+v, want %q", c.at, m, c.want)
+		}
+	}
+
+	ch := &NotificationChannel{Name: "Pager", Type: "email", Enabled: true, Settings: []byte("{}"), OncallScheduleID: &sched.ID}
+	if err := store.CreateNotificationChannel(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	gotCh, err := store.GetNotificationChannel(ctx, ch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotCh.OncallScheduleID == nil || *gotCh.OncallScheduleID != sched.ID {
+		t.Fatalf("expected oncall_schedule_id %d, got %v", sched.ID, gotCh.OncallScheduleID)
+	}
+
+	if err := store.DeleteOncallSchedule(ctx, sched.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CurrentResponder(ctx, sched.ID, time.Now()); err != sql.ErrNoRows {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+	gotCh, err = store.GetNotificationChannel(ctx, ch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotCh.OncallScheduleID != nil {
+		t.Fatalf("expected channel detached from deleted schedule, got %v", *gotCh.OncallScheduleID)
+	}
+}
+
+func TestMonitorParents(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	gateway := createTestMonitor(t, store, ctx, "Gateway")
+	api := createTestMonitor(t, store, ctx, "API")
+	api.ParentID = &gateway.ID
+	if err := store.UpdateMonitor(ctx, api); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetMonitor(ctx, api.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ParentID == nil || *got.ParentID != gateway.ID {
+		t.Fatalf("expected parent_id %d, got %v", gateway.ID, got.ParentID)
+	}
+
+	parents, err := store.ListMonitorParents(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parents) != 1 || parents[api.ID] != gateway.ID {
+		t.Fatalf("expected {%d: %d}, got %v", api.ID, gateway.ID, parents)
+	}
+
+	if err := store.DeleteMonitor(ctx, gateway.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err = store.GetMonitor(ctx, api.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ParentID != nil {
+		t.Fatalf("expected parent_id cleared with the parent, got %d", *got.ParentID)
+	}
+}
+
+func TestMonitorTransitions(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	m := createTestMonitor(t, store, ctx, "Flappy")
+	other := createTestMonitor(t, store, ctx, "Other")
+	for _, tr := range []*MonitorTransition{
+		{MonitorID: m.ID, FromStatus: "up", ToStatus: "down", Cause: "timeout"},
+		{MonitorID: m.ID, FromStatus: "down", ToStatus: "up"},
+		{MonitorID: m.ID, FromStatus: "up", ToStatus: "degraded", Cause: "slow"},
+		{MonitorID: other.ID, FromStatus: "up", ToStatus: "down"},
+	} {
+		if err := store.InsertMonitorTransition(ctx, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := store.ListMonitorTransitions(ctx, m.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ToStatus != "degraded" || got[0].Cause != "slow" || got[1].ToStatus != "up" {
+		t.Fatalf("expected the two newest transitions, got %+v", got)
+	}
+
+	n, err := store.PurgeOldTransitions(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("purged %d transitions, want 4", n)
+	}
+	if got, _ := store.ListMonitorTransitions(ctx, m.ID, 10); len(got) != 0 {
+		t.Errorf("expected no transitions after purge, got %d", len(got))
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+func (s *SQLiteStore) InsertMonitorTransition(ctx context.Context, t *MonitorTransition) error {
+	now := formatTime(time.Now())
+	res, err := s.writeDB.ExecContext(ctx,
+		`INSERT INTO monitor_transitions (monitor_id, from_status, to_status, cause, created_at)
+		 VALUES (?, ?, ?, ?, ?)`,
+		t.MonitorID, t.FromStatus, t.ToStatus, t.Cause, now)
+	if err != nil {
+		return err
+	}
+	id, _ := res.LastInsertId()
+	t.ID = id
+	t.CreatedAt = parseTime(now)
+	return nil
+}
+
+// ListMonitorTransitions returns the monitor's latest limit transitions,
+// newest first.
+func (s *SQLiteStore) ListMonitorTransitions(ctx context.Context, monitorID int64, limit int) ([]*MonitorTransition, error) {
+	rows, err := s.readDB.QueryContext(ctx,
+		`SELECT id, monitor_id, from_status, to_status, cause, created_at
+		 FROM monitor_transitions WHERE monitor_id=? ORDER BY created_at DESC, id DESC LIMIT ?`,
+		monitorID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transitions := []*MonitorTransition{}
+	for rows.Next() {
+		var t MonitorTransition
+		var createdAt string
+		if err := rows.Scan(&t.ID, &t.MonitorID, &t.FromStatus, &t.ToStatus, &t.Cause, &createdAt); err != nil {
+			return nil, err
+		}
+		t.CreatedAt = parseTime(createdAt)
+		transitions = append(transitions, &t)
+	}
+	return transitions, rows.Err()
+}
+
+func (s *SQLiteStore) PurgeOldTransitions(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.writeDB.ExecContext(ctx, "DELETE FROM monitor_transitions WHERE created_at < ?", formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
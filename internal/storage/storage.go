@@ -2,13 +2,25 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrMonitorLimit is returned when creating or moving monitors would exceed
+// the configured instance-wide or per-group monitor limit.
+var ErrMonitorLimit = errors.New("monitor limit reached")
+
+// MonitorLimits caps how many monitors may exist. Zero means unlimited.
+type MonitorLimits struct {
+	MaxMonitors         int
+	MaxMonitorsPerGroup int
+}
+
 // Store defines the complete storage interface.
 type Store interface {
 	// Monitors
 	CreateMonitor(ctx context.Context, m *Monitor) error
+	CreateMonitors(ctx context.Context, monitors []*Monitor) error
 	GetMonitor(ctx context.Context, id int64) (*Monitor, error)
 	ListMonitors(ctx context.Context, f MonitorListFilter, p Pagination) (*PaginatedResult, error)
 	UpdateMonitor(ctx context.Context, m *Monitor) error
@@ -18,6 +30,8 @@ type Store interface {
 	BulkDeleteMonitors(ctx context.Context, ids []int64) (int64, error)
 	BulkSetMonitorGroup(ctx context.Context, ids []int64, groupID *int64) (int64, error)
 	GetAllEnabledMonitors(ctx context.Context) ([]*Monitor, error)
+	CountMonitors(ctx context.Context) (total int64, byGroup map[int64]int64, err error)
+	ListMonitorParents(ctx context.Context) (map[int64]int64, error)
 
 	// Monitor status (runtime state)
 	GetMonitorStatus(ctx context.Context, monitorID int64) (*MonitorStatus, error)
@@ -26,16 +40,23 @@ type Store interface {
 	// Check results
 	InsertCheckResult(ctx context.Context, r *CheckResult) error
 	ListCheckResults(ctx context.Context, monitorID int64, p Pagination) (*PaginatedResult, error)
+	ListCheckResultsRange(ctx context.Context, monitorID int64, from, to time.Time, afterID int64, limit int) ([]*CheckResult, error)
 	GetLatestCheckResult(ctx context.Context, monitorID int64) (*CheckResult, error)
+	GetCheckResult(ctx context.Context, id int64) (*CheckResult, error)
+	GetCheckConfig(ctx context.Context, monitorID int64, hash string) (*CheckConfig, error)
 	GetMonitorSparklines(ctx context.Context, monitorIDs []int64, n int) (map[int64][]*SparklinePoint, error)
 
 	// Incidents
 	CreateIncident(ctx context.Context, inc *Incident) error
 	GetIncident(ctx context.Context, id int64) (*Incident, error)
 	ListIncidents(ctx context.Context, monitorID int64, status string, search string, p Pagination) (*PaginatedResult, error)
+	GroupIncidentsByCause(ctx context.Context, monitorID int64, status string, search string, from time.Time) ([]*IncidentCauseGroup, error)
 	UpdateIncident(ctx context.Context, inc *Incident) error
+	SetIncidentPostmortem(ctx context.Context, id int64, postmortem string) error
 	DeleteIncident(ctx context.Context, id int64) error
 	GetOpenIncident(ctx context.Context, monitorID int64) (*Incident, error)
+	GetRecentlyResolvedIncident(ctx context.Context, monitorID int64, since time.Time) (*Incident, error)
+	ListExpiredAcks(ctx context.Context, now time.Time) ([]*Incident, error)
 
 	// Incident events
 	InsertIncidentEvent(ctx context.Context, e *IncidentEvent) error
@@ -64,26 +85,44 @@ type Store interface {
 	InsertContentChange(ctx context.Context, c *ContentChange) error
 	ListContentChanges(ctx context.Context, monitorID int64, p Pagination) (*PaginatedResult, error)
 
+	// Status transitions
+	InsertMonitorTransition(ctx context.Context, t *MonitorTransition) error
+	ListMonitorTransitions(ctx context.Context, monitorID int64, limit int) ([]*MonitorTransition, error)
+	PurgeOldTransitions(ctx context.Context, before time.Time) (int64, error)
+
 	// Heartbeats
 	CreateHeartbeat(ctx context.Context, h *Heartbeat) error
 	GetHeartbeatByToken(ctx context.Context, token string) (*Heartbeat, error)
 	GetHeartbeatByMonitorID(ctx context.Context, monitorID int64) (*Heartbeat, error)
 	UpdateHeartbeatPing(ctx context.Context, token string) error
+	PauseHeartbeat(ctx context.Context, token string) error
+	ResumeHeartbeat(ctx context.Context, token string) error
+	RestartHeartbeatDeadline(ctx context.Context, monitorID int64) error
 	ListExpiredHeartbeats(ctx context.Context) ([]*Heartbeat, error)
 	UpdateHeartbeatStatus(ctx context.Context, monitorID int64, status string) error
 	DeleteHeartbeat(ctx context.Context, monitorID int64) error
 
 	// Analytics
 	GetResponseTimeSeries(ctx context.Context, monitorID int64, from, to time.Time, maxPoints int) ([]*TimeSeriesPoint, error)
+	GetTimeSeries(ctx context.Context, monitorID int64, from, to time.Time, maxPoints int) ([]TimeSeriesPoint, error)
 	GetUptimePercent(ctx context.Context, monitorID int64, from, to time.Time) (float64, error)
 	GetResponseTimePercentiles(ctx context.Context, monitorID int64, from, to time.Time) (p50, p95, p99 float64, err error)
 	GetCheckCounts(ctx context.Context, monitorID int64, from, to time.Time) (total, up, down, degraded int64, err error)
+	GetApdex(ctx context.Context, monitorID int64, from, to time.Time, thresholdMs int64, failedAsFrustrated bool) (*ApdexReport, error)
+	GetSLAReport(ctx context.Context, monitorID int64, from, to time.Time) (*SLAReport, error)
 	CountMonitorsByStatus(ctx context.Context) (up, down, degraded, paused int64, err error)
 	GetLatestResponseTimes(ctx context.Context) (map[int64]int64, error)
+	RecomputeLatencyBaselines(ctx context.Context, monitorID int64, since time.Time) error
+	GetLatencyBaseline(ctx context.Context, monitorID int64, hour int) (*LatencyBaseline, error)
+	ListLatencyBaselines(ctx context.Context, monitorID int64) ([]*LatencyBaseline, error)
 
 	// Monitor notification routing
 	GetMonitorNotificationChannelIDs(ctx context.Context, monitorID int64) ([]int64, error)
 	SetMonitorNotificationChannels(ctx context.Context, monitorID int64, channelIDs []int64) error
+	GetMonitorNotificationFilters(ctx context.Context, monitorID int64) (map[int64]*NotificationFilter, error)
+	SetMonitorNotificationFilters(ctx context.Context, monitorID int64, filters map[int64]*NotificationFilter) error
+	SetChannelTags(ctx context.Context, channelID int64, tagIDs []int64) error
+	GetChannelsByTag(ctx context.Context, tagIDs []int64) ([]int64, error)
 
 	// Monitor groups
 	CreateMonitorGroup(ctx context.Context, g *MonitorGroup) error
@@ -91,6 +130,9 @@ type Store interface {
 	ListMonitorGroups(ctx context.Context) ([]*MonitorGroup, error)
 	UpdateMonitorGroup(ctx context.Context, g *MonitorGroup) error
 	DeleteMonitorGroup(ctx context.Context, id int64) error
+	GetGroupStatus(ctx context.Context, groupID int64) (*GroupStatus, error)
+	GetGroupUptime(ctx context.Context, groupID int64, from, to time.Time, mode string) (*GroupUptime, error)
+	GetGroupSLAReport(ctx context.Context, groupID int64, from, to time.Time) (*GroupSLAReport, error)
 
 	// Tags
 	CreateTag(ctx context.Context, t *Tag) error
@@ -130,6 +172,7 @@ type Store interface {
 
 	// Status pages
 	GetDailyUptime(ctx context.Context, monitorID int64, from, to time.Time) ([]*DailyUptime, error)
+	RollupDailyUptime(ctx context.Context, from, to time.Time) error
 	IsMonitorOnStatusPage(ctx context.Context, monitorID int64) (bool, error)
 	CreateStatusPage(ctx context.Context, sp *StatusPage) error
 	GetStatusPage(ctx context.Context, id int64) (*StatusPage, error)
@@ -140,21 +183,67 @@ type Store interface {
 	SetStatusPageMonitors(ctx context.Context, pageID int64, monitors []StatusPageMonitor) error
 	ListStatusPageMonitors(ctx context.Context, pageID int64) ([]StatusPageMonitor, error)
 	ListStatusPageMonitorsWithStatus(ctx context.Context, pageID int64) ([]*Monitor, []StatusPageMonitor, error)
+	SetStatusPageComponents(ctx context.Context, pageID int64, components []StatusPageComponent) error
+	ListStatusPageComponents(ctx context.Context, pageID int64) ([]StatusPageComponent, error)
+	CreateStatusPageSubscriber(ctx context.Context, sub *StatusPageSubscriber) error
+	GetStatusPageSubscriberByEmail(ctx context.Context, pageID int64, email string) (*StatusPageSubscriber, error)
+	GetStatusPageSubscriberByToken(ctx context.Context, token string) (*StatusPageSubscriber, error)
+	ConfirmStatusPageSubscriber(ctx context.Context, id int64) error
+	DeleteStatusPageSubscriber(ctx context.Context, id int64) error
+	ListStatusPageSubscribers(ctx context.Context, pageID int64) ([]*StatusPageSubscriber, error)
+	ListSubscribableStatusPages(ctx context.Context, monitorID int64) ([]*StatusPage, error)
 
 	// Proxies
 	CreateProxy(ctx context.Context, p *Proxy) error
 	GetProxy(ctx context.Context, id int64) (*Proxy, error)
 	ListProxies(ctx context.Context) ([]*Proxy, error)
 	UpdateProxy(ctx context.Context, p *Proxy) error
+	UpdateProxyHealth(ctx context.Context, p *Proxy) error
+	SetProxyAutoDisabled(ctx context.Context, id int64, disabled bool) (bool, error)
 	DeleteProxy(ctx context.Context, id int64) error
 
+	// Probes
+	CreateProbe(ctx context.Context, p *Probe) error
+	GetProbe(ctx context.Context, id int64) (*Probe, error)
+	ListProbes(ctx context.Context) ([]*Probe, error)
+	UpdateProbe(ctx context.Context, p *Probe) error
+	DeleteProbe(ctx context.Context, id int64) error
+	GetMonitorProbeIDs(ctx context.Context, monitorID int64) ([]int64, error)
+	SetMonitorProbes(ctx context.Context, monitorID int64, probeIDs []int64) error
+	ListMonitorProbes(ctx context.Context) (map[int64][]*Probe, error)
+	InsertProbeChecks(ctx context.Context, checks []*CheckResult) error
+	GetUptimeByProbe(ctx context.Context, monitorID int64, from, to time.Time) ([]*ProbeUptime, error)
+
+	// Escalation policies
+	CreateEscalationPolicy(ctx context.Context, ep *EscalationPolicy) error
+	GetEscalationPolicy(ctx context.Context, id int64) (*EscalationPolicy, error)
+	ListEscalationPolicies(ctx context.Context) ([]*EscalationPolicy, error)
+	UpdateEscalationPolicy(ctx context.Context, ep *EscalationPolicy) error
+	DeleteEscalationPolicy(ctx context.Context, id int64) error
+	GetMonitorEscalationPolicyID(ctx context.Context, monitorID int64) (*int64, error)
+	SetMonitorEscalationPolicy(ctx context.Context, monitorID int64, policyID *int64) error
+	ListPendingEscalations(ctx context.Context) ([]*PendingEscalation, error)
+
+	// On-call schedules
+	CreateOncallSchedule(ctx context.Context, sched *OncallSchedule) error
+	GetOncallSchedule(ctx context.Context, id int64) (*OncallSchedule, error)
+	ListOncallSchedules(ctx context.Context) ([]*OncallSchedule, error)
+	UpdateOncallSchedule(ctx context.Context, sched *OncallSchedule) error
+	DeleteOncallSchedule(ctx context.Context, id int64) error
+	CurrentResponder(ctx context.Context, scheduleID int64, at time.Time) (*OncallMember, error)
+
 	// Data retention
 	PurgeOldData(ctx context.Context, before time.Time) (int64, error)
 
 	// Database maintenance
 	Vacuum(ctx context.Context) error
 	DBSize() (int64, error)
+	Ping(ctx context.Context) error
 
 	// Lifecycle
 	Close() error
+
+	// Write buffering
+	DroppedWrites() int64
+	PendingWrites() int
 }
@@ -0,0 +1,28 @@
+//go:build !postgres
+
+package storage
+
+import (
+	"os"
+	"testing"
+)
+
+func testStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	tmpFile, err := os.CreateTemp("", "asura-test-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpFile.Close()
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	store, err := NewSQLiteStore(tmpFile.Name(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// sqliteOnly marks a test that exercises SQLite files or locking.
+func sqliteOnly(t *testing.T) {}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WriteRetry configures how InsertCheckResult and UpsertMonitorStatus ride
+// out a locked database. Zero values disable retries and buffering.
+type WriteRetry struct {
+	Attempts   int           // tries per write before buffering it
+	Backoff    time.Duration // delay after the first failed try, doubled each time
+	BufferSize int           // writes held in memory while the database stays locked
+}
+
+// writeBuffer holds writes that could not be applied because the database
+// was locked. They are replayed in order before the next guarded write.
+type writeBuffer struct {
+	mu      sync.Mutex
+	pending []func(context.Context) error
+	dropped atomic.Int64
+}
+
+// SetWriteRetry configures retries and buffering for hot-path writes. It
+// must be called before the store is used concurrently.
+func (s *SQLiteStore) SetWriteRetry(c WriteRetry) {
+	s.writeRetry = c
+}
+
+// DroppedWrites returns how many writes were lost because the database stayed
+// locked with a full buffer, or failed when replayed.
+func (s *SQLiteStore) DroppedWrites() int64 {
+	return s.writeBuf.dropped.Load()
+}
+
+// PendingWrites returns how many writes are waiting for the database.
+func (s *SQLiteStore) PendingWrites() int {
+	s.writeBuf.mu.Lock()
+	defer s.writeBuf.mu.Unlock()
+	return len(s.writeBuf.pending)
+}
+
+// FlushPendingWrites replays buffered writes. It stops at the first write
+// that still finds the database locked.
+func (s *SQLiteStore) FlushPendingWrites(ctx context.Context) error {
+	s.writeBuf.mu.Lock()
+	defer s.writeBuf.mu.Unlock()
+	return s.flushLocked(ctx)
+}
+
+// guardedWrite runs op, retrying while the database is locked. Buffered
+// writes are replayed first so writes land in order. If the database stays
+// locked, replay is buffered in place of op and guardedWrite returns nil.
+// replay must not share memory with the caller.
+func (s *SQLiteStore) guardedWrite(ctx context.Context, op, replay func(context.Context) error) error {
+	s.writeBuf.mu.Lock()
+	defer s.writeBuf.mu.Unlock()
+
+	err := s.flushLocked(ctx)
+	if err == nil {
+		err = s.retryBusy(ctx, op)
+	}
+	if !isBusy(err) || s.writeRetry.BufferSize <= 0 {
+		return err
+	}
+	if len(s.writeBuf.pending) >= s.writeRetry.BufferSize {
+		s.writeBuf.dropped.Add(1)
+		return fmt.Errorf("write buffer full, dropping write: %w", err)
+	}
+	s.writeBuf.pending = append(s.writeBuf.pending, replay)
+	return nil
+}
+
+func (s *SQLiteStore) flushLocked(ctx context.Context) error {
+	for len(s.writeBuf.pending) > 0 {
+		err := s.retryBusy(ctx, s.writeBuf.pending[0])
+		if isBusy(err) {
+			return err
+		}
+		if err != nil {
+			s.writeBuf.dropped.Add(1)
+		}
+		s.writeBuf.pending[0] = nil
+		s.writeBuf.pending = s.writeBuf.pending[1:]
+	}
+	return nil
+}
+
+func (s *SQLiteStore) retryBusy(ctx context.Context, op func(context.Context) error) error {
+	backoff := s.writeRetry.Backoff
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		if !isBusy(err) || attempt >= s.writeRetry.Attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isBusy reports whether err means another connection holds the write lock.
+func isBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "SQLITE_BUSY")
+}
@@ -1,15 +1,27 @@
 package validate
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/url"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/cronexpr"
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
 
+	"github.com/y0f/asura/internal/htmltext"
 	"github.com/y0f/asura/internal/incident"
+	"github.com/y0f/asura/internal/notifier"
 	"github.com/y0f/asura/internal/storage"
 )
 
@@ -17,7 +29,8 @@ var ValidMonitorTypes = map[string]bool{
 	"http": true, "tcp": true, "dns": true,
 	"icmp": true, "tls": true, "websocket": true, "command": true,
 	"heartbeat": true, "docker": true, "domain": true,
-	"grpc": true, "mqtt": true,
+	"grpc": true, "mqtt": true, "amqp": true, "s3": true, "smtp": true, "redis": true, "ssh": true, "ntp": true, "kafka": true,
+	"transaction": true,
 }
 
 var ValidIncidentStatuses = map[string]bool{
@@ -29,15 +42,22 @@ var _validNotificationTypes = map[string]bool{
 	"discord": true, "slack": true, "ntfy": true,
 	"teams": true, "pagerduty": true, "opsgenie": true, "pushover": true,
 	"googlechat": true, "matrix": true, "gotify": true,
+	"mattermost": true, "rocketchat": true, "twilio": true, "signal": true,
 }
 
 var _validNotificationEvents = map[string]bool{
-	"incident.created":      true,
-	"incident.acknowledged": true,
-	"incident.resolved":     true,
-	"incident.reminder":     true,
-	"content.changed":       true,
-	"cert.changed":          true,
+	"incident.created":        true,
+	"incident.acknowledged":   true,
+	"incident.resolved":       true,
+	"incident.reminder":       true,
+	"content.changed":         true,
+	"cert.changed":            true,
+	"monitor.latency_anomaly": true,
+	"monitor.down":            true,
+	"monitor.recovered":       true,
+	"check.completed":         true,
+	"proxy.disabled":          true,
+	"proxy.enabled":           true,
 }
 
 func ValidateMonitor(m *storage.Monitor) error {
@@ -50,8 +70,14 @@ func ValidateMonitor(m *storage.Monitor) error {
 	if len(m.Description) > 5000 {
 		return fmt.Errorf("description must be at most 5000 characters")
 	}
+	if len(m.Owner) > 255 {
+		return fmt.Errorf("owner must be at most 255 characters")
+	}
+	if m.ParentID != nil && m.ID != 0 && *m.ParentID == m.ID {
+		return fmt.Errorf("parent_id must not be the monitor itself")
+	}
 	if !ValidMonitorTypes[m.Type] {
-		return fmt.Errorf("type must be one of: http, tcp, dns, icmp, tls, websocket, command, heartbeat, docker, domain, grpc, mqtt")
+		return fmt.Errorf("type must be one of: http, tcp, dns, icmp, tls, websocket, command, heartbeat, docker, domain, grpc, mqtt, amqp, s3, smtp, redis, ssh, ntp, kafka, transaction")
 	}
 	if m.Type == "heartbeat" {
 		return nil
@@ -65,6 +91,26 @@ func ValidateMonitor(m *storage.Monitor) error {
 	return validateMonitorLimits(m)
 }
 
+// ValidateMonitorParent rejects a parent that would make monitor id depend on
+// itself. parents maps each monitor to its current parent; id is 0 for a new
+// monitor, which nothing can depend on yet.
+func ValidateMonitorParent(id int64, parentID *int64, parents map[int64]int64) error {
+	if parentID == nil || id == 0 {
+		return nil
+	}
+	seen := map[int64]bool{}
+	for cur, ok := *parentID, true; ok; cur, ok = parents[cur] {
+		if cur == id {
+			return fmt.Errorf("parent_id would create a dependency cycle")
+		}
+		if seen[cur] {
+			break
+		}
+		seen[cur] = true
+	}
+	return nil
+}
+
 func validateMonitorLimits(m *storage.Monitor) error {
 	if m.Interval < 5 {
 		return fmt.Errorf("interval must be at least 5 seconds")
@@ -78,18 +124,59 @@ func validateMonitorLimits(m *storage.Monitor) error {
 	if m.Timeout > 300 {
 		return fmt.Errorf("timeout must be at most 300 seconds")
 	}
+	if m.ConnectTimeout < 0 || m.ConnectTimeout > m.Timeout {
+		return fmt.Errorf("connect_timeout must be between 0 and timeout")
+	}
+	if m.MaxResponseTimeMs < 0 || m.MaxResponseTimeMs > m.Timeout*1000 {
+		return fmt.Errorf("max_response_time_ms must be between 0 and %d (the timeout)", m.Timeout*1000)
+	}
+	if m.WarmupSeconds < 0 || m.WarmupSeconds > 86400 {
+		return fmt.Errorf("warmup_seconds must be between 0 and 86400")
+	}
 	if m.FailureThreshold < 1 {
 		return fmt.Errorf("failure_threshold must be at least 1")
 	}
 	if m.SuccessThreshold < 1 {
 		return fmt.Errorf("success_threshold must be at least 1")
 	}
+	if m.DegradedThreshold < 0 || m.DegradedThreshold > 100 {
+		return fmt.Errorf("degraded_threshold must be between 0 and 100")
+	}
+	if m.FlapWindowSeconds < 0 || m.FlapWindowSeconds > 86400 {
+		return fmt.Errorf("flap_window_seconds must be between 0 and 86400")
+	}
 	if m.ResendInterval < 0 {
 		return fmt.Errorf("resend_interval must be non-negative")
 	}
 	if m.ResendInterval > 86400 {
 		return fmt.Errorf("resend_interval must be at most 86400 seconds")
 	}
+	if m.LatencyBaselineSigma < 0 || m.LatencyBaselineSigma > 10 {
+		return fmt.Errorf("latency_baseline_sigma must be between 0 and 10")
+	}
+	if m.StreamChecksEvery < 0 || m.StreamChecksEvery > 1000 {
+		return fmt.Errorf("stream_checks_every must be between 0 and 1000")
+	}
+	if m.Retries < 0 || m.Retries > 5 {
+		return fmt.Errorf("retries must be between 0 and 5")
+	}
+	if m.RetryInterval < 0 || m.RetryInterval > 60 {
+		return fmt.Errorf("retry_interval must be between 0 and 60 seconds")
+	}
+	if len(m.ProbeIDs) > maxMonitorProbes {
+		return fmt.Errorf("at most %d probes allowed", maxMonitorProbes)
+	}
+	if len(m.RedactPatterns) > maxRedactPatterns {
+		return fmt.Errorf("redact_patterns must have at most %d entries", maxRedactPatterns)
+	}
+	for i, pattern := range m.RedactPatterns {
+		if pattern == "" {
+			return fmt.Errorf("redact_patterns[%d] must not be empty", i)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("redact_patterns[%d]: %w", i, err)
+		}
+	}
 	return validateMonitorJSON(m)
 }
 
@@ -99,6 +186,9 @@ func validateMonitorJSON(m *storage.Monitor) error {
 		if err := json.Unmarshal(m.Settings, &s); err != nil {
 			return fmt.Errorf("settings must be a valid JSON object")
 		}
+		if _, ok := s["validate_dnssec"]; ok && m.Type != "dns" {
+			return fmt.Errorf("settings.validate_dnssec is only supported by dns monitors")
+		}
 	}
 	if len(m.Assertions) > 0 && string(m.Assertions) != "[]" {
 		var a []any
@@ -109,6 +199,525 @@ func validateMonitorJSON(m *storage.Monitor) error {
 	if m.Type == "docker" {
 		return validateDockerSettings(m)
 	}
+	if m.Type == "grpc" {
+		return validateGRPCSettings(m)
+	}
+	if m.Type == "http" {
+		return validateHTTPSettings(m)
+	}
+	if m.Type == "tcp" {
+		return validateTCPSettings(m)
+	}
+	if m.Type == "dns" {
+		return validateDNSSettings(m)
+	}
+	if m.Type == "amqp" {
+		return validateAMQPSettings(m)
+	}
+	if m.Type == "s3" {
+		return validateS3Settings(m)
+	}
+	if m.Type == "smtp" {
+		return validateSMTPSettings(m)
+	}
+	if m.Type == "redis" {
+		return validateRedisSettings(m)
+	}
+	if m.Type == "ssh" {
+		return validateSSHSettings(m)
+	}
+	if m.Type == "ntp" {
+		return validateNTPSettings(m)
+	}
+	if m.Type == "icmp" {
+		return validateICMPSettings(m)
+	}
+	if m.Type == "kafka" {
+		return validateKafkaSettings(m)
+	}
+	if m.Type == "tls" {
+		return validateTLSSettings(m)
+	}
+	if m.Type == "mqtt" {
+		return validateMQTTSettings(m)
+	}
+	if m.Type == "websocket" {
+		return validateWebSocketSettings(m)
+	}
+	if m.Type == "transaction" {
+		return validateTransactionSettings(m)
+	}
+	return nil
+}
+
+// maxRangeBytes matches the checker's body read limit.
+const maxRangeBytes = 1 << 20
+
+const maxIgnorePatterns = 20
+
+const maxRedactPatterns = 20
+
+func validateHTTPSettings(m *storage.Monitor) error {
+	var hs storage.HTTPSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &hs); err != nil {
+			return fmt.Errorf("invalid http settings: %w", err)
+		}
+	}
+	if _, err := hs.ExpectedStatus.Ranges(); err != nil {
+		return fmt.Errorf("settings.expected_status: %w", err)
+	}
+	if hs.RangeBytes < 0 || hs.RangeBytes > maxRangeBytes {
+		return fmt.Errorf("settings.range_bytes must be between 0 and %d", maxRangeBytes)
+	}
+	if hs.MaxTTFBMs < 0 {
+		return fmt.Errorf("settings.max_ttfb_ms must not be negative")
+	}
+	if hs.BodyEncoding == "multipart" {
+		if _, err := hs.MultipartFields(); err != nil {
+			return fmt.Errorf("settings.body: %w", err)
+		}
+	}
+	if f := hs.MultipartFile; f != nil {
+		if hs.BodyEncoding != "multipart" {
+			return fmt.Errorf("settings.multipart_file requires body_encoding multipart")
+		}
+		if strings.TrimSpace(f.Field) == "" || strings.TrimSpace(f.Filename) == "" {
+			return fmt.Errorf("settings.multipart_file requires field and filename")
+		}
+	}
+	if c := hs.Content; c != nil {
+		if c.Selector != "" {
+			if _, err := htmltext.Compile(c.Selector); err != nil {
+				return fmt.Errorf("settings.content.selector: %w", err)
+			}
+		}
+		if len(c.IgnorePatterns) > maxIgnorePatterns {
+			return fmt.Errorf("settings.content.ignore_patterns must have at most %d entries", maxIgnorePatterns)
+		}
+		for i, pattern := range c.IgnorePatterns {
+			if pattern == "" {
+				return fmt.Errorf("settings.content.ignore_patterns[%d] must not be empty", i)
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("settings.content.ignore_patterns[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateTCPSettings(m *storage.Monitor) error {
+	var ts storage.TCPSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ts); err != nil {
+			return fmt.Errorf("invalid tcp settings: %w", err)
+		}
+	}
+	switch ts.Mode {
+	case "", "connect", "banner":
+	default:
+		return fmt.Errorf("settings.mode must be connect or banner")
+	}
+	if ts.Mode == "connect" && (ts.SendData != "" || ts.ExpectData != "" || ts.ExpectRegex != "") {
+		return fmt.Errorf("settings.mode connect cannot be combined with send_data, expect_data or expect_regex")
+	}
+	if ts.ExpectRegex != "" {
+		if _, err := regexp.Compile(ts.ExpectRegex); err != nil {
+			return fmt.Errorf("settings.expect_regex: %w", err)
+		}
+	}
+	if ts.BannerTimeoutMs < 0 {
+		return fmt.Errorf("settings.banner_timeout_ms must not be negative")
+	}
+	if m.Timeout > 0 && ts.BannerTimeoutMs > m.Timeout*1000 {
+		return fmt.Errorf("settings.banner_timeout_ms must not exceed the monitor timeout")
+	}
+	return nil
+}
+
+func validateDNSSettings(m *storage.Monitor) error {
+	var ds storage.DNSSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ds); err != nil {
+			return fmt.Errorf("invalid dns settings: %w", err)
+		}
+	}
+	if ds.DoHURL != "" {
+		u, err := url.Parse(ds.DoHURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("settings.doh_url must be an https URL")
+		}
+		if ds.Server != "" {
+			return fmt.Errorf("settings.server and settings.doh_url are mutually exclusive")
+		}
+	}
+	switch ds.MatchMode {
+	case "", "any", "all", "exact":
+	default:
+		return fmt.Errorf("settings.match_mode must be any, all or exact")
+	}
+	if ds.MatchMode != "" && len(ds.ExpectedValues) == 0 {
+		return fmt.Errorf("settings.match_mode requires expected_values")
+	}
+	for i, v := range ds.ExpectedValues {
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("settings.expected_values[%d] must not be empty", i)
+		}
+	}
+	return nil
+}
+
+func validateAMQPSettings(m *storage.Monitor) error {
+	u, err := url.Parse(m.Target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("target must be the RabbitMQ management API URL (http or https)")
+	}
+	var as storage.AMQPSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &as); err != nil {
+			return fmt.Errorf("invalid amqp settings: %w", err)
+		}
+	}
+	if strings.TrimSpace(as.Queue) == "" {
+		return fmt.Errorf("settings.queue is required")
+	}
+	if as.WarnDepth < 0 || as.CritDepth < 0 {
+		return fmt.Errorf("settings.warn_depth and settings.crit_depth must not be negative")
+	}
+	if as.WarnDepth > 0 && as.CritDepth > 0 && as.WarnDepth >= as.CritDepth {
+		return fmt.Errorf("settings.warn_depth must be below settings.crit_depth")
+	}
+	return nil
+}
+
+func validateS3Settings(m *storage.Monitor) error {
+	u, err := url.Parse(m.Target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("target must be the S3 endpoint URL (http or https)")
+	}
+	var ss storage.S3Settings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ss); err != nil {
+			return fmt.Errorf("invalid s3 settings: %w", err)
+		}
+	}
+	if strings.TrimSpace(ss.Bucket) == "" {
+		return fmt.Errorf("settings.bucket is required")
+	}
+	if strings.ContainsAny(ss.Bucket, "/ ") {
+		return fmt.Errorf("settings.bucket must not contain slashes or spaces")
+	}
+	if strings.TrimSpace(ss.Key) == "" {
+		return fmt.Errorf("settings.key is required")
+	}
+	if (ss.AccessKeyID == "") != (ss.SecretAccessKey == "") {
+		return fmt.Errorf("settings.access_key_id and settings.secret_access_key must be set together")
+	}
+	if ss.MaxAgeSeconds < 0 {
+		return fmt.Errorf("settings.max_age_seconds must not be negative")
+	}
+	return nil
+}
+
+func validateSMTPSettings(m *storage.Monitor) error {
+	var ss storage.SMTPSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ss); err != nil {
+			return fmt.Errorf("invalid smtp settings: %w", err)
+		}
+	}
+	if ss.Password != "" && ss.Username == "" {
+		return fmt.Errorf("settings.password requires settings.username")
+	}
+	// AUTH PLAIN sends the password in the clear, so it needs STARTTLS.
+	if ss.Username != "" && !ss.StartTLS {
+		return fmt.Errorf("settings.username requires settings.starttls")
+	}
+	if ss.SendTestMail {
+		if !strings.Contains(ss.MailFrom, "@") || !strings.Contains(ss.MailTo, "@") {
+			return fmt.Errorf("settings.send_test_mail requires mail_from and mail_to addresses")
+		}
+	}
+	if strings.ContainsAny(ss.MailFrom+ss.MailTo, "\r\n") {
+		return fmt.Errorf("settings.mail_from and settings.mail_to must not contain line breaks")
+	}
+	return nil
+}
+
+// _redisReadCommands are the commands a redis monitor may run. Monitors must
+// not be able to change data.
+var _redisReadCommands = map[string]bool{
+	"PING": true, "ECHO": true, "INFO": true, "DBSIZE": true, "TIME": true, "ROLE": true,
+	"GET": true, "EXISTS": true, "TTL": true, "TYPE": true, "STRLEN": true,
+	"LLEN": true, "SCARD": true, "ZCARD": true, "HLEN": true, "XLEN": true,
+}
+
+func validateRedisSettings(m *storage.Monitor) error {
+	var rs storage.RedisSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &rs); err != nil {
+			return fmt.Errorf("invalid redis settings: %w", err)
+		}
+	}
+	if rs.DB < 0 || rs.DB > 15 {
+		return fmt.Errorf("settings.db must be between 0 and 15")
+	}
+	if rs.Username != "" && rs.Password == "" {
+		return fmt.Errorf("settings.username requires settings.password")
+	}
+	if args := strings.Fields(rs.Command); len(args) > 0 && !_redisReadCommands[strings.ToUpper(args[0])] {
+		return fmt.Errorf("settings.command must be a read-only command such as PING, INFO, DBSIZE or GET")
+	}
+	return nil
+}
+
+// _sshFingerprintPattern matches an OpenSSH SHA256 fingerprint, with or
+// without the SHA256: prefix.
+var _sshFingerprintPattern = regexp.MustCompile(`^(SHA256:)?[A-Za-z0-9+/]{43}=?$`)
+
+func validateSSHSettings(m *storage.Monitor) error {
+	var ss storage.SSHSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ss); err != nil {
+			return fmt.Errorf("invalid ssh settings: %w", err)
+		}
+	}
+	if ss.ExpectedFingerprint != "" {
+		if ss.BannerOnly {
+			return fmt.Errorf("settings.expected_fingerprint requires the key exchange, unset banner_only")
+		}
+		if !_sshFingerprintPattern.MatchString(strings.TrimSpace(ss.ExpectedFingerprint)) {
+			return fmt.Errorf("settings.expected_fingerprint must be a SHA256 fingerprint as printed by ssh-keygen -l")
+		}
+	}
+	return nil
+}
+
+func validateNTPSettings(m *storage.Monitor) error {
+	var ns storage.NTPSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ns); err != nil {
+			return fmt.Errorf("invalid ntp settings: %w", err)
+		}
+	}
+	if ns.MaxOffsetMs < 0 || ns.CritOffsetMs < 0 {
+		return fmt.Errorf("settings.max_offset_ms and settings.crit_offset_ms must not be negative")
+	}
+	if ns.MaxOffsetMs > 0 && ns.CritOffsetMs > 0 && ns.MaxOffsetMs >= ns.CritOffsetMs {
+		return fmt.Errorf("settings.max_offset_ms must be below settings.crit_offset_ms")
+	}
+	if ns.MaxStratum < 0 || ns.MaxStratum > 15 {
+		return fmt.Errorf("settings.max_stratum must be between 0 and 15")
+	}
+	return nil
+}
+
+func validateTLSSettings(m *storage.Monitor) error {
+	var ts storage.TLSSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ts); err != nil {
+			return fmt.Errorf("invalid tls settings: %w", err)
+		}
+	}
+	if len(ts.ExpectedIssuer) > 1024 {
+		return fmt.Errorf("settings.expected_issuer must be at most 1024 characters")
+	}
+	if len(ts.ExpectedCN) > 255 {
+		return fmt.Errorf("settings.expected_cn must be at most 255 characters")
+	}
+	return nil
+}
+
+// maxTransactionSteps bounds the requests a transaction check sends.
+const maxTransactionSteps = 20
+
+var transactionVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validateTransactionSettings(m *storage.Monitor) error {
+	u, err := url.Parse(m.Target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("target must be the base URL of the transaction (http or https)")
+	}
+	var ts storage.TransactionSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ts); err != nil {
+			return fmt.Errorf("invalid transaction settings: %w", err)
+		}
+	}
+	if len(ts.Steps) == 0 {
+		return fmt.Errorf("settings.steps must have at least one step")
+	}
+	if len(ts.Steps) > maxTransactionSteps {
+		return fmt.Errorf("settings.steps must have at most %d entries", maxTransactionSteps)
+	}
+	for i, step := range ts.Steps {
+		if strings.TrimSpace(step.URL) == "" {
+			return fmt.Errorf("settings.steps[%d].url is required", i)
+		}
+		if su, err := url.Parse(step.URL); err != nil {
+			return fmt.Errorf("settings.steps[%d].url: %w", i, err)
+		} else if su.IsAbs() && su.Scheme != "http" && su.Scheme != "https" {
+			return fmt.Errorf("settings.steps[%d].url must be http or https", i)
+		}
+		if _, err := step.ExpectedStatus.Ranges(); err != nil {
+			return fmt.Errorf("settings.steps[%d].expected_status: %w", i, err)
+		}
+		if len(step.Assertions) > 0 {
+			var cs map[string]any
+			if err := json.Unmarshal(step.Assertions, &cs); err != nil {
+				return fmt.Errorf("settings.steps[%d].assertions must be a JSON object", i)
+			}
+		}
+		for j, ex := range step.Extract {
+			if !transactionVarName.MatchString(ex.Var) {
+				return fmt.Errorf("settings.steps[%d].extract[%d].var must be a name of letters, digits and underscores", i, j)
+			}
+			switch ex.Source {
+			case "", storage.ExtractJSON, storage.ExtractHeader:
+			default:
+				return fmt.Errorf("settings.steps[%d].extract[%d].source must be json or header", i, j)
+			}
+			if strings.TrimSpace(ex.Path) == "" {
+				return fmt.Errorf("settings.steps[%d].extract[%d].path is required", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// maxWebSocketSteps bounds the script a websocket check runs.
+const maxWebSocketSteps = 20
+
+func validateWebSocketSettings(m *storage.Monitor) error {
+	var ws storage.WebSocketSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ws); err != nil {
+			return fmt.Errorf("invalid websocket settings: %w", err)
+		}
+	}
+	if len(ws.Steps) > maxWebSocketSteps {
+		return fmt.Errorf("settings.steps must have at most %d entries", maxWebSocketSteps)
+	}
+	for i, step := range ws.Steps {
+		if step.Send == "" && step.Expect == "" {
+			return fmt.Errorf("settings.steps[%d]: send or expect is required", i)
+		}
+		switch step.Match {
+		case "", storage.WSMatchContains:
+		case storage.WSMatchRegex:
+			if _, err := regexp.Compile(step.Expect); err != nil {
+				return fmt.Errorf("settings.steps[%d].expect: %w", i, err)
+			}
+		case storage.WSMatchJSON:
+			if !json.Valid([]byte(step.Expect)) {
+				return fmt.Errorf("settings.steps[%d].expect must be valid JSON", i)
+			}
+		default:
+			return fmt.Errorf("settings.steps[%d].match must be contains, regex or json", i)
+		}
+	}
+	return nil
+}
+
+func validateMQTTSettings(m *storage.Monitor) error {
+	var ms storage.MQTTSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ms); err != nil {
+			return fmt.Errorf("invalid mqtt settings: %w", err)
+		}
+	}
+	if ms.QoS < 0 || ms.QoS > 2 {
+		return fmt.Errorf("settings.qos must be 0, 1 or 2")
+	}
+	if ms.ExpectRegex != "" {
+		if _, err := regexp.Compile(ms.ExpectRegex); err != nil {
+			return fmt.Errorf("settings.expect_regex: %w", err)
+		}
+	}
+	if ms.Topic == "" && (ms.ExpectMessage != "" || ms.ExpectRegex != "" || ms.WaitRetained) {
+		return fmt.Errorf("settings.topic is required with expect_message, expect_regex or wait_retained")
+	}
+	return nil
+}
+
+func validateKafkaSettings(m *storage.Monitor) error {
+	var ks storage.KafkaSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &ks); err != nil {
+			return fmt.Errorf("invalid kafka settings: %w", err)
+		}
+	}
+	if ks.MinBrokers < 0 {
+		return fmt.Errorf("settings.min_brokers must not be negative")
+	}
+	if ks.SASLUser != "" && ks.SASLPass == "" {
+		return fmt.Errorf("settings.sasl_password is required with settings.sasl_username")
+	}
+	if ks.SASLUser == "" && ks.SASLPass != "" {
+		return fmt.Errorf("settings.sasl_username is required with settings.sasl_password")
+	}
+	return nil
+}
+
+func validateICMPSettings(m *storage.Monitor) error {
+	var is storage.ICMPSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &is); err != nil {
+			return fmt.Errorf("invalid icmp settings: %w", err)
+		}
+	}
+	if is.Count < 0 || is.Count > 20 {
+		return fmt.Errorf("settings.count must be between 0 and 20")
+	}
+	if is.IntervalMs != 0 && (is.IntervalMs < 100 || is.IntervalMs > 10000) {
+		return fmt.Errorf("settings.interval_ms must be between 100 and 10000")
+	}
+	if is.MaxLossPercent < 0 || is.MaxLossPercent > 100 {
+		return fmt.Errorf("settings.max_loss_percent must be between 0 and 100")
+	}
+	count := is.Count
+	if count == 0 {
+		count = 3
+	}
+	if is.IntervalMs > 0 && (count-1)*is.IntervalMs >= m.Timeout*1000 {
+		return fmt.Errorf("settings.count pings at settings.interval_ms must fit within the timeout")
+	}
+	return nil
+}
+
+func validateGRPCSettings(m *storage.Monitor) error {
+	var gs storage.GRPCSettings
+	if len(m.Settings) > 0 {
+		if err := json.Unmarshal(m.Settings, &gs); err != nil {
+			return fmt.Errorf("invalid grpc settings: %w", err)
+		}
+	}
+	switch gs.Mode {
+	case "", "health", "stream":
+	default:
+		return fmt.Errorf("settings.mode must be health or stream")
+	}
+	if gs.Method != "" {
+		parts := strings.Split(strings.TrimPrefix(gs.Method, "/"), "/")
+		if !strings.HasPrefix(gs.Method, "/") || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("settings.method must look like /package.Service/Method")
+		}
+	}
+	if gs.RequestBase64 != "" {
+		if _, err := base64.StdEncoding.DecodeString(gs.RequestBase64); err != nil {
+			return fmt.Errorf("settings.request_base64 must be valid base64")
+		}
+	}
+	if len(gs.Request) > 0 && string(gs.Request) != "null" {
+		var obj map[string]any
+		if err := json.Unmarshal(gs.Request, &obj); err != nil {
+			return fmt.Errorf("settings.request must be a JSON object")
+		}
+		if gs.Mode != "stream" && gs.Method == "" {
+			return fmt.Errorf("settings.request requires settings.method")
+		}
+	}
 	return nil
 }
 
@@ -145,15 +754,203 @@ func ValidateNotificationChannel(ch *storage.NotificationChannel) error {
 		return fmt.Errorf("name must be at most 255 characters")
 	}
 	if !_validNotificationTypes[ch.Type] {
-		return fmt.Errorf("type must be one of: webhook, email, telegram, discord, slack, ntfy, teams, pagerduty, opsgenie, pushover, googlechat, matrix, gotify")
+		return fmt.Errorf("type must be one of: webhook, email, telegram, discord, slack, ntfy, teams, pagerduty, opsgenie, pushover, googlechat, matrix, gotify, mattermost, rocketchat, twilio, signal")
 	}
 	if len(ch.Settings) == 0 {
 		return fmt.Errorf("settings is required")
 	}
+	if err := notifier.CheckSettingsEnv(ch.Settings); err != nil {
+		return err
+	}
 	for _, ev := range ch.Events {
 		if !_validNotificationEvents[ev] {
 			return fmt.Errorf("invalid event: %s", ev)
 		}
+		if ev == "check.completed" && ch.Type != "webhook" {
+			return fmt.Errorf("event check.completed is only supported by webhook channels")
+		}
+	}
+	for _, id := range ch.TagIDs {
+		if id <= 0 {
+			return fmt.Errorf("tag_ids must be positive")
+		}
+	}
+	if _, err := notifier.ParseSchedule(ch.Schedule); err != nil {
+		return err
+	}
+	if ch.OncallScheduleID != nil && !notifier.SupportsOncall(ch.Type) {
+		return fmt.Errorf("oncall_schedule_id is only supported by email, pagerduty and opsgenie channels")
+	}
+	if ch.DigestWindowSeconds != 0 && (ch.DigestWindowSeconds < minDigestWindowSeconds || ch.DigestWindowSeconds > maxDigestWindowSeconds) {
+		return fmt.Errorf("digest_window_seconds must be 0 or between %d and %d", minDigestWindowSeconds, maxDigestWindowSeconds)
+	}
+	if ch.DigestWindowSeconds != 0 && !notifier.SupportsDigest(ch.Type) {
+		return fmt.Errorf("digest_window_seconds is not supported by pagerduty and opsgenie channels")
+	}
+	if ch.DigestFlushOnResolve && ch.DigestWindowSeconds == 0 {
+		return fmt.Errorf("digest_flush_on_resolve requires digest_window_seconds")
+	}
+	switch ch.Type {
+	case "webhook":
+		if err := validateWebhookSettings(ch); err != nil {
+			return err
+		}
+	case "email":
+		if err := validateEmailSettings(ch); err != nil {
+			return err
+		}
+	case "twilio":
+		if err := validateTwilioSettings(ch); err != nil {
+			return err
+		}
+	case "signal":
+		if err := validateSignalSettings(ch); err != nil {
+			return err
+		}
+	}
+	return validateSeverityPriorities(ch)
+}
+
+// Digest windows shorter than this would barely batch anything; longer ones
+// would hold pages back for too long.
+const (
+	minDigestWindowSeconds = 10
+	maxDigestWindowSeconds = 3600
+)
+
+const maxWebhookTemplateLen = 64 << 10
+
+func validateWebhookSettings(ch *storage.NotificationChannel) error {
+	var s notifier.WebhookSettings
+	if err := json.Unmarshal(ch.Settings, &s); err != nil {
+		return fmt.Errorf("invalid webhook settings: %w", err)
+	}
+	if s.BodyTemplate == "" {
+		if s.ContentType != "" {
+			return fmt.Errorf("content_type is only used with body_template")
+		}
+		return nil
+	}
+	if len(s.BodyTemplate) > maxWebhookTemplateLen {
+		return fmt.Errorf("body_template must be at most %d bytes", maxWebhookTemplateLen)
+	}
+	if _, err := notifier.ParseWebhookTemplate(s.BodyTemplate); err != nil {
+		return fmt.Errorf("body_template: %w", err)
+	}
+	if s.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(s.ContentType); err != nil {
+			return fmt.Errorf("content_type must be a valid media type")
+		}
+	}
+	return nil
+}
+
+const maxEmailTemplateLen = 64 << 10
+
+func validateEmailSettings(ch *storage.NotificationChannel) error {
+	var s notifier.EmailSettings
+	if err := json.Unmarshal(ch.Settings, &s); err != nil {
+		return fmt.Errorf("invalid email settings: %w", err)
+	}
+	if s.ReplyTo != "" {
+		if _, err := mail.ParseAddressList(s.ReplyTo); err != nil {
+			return fmt.Errorf("reply_to must be a valid email address")
+		}
+	}
+	if len(s.SubjectTemplate) > 1024 {
+		return fmt.Errorf("subject_template must be at most 1024 bytes")
+	}
+	if _, err := notifier.ParseEmailSubjectTemplate(s.SubjectTemplate); err != nil {
+		return fmt.Errorf("subject_template: %w", err)
+	}
+	if len(s.HTMLTemplate) > maxEmailTemplateLen {
+		return fmt.Errorf("html_template must be at most %d bytes", maxEmailTemplateLen)
+	}
+	if _, err := notifier.ParseEmailHTMLTemplate(s.HTMLTemplate); err != nil {
+		return fmt.Errorf("html_template: %w", err)
+	}
+	return nil
+}
+
+// _phoneNumberPattern matches an E.164 phone number.
+var _phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+func validateTwilioSettings(ch *storage.NotificationChannel) error {
+	var s notifier.TwilioSettings
+	if err := json.Unmarshal(ch.Settings, &s); err != nil {
+		return fmt.Errorf("invalid twilio settings: %w", err)
+	}
+	if s.AccountSID == "" || s.AuthToken == "" {
+		return fmt.Errorf("twilio account_sid and auth_token are required")
+	}
+	if !_phoneNumberPattern.MatchString(s.FromNumber) {
+		return fmt.Errorf("from_number must be a phone number in E.164 format (e.g. +15550100)")
+	}
+	if len(s.ToNumbers) == 0 {
+		return fmt.Errorf("to_numbers is required")
+	}
+	if len(s.ToNumbers) > 20 {
+		return fmt.Errorf("at most 20 to_numbers allowed")
+	}
+	for _, n := range s.ToNumbers {
+		if !_phoneNumberPattern.MatchString(n) {
+			return fmt.Errorf("to_numbers: %q is not a phone number in E.164 format", n)
+		}
+	}
+	return nil
+}
+
+func validateSignalSettings(ch *storage.NotificationChannel) error {
+	var s notifier.SignalSettings
+	if err := json.Unmarshal(ch.Settings, &s); err != nil {
+		return fmt.Errorf("invalid signal settings: %w", err)
+	}
+	// A URL read from the environment is only known at send time.
+	if !notifier.HasEnvRef(s.APIURL) {
+		u, err := url.Parse(s.APIURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("api_url must be an http or https URL")
+		}
+	}
+	if !_phoneNumberPattern.MatchString(s.Number) {
+		return fmt.Errorf("number must be a phone number in E.164 format (e.g. +15550100)")
+	}
+	if len(s.Recipients) == 0 {
+		return fmt.Errorf("recipients is required")
+	}
+	if len(s.Recipients) > 20 {
+		return fmt.Errorf("at most 20 recipients allowed")
+	}
+	return nil
+}
+
+// _priorityRanges holds the valid priority range of each push channel type
+// that supports severity_priorities.
+var _priorityRanges = map[string][2]int{
+	"ntfy":   {1, 5},
+	"gotify": {0, 10},
+}
+
+func validateSeverityPriorities(ch *storage.NotificationChannel) error {
+	bounds, ok := _priorityRanges[ch.Type]
+	if !ok {
+		return nil
+	}
+	var s struct {
+		SeverityPriorities map[string]int `json:"severity_priorities"`
+	}
+	if err := json.Unmarshal(ch.Settings, &s); err != nil {
+		return fmt.Errorf("invalid %s settings: %w", ch.Type, err)
+	}
+	for sev, prio := range s.SeverityPriorities {
+		switch sev {
+		case notifier.SeverityCritical, notifier.SeverityWarning, notifier.SeverityInfo:
+		default:
+			return fmt.Errorf("severity_priorities: unknown severity %q (use critical, warning or info)", sev)
+		}
+		if prio < bounds[0] || prio > bounds[1] {
+			return fmt.Errorf("severity_priorities.%s must be between %d and %d", sev, bounds[0], bounds[1])
+		}
 	}
 	return nil
 }
@@ -188,6 +985,33 @@ func ValidateMonitorTags(tags []storage.MonitorTag) error {
 	return nil
 }
 
+// ValidateNotificationFilters checks a monitor's per-channel notification
+// filters against the channels it is linked to.
+func ValidateNotificationFilters(filters map[int64]*storage.NotificationFilter, channelIDs []int64) error {
+	for cid, f := range filters {
+		if !slices.Contains(channelIDs, cid) {
+			return fmt.Errorf("notification_filters: channel %d is not in notification_channel_ids", cid)
+		}
+		if f == nil {
+			continue
+		}
+		switch f.MinSeverity {
+		case "", incident.SeverityWarning, incident.SeverityCritical:
+		default:
+			return fmt.Errorf("notification_filters[%d].min_severity must be warning or critical", cid)
+		}
+		if len(f.Tags) > 20 {
+			return fmt.Errorf("notification_filters[%d].tags must have at most 20 entries", cid)
+		}
+		for i, tag := range f.Tags {
+			if strings.TrimSpace(tag) == "" {
+				return fmt.Errorf("notification_filters[%d].tags[%d] must not be empty", cid, i)
+			}
+		}
+	}
+	return nil
+}
+
 func ValidateMonitorGroup(g *storage.MonitorGroup) error {
 	if strings.TrimSpace(g.Name) == "" {
 		return fmt.Errorf("name is required")
@@ -227,9 +1051,66 @@ func ValidateStatusPage(sp *storage.StatusPage) error {
 		return fmt.Errorf("analytics_script must be at most 5000 characters")
 	}
 	sp.AnalyticsScript = sanitizeAnalyticsScript(sp.AnalyticsScript)
+	if len(sp.AnnouncementHTML) > 5000 {
+		return fmt.Errorf("announcement_html must be at most 5000 characters")
+	}
+	sp.AnnouncementHTML = sanitizeHTML(sp.AnnouncementHTML)
+	if sp.AnnouncementStart != nil && sp.AnnouncementEnd != nil && !sp.AnnouncementEnd.After(*sp.AnnouncementStart) {
+		return fmt.Errorf("announcement_end must be after announcement_start")
+	}
+	if sp.APITokenHash != "" && !isSHA256Hex(sp.APITokenHash) {
+		return fmt.Errorf("api_token_hash must be a hex SHA-256 digest")
+	}
 	return nil
 }
 
+func isSHA256Hex(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == sha256.Size
+}
+
+func ValidateStatusPageComponents(components []storage.StatusPageComponent) error {
+	for i := range components {
+		c := &components[i]
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name == "" {
+			return fmt.Errorf("components[%d].name is required", i)
+		}
+		if len(c.Name) > 200 {
+			return fmt.Errorf("components[%d].name must be at most 200 characters", i)
+		}
+		if len(c.Description) > 1000 {
+			return fmt.Errorf("components[%d].description must be at most 1000 characters", i)
+		}
+		for j, m := range c.Monitors {
+			if m.MonitorID <= 0 {
+				return fmt.Errorf("components[%d].monitors[%d].monitor_id is required", i, j)
+			}
+			if m.Weight < 0 || m.Weight > 100 {
+				return fmt.Errorf("components[%d].monitors[%d].weight must be between 0 and 100", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateSubscriberEmail checks an address submitted to a status page
+// subscribe form and returns it trimmed and lowercased.
+func ValidateSubscriberEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", fmt.Errorf("email is required")
+	}
+	if len(email) > 254 {
+		return "", fmt.Errorf("email must be at most 254 characters")
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", fmt.Errorf("email is not a valid address")
+	}
+	return email, nil
+}
+
 func ValidateProxy(p *storage.Proxy) error {
 	if strings.TrimSpace(p.Name) == "" {
 		return fmt.Errorf("name is required")
@@ -252,21 +1133,238 @@ func ValidateProxy(p *storage.Proxy) error {
 	return nil
 }
 
+// maxMonitorProbes bounds how many probes one monitor is checked from.
+const maxMonitorProbes = 20
+
+func ValidateProbe(p *storage.Probe) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(p.Name) > 255 {
+		return fmt.Errorf("name must be at most 255 characters")
+	}
+	switch p.Type {
+	case "local":
+		if p.URL != "" {
+			return fmt.Errorf("url is only used by agent probes")
+		}
+		if p.SourceAddr != "" && net.ParseIP(p.SourceAddr) == nil {
+			return fmt.Errorf("source_addr must be an IP address")
+		}
+	case "agent":
+		if p.SourceAddr != "" {
+			return fmt.Errorf("source_addr is only used by local probes")
+		}
+		u, err := url.Parse(p.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url must be an http or https URL")
+		}
+		if len(p.URL) > 2048 {
+			return fmt.Errorf("url must be at most 2048 characters")
+		}
+	default:
+		return fmt.Errorf("type must be local or agent")
+	}
+	if len(p.Token) > 255 {
+		return fmt.Errorf("token must be at most 255 characters")
+	}
+	return nil
+}
+
+// maxEscalationSteps bounds how many tiers a policy may have.
+const maxEscalationSteps = 10
+
+func ValidateEscalationPolicy(ep *storage.EscalationPolicy) error {
+	if strings.TrimSpace(ep.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(ep.Name) > 255 {
+		return fmt.Errorf("name must be at most 255 characters")
+	}
+	if len(ep.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+	if len(ep.Steps) > maxEscalationSteps {
+		return fmt.Errorf("at most %d steps are allowed", maxEscalationSteps)
+	}
+	prev := 0
+	for i, step := range ep.Steps {
+		if step.DelayMinutes < 1 || step.DelayMinutes > 10080 {
+			return fmt.Errorf("steps[%d].delay_minutes must be between 1 and 10080", i)
+		}
+		if step.DelayMinutes <= prev {
+			return fmt.Errorf("steps[%d].delay_minutes must be greater than the previous step", i)
+		}
+		prev = step.DelayMinutes
+		if len(step.ChannelIDs) == 0 {
+			return fmt.Errorf("steps[%d].channel_ids must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// Bounds for on-call schedules.
+const (
+	maxOncallMembers    = 50
+	maxOncallOverrides  = 100
+	maxOncallShiftHours = 24 * 28
+)
+
+func ValidateOncallSchedule(sched *storage.OncallSchedule) error {
+	if strings.TrimSpace(sched.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(sched.Name) > 255 {
+		return fmt.Errorf("name must be at most 255 characters")
+	}
+	if sched.Timezone != "" {
+		if _, err := time.LoadLocation(sched.Timezone); err != nil {
+			return fmt.Errorf("timezone: unknown time zone %q", sched.Timezone)
+		}
+	}
+	if _, err := time.Parse(storage.OncallRotationLayout, sched.RotationStart); err != nil {
+		return fmt.Errorf("rotation_start must be a local date and time like 2025-01-06T09:00")
+	}
+	if sched.ShiftHours < 1 || sched.ShiftHours > maxOncallShiftHours {
+		return fmt.Errorf("shift_hours must be between 1 and %d", maxOncallShiftHours)
+	}
+	if len(sched.Members) == 0 {
+		return fmt.Errorf("at least one member is required")
+	}
+	if len(sched.Members) > maxOncallMembers {
+		return fmt.Errorf("at most %d members are allowed", maxOncallMembers)
+	}
+	for i := range sched.Members {
+		if err := validateOncallMember(&sched.Members[i]); err != nil {
+			return fmt.Errorf("members[%d]: %w", i, err)
+		}
+	}
+	if len(sched.Overrides) > maxOncallOverrides {
+		return fmt.Errorf("at most %d overrides are allowed", maxOncallOverrides)
+	}
+	for i := range sched.Overrides {
+		o := &sched.Overrides[i]
+		if o.Start.IsZero() || !o.End.After(o.Start) {
+			return fmt.Errorf("overrides[%d]: end must be after start", i)
+		}
+		if err := validateOncallMember(&o.Member); err != nil {
+			return fmt.Errorf("overrides[%d].member: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateOncallMember trims the member's fields and requires a name and a
+// way to reach them.
+func validateOncallMember(m *storage.OncallMember) error {
+	m.Name = strings.TrimSpace(m.Name)
+	m.RoutingKey = strings.TrimSpace(m.RoutingKey)
+	if m.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(m.Name) > 255 {
+		return fmt.Errorf("name must be at most 255 characters")
+	}
+	if m.Email == "" && m.RoutingKey == "" {
+		return fmt.Errorf("email or routing_key is required")
+	}
+	if m.Email != "" {
+		email, err := ValidateSubscriberEmail(m.Email)
+		if err != nil {
+			return err
+		}
+		m.Email = email
+	}
+	return nil
+}
+
 func ValidateMaintenanceWindow(mw *storage.MaintenanceWindow) error {
 	if strings.TrimSpace(mw.Name) == "" {
 		return fmt.Errorf("name is required")
 	}
-	if mw.StartTime.IsZero() {
-		return fmt.Errorf("start_time is required")
+	switch mw.Recurring {
+	case "", "daily", "weekly", "monthly":
+	case "cron":
+		if err := validateCronWindow(mw); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("recurring must be one of: daily, weekly, monthly, cron")
 	}
-	if mw.EndTime.IsZero() {
-		return fmt.Errorf("end_time is required")
+	if mw.Recurring != "cron" {
+		if mw.CronExpr != "" {
+			return fmt.Errorf("cron_expr requires recurring: cron")
+		}
+		if mw.StartTime.IsZero() {
+			return fmt.Errorf("start_time is required")
+		}
+		if mw.EndTime.IsZero() {
+			return fmt.Errorf("end_time is required")
+		}
+		if !mw.EndTime.After(mw.StartTime) {
+			return fmt.Errorf("end_time must be after start_time")
+		}
+	}
+	if mw.Timezone != "" {
+		if _, err := time.LoadLocation(mw.Timezone); err != nil {
+			return fmt.Errorf("timezone: unknown time zone %q", mw.Timezone)
+		}
+	}
+	return nil
+}
+
+// maxCronWindowMinutes caps a cron window at one week.
+const maxCronWindowMinutes = 7 * 24 * 60
+
+func validateCronWindow(mw *storage.MaintenanceWindow) error {
+	if strings.TrimSpace(mw.CronExpr) == "" {
+		return fmt.Errorf("cron_expr is required for cron windows")
+	}
+	expr, err := cronexpr.Parse(mw.CronExpr)
+	if err != nil {
+		return fmt.Errorf("cron_expr: %v", err)
+	}
+	if expr.Next(time.Now()).IsZero() {
+		return fmt.Errorf("cron_expr never fires")
+	}
+	if mw.DurationMinutes < 1 || mw.DurationMinutes > maxCronWindowMinutes {
+		return fmt.Errorf("duration_minutes must be between 1 and %d", maxCronWindowMinutes)
+	}
+	return nil
+}
+
+// maxAckTimeoutMinutes caps an acknowledgement timeout at one week.
+const maxAckTimeoutMinutes = 7 * 24 * 60
+
+// ValidateAckTimeout checks an optional acknowledgement timeout; 0 means the
+// acknowledgement never lapses.
+func ValidateAckTimeout(minutes int) error {
+	if minutes < 0 || minutes > maxAckTimeoutMinutes {
+		return fmt.Errorf("ack_timeout_minutes must be between 0 and %d", maxAckTimeoutMinutes)
 	}
-	if !mw.EndTime.After(mw.StartTime) {
-		return fmt.Errorf("end_time must be after start_time")
+	return nil
+}
+
+const (
+	maxPostmortemLen   = 20000
+	maxIncidentNoteLen = 2000
+)
+
+// ValidatePostmortem checks an incident postmortem; empty clears it.
+func ValidatePostmortem(text string) error {
+	if len(text) > maxPostmortemLen {
+		return fmt.Errorf("postmortem must be at most %d characters", maxPostmortemLen)
+	}
+	return nil
+}
+
+// ValidateIncidentNote checks the message of a timeline note.
+func ValidateIncidentNote(message string) error {
+	if strings.TrimSpace(message) == "" {
+		return fmt.Errorf("message is required")
 	}
-	if mw.Recurring != "" && mw.Recurring != "daily" && mw.Recurring != "weekly" && mw.Recurring != "monthly" {
-		return fmt.Errorf("recurring must be one of: daily, weekly, monthly")
+	if len(message) > maxIncidentNoteLen {
+		return fmt.Errorf("message must be at most %d characters", maxIncidentNoteLen)
 	}
 	return nil
 }
@@ -400,7 +1498,8 @@ func splitCSSRules(css string) []string {
 }
 
 // sanitizeHTML strips dangerous tags and attributes from HTML, allowing only
-// safe structural elements. Used for CustomHeaderHTML on public status pages.
+// safe structural elements. Used for CustomHeaderHTML and AnnouncementHTML on
+// public status pages.
 func sanitizeHTML(input string) string {
 	if input == "" {
 		return ""
@@ -42,10 +42,32 @@ func TestValidateMonitor(t *testing.T) {
 		{"timeout too high", func(m *storage.Monitor) { m.Timeout = 301 }, "at most 300"},
 		{"failure threshold zero", func(m *storage.Monitor) { m.FailureThreshold = 0 }, "at least 1"},
 		{"success threshold zero", func(m *storage.Monitor) { m.SuccessThreshold = 0 }, "at least 1"},
+		{"degraded threshold valid", func(m *storage.Monitor) { m.DegradedThreshold = 3 }, ""},
+		{"degraded threshold negative", func(m *storage.Monitor) { m.DegradedThreshold = -1 }, "degraded_threshold must be between 0 and 100"},
+		{"degraded threshold too high", func(m *storage.Monitor) { m.DegradedThreshold = 101 }, "degraded_threshold must be between 0 and 100"},
+		{"flap window valid", func(m *storage.Monitor) { m.FlapWindowSeconds = 600 }, ""},
+		{"flap window too long", func(m *storage.Monitor) { m.FlapWindowSeconds = 86401 }, "flap_window_seconds must be between 0 and 86400"},
+		{"connect timeout valid", func(m *storage.Monitor) { m.ConnectTimeout = 3 }, ""},
+		{"connect timeout equal to timeout", func(m *storage.Monitor) { m.ConnectTimeout = m.Timeout }, ""},
+		{"connect timeout over timeout", func(m *storage.Monitor) { m.ConnectTimeout = m.Timeout + 1 }, "connect_timeout must be between 0 and timeout"},
+		{"connect timeout negative", func(m *storage.Monitor) { m.ConnectTimeout = -1 }, "connect_timeout must be between 0 and timeout"},
+		{"max response time valid", func(m *storage.Monitor) { m.MaxResponseTimeMs = 800 }, ""},
+		{"max response time negative", func(m *storage.Monitor) { m.MaxResponseTimeMs = -1 }, "max_response_time_ms must be between 0 and"},
+		{"max response time over timeout", func(m *storage.Monitor) { m.MaxResponseTimeMs = m.Timeout*1000 + 1 }, "max_response_time_ms must be between 0 and"},
+		{"warmup valid", func(m *storage.Monitor) { m.WarmupSeconds = 300 }, ""},
+		{"warmup negative", func(m *storage.Monitor) { m.WarmupSeconds = -1 }, "warmup_seconds must be between 0 and 86400"},
+		{"warmup too long", func(m *storage.Monitor) { m.WarmupSeconds = 86401 }, "warmup_seconds must be between 0 and 86400"},
 		{"resend interval zero", func(m *storage.Monitor) { m.ResendInterval = 0 }, ""},
 		{"resend interval valid", func(m *storage.Monitor) { m.ResendInterval = 300 }, ""},
 		{"resend interval negative", func(m *storage.Monitor) { m.ResendInterval = -1 }, "resend_interval must be non-negative"},
 		{"resend interval too high", func(m *storage.Monitor) { m.ResendInterval = 86401 }, "resend_interval must be at most 86400"},
+		{"retries valid", func(m *storage.Monitor) { m.Retries, m.RetryInterval = 5, 10 }, ""},
+		{"retries negative", func(m *storage.Monitor) { m.Retries = -1 }, "retries must be between 0 and 5"},
+		{"retries too high", func(m *storage.Monitor) { m.Retries = 6 }, "retries must be between 0 and 5"},
+		{"retry interval too high", func(m *storage.Monitor) { m.RetryInterval = 61 }, "retry_interval must be between 0 and 60"},
+		{"redact patterns valid", func(m *storage.Monitor) { m.RedactPatterns = []string{`token=\w+`} }, ""},
+		{"redact pattern empty", func(m *storage.Monitor) { m.RedactPatterns = []string{""} }, "redact_patterns[0] must not be empty"},
+		{"redact pattern invalid", func(m *storage.Monitor) { m.RedactPatterns = []string{"ok", "("} }, "redact_patterns[1]"},
 		{"invalid settings json", func(m *storage.Monitor) { m.Settings = json.RawMessage("not json") }, "valid JSON object"},
 		{"invalid assertions json", func(m *storage.Monitor) { m.Assertions = json.RawMessage("not json") }, "valid JSON array"},
 		{"valid settings", func(m *storage.Monitor) { m.Settings = json.RawMessage(`{"method":"GET"}`) }, ""},
@@ -97,6 +119,171 @@ func TestValidateNotificationChannel(t *testing.T) {
 			},
 			"",
 		},
+		{
+			"invalid tag id",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings: json.RawMessage(`{"url":"https://example.com"}`),
+				TagIDs:   []int64{3, 0},
+			},
+			"tag_ids",
+		},
+		{
+			"valid schedule",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings: json.RawMessage(`{"url":"https://example.com"}`),
+				Schedule: json.RawMessage(`{"windows":[{"days":["mon"],"start":"09:00","end":"17:00"}]}`),
+			},
+			"",
+		},
+		{
+			"check stream on webhook",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings: json.RawMessage(`{"url":"https://example.com"}`),
+				Events:   []string{"check.completed"},
+			},
+			"",
+		},
+		{
+			"webhook body template",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings: json.RawMessage(`{"url":"https://example.com","body_template":"{\"event\":{{json .EventType}}}","content_type":"application/vnd.bus+json"}`),
+			},
+			"",
+		},
+		{
+			"twilio",
+			&storage.NotificationChannel{
+				Name: "SMS", Type: "twilio",
+				Settings: json.RawMessage(`{"account_sid":"AC1","auth_token":"tok","from_number":"+15550100","to_numbers":["+15550123"]}`),
+			},
+			"",
+		},
+		{
+			"twilio bad recipient",
+			&storage.NotificationChannel{
+				Name: "SMS", Type: "twilio",
+				Settings: json.RawMessage(`{"account_sid":"AC1","auth_token":"tok","from_number":"+15550100","to_numbers":["555-0123"]}`),
+			},
+			"E.164",
+		},
+		{
+			"signal",
+			&storage.NotificationChannel{
+				Name: "Signal", Type: "signal",
+				Settings: json.RawMessage(`{"api_url":"http://signal-cli:8080","number":"+15550100","recipients":["+15550123"]}`),
+			},
+			"",
+		},
+		{
+			"signal gateway from env",
+			&storage.NotificationChannel{
+				Name: "Signal", Type: "signal",
+				Settings: json.RawMessage(`{"api_url":"${ASURA_NOTIFY_SIGNAL_URL}","number":"+15550100","recipients":["+15550123"]}`),
+			},
+			"",
+		},
+		{
+			"env reference without prefix",
+			&storage.NotificationChannel{
+				Name: "Slack", Type: "slack",
+				Settings: json.RawMessage(`{"webhook_url":"${AWS_SECRET_ACCESS_KEY}"}`),
+			},
+			"must start with ASURA_NOTIFY_",
+		},
+		{
+			"signal without gateway",
+			&storage.NotificationChannel{
+				Name: "Signal", Type: "signal",
+				Settings: json.RawMessage(`{"number":"+15550100","recipients":["+15550123"]}`),
+			},
+			"api_url",
+		},
+		{
+			"webhook body template parse error",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings: json.RawMessage(`{"url":"https://example.com","body_template":"{{.EventType"}`),
+			},
+			"body_template:",
+		},
+		{
+			"webhook body template unknown func",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings: json.RawMessage(`{"url":"https://example.com","body_template":"{{title .EventType}}"}`),
+			},
+			"function \"title\" not defined",
+		},
+		{
+			"webhook content type without template",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings: json.RawMessage(`{"url":"https://example.com","content_type":"text/plain"}`),
+			},
+			"only used with body_template",
+		},
+		{
+			"webhook invalid content type",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings: json.RawMessage(`{"url":"https://example.com","body_template":"x","content_type":"text/"}`),
+			},
+			"valid media type",
+		},
+		{
+			"email templates",
+			&storage.NotificationChannel{
+				Name: "Mail", Type: "email",
+				Settings: json.RawMessage(`{"host":"smtp.example.com","to":["ops@example.com"],"reply_to":"oncall@example.com","subject_template":"[{{upper .EventType}}]","html_template":"<p>{{.EventType}}</p>"}`),
+			},
+			"",
+		},
+		{
+			"email subject template parse error",
+			&storage.NotificationChannel{
+				Name: "Mail", Type: "email",
+				Settings: json.RawMessage(`{"host":"smtp.example.com","to":["ops@example.com"],"subject_template":"{{.EventType"}`),
+			},
+			"subject_template:",
+		},
+		{
+			"email html template escape error",
+			&storage.NotificationChannel{
+				Name: "Mail", Type: "email",
+				Settings: json.RawMessage(`{"host":"smtp.example.com","to":["ops@example.com"],"html_template":"<a href=\"{{.EventType}}"}`),
+			},
+			"html_template:",
+		},
+		{
+			"email bad reply-to",
+			&storage.NotificationChannel{
+				Name: "Mail", Type: "email",
+				Settings: json.RawMessage(`{"host":"smtp.example.com","to":["ops@example.com"],"reply_to":"not an address"}`),
+			},
+			"reply_to",
+		},
+		{
+			"check stream on slack",
+			&storage.NotificationChannel{
+				Name: "Slack", Type: "slack",
+				Settings: json.RawMessage(`{"webhook_url":"https://hooks.slack.com/x"}`),
+				Events:   []string{"check.completed"},
+			},
+			"only supported by webhook",
+		},
+		{
+			"invalid schedule",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings: json.RawMessage(`{"url":"https://example.com"}`),
+				Schedule: json.RawMessage(`{"windows":[{"start":"9","end":"17:00"}]}`),
+			},
+			"schedule.windows[0].start",
+		},
 		{
 			"empty name",
 			&storage.NotificationChannel{Name: "", Type: "webhook", Settings: json.RawMessage("{}")},
@@ -126,6 +313,66 @@ func TestValidateNotificationChannel(t *testing.T) {
 			},
 			"invalid event",
 		},
+		{
+			"valid ntfy severity priorities",
+			&storage.NotificationChannel{
+				Name: "Phone", Type: "ntfy",
+				Settings: json.RawMessage(`{"topic":"alerts","severity_priorities":{"critical":5,"info":1}}`),
+			},
+			"",
+		},
+		{
+			"ntfy priority out of range",
+			&storage.NotificationChannel{
+				Name: "Phone", Type: "ntfy",
+				Settings: json.RawMessage(`{"topic":"alerts","severity_priorities":{"critical":8}}`),
+			},
+			"between 1 and 5",
+		},
+		{
+			"gotify unknown severity",
+			&storage.NotificationChannel{
+				Name: "Phone", Type: "gotify",
+				Settings: json.RawMessage(`{"server_url":"https://g.example.com","severity_priorities":{"major":8}}`),
+			},
+			"unknown severity",
+		},
+		{
+			"oncall schedule on unsupported type",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings:         json.RawMessage(`{"url":"https://example.com"}`),
+				OncallScheduleID: func() *int64 { id := int64(1); return &id }(),
+			},
+			"oncall_schedule_id is only supported",
+		},
+		{
+			"digest window too short",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings:            json.RawMessage(`{"url":"https://example.com"}`),
+				DigestWindowSeconds: 5,
+			},
+			"digest_window_seconds must be 0 or between",
+		},
+		{
+			"digest window too long",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings:            json.RawMessage(`{"url":"https://example.com"}`),
+				DigestWindowSeconds: 7200,
+			},
+			"digest_window_seconds must be 0 or between",
+		},
+		{
+			"flush on resolve without digest",
+			&storage.NotificationChannel{
+				Name: "Hook", Type: "webhook",
+				Settings:             json.RawMessage(`{"url":"https://example.com"}`),
+				DigestFlushOnResolve: true,
+			},
+			"digest_flush_on_resolve requires",
+		},
 	}
 
 	for _, tt := range tests {
@@ -163,6 +410,15 @@ func TestValidateMaintenanceWindow(t *testing.T) {
 		{"zero end", &storage.MaintenanceWindow{Name: "MW", StartTime: now}, "end_time is required"},
 		{"end before start", &storage.MaintenanceWindow{Name: "MW", StartTime: later, EndTime: now}, "end_time must be after"},
 		{"invalid recurring", &storage.MaintenanceWindow{Name: "MW", StartTime: now, EndTime: later, Recurring: "yearly"}, "recurring must be one of"},
+		{"valid timezone", &storage.MaintenanceWindow{Name: "MW", StartTime: now, EndTime: later, Recurring: "daily", Timezone: "America/New_York"}, ""},
+		{"unknown timezone", &storage.MaintenanceWindow{Name: "MW", StartTime: now, EndTime: later, Recurring: "daily", Timezone: "Mars/Olympus"}, "unknown time zone"},
+		{"valid cron", &storage.MaintenanceWindow{Name: "MW", Recurring: "cron", CronExpr: "0 1 * * MON#1", DurationMinutes: 120}, ""},
+		{"cron without expr", &storage.MaintenanceWindow{Name: "MW", Recurring: "cron", DurationMinutes: 60}, "cron_expr is required"},
+		{"malformed cron", &storage.MaintenanceWindow{Name: "MW", Recurring: "cron", CronExpr: "0 1 *", DurationMinutes: 60}, "cron_expr"},
+		{"cron never fires", &storage.MaintenanceWindow{Name: "MW", Recurring: "cron", CronExpr: "0 0 30 2 *", DurationMinutes: 60}, "never fires"},
+		{"cron zero duration", &storage.MaintenanceWindow{Name: "MW", Recurring: "cron", CronExpr: "0 2 * * *"}, "duration_minutes"},
+		{"cron duration too long", &storage.MaintenanceWindow{Name: "MW", Recurring: "cron", CronExpr: "0 2 * * *", DurationMinutes: 20000}, "duration_minutes"},
+		{"cron expr without cron mode", &storage.MaintenanceWindow{Name: "MW", StartTime: now, EndTime: later, CronExpr: "0 2 * * *"}, "requires recurring: cron"},
 	}
 
 	for _, tt := range tests {
@@ -249,6 +505,45 @@ func TestValidateProxy(t *testing.T) {
 	}
 }
 
+func TestValidateProbe(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       *storage.Probe
+		wantErr string
+	}{
+		{"valid local", &storage.Probe{Name: "local", Type: "local"}, ""},
+		{"valid local with source", &storage.Probe{Name: "eth1", Type: "local", SourceAddr: "192.0.2.10"}, ""},
+		{"valid agent", &storage.Probe{Name: "eu-west", Type: "agent", URL: "https://eu.example.com/api/v1/probe/check", Token: "ak_x"}, ""},
+		{"empty name", &storage.Probe{Type: "local"}, "name is required"},
+		{"bad type", &storage.Probe{Name: "P", Type: "remote"}, "type must be local or agent"},
+		{"bad source addr", &storage.Probe{Name: "P", Type: "local", SourceAddr: "eth0"}, "source_addr must be an IP address"},
+		{"local with url", &storage.Probe{Name: "P", Type: "local", URL: "https://x"}, "url is only used by agent probes"},
+		{"agent without url", &storage.Probe{Name: "P", Type: "agent"}, "url must be an http or https URL"},
+		{"agent bad scheme", &storage.Probe{Name: "P", Type: "agent", URL: "ftp://x/probe"}, "url must be an http or https URL"},
+		{"agent with source", &storage.Probe{Name: "P", Type: "agent", URL: "https://x", SourceAddr: "192.0.2.10"}, "source_addr is only used by local probes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProbe(tt.p)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %q, want substring %q", err.Error(), tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
+
 func TestValidateStatusPage(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -260,6 +555,12 @@ func TestValidateStatusPage(t *testing.T) {
 		{"title too long", &storage.StatusPage{Title: strings.Repeat("t", 201), Slug: "slug"}, "at most 200"},
 		{"empty slug", &storage.StatusPage{Title: "T"}, "slug is required"},
 		{"description too long", &storage.StatusPage{Title: "T", Slug: "s", Description: strings.Repeat("d", 1001)}, "at most 1000"},
+		{"announcement too long", &storage.StatusPage{Title: "T", Slug: "s", AnnouncementHTML: strings.Repeat("a", 5001)}, "announcement_html"},
+		{"announcement ends before start", &storage.StatusPage{Title: "T", Slug: "s",
+			AnnouncementStart: timePtr(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)),
+			AnnouncementEnd:   timePtr(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))}, "announcement_end"},
+		{"api token hash", &storage.StatusPage{Title: "T", Slug: "s", APITokenHash: strings.Repeat("ab", 32)}, ""},
+		{"api token not hashed", &storage.StatusPage{Title: "T", Slug: "s", APITokenHash: "plain-token"}, "api_token_hash"},
 	}
 
 	for _, tt := range tests {
@@ -416,129 +717,736 @@ func TestValidateSlug(t *testing.T) {
 	}
 }
 
-func TestValidateDockerSettings(t *testing.T) {
+func TestValidateHTTPRangeSettings(t *testing.T) {
 	tests := []struct {
 		name     string
-		target   string
 		settings string
 		wantErr  string
 	}{
-		{"valid container name", "my-container", `{}`, ""},
-		{"name from settings", "ignored", `{"container_name":"my-app"}`, ""},
-		{"invalid chars in name", "bad/name", `{}`, "invalid characters"},
-		{"path traversal in socket", "ok", `{"socket_path":"/../etc/shadow"}`, "path traversal"},
-		{"relative socket path", "ok", `{"socket_path":"var/run/docker.sock"}`, "absolute path"},
-		{"valid socket path", "ok", `{"socket_path":"/var/run/docker.sock"}`, ""},
+		{"no range", `{}`, ""},
+		{"range with ttfb", `{"range_bytes":1024,"max_ttfb_ms":500}`, ""},
+		{"negative range", `{"range_bytes":-1}`, "range_bytes"},
+		{"range too large", `{"range_bytes":2097152}`, "range_bytes"},
+		{"negative ttfb", `{"max_ttfb_ms":-5}`, "max_ttfb_ms"},
+		{"multipart", `{"body_encoding":"multipart","body":"a=1\nb=x=y","multipart_file":{"field":"file","filename":"a.txt","content":"hi"}}`, ""},
+		{"multipart bad line", `{"body_encoding":"multipart","body":"a=1\nnope"}`, "line 2"},
+		{"multipart file without encoding", `{"body_encoding":"form","multipart_file":{"field":"file","filename":"a.txt"}}`, "requires body_encoding"},
+		{"multipart file without field", `{"body_encoding":"multipart","multipart_file":{"filename":"a.txt"}}`, "field and filename"},
+		{"content selector", `{"content":{"selector":"#pricing .price, main > h1"}}`, ""},
+		{"content selector pseudo-class", `{"content":{"selector":"li:first-child"}}`, "settings.content.selector"},
+		{"content selector unterminated", `{"content":{"selector":"[data-id"}}`, "settings.content.selector"},
+		{"ignore patterns", `{"content":{"ignore_patterns":["\\d{2}:\\d{2}","csrf=[a-f0-9]+"]}}`, ""},
+		{"ignore pattern invalid", `{"content":{"ignore_patterns":["ok","(unclosed"]}}`, "ignore_patterns[1]"},
+		{"ignore pattern empty", `{"content":{"ignore_patterns":[""]}}`, "must not be empty"},
+		{"expected status number", `{"expected_status":201}`, ""},
+		{"expected status spec", `{"expected_status":"200-299, 401,5XX"}`, ""},
+		{"expected status reversed range", `{"expected_status":"299-200"}`, "reversed"},
+		{"expected status out of range", `{"expected_status":"200,600"}`, "settings.expected_status"},
+		{"expected status empty entry", `{"expected_status":"200,"}`, "settings.expected_status"},
+		{"expected status bad class", `{"expected_status":"6xx"}`, "settings.expected_status"},
+		{"expected status wrong type", `{"expected_status":true}`, "expected_status"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &storage.Monitor{
-				Name:             "Docker Test",
-				Type:             "docker",
-				Target:           tt.target,
-				Interval:         30,
-				Timeout:          5,
-				FailureThreshold: 1,
-				SuccessThreshold: 1,
-			}
-			if tt.settings != "" {
-				m.Settings = json.RawMessage(tt.settings)
+				Name: "Range Test", Type: "http", Target: "https://example.com/video.mp4",
+				Interval: 30, Timeout: 5, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
 			}
 			err := ValidateMonitor(m)
 			if tt.wantErr == "" {
 				if err != nil {
 					t.Errorf("expected no error, got: %v", err)
 				}
-			} else {
-				if err == nil {
-					t.Fatal("expected error, got nil")
-				}
-				if !strings.Contains(err.Error(), tt.wantErr) {
-					t.Errorf("error = %q, want substring %q", err.Error(), tt.wantErr)
-				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestValidateTag(t *testing.T) {
+func TestValidateTCPSettings(t *testing.T) {
 	tests := []struct {
-		name    string
-		tag     *storage.Tag
-		wantErr string
+		name     string
+		settings string
+		wantErr  string
 	}{
-		{"valid", &storage.Tag{Name: "prod", Color: "#ff0000"}, ""},
-		{"valid default color", &storage.Tag{Name: "prod"}, ""},
-		{"empty name", &storage.Tag{Name: "", Color: "#ff0000"}, "name is required"},
-		{"name too long", &storage.Tag{Name: strings.Repeat("t", 51), Color: "#ff0000"}, "at most 50"},
-		{"invalid color", &storage.Tag{Name: "tag", Color: "red"}, "valid hex color"},
-		{"short hex", &storage.Tag{Name: "tag", Color: "#fff"}, "valid hex color"},
+		{"default", `{"expect_data":"SSH-"}`, ""},
+		{"connect only", `{"mode":"connect"}`, ""},
+		{"banner with timeout", `{"mode":"banner","banner_timeout_ms":2000}`, ""},
+		{"unknown mode", `{"mode":"udp"}`, "settings.mode"},
+		{"connect with expect", `{"mode":"connect","expect_data":"x"}`, "connect"},
+		{"expect regex", `{"mode":"banner","expect_regex":"^SSH-2\\.0-OpenSSH_[0-9]"}`, ""},
+		{"invalid expect regex", `{"expect_regex":"SSH-("}`, "settings.expect_regex"},
+		{"connect with regex", `{"mode":"connect","expect_regex":"x"}`, "connect"},
+		{"negative timeout", `{"banner_timeout_ms":-1}`, "banner_timeout_ms"},
+		{"timeout above monitor timeout", `{"banner_timeout_ms":6000}`, "banner_timeout_ms"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := ValidateTag(tt.tag)
+			m := &storage.Monitor{
+				Name: "TCP Test", Type: "tcp", Target: "example.com:22",
+				Interval: 30, Timeout: 5, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
 			if tt.wantErr == "" {
 				if err != nil {
 					t.Errorf("expected no error, got: %v", err)
 				}
-			} else {
-				if err == nil {
-					t.Fatal("expected error, got nil")
-				}
-				if !strings.Contains(err.Error(), tt.wantErr) {
-					t.Errorf("error = %q, want substring %q", err.Error(), tt.wantErr)
-				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestSanitizeHTML(t *testing.T) {
+func TestValidateDNSSettings(t *testing.T) {
 	tests := []struct {
-		name  string
-		input string
-		want  string
+		name     string
+		settings string
+		wantErr  string
 	}{
-		{"empty", "", ""},
-		{"plain text", "Hello world", "Hello world"},
-		{"safe tags", `<p>Hello <strong>world</strong></p>`, `<p>Hello <strong>world</strong></p>`},
-		{"safe link", `<a href="https://example.com">link</a>`, `<a href="https://example.com">link</a>`},
-		{"safe image", `<img src="https://img.example.com/logo.png" alt="Logo"/>`, `<img src="https://img.example.com/logo.png" alt="Logo"/>`},
-		{"strips script", `<p>Hello</p><script>alert(1)</script>`, `<p>Hello</p>`},
-		{"strips iframe", `<div><iframe src="https://evil.com"></iframe></div>`, `<div></div>`},
-		{"strips object", `<object data="evil.swf"></object>`, ``},
-		{"strips form", `<form action="/steal"><input></form>`, ``},
-		{"strips event handlers", `<div onclick="alert(1)">click</div>`, `<div>click</div>`},
-		{"strips onload", `<img src="https://img.example.com/x.png" onload="alert(1)"/>`, `<img src="https://img.example.com/x.png"/>`},
-		{"strips onerror", `<img src="/logo.png" onerror="alert(1)"/>`, `<img src="/logo.png"/>`},
-		{"strips javascript href", `<a href="javascript:alert(1)">xss</a>`, `<a>xss</a>`},
-		{"strips data href", `<a href="data:text/html,<script>alert(1)</script>">xss</a>`, `<a>xss</a>`},
-		{"strips style tag", `<style>body{display:none}</style><p>hi</p>`, `<p>hi</p>`},
-		{"keeps class attribute", `<div class="banner">hi</div>`, `<div class="banner">hi</div>`},
-		{"keeps style attribute", `<div style="color:red">hi</div>`, `<div style="color:red">hi</div>`},
-		{"strips unknown attributes", `<div data-evil="x" draggable="true">hi</div>`, `<div>hi</div>`},
-		{"nested safe", `<div><p><strong>bold</strong> and <em>italic</em></p></div>`, `<div><p><strong>bold</strong> and <em>italic</em></p></div>`},
-		{"relative src ok", `<img src="/static/logo.png" alt="ok"/>`, `<img src="/static/logo.png" alt="ok"/>`},
-		{"anchor href ok", `<a href="#section">jump</a>`, `<a href="#section">jump</a>`},
+		{"default", `{"record_type":"A"}`, ""},
+		{"expected values", `{"record_type":"A,AAAA","expected_values":["93.184.216.34"],"match_mode":"all"}`, ""},
+		{"unknown match mode", `{"expected_values":["1.2.3.4"],"match_mode":"some"}`, "settings.match_mode"},
+		{"match mode without values", `{"match_mode":"exact"}`, "requires expected_values"},
+		{"blank expected value", `{"expected_values":[" "]}`, "expected_values[0]"},
+		{"doh", `{"record_type":"A","doh_url":"https://dns.example.com/dns-query"}`, ""},
+		{"doh over http", `{"doh_url":"http://dns.example.com/dns-query"}`, "settings.doh_url"},
+		{"doh with server", `{"doh_url":"https://dns.example.com/dns-query","server":"8.8.8.8"}`, "mutually exclusive"},
+		{"dnssec", `{"record_type":"A","validate_dnssec":true}`, ""},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := sanitizeHTML(tt.input)
-			if got != tt.want {
-				t.Errorf("sanitizeHTML() = %q, want %q", got, tt.want)
+			m := &storage.Monitor{
+				Name: "DNS Test", Type: "dns", Target: "example.com",
+				Interval: 30, Timeout: 5, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestSanitizeAnalyticsScript(t *testing.T) {
+func TestValidateDNSSECOnlyForDNS(t *testing.T) {
+	m := &storage.Monitor{
+		Name: "TLS Test", Type: "tls", Target: "example.com",
+		Interval: 30, Timeout: 5, FailureThreshold: 1, SuccessThreshold: 1,
+		Settings: json.RawMessage(`{"validate_dnssec":true}`),
+	}
+	err := ValidateMonitor(m)
+	if err == nil || !strings.Contains(err.Error(), "only supported by dns monitors") {
+		t.Fatalf("error = %v, want validate_dnssec rejected", err)
+	}
+}
+
+func TestValidateAMQPSettings(t *testing.T) {
 	tests := []struct {
-		name  string
-		input string
-		want  string
+		name     string
+		target   string
+		settings string
+		wantErr  string
+	}{
+		{"valid", "http://rabbitmq:15672", `{"queue":"orders","warn_depth":100,"crit_depth":1000}`, ""},
+		{"no thresholds", "https://rabbitmq.example.com", `{"queue":"orders"}`, ""},
+		{"missing queue", "http://rabbitmq:15672", `{}`, "settings.queue"},
+		{"amqp url target", "amqp://rabbitmq:5672", `{"queue":"orders"}`, "management API URL"},
+		{"negative depth", "http://rabbitmq:15672", `{"queue":"orders","warn_depth":-1}`, "negative"},
+		{"warn above crit", "http://rabbitmq:15672", `{"queue":"orders","warn_depth":500,"crit_depth":100}`, "below"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Queue", Type: "amqp", Target: tt.target,
+				Interval: 30, Timeout: 5, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateS3Settings(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		settings string
+		wantErr  string
+	}{
+		{"valid", "https://s3.eu-west-1.amazonaws.com", `{"bucket":"backups","key":"db.sql.gz","access_key_id":"AKID","secret_access_key":"secret","max_age_seconds":86400}`, ""},
+		{"anonymous", "http://minio:9000", `{"bucket":"public","key":"index.html"}`, ""},
+		{"s3 url target", "s3://backups/db.sql.gz", `{"bucket":"backups","key":"db.sql.gz"}`, "endpoint URL"},
+		{"missing bucket", "http://minio:9000", `{"key":"db.sql.gz"}`, "settings.bucket is required"},
+		{"bucket with slash", "http://minio:9000", `{"bucket":"a/b","key":"db.sql.gz"}`, "slashes"},
+		{"missing key", "http://minio:9000", `{"bucket":"backups"}`, "settings.key"},
+		{"secret without id", "http://minio:9000", `{"bucket":"backups","key":"k","secret_access_key":"secret"}`, "set together"},
+		{"negative max age", "http://minio:9000", `{"bucket":"backups","key":"k","max_age_seconds":-1}`, "negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Backup", Type: "s3", Target: tt.target,
+				Interval: 300, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSMTPSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  string
+	}{
+		{"empty", `{}`, ""},
+		{"auth with starttls", `{"starttls":true,"username":"relay","password":"secret"}`, ""},
+		{"test mail", `{"send_test_mail":true,"mail_from":"monitor@example.com","mail_to":"sink@example.com"}`, ""},
+		{"password without username", `{"password":"secret"}`, "requires settings.username"},
+		{"auth without starttls", `{"username":"relay","password":"secret"}`, "requires settings.starttls"},
+		{"test mail without recipient", `{"send_test_mail":true,"mail_from":"monitor@example.com"}`, "mail_from and mail_to"},
+		{"header injection", `{"mail_from":"a@example.com\r\nBcc: x@example.com"}`, "line breaks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Relay", Type: "smtp", Target: "mail.example.com:25",
+				Interval: 60, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRedisSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  string
+	}{
+		{"empty", `{}`, ""},
+		{"info", `{"password":"secret","db":3,"command":"INFO replication"}`, ""},
+		{"lowercase read command", `{"command":"llen jobs"}`, ""},
+		{"db out of range", `{"db":16}`, "between 0 and 15"},
+		{"username without password", `{"username":"monitor"}`, "requires settings.password"},
+		{"write command", `{"command":"FLUSHALL"}`, "read-only command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Cache", Type: "redis", Target: "redis:6379",
+				Interval: 60, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSSHSettings(t *testing.T) {
+	fingerprint := "SHA256:RKDJ9vqHHvjl+SyoXVE0MqMu8sql1UQEBQ86vO3Jv+Y"
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  string
+	}{
+		{"empty", `{}`, ""},
+		{"fingerprint", `{"expected_fingerprint":"` + fingerprint + `","expect_banner":"OpenSSH"}`, ""},
+		{"fingerprint without prefix", `{"expected_fingerprint":"RKDJ9vqHHvjl+SyoXVE0MqMu8sql1UQEBQ86vO3Jv+Y="}`, ""},
+		{"banner only", `{"banner_only":true,"expect_banner":"OpenSSH"}`, ""},
+		{"md5 fingerprint", `{"expected_fingerprint":"MD5:16:27:ac:a5:76:28:2d:36:63:1b:56:4d:eb:df:a6:48"}`, "SHA256 fingerprint"},
+		{"fingerprint with banner only", `{"banner_only":true,"expected_fingerprint":"` + fingerprint + `"}`, "banner_only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Bastion", Type: "ssh", Target: "bastion.example.com",
+				Interval: 60, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNTPSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  string
+	}{
+		{"empty", `{}`, ""},
+		{"thresholds", `{"max_offset_ms":50,"crit_offset_ms":500,"max_stratum":3}`, ""},
+		{"negative offset", `{"max_offset_ms":-1}`, "must not be negative"},
+		{"max above crit", `{"max_offset_ms":500,"crit_offset_ms":50}`, "below settings.crit_offset_ms"},
+		{"stratum out of range", `{"max_stratum":16}`, "between 0 and 15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Time", Type: "ntp", Target: "time.example.com",
+				Interval: 60, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTLSSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  string
+	}{
+		{"empty", `{}`, ""},
+		{"expectations", `{"expected_issuer":"R11","expected_cn":"example.com","require_chain_valid":true}`, ""},
+		{"long common name", `{"expected_cn":"` + strings.Repeat("a", 256) + `"}`, "settings.expected_cn"},
+		{"wrong type", `{"require_chain_valid":"yes"}`, "invalid tls settings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Cert", Type: "tls", Target: "example.com:443",
+				Interval: 60, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTransactionSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		settings string
+		wantErr  string
+	}{
+		{"login flow", "https://app.example.com", `{"steps":[{"method":"POST","url":"/login","body":"{}","extract":[{"var":"token","source":"json","path":"$.token"}]},{"url":"/me","headers":{"Authorization":"Bearer {{token}}"},"expected_status":"2xx","assertions":{"operator":"and","groups":[]}}]}`, ""},
+		{"target not a url", "app.example.com", `{"steps":[{"url":"/"}]}`, "target must be the base URL"},
+		{"no steps", "https://app.example.com", `{}`, "at least one step"},
+		{"missing url", "https://app.example.com", `{"steps":[{"method":"GET"}]}`, "settings.steps[0].url is required"},
+		{"ftp step", "https://app.example.com", `{"steps":[{"url":"ftp://files.example.com/x"}]}`, "must be http or https"},
+		{"bad status", "https://app.example.com", `{"steps":[{"url":"/","expected_status":"abc"}]}`, "settings.steps[0].expected_status"},
+		{"assertions not object", "https://app.example.com", `{"steps":[{"url":"/","assertions":[1]}]}`, "must be a JSON object"},
+		{"bad var name", "https://app.example.com", `{"steps":[{"url":"/","extract":[{"var":"a-b","path":"$.x"}]}]}`, "extract[0].var"},
+		{"bad source", "https://app.example.com", `{"steps":[{"url":"/","extract":[{"var":"a","source":"cookie","path":"x"}]}]}`, "json or header"},
+		{"missing path", "https://app.example.com", `{"steps":[{"url":"/","extract":[{"var":"a","source":"header"}]}]}`, "extract[0].path is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Login", Type: "transaction", Target: tt.target,
+				Interval: 60, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWebSocketSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  string
+	}{
+		{"empty", `{}`, ""},
+		{"steps", `{"subprotocol":"v1","steps":[{"send":"{\"type\":\"auth\"}","expect":"{\"ok\":true}","match":"json"},{"send":"ping","expect":"^pong","match":"regex"},{"expect":"bye"}]}`, ""},
+		{"empty step", `{"steps":[{"match":"regex"}]}`, "send or expect is required"},
+		{"bad regex", `{"steps":[{"expect":"(","match":"regex"}]}`, "settings.steps[0].expect"},
+		{"bad json", `{"steps":[{"expect":"{ok","match":"json"}]}`, "must be valid JSON"},
+		{"unknown match", `{"steps":[{"expect":"ok","match":"glob"}]}`, "must be contains, regex or json"},
+		{"too many steps", `{"steps":[` + strings.TrimSuffix(strings.Repeat(`{"send":"x"},`, 21), ",") + `]}`, "at most 20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Realtime", Type: "websocket", Target: "wss://example.com/ws",
+				Interval: 60, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMQTTSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  string
+	}{
+		{"empty", `{}`, ""},
+		{"expect message", `{"topic":"health","expect_message":"ok","qos":1,"wait_retained":true}`, ""},
+		{"qos out of range", `{"topic":"health","qos":3}`, "settings.qos"},
+		{"bad regex", `{"topic":"health","expect_regex":"("}`, "settings.expect_regex"},
+		{"expectation without topic", `{"expect_message":"ok"}`, "settings.topic is required"},
+		{"retained without topic", `{"wait_retained":true}`, "settings.topic is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Broker", Type: "mqtt", Target: "broker.example.com:1883",
+				Interval: 60, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateKafkaSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  string
+	}{
+		{"empty", `{}`, ""},
+		{"sasl", `{"min_brokers":3,"use_tls":true,"sasl_username":"monitor","sasl_password":"secret"}`, ""},
+		{"negative min brokers", `{"min_brokers":-1}`, "must not be negative"},
+		{"user without password", `{"sasl_username":"monitor"}`, "sasl_password is required"},
+		{"password without user", `{"sasl_password":"secret"}`, "sasl_username is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Kafka", Type: "kafka", Target: "kafka.example.com:9092",
+				Interval: 60, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateICMPSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  string
+	}{
+		{"empty", `{}`, ""},
+		{"custom", `{"count":5,"interval_ms":200,"max_loss_percent":20}`, ""},
+		{"count out of range", `{"count":21}`, "between 0 and 20"},
+		{"interval too short", `{"interval_ms":50}`, "between 100 and 10000"},
+		{"loss out of range", `{"max_loss_percent":101}`, "between 0 and 100"},
+		{"exceeds timeout", `{"count":10,"interval_ms":2000}`, "fit within the timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "Ping", Type: "icmp", Target: "192.0.2.1",
+				Interval: 60, Timeout: 10, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateGRPCSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  string
+	}{
+		{"default health mode", `{}`, ""},
+		{"stream default method", `{"mode":"stream"}`, ""},
+		{"stream custom method", `{"mode":"stream","method":"/pkg.Feed/Subscribe","request_base64":"CgNCVEM="}`, ""},
+		{"unknown mode", `{"mode":"bidi"}`, "settings.mode"},
+		{"method without slash", `{"mode":"stream","method":"pkg.Feed/Subscribe"}`, "settings.method"},
+		{"method missing name", `{"mode":"stream","method":"/pkg.Feed/"}`, "settings.method"},
+		{"bad base64", `{"mode":"stream","request_base64":"not base64!"}`, "request_base64"},
+		{"unary method with request", `{"method":"/pkg.Users/Get","request":{"id":"42"}}`, ""},
+		{"request not an object", `{"method":"/pkg.Users/Get","request":"id=42"}`, "settings.request must be a JSON object"},
+		{"request without method", `{"request":{"id":"42"}}`, "requires settings.method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name: "gRPC Test", Type: "grpc", Target: "localhost:50051",
+				Interval: 30, Timeout: 5, FailureThreshold: 1, SuccessThreshold: 1,
+				Settings: json.RawMessage(tt.settings),
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDockerSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		settings string
+		wantErr  string
+	}{
+		{"valid container name", "my-container", `{}`, ""},
+		{"name from settings", "ignored", `{"container_name":"my-app"}`, ""},
+		{"invalid chars in name", "bad/name", `{}`, "invalid characters"},
+		{"path traversal in socket", "ok", `{"socket_path":"/../etc/shadow"}`, "path traversal"},
+		{"relative socket path", "ok", `{"socket_path":"var/run/docker.sock"}`, "absolute path"},
+		{"valid socket path", "ok", `{"socket_path":"/var/run/docker.sock"}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &storage.Monitor{
+				Name:             "Docker Test",
+				Type:             "docker",
+				Target:           tt.target,
+				Interval:         30,
+				Timeout:          5,
+				FailureThreshold: 1,
+				SuccessThreshold: 1,
+			}
+			if tt.settings != "" {
+				m.Settings = json.RawMessage(tt.settings)
+			}
+			err := ValidateMonitor(m)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %q, want substring %q", err.Error(), tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     *storage.Tag
+		wantErr string
+	}{
+		{"valid", &storage.Tag{Name: "prod", Color: "#ff0000"}, ""},
+		{"valid default color", &storage.Tag{Name: "prod"}, ""},
+		{"empty name", &storage.Tag{Name: "", Color: "#ff0000"}, "name is required"},
+		{"name too long", &storage.Tag{Name: strings.Repeat("t", 51), Color: "#ff0000"}, "at most 50"},
+		{"invalid color", &storage.Tag{Name: "tag", Color: "red"}, "valid hex color"},
+		{"short hex", &storage.Tag{Name: "tag", Color: "#fff"}, "valid hex color"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTag(tt.tag)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %q, want substring %q", err.Error(), tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"plain text", "Hello world", "Hello world"},
+		{"safe tags", `<p>Hello <strong>world</strong></p>`, `<p>Hello <strong>world</strong></p>`},
+		{"safe link", `<a href="https://example.com">link</a>`, `<a href="https://example.com">link</a>`},
+		{"safe image", `<img src="https://img.example.com/logo.png" alt="Logo"/>`, `<img src="https://img.example.com/logo.png" alt="Logo"/>`},
+		{"strips script", `<p>Hello</p><script>alert(1)</script>`, `<p>Hello</p>`},
+		{"strips iframe", `<div><iframe src="https://evil.com"></iframe></div>`, `<div></div>`},
+		{"strips object", `<object data="evil.swf"></object>`, ``},
+		{"strips form", `<form action="/steal"><input></form>`, ``},
+		{"strips event handlers", `<div onclick="alert(1)">click</div>`, `<div>click</div>`},
+		{"strips onload", `<img src="https://img.example.com/x.png" onload="alert(1)"/>`, `<img src="https://img.example.com/x.png"/>`},
+		{"strips onerror", `<img src="/logo.png" onerror="alert(1)"/>`, `<img src="/logo.png"/>`},
+		{"strips javascript href", `<a href="javascript:alert(1)">xss</a>`, `<a>xss</a>`},
+		{"strips data href", `<a href="data:text/html,<script>alert(1)</script>">xss</a>`, `<a>xss</a>`},
+		{"strips style tag", `<style>body{display:none}</style><p>hi</p>`, `<p>hi</p>`},
+		{"keeps class attribute", `<div class="banner">hi</div>`, `<div class="banner">hi</div>`},
+		{"keeps style attribute", `<div style="color:red">hi</div>`, `<div style="color:red">hi</div>`},
+		{"strips unknown attributes", `<div data-evil="x" draggable="true">hi</div>`, `<div>hi</div>`},
+		{"nested safe", `<div><p><strong>bold</strong> and <em>italic</em></p></div>`, `<div><p><strong>bold</strong> and <em>italic</em></p></div>`},
+		{"relative src ok", `<img src="/static/logo.png" alt="ok"/>`, `<img src="/static/logo.png" alt="ok"/>`},
+		{"anchor href ok", `<a href="#section">jump</a>`, `<a href="#section">jump</a>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizeHTML(tt.input)
+			if got != tt.want {
+				t.Errorf("sanitizeHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeAnalyticsScript(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
 	}{
 		{"empty", "", ""},
 		{"valid plausible", `<script defer data-domain="example.com" src="https://plausible.io/js/script.js"></script>`, `<script defer="" data-domain="example.com" src="https://plausible.io/js/script.js"></script>` + "\n"},
@@ -561,6 +1469,35 @@ func TestSanitizeAnalyticsScript(t *testing.T) {
 	}
 }
 
+func TestValidateNotificationFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[int64]*storage.NotificationFilter
+		wantErr string
+	}{
+		{"none", nil, ""},
+		{"valid", map[int64]*storage.NotificationFilter{1: {Tags: []string{"production"}, MinSeverity: "critical"}}, ""},
+		{"unlinked channel", map[int64]*storage.NotificationFilter{3: {MinSeverity: "critical"}}, "channel 3 is not in notification_channel_ids"},
+		{"unknown severity", map[int64]*storage.NotificationFilter{2: {MinSeverity: "high"}}, "min_severity"},
+		{"blank tag", map[int64]*storage.NotificationFilter{1: {Tags: []string{" "}}}, "tags[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNotificationFilters(tt.filters, []int64{1, 2})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateMonitorTags(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -597,3 +1534,165 @@ func TestValidateMonitorTags(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateEscalationPolicy(t *testing.T) {
+	step := func(delay int, ids ...int64) storage.EscalationStep {
+		return storage.EscalationStep{DelayMinutes: delay, ChannelIDs: ids}
+	}
+	tests := []struct {
+		name    string
+		policy  storage.EscalationPolicy
+		wantErr string
+	}{
+		{"valid", storage.EscalationPolicy{Name: "tiers", Steps: []storage.EscalationStep{step(15, 1), step(45, 2, 3)}}, ""},
+		{"no name", storage.EscalationPolicy{Steps: []storage.EscalationStep{step(15, 1)}}, "name is required"},
+		{"no steps", storage.EscalationPolicy{Name: "empty"}, "at least one step"},
+		{"zero delay", storage.EscalationPolicy{Name: "now", Steps: []storage.EscalationStep{step(0, 1)}}, "delay_minutes must be between"},
+		{"decreasing delay", storage.EscalationPolicy{Name: "back", Steps: []storage.EscalationStep{step(30, 1), step(30, 2)}}, "greater than the previous step"},
+		{"no channels", storage.EscalationPolicy{Name: "nobody", Steps: []storage.EscalationStep{step(15)}}, "channel_ids must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEscalationPolicy(&tt.policy)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMonitorParent(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	// 3 depends on 2, which depends on 1.
+	parents := map[int64]int64{2: 1, 3: 2}
+	tests := []struct {
+		name     string
+		id       int64
+		parentID *int64
+		wantErr  bool
+	}{
+		{"no parent", 1, nil, false},
+		{"new monitor", 0, id(3), false},
+		{"chain", 4, id(3), false},
+		{"direct cycle", 2, id(3), true},
+		{"indirect cycle", 1, id(3), true},
+		{"existing loop elsewhere", 5, id(6), false},
+	}
+	parents[6], parents[7] = 7, 6
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMonitorParent(tt.id, tt.parentID, parents)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	self := validMonitor()
+	self.ID = 4
+	self.ParentID = id(4)
+	if err := ValidateMonitor(self); err == nil || !strings.Contains(err.Error(), "parent_id") {
+		t.Errorf("expected self-parent error, got %v", err)
+	}
+}
+
+func TestValidateOncallSchedule(t *testing.T) {
+	alice := storage.OncallMember{Name: "Alice", Email: "alice@example.com"}
+	sched := func(mod func(*storage.OncallSchedule)) storage.OncallSchedule {
+		s := storage.OncallSchedule{Name: "Primary", Timezone: "Europe/Amsterdam", RotationStart: "2025-01-06T09:00", ShiftHours: 168, Members: []storage.OncallMember{alice}}
+		if mod != nil {
+			mod(&s)
+		}
+		return s
+	}
+	start := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		sched   storage.OncallSchedule
+		wantErr string
+	}{
+		{"valid", sched(nil), ""},
+		{"routing key only", sched(func(s *storage.OncallSchedule) {
+			s.Members = []storage.OncallMember{{Name: "Pager", RoutingKey: "R0KEY"}}
+		}), ""},
+		{"no name", sched(func(s *storage.OncallSchedule) { s.Name = " " }), "name is required"},
+		{"bad timezone", sched(func(s *storage.OncallSchedule) { s.Timezone = "Mars/Olympus" }), "unknown time zone"},
+		{"bad rotation start", sched(func(s *storage.OncallSchedule) { s.RotationStart = "monday" }), "rotation_start"},
+		{"zero shift", sched(func(s *storage.OncallSchedule) { s.ShiftHours = 0 }), "shift_hours must be between"},
+		{"no members", sched(func(s *storage.OncallSchedule) { s.Members = nil }), "at least one member"},
+		{"unreachable member", sched(func(s *storage.OncallSchedule) { s.Members = []storage.OncallMember{{Name: "Bob"}} }), "email or routing_key"},
+		{"bad member email", sched(func(s *storage.OncallSchedule) { s.Members = []storage.OncallMember{{Name: "Bob", Email: "bob"}} }), "members[0]"},
+		{"valid override", sched(func(s *storage.OncallSchedule) {
+			s.Overrides = []storage.OncallOverride{{Start: start, End: start.Add(8 * time.Hour), Member: alice}}
+		}), ""},
+		{"inverted override", sched(func(s *storage.OncallSchedule) {
+			s.Overrides = []storage.OncallOverride{{Start: start, End: start, Member: alice}}
+		}), "end must be after start"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOncallSchedule(&tt.sched)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSubscriberEmail(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{" Ops@Example.com ", "ops@example.com", ""},
+		{"", "", "email is required"},
+		{"not-an-email", "", "not a valid address"},
+		{"Ops <ops@example.com>", "", "not a valid address"},
+		{strings.Repeat("a", 250) + "@x.io", "", "at most 254"},
+	}
+	for _, tt := range tests {
+		got, err := ValidateSubscriberEmail(tt.in)
+		if tt.wantErr == "" {
+			if err != nil || got != tt.want {
+				t.Errorf("ValidateSubscriberEmail(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateSubscriberEmail(%q) error = %v, want substring %q", tt.in, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateIncidentText(t *testing.T) {
+	if err := ValidatePostmortem(""); err != nil {
+		t.Errorf("empty postmortem: %v", err)
+	}
+	if err := ValidatePostmortem(strings.Repeat("x", maxPostmortemLen+1)); err == nil {
+		t.Error("expected error for long postmortem")
+	}
+	if err := ValidateIncidentNote("Rolled back deploy 412"); err != nil {
+		t.Errorf("note: %v", err)
+	}
+	for _, msg := range []string{"", "   ", strings.Repeat("x", maxIncidentNoteLen+1)} {
+		if err := ValidateIncidentNote(msg); err == nil {
+			t.Errorf("ValidateIncidentNote(%d chars): expected error", len(msg))
+		}
+	}
+}
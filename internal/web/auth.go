@@ -61,6 +61,14 @@ func (h *Handler) LoginPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Scope is only enforced on the monitor API, so scoped keys cannot use
+	// the dashboard.
+	if apiKey.Scoped() {
+		h.auditLogin("login_scoped_key", apiKey.Name, ip)
+		h.renderComponent(w, r, views.LoginPage(views.LoginParams{BasePath: h.cfg.Server.BasePath, Error: "This API key is scoped to groups or tags and can only be used with the API"}))
+		return
+	}
+
 	if apiKey.TOTP {
 		_, err := h.store.GetTOTPKey(r.Context(), apiKey.Name)
 		if err != nil {
@@ -306,7 +314,7 @@ func (h *Handler) RequireAuth(next http.Handler) http.Handler {
 		}
 
 		apiKey := h.cfg.LookupAPIKeyByName(sess.APIKeyName)
-		if apiKey == nil {
+		if apiKey == nil || apiKey.Scoped() {
 			if err := h.store.DeleteSession(r.Context(), tokenHash); err != nil {
 				h.logger.Error("web: delete orphaned session", "error", err)
 			}
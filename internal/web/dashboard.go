@@ -50,6 +50,7 @@ func (h *Handler) Dashboard(w http.ResponseWriter, r *http.Request) {
 
 	now := time.Now().UTC()
 	requests24h, visitors24h := h.loadRequestStats(ctx, now)
+	groups := h.loadGroupRollups(ctx)
 
 	lp := h.newLayoutParams(r, "Dashboard", "dashboard")
 	h.renderComponent(w, r, views.DashboardPage(views.DashboardParams{
@@ -69,9 +70,29 @@ func (h *Handler) Dashboard(w http.ResponseWriter, r *http.Request) {
 		Page:          page,
 		TotalPages:    totalPages,
 		Filter:        typeFilter,
+		Groups:        groups,
 	}))
 }
 
+// loadGroupRollups returns every monitor group with its status rollup.
+func (h *Handler) loadGroupRollups(ctx context.Context) []views.GroupRollup {
+	groups, err := h.store.ListMonitorGroups(ctx)
+	if err != nil {
+		h.logger.Error("web: list groups", "error", err)
+		return nil
+	}
+	rollups := make([]views.GroupRollup, 0, len(groups))
+	for _, g := range groups {
+		gs, err := h.store.GetGroupStatus(ctx, g.ID)
+		if err != nil {
+			h.logger.Error("web: get group status", "group_id", g.ID, "error", err)
+			continue
+		}
+		rollups = append(rollups, views.GroupRollup{Group: g, Status: gs})
+	}
+	return rollups
+}
+
 func (h *Handler) loadAllMonitors(ctx context.Context) []*storage.Monitor {
 	result, err := h.store.ListMonitors(ctx, storage.MonitorListFilter{}, storage.Pagination{Page: 1, PerPage: 1000})
 	if err != nil {
@@ -40,11 +40,13 @@ func (h *Handler) ExportConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Content-Disposition", `attachment; filename="asura-export.json"`)
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	enc.Encode(data)
+	format := "json"
+	if r.URL.Query().Get("format") == "yaml" {
+		format = "yaml"
+	}
+	if err := api.WriteExport(w, data, "asura-export", format); err != nil {
+		h.logger.Error("web: export", "error", err)
+	}
 }
 
 func (h *Handler) ImportConfig(w http.ResponseWriter, r *http.Request) {
@@ -65,7 +67,7 @@ func (h *Handler) ImportConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, _, err := r.FormFile("file")
+	file, fh, err := r.FormFile("file")
 	if err != nil {
 		h.setFlash(w, "No file uploaded")
 		h.redirect(w, r, "/settings")
@@ -80,6 +82,14 @@ func (h *Handler) ImportConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if api.IsYAMLExport(fh.Header.Get("Content-Type"), fh.Filename, body) {
+		if body, err = api.ExportYAMLToJSON(body); err != nil {
+			h.setFlash(w, "Invalid YAML file")
+			h.redirect(w, r, "/settings")
+			return
+		}
+	}
+
 	var data api.ExportData
 	if err := json.Unmarshal(body, &data); err != nil {
 		h.setFlash(w, "Invalid JSON file")
@@ -3,6 +3,7 @@ package web
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/y0f/asura/internal/httputil"
 	"github.com/y0f/asura/internal/storage"
@@ -50,6 +51,57 @@ func (h *Handler) GroupDetail(w http.ResponseWriter, r *http.Request) {
 	}))
 }
 
+func (h *Handler) GroupSLA(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		h.redirect(w, r, "/groups")
+		return
+	}
+
+	ctx := r.Context()
+	group, err := h.store.GetMonitorGroup(ctx, id)
+	if err != nil {
+		h.redirect(w, r, "/groups")
+		return
+	}
+
+	from, to := httputil.PreviousMonth(time.Now())
+	to = to.AddDate(0, 0, -1)
+	if d, err := time.Parse("2006-01-02", r.URL.Query().Get("from")); err == nil {
+		from = d
+	}
+	if d, err := time.Parse("2006-01-02", r.URL.Query().Get("to")); err == nil {
+		to = d
+	}
+	if to.Before(from) {
+		from, to = to, from
+	}
+
+	lp := h.newLayoutParams(r, "SLA report: "+group.Name, "groups")
+	rep, err := h.store.GetGroupSLAReport(ctx, id, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		h.logger.Error("web: get group sla report", "error", err)
+		rep = &storage.GroupSLAReport{GroupID: id, NoData: true}
+		lp.Error = "Failed to generate the SLA report."
+	}
+	names := make(map[int64]string)
+	if result, err := h.store.ListMonitors(ctx, storage.MonitorListFilter{GroupID: &id}, storage.Pagination{Page: 1, PerPage: 10000}); err == nil {
+		monitors, _ := result.Data.([]*storage.Monitor)
+		for _, m := range monitors {
+			names[m.ID] = m.Name
+		}
+	}
+
+	h.renderComponent(w, r, views.GroupSLAPage(views.GroupSLAParams{
+		LayoutParams: lp,
+		Group:        group,
+		Report:       rep,
+		MonitorNames: names,
+		FromDate:     from.Format("2006-01-02"),
+		ToDate:       to.Format("2006-01-02"),
+	}))
+}
+
 func (h *Handler) GroupCreate(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
 	g := &storage.MonitorGroup{
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,13 +31,41 @@ func (h *Handler) Incidents(w http.ResponseWriter, r *http.Request) {
 		status = ""
 	}
 	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	lp := h.newLayoutParams(r, "Incidents", "incidents")
+
+	if r.URL.Query().Get("group") == "cause" {
+		now := time.Now().UTC()
+		timeRange := r.URL.Query().Get("range")
+		var from time.Time
+		switch timeRange {
+		case "24h":
+			from = now.Add(-24 * time.Hour)
+		case "30d":
+			from = now.AddDate(0, 0, -30)
+		default:
+			timeRange = "7d"
+			from = now.AddDate(0, 0, -7)
+		}
+		groups, err := h.store.GroupIncidentsByCause(r.Context(), 0, status, q, from)
+		if err != nil {
+			h.logger.Error("web: group incidents by cause", "error", err)
+		}
+		h.renderComponent(w, r, views.IncidentListPage(views.IncidentListParams{
+			LayoutParams: lp,
+			Filter:       status,
+			Search:       q,
+			GroupBy:      "cause",
+			TimeRange:    timeRange,
+			Groups:       groups,
+		}))
+		return
+	}
 
 	result, err := h.store.ListIncidents(r.Context(), 0, status, q, p)
 	if err != nil {
 		h.logger.Error("web: list incidents", "error", err)
 	}
 
-	lp := h.newLayoutParams(r, "Incidents", "incidents")
 	h.renderComponent(w, r, views.IncidentListPage(views.IncidentListParams{
 		LayoutParams: lp,
 		Result:       result,
@@ -76,6 +105,19 @@ func (h *Handler) IncidentAck(w http.ResponseWriter, r *http.Request) {
 	}
 	ctx := r.Context()
 
+	timeout := 0
+	if v := r.FormValue("ack_timeout_minutes"); v != "" {
+		timeout, err = strconv.Atoi(v)
+		if err == nil {
+			err = validate.ValidateAckTimeout(timeout)
+		}
+		if err != nil {
+			h.setFlash(w, "Invalid acknowledgement timeout")
+			h.redirect(w, r, "/incidents/"+r.PathValue("id"))
+			return
+		}
+	}
+
 	inc, err := h.store.GetIncident(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -91,6 +133,7 @@ func (h *Handler) IncidentAck(w http.ResponseWriter, r *http.Request) {
 	inc.Status = incident.StatusAcknowledged
 	inc.AcknowledgedAt = &now
 	inc.AcknowledgedBy = httputil.GetAPIKeyName(ctx)
+	inc.AckDeadline = incident.AckDeadline(now, timeout)
 
 	if err := h.store.UpdateIncident(ctx, inc); err != nil {
 		h.logger.Error("web: ack incident", "error", err)
@@ -99,7 +142,7 @@ func (h *Handler) IncidentAck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.store.InsertIncidentEvent(ctx, newIncidentEvent(inc.ID, incident.EventAcknowledged, "Acknowledged by "+inc.AcknowledgedBy)); err != nil {
+	if err := h.store.InsertIncidentEvent(ctx, newIncidentEvent(inc.ID, incident.EventAcknowledged, incident.AckMessage(inc.AcknowledgedBy, timeout))); err != nil {
 		h.logger.Error("web: insert ack event", "error", err)
 	}
 
@@ -132,6 +175,7 @@ func (h *Handler) IncidentResolve(w http.ResponseWriter, r *http.Request) {
 	inc.Status = incident.StatusResolved
 	inc.ResolvedAt = &now
 	inc.ResolvedBy = httputil.GetAPIKeyName(ctx)
+	inc.AckDeadline = nil
 
 	if err := h.store.UpdateIncident(ctx, inc); err != nil {
 		h.logger.Error("web: resolve incident", "error", err)
@@ -167,3 +211,65 @@ func (h *Handler) IncidentDelete(w http.ResponseWriter, r *http.Request) {
 	h.setFlash(w, "Incident deleted")
 	h.redirect(w, r, "/incidents")
 }
+
+func (h *Handler) IncidentPostmortem(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		h.redirect(w, r, "/incidents")
+		return
+	}
+	back := "/incidents/" + r.PathValue("id")
+
+	text := strings.TrimSpace(r.FormValue("postmortem"))
+	if err := validate.ValidatePostmortem(text); err != nil {
+		h.setFlash(w, err.Error())
+		h.redirect(w, r, back)
+		return
+	}
+	if err := h.store.SetIncidentPostmortem(r.Context(), id, text); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			h.redirect(w, r, "/incidents")
+			return
+		}
+		h.logger.Error("web: set incident postmortem", "error", err)
+		h.setFlash(w, "Failed to save postmortem")
+		h.redirect(w, r, back)
+		return
+	}
+
+	h.setFlash(w, "Postmortem saved")
+	h.redirect(w, r, back)
+}
+
+func (h *Handler) IncidentNote(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		h.redirect(w, r, "/incidents")
+		return
+	}
+	back := "/incidents/" + r.PathValue("id")
+	ctx := r.Context()
+
+	msg := strings.TrimSpace(r.FormValue("message"))
+	if err := validate.ValidateIncidentNote(msg); err != nil {
+		h.setFlash(w, "Note "+err.Error())
+		h.redirect(w, r, back)
+		return
+	}
+	if _, err := h.store.GetIncident(ctx, id); err != nil {
+		h.redirect(w, r, "/incidents")
+		return
+	}
+
+	e := newIncidentEvent(id, incident.EventNote, msg)
+	e.Author = httputil.GetAPIKeyName(ctx)
+	if err := h.store.InsertIncidentEvent(ctx, e); err != nil {
+		h.logger.Error("web: insert incident note", "error", err)
+		h.setFlash(w, "Failed to add note")
+		h.redirect(w, r, back)
+		return
+	}
+
+	h.setFlash(w, "Note added")
+	h.redirect(w, r, back)
+}
@@ -18,10 +18,19 @@ func (h *Handler) Maintenance(w http.ResponseWriter, r *http.Request) {
 		h.logger.Error("web: list maintenance", "error", err)
 	}
 
+	now := time.Now()
+	next := make(map[int64]views.MaintenanceOccurrence, len(windows))
+	for _, mw := range windows {
+		if start, end, ok := storage.NextMaintenanceOccurrence(mw, now); ok {
+			next[mw.ID] = views.MaintenanceOccurrence{Start: start, End: end, Active: !start.After(now)}
+		}
+	}
+
 	lp := h.newLayoutParams(r, "Maintenance", "maintenance")
 	h.renderComponent(w, r, views.MaintenanceListPage(views.MaintenanceListParams{
 		LayoutParams: lp,
 		Windows:      windows,
+		Next:         next,
 	}))
 }
 
@@ -81,14 +90,19 @@ func (h *Handler) MaintenanceDelete(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) parseMaintenanceForm(r *http.Request) *storage.MaintenanceWindow {
 	r.ParseForm()
 
-	startTime, _ := time.Parse("2006-01-02T15:04", r.FormValue("start_time"))
-	endTime, _ := time.Parse("2006-01-02T15:04", r.FormValue("end_time"))
-
 	mw := &storage.MaintenanceWindow{
 		Name:      r.FormValue("name"),
-		StartTime: startTime,
-		EndTime:   endTime,
 		Recurring: r.FormValue("recurring"),
+		Timezone:  strings.TrimSpace(r.FormValue("timezone")),
+	}
+	if mw.Recurring == "cron" {
+		mw.CronExpr = strings.TrimSpace(r.FormValue("cron_expr"))
+		mw.DurationMinutes, _ = strconv.Atoi(r.FormValue("duration_minutes"))
+	} else {
+		// The form's times are wall-clock times in the window's timezone.
+		loc := mw.Location()
+		mw.StartTime, _ = time.ParseInLocation("2006-01-02T15:04", r.FormValue("start_time"), loc)
+		mw.EndTime, _ = time.ParseInLocation("2006-01-02T15:04", r.FormValue("end_time"), loc)
 	}
 
 	if ids := r.FormValue("monitor_ids"); ids != "" {
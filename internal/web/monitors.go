@@ -1,7 +1,11 @@
 package web
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -9,6 +13,7 @@ import (
 
 	"github.com/y0f/asura/internal/assertion"
 	"github.com/y0f/asura/internal/httputil"
+	"github.com/y0f/asura/internal/monitor"
 	"github.com/y0f/asura/internal/storage"
 	"github.com/y0f/asura/internal/validate"
 	"github.com/y0f/asura/internal/web/views"
@@ -29,6 +34,7 @@ func monitorToFormData(mon *storage.Monitor) *views.MonitorFormParams {
 		fd.HTTP.BodyEncoding = "json"
 		fd.HeadersJSON = "[]"
 		fd.WsHeadersJSON = "[]"
+		fd.WsStepsJSON = "[]"
 		fd.AssertionsJSON = `{"operator":"and","groups":[]}`
 		fd.SettingsJSON = "{}"
 		fd.AssertionsRaw = "{}"
@@ -49,21 +55,31 @@ func monitorToFormData(mon *storage.Monitor) *views.MonitorFormParams {
 
 	fd.HeadersJSON = headersToJSON(fd.HTTP.Headers)
 	fd.WsHeadersJSON = headersToJSON(fd.WS.Headers)
+	fd.WsStepsJSON = wsStepsToJSON(fd.WS.Steps)
 	fd.AssertionsJSON = assertionsToJSON(mon.Assertions)
 	return fd
 }
 
 var _settingsTargets = map[string]func(*views.MonitorFormParams) any{
-	"http":      func(fd *views.MonitorFormParams) any { return &fd.HTTP },
-	"tcp":       func(fd *views.MonitorFormParams) any { return &fd.TCP },
-	"dns":       func(fd *views.MonitorFormParams) any { return &fd.DNS },
-	"tls":       func(fd *views.MonitorFormParams) any { return &fd.TLS },
-	"websocket": func(fd *views.MonitorFormParams) any { return &fd.WS },
-	"command":   func(fd *views.MonitorFormParams) any { return &fd.Cmd },
-	"docker":    func(fd *views.MonitorFormParams) any { return &fd.Docker },
-	"domain":    func(fd *views.MonitorFormParams) any { return &fd.Domain },
-	"grpc":      func(fd *views.MonitorFormParams) any { return &fd.GRPC },
-	"mqtt":      func(fd *views.MonitorFormParams) any { return &fd.MQTT },
+	"http":        func(fd *views.MonitorFormParams) any { return &fd.HTTP },
+	"tcp":         func(fd *views.MonitorFormParams) any { return &fd.TCP },
+	"dns":         func(fd *views.MonitorFormParams) any { return &fd.DNS },
+	"tls":         func(fd *views.MonitorFormParams) any { return &fd.TLS },
+	"websocket":   func(fd *views.MonitorFormParams) any { return &fd.WS },
+	"command":     func(fd *views.MonitorFormParams) any { return &fd.Cmd },
+	"docker":      func(fd *views.MonitorFormParams) any { return &fd.Docker },
+	"domain":      func(fd *views.MonitorFormParams) any { return &fd.Domain },
+	"grpc":        func(fd *views.MonitorFormParams) any { return &fd.GRPC },
+	"mqtt":        func(fd *views.MonitorFormParams) any { return &fd.MQTT },
+	"amqp":        func(fd *views.MonitorFormParams) any { return &fd.AMQP },
+	"s3":          func(fd *views.MonitorFormParams) any { return &fd.S3 },
+	"smtp":        func(fd *views.MonitorFormParams) any { return &fd.SMTP },
+	"redis":       func(fd *views.MonitorFormParams) any { return &fd.Redis },
+	"ssh":         func(fd *views.MonitorFormParams) any { return &fd.SSH },
+	"ntp":         func(fd *views.MonitorFormParams) any { return &fd.NTP },
+	"icmp":        func(fd *views.MonitorFormParams) any { return &fd.ICMP },
+	"kafka":       func(fd *views.MonitorFormParams) any { return &fd.Kafka },
+	"transaction": func(fd *views.MonitorFormParams) any { return &fd.Transaction },
 }
 
 func unmarshalMonitorSettings(fd *views.MonitorFormParams, mon *storage.Monitor) {
@@ -108,6 +124,17 @@ func headersToJSON(headers map[string]string) string {
 	return views.ToJSON(pairs)
 }
 
+func wsStepsToJSON(steps []storage.WebSocketStep) string {
+	rows := make([]storage.WebSocketStep, len(steps))
+	for i, step := range steps {
+		rows[i] = step
+		if rows[i].Match == "" {
+			rows[i].Match = storage.WSMatchContains
+		}
+	}
+	return views.ToJSON(rows)
+}
+
 func assertionsToJSON(raw json.RawMessage) string {
 	var cs assertion.ConditionSet
 	if len(raw) > 0 {
@@ -128,21 +155,40 @@ var _settingsAssemblers = map[string]func(*http.Request) json.RawMessage{
 		return b
 	},
 	"tcp": func(r *http.Request) json.RawMessage {
+		bannerTimeout, _ := strconv.Atoi(r.FormValue("settings_banner_timeout_ms"))
 		b, _ := json.Marshal(storage.TCPSettings{
-			SendData:   r.FormValue("settings_send_data"),
-			ExpectData: r.FormValue("settings_expect_data"),
+			SendData:        r.FormValue("settings_send_data"),
+			ExpectData:      r.FormValue("settings_expect_data"),
+			ExpectRegex:     r.FormValue("settings_expect_regex"),
+			Mode:            r.FormValue("settings_tcp_mode"),
+			BannerTimeoutMs: bannerTimeout,
 		})
 		return b
 	},
 	"dns": func(r *http.Request) json.RawMessage {
-		b, _ := json.Marshal(storage.DNSSettings{
-			RecordType: r.FormValue("settings_record_type"),
-			Server:     r.FormValue("settings_dns_server"),
-		})
+		s := storage.DNSSettings{
+			RecordType:     r.FormValue("settings_record_type"),
+			Server:         strings.TrimSpace(r.FormValue("settings_dns_server")),
+			DoHURL:         strings.TrimSpace(r.FormValue("settings_dns_doh_url")),
+			ValidateDNSSEC: r.FormValue("settings_dns_validate_dnssec") == "on",
+		}
+		for _, v := range strings.Split(r.FormValue("settings_dns_expected_values"), "\n") {
+			if trimmed := strings.TrimSpace(v); trimmed != "" {
+				s.ExpectedValues = append(s.ExpectedValues, trimmed)
+			}
+		}
+		if len(s.ExpectedValues) > 0 {
+			s.MatchMode = r.FormValue("settings_dns_match_mode")
+		}
+		b, _ := json.Marshal(s)
 		return b
 	},
 	"tls": func(r *http.Request) json.RawMessage {
-		s := storage.TLSSettings{}
+		s := storage.TLSSettings{
+			ExpectedIssuer:    strings.TrimSpace(r.FormValue("settings_tls_expected_issuer")),
+			ExpectedCN:        strings.TrimSpace(r.FormValue("settings_tls_expected_cn")),
+			RequireChainValid: r.FormValue("settings_tls_require_chain_valid") == "on",
+		}
 		if v := r.FormValue("settings_warn_days_before"); v != "" {
 			s.WarnDaysBefore, _ = strconv.Atoi(v)
 		}
@@ -153,7 +199,9 @@ var _settingsAssemblers = map[string]func(*http.Request) json.RawMessage{
 		b, _ := json.Marshal(storage.WebSocketSettings{
 			SendMessage: r.FormValue("settings_send_message"),
 			ExpectReply: r.FormValue("settings_expect_reply"),
+			Subprotocol: strings.TrimSpace(r.FormValue("settings_ws_subprotocol")),
 			Headers:     assembleHeaders(r, "settings_ws_header_key", "settings_ws_header_value"),
+			Steps:       assembleWSSteps(r),
 		})
 		return b
 	},
@@ -175,24 +223,133 @@ var _settingsAssemblers = map[string]func(*http.Request) json.RawMessage{
 		return b
 	},
 	"grpc": func(r *http.Request) json.RawMessage {
+		var request json.RawMessage
+		if v := strings.TrimSpace(r.FormValue("settings_grpc_request_json")); v != "" {
+			request = json.RawMessage(v)
+			if !json.Valid(request) {
+				// Keep invalid input as a string so validation reports it.
+				request, _ = json.Marshal(v)
+			}
+		}
 		b, _ := json.Marshal(storage.GRPCSettings{
 			ServiceName:   r.FormValue("settings_grpc_service"),
 			UseTLS:        r.FormValue("settings_grpc_tls") == "on",
 			SkipTLSVerify: r.FormValue("settings_grpc_skip_verify") == "on",
+			Mode:          r.FormValue("settings_grpc_mode"),
+			Method:        strings.TrimSpace(r.FormValue("settings_grpc_method")),
+			RequestBase64: strings.TrimSpace(r.FormValue("settings_grpc_request")),
+			Request:       request,
 		})
 		return b
 	},
 	"mqtt": func(r *http.Request) json.RawMessage {
+		qos, _ := strconv.Atoi(r.FormValue("settings_mqtt_qos"))
 		b, _ := json.Marshal(storage.MQTTSettings{
 			ClientID:      r.FormValue("settings_mqtt_client_id"),
 			Username:      r.FormValue("settings_mqtt_username"),
 			Password:      r.FormValue("settings_mqtt_password"),
 			Topic:         r.FormValue("settings_mqtt_topic"),
 			ExpectMessage: r.FormValue("settings_mqtt_expect"),
+			ExpectRegex:   r.FormValue("settings_mqtt_expect_regex"),
+			QoS:           qos,
+			WaitRetained:  r.FormValue("settings_mqtt_wait_retained") == "on",
 			UseTLS:        r.FormValue("settings_mqtt_tls") == "on",
 		})
 		return b
 	},
+	"amqp": func(r *http.Request) json.RawMessage {
+		warn, _ := strconv.ParseInt(r.FormValue("settings_amqp_warn_depth"), 10, 64)
+		crit, _ := strconv.ParseInt(r.FormValue("settings_amqp_crit_depth"), 10, 64)
+		b, _ := json.Marshal(storage.AMQPSettings{
+			VHost:         strings.TrimSpace(r.FormValue("settings_amqp_vhost")),
+			Queue:         strings.TrimSpace(r.FormValue("settings_amqp_queue")),
+			Username:      r.FormValue("settings_amqp_username"),
+			Password:      r.FormValue("settings_amqp_password"),
+			WarnDepth:     warn,
+			CritDepth:     crit,
+			SkipTLSVerify: r.FormValue("settings_amqp_skip_verify") == "on",
+		})
+		return b
+	},
+	"s3": func(r *http.Request) json.RawMessage {
+		maxAge, _ := strconv.ParseInt(r.FormValue("settings_s3_max_age_seconds"), 10, 64)
+		b, _ := json.Marshal(storage.S3Settings{
+			Region:          strings.TrimSpace(r.FormValue("settings_s3_region")),
+			Bucket:          strings.TrimSpace(r.FormValue("settings_s3_bucket")),
+			Key:             strings.TrimSpace(r.FormValue("settings_s3_key")),
+			AccessKeyID:     strings.TrimSpace(r.FormValue("settings_s3_access_key_id")),
+			SecretAccessKey: r.FormValue("settings_s3_secret_access_key"),
+			MaxAgeSeconds:   maxAge,
+			VirtualHosted:   r.FormValue("settings_s3_virtual_hosted") == "on",
+			SkipTLSVerify:   r.FormValue("settings_s3_skip_verify") == "on",
+		})
+		return b
+	},
+	"smtp": func(r *http.Request) json.RawMessage {
+		b, _ := json.Marshal(storage.SMTPSettings{
+			StartTLS:       r.FormValue("settings_smtp_starttls") == "on",
+			ExpectGreeting: r.FormValue("settings_smtp_expect_greeting"),
+			Username:       r.FormValue("settings_smtp_username"),
+			Password:       r.FormValue("settings_smtp_password"),
+			SendTestMail:   r.FormValue("settings_smtp_send_test_mail") == "on",
+			MailFrom:       strings.TrimSpace(r.FormValue("settings_smtp_mail_from")),
+			MailTo:         strings.TrimSpace(r.FormValue("settings_smtp_mail_to")),
+			SkipTLSVerify:  r.FormValue("settings_smtp_skip_verify") == "on",
+		})
+		return b
+	},
+	"redis": func(r *http.Request) json.RawMessage {
+		db, _ := strconv.Atoi(r.FormValue("settings_redis_db"))
+		b, _ := json.Marshal(storage.RedisSettings{
+			Username: r.FormValue("settings_redis_username"),
+			Password: r.FormValue("settings_redis_password"),
+			DB:       db,
+			UseTLS:   r.FormValue("settings_redis_tls") == "on",
+			Command:  strings.TrimSpace(r.FormValue("settings_redis_command")),
+		})
+		return b
+	},
+	"ssh": func(r *http.Request) json.RawMessage {
+		b, _ := json.Marshal(storage.SSHSettings{
+			ExpectBanner:        r.FormValue("settings_ssh_expect_banner"),
+			ExpectedFingerprint: strings.TrimSpace(r.FormValue("settings_ssh_expected_fingerprint")),
+			BannerOnly:          r.FormValue("settings_ssh_banner_only") == "on",
+		})
+		return b
+	},
+	"ntp": func(r *http.Request) json.RawMessage {
+		maxOffset, _ := strconv.Atoi(r.FormValue("settings_ntp_max_offset_ms"))
+		critOffset, _ := strconv.Atoi(r.FormValue("settings_ntp_crit_offset_ms"))
+		maxStratum, _ := strconv.Atoi(r.FormValue("settings_ntp_max_stratum"))
+		b, _ := json.Marshal(storage.NTPSettings{
+			MaxOffsetMs:  maxOffset,
+			CritOffsetMs: critOffset,
+			MaxStratum:   maxStratum,
+		})
+		return b
+	},
+	"icmp": func(r *http.Request) json.RawMessage {
+		count, _ := strconv.Atoi(r.FormValue("settings_icmp_count"))
+		interval, _ := strconv.Atoi(r.FormValue("settings_icmp_interval_ms"))
+		maxLoss, _ := strconv.Atoi(r.FormValue("settings_icmp_max_loss_percent"))
+		b, _ := json.Marshal(storage.ICMPSettings{
+			Count:          count,
+			IntervalMs:     interval,
+			MaxLossPercent: maxLoss,
+		})
+		return b
+	},
+	"kafka": func(r *http.Request) json.RawMessage {
+		minBrokers, _ := strconv.Atoi(r.FormValue("settings_kafka_min_brokers"))
+		b, _ := json.Marshal(storage.KafkaSettings{
+			MinBrokers: minBrokers,
+			UseTLS:     r.FormValue("settings_kafka_tls") == "on",
+			SASLUser:   strings.TrimSpace(r.FormValue("settings_kafka_sasl_username")),
+			SASLPass:   r.FormValue("settings_kafka_sasl_password"),
+		})
+		return b
+	},
+	"transaction": assembleTransactionSettings,
 }
 
 func assembleSettings(r *http.Request, monType string) json.RawMessage {
@@ -202,8 +359,24 @@ func assembleSettings(r *http.Request, monType string) json.RawMessage {
 	return nil
 }
 
+// assembleTransactionSettings reads the steps JSON editor. Input that does
+// not decode as a list of steps is passed through so validation reports it.
+func assembleTransactionSettings(r *http.Request) json.RawMessage {
+	s := storage.TransactionSettings{SkipTLSVerify: r.FormValue("settings_transaction_skip_tls_verify") == "on"}
+	if raw := strings.TrimSpace(r.FormValue("settings_transaction_steps")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &s.Steps); err != nil {
+			return json.RawMessage(raw)
+		}
+	}
+	b, _ := json.Marshal(s)
+	return b
+}
+
 func assembleCommandSettings(r *http.Request) json.RawMessage {
-	s := storage.CommandSettings{Command: r.FormValue("settings_command")}
+	s := storage.CommandSettings{
+		Command:   r.FormValue("settings_command"),
+		ParseJSON: r.FormValue("settings_parse_json") == "on",
+	}
 	if argsStr := strings.TrimSpace(r.FormValue("settings_args")); argsStr != "" {
 		for _, a := range strings.Split(argsStr, ",") {
 			if trimmed := strings.TrimSpace(a); trimmed != "" {
@@ -217,13 +390,11 @@ func assembleCommandSettings(r *http.Request) json.RawMessage {
 
 func assembleHTTPSettings(r *http.Request) storage.HTTPSettings {
 	s := storage.HTTPSettings{
-		Method:       r.FormValue("settings_method"),
-		Body:         r.FormValue("settings_body"),
-		BodyEncoding: r.FormValue("settings_body_encoding"),
-		AuthMethod:   r.FormValue("settings_auth_method"),
-	}
-	if v := r.FormValue("settings_expected_status"); v != "" {
-		s.ExpectedStatus, _ = strconv.Atoi(v)
+		Method:         r.FormValue("settings_method"),
+		Body:           r.FormValue("settings_body"),
+		BodyEncoding:   r.FormValue("settings_body_encoding"),
+		AuthMethod:     r.FormValue("settings_auth_method"),
+		ExpectedStatus: storage.StatusSpec(strings.TrimSpace(r.FormValue("settings_expected_status"))),
 	}
 	if v := r.FormValue("settings_max_redirects"); v != "" {
 		s.MaxRedirects, _ = strconv.Atoi(v)
@@ -232,8 +403,15 @@ func assembleHTTPSettings(r *http.Request) storage.HTTPSettings {
 			s.FollowRedirects = &f
 		}
 	}
+	if v := r.FormValue("settings_range_bytes"); v != "" {
+		s.RangeBytes, _ = strconv.Atoi(v)
+	}
+	if v := r.FormValue("settings_max_ttfb_ms"); v != "" {
+		s.MaxTTFBMs, _ = strconv.Atoi(v)
+	}
 	s.SkipTLSVerify = r.FormValue("settings_skip_tls_verify") == "on"
 	s.CacheBuster = r.FormValue("settings_cache_buster") == "on"
+	s.NoDecompress = r.FormValue("settings_no_decompress") == "on"
 	s.Headers = assembleHeaders(r, "settings_header_key", "settings_header_value")
 	switch s.AuthMethod {
 	case "basic":
@@ -242,9 +420,50 @@ func assembleHTTPSettings(r *http.Request) storage.HTTPSettings {
 	case "bearer":
 		s.BearerToken = r.FormValue("settings_bearer_token")
 	}
+	if s.BodyEncoding == "multipart" {
+		if field := strings.TrimSpace(r.FormValue("settings_multipart_file_field")); field != "" {
+			s.MultipartFile = &storage.MultipartFile{
+				Field:       field,
+				Filename:    strings.TrimSpace(r.FormValue("settings_multipart_file_name")),
+				ContentType: strings.TrimSpace(r.FormValue("settings_multipart_file_type")),
+				Content:     r.FormValue("settings_multipart_file_content"),
+			}
+		}
+	}
+	content := storage.ContentSettings{Selector: strings.TrimSpace(r.FormValue("settings_content_selector"))}
+	for _, line := range strings.Split(r.FormValue("settings_content_ignore_patterns"), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			content.IgnorePatterns = append(content.IgnorePatterns, line)
+		}
+	}
+	if content.Selector != "" || len(content.IgnorePatterns) > 0 {
+		s.Content = &content
+	}
 	return s
 }
 
+// assembleWSSteps reads the websocket script rows, skipping empty ones.
+func assembleWSSteps(r *http.Request) []storage.WebSocketStep {
+	sends := r.Form["settings_ws_step_send[]"]
+	expects := r.Form["settings_ws_step_expect[]"]
+	matches := r.Form["settings_ws_step_match[]"]
+	var steps []storage.WebSocketStep
+	for i, send := range sends {
+		step := storage.WebSocketStep{Send: send}
+		if i < len(expects) {
+			step.Expect = expects[i]
+		}
+		if i < len(matches) && matches[i] != storage.WSMatchContains {
+			step.Match = matches[i]
+		}
+		if step.Send == "" && step.Expect == "" {
+			continue
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
 func assembleHeaders(r *http.Request, keyField, valueField string) map[string]string {
 	keys := r.Form[keyField+"[]"]
 	values := r.Form[valueField+"[]"]
@@ -312,7 +531,7 @@ func assembleConditionSet(r *http.Request) json.RawMessage {
 			grp.Conditions = append(grp.Conditions, assertion.Assertion{
 				Type:     aType,
 				Operator: r.FormValue("group_" + gi + "_operator_" + ci),
-				Target:   r.FormValue("group_" + gi + "_target_" + ci),
+				Target:   strings.TrimSpace(r.FormValue("group_" + gi + "_target_" + ci)),
 				Value:    r.FormValue("group_" + gi + "_value_" + ci),
 				Degraded: r.FormValue("group_"+gi+"_degraded_"+ci) == "on",
 			})
@@ -341,12 +560,7 @@ func (h *Handler) Monitors(w http.ResponseWriter, r *http.Request) {
 		typeFilter = ""
 	}
 
-	var tagFilter *int64
-	if v := r.URL.Query().Get("tag"); v != "" {
-		if tid, err := strconv.ParseInt(v, 10, 64); err == nil {
-			tagFilter = &tid
-		}
-	}
+	tagFilter, tagMatch := httputil.ParseTagFilter(r)
 
 	var groupFilter *int64
 	if v := r.URL.Query().Get("group"); v != "" {
@@ -367,7 +581,12 @@ func (h *Handler) Monitors(w http.ResponseWriter, r *http.Request) {
 		sortParam = ""
 	}
 
-	f := storage.MonitorListFilter{Type: typeFilter, Search: q, TagID: tagFilter, GroupID: groupFilter, Status: statusFilter, Sort: sortParam}
+	ownerFilter := strings.TrimSpace(r.URL.Query().Get("owner"))
+
+	f := storage.MonitorListFilter{
+		Type: typeFilter, Search: q, TagIDs: tagFilter, TagMatch: tagMatch, GroupID: groupFilter, Status: statusFilter,
+		Owner: httputil.ResolveOwner(r.Context(), ownerFilter), Sort: sortParam,
+	}
 	result, err := h.store.ListMonitors(r.Context(), f, p)
 	if err != nil {
 		h.logger.Error("web: list monitors", "error", err)
@@ -389,6 +608,10 @@ func (h *Handler) Monitors(w http.ResponseWriter, r *http.Request) {
 
 	groups, _ := h.store.ListMonitorGroups(r.Context())
 	allTags, _ := h.store.ListTags(r.Context())
+	var groupStatus *storage.GroupStatus
+	if groupFilter != nil {
+		groupStatus, _ = h.store.GetGroupStatus(r.Context(), *groupFilter)
+	}
 
 	lp := h.newLayoutParams(r, "Monitors", "monitors")
 	h.renderComponent(w, r, views.MonitorListPage(views.MonitorListParams{
@@ -400,8 +623,11 @@ func (h *Handler) Monitors(w http.ResponseWriter, r *http.Request) {
 		TagMap:        tagMap,
 		AllTags:       allTags,
 		TagFilter:     tagFilter,
+		TagMatch:      tagMatch,
 		GroupFilter:   groupFilter,
+		GroupStatus:   groupStatus,
 		StatusFilter:  statusFilter,
+		OwnerFilter:   ownerFilter,
 		SortParam:     sortParam,
 	}))
 }
@@ -443,10 +669,17 @@ func (h *Handler) MonitorDetail(w http.ResponseWriter, r *http.Request) {
 	uptime7d, _ := h.store.GetUptimePercent(ctx, id, now.Add(-7*24*time.Hour), now)
 	uptime30d, _ := h.store.GetUptimePercent(ctx, id, now.Add(-30*24*time.Hour), now)
 	p50, p95, p99, _ := h.store.GetResponseTimePercentiles(ctx, id, now.Add(-24*time.Hour), now)
+	apdex, _ := h.store.GetApdex(ctx, id, now.Add(-24*time.Hour), now, storage.DefaultApdexThresholdMs, false)
 	totalChecks, upChecks, downChecks, _, _ := h.store.GetCheckCounts(ctx, id, now.Add(-24*time.Hour), now)
 	latestCheck, _ := h.store.GetLatestCheckResult(ctx, id)
 	openIncident, _ := h.store.GetOpenIncident(ctx, id)
 	monTags, _ := h.store.GetMonitorTags(ctx, id)
+	probes, _ := h.store.GetUptimeByProbe(ctx, id, now.Add(-24*time.Hour), now)
+	transitions, _ := h.store.ListMonitorTransitions(ctx, id, 20)
+	var heartbeat *storage.Heartbeat
+	if mon.Type == "heartbeat" {
+		heartbeat, _ = h.store.GetHeartbeatByMonitorID(ctx, id)
+	}
 
 	lp := h.newLayoutParams(r, mon.Name, "monitors")
 	h.renderComponent(w, r, views.MonitorDetailPage(views.MonitorDetailParams{
@@ -462,20 +695,94 @@ func (h *Handler) MonitorDetail(w http.ResponseWriter, r *http.Request) {
 		P50:          p50,
 		P95:          p95,
 		P99:          p99,
+		Apdex:        apdex,
 		TotalChecks:  totalChecks,
 		UpChecks:     upChecks,
 		DownChecks:   downChecks,
 		LatestCheck:  latestCheck,
 		OpenIncident: openIncident,
 		Tags:         monTags,
+		Probes:       probes,
+		Heartbeat:    heartbeat,
+		Transitions:  transitions,
+	}))
+}
+
+// MonitorSLA renders a printable SLA report between two inclusive UTC
+// dates, by default the previous calendar month.
+func (h *Handler) MonitorSLA(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		h.redirect(w, r, "/monitors")
+		return
+	}
+
+	ctx := r.Context()
+	mon, err := h.store.GetMonitor(ctx, id)
+	if err != nil {
+		h.redirect(w, r, "/monitors")
+		return
+	}
+
+	from, to := httputil.PreviousMonth(time.Now())
+	to = to.AddDate(0, 0, -1)
+	if d, err := time.Parse("2006-01-02", r.URL.Query().Get("from")); err == nil {
+		from = d
+	}
+	if d, err := time.Parse("2006-01-02", r.URL.Query().Get("to")); err == nil {
+		to = d
+	}
+	if to.Before(from) {
+		from, to = to, from
+	}
+
+	lp := h.newLayoutParams(r, "SLA report: "+mon.Name, "monitors")
+	rep, err := h.store.GetSLAReport(ctx, id, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		h.logger.Error("web: get sla report", "error", err)
+		rep = &storage.SLAReport{MonitorID: id, NoData: true}
+		lp.Error = "Failed to generate the SLA report."
+	}
+
+	h.renderComponent(w, r, views.MonitorSLAPage(views.MonitorSLAParams{
+		LayoutParams: lp,
+		Monitor:      mon,
+		Report:       rep,
+		FromDate:     from.Format("2006-01-02"),
+		ToDate:       to.Format("2006-01-02"),
 	}))
 }
 
 func (h *Handler) renderMonitorForm(w http.ResponseWriter, r *http.Request, lp views.LayoutParams, fd *views.MonitorFormParams) {
 	fd.LayoutParams = lp
+	fd.EscalationPolicies, _ = h.store.ListEscalationPolicies(r.Context())
+	if all, err := h.store.ListMonitors(r.Context(), storage.MonitorListFilter{}, storage.Pagination{Page: 1, PerPage: 10000}); err == nil {
+		monitors, _ := all.Data.([]*storage.Monitor)
+		for _, m := range monitors {
+			if m.ID != fd.Monitor.ID {
+				fd.ParentMonitors = append(fd.ParentMonitors, m)
+			}
+		}
+	}
 	h.renderComponent(w, r, views.MonitorFormPage(*fd))
 }
 
+// checkMonitorParent validates mon's parent like ValidateMonitor validates
+// its own fields.
+func (h *Handler) checkMonitorParent(ctx context.Context, mon *storage.Monitor) error {
+	if mon.ParentID == nil {
+		return nil
+	}
+	if _, err := h.store.GetMonitor(ctx, *mon.ParentID); err != nil {
+		return fmt.Errorf("parent monitor not found")
+	}
+	parents, err := h.store.ListMonitorParents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check parent monitor")
+	}
+	return validate.ValidateMonitorParent(mon.ID, mon.ParentID, parents)
+}
+
 func (h *Handler) MonitorForm(w http.ResponseWriter, r *http.Request) {
 	lp := h.newLayoutParams(r, "New Monitor", "monitors")
 
@@ -497,6 +804,8 @@ func (h *Handler) MonitorForm(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		lp.Title = "Edit " + mon.Name
+		mon.EscalationPolicyID, _ = h.store.GetMonitorEscalationPolicyID(r.Context(), id)
+		mon.NotificationFilters, _ = h.store.GetMonitorNotificationFilters(r.Context(), id)
 		fd := monitorToFormData(mon)
 		fd.Groups = groups
 		fd.NotificationChannels = channels
@@ -520,7 +829,14 @@ func (h *Handler) MonitorCreate(w http.ResponseWriter, r *http.Request) {
 
 	h.applyMonitorDefaults(mon)
 
-	if err := validate.ValidateMonitor(mon); err != nil {
+	err := validate.ValidateMonitor(mon)
+	if err == nil {
+		err = validate.ValidateNotificationFilters(mon.NotificationFilters, channelIDs)
+	}
+	if err == nil {
+		err = h.checkMonitorParent(r.Context(), mon)
+	}
+	if err != nil {
 		groups, _ := h.store.ListMonitorGroups(r.Context())
 		channels, _ := h.store.ListNotificationChannels(r.Context())
 		proxies, _ := h.store.ListProxies(r.Context())
@@ -538,6 +854,8 @@ func (h *Handler) MonitorCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	monTags, autoTags := h.applyAutoTags(r, mon, monTags)
+
 	if err := h.store.CreateMonitor(r.Context(), mon); err != nil {
 		groups, _ := h.store.ListMonitorGroups(r.Context())
 		channels, _ := h.store.ListNotificationChannels(r.Context())
@@ -545,7 +863,7 @@ func (h *Handler) MonitorCreate(w http.ResponseWriter, r *http.Request) {
 		allTags, _ := h.store.ListTags(r.Context())
 		h.logger.Error("web: create monitor", "error", err)
 		lp := h.newLayoutParams(r, "New Monitor", "monitors")
-		lp.Error = "Failed to create monitor"
+		lp.Error = monitorSaveError(err, "Failed to create monitor")
 		fd := monitorToFormData(mon)
 		fd.Groups = groups
 		fd.NotificationChannels = channels
@@ -560,6 +878,16 @@ func (h *Handler) MonitorCreate(w http.ResponseWriter, r *http.Request) {
 	if len(channelIDs) > 0 {
 		if err := h.store.SetMonitorNotificationChannels(r.Context(), mon.ID, channelIDs); err != nil {
 			h.logger.Error("web: set monitor notification channels", "error", err)
+		} else if len(mon.NotificationFilters) > 0 {
+			if err := h.store.SetMonitorNotificationFilters(r.Context(), mon.ID, mon.NotificationFilters); err != nil {
+				h.logger.Error("web: set monitor notification filters", "error", err)
+			}
+		}
+	}
+
+	if mon.EscalationPolicyID != nil {
+		if err := h.store.SetMonitorEscalationPolicy(r.Context(), mon.ID, mon.EscalationPolicyID); err != nil {
+			h.logger.Error("web: set monitor escalation policy", "error", err)
 		}
 	}
 
@@ -573,10 +901,27 @@ func (h *Handler) MonitorCreate(w http.ResponseWriter, r *http.Request) {
 		h.pipeline.ReloadMonitors()
 	}
 
-	h.setFlash(w, "Monitor created")
+	h.setFlash(w, autoTagFlash("Monitor created", autoTags))
 	h.redirect(w, r, "/monitors")
 }
 
+// applyAutoTags adds the tags of matching monitor.auto_tag_rules to the
+// form's tags and returns the names of the tags it added.
+func (h *Handler) applyAutoTags(r *http.Request, mon *storage.Monitor, tags []storage.MonitorTag) ([]storage.MonitorTag, []string) {
+	tags, applied, err := httputil.ApplyAutoTags(r.Context(), h.store, h.cfg.Monitor.AutoTagRules, mon, tags)
+	if err != nil {
+		h.logger.Error("web: apply auto tags", "error", err)
+	}
+	return tags, applied
+}
+
+func autoTagFlash(msg string, applied []string) string {
+	if len(applied) == 0 {
+		return msg
+	}
+	return msg + " (auto-tagged: " + strings.Join(applied, ", ") + ")"
+}
+
 func (h *Handler) MonitorUpdate(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
@@ -587,7 +932,14 @@ func (h *Handler) MonitorUpdate(w http.ResponseWriter, r *http.Request) {
 	mon, channelIDs, monTags := h.parseMonitorForm(r)
 	mon.ID = id
 
-	if err := validate.ValidateMonitor(mon); err != nil {
+	err = validate.ValidateMonitor(mon)
+	if err == nil {
+		err = validate.ValidateNotificationFilters(mon.NotificationFilters, channelIDs)
+	}
+	if err == nil {
+		err = h.checkMonitorParent(r.Context(), mon)
+	}
+	if err != nil {
 		groups, _ := h.store.ListMonitorGroups(r.Context())
 		channels, _ := h.store.ListNotificationChannels(r.Context())
 		proxies, _ := h.store.ListProxies(r.Context())
@@ -605,6 +957,8 @@ func (h *Handler) MonitorUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	monTags, autoTags := h.applyAutoTags(r, mon, monTags)
+
 	if err := h.store.UpdateMonitor(r.Context(), mon); err != nil {
 		groups, _ := h.store.ListMonitorGroups(r.Context())
 		channels, _ := h.store.ListNotificationChannels(r.Context())
@@ -612,7 +966,7 @@ func (h *Handler) MonitorUpdate(w http.ResponseWriter, r *http.Request) {
 		allTags, _ := h.store.ListTags(r.Context())
 		h.logger.Error("web: update monitor", "error", err)
 		lp := h.newLayoutParams(r, "Edit Monitor", "monitors")
-		lp.Error = "Failed to update monitor"
+		lp.Error = monitorSaveError(err, "Failed to update monitor")
 		fd := monitorToFormData(mon)
 		fd.Groups = groups
 		fd.NotificationChannels = channels
@@ -626,6 +980,12 @@ func (h *Handler) MonitorUpdate(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.store.SetMonitorNotificationChannels(r.Context(), id, channelIDs); err != nil {
 		h.logger.Error("web: set monitor notification channels", "error", err)
+	} else if err := h.store.SetMonitorNotificationFilters(r.Context(), id, mon.NotificationFilters); err != nil {
+		h.logger.Error("web: set monitor notification filters", "error", err)
+	}
+
+	if err := h.store.SetMonitorEscalationPolicy(r.Context(), id, mon.EscalationPolicyID); err != nil {
+		h.logger.Error("web: set monitor escalation policy", "error", err)
 	}
 
 	if err := h.store.SetMonitorTags(r.Context(), id, monTags); err != nil {
@@ -636,7 +996,7 @@ func (h *Handler) MonitorUpdate(w http.ResponseWriter, r *http.Request) {
 		h.pipeline.ReloadMonitors()
 	}
 
-	h.setFlash(w, "Monitor updated")
+	h.setFlash(w, autoTagFlash("Monitor updated", autoTags))
 	h.redirect(w, r, "/monitors/"+strconv.FormatInt(id, 10))
 }
 
@@ -678,6 +1038,54 @@ func (h *Handler) MonitorPause(w http.ResponseWriter, r *http.Request) {
 	h.redirect(w, r, "/monitors/"+strconv.FormatInt(id, 10))
 }
 
+// MonitorCheckNow checks a monitor immediately, outside its schedule.
+func (h *Handler) MonitorCheckNow(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		h.redirect(w, r, "/monitors")
+		return
+	}
+	back := "/monitors/" + strconv.FormatInt(id, 10)
+	if h.pipeline == nil {
+		h.setFlash(w, "Check runner is not available")
+		h.redirect(w, r, back)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.checkNowWait())
+	defer cancel()
+	res := h.pipeline.TriggerNow(ctx, id)[0]
+	switch {
+	case res.Err == nil:
+		msg := fmt.Sprintf("Checked now: %s in %dms", res.Check.Status, res.Check.ResponseTime)
+		if res.Check.Message != "" {
+			msg += " (" + res.Check.Message + ")"
+		}
+		h.setFlash(w, msg)
+	case errors.Is(res.Err, sql.ErrNoRows):
+		h.redirect(w, r, "/monitors")
+		return
+	case errors.Is(res.Err, context.DeadlineExceeded):
+		h.setFlash(w, "Check is still running; its result will appear in the check history")
+	case errors.Is(res.Err, monitor.ErrTriggerHeartbeat), errors.Is(res.Err, monitor.ErrTriggerPaused), errors.Is(res.Err, monitor.ErrTriggerTooSoon),
+		errors.Is(res.Err, monitor.ErrTriggerDropped):
+		h.setFlash(w, "Cannot check now: "+res.Err.Error())
+	default:
+		h.logger.Error("web: check now", "monitor_id", id, "error", res.Err)
+		h.setFlash(w, "Check failed")
+	}
+	h.redirect(w, r, back)
+}
+
+// checkNowWait bounds how long a check-now request waits for results so the
+// redirect is sent before the server's write timeout.
+func (h *Handler) checkNowWait() time.Duration {
+	if wait := h.cfg.Server.WriteTimeout - 2*time.Second; wait > 0 {
+		return wait
+	}
+	return 28 * time.Second
+}
+
 func (h *Handler) MonitorResume(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
@@ -697,6 +1105,49 @@ func (h *Handler) MonitorResume(w http.ResponseWriter, r *http.Request) {
 	h.redirect(w, r, "/monitors/"+strconv.FormatInt(id, 10))
 }
 
+// MonitorHeartbeatPause stops expiry checking for a heartbeat monitor's
+// pings without pausing the monitor itself.
+func (h *Handler) MonitorHeartbeatPause(w http.ResponseWriter, r *http.Request) {
+	h.setHeartbeatPaused(w, r, true)
+}
+
+// MonitorHeartbeatResume restarts expiry checking for a paused heartbeat.
+func (h *Handler) MonitorHeartbeatResume(w http.ResponseWriter, r *http.Request) {
+	h.setHeartbeatPaused(w, r, false)
+}
+
+func (h *Handler) setHeartbeatPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		h.redirect(w, r, "/monitors")
+		return
+	}
+	back := "/monitors/" + strconv.FormatInt(id, 10)
+	hb, err := h.store.GetHeartbeatByMonitorID(r.Context(), id)
+	if err != nil {
+		h.setFlash(w, "Heartbeat not found")
+		h.redirect(w, r, back)
+		return
+	}
+	if paused {
+		err = h.store.PauseHeartbeat(r.Context(), hb.Token)
+	} else {
+		err = h.store.ResumeHeartbeat(r.Context(), hb.Token)
+	}
+	if err != nil {
+		h.logger.Error("web: set heartbeat paused", "error", err)
+		h.setFlash(w, "Failed to update heartbeat")
+		h.redirect(w, r, back)
+		return
+	}
+	if paused {
+		h.setFlash(w, "Heartbeat pings paused")
+	} else {
+		h.setFlash(w, "Heartbeat pings resumed")
+	}
+	h.redirect(w, r, back)
+}
+
 func (h *Handler) MonitorClone(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(r)
 	if err != nil {
@@ -713,27 +1164,42 @@ func (h *Handler) MonitorClone(w http.ResponseWriter, r *http.Request) {
 	}
 
 	clone := &storage.Monitor{
-		Name:             src.Name + " (copy)",
-		Description:      src.Description,
-		Type:             src.Type,
-		Target:           src.Target,
-		Interval:         src.Interval,
-		Timeout:          src.Timeout,
-		Enabled:          false,
-		Settings:         src.Settings,
-		Assertions:       src.Assertions,
-		TrackChanges:     src.TrackChanges,
-		FailureThreshold: src.FailureThreshold,
-		SuccessThreshold: src.SuccessThreshold,
-		UpsideDown:       src.UpsideDown,
-		ResendInterval:   src.ResendInterval,
-		GroupID:          src.GroupID,
-		ProxyID:          src.ProxyID,
+		Name:                  src.Name + " (copy)",
+		Description:           src.Description,
+		Owner:                 src.Owner,
+		Type:                  src.Type,
+		Target:                src.Target,
+		Interval:              src.Interval,
+		Timeout:               src.Timeout,
+		Enabled:               false,
+		Settings:              src.Settings,
+		Assertions:            src.Assertions,
+		TrackChanges:          src.TrackChanges,
+		FailureThreshold:      src.FailureThreshold,
+		SuccessThreshold:      src.SuccessThreshold,
+		UpsideDown:            src.UpsideDown,
+		ResendInterval:        src.ResendInterval,
+		GroupID:               src.GroupID,
+		ProxyID:               src.ProxyID,
+		AckSilencesReminders:  src.AckSilencesReminders,
+		LatencyBaselineSigma:  src.LatencyBaselineSigma,
+		SkipDefaultChannel:    src.SkipDefaultChannel,
+		CaptureFailureContext: src.CaptureFailureContext,
+		StreamChecksEvery:     src.StreamChecksEvery,
+		Retries:               src.Retries,
+		RetryInterval:         src.RetryInterval,
+		RedactPatterns:        src.RedactPatterns,
+		DegradedThreshold:     src.DegradedThreshold,
+		FlapWindowSeconds:     src.FlapWindowSeconds,
+		ParentID:              src.ParentID,
+		ConnectTimeout:        src.ConnectTimeout,
+		MaxResponseTimeMs:     src.MaxResponseTimeMs,
+		WarmupSeconds:         src.WarmupSeconds,
 	}
 
 	if err := h.store.CreateMonitor(ctx, clone); err != nil {
 		h.logger.Error("web: clone monitor", "error", err)
-		h.setFlash(w, "Failed to clone monitor")
+		h.setFlash(w, monitorSaveError(err, "Failed to clone monitor"))
 		h.redirect(w, r, "/monitors/"+strconv.FormatInt(id, 10))
 		return
 	}
@@ -742,6 +1208,22 @@ func (h *Handler) MonitorClone(w http.ResponseWriter, r *http.Request) {
 	if len(channelIDs) > 0 {
 		if err := h.store.SetMonitorNotificationChannels(ctx, clone.ID, channelIDs); err != nil {
 			h.logger.Error("web: clone monitor channels", "error", err)
+		} else if filters, _ := h.store.GetMonitorNotificationFilters(ctx, id); len(filters) > 0 {
+			if err := h.store.SetMonitorNotificationFilters(ctx, clone.ID, filters); err != nil {
+				h.logger.Error("web: clone monitor notification filters", "error", err)
+			}
+		}
+	}
+
+	if policyID, _ := h.store.GetMonitorEscalationPolicyID(ctx, id); policyID != nil {
+		if err := h.store.SetMonitorEscalationPolicy(ctx, clone.ID, policyID); err != nil {
+			h.logger.Error("web: clone monitor escalation policy", "error", err)
+		}
+	}
+
+	if probeIDs, _ := h.store.GetMonitorProbeIDs(ctx, id); len(probeIDs) > 0 {
+		if err := h.store.SetMonitorProbes(ctx, clone.ID, probeIDs); err != nil {
+			h.logger.Error("web: clone monitor probes", "error", err)
 		}
 	}
 
@@ -798,6 +1280,29 @@ func (h *Handler) MonitorBulk(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		msg = strconv.Itoa(len(ids)) + " monitors deleted"
+	case "check":
+		if h.pipeline == nil {
+			h.setFlash(w, "Check runner is not available")
+			h.redirect(w, r, "/monitors")
+			return
+		}
+		if len(ids) > monitor.MaxTriggerBatch {
+			h.setFlash(w, fmt.Sprintf("Select at most %d monitors to check", monitor.MaxTriggerBatch))
+			h.redirect(w, r, "/monitors")
+			return
+		}
+		ctx, cancel := context.WithTimeout(ctx, h.checkNowWait())
+		defer cancel()
+		checked := 0
+		for _, res := range h.pipeline.TriggerNow(ctx, ids...) {
+			if res.Err == nil {
+				checked++
+			}
+		}
+		msg = fmt.Sprintf("%d of %d monitors checked", checked, len(ids))
+		if skipped := len(ids) - checked; skipped > 0 {
+			msg += fmt.Sprintf(" (%d skipped: paused, heartbeat, checked in the last %s, or still running)", skipped, monitor.TriggerCooldown)
+		}
 	case "set_group":
 		var gid *int64
 		if v := r.FormValue("group_id"); v != "" {
@@ -807,7 +1312,7 @@ func (h *Handler) MonitorBulk(w http.ResponseWriter, r *http.Request) {
 		}
 		if _, err := h.store.BulkSetMonitorGroup(ctx, ids, gid); err != nil {
 			h.logger.Error("web: bulk set group", "error", err)
-			h.setFlash(w, "Failed to update monitors")
+			h.setFlash(w, monitorSaveError(err, "Failed to update monitors"))
 			h.redirect(w, r, "/monitors")
 			return
 		}
@@ -825,6 +1330,15 @@ func (h *Handler) MonitorBulk(w http.ResponseWriter, r *http.Request) {
 	h.redirect(w, r, "/monitors")
 }
 
+// monitorSaveError returns the limit error itself when a monitor limit was hit,
+// since it tells the user what to do, and the generic fallback otherwise.
+func monitorSaveError(err error, fallback string) string {
+	if errors.Is(err, storage.ErrMonitorLimit) {
+		return err.Error()
+	}
+	return fallback
+}
+
 func (h *Handler) applyMonitorDefaults(m *storage.Monitor) {
 	if m.Interval == 0 {
 		m.Interval = int(h.cfg.Monitor.DefaultInterval.Seconds())
@@ -854,6 +1368,7 @@ func (h *Handler) parseMonitorForm(r *http.Request) (*storage.Monitor, []int64,
 	mon := &storage.Monitor{
 		Name:             r.FormValue("name"),
 		Description:      r.FormValue("description"),
+		Owner:            strings.TrimSpace(r.FormValue("owner")),
 		Type:             r.FormValue("type"),
 		Target:           r.FormValue("target"),
 		Interval:         interval,
@@ -869,6 +1384,54 @@ func (h *Handler) parseMonitorForm(r *http.Request) (*storage.Monitor, []int64,
 		mon.ResendInterval, _ = strconv.Atoi(v)
 	}
 
+	mon.SkipDefaultChannel = r.FormValue("skip_default_channel") == "on"
+	mon.CaptureFailureContext = r.FormValue("capture_failure_context") == "on"
+
+	if v := r.FormValue("stream_checks_every"); v != "" {
+		mon.StreamChecksEvery, _ = strconv.Atoi(v)
+	}
+
+	if v := r.FormValue("retries"); v != "" {
+		mon.Retries, _ = strconv.Atoi(v)
+	}
+	if v := r.FormValue("retry_interval"); v != "" {
+		mon.RetryInterval, _ = strconv.Atoi(v)
+	}
+	if v := r.FormValue("degraded_threshold"); v != "" {
+		mon.DegradedThreshold, _ = strconv.Atoi(v)
+	}
+	if v := r.FormValue("flap_window_seconds"); v != "" {
+		mon.FlapWindowSeconds, _ = strconv.Atoi(v)
+	}
+	if v := r.FormValue("connect_timeout"); v != "" {
+		mon.ConnectTimeout, _ = strconv.Atoi(v)
+	}
+	if v := r.FormValue("max_response_time_ms"); v != "" {
+		mon.MaxResponseTimeMs, _ = strconv.Atoi(v)
+	}
+	if v := r.FormValue("warmup_seconds"); v != "" {
+		mon.WarmupSeconds, _ = strconv.Atoi(v)
+	}
+
+	for _, line := range strings.Split(r.FormValue("redact_patterns"), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			mon.RedactPatterns = append(mon.RedactPatterns, line)
+		}
+	}
+
+	if v := r.FormValue("latency_baseline_sigma"); v != "" {
+		mon.LatencyBaselineSigma, _ = strconv.ParseFloat(v, 64)
+	}
+
+	switch r.FormValue("ack_silences_reminders") {
+	case "yes":
+		v := true
+		mon.AckSilencesReminders = &v
+	case "no":
+		v := false
+		mon.AckSilencesReminders = &v
+	}
+
 	if v := r.FormValue("group_id"); v != "" {
 		gid, err := strconv.ParseInt(v, 10, 64)
 		if err == nil && gid > 0 {
@@ -883,6 +1446,20 @@ func (h *Handler) parseMonitorForm(r *http.Request) (*storage.Monitor, []int64,
 		}
 	}
 
+	if v := r.FormValue("parent_id"); v != "" {
+		pid, err := strconv.ParseInt(v, 10, 64)
+		if err == nil && pid > 0 {
+			mon.ParentID = &pid
+		}
+	}
+
+	if v := r.FormValue("escalation_policy_id"); v != "" {
+		eid, err := strconv.ParseInt(v, 10, 64)
+		if err == nil && eid > 0 {
+			mon.EscalationPolicyID = &eid
+		}
+	}
+
 	mon.Settings = parseJSONOrForm(r, "settings", func(r *http.Request) json.RawMessage {
 		return assembleSettings(r, mon.Type)
 	})
@@ -901,7 +1478,36 @@ func (h *Handler) parseMonitorForm(r *http.Request) (*storage.Monitor, []int64,
 		monTags = append(monTags, storage.MonitorTag{TagID: tid, Value: val})
 	}
 
-	return mon, parseIDList(r.Form["notification_channel_ids[]"]), monTags
+	channelIDs := parseIDList(r.Form["notification_channel_ids[]"])
+	mon.NotificationFilters = parseNotificationFilters(r, channelIDs)
+
+	return mon, channelIDs, monTags
+}
+
+// parseNotificationFilters reads the per-channel filter fields of the
+// selected channels: a critical-only checkbox and comma-separated tags.
+func parseNotificationFilters(r *http.Request, channelIDs []int64) map[int64]*storage.NotificationFilter {
+	var filters map[int64]*storage.NotificationFilter
+	for _, cid := range channelIDs {
+		id := strconv.FormatInt(cid, 10)
+		f := &storage.NotificationFilter{}
+		if r.FormValue("notification_filter_critical_"+id) == "on" {
+			f.MinSeverity = "critical"
+		}
+		for _, tag := range strings.Split(r.FormValue("notification_filter_tags_"+id), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				f.Tags = append(f.Tags, tag)
+			}
+		}
+		if f.IsZero() {
+			continue
+		}
+		if filters == nil {
+			filters = make(map[int64]*storage.NotificationFilter)
+		}
+		filters[cid] = f
+	}
+	return filters
 }
 
 func parseJSONOrForm(r *http.Request, prefix string, formFn func(*http.Request) json.RawMessage) json.RawMessage {
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -109,8 +110,8 @@ func TestAssembleSettingsHTTP(t *testing.T) {
 	if s.BasicAuthUser != "" {
 		t.Errorf("basic_auth_user should be empty for bearer auth, got %q", s.BasicAuthUser)
 	}
-	if s.ExpectedStatus != 201 {
-		t.Errorf("expected_status = %d", s.ExpectedStatus)
+	if s.ExpectedStatus != "201" {
+		t.Errorf("expected_status = %q", s.ExpectedStatus)
 	}
 	if s.MaxRedirects != 5 {
 		t.Errorf("max_redirects = %d", s.MaxRedirects)
@@ -164,6 +165,63 @@ func TestAssembleSettingsHTTPMaxRedirectsZero(t *testing.T) {
 	}
 }
 
+func TestAssembleSettingsHTTPMultipart(t *testing.T) {
+	form := url.Values{
+		"settings_method":                 {"POST"},
+		"settings_body_encoding":          {"multipart"},
+		"settings_body":                   {"a=1\nb=2"},
+		"settings_multipart_file_field":   {" upload "},
+		"settings_multipart_file_name":    {"probe.txt"},
+		"settings_multipart_file_content": {"hello"},
+	}
+	raw := assembleSettings(buildFormRequest(form), "http")
+
+	var s storage.HTTPSettings
+	json.Unmarshal(raw, &s)
+
+	if s.BodyEncoding != "multipart" || s.Body != "a=1\nb=2" {
+		t.Errorf("body = %q (%s)", s.Body, s.BodyEncoding)
+	}
+	if f := s.MultipartFile; f == nil || f.Field != "upload" || f.Filename != "probe.txt" || f.Content != "hello" {
+		t.Errorf("file part = %+v", f)
+	}
+
+	form.Set("settings_body_encoding", "form")
+	raw = assembleSettings(buildFormRequest(form), "http")
+	s = storage.HTTPSettings{}
+	json.Unmarshal(raw, &s)
+	if s.MultipartFile != nil {
+		t.Errorf("file part should only be kept for multipart, got %+v", s.MultipartFile)
+	}
+}
+
+func TestAssembleSettingsHTTPContentSelector(t *testing.T) {
+	raw := assembleSettings(buildFormRequest(url.Values{"settings_content_selector": {" #price "}}), "http")
+	var s storage.HTTPSettings
+	json.Unmarshal(raw, &s)
+	if s.Content == nil || s.Content.Selector != "#price" {
+		t.Errorf("content = %+v", s.Content)
+	}
+
+	raw = assembleSettings(buildFormRequest(url.Values{"settings_content_selector": {"  "}}), "http")
+	s = storage.HTTPSettings{}
+	json.Unmarshal(raw, &s)
+	if s.Content != nil {
+		t.Errorf("blank selector should leave content unset, got %+v", s.Content)
+	}
+}
+
+func TestAssembleSettingsHTTPIgnorePatterns(t *testing.T) {
+	form := url.Values{"settings_content_ignore_patterns": {"\\d{2}:\\d{2}\r\n\r\ncsrf=\\w+ \n"}}
+	raw := assembleSettings(buildFormRequest(form), "http")
+
+	var s storage.HTTPSettings
+	json.Unmarshal(raw, &s)
+	if s.Content == nil || len(s.Content.IgnorePatterns) != 2 || s.Content.IgnorePatterns[0] != `\d{2}:\d{2}` || s.Content.IgnorePatterns[1] != `csrf=\w+ ` {
+		t.Errorf("content = %+v", s.Content)
+	}
+}
+
 func TestAssembleSettingsTCP(t *testing.T) {
 	form := url.Values{
 		"settings_send_data":   {"PING"},
@@ -196,6 +254,23 @@ func TestAssembleSettingsDNS(t *testing.T) {
 	}
 }
 
+func TestAssembleSettingsDNSExpectedValues(t *testing.T) {
+	form := url.Values{
+		"settings_record_type":         {"A,AAAA"},
+		"settings_dns_expected_values": {"93.184.216.34\r\n\r\n 2606:2800:220:1::1 \n"},
+		"settings_dns_match_mode":      {"all"},
+	}
+	r := buildFormRequest(form)
+	raw := assembleSettings(r, "dns")
+
+	var s storage.DNSSettings
+	json.Unmarshal(raw, &s)
+
+	if len(s.ExpectedValues) != 2 || s.ExpectedValues[1] != "2606:2800:220:1::1" || s.MatchMode != "all" {
+		t.Errorf("dns settings = %+v", s)
+	}
+}
+
 func TestAssembleSettingsTLS(t *testing.T) {
 	form := url.Values{"settings_warn_days_before": {"14"}}
 	r := buildFormRequest(form)
@@ -231,6 +306,53 @@ func TestAssembleSettingsWebSocket(t *testing.T) {
 	}
 }
 
+func TestAssembleSettingsWebSocketSteps(t *testing.T) {
+	form := url.Values{"settings_ws_subprotocol": {" realtime.v1 "}}
+	for _, row := range [][3]string{
+		{`{"type":"auth"}`, `{"type":"ready"}`, "json"},
+		{"", "", "contains"},
+		{"ping", "pong", "contains"},
+	} {
+		form.Add("settings_ws_step_send[]", row[0])
+		form.Add("settings_ws_step_expect[]", row[1])
+		form.Add("settings_ws_step_match[]", row[2])
+	}
+
+	r := buildFormRequest(form)
+	var s storage.WebSocketSettings
+	json.Unmarshal(assembleSettings(r, "websocket"), &s)
+
+	if s.Subprotocol != "realtime.v1" {
+		t.Errorf("subprotocol = %q", s.Subprotocol)
+	}
+	want := []storage.WebSocketStep{
+		{Send: `{"type":"auth"}`, Expect: `{"type":"ready"}`, Match: "json"},
+		{Send: "ping", Expect: "pong"},
+	}
+	if !reflect.DeepEqual(s.Steps, want) {
+		t.Errorf("steps = %+v, want %+v", s.Steps, want)
+	}
+}
+
+func TestAssembleSettingsTransaction(t *testing.T) {
+	form := url.Values{
+		"settings_transaction_steps":           {`[{"method":"POST","url":"/login","extract":[{"var":"token","path":"$.token"}]},{"url":"/me"}]`},
+		"settings_transaction_skip_tls_verify": {"on"},
+	}
+	var s storage.TransactionSettings
+	if err := json.Unmarshal(assembleSettings(buildFormRequest(form), "transaction"), &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Steps) != 2 || s.Steps[0].Extract[0].Var != "token" || s.Steps[1].URL != "/me" || !s.SkipTLSVerify {
+		t.Errorf("transaction settings = %+v", s)
+	}
+
+	raw := assembleSettings(buildFormRequest(url.Values{"settings_transaction_steps": {`[{"url":`}}), "transaction")
+	if string(raw) != `[{"url":` {
+		t.Errorf("invalid steps should pass through for validation, got %s", raw)
+	}
+}
+
 func TestAssembleSettingsCommand(t *testing.T) {
 	form := url.Values{
 		"settings_command": {"/usr/bin/check"},
@@ -250,11 +372,63 @@ func TestAssembleSettingsCommand(t *testing.T) {
 	}
 }
 
+func TestAssembleSettingsNTP(t *testing.T) {
+	form := url.Values{
+		"settings_ntp_max_offset_ms":  {"50"},
+		"settings_ntp_crit_offset_ms": {"500"},
+		"settings_ntp_max_stratum":    {"3"},
+	}
+	r := buildFormRequest(form)
+	raw := assembleSettings(r, "ntp")
+
+	var s storage.NTPSettings
+	json.Unmarshal(raw, &s)
+
+	if s.MaxOffsetMs != 50 || s.CritOffsetMs != 500 || s.MaxStratum != 3 {
+		t.Errorf("ntp settings = %+v", s)
+	}
+}
+
+func TestAssembleSettingsKafka(t *testing.T) {
+	form := url.Values{
+		"settings_kafka_min_brokers":   {"3"},
+		"settings_kafka_tls":           {"on"},
+		"settings_kafka_sasl_username": {" monitor "},
+		"settings_kafka_sasl_password": {"secret"},
+	}
+	r := buildFormRequest(form)
+	raw := assembleSettings(r, "kafka")
+
+	var s storage.KafkaSettings
+	json.Unmarshal(raw, &s)
+
+	if s.MinBrokers != 3 || !s.UseTLS || s.SASLUser != "monitor" || s.SASLPass != "secret" {
+		t.Errorf("kafka settings = %+v", s)
+	}
+}
+
+func TestAssembleSettingsICMP(t *testing.T) {
+	form := url.Values{
+		"settings_icmp_count":            {"5"},
+		"settings_icmp_interval_ms":      {"200"},
+		"settings_icmp_max_loss_percent": {"20"},
+	}
+	r := buildFormRequest(form)
+	raw := assembleSettings(r, "icmp")
+
+	var s storage.ICMPSettings
+	json.Unmarshal(raw, &s)
+
+	if s.Count != 5 || s.IntervalMs != 200 || s.MaxLossPercent != 20 {
+		t.Errorf("icmp settings = %+v", s)
+	}
+}
+
 func TestAssembleSettingsUnknownType(t *testing.T) {
 	r := buildFormRequest(url.Values{})
-	raw := assembleSettings(r, "icmp")
+	raw := assembleSettings(r, "heartbeat")
 	if raw != nil {
-		t.Errorf("expected nil for icmp, got %s", raw)
+		t.Errorf("expected nil for heartbeat, got %s", raw)
 	}
 }
 
@@ -296,6 +470,39 @@ func TestAssembleAssertions(t *testing.T) {
 	}
 }
 
+func TestAssembleAssertionsHeaderRoundTrip(t *testing.T) {
+	form := url.Values{
+		"group_count":            {"1"},
+		"condition_set_operator": {"and"},
+		"group_0_operator":       {"and"},
+		"group_0_count":          {"2"},
+		"group_0_type_0":         {"header"},
+		"group_0_operator_0":     {"contains"},
+		"group_0_target_0":       {" Cache-Control "},
+		"group_0_value_0":        {"no-store"},
+		"group_0_type_1":         {"header"},
+		"group_0_operator_1":     {"exists"},
+		"group_0_target_1":       {"strict-transport-security"},
+	}
+	raw := assembleAssertions(buildFormRequest(form))
+
+	var cs assertion.ConditionSet
+	if err := json.Unmarshal([]byte(assertionsToJSON(raw)), &cs); err != nil {
+		t.Fatal(err)
+	}
+	if len(cs.Groups) != 1 || len(cs.Groups[0].Conditions) != 2 {
+		t.Fatalf("unexpected condition set: %+v", cs)
+	}
+	c0 := cs.Groups[0].Conditions[0]
+	if c0.Type != "header" || c0.Target != "Cache-Control" || c0.Operator != "contains" || c0.Value != "no-store" {
+		t.Errorf("condition[0] = %+v", c0)
+	}
+	c1 := cs.Groups[0].Conditions[1]
+	if c1.Type != "header" || c1.Target != "strict-transport-security" || c1.Operator != "exists" {
+		t.Errorf("condition[1] = %+v", c1)
+	}
+}
+
 func TestAssembleAssertionsEmpty(t *testing.T) {
 	r := buildFormRequest(url.Values{})
 	raw := assembleAssertions(r)
@@ -19,11 +19,21 @@ func (h *Handler) Notifications(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.logger.Error("web: list notifications", "error", err)
 	}
+	tags, err := h.store.ListTags(r.Context())
+	if err != nil {
+		h.logger.Error("web: list tags for notifications", "error", err)
+	}
+	oncall, err := h.store.ListOncallSchedules(r.Context())
+	if err != nil {
+		h.logger.Error("web: list oncall schedules for notifications", "error", err)
+	}
 
 	lp := h.newLayoutParams(r, "Notifications", "notifications")
 	h.renderComponent(w, r, views.NotificationListPage(views.NotificationListParams{
 		LayoutParams: lp,
 		Channels:     channels,
+		Tags:         tags,
+		Oncall:       oncall,
 	}))
 }
 
@@ -42,6 +52,9 @@ func (h *Handler) NotificationCreate(w http.ResponseWriter, r *http.Request) {
 		h.redirect(w, r, "/notifications")
 		return
 	}
+	if err := h.store.SetChannelTags(r.Context(), ch.ID, ch.TagIDs); err != nil {
+		h.logger.Error("web: set channel tags", "error", err)
+	}
 
 	h.setFlash(w, "Notification channel created")
 	h.redirect(w, r, "/notifications")
@@ -68,6 +81,9 @@ func (h *Handler) NotificationUpdate(w http.ResponseWriter, r *http.Request) {
 		h.redirect(w, r, "/notifications")
 		return
 	}
+	if err := h.store.SetChannelTags(r.Context(), ch.ID, ch.TagIDs); err != nil {
+		h.logger.Error("web: set channel tags", "error", err)
+	}
 
 	h.setFlash(w, "Notification channel updated")
 	h.redirect(w, r, "/notifications")
@@ -163,6 +179,20 @@ func (h *Handler) parseNotificationForm(r *http.Request) *storage.NotificationCh
 	}
 
 	ch.Events = parseNotificationEvents(r)
+	ch.TagIDs = parseIDList(r.Form["tag_ids[]"])
+
+	if raw := strings.TrimSpace(r.FormValue("schedule_json")); raw != "" {
+		ch.Schedule = json.RawMessage(raw)
+	}
+	if notifier.SupportsOncall(ch.Type) {
+		if id, err := strconv.ParseInt(r.FormValue("oncall_schedule_id"), 10, 64); err == nil && id > 0 {
+			ch.OncallScheduleID = &id
+		}
+	}
+	if notifier.SupportsDigest(ch.Type) {
+		ch.DigestWindowSeconds, _ = strconv.Atoi(strings.TrimSpace(r.FormValue("digest_window_seconds")))
+		ch.DigestFlushOnResolve = ch.DigestWindowSeconds > 0 && r.FormValue("digest_flush_on_resolve") == "on"
+	}
 
 	return ch
 }
@@ -176,6 +206,12 @@ func parseNotificationEvents(r *http.Request) []string {
 		"event_incident_reminder",
 		"event_content_changed",
 		"event_cert_changed",
+		"event_latency_anomaly",
+		"event_check_completed",
+		"event_monitor_down",
+		"event_monitor_recovered",
+		"event_proxy_disabled",
+		"event_proxy_enabled",
 	}
 	eventValues := []string{
 		"incident.created",
@@ -184,6 +220,12 @@ func parseNotificationEvents(r *http.Request) []string {
 		"incident.reminder",
 		"content.changed",
 		"cert.changed",
+		"monitor.latency_anomaly",
+		"check.completed",
+		"monitor.down",
+		"monitor.recovered",
+		"proxy.disabled",
+		"proxy.enabled",
 	}
 	for i, key := range eventKeys {
 		if r.FormValue(key) == "on" {
@@ -208,8 +250,13 @@ func assembleNotificationSettings(r *http.Request, chType string) json.RawMessag
 	switch chType {
 	case "webhook":
 		s := notifier.WebhookSettings{
-			URL:    r.FormValue("notif_webhook_url"),
-			Secret: r.FormValue("notif_webhook_secret"),
+			URL:           r.FormValue("notif_webhook_url"),
+			SigningSecret: r.FormValue("notif_webhook_signing_secret"),
+			Secret:        r.FormValue("notif_webhook_secret"),
+			BodyTemplate:  r.FormValue("notif_webhook_body_template"),
+		}
+		if s.BodyTemplate != "" {
+			s.ContentType = strings.TrimSpace(r.FormValue("notif_webhook_content_type"))
 		}
 		b, _ := json.Marshal(s)
 		return b
@@ -239,8 +286,10 @@ func assembleNotificationSettings(r *http.Request, chType string) json.RawMessag
 		return assembleNtfySettings(r)
 	case "teams", "pagerduty", "opsgenie", "pushover":
 		return assembleExtendedSettings(r, chType)
-	case "googlechat", "matrix", "gotify":
+	case "googlechat", "matrix", "gotify", "mattermost", "rocketchat":
 		return assembleExtraSettings(r, chType)
+	case "twilio", "signal":
+		return assembleSMSSettings(r, chType)
 	default:
 		return json.RawMessage("{}")
 	}
@@ -255,6 +304,10 @@ func assembleEmailSettings(r *http.Request) json.RawMessage {
 		Password: r.FormValue("notif_email_password"),
 		From:    r.FormValue("notif_email_from"),
 		TLSMode: r.FormValue("notif_email_tls_mode"),
+		ReplyTo: strings.TrimSpace(r.FormValue("notif_email_reply_to")),
+
+		SubjectTemplate: r.FormValue("notif_email_subject_template"),
+		HTMLTemplate:    r.FormValue("notif_email_html_template"),
 	}
 	if toStr := strings.TrimSpace(r.FormValue("notif_email_to")); toStr != "" {
 		for _, addr := range strings.Split(toStr, ",") {
@@ -317,11 +370,53 @@ func assembleExtraSettings(r *http.Request, chType string) json.RawMessage {
 			s.Priority, _ = strconv.Atoi(p)
 		}
 		v = s
+	case "mattermost":
+		v = notifier.MattermostSettings{
+			WebhookURL: r.FormValue("notif_mattermost_webhook_url"),
+			Channel:    strings.TrimSpace(r.FormValue("notif_mattermost_channel")),
+		}
+	case "rocketchat":
+		v = notifier.RocketChatSettings{
+			WebhookURL: r.FormValue("notif_rocketchat_webhook_url"),
+			Channel:    strings.TrimSpace(r.FormValue("notif_rocketchat_channel")),
+		}
+	}
+	b, _ := json.Marshal(v)
+	return b
+}
+
+func assembleSMSSettings(r *http.Request, chType string) json.RawMessage {
+	var v any
+	switch chType {
+	case "twilio":
+		v = notifier.TwilioSettings{
+			AccountSID: strings.TrimSpace(r.FormValue("notif_twilio_account_sid")),
+			AuthToken:  r.FormValue("notif_twilio_auth_token"),
+			FromNumber: strings.TrimSpace(r.FormValue("notif_twilio_from_number")),
+			ToNumbers:  splitList(r.FormValue("notif_twilio_to_numbers")),
+		}
+	case "signal":
+		v = notifier.SignalSettings{
+			APIURL:     r.FormValue("notif_signal_api_url"),
+			Number:     strings.TrimSpace(r.FormValue("notif_signal_number")),
+			Recipients: splitList(r.FormValue("notif_signal_recipients")),
+		}
 	}
 	b, _ := json.Marshal(v)
 	return b
 }
 
+// splitList splits a comma-separated form value, dropping blank entries.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
+
 func assembleExtendedSettings(r *http.Request, chType string) json.RawMessage {
 	var v any
 	switch chType {
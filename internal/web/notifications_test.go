@@ -10,8 +10,9 @@ import (
 
 func TestAssembleNotificationSettingsWebhook(t *testing.T) {
 	form := url.Values{
-		"notif_webhook_url":    {"https://example.com/hook"},
-		"notif_webhook_secret": {"s3cret"},
+		"notif_webhook_url":            {"https://example.com/hook"},
+		"notif_webhook_signing_secret": {"whsec"},
+		"notif_webhook_secret":         {"s3cret"},
 	}
 	r := buildFormRequest(form)
 	raw := assembleNotificationSettings(r, "webhook")
@@ -23,6 +24,9 @@ func TestAssembleNotificationSettingsWebhook(t *testing.T) {
 	if s.URL != "https://example.com/hook" {
 		t.Errorf("url = %q", s.URL)
 	}
+	if s.SigningSecret != "whsec" {
+		t.Errorf("signing secret = %q", s.SigningSecret)
+	}
 	if s.Secret != "s3cret" {
 		t.Errorf("secret = %q", s.Secret)
 	}
@@ -81,6 +85,58 @@ func TestAssembleNotificationSettingsSlack(t *testing.T) {
 	}
 }
 
+func TestAssembleNotificationSettingsChat(t *testing.T) {
+	for _, chType := range []string{"mattermost", "rocketchat"} {
+		t.Run(chType, func(t *testing.T) {
+			form := url.Values{
+				"notif_" + chType + "_webhook_url": {"https://chat.example.com/hooks/abc"},
+				"notif_" + chType + "_channel":     {" alerts "},
+			}
+			raw := assembleNotificationSettings(buildFormRequest(form), chType)
+
+			var s notifier.MattermostSettings
+			if err := json.Unmarshal(raw, &s); err != nil {
+				t.Fatal(err)
+			}
+			if s.WebhookURL != "https://chat.example.com/hooks/abc" {
+				t.Errorf("webhook = %q", s.WebhookURL)
+			}
+			if s.Channel != "alerts" {
+				t.Errorf("channel = %q", s.Channel)
+			}
+		})
+	}
+}
+
+func TestAssembleNotificationSettingsSMS(t *testing.T) {
+	raw := assembleNotificationSettings(buildFormRequest(url.Values{
+		"notif_twilio_account_sid": {" AC123 "},
+		"notif_twilio_auth_token":  {"tok"},
+		"notif_twilio_from_number": {"+15550100"},
+		"notif_twilio_to_numbers":  {"+15550123, ,+15550124"},
+	}), "twilio")
+	var tw notifier.TwilioSettings
+	if err := json.Unmarshal(raw, &tw); err != nil {
+		t.Fatal(err)
+	}
+	if tw.AccountSID != "AC123" || tw.AuthToken != "tok" || len(tw.ToNumbers) != 2 || tw.ToNumbers[1] != "+15550124" {
+		t.Errorf("twilio settings = %+v", tw)
+	}
+
+	raw = assembleNotificationSettings(buildFormRequest(url.Values{
+		"notif_signal_api_url":    {"http://signal-cli:8080"},
+		"notif_signal_number":     {"+15550100"},
+		"notif_signal_recipients": {"+15550123,group.abc"},
+	}), "signal")
+	var sig notifier.SignalSettings
+	if err := json.Unmarshal(raw, &sig); err != nil {
+		t.Fatal(err)
+	}
+	if sig.APIURL != "http://signal-cli:8080" || len(sig.Recipients) != 2 || sig.Recipients[1] != "group.abc" {
+		t.Errorf("signal settings = %+v", sig)
+	}
+}
+
 func TestAssembleNotificationSettingsEmail(t *testing.T) {
 	form := url.Values{
 		"notif_email_host":     {"smtp.example.com"},
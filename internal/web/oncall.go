@@ -0,0 +1,180 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/y0f/asura/internal/httputil"
+	"github.com/y0f/asura/internal/storage"
+	"github.com/y0f/asura/internal/validate"
+	"github.com/y0f/asura/internal/web/views"
+)
+
+func (h *Handler) OncallSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.store.ListOncallSchedules(r.Context())
+	if err != nil {
+		h.logger.Error("web: list oncall schedules", "error", err)
+	}
+
+	lp := h.newLayoutParams(r, "On-call Schedules", "notifications")
+	h.renderComponent(w, r, views.OncallListPage(views.OncallListParams{
+		LayoutParams: lp,
+		Schedules:    schedules,
+		Now:          time.Now(),
+	}))
+}
+
+func (h *Handler) OncallScheduleCreate(w http.ResponseWriter, r *http.Request) {
+	sched, err := parseOncallForm(r)
+	if err == nil {
+		err = validate.ValidateOncallSchedule(sched)
+	}
+	if err != nil {
+		h.setFlash(w, err.Error())
+		h.redirect(w, r, "/oncall")
+		return
+	}
+
+	if err := h.store.CreateOncallSchedule(r.Context(), sched); err != nil {
+		h.logger.Error("web: create oncall schedule", "error", err)
+		h.setFlash(w, "Failed to create schedule")
+		h.redirect(w, r, "/oncall")
+		return
+	}
+
+	h.audit(r, "create", "oncall_schedule", sched.ID, "")
+	h.setFlash(w, "On-call schedule created")
+	h.redirect(w, r, "/oncall")
+}
+
+func (h *Handler) OncallScheduleUpdate(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		h.redirect(w, r, "/oncall")
+		return
+	}
+	sched, err := parseOncallForm(r)
+	if err == nil {
+		err = validate.ValidateOncallSchedule(sched)
+	}
+	if err != nil {
+		h.setFlash(w, err.Error())
+		h.redirect(w, r, "/oncall")
+		return
+	}
+	sched.ID = id
+
+	if err := h.store.UpdateOncallSchedule(r.Context(), sched); err != nil {
+		h.logger.Error("web: update oncall schedule", "error", err)
+		h.setFlash(w, "Failed to update schedule")
+		h.redirect(w, r, "/oncall")
+		return
+	}
+
+	h.invalidateOncall(id)
+	h.audit(r, "update", "oncall_schedule", sched.ID, "")
+	h.setFlash(w, "On-call schedule updated")
+	h.redirect(w, r, "/oncall")
+}
+
+func (h *Handler) OncallScheduleDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		h.redirect(w, r, "/oncall")
+		return
+	}
+	if err := h.store.DeleteOncallSchedule(r.Context(), id); err != nil {
+		h.logger.Error("web: delete oncall schedule", "error", err)
+		h.setFlash(w, "Failed to delete schedule")
+		h.redirect(w, r, "/oncall")
+		return
+	}
+	h.invalidateOncall(id)
+	h.audit(r, "delete", "oncall_schedule", id, "")
+	h.setFlash(w, "On-call schedule deleted")
+	h.redirect(w, r, "/oncall")
+}
+
+// invalidateOncall makes notifications pick up a changed or deleted schedule
+// right away instead of after the dispatcher's cache expires.
+func (h *Handler) invalidateOncall(id int64) {
+	if h.notifier != nil {
+		h.notifier.InvalidateOncall(id)
+	}
+}
+
+// parseOncallForm reads the schedule editor. Members are one per line as
+// "name, email, routing key"; overrides prefix that with the local start and
+// end, e.g. "2025-01-06T09:00, 2025-01-07T09:00, Alice, alice@example.com".
+func parseOncallForm(r *http.Request) (*storage.OncallSchedule, error) {
+	r.ParseForm()
+	shiftHours, _ := strconv.Atoi(r.FormValue("shift_hours"))
+	sched := &storage.OncallSchedule{
+		Name:          strings.TrimSpace(r.FormValue("name")),
+		Timezone:      strings.TrimSpace(r.FormValue("timezone")),
+		RotationStart: r.FormValue("rotation_start"),
+		ShiftHours:    shiftHours,
+	}
+
+	for i, line := range nonEmptyLines(r.FormValue("members")) {
+		m, err := parseOncallMember(strings.Split(line, ","))
+		if err != nil {
+			return nil, fmt.Errorf("members line %d: %w", i+1, err)
+		}
+		sched.Members = append(sched.Members, m)
+	}
+
+	loc := sched.Location()
+	for i, line := range nonEmptyLines(r.FormValue("overrides")) {
+		fields := strings.Split(line, ",")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("overrides line %d: expected start, end, name, email", i+1)
+		}
+		start, err := time.ParseInLocation(storage.OncallRotationLayout, strings.TrimSpace(fields[0]), loc)
+		if err != nil {
+			return nil, fmt.Errorf("overrides line %d: start must look like 2025-01-06T09:00", i+1)
+		}
+		end, err := time.ParseInLocation(storage.OncallRotationLayout, strings.TrimSpace(fields[1]), loc)
+		if err != nil {
+			return nil, fmt.Errorf("overrides line %d: end must look like 2025-01-06T09:00", i+1)
+		}
+		m, err := parseOncallMember(fields[2:])
+		if err != nil {
+			return nil, fmt.Errorf("overrides line %d: %w", i+1, err)
+		}
+		sched.Overrides = append(sched.Overrides, storage.OncallOverride{Start: start.UTC(), End: end.UTC(), Member: m})
+	}
+	return sched, nil
+}
+
+func parseOncallMember(fields []string) (storage.OncallMember, error) {
+	if len(fields) > 3 {
+		return storage.OncallMember{}, fmt.Errorf("expected name, email and routing key")
+	}
+	var m storage.OncallMember
+	for i, f := range fields {
+		f = strings.TrimSpace(f)
+		switch i {
+		case 0:
+			m.Name = f
+		case 1:
+			m.Email = f
+		case 2:
+			m.RoutingKey = f
+		}
+	}
+	return m, nil
+}
+
+func nonEmptyLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
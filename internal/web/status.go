@@ -4,13 +4,16 @@ import (
 	"context"
 	"crypto/sha256"
 	"crypto/subtle"
+	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/y0f/asura/internal/httputil"
 	"github.com/y0f/asura/internal/storage"
+	"github.com/y0f/asura/internal/validate"
 	"github.com/y0f/asura/internal/web/views"
 )
 
@@ -59,8 +62,26 @@ func (h *Handler) StatusPageByID(w http.ResponseWriter, r *http.Request, pageID
 		groupNameMap[spm.MonitorID] = spm.GroupName
 	}
 
+	comps, err := h.store.ListStatusPageComponents(ctx, sp.ID)
+	if err != nil {
+		h.logger.Error("web: status page components", "error", err)
+	}
+	summaries, members := httputil.SummarizeComponents(ctx, h.store, comps, monitors, from, now)
+
 	var monitorData []views.MonitorWithUptime
+	for _, cs := range summaries {
+		monitorData = append(monitorData, views.MonitorWithUptime{
+			Monitor:     &storage.Monitor{ID: cs.Component.ID, Name: cs.Component.Name, Status: cs.Status},
+			DailyBars:   dailyBars(cs.Daily, now),
+			Uptime90d:   cs.Uptime,
+			UptimeLabel: views.UptimeFmt(cs.Uptime),
+			Description: cs.Component.Description,
+		})
+	}
 	for _, m := range monitors {
+		if members[m.ID] {
+			continue
+		}
 		bars := h.buildDailyBars(ctx, m.ID, from, now)
 		uptime, err := h.store.GetUptimePercent(ctx, m.ID, from, now)
 		if err != nil {
@@ -91,20 +112,168 @@ func (h *Handler) StatusPageByID(w http.ResponseWriter, r *http.Request, pageID
 
 	overall := httputil.OverallStatus(monitors)
 	incidents := httputil.PublicIncidentsForPage(ctx, h.store, sp, monitors, now)
+	httputil.RelabelComponentIncidents(incidents, summaries)
 
 	h.renderComponent(w, r, views.PublicStatusPage(views.PublicStatusPageParams{
 		Title:        sp.Title,
 		BasePath:     h.cfg.Server.BasePath,
 		Config:       sp,
+		Announcement: httputil.ActiveAnnouncement(sp, now),
 		Monitors:     monitorData,
 		Groups:       groups,
 		HasGroups:    len(groups) > 1 || (len(groups) == 1 && groups[0].Name != ""),
 		Overall:      overall,
 		Incidents:    incidents,
 		HasIncidents: len(incidents) > 0,
+		Subscribe:    r.URL.Query().Get("subscribe"),
+	}))
+}
+
+// acceptsSubscribers reports whether visitors may subscribe to sp.
+func acceptsSubscribers(sp *storage.StatusPage) bool {
+	return sp.Enabled && sp.ShowIncidents && sp.SubscriberChannelID != nil
+}
+
+// StatusPageSubscribe starts a double opt-in subscription: the address is
+// stored unconfirmed and sent a confirmation link. The response is the same
+// whether or not the address was already subscribed.
+func (h *Handler) StatusPageSubscribe(w http.ResponseWriter, r *http.Request, pageID int64, slug string) {
+	ctx := r.Context()
+	pageURL := h.cfg.Server.BasePath + "/" + slug
+
+	sp, err := h.store.GetStatusPage(ctx, pageID)
+	if err != nil || !acceptsSubscribers(sp) {
+		http.Redirect(w, r, pageURL, http.StatusSeeOther)
+		return
+	}
+	if sp.PasswordHash != "" && !h.checkStatusPageAuth(r, pageID, sp.PasswordHash) {
+		http.Redirect(w, r, pageURL+"/auth", http.StatusSeeOther)
+		return
+	}
+
+	ip := httputil.ExtractIP(r, h.cfg.TrustedNets())
+	if !h.loginRL.Allow(ip) {
+		http.Redirect(w, r, pageURL+"?subscribe=error", http.StatusSeeOther)
+		return
+	}
+
+	email, err := validate.ValidateSubscriberEmail(r.FormValue("email"))
+	if err != nil {
+		http.Redirect(w, r, pageURL+"?subscribe=invalid", http.StatusSeeOther)
+		return
+	}
+
+	sub, err := h.store.GetStatusPageSubscriberByEmail(ctx, pageID, email)
+	switch {
+	case err == nil:
+		if sub.Confirmed {
+			http.Redirect(w, r, pageURL+"?subscribe=sent", http.StatusSeeOther)
+			return
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		token, err := generateSessionToken()
+		if err != nil {
+			h.logger.Error("web: generate subscriber token", "error", err)
+			http.Redirect(w, r, pageURL+"?subscribe=error", http.StatusSeeOther)
+			return
+		}
+		sub = &storage.StatusPageSubscriber{PageID: pageID, Email: email, Token: token}
+		if err := h.store.CreateStatusPageSubscriber(ctx, sub); err != nil {
+			h.logger.Error("web: create status page subscriber", "error", err)
+			http.Redirect(w, r, pageURL+"?subscribe=error", http.StatusSeeOther)
+			return
+		}
+	default:
+		h.logger.Error("web: get status page subscriber", "error", err)
+		http.Redirect(w, r, pageURL+"?subscribe=error", http.StatusSeeOther)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := h.notifier.SendSubscriptionConfirmation(ctx, sp, sub); err != nil {
+			h.logger.Warn("web: send subscription confirmation", "page_id", pageID, "error", err)
+		}
+	}()
+	http.Redirect(w, r, pageURL+"?subscribe=sent", http.StatusSeeOther)
+}
+
+// subscriberByToken returns the subscriber of pageID holding the token in
+// the request, or nil.
+func (h *Handler) subscriberByToken(r *http.Request, pageID int64) *storage.StatusPageSubscriber {
+	token := r.FormValue("token")
+	if token == "" {
+		return nil
+	}
+	sub, err := h.store.GetStatusPageSubscriberByToken(r.Context(), token)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			h.logger.Error("web: get status page subscriber", "error", err)
+		}
+		return nil
+	}
+	if sub.PageID != pageID {
+		return nil
+	}
+	return sub
+}
+
+func (h *Handler) renderSubscription(w http.ResponseWriter, r *http.Request, pageID int64, slug, message, token string) {
+	title := "Status"
+	if sp, err := h.store.GetStatusPage(r.Context(), pageID); err == nil {
+		title = sp.Title
+	}
+	h.renderComponent(w, r, views.StatusPageSubscriptionPage(views.StatusPageSubscriptionParams{
+		Title:    title,
+		BasePath: h.cfg.Server.BasePath,
+		Slug:     slug,
+		Message:  message,
+		Token:    token,
 	}))
 }
 
+func (h *Handler) StatusPageSubscribeConfirm(w http.ResponseWriter, r *http.Request, pageID int64, slug string) {
+	sub := h.subscriberByToken(r, pageID)
+	if sub == nil {
+		h.renderSubscription(w, r, pageID, slug, "This confirmation link is invalid or no longer active.", "")
+		return
+	}
+	if err := h.store.ConfirmStatusPageSubscriber(r.Context(), sub.ID); err != nil {
+		h.logger.Error("web: confirm status page subscriber", "error", err)
+		h.renderSubscription(w, r, pageID, slug, "Could not confirm your subscription. Try again later.", "")
+		return
+	}
+	h.renderSubscription(w, r, pageID, slug, "Your subscription is confirmed. You will be emailed about new incidents.", "")
+}
+
+// StatusPageUnsubscribeGet asks for confirmation rather than unsubscribing,
+// so link scanners that follow email links don't remove subscribers.
+func (h *Handler) StatusPageUnsubscribeGet(w http.ResponseWriter, r *http.Request, pageID int64, slug string) {
+	sub := h.subscriberByToken(r, pageID)
+	if sub == nil {
+		h.renderSubscription(w, r, pageID, slug, "This unsubscribe link is invalid or you are already unsubscribed.", "")
+		return
+	}
+	h.renderSubscription(w, r, pageID, slug, "Stop emailing incident updates to "+sub.Email+"?", sub.Token)
+}
+
+// StatusPageUnsubscribePost removes the subscriber. It also serves one-click
+// unsubscribe requests that mail clients send for the List-Unsubscribe header.
+func (h *Handler) StatusPageUnsubscribePost(w http.ResponseWriter, r *http.Request, pageID int64, slug string) {
+	sub := h.subscriberByToken(r, pageID)
+	if sub == nil {
+		h.renderSubscription(w, r, pageID, slug, "This unsubscribe link is invalid or you are already unsubscribed.", "")
+		return
+	}
+	if err := h.store.DeleteStatusPageSubscriber(r.Context(), sub.ID); err != nil {
+		h.logger.Error("web: delete status page subscriber", "error", err)
+		h.renderSubscription(w, r, pageID, slug, "Could not unsubscribe. Try again later.", "")
+		return
+	}
+	h.renderSubscription(w, r, pageID, slug, "You have been unsubscribed.", "")
+}
+
 func (h *Handler) StatusPageAuthGet(w http.ResponseWriter, r *http.Request, pageID int64) {
 	ctx := r.Context()
 	sp, err := h.store.GetStatusPage(ctx, pageID)
@@ -168,7 +337,12 @@ func (h *Handler) buildDailyBars(ctx context.Context, monitorID int64, from, now
 	if err != nil {
 		h.logger.Error("web: status daily uptime", "monitor_id", monitorID, "error", err)
 	}
+	return dailyBars(daily, now)
+}
 
+// dailyBars lays out the last 90 days ending at now, filling days without
+// data with empty bars.
+func dailyBars(daily []*storage.DailyUptime, now time.Time) []views.DailyBar {
 	dayMap := make(map[string]*storage.DailyUptime)
 	for _, d := range daily {
 		dayMap[d.Date] = d
@@ -6,8 +6,10 @@ import (
 	"encoding/hex"
 	"errors"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/y0f/asura/internal/httputil"
 	"github.com/y0f/asura/internal/storage"
@@ -29,10 +31,42 @@ func (h *Handler) StatusPages(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) StatusPageForm(w http.ResponseWriter, r *http.Request) {
+	h.renderStatusPageForm(w, r, h.takeNewAPIToken(w, r))
+}
+
+// newAPITokenCookie carries a regenerated status page API token from the
+// POST that created it to the edit page it redirects to. It is scoped to
+// that page and cleared as soon as the token has been shown.
+const newAPITokenCookie = "new_api_token"
+
+// takeNewAPIToken returns the token left by StatusPageRegenerateToken for
+// the status page being edited, if any, and clears it so a reload doesn't
+// show it again.
+func (h *Handler) takeNewAPIToken(w http.ResponseWriter, r *http.Request) string {
+	id := r.PathValue("id")
+	c, err := r.Cookie(newAPITokenCookie)
+	if id == "" || err != nil || c.Value == "" {
+		return ""
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     newAPITokenCookie,
+		Path:     h.cfg.Server.BasePath + "/status-pages/" + id + "/edit",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.cfg.Auth.Session.CookieSecure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return c.Value
+}
+
+// renderStatusPageForm shows the new or edit form, with newToken displayed
+// once after the API token was regenerated.
+func (h *Handler) renderStatusPageForm(w http.ResponseWriter, r *http.Request, newToken string) {
 	ctx := r.Context()
 
 	var sp *storage.StatusPage
 	var pageMonitors []storage.StatusPageMonitor
+	var components []storage.StatusPageComponent
 
 	idStr := r.PathValue("id")
 	if idStr != "" {
@@ -58,6 +92,10 @@ func (h *Handler) StatusPageForm(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			h.logger.Error("web: list status page monitors", "error", err)
 		}
+		components, err = h.store.ListStatusPageComponents(ctx, id)
+		if err != nil {
+			h.logger.Error("web: list status page components", "error", err)
+		}
 	}
 
 	allMonitors, err := h.store.ListMonitors(ctx, storage.MonitorListFilter{}, storage.Pagination{Page: 1, PerPage: 10000})
@@ -80,13 +118,27 @@ func (h *Handler) StatusPageForm(w http.ResponseWriter, r *http.Request) {
 		assignedData[pm.MonitorID] = pm
 	}
 
+	channels, err := h.store.ListNotificationChannels(ctx)
+	if err != nil {
+		h.logger.Error("web: list channels for status page form", "error", err)
+	}
+	var emailChannels []*storage.NotificationChannel
+	for _, ch := range channels {
+		if ch.Type == "email" {
+			emailChannels = append(emailChannels, ch)
+		}
+	}
+
 	lp := h.newLayoutParams(r, "Status Page", "status-pages")
 	h.renderComponent(w, r, views.StatusPageFormPage(views.StatusPageFormParams{
-		LayoutParams: lp,
-		StatusPage:   sp,
-		Monitors:     monitors,
-		Assigned:     assignedSet,
-		AssignedData: assignedData,
+		LayoutParams:  lp,
+		StatusPage:    sp,
+		Monitors:      monitors,
+		Assigned:      assignedSet,
+		AssignedData:  assignedData,
+		Components:    components,
+		EmailChannels: emailChannels,
+		NewAPIToken:   newToken,
 	}))
 }
 
@@ -96,6 +148,19 @@ func hashPassword(password string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// parseAnnouncement reads the announcement banner fields. Times are entered
+// in UTC, like maintenance windows.
+func parseAnnouncement(r *http.Request, sp *storage.StatusPage) {
+	sp.AnnouncementHTML = strings.TrimSpace(r.FormValue("announcement_html"))
+	sp.AnnouncementEnabled = r.FormValue("announcement_enabled") == "on"
+	if t, err := time.Parse("2006-01-02T15:04", r.FormValue("announcement_start")); err == nil {
+		sp.AnnouncementStart = &t
+	}
+	if t, err := time.Parse("2006-01-02T15:04", r.FormValue("announcement_end")); err == nil {
+		sp.AnnouncementEnd = &t
+	}
+}
+
 func (h *Handler) StatusPageCreate(w http.ResponseWriter, r *http.Request) {
 	r.ParseForm()
 
@@ -115,6 +180,10 @@ func (h *Handler) StatusPageCreate(w http.ResponseWriter, r *http.Request) {
 	if v := r.FormValue("sort_order"); v != "" {
 		sp.SortOrder, _ = strconv.Atoi(v)
 	}
+	if id, err := strconv.ParseInt(r.FormValue("subscriber_channel_id"), 10, 64); err == nil {
+		sp.SubscriberChannelID = &id
+	}
+	parseAnnouncement(r, sp)
 	if pw := r.FormValue("password"); pw != "" {
 		sp.PasswordHash = hashPassword(pw)
 	}
@@ -126,6 +195,11 @@ func (h *Handler) StatusPageCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
+	if err := httputil.CheckSubscriberChannel(ctx, h.store, sp); err != nil {
+		h.setFlash(w, err.Error())
+		h.redirect(w, r, "/status-pages/new")
+		return
+	}
 
 	existing, err := h.store.GetStatusPageBySlug(ctx, sp.Slug)
 	if err == nil && existing != nil {
@@ -147,6 +221,11 @@ func (h *Handler) StatusPageCreate(w http.ResponseWriter, r *http.Request) {
 			h.logger.Error("web: set status page monitors", "error", err)
 		}
 	}
+	if comps := parseStatusPageComponents(r, monitors, nil); len(comps) > 0 {
+		if err := h.store.SetStatusPageComponents(ctx, sp.ID, comps); err != nil {
+			h.logger.Error("web: set status page components", "error", err)
+		}
+	}
 
 	if h.OnStatusPageChange != nil {
 		h.OnStatusPageChange()
@@ -187,10 +266,15 @@ func (h *Handler) StatusPageUpdate(w http.ResponseWriter, r *http.Request) {
 		CustomHeaderHTML: r.FormValue("custom_header_html"),
 		AnalyticsScript:  r.FormValue("analytics_script"),
 		PasswordHash:     existing.PasswordHash,
+		APITokenHash:     existing.APITokenHash,
 	}
 	if v := r.FormValue("sort_order"); v != "" {
 		sp.SortOrder, _ = strconv.Atoi(v)
 	}
+	if id, err := strconv.ParseInt(r.FormValue("subscriber_channel_id"), 10, 64); err == nil {
+		sp.SubscriberChannelID = &id
+	}
+	parseAnnouncement(r, sp)
 	if pw := r.FormValue("password"); pw != "" {
 		sp.PasswordHash = hashPassword(pw)
 	} else if r.FormValue("clear_password") == "on" {
@@ -204,6 +288,11 @@ func (h *Handler) StatusPageUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
+	if err := httputil.CheckSubscriberChannel(ctx, h.store, sp); err != nil {
+		h.setFlash(w, err.Error())
+		h.redirect(w, r, "/status-pages/"+strconv.FormatInt(id, 10)+"/edit")
+		return
+	}
 
 	slugOwner, err := h.store.GetStatusPageBySlug(ctx, sp.Slug)
 	if err == nil && slugOwner != nil && slugOwner.ID != id {
@@ -223,6 +312,13 @@ func (h *Handler) StatusPageUpdate(w http.ResponseWriter, r *http.Request) {
 	if err := h.store.SetStatusPageMonitors(ctx, id, monitors); err != nil {
 		h.logger.Error("web: set status page monitors", "error", err)
 	}
+	existingComps, err := h.store.ListStatusPageComponents(ctx, id)
+	if err != nil {
+		h.logger.Error("web: list status page components", "error", err)
+	}
+	if err := h.store.SetStatusPageComponents(ctx, id, parseStatusPageComponents(r, monitors, existingComps)); err != nil {
+		h.logger.Error("web: set status page components", "error", err)
+	}
 
 	if h.OnStatusPageChange != nil {
 		h.OnStatusPageChange()
@@ -247,6 +343,74 @@ func (h *Handler) StatusPageDelete(w http.ResponseWriter, r *http.Request) {
 	h.redirect(w, r, "/status-pages")
 }
 
+// StatusPageRegenerateToken replaces the page's API token and shows the new
+// one on the edit form. Only its hash is kept.
+func (h *Handler) StatusPageRegenerateToken(w http.ResponseWriter, r *http.Request) {
+	sp, ok := h.statusPageForToken(w, r)
+	if !ok {
+		return
+	}
+	token, hash, err := httputil.GenerateStatusPageToken()
+	if err != nil {
+		h.logger.Error("web: generate status page token", "error", err)
+		h.setFlash(w, "Failed to generate token")
+		h.redirect(w, r, "/status-pages/"+strconv.FormatInt(sp.ID, 10)+"/edit")
+		return
+	}
+	sp.APITokenHash = hash
+	if err := h.store.UpdateStatusPage(r.Context(), sp); err != nil {
+		h.logger.Error("web: save status page token", "error", err)
+		h.setFlash(w, "Failed to save token")
+		h.redirect(w, r, "/status-pages/"+strconv.FormatInt(sp.ID, 10)+"/edit")
+		return
+	}
+	// Redirect rather than render, so reloading the page can't regenerate
+	// the token again. The edit page shows it once.
+	edit := "/status-pages/" + strconv.FormatInt(sp.ID, 10) + "/edit"
+	http.SetCookie(w, &http.Cookie{
+		Name:     newAPITokenCookie,
+		Value:    token,
+		Path:     h.cfg.Server.BasePath + edit,
+		MaxAge:   60,
+		HttpOnly: true,
+		Secure:   h.cfg.Auth.Session.CookieSecure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	h.redirect(w, r, edit)
+}
+
+func (h *Handler) StatusPageDeleteToken(w http.ResponseWriter, r *http.Request) {
+	sp, ok := h.statusPageForToken(w, r)
+	if !ok {
+		return
+	}
+	sp.APITokenHash = ""
+	if err := h.store.UpdateStatusPage(r.Context(), sp); err != nil {
+		h.logger.Error("web: remove status page token", "error", err)
+		h.setFlash(w, "Failed to remove token")
+	} else {
+		h.setFlash(w, "API token removed")
+	}
+	h.redirect(w, r, "/status-pages/"+strconv.FormatInt(sp.ID, 10)+"/edit")
+}
+
+func (h *Handler) statusPageForToken(w http.ResponseWriter, r *http.Request) (*storage.StatusPage, bool) {
+	id, err := httputil.ParseID(r)
+	if err != nil {
+		h.setFlash(w, "Invalid ID")
+		h.redirect(w, r, "/status-pages")
+		return nil, false
+	}
+	sp, err := h.store.GetStatusPage(r.Context(), id)
+	if err != nil {
+		h.logger.Error("web: get status page for token", "error", err)
+		h.setFlash(w, "Failed to load status page")
+		h.redirect(w, r, "/status-pages")
+		return nil, false
+	}
+	return sp, true
+}
+
 func parseStatusPageMonitors(r *http.Request, pageID int64) []storage.StatusPageMonitor {
 	var result []storage.StatusPageMonitor
 	for key, vals := range r.Form {
@@ -276,3 +440,47 @@ func parseStatusPageMonitors(r *http.Request, pageID int64) []storage.StatusPage
 	}
 	return result
 }
+
+// parseStatusPageComponents builds components from the per-monitor component
+// name and weight fields. Components are created by name in monitor sort
+// order; descriptions of existing components with the same name are kept.
+func parseStatusPageComponents(r *http.Request, monitors []storage.StatusPageMonitor, existing []storage.StatusPageComponent) []storage.StatusPageComponent {
+	sorted := append([]storage.StatusPageMonitor(nil), monitors...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].SortOrder != sorted[j].SortOrder {
+			return sorted[i].SortOrder < sorted[j].SortOrder
+		}
+		return sorted[i].MonitorID < sorted[j].MonitorID
+	})
+
+	descriptions := make(map[string]string, len(existing))
+	for _, c := range existing {
+		descriptions[c.Name] = c.Description
+	}
+
+	var result []storage.StatusPageComponent
+	idx := make(map[string]int)
+	for _, m := range sorted {
+		idStr := strconv.FormatInt(m.MonitorID, 10)
+		name := strings.TrimSpace(r.FormValue("monitor_" + idStr + "_component"))
+		if name == "" {
+			continue
+		}
+		weight, _ := strconv.Atoi(r.FormValue("monitor_" + idStr + "_weight"))
+		if weight < 1 || weight > 100 {
+			weight = 1
+		}
+		i, ok := idx[name]
+		if !ok {
+			i = len(result)
+			idx[name] = i
+			result = append(result, storage.StatusPageComponent{
+				Name:        name,
+				Description: descriptions[name],
+				SortOrder:   i,
+			})
+		}
+		result[i].Monitors = append(result[i].Monitors, storage.StatusPageComponentMonitor{MonitorID: m.MonitorID, Weight: weight})
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/y0f/asura/internal/storage"
+)
+
+func TestStatusPageRegenerateTokenRedirects(t *testing.T) {
+	for _, secure := range []bool{true, false} {
+		t.Run(fmt.Sprintf("cookie_secure=%v", secure), func(t *testing.T) {
+			testStatusPageRegenerateTokenRedirects(t, secure)
+		})
+	}
+}
+
+func testStatusPageRegenerateTokenRedirects(t *testing.T, secure bool) {
+	tmp, err := os.CreateTemp("", "asura-web-test-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp.Close()
+	t.Cleanup(func() { os.Remove(tmp.Name()) })
+	store, err := storage.NewSQLiteStore(tmp.Name(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	h := testWebHandler(t)
+	h.store = store
+	h.cfg.Auth.Session.CookieSecure = secure
+	ctx := context.Background()
+	sp := &storage.StatusPage{Title: "Status", Slug: "status", Enabled: true}
+	if err := store.CreateStatusPage(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	id := strconv.FormatInt(sp.ID, 10)
+	editPath := "/status-pages/" + id + "/edit"
+
+	req := httptest.NewRequest("POST", "/status-pages/"+id+"/api-token", nil)
+	req.SetPathValue("id", id)
+	w := httptest.NewRecorder()
+	h.StatusPageRegenerateToken(w, req)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != editPath {
+		t.Fatalf("regenerate: status %d, location %q; want a redirect to %s", w.Code, w.Header().Get("Location"), editPath)
+	}
+	var token *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == newAPITokenCookie {
+			token = c
+		}
+	}
+	if token == nil || token.Value == "" || token.Path != editPath || !token.HttpOnly || token.Secure != secure {
+		t.Fatalf("token cookie = %+v, want an HttpOnly cookie scoped to %s with Secure=%v", token, editPath, secure)
+	}
+	saved, _ := store.GetStatusPage(ctx, sp.ID)
+	if !saved.APITokenEnabled {
+		t.Fatal("expected the token hash to be saved")
+	}
+
+	edit := func(cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", editPath, nil)
+		req.SetPathValue("id", id)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		h.StatusPageForm(w, req)
+		return w
+	}
+	w = edit(token)
+	if !strings.Contains(w.Body.String(), token.Value) {
+		t.Fatal("expected the edit page to show the new token")
+	}
+	cleared := false
+	for _, c := range w.Result().Cookies() {
+		if c.Name == newAPITokenCookie && c.MaxAge < 0 && c.Path == editPath && c.Secure == secure {
+			cleared = true
+		}
+	}
+	if !cleared {
+		t.Fatal("expected the edit page to clear the token cookie")
+	}
+
+	if w := edit(nil); strings.Contains(w.Body.String(), token.Value) {
+		t.Fatal("expected a reload not to show the token again")
+	}
+	if again, _ := store.GetStatusPage(ctx, sp.ID); again.APITokenHash != saved.APITokenHash {
+		t.Fatal("expected a reload not to regenerate the token")
+	}
+}
@@ -48,10 +48,12 @@ func StatusDot(status string) string {
 	switch status {
 	case "up", "resolved":
 		return "bg-emerald-400"
-	case "down", "created":
+	case "down", "created", "ack_expired", "reoccurred":
 		return "bg-red-400"
 	case "degraded", "acknowledged", "paused":
 		return "bg-yellow-400"
+	case "note":
+		return "bg-blue-400"
 	default:
 		return "bg-gray-500"
 	}
@@ -120,6 +122,13 @@ func IncidentDuration(started time.Time, resolved *time.Time) string {
 	return fmt.Sprintf("%dd %dh", int(d.Hours()/24), int(d.Hours())%24)
 }
 
+func latencySigmaValue(sigma float64) string {
+	if sigma <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(sigma, 'f', -1, 64)
+}
+
 func UptimeFmt(pct float64) string {
 	if pct >= 99.995 {
 		return "100%"
@@ -227,6 +236,22 @@ func TypeLabel(t string) string {
 		return "gRPC"
 	case "mqtt":
 		return "MQTT"
+	case "amqp":
+		return "AMQP"
+	case "s3":
+		return "S3"
+	case "smtp":
+		return "SMTP"
+	case "redis":
+		return "Redis"
+	case "ssh":
+		return "SSH"
+	case "ntp":
+		return "NTP"
+	case "kafka":
+		return "Kafka"
+	case "transaction":
+		return "Transaction"
 	default:
 		return t
 	}
@@ -273,6 +298,21 @@ func ParseDNS(s string) []string {
 	return records
 }
 
+// ParseHeaders decodes a check's stored response headers, sorted by name.
+func ParseHeaders(s string) [][2]string {
+	if s == "" {
+		return nil
+	}
+	var m map[string]string
+	json.Unmarshal([]byte(s), &m)
+	headers := make([][2]string, 0, len(m))
+	for k, v := range m {
+		headers = append(headers, [2]string{k, v})
+	}
+	slices.SortFunc(headers, func(a, b [2]string) int { return strings.Compare(a[0], b[0]) })
+	return headers
+}
+
 func statusPageMonitorSort(data map[int64]storage.StatusPageMonitor, monID int64) string {
 	if spm, ok := data[monID]; ok && spm.SortOrder != 0 {
 		return strconv.Itoa(spm.SortOrder)
@@ -287,6 +327,28 @@ func statusPageMonitorGroup(data map[int64]storage.StatusPageMonitor, monID int6
 	return ""
 }
 
+func statusPageMonitorComponent(comps []storage.StatusPageComponent, monID int64) string {
+	for _, c := range comps {
+		for _, cm := range c.Monitors {
+			if cm.MonitorID == monID {
+				return c.Name
+			}
+		}
+	}
+	return ""
+}
+
+func statusPageMonitorWeight(comps []storage.StatusPageComponent, monID int64) string {
+	for _, c := range comps {
+		for _, cm := range c.Monitors {
+			if cm.MonitorID == monID && cm.Weight > 1 {
+				return strconv.Itoa(cm.Weight)
+			}
+		}
+	}
+	return ""
+}
+
 // RenderMarkdown converts a markdown subset to safe HTML for display.
 // Supports: headers, bold, italic, inline code, fenced code blocks,
 // unordered/ordered lists, links (http/https only), and paragraphs.
@@ -172,7 +172,7 @@ func NotificationHistoryPage(p NotificationHistoryParams) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			for _, et := range []string{"incident.created", "incident.resolved", "incident.reminder", "incident.acknowledged", "content.changed", "cert.changed", "test"} {
+			for _, et := range []string{"incident.created", "incident.resolved", "incident.reminder", "incident.escalated", "incident.acknowledged", "content.changed", "cert.changed", "monitor.latency_anomaly", "monitor.down", "monitor.recovered", "proxy.disabled", "proxy.enabled", "test"} {
 				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "<option value=\"")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
@@ -10,6 +10,7 @@ import templruntime "github.com/a-h/templ/runtime"
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/y0f/asura/internal/storage"
 )
@@ -17,46 +18,73 @@ import (
 type NotificationListParams struct {
 	LayoutParams
 	Channels []*storage.NotificationChannel
+	Tags     []*storage.Tag
+	Oncall   []*storage.OncallSchedule
 }
 
+func (p NotificationListParams) channelTags(ch *storage.NotificationChannel) []*storage.Tag {
+	var tags []*storage.Tag
+	for _, t := range p.Tags {
+		if slices.Contains(ch.TagIDs, t.ID) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// notifURLPattern accepts an http(s) URL or a value with an
+// ${ASURA_NOTIFY_*} reference, which is only resolved at send time.
+const notifURLPattern = `(https?://.+|.*\$\{ASURA_NOTIFY_[A-Za-z0-9_]+\}.*)`
+
 func notifXData() string {
 	return `{
     showForm: false,
     editId: 0,
     advancedNotifSettings: false,
-    formData: {name:'', type:'webhook', enabled:true, settings_json:'{}'},
+    formData: {name:'', type:'webhook', enabled:true, settings_json:'{}', schedule_json:'', oncall_schedule_id:'', digest_window_seconds:'', digest_flush_on_resolve:false},
+    tagIds: [],
+    toggleTag(id) { this.tagIds = this.tagIds.includes(id) ? this.tagIds.filter(t => t !== id) : [...this.tagIds, id] },
     events: {created:true, resolved:true, acknowledged:false, reminder:true, changed:false, certChanged:false},
-    webhook: {url:'', secret:''},
+    webhook: {url:'', signing_secret:'', secret:'', body_template:'', content_type:''},
     telegram: {bot_token:'', chat_id:''},
     discord: {webhook_url:''},
     slack: {webhook_url:'', channel:''},
-    email: {host:'', port:587, username:'', password:'', from:'', to:'', tls_mode:'starttls', cc:'', bcc:''},
-    ntfy: {server_url:'', topic:'', priority:3, tags:'', click_url:''},
+    email: {host:'', port:587, username:'', password:'', from:'', to:'', tls_mode:'starttls', cc:'', bcc:'', reply_to:'', subject_template:'', html_template:''},
+    ntfy: {server_url:'', topic:'', priority:'', tags:'', click_url:''},
     teams: {webhook_url:''},
     pagerduty: {routing_key:''},
     opsgenie: {api_key:'', region:''},
     pushover: {user_key:'', app_token:'', priority:'0', sound:'', device:''},
     googlechat: {webhook_url:''},
     matrix: {homeserver:'', access_token:'', room_id:''},
-    gotify: {server_url:'', app_token:'', priority:5},
+    gotify: {server_url:'', app_token:'', priority:''},
+    mattermost: {webhook_url:'', channel:''},
+    rocketchat: {webhook_url:'', channel:''},
+    twilio: {account_sid:'', auth_token:'', from_number:'', to_numbers:''},
+    signal: {api_url:'', number:'', recipients:''},
     resetForm() {
         this.editId = 0;
         this.advancedNotifSettings = false;
-        this.formData = {name:'', type:'webhook', enabled:true, settings_json:'{}'};
-        this.events = {created:true, resolved:true, acknowledged:false, reminder:true, changed:false, certChanged:false};
-        this.webhook = {url:'', secret:''};
+        this.formData = {name:'', type:'webhook', enabled:true, settings_json:'{}', schedule_json:'', oncall_schedule_id:'', digest_window_seconds:'', digest_flush_on_resolve:false};
+        this.tagIds = [];
+        this.events = {created:true, resolved:true, acknowledged:false, reminder:true, changed:false, certChanged:false, latencyAnomaly:false, checkCompleted:false, monitorDown:false, monitorRecovered:false, proxyDisabled:false, proxyEnabled:false};
+        this.webhook = {url:'', signing_secret:'', secret:'', body_template:'', content_type:''};
         this.telegram = {bot_token:'', chat_id:''};
         this.discord = {webhook_url:''};
         this.slack = {webhook_url:'', channel:''};
-        this.email = {host:'', port:587, username:'', password:'', from:'', to:'', tls_mode:'starttls', cc:'', bcc:''};
-        this.ntfy = {server_url:'', topic:'', priority:3, tags:'', click_url:''};
+        this.email = {host:'', port:587, username:'', password:'', from:'', to:'', tls_mode:'starttls', cc:'', bcc:'', reply_to:'', subject_template:'', html_template:''};
+        this.ntfy = {server_url:'', topic:'', priority:'', tags:'', click_url:''};
         this.teams = {webhook_url:''};
         this.pagerduty = {routing_key:''};
         this.opsgenie = {api_key:'', region:''};
         this.pushover = {user_key:'', app_token:'', priority:'0', sound:'', device:''};
         this.googlechat = {webhook_url:''};
         this.matrix = {homeserver:'', access_token:'', room_id:''};
-        this.gotify = {server_url:'', app_token:'', priority:5};
+        this.gotify = {server_url:'', app_token:'', priority:''};
+        this.mattermost = {webhook_url:'', channel:''};
+        this.rocketchat = {webhook_url:'', channel:''};
+        this.twilio = {account_sid:'', auth_token:'', from_number:'', to_numbers:''};
+        this.signal = {api_url:'', number:'', recipients:''};
     },
     editChannel(ch) {
         this.resetForm();
@@ -65,7 +93,12 @@ func notifXData() string {
         this.formData.type = ch.type;
         this.formData.enabled = ch.enabled;
         this.formData.settings_json = JSON.stringify(ch.settings || {});
-        this.events = {created:false, resolved:false, acknowledged:false, reminder:false, changed:false};
+        this.formData.schedule_json = ch.schedule ? JSON.stringify(ch.schedule, null, 2) : '';
+        this.formData.oncall_schedule_id = ch.oncall_schedule_id ? String(ch.oncall_schedule_id) : '';
+        this.formData.digest_window_seconds = ch.digest_window_seconds ? String(ch.digest_window_seconds) : '';
+        this.formData.digest_flush_on_resolve = !!ch.digest_flush_on_resolve;
+        this.tagIds = ch.tag_ids || [];
+        this.events = {created:false, resolved:false, acknowledged:false, reminder:false, changed:false, certChanged:false, latencyAnomaly:false, checkCompleted:false, monitorDown:false, monitorRecovered:false, proxyDisabled:false, proxyEnabled:false};
         if (ch.events) {
             ch.events.forEach(e => {
                 if (e === 'incident.created') this.events.created = true;
@@ -74,23 +107,33 @@ func notifXData() string {
                 if (e === 'incident.reminder') this.events.reminder = true;
                 if (e === 'content.changed') this.events.changed = true;
                 if (e === 'cert.changed') this.events.certChanged = true;
+                if (e === 'monitor.latency_anomaly') this.events.latencyAnomaly = true;
+                if (e === 'check.completed') this.events.checkCompleted = true;
+                if (e === 'monitor.down') this.events.monitorDown = true;
+                if (e === 'monitor.recovered') this.events.monitorRecovered = true;
+                if (e === 'proxy.disabled') this.events.proxyDisabled = true;
+                if (e === 'proxy.enabled') this.events.proxyEnabled = true;
             });
         }
         let s = ch.settings || {};
         switch(ch.type) {
-            case 'webhook': this.webhook = {url: s.url||'', secret: s.secret||''}; break;
+            case 'webhook': this.webhook = {url: s.url||'', signing_secret: s.signing_secret||'', secret: s.secret||'', body_template: s.body_template||'', content_type: s.content_type||''}; break;
             case 'telegram': this.telegram = {bot_token: s.bot_token||'', chat_id: s.chat_id||''}; break;
             case 'discord': this.discord = {webhook_url: s.webhook_url||''}; break;
             case 'slack': this.slack = {webhook_url: s.webhook_url||'', channel: s.channel||''}; break;
-            case 'email': this.email = {host: s.host||'', port: s.port||587, username: s.username||'', password: s.password||'', from: s.from||'', to: (s.to||[]).join(', '), tls_mode: s.tls_mode||'starttls', cc: (s.cc||[]).join(', '), bcc: (s.bcc||[]).join(', ')}; break;
-            case 'ntfy': this.ntfy = {server_url: s.server_url||'', topic: s.topic||'', priority: String(s.priority||3), tags: s.tags||'', click_url: s.click_url||''}; break;
+            case 'email': this.email = {host: s.host||'', port: s.port||587, username: s.username||'', password: s.password||'', from: s.from||'', to: (s.to||[]).join(', '), tls_mode: s.tls_mode||'starttls', cc: (s.cc||[]).join(', '), bcc: (s.bcc||[]).join(', '), reply_to: s.reply_to||'', subject_template: s.subject_template||'', html_template: s.html_template||''}; break;
+            case 'ntfy': this.ntfy = {server_url: s.server_url||'', topic: s.topic||'', priority: s.priority ? String(s.priority) : '', tags: s.tags||'', click_url: s.click_url||''}; break;
             case 'teams': this.teams = {webhook_url: s.webhook_url||''}; break;
             case 'pagerduty': this.pagerduty = {routing_key: s.routing_key||''}; break;
             case 'opsgenie': this.opsgenie = {api_key: s.api_key||'', region: s.region||''}; break;
             case 'pushover': this.pushover = {user_key: s.user_key||'', app_token: s.app_token||'', priority: String(s.priority||0), sound: s.sound||'', device: s.device||''}; break;
             case 'googlechat': this.googlechat = {webhook_url: s.webhook_url||''}; break;
             case 'matrix': this.matrix = {homeserver: s.homeserver||'', access_token: s.access_token||'', room_id: s.room_id||''}; break;
-            case 'gotify': this.gotify = {server_url: s.server_url||'', app_token: s.app_token||'', priority: String(s.priority||5)}; break;
+            case 'gotify': this.gotify = {server_url: s.server_url||'', app_token: s.app_token||'', priority: s.priority ? String(s.priority) : ''}; break;
+            case 'mattermost': this.mattermost = {webhook_url: s.webhook_url||'', channel: s.channel||''}; break;
+            case 'rocketchat': this.rocketchat = {webhook_url: s.webhook_url||'', channel: s.channel||''}; break;
+            case 'twilio': this.twilio = {account_sid: s.account_sid||'', auth_token: s.auth_token||'', from_number: s.from_number||'', to_numbers: (s.to_numbers||[]).join(', ')}; break;
+            case 'signal': this.signal = {api_url: s.api_url||'', number: s.number||'', recipients: (s.recipients||[]).join(', ')}; break;
         }
         this.showForm = true;
     }
@@ -137,7 +180,7 @@ func NotificationListPage(p NotificationListParams) templ.Component {
 			var templ_7745c5c3_Var3 string
 			templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(notifXData())
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 94, Col: 28}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 137, Col: 28}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 			if templ_7745c5c3_Err != nil {
@@ -150,181 +193,232 @@ func NotificationListPage(p NotificationListParams) templ.Component {
 			var templ_7745c5c3_Var4 templ.SafeURL
 			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL(p.BasePath + "/notifications/history"))
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 98, Col: 67}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 141, Col: 67}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\" class=\"text-[12px] text-muted hover:text-white transition-colors\">History</a></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\" class=\"text-[12px] text-muted hover:text-white transition-colors\">History</a> <a href=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var5 templ.SafeURL
+			templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL(p.BasePath + "/oncall"))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 142, Col: 52}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "\" class=\"text-[12px] text-muted hover:text-white transition-colors\">On-call</a></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			if p.Perms["notifications.write"] {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<button @click=\"resetForm(); showForm = true\" class=\"inline-flex items-center gap-1.5 px-3 py-1.5 bg-brand hover:bg-brand/85 text-white text-[12px] font-medium rounded transition-colors\"><svg class=\"w-3 h-3\" viewBox=\"0 0 24 24\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2.5\"><path d=\"M12 5v14m7-7H5\"></path></svg> New Channel</button>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<button @click=\"resetForm(); showForm = true\" class=\"inline-flex items-center gap-1.5 px-3 py-1.5 bg-brand hover:bg-brand/85 text-white text-[12px] font-medium rounded transition-colors\"><svg class=\"w-3 h-3\" viewBox=\"0 0 24 24\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2.5\"><path d=\"M12 5v14m7-7H5\"></path></svg> New Channel</button>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			if len(p.Channels) > 0 {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<div class=\"grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-3\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<div class=\"grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-3\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 				for _, ch := range p.Channels {
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<div class=\"border border-line rounded-lg p-4 hover:border-line-light transition-colors\"><div class=\"flex items-center justify-between mb-2.5\"><div class=\"flex items-center gap-2\"><span class=\"text-[13px] text-white font-medium\">")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<div class=\"border border-line rounded-lg p-4 hover:border-line-light transition-colors\"><div class=\"flex items-center justify-between mb-2.5\"><div class=\"flex items-center gap-2\"><span class=\"text-[13px] text-white font-medium\">")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					var templ_7745c5c3_Var5 string
-					templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(ch.Name)
+					var templ_7745c5c3_Var6 string
+					templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(ch.Name)
 					if templ_7745c5c3_Err != nil {
-						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 114, Col: 67}
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 158, Col: 67}
 					}
-					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "</span> <span class=\"text-[10px] text-brand uppercase tracking-wider\">")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "</span> <span class=\"text-[10px] text-brand uppercase tracking-wider\">")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					var templ_7745c5c3_Var6 string
-					templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(ch.Type)
+					var templ_7745c5c3_Var7 string
+					templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(ch.Type)
 					if templ_7745c5c3_Err != nil {
-						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 115, Col: 80}
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 159, Col: 80}
 					}
-					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "</span></div>")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "</span></div>")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					var templ_7745c5c3_Var7 = []any{"w-1.5 h-1.5 rounded-full", templ.KV("bg-emerald-400", ch.Enabled), templ.KV("bg-muted", !ch.Enabled)}
-					templ_7745c5c3_Err = templ.RenderCSSItems(ctx, templ_7745c5c3_Buffer, templ_7745c5c3_Var7...)
+					var templ_7745c5c3_Var8 = []any{"w-1.5 h-1.5 rounded-full", templ.KV("bg-emerald-400", ch.Enabled), templ.KV("bg-muted", !ch.Enabled)}
+					templ_7745c5c3_Err = templ.RenderCSSItems(ctx, templ_7745c5c3_Buffer, templ_7745c5c3_Var8...)
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "<div class=\"")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "<div class=\"")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					var templ_7745c5c3_Var8 string
-					templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(templ.CSSClasses(templ_7745c5c3_Var7).String())
+					var templ_7745c5c3_Var9 string
+					templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(templ.CSSClasses(templ_7745c5c3_Var8).String())
 					if templ_7745c5c3_Err != nil {
 						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 1, Col: 0}
 					}
-					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "\"></div></div><div class=\"flex flex-wrap gap-1 mb-3\">")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "\"></div></div><div class=\"flex flex-wrap gap-1 mb-3\">")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					for _, ev := range ch.Events {
-						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "<span class=\"text-[10px] px-1.5 py-px rounded border border-line text-muted\">")
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "<span class=\"text-[10px] px-1.5 py-px rounded border border-line text-muted\">")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						var templ_7745c5c3_Var10 string
+						templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(ev)
+						if templ_7745c5c3_Err != nil {
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 165, Col: 90}
+						}
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "</span> ")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+					}
+					if len(ch.Schedule) > 0 {
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<span class=\"text-[10px] px-1.5 py-px rounded border border-brand/30 text-brand\">scheduled</span> ")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+					}
+					for _, tag := range p.channelTags(ch) {
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "<span class=\"inline-flex items-center gap-1 text-[10px] px-1.5 py-px rounded border border-line text-muted-light\" title=\"Also notifies for monitors with this tag\"><span class=\"w-1.5 h-1.5 rounded-full shrink-0\" style=\"")
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						var templ_7745c5c3_Var9 string
-						templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(ev)
+						var templ_7745c5c3_Var11 string
+						templ_7745c5c3_Var11, templ_7745c5c3_Err = templruntime.SanitizeStyleAttributeValues("background-color: " + tag.Color)
+						if templ_7745c5c3_Err != nil {
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 172, Col: 98}
+						}
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
 						if templ_7745c5c3_Err != nil {
-							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 121, Col: 90}
+							return templ_7745c5c3_Err
 						}
-						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "\"></span> ")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						var templ_7745c5c3_Var12 string
+						templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(tag.Name)
+						if templ_7745c5c3_Err != nil {
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 173, Col: 20}
+						}
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "</span>")
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "</span>")
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "</div>")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "</div>")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 					if p.Perms["notifications.write"] {
-						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<div class=\"flex items-center gap-1.5 pt-2.5 border-t border-line\"><button type=\"button\" @click=\"")
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "<div class=\"flex items-center gap-1.5 pt-2.5 border-t border-line\"><button type=\"button\" @click=\"")
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						var templ_7745c5c3_Var10 string
-						templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("editChannel(%s)", ToJSON(ch)))
+						var templ_7745c5c3_Var13 string
+						templ_7745c5c3_Var13, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("editChannel(%s)", ToJSON(ch)))
 						if templ_7745c5c3_Err != nil {
-							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 126, Col: 82}
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 179, Col: 82}
 						}
-						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var13))
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "\" class=\"px-2 py-1 text-[11px] text-brand border border-brand/20 rounded hover:bg-brand/5 transition-colors\">Edit</button><form method=\"POST\" action=\"")
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "\" class=\"px-2 py-1 text-[11px] text-brand border border-brand/20 rounded hover:bg-brand/5 transition-colors\">Edit</button><form method=\"POST\" action=\"")
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						var templ_7745c5c3_Var11 templ.SafeURL
-						templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL(fmt.Sprintf("%s/notifications/%d/test", p.BasePath, ch.ID)))
+						var templ_7745c5c3_Var14 templ.SafeURL
+						templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL(fmt.Sprintf("%s/notifications/%d/test", p.BasePath, ch.ID)))
 						if templ_7745c5c3_Err != nil {
-							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 128, Col: 111}
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 181, Col: 111}
 						}
-						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "\" class=\"contents\"><button type=\"submit\" class=\"px-2 py-1 text-[11px] text-brand border border-brand/20 rounded hover:bg-brand/5 transition-colors\">Test</button></form><form method=\"POST\" action=\"")
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "\" class=\"contents\"><button type=\"submit\" class=\"px-2 py-1 text-[11px] text-brand border border-brand/20 rounded hover:bg-brand/5 transition-colors\">Test</button></form><form method=\"POST\" action=\"")
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						var templ_7745c5c3_Var12 templ.SafeURL
-						templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL(fmt.Sprintf("%s/notifications/%d/delete", p.BasePath, ch.ID)))
+						var templ_7745c5c3_Var15 templ.SafeURL
+						templ_7745c5c3_Var15, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL(fmt.Sprintf("%s/notifications/%d/delete", p.BasePath, ch.ID)))
 						if templ_7745c5c3_Err != nil {
-							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 131, Col: 113}
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 184, Col: 113}
 						}
-						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var15))
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
-						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "\" x-data @submit.prevent=\"if(confirm('Delete this channel?')) $el.submit()\" class=\"contents\"><button type=\"submit\" class=\"px-2 py-1 text-[11px] text-red-400 border border-red-500/20 rounded hover:bg-red-500/5 transition-colors\">Delete</button></form></div>")
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "\" x-data @submit.prevent=\"if(confirm('Delete this channel?')) $el.submit()\" class=\"contents\"><button type=\"submit\" class=\"px-2 py-1 text-[11px] text-red-400 border border-red-500/20 rounded hover:bg-red-500/5 transition-colors\">Delete</button></form></div>")
 						if templ_7745c5c3_Err != nil {
 							return templ_7745c5c3_Err
 						}
 					}
-					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "</div>")
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "</div>")
 					if templ_7745c5c3_Err != nil {
 						return templ_7745c5c3_Err
 					}
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "</div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "</div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			} else {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "<div class=\"border border-line rounded-lg px-4 py-16 text-center\"><p class=\"text-muted text-[13px] mb-2\">No notification channels</p><button @click=\"resetForm(); showForm = true\" class=\"text-[12px] text-brand hover:text-brand/80 transition-colors\">Create one</button></div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "<div class=\"border border-line rounded-lg px-4 py-16 text-center\"><p class=\"text-muted text-[13px] mb-2\">No notification channels</p><button @click=\"resetForm(); showForm = true\" class=\"text-[12px] text-brand hover:text-brand/80 transition-colors\">Create one</button></div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "<!-- Modal --><div x-show=\"showForm\" x-cloak x-transition.opacity class=\"fixed inset-0 z-50 flex items-center justify-center bg-black/50 px-4\" @click.self=\"showForm = false\"><div class=\"bg-surface-100 border border-line rounded-lg p-5 w-full max-w-md max-h-[90vh] overflow-y-auto\" x-show=\"showForm\" x-transition @click.stop><h3 class=\"text-[15px] font-medium text-white mb-4\" x-text=\"editId ? 'Edit Notification Channel' : 'New Notification Channel'\"></h3><form method=\"POST\" data-base-action=\"")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "<!-- Modal --><div x-show=\"showForm\" x-cloak x-transition.opacity class=\"fixed inset-0 z-50 flex items-center justify-center bg-black/50 px-4\" @click.self=\"showForm = false\"><div class=\"bg-surface-100 border border-line rounded-lg p-5 w-full max-w-md max-h-[90vh] overflow-y-auto\" x-show=\"showForm\" x-transition @click.stop><h3 class=\"text-[15px] font-medium text-white mb-4\" x-text=\"editId ? 'Edit Notification Channel' : 'New Notification Channel'\"></h3><form method=\"POST\" data-base-action=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var13 string
-			templ_7745c5c3_Var13, templ_7745c5c3_Err = templ.JoinStringErrs(p.BasePath + "/notifications")
+			var templ_7745c5c3_Var16 string
+			templ_7745c5c3_Var16, templ_7745c5c3_Err = templ.JoinStringErrs(p.BasePath + "/notifications")
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 149, Col: 73}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 202, Col: 73}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var13))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var16))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "\" @submit=\"if(editId) $el.action = $el.dataset.baseAction + '/' + editId; else $el.action = $el.dataset.baseAction\" class=\"space-y-3\"><div><label class=\"form-label\">Name</label> <input type=\"text\" name=\"name\" x-model=\"formData.name\" required class=\"form-input\"></div><div><label class=\"form-label\">Type</label> <select name=\"type\" x-model=\"formData.type\" class=\"form-select\"><option value=\"webhook\">Webhook</option> <option value=\"email\">Email</option> <option value=\"telegram\">Telegram</option> <option value=\"discord\">Discord</option> <option value=\"slack\">Slack</option> <option value=\"ntfy\">ntfy</option> <option value=\"teams\">Microsoft Teams</option> <option value=\"pagerduty\">PagerDuty</option> <option value=\"opsgenie\">Opsgenie</option> <option value=\"pushover\">Pushover</option> <option value=\"googlechat\">Google Chat</option> <option value=\"matrix\">Matrix</option> <option value=\"gotify\">Gotify</option></select></div><!-- Settings --><div><div class=\"flex items-center justify-between mb-1.5\"><label class=\"form-label mb-0!\">Settings</label> <button type=\"button\" @click=\"advancedNotifSettings = !advancedNotifSettings\" class=\"text-[11px] text-brand hover:text-brand/80 transition-colors\"><span x-text=\"advancedNotifSettings ? 'Form Mode' : 'Advanced (JSON)'\"></span></button></div><input type=\"hidden\" name=\"notif_settings_mode\" :value=\"advancedNotifSettings ? 'json' : 'form'\"><!-- Advanced JSON --><div x-show=\"advancedNotifSettings\" x-cloak><textarea name=\"settings_json\" x-model=\"formData.settings_json\" rows=\"4\" class=\"form-input font-mono resize-y\"></textarea></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "\" @submit=\"if(editId) $el.action = $el.dataset.baseAction + '/' + editId; else $el.action = $el.dataset.baseAction\" class=\"space-y-3\"><div><label class=\"form-label\">Name</label> <input type=\"text\" name=\"name\" x-model=\"formData.name\" required class=\"form-input\"></div><div><label class=\"form-label\">Type</label> <select name=\"type\" x-model=\"formData.type\" class=\"form-select\"><option value=\"webhook\">Webhook</option> <option value=\"email\">Email</option> <option value=\"telegram\">Telegram</option> <option value=\"discord\">Discord</option> <option value=\"slack\">Slack</option> <option value=\"ntfy\">ntfy</option> <option value=\"teams\">Microsoft Teams</option> <option value=\"pagerduty\">PagerDuty</option> <option value=\"opsgenie\">Opsgenie</option> <option value=\"pushover\">Pushover</option> <option value=\"googlechat\">Google Chat</option> <option value=\"matrix\">Matrix</option> <option value=\"gotify\">Gotify</option> <option value=\"mattermost\">Mattermost</option> <option value=\"rocketchat\">Rocket.Chat</option> <option value=\"twilio\">Twilio SMS</option> <option value=\"signal\">Signal</option></select></div><!-- Settings --><div><div class=\"flex items-center justify-between mb-1.5\"><label class=\"form-label mb-0!\">Settings</label> <button type=\"button\" @click=\"advancedNotifSettings = !advancedNotifSettings\" class=\"text-[11px] text-brand hover:text-brand/80 transition-colors\"><span x-text=\"advancedNotifSettings ? 'Form Mode' : 'Advanced (JSON)'\"></span></button></div><input type=\"hidden\" name=\"notif_settings_mode\" :value=\"advancedNotifSettings ? 'json' : 'form'\"><!-- Advanced JSON --><div x-show=\"advancedNotifSettings\" x-cloak><textarea name=\"settings_json\" x-model=\"formData.settings_json\" rows=\"4\" class=\"form-input font-mono resize-y\"></textarea></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -380,7 +474,141 @@ func NotificationListPage(p NotificationListParams) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "</div><!-- Events --><div><label class=\"form-label mb-2\">Events</label><div class=\"grid grid-cols-2 gap-2\"><label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_incident_created\" :checked=\"events.created\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Incident Created</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_incident_resolved\" :checked=\"events.resolved\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Incident Resolved</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_incident_acknowledged\" :checked=\"events.acknowledged\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Incident Acknowledged</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_incident_reminder\" :checked=\"events.reminder\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Incident Reminder</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_content_changed\" :checked=\"events.changed\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Content Changed</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_cert_changed\" :checked=\"events.certChanged\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Certificate Changed</span></label></div></div><label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"enabled\" :checked=\"formData.enabled\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Enabled</span></label><div class=\"flex items-center gap-3 pt-1\"><button type=\"submit\" class=\"btn-primary\" x-text=\"editId ? 'Update' : 'Create'\"></button> <button type=\"button\" @click=\"showForm = false\" class=\"text-[13px] text-muted hover:text-muted-light transition-colors\">Cancel</button></div></form></div></div></div>")
+			templ_7745c5c3_Err = notifMattermostFields().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = notifRocketchatFields().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = notifTwilioFields().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = notifSignalFields().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, "</div><!-- Events --><div><label class=\"form-label mb-2\">Events</label><div class=\"grid grid-cols-2 gap-2\"><label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_incident_created\" :checked=\"events.created\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Incident Created</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_incident_resolved\" :checked=\"events.resolved\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Incident Resolved</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_incident_acknowledged\" :checked=\"events.acknowledged\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Incident Acknowledged</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_incident_reminder\" :checked=\"events.reminder\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Incident Reminder</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_content_changed\" :checked=\"events.changed\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Content Changed</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_cert_changed\" :checked=\"events.certChanged\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Certificate Changed</span></label> <label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"event_latency_anomaly\" :checked=\"events.latencyAnomaly\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Latency Anomaly</span></label> <label class=\"flex items-center gap-2 cursor-pointer\" title=\"Every change to down, without waiting for the failure threshold\"><input type=\"checkbox\" name=\"event_monitor_down\" :checked=\"events.monitorDown\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Monitor Down</span></label> <label class=\"flex items-center gap-2 cursor-pointer\" title=\"Every change away from down, without waiting for the success threshold\"><input type=\"checkbox\" name=\"event_monitor_recovered\" :checked=\"events.monitorRecovered\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Monitor Recovered</span></label> <label class=\"flex items-center gap-2 cursor-pointer\" title=\"A proxy was disabled after failing its health checks\"><input type=\"checkbox\" name=\"event_proxy_disabled\" :checked=\"events.proxyDisabled\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Proxy Disabled</span></label> <label class=\"flex items-center gap-2 cursor-pointer\" title=\"A proxy disabled by its health checks passed again\"><input type=\"checkbox\" name=\"event_proxy_enabled\" :checked=\"events.proxyEnabled\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Proxy Enabled</span></label> <label class=\"flex items-center gap-2 cursor-pointer\" x-show=\"formData.type === 'webhook'\" x-cloak title=\"Every sampled check result from monitors with streaming enabled\"><input type=\"checkbox\" name=\"event_check_completed\" :checked=\"events.checkCompleted\" :disabled=\"formData.type !== 'webhook'\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Check Completed</span></label></div></div><!-- Tags -->")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if len(p.Tags) > 0 {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 30, "<div><label class=\"form-label mb-2\">Tags</label><div class=\"flex flex-wrap gap-1.5\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				for _, tag := range p.Tags {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 31, "<button type=\"button\" @click=\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var17 string
+					templ_7745c5c3_Var17, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("toggleTag(%d)", tag.ID))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 322, Col: 56}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var17))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 32, "\" :class=\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var18 string
+					templ_7745c5c3_Var18, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("tagIds.includes(%d) ? 'border-brand/30 bg-brand/[0.06] text-white' : 'border-line hover:border-line-light text-muted-light'", tag.ID))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 323, Col: 166}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var18))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 33, "\" class=\"inline-flex items-center gap-1 rounded border px-1.5 py-0.5 text-[11px] transition-colors cursor-pointer select-none\"><span class=\"w-1.5 h-1.5 rounded-full shrink-0\" style=\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var19 string
+					templ_7745c5c3_Var19, templ_7745c5c3_Err = templruntime.SanitizeStyleAttributeValues("background-color: " + tag.Color)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 325, Col: 99}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var19))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 34, "\"></span> ")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var20 string
+					templ_7745c5c3_Var20, templ_7745c5c3_Err = templ.JoinStringErrs(tag.Name)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 326, Col: 21}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var20))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 35, "</button>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 36, "</div><template x-for=\"id in tagIds\" :key=\"id\"><input type=\"hidden\" name=\"tag_ids[]\" :value=\"id\"></template><p class=\"text-[10px] text-muted mt-1\">Also notify for every monitor carrying one of these tags. A tagged channel no longer receives alerts from untagged monitors without channels.</p></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 37, "<!-- On-call -->")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if len(p.Oncall) > 0 {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 38, "<div x-show=\"['email', 'pagerduty', 'opsgenie'].includes(formData.type)\" x-cloak><label class=\"form-label\">On-call Schedule</label> <select name=\"oncall_schedule_id\" x-model=\"formData.oncall_schedule_id\" class=\"form-select\"><option value=\"\">None (use the recipients above)</option> ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				for _, s := range p.Oncall {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 39, "<option value=\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var21 string
+					templ_7745c5c3_Var21, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprint(s.ID))
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 343, Col: 42}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var21))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 40, "\">")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var22 string
+					templ_7745c5c3_Var22, templ_7745c5c3_Err = templ.JoinStringErrs(s.Name)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 343, Col: 53}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var22))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 41, "</option>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 42, "</select><p class=\"text-[10px] text-muted mt-1\">Send to whoever is on call instead: their email, Opsgenie user or PagerDuty routing key</p></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 43, "<!-- Digest --><div x-show=\"!['pagerduty', 'opsgenie'].includes(formData.type)\" x-cloak><label class=\"form-label\">Digest Window (seconds)</label> <input type=\"number\" name=\"digest_window_seconds\" x-model=\"formData.digest_window_seconds\" min=\"10\" max=\"3600\" placeholder=\"Off\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Collect events for this long and send one summary listing the affected monitors (10-3600, empty = send each event)</p><label x-show=\"formData.digest_window_seconds\" class=\"flex items-center gap-2 cursor-pointer mt-2\"><input type=\"checkbox\" name=\"digest_flush_on_resolve\" :checked=\"formData.digest_flush_on_resolve\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Send the digest right away when an incident resolves</span></label></div><!-- Schedule --><div><label class=\"form-label\">Active Schedule (JSON, empty = always)</label> <textarea name=\"schedule_json\" x-model=\"formData.schedule_json\" rows=\"3\" class=\"form-input font-mono resize-y\" placeholder='{\"timezone\":\"Europe/Amsterdam\",\"windows\":[{\"days\":[\"mon\",\"tue\",\"wed\",\"thu\",\"fri\"],\"start\":\"09:00\",\"end\":\"17:00\"}]}'></textarea></div><label class=\"flex items-center gap-2 cursor-pointer\"><input type=\"checkbox\" name=\"enabled\" :checked=\"formData.enabled\" class=\"form-checkbox\"> <span class=\"text-[12px] text-muted-light\">Enabled</span></label><div class=\"flex items-center gap-3 pt-1\"><button type=\"submit\" class=\"btn-primary\" x-text=\"editId ? 'Update' : 'Create'\"></button> <button type=\"button\" @click=\"showForm = false\" class=\"text-[13px] text-muted hover:text-muted-light transition-colors\">Cancel</button></div></form></div></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -410,12 +638,25 @@ func notifWebhookFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var14 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var14 == nil {
-			templ_7745c5c3_Var14 = templ.NopComponent
+		templ_7745c5c3_Var23 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var23 == nil {
+			templ_7745c5c3_Var23 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "<div x-show=\"!advancedNotifSettings && formData.type === 'webhook'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">URL</label> <input type=\"url\" name=\"notif_webhook_url\" x-model=\"webhook.url\" :required=\"!advancedNotifSettings && formData.type === 'webhook'\" placeholder=\"https://example.com/webhook\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Secret</label> <input type=\"text\" name=\"notif_webhook_secret\" x-model=\"webhook.secret\" placeholder=\"Optional HMAC-SHA256 secret\" class=\"form-input\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 44, "<div x-show=\"!advancedNotifSettings && formData.type === 'webhook'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">URL</label> <input type=\"text\" name=\"notif_webhook_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var24 string
+		templ_7745c5c3_Var24, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 384, Col: 88}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var24))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 45, "\" x-model=\"webhook.url\" :required=\"!advancedNotifSettings && formData.type === 'webhook'\" placeholder=\"https://example.com/webhook\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Signing Secret</label> <input type=\"text\" name=\"notif_webhook_signing_secret\" x-model=\"webhook.signing_secret\" placeholder=\"Optional HMAC-SHA256 secret\" class=\"form-input\"></div><div x-show=\"webhook.secret\" x-cloak><label class=\"form-label-sm\">Legacy Secret</label> <input type=\"text\" name=\"notif_webhook_secret\" x-model=\"webhook.secret\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Signs the body without a timestamp. Clear it once the receiver uses the signing secret.</p></div><div><label class=\"form-label-sm\">Body Template</label> <textarea name=\"notif_webhook_body_template\" x-model=\"webhook.body_template\" rows=\"4\" class=\"form-input font-mono resize-y\" placeholder='Optional Go template, e.g. {\"summary\":{{ json .Incident.Cause }}}'></textarea></div><div x-show=\"webhook.body_template\"><label class=\"form-label-sm\">Content Type</label> <input type=\"text\" name=\"notif_webhook_content_type\" x-model=\"webhook.content_type\" placeholder=\"application/json\" class=\"form-input\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -439,12 +680,12 @@ func notifTelegramFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var15 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var15 == nil {
-			templ_7745c5c3_Var15 = templ.NopComponent
+		templ_7745c5c3_Var25 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var25 == nil {
+			templ_7745c5c3_Var25 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "<div x-show=\"!advancedNotifSettings && formData.type === 'telegram'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Bot Token</label> <input type=\"text\" name=\"notif_telegram_bot_token\" x-model=\"telegram.bot_token\" :required=\"!advancedNotifSettings && formData.type === 'telegram'\" placeholder=\"123456:ABC-DEF1234...\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Chat ID</label> <input type=\"text\" name=\"notif_telegram_chat_id\" x-model=\"telegram.chat_id\" :required=\"!advancedNotifSettings && formData.type === 'telegram'\" placeholder=\"-1001234567890\" class=\"form-input\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 46, "<div x-show=\"!advancedNotifSettings && formData.type === 'telegram'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Bot Token</label> <input type=\"text\" name=\"notif_telegram_bot_token\" x-model=\"telegram.bot_token\" :required=\"!advancedNotifSettings && formData.type === 'telegram'\" placeholder=\"123456:ABC-DEF1234...\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Chat ID</label> <input type=\"text\" name=\"notif_telegram_chat_id\" x-model=\"telegram.chat_id\" :required=\"!advancedNotifSettings && formData.type === 'telegram'\" placeholder=\"-1001234567890\" class=\"form-input\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -468,12 +709,25 @@ func notifDiscordFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var16 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var16 == nil {
-			templ_7745c5c3_Var16 = templ.NopComponent
+		templ_7745c5c3_Var26 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var26 == nil {
+			templ_7745c5c3_Var26 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "<div x-show=\"!advancedNotifSettings && formData.type === 'discord'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Webhook URL</label> <input type=\"url\" name=\"notif_discord_webhook_url\" x-model=\"discord.webhook_url\" :required=\"!advancedNotifSettings && formData.type === 'discord'\" placeholder=\"https://discord.com/api/webhooks/...\" class=\"form-input\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 47, "<div x-show=\"!advancedNotifSettings && formData.type === 'discord'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Webhook URL</label> <input type=\"text\" name=\"notif_discord_webhook_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var27 string
+		templ_7745c5c3_Var27, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 424, Col: 96}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var27))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 48, "\" x-model=\"discord.webhook_url\" :required=\"!advancedNotifSettings && formData.type === 'discord'\" placeholder=\"https://discord.com/api/webhooks/...\" class=\"form-input\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -497,12 +751,25 @@ func notifSlackFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var17 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var17 == nil {
-			templ_7745c5c3_Var17 = templ.NopComponent
+		templ_7745c5c3_Var28 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var28 == nil {
+			templ_7745c5c3_Var28 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "<div x-show=\"!advancedNotifSettings && formData.type === 'slack'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Webhook URL</label> <input type=\"url\" name=\"notif_slack_webhook_url\" x-model=\"slack.webhook_url\" :required=\"!advancedNotifSettings && formData.type === 'slack'\" placeholder=\"https://hooks.slack.com/services/...\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Channel</label> <input type=\"text\" name=\"notif_slack_channel\" x-model=\"slack.channel\" placeholder=\"Optional (e.g. #alerts)\" class=\"form-input\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 49, "<div x-show=\"!advancedNotifSettings && formData.type === 'slack'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Webhook URL</label> <input type=\"text\" name=\"notif_slack_webhook_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var29 string
+		templ_7745c5c3_Var29, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 433, Col: 94}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var29))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 50, "\" x-model=\"slack.webhook_url\" :required=\"!advancedNotifSettings && formData.type === 'slack'\" placeholder=\"https://hooks.slack.com/services/...\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Channel</label> <input type=\"text\" name=\"notif_slack_channel\" x-model=\"slack.channel\" placeholder=\"Optional (e.g. #alerts)\" class=\"form-input\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -526,12 +793,12 @@ func notifEmailFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var18 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var18 == nil {
-			templ_7745c5c3_Var18 = templ.NopComponent
+		templ_7745c5c3_Var30 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var30 == nil {
+			templ_7745c5c3_Var30 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, "<div x-show=\"!advancedNotifSettings && formData.type === 'email'\" x-cloak class=\"space-y-3\"><div class=\"grid grid-cols-3 gap-3\"><div class=\"col-span-2\"><label class=\"form-label-sm\">SMTP Host</label> <input type=\"text\" name=\"notif_email_host\" x-model=\"email.host\" :required=\"!advancedNotifSettings && formData.type === 'email'\" placeholder=\"smtp.example.com\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Port</label> <input type=\"number\" name=\"notif_email_port\" x-model=\"email.port\" placeholder=\"587\" class=\"form-input tabular-nums\"></div></div><div class=\"grid grid-cols-2 gap-3\"><div><label class=\"form-label-sm\">Username</label> <input type=\"text\" name=\"notif_email_username\" x-model=\"email.username\" placeholder=\"SMTP user\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Password</label> <input type=\"password\" name=\"notif_email_password\" x-model=\"email.password\" placeholder=\"SMTP password\" class=\"form-input\"></div></div><div><label class=\"form-label-sm\">From</label> <input type=\"email\" name=\"notif_email_from\" x-model=\"email.from\" :required=\"!advancedNotifSettings && formData.type === 'email'\" placeholder=\"alerts@example.com\" class=\"form-input\"></div><div><label class=\"form-label-sm\">To</label> <input type=\"text\" name=\"notif_email_to\" x-model=\"email.to\" :required=\"!advancedNotifSettings && formData.type === 'email'\" placeholder=\"admin@example.com, ops@example.com\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Comma-separated</p></div><div><label class=\"form-label-sm\">TLS Mode</label> <select name=\"notif_email_tls_mode\" x-model=\"email.tls_mode\" class=\"form-select\"><option value=\"starttls\">STARTTLS (default, port 587)</option> <option value=\"smtps\">SMTPS (port 465)</option> <option value=\"none\">None (plain, port 25)</option></select></div><div><label class=\"form-label-sm\">CC</label> <input type=\"text\" name=\"notif_email_cc\" x-model=\"email.cc\" placeholder=\"cc@example.com\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Comma-separated (optional)</p></div><div><label class=\"form-label-sm\">BCC</label> <input type=\"text\" name=\"notif_email_bcc\" x-model=\"email.bcc\" placeholder=\"bcc@example.com\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Comma-separated (optional)</p></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 51, "<div x-show=\"!advancedNotifSettings && formData.type === 'email'\" x-cloak class=\"space-y-3\"><div class=\"grid grid-cols-3 gap-3\"><div class=\"col-span-2\"><label class=\"form-label-sm\">SMTP Host</label> <input type=\"text\" name=\"notif_email_host\" x-model=\"email.host\" :required=\"!advancedNotifSettings && formData.type === 'email'\" placeholder=\"smtp.example.com\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Port</label> <input type=\"number\" name=\"notif_email_port\" x-model=\"email.port\" placeholder=\"587\" class=\"form-input tabular-nums\"></div></div><div class=\"grid grid-cols-2 gap-3\"><div><label class=\"form-label-sm\">Username</label> <input type=\"text\" name=\"notif_email_username\" x-model=\"email.username\" placeholder=\"SMTP user\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Password</label> <input type=\"password\" name=\"notif_email_password\" x-model=\"email.password\" placeholder=\"SMTP password\" class=\"form-input\"></div></div><div><label class=\"form-label-sm\">From</label> <input type=\"email\" name=\"notif_email_from\" x-model=\"email.from\" :required=\"!advancedNotifSettings && formData.type === 'email'\" placeholder=\"alerts@example.com\" class=\"form-input\"></div><div><label class=\"form-label-sm\">To</label> <input type=\"text\" name=\"notif_email_to\" x-model=\"email.to\" :required=\"!advancedNotifSettings && formData.type === 'email'\" placeholder=\"admin@example.com, ops@example.com\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Comma-separated</p></div><div><label class=\"form-label-sm\">TLS Mode</label> <select name=\"notif_email_tls_mode\" x-model=\"email.tls_mode\" class=\"form-select\"><option value=\"starttls\">STARTTLS (default, port 587)</option> <option value=\"smtps\">SMTPS (port 465)</option> <option value=\"none\">None (plain, port 25)</option></select></div><div><label class=\"form-label-sm\">CC</label> <input type=\"text\" name=\"notif_email_cc\" x-model=\"email.cc\" placeholder=\"cc@example.com\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Comma-separated (optional)</p></div><div><label class=\"form-label-sm\">BCC</label> <input type=\"text\" name=\"notif_email_bcc\" x-model=\"email.bcc\" placeholder=\"bcc@example.com\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Comma-separated (optional)</p></div><div><label class=\"form-label-sm\">Reply-To</label> <input type=\"email\" name=\"notif_email_reply_to\" x-model=\"email.reply_to\" placeholder=\"oncall@example.com\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Subject Template</label> <input type=\"text\" name=\"notif_email_subject_template\" x-model=\"email.subject_template\" class=\"form-input font-mono\" placeholder=\"Optional Go template, e.g. [{{ upper .EventType }}] {{ .Incident.MonitorName }}\"></div><div><label class=\"form-label-sm\">HTML Template</label> <textarea name=\"notif_email_html_template\" x-model=\"email.html_template\" rows=\"4\" class=\"form-input font-mono resize-y\" placeholder=\"Optional Go template, e.g. <h1>{{ .Incident.MonitorName }}</h1>\"></textarea><p class=\"text-[10px] text-muted mt-1\">Replaces the built-in layout. A plain-text version is added.</p></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -555,12 +822,38 @@ func notifNtfyFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var19 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var19 == nil {
-			templ_7745c5c3_Var19 = templ.NopComponent
+		templ_7745c5c3_Var31 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var31 == nil {
+			templ_7745c5c3_Var31 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 30, "<div x-show=\"!advancedNotifSettings && formData.type === 'ntfy'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Server URL</label> <input type=\"url\" name=\"notif_ntfy_server_url\" x-model=\"ntfy.server_url\" placeholder=\"https://ntfy.sh\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Leave empty for ntfy.sh</p></div><div><label class=\"form-label-sm\">Topic</label> <input type=\"text\" name=\"notif_ntfy_topic\" x-model=\"ntfy.topic\" :required=\"!advancedNotifSettings && formData.type === 'ntfy'\" placeholder=\"asura-alerts\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Priority</label> <select name=\"notif_ntfy_priority\" x-model=\"ntfy.priority\" class=\"form-select\"><option value=\"1\">1 — Min</option> <option value=\"2\">2 — Low</option> <option value=\"3\">3 — Default</option> <option value=\"4\">4 — High</option> <option value=\"5\">5 — Urgent</option></select></div><div><label class=\"form-label-sm\">Tags</label> <input type=\"text\" name=\"notif_ntfy_tags\" x-model=\"ntfy.tags\" placeholder=\"warning,server\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Comma-separated emoji tags</p></div><div><label class=\"form-label-sm\">Click URL</label> <input type=\"url\" name=\"notif_ntfy_click_url\" x-model=\"ntfy.click_url\" placeholder=\"https://status.example.com\" class=\"form-input\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 52, "<div x-show=\"!advancedNotifSettings && formData.type === 'ntfy'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Server URL</label> <input type=\"text\" name=\"notif_ntfy_server_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var32 string
+		templ_7745c5c3_Var32, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 513, Col: 92}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var32))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 53, "\" x-model=\"ntfy.server_url\" placeholder=\"https://ntfy.sh\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Leave empty for ntfy.sh</p></div><div><label class=\"form-label-sm\">Topic</label> <input type=\"text\" name=\"notif_ntfy_topic\" x-model=\"ntfy.topic\" :required=\"!advancedNotifSettings && formData.type === 'ntfy'\" placeholder=\"asura-alerts\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Priority</label> <select name=\"notif_ntfy_priority\" x-model=\"ntfy.priority\" class=\"form-select\"><option value=\"\">By severity</option> <option value=\"1\">1 — Min</option> <option value=\"2\">2 — Low</option> <option value=\"3\">3 — Default</option> <option value=\"4\">4 — High</option> <option value=\"5\">5 — Urgent</option></select></div><div><label class=\"form-label-sm\">Tags</label> <input type=\"text\" name=\"notif_ntfy_tags\" x-model=\"ntfy.tags\" placeholder=\"warning,server\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Comma-separated emoji tags</p></div><div><label class=\"form-label-sm\">Click URL</label> <input type=\"text\" name=\"notif_ntfy_click_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var33 string
+		templ_7745c5c3_Var33, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 538, Col: 91}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var33))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 54, "\" x-model=\"ntfy.click_url\" placeholder=\"https://status.example.com\" class=\"form-input\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -584,12 +877,25 @@ func notifTeamsFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var20 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var20 == nil {
-			templ_7745c5c3_Var20 = templ.NopComponent
+		templ_7745c5c3_Var34 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var34 == nil {
+			templ_7745c5c3_Var34 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 31, "<div x-show=\"!advancedNotifSettings && formData.type === 'teams'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Webhook URL</label> <input type=\"url\" name=\"notif_teams_webhook_url\" x-model=\"teams.webhook_url\" :required=\"!advancedNotifSettings && formData.type === 'teams'\" placeholder=\"https://outlook.office.com/webhook/...\" class=\"form-input\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 55, "<div x-show=\"!advancedNotifSettings && formData.type === 'teams'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Webhook URL</label> <input type=\"text\" name=\"notif_teams_webhook_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var35 string
+		templ_7745c5c3_Var35, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 547, Col: 94}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var35))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 56, "\" x-model=\"teams.webhook_url\" :required=\"!advancedNotifSettings && formData.type === 'teams'\" placeholder=\"https://outlook.office.com/webhook/...\" class=\"form-input\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -613,12 +919,12 @@ func notifPagerdutyFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var21 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var21 == nil {
-			templ_7745c5c3_Var21 = templ.NopComponent
+		templ_7745c5c3_Var36 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var36 == nil {
+			templ_7745c5c3_Var36 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 32, "<div x-show=\"!advancedNotifSettings && formData.type === 'pagerduty'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Routing Key</label> <input type=\"text\" name=\"notif_pagerduty_routing_key\" x-model=\"pagerduty.routing_key\" :required=\"!advancedNotifSettings && formData.type === 'pagerduty'\" placeholder=\"Events API v2 integration key\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">From your PagerDuty service integration</p></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 57, "<div x-show=\"!advancedNotifSettings && formData.type === 'pagerduty'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Routing Key</label> <input type=\"text\" name=\"notif_pagerduty_routing_key\" x-model=\"pagerduty.routing_key\" :required=\"!advancedNotifSettings && formData.type === 'pagerduty'\" placeholder=\"Events API v2 integration key\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">From your PagerDuty service integration</p></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -642,12 +948,12 @@ func notifOpsgenieFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var22 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var22 == nil {
-			templ_7745c5c3_Var22 = templ.NopComponent
+		templ_7745c5c3_Var37 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var37 == nil {
+			templ_7745c5c3_Var37 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 33, "<div x-show=\"!advancedNotifSettings && formData.type === 'opsgenie'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">API Key</label> <input type=\"text\" name=\"notif_opsgenie_api_key\" x-model=\"opsgenie.api_key\" :required=\"!advancedNotifSettings && formData.type === 'opsgenie'\" placeholder=\"Opsgenie API integration key\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Region</label> <select name=\"notif_opsgenie_region\" x-model=\"opsgenie.region\" class=\"form-select\"><option value=\"\">US (default)</option> <option value=\"eu\">EU</option></select></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 58, "<div x-show=\"!advancedNotifSettings && formData.type === 'opsgenie'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">API Key</label> <input type=\"text\" name=\"notif_opsgenie_api_key\" x-model=\"opsgenie.api_key\" :required=\"!advancedNotifSettings && formData.type === 'opsgenie'\" placeholder=\"Opsgenie API integration key\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Region</label> <select name=\"notif_opsgenie_region\" x-model=\"opsgenie.region\" class=\"form-select\"><option value=\"\">US (default)</option> <option value=\"eu\">EU</option></select></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -671,12 +977,12 @@ func notifPushoverFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var23 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var23 == nil {
-			templ_7745c5c3_Var23 = templ.NopComponent
+		templ_7745c5c3_Var38 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var38 == nil {
+			templ_7745c5c3_Var38 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 34, "<div x-show=\"!advancedNotifSettings && formData.type === 'pushover'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">User Key</label> <input type=\"text\" name=\"notif_pushover_user_key\" x-model=\"pushover.user_key\" :required=\"!advancedNotifSettings && formData.type === 'pushover'\" placeholder=\"Your Pushover user key\" class=\"form-input\"></div><div><label class=\"form-label-sm\">App Token</label> <input type=\"text\" name=\"notif_pushover_app_token\" x-model=\"pushover.app_token\" :required=\"!advancedNotifSettings && formData.type === 'pushover'\" placeholder=\"Your Pushover application token\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Priority</label> <select name=\"notif_pushover_priority\" x-model=\"pushover.priority\" class=\"form-select\"><option value=\"-2\">Lowest</option> <option value=\"-1\">Low</option> <option value=\"0\">Normal (default)</option> <option value=\"1\">High</option> <option value=\"2\">Emergency</option></select><p class=\"text-[10px] text-muted mt-1\">0 = auto-select based on event type</p></div><div><label class=\"form-label-sm\">Sound</label> <input type=\"text\" name=\"notif_pushover_sound\" x-model=\"pushover.sound\" placeholder=\"pushover (default)\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Device</label> <input type=\"text\" name=\"notif_pushover_device\" x-model=\"pushover.device\" placeholder=\"All devices (default)\" class=\"form-input\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 59, "<div x-show=\"!advancedNotifSettings && formData.type === 'pushover'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">User Key</label> <input type=\"text\" name=\"notif_pushover_user_key\" x-model=\"pushover.user_key\" :required=\"!advancedNotifSettings && formData.type === 'pushover'\" placeholder=\"Your Pushover user key\" class=\"form-input\"></div><div><label class=\"form-label-sm\">App Token</label> <input type=\"text\" name=\"notif_pushover_app_token\" x-model=\"pushover.app_token\" :required=\"!advancedNotifSettings && formData.type === 'pushover'\" placeholder=\"Your Pushover application token\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Priority</label> <select name=\"notif_pushover_priority\" x-model=\"pushover.priority\" class=\"form-select\"><option value=\"-2\">Lowest</option> <option value=\"-1\">Low</option> <option value=\"0\">Normal (default)</option> <option value=\"1\">High</option> <option value=\"2\">Emergency</option></select><p class=\"text-[10px] text-muted mt-1\">0 = auto-select based on event type</p></div><div><label class=\"form-label-sm\">Sound</label> <input type=\"text\" name=\"notif_pushover_sound\" x-model=\"pushover.sound\" placeholder=\"pushover (default)\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Device</label> <input type=\"text\" name=\"notif_pushover_device\" x-model=\"pushover.device\" placeholder=\"All devices (default)\" class=\"form-input\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -700,12 +1006,25 @@ func notifGooglechatFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var24 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var24 == nil {
-			templ_7745c5c3_Var24 = templ.NopComponent
+		templ_7745c5c3_Var39 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var39 == nil {
+			templ_7745c5c3_Var39 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 35, "<div x-show=\"!advancedNotifSettings && formData.type === 'googlechat'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Webhook URL</label> <input type=\"url\" name=\"notif_googlechat_webhook_url\" x-model=\"googlechat.webhook_url\" :required=\"!advancedNotifSettings && formData.type === 'googlechat'\" placeholder=\"https://chat.googleapis.com/v1/spaces/.../messages?key=...\" class=\"form-input\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 60, "<div x-show=\"!advancedNotifSettings && formData.type === 'googlechat'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Webhook URL</label> <input type=\"text\" name=\"notif_googlechat_webhook_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var40 string
+		templ_7745c5c3_Var40, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 614, Col: 99}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var40))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 61, "\" x-model=\"googlechat.webhook_url\" :required=\"!advancedNotifSettings && formData.type === 'googlechat'\" placeholder=\"https://chat.googleapis.com/v1/spaces/.../messages?key=...\" class=\"form-input\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -729,12 +1048,25 @@ func notifMatrixFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var25 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var25 == nil {
-			templ_7745c5c3_Var25 = templ.NopComponent
+		templ_7745c5c3_Var41 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var41 == nil {
+			templ_7745c5c3_Var41 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 36, "<div x-show=\"!advancedNotifSettings && formData.type === 'matrix'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Homeserver</label> <input type=\"url\" name=\"notif_matrix_homeserver\" x-model=\"matrix.homeserver\" :required=\"!advancedNotifSettings && formData.type === 'matrix'\" placeholder=\"https://matrix.org\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Access Token</label> <input type=\"text\" name=\"notif_matrix_access_token\" x-model=\"matrix.access_token\" :required=\"!advancedNotifSettings && formData.type === 'matrix'\" placeholder=\"syt_...\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Room ID</label> <input type=\"text\" name=\"notif_matrix_room_id\" x-model=\"matrix.room_id\" :required=\"!advancedNotifSettings && formData.type === 'matrix'\" placeholder=\"!roomid:matrix.org\" class=\"form-input\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 62, "<div x-show=\"!advancedNotifSettings && formData.type === 'matrix'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Homeserver</label> <input type=\"text\" name=\"notif_matrix_homeserver\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var42 string
+		templ_7745c5c3_Var42, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 623, Col: 94}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var42))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 63, "\" x-model=\"matrix.homeserver\" :required=\"!advancedNotifSettings && formData.type === 'matrix'\" placeholder=\"https://matrix.org\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Access Token</label> <input type=\"text\" name=\"notif_matrix_access_token\" x-model=\"matrix.access_token\" :required=\"!advancedNotifSettings && formData.type === 'matrix'\" placeholder=\"syt_...\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Room ID</label> <input type=\"text\" name=\"notif_matrix_room_id\" x-model=\"matrix.room_id\" :required=\"!advancedNotifSettings && formData.type === 'matrix'\" placeholder=\"!roomid:matrix.org\" class=\"form-input\"></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -758,12 +1090,180 @@ func notifGotifyFields() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var26 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var26 == nil {
-			templ_7745c5c3_Var26 = templ.NopComponent
+		templ_7745c5c3_Var43 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var43 == nil {
+			templ_7745c5c3_Var43 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 64, "<div x-show=\"!advancedNotifSettings && formData.type === 'gotify'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Server URL</label> <input type=\"text\" name=\"notif_gotify_server_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var44 string
+		templ_7745c5c3_Var44, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 640, Col: 94}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var44))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 65, "\" x-model=\"gotify.server_url\" :required=\"!advancedNotifSettings && formData.type === 'gotify'\" placeholder=\"https://gotify.example.com\" class=\"form-input\"></div><div><label class=\"form-label-sm\">App Token</label> <input type=\"text\" name=\"notif_gotify_app_token\" x-model=\"gotify.app_token\" :required=\"!advancedNotifSettings && formData.type === 'gotify'\" placeholder=\"Application token from Gotify\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Priority</label> <select name=\"notif_gotify_priority\" x-model=\"gotify.priority\" class=\"form-select\"><option value=\"\">By severity</option> <option value=\"1\">1 — Low</option> <option value=\"5\">5 — Normal</option> <option value=\"8\">8 — High</option> <option value=\"10\">10 — Max</option></select></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func notifMattermostFields() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var45 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var45 == nil {
+			templ_7745c5c3_Var45 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 66, "<div x-show=\"!advancedNotifSettings && formData.type === 'mattermost'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Webhook URL</label> <input type=\"text\" name=\"notif_mattermost_webhook_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var46 string
+		templ_7745c5c3_Var46, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 663, Col: 99}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var46))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 67, "\" x-model=\"mattermost.webhook_url\" :required=\"!advancedNotifSettings && formData.type === 'mattermost'\" placeholder=\"https://mattermost.example.com/hooks/...\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Channel</label> <input type=\"text\" name=\"notif_mattermost_channel\" x-model=\"mattermost.channel\" placeholder=\"Optional (e.g. town-square)\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Overrides the webhook's default channel if the webhook allows it</p></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func notifRocketchatFields() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var47 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var47 == nil {
+			templ_7745c5c3_Var47 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 37, "<div x-show=\"!advancedNotifSettings && formData.type === 'gotify'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Server URL</label> <input type=\"url\" name=\"notif_gotify_server_url\" x-model=\"gotify.server_url\" :required=\"!advancedNotifSettings && formData.type === 'gotify'\" placeholder=\"https://gotify.example.com\" class=\"form-input\"></div><div><label class=\"form-label-sm\">App Token</label> <input type=\"text\" name=\"notif_gotify_app_token\" x-model=\"gotify.app_token\" :required=\"!advancedNotifSettings && formData.type === 'gotify'\" placeholder=\"Application token from Gotify\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Priority</label> <select name=\"notif_gotify_priority\" x-model=\"gotify.priority\" class=\"form-select\"><option value=\"1\">1 — Low</option> <option value=\"5\">5 — Normal (default)</option> <option value=\"8\">8 — High</option> <option value=\"10\">10 — Max</option></select></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 68, "<div x-show=\"!advancedNotifSettings && formData.type === 'rocketchat'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Webhook URL</label> <input type=\"text\" name=\"notif_rocketchat_webhook_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var48 string
+		templ_7745c5c3_Var48, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 677, Col: 99}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var48))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 69, "\" x-model=\"rocketchat.webhook_url\" :required=\"!advancedNotifSettings && formData.type === 'rocketchat'\" placeholder=\"https://chat.example.com/hooks/...\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Channel</label> <input type=\"text\" name=\"notif_rocketchat_channel\" x-model=\"rocketchat.channel\" placeholder=\"Optional (e.g. #alerts or @user)\" class=\"form-input\"></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func notifTwilioFields() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var49 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var49 == nil {
+			templ_7745c5c3_Var49 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 70, "<div x-show=\"!advancedNotifSettings && formData.type === 'twilio'\" x-cloak class=\"space-y-3\"><div class=\"grid grid-cols-1 sm:grid-cols-2 gap-3\"><div><label class=\"form-label-sm\">Account SID</label> <input type=\"text\" name=\"notif_twilio_account_sid\" x-model=\"twilio.account_sid\" :required=\"!advancedNotifSettings && formData.type === 'twilio'\" placeholder=\"AC...\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Auth Token</label> <input type=\"password\" name=\"notif_twilio_auth_token\" x-model=\"twilio.auth_token\" :required=\"!advancedNotifSettings && formData.type === 'twilio'\" class=\"form-input\"></div></div><div><label class=\"form-label-sm\">From Number</label> <input type=\"text\" name=\"notif_twilio_from_number\" x-model=\"twilio.from_number\" :required=\"!advancedNotifSettings && formData.type === 'twilio'\" placeholder=\"+15550100\" class=\"form-input\"></div><div><label class=\"form-label-sm\">To Numbers</label> <input type=\"text\" name=\"notif_twilio_to_numbers\" x-model=\"twilio.to_numbers\" :required=\"!advancedNotifSettings && formData.type === 'twilio'\" placeholder=\"+15550123, +15550124\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Comma-separated, in E.164 format</p></div></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func notifSignalFields() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var50 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var50 == nil {
+			templ_7745c5c3_Var50 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 71, "<div x-show=\"!advancedNotifSettings && formData.type === 'signal'\" x-cloak class=\"space-y-3\"><div><label class=\"form-label-sm\">Gateway URL</label> <input type=\"text\" name=\"notif_signal_api_url\" inputmode=\"url\" pattern=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var51 string
+		templ_7745c5c3_Var51, templ_7745c5c3_Err = templ.JoinStringErrs(notifURLPattern)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/web/views/notifications.templ`, Line: 714, Col: 91}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var51))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 72, "\" x-model=\"signal.api_url\" :required=\"!advancedNotifSettings && formData.type === 'signal'\" placeholder=\"http://signal-cli:8080\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Base URL of a signal-cli REST API gateway</p></div><div><label class=\"form-label-sm\">Sender Number</label> <input type=\"text\" name=\"notif_signal_number\" x-model=\"signal.number\" :required=\"!advancedNotifSettings && formData.type === 'signal'\" placeholder=\"+15550100\" class=\"form-input\"></div><div><label class=\"form-label-sm\">Recipients</label> <input type=\"text\" name=\"notif_signal_recipients\" x-model=\"signal.recipients\" :required=\"!advancedNotifSettings && formData.type === 'signal'\" placeholder=\"+15550123, group.abc...\" class=\"form-input\"><p class=\"text-[10px] text-muted mt-1\">Comma-separated numbers or group IDs</p></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}